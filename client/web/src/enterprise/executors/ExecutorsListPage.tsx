@@ -141,12 +141,16 @@ export const ExecutorNode: FunctionComponent<React.PropsWithChildren<ExecutorNod
                                 />
                             )}
                             {node.hostname}{' '}
-                            <Badge
-                                variant="secondary"
-                                tooltip={`The executor is configured to pull data from the queue "${node.queueName}"`}
-                            >
-                                {node.queueName}
-                            </Badge>
+                            {node.queueNames.map(queueName => (
+                                <Badge
+                                    key={queueName}
+                                    variant="secondary"
+                                    tooltip={`The executor is configured to pull data from the queue "${queueName}"`}
+                                    className="mr-1"
+                                >
+                                    {queueName}
+                                </Badge>
+                            ))}
                         </H4>
                     </div>
                     <span>
@@ -13,6 +13,7 @@ export const executorFieldsFragment = gql`
         id
         hostname
         queueName
+        queueNames
         active
         os
         architecture
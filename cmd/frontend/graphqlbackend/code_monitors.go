@@ -97,9 +97,22 @@ type MonitorWebhookResolver interface {
 	Enabled() bool
 	IncludeResults() bool
 	URL() string
+	Template() MonitorWebhookTemplateResolver
 	Events(ctx context.Context, args *ListEventsArgs) (MonitorActionEventConnectionResolver, error)
 }
 
+type MonitorWebhookTemplateResolver interface {
+	Method() string
+	Headers() []MonitorWebhookHeaderResolver
+	Body() string
+	HasSecret() bool
+}
+
+type MonitorWebhookHeaderResolver interface {
+	Name() string
+	Value() string
+}
+
 type MonitorSlackWebhookResolver interface {
 	ID() graphql.ID
 	Enabled() bool
@@ -179,6 +192,19 @@ type CreateActionWebhookArgs struct {
 	Enabled        bool
 	IncludeResults bool
 	URL            string
+	Template       *MonitorWebhookTemplateArgs
+}
+
+type MonitorWebhookTemplateArgs struct {
+	Method  *string
+	Headers *[]MonitorWebhookHeaderArgs
+	Body    string
+	Secret  *string
+}
+
+type MonitorWebhookHeaderArgs struct {
+	Name  string
+	Value string
 }
 
 type CreateActionSlackWebhookArgs struct {
@@ -15,6 +15,7 @@ type ComputeResolver interface {
 type ComputeResultResolver interface {
 	ToComputeMatchContext() (ComputeMatchContextResolver, bool)
 	ToComputeText() (ComputeTextResolver, bool)
+	ToComputePatch() (ComputePatchResolver, bool)
 }
 
 type ComputeMatchContextResolver interface {
@@ -43,3 +44,18 @@ type ComputeTextResolver interface {
 	Kind() *string
 	Value() string
 }
+
+type ComputePatchResolver interface {
+	Repository() *RepositoryResolver
+	Commit() string
+	Path() string
+	Kind() string
+	Diff() string
+	Edits() []ComputeEditResolver
+	Definitions() []RangeResolver
+}
+
+type ComputeEditResolver interface {
+	Range() RangeResolver
+	Value() string
+}
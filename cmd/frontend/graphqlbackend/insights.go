@@ -400,6 +400,7 @@ type LineChartSearchInsightDataSeriesInput struct {
 	RepositoryScope            RepositoryScopeInput
 	Options                    LineChartDataSeriesOptionsInput
 	GeneratedFromCaptureGroups *bool
+	RepoMetadata               *bool
 }
 
 type LineChartDataSeriesOptionsInput struct {
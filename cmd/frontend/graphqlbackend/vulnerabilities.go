@@ -0,0 +1,147 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies"
+	livedependencies "github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/live"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+const defaultVulnerabilityMatchesPageSize = 50
+
+type vulnerabilityMatchesArgs struct {
+	Query      *string
+	Repository *graphql.ID
+	First      *int32
+	After      *string
+}
+
+// VulnerabilityMatches resolves the lockfile dependencies affected by imported security advisories.
+func (r *schemaResolver) VulnerabilityMatches(ctx context.Context, args *vulnerabilityMatchesArgs) (*vulnerabilityMatchConnectionResolver, error) {
+	// 🚨 SECURITY: Only site admins may view vulnerability matches across repositories
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	opts := dependencies.ListVulnerabilityMatchesOpts{Limit: defaultVulnerabilityMatchesPageSize}
+	if args.Query != nil {
+		opts.Query = *args.Query
+	}
+	if args.Repository != nil {
+		repoID, err := UnmarshalRepositoryID(*args.Repository)
+		if err != nil {
+			return nil, err
+		}
+		opts.RepositoryID = int(repoID)
+	}
+	if args.First != nil {
+		opts.Limit = int(*args.First)
+	}
+	offset, err := graphqlutil.DecodeIntCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+	opts.Offset = offset
+
+	matches, totalCount, err := livedependencies.GetService(r.db, livedependencies.NewSyncer()).ListVulnerabilityMatches(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*vulnerabilityMatchResolver, 0, len(matches))
+	for _, match := range matches {
+		resolvers = append(resolvers, &vulnerabilityMatchResolver{
+			match: match,
+			repo: NewRepositoryResolver(r.db, &types.Repo{
+				ID:   api.RepoID(match.RepositoryID),
+				Name: api.RepoName(match.RepositoryName),
+			}),
+		})
+	}
+
+	var next *int32
+	if nextOffset := offset + len(matches); nextOffset < totalCount {
+		nextOffset32 := int32(nextOffset)
+		next = &nextOffset32
+	}
+
+	return &vulnerabilityMatchConnectionResolver{
+		nodes:      resolvers,
+		totalCount: totalCount,
+		next:       next,
+	}, nil
+}
+
+type vulnerabilityMatchConnectionResolver struct {
+	nodes      []*vulnerabilityMatchResolver
+	totalCount int
+	next       *int32
+}
+
+func (r *vulnerabilityMatchConnectionResolver) Nodes() []*vulnerabilityMatchResolver {
+	return r.nodes
+}
+
+func (r *vulnerabilityMatchConnectionResolver) TotalCount() int32 {
+	return int32(r.totalCount)
+}
+
+func (r *vulnerabilityMatchConnectionResolver) PageInfo() *graphqlutil.PageInfo {
+	return graphqlutil.EncodeIntCursor(r.next)
+}
+
+type vulnerabilityMatchResolver struct {
+	match dependencies.VulnerabilityMatch
+	repo  *RepositoryResolver
+}
+
+func (r *vulnerabilityMatchResolver) Vulnerability() *vulnerabilityResolver {
+	return &vulnerabilityResolver{vulnerability: r.match.Vulnerability}
+}
+
+func (r *vulnerabilityMatchResolver) Repository() *RepositoryResolver {
+	return r.repo
+}
+
+func (r *vulnerabilityMatchResolver) Commit() string         { return r.match.Commit }
+func (r *vulnerabilityMatchResolver) Lockfile() string       { return r.match.Lockfile }
+func (r *vulnerabilityMatchResolver) PackageName() string    { return r.match.PackageName }
+func (r *vulnerabilityMatchResolver) PackageVersion() string { return r.match.PackageVersion }
+
+type vulnerabilityResolver struct {
+	vulnerability dependencies.Vulnerability
+}
+
+func (r *vulnerabilityResolver) SourceID() string { return r.vulnerability.SourceID }
+func (r *vulnerabilityResolver) Summary() string  { return r.vulnerability.Summary }
+func (r *vulnerabilityResolver) Details() string  { return r.vulnerability.Details }
+
+func (r *vulnerabilityResolver) Aliases() []string {
+	if r.vulnerability.Aliases == nil {
+		return []string{}
+	}
+
+	return r.vulnerability.Aliases
+}
+
+func (r *vulnerabilityResolver) Severity() *string {
+	if r.vulnerability.Severity == "" {
+		return nil
+	}
+
+	return &r.vulnerability.Severity
+}
+
+func (r *vulnerabilityResolver) Published() *DateTime {
+	return DateTimeOrNil(r.vulnerability.PublishedAt)
+}
+
+func (r *vulnerabilityResolver) Modified() *DateTime {
+	return DateTimeOrNil(r.vulnerability.ModifiedAt)
+}
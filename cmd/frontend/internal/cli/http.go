@@ -60,7 +60,7 @@ func newExternalHTTPHandler(
 	// 🚨 SECURITY: The HTTP API should not accept cookies as authentication, except from trusted
 	// origins, to avoid CSRF attacks. See session.CookieMiddlewareWithCSRFSafety for details.
 	apiHandler = session.CookieMiddlewareWithCSRFSafety(db, apiHandler, corsAllowHeader, isTrustedOrigin) // API accepts cookies with special header
-	apiHandler = internalhttpapi.AccessTokenAuthMiddleware(db, apiHandler)                                // API accepts access tokens
+	apiHandler = internalhttpapi.AccessTokenAuthMiddleware(db, schema, apiHandler)                        // API accepts access tokens
 	apiHandler = gziphandler.GzipHandler(apiHandler)
 	if envvar.SourcegraphDotComMode() {
 		apiHandler = deviceid.Middleware(apiHandler)
@@ -79,9 +79,9 @@ func newExternalHTTPHandler(
 	}
 	appHandler = featureflag.Middleware(db.FeatureFlags(), appHandler)
 	appHandler = actor.AnonymousUIDMiddleware(appHandler)
-	appHandler = authMiddlewares.App(appHandler)                           // 🚨 SECURITY: auth middleware
-	appHandler = session.CookieMiddleware(db, appHandler)                  // app accepts cookies
-	appHandler = internalhttpapi.AccessTokenAuthMiddleware(db, appHandler) // app accepts access tokens
+	appHandler = authMiddlewares.App(appHandler)                                   // 🚨 SECURITY: auth middleware
+	appHandler = session.CookieMiddleware(db, appHandler)                          // app accepts cookies
+	appHandler = internalhttpapi.AccessTokenAuthMiddleware(db, schema, appHandler) // app accepts access tokens
 	if envvar.SourcegraphDotComMode() {
 		appHandler = deviceid.Middleware(appHandler)
 	}
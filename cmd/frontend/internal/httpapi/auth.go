@@ -1,8 +1,17 @@
 package httpapi
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 
+	"github.com/graph-gophers/graphql-go"
+	gqltypes "github.com/graph-gophers/graphql-go/types"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
 	"github.com/inconshreveable/log15"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
@@ -15,8 +24,9 @@ import (
 )
 
 // AccessTokenAuthMiddleware authenticates the user based on the
-// token query parameter or the "Authorization" header.
-func AccessTokenAuthMiddleware(db database.DB, next http.Handler) http.Handler {
+// token query parameter or the "Authorization" header. The GraphQL schema is
+// used to determine the scope required by GraphQL requests.
+func AccessTokenAuthMiddleware(db database.DB, schema *graphql.Schema, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Vary", "Authorization")
 
@@ -65,7 +75,7 @@ func AccessTokenAuthMiddleware(db database.DB, next http.Handler) http.Handler {
 			// is allowed to do.
 			var requiredScope string
 			if sudoUser == "" {
-				requiredScope = authz.ScopeUserAll
+				requiredScope = requiredRequestScope(r, schema)
 			} else {
 				requiredScope = authz.ScopeSiteAdminSudo
 			}
@@ -119,3 +129,352 @@ func AccessTokenAuthMiddleware(db database.DB, next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// requiredRequestScope returns the access token scope required to serve the request. Requests
+// that are not known to be covered by a narrower scope require authz.ScopeUserAll.
+//
+// 🚨 SECURITY: Every request that is mapped to a scope other than authz.ScopeUserAll must only
+// be able to perform the actions described by that scope.
+func requiredRequestScope(r *http.Request, schema *graphql.Schema) string {
+	path := r.URL.Path
+	switch {
+	case path == "/.api/graphql" && r.Method == "POST":
+		return requiredGraphQLScope(r, schema)
+	case (path == "/.api/search/stream" || path == "/search/stream") && r.Method == "GET":
+		return authz.ScopeSearchRead
+	case strings.HasPrefix(path, "/.api/search/export/") && r.Method == "GET":
+		return authz.ScopeSearchRead
+	case path == "/.api/lsif/upload" && r.Method == "POST":
+		return authz.ScopeCodeIntelUpload
+	case strings.HasPrefix(path, "/.api/files/batch-changes/") && r.Method == "POST":
+		// Uploads of the files mounted into batch spec steps, made by src-cli.
+		return authz.ScopeBatchesWrite
+	case strings.Contains(path, "/-/raw") && (r.Method == "GET" || r.Method == "HEAD"):
+		return authz.ScopeRepoRead
+	}
+	return authz.ScopeUserAll
+}
+
+// requiredGraphQLScope returns the access token scope required to execute the GraphQL request.
+// The request body is restored, so that the GraphQL handler can read it again.
+func requiredGraphQLScope(r *http.Request, schema *graphql.Schema) string {
+	if schema == nil {
+		return authz.ScopeUserAll
+	}
+
+	body, err := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return authz.ScopeUserAll
+	}
+
+	var reader io.Reader = bytes.NewReader(body)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return authz.ScopeUserAll
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	var params graphQLQueryParams
+	if err := json.NewDecoder(reader).Decode(&params); err != nil {
+		return authz.ScopeUserAll
+	}
+	return graphQLQueryScope(schema.ASTSchema(), params.Query)
+}
+
+var (
+	// introspectionFields may be queried with any scope.
+	introspectionFields = map[string]struct{}{"__typename": {}, "__schema": {}, "__type": {}}
+
+	// searchQueryFields are the top-level query fields allowed by authz.ScopeSearchRead.
+	searchQueryFields = map[string]struct{}{"search": {}, "parseSearchQuery": {}}
+
+	// repoQueryFields are the top-level query fields allowed by authz.ScopeRepoRead, in addition to
+	// searchQueryFields.
+	repoQueryFields = map[string]struct{}{
+		"repository":         {},
+		"repositoryRedirect": {},
+		"repositories":       {},
+		"highlightCode":      {},
+	}
+
+	// batchChangesFields are the top-level query and mutation fields allowed by
+	// authz.ScopeBatchesWrite, in addition to repoQueryFields and searchQueryFields. Besides the
+	// batch changes fields, they include the queries that src-cli uses to resolve the namespace
+	// of a batch change and to check the Sourcegraph version. Fields that manage code host
+	// credentials are excluded.
+	batchChangesFields = map[string]struct{}{
+		"currentUser":                        {},
+		"user":                               {},
+		"organization":                       {},
+		"namespace":                          {},
+		"namespaceByName":                    {},
+		"site":                               {},
+		"batchChanges":                       {},
+		"batchChange":                        {},
+		"batchChangesCodeHosts":              {},
+		"availableBulkOperations":            {},
+		"batchSpecs":                         {},
+		"createChangesetSpec":                {},
+		"syncChangeset":                      {},
+		"reenqueueChangeset":                 {},
+		"createBatchChange":                  {},
+		"createBatchSpec":                    {},
+		"createEmptyBatchChange":             {},
+		"upsertEmptyBatchChange":             {},
+		"createBatchSpecFromRaw":             {},
+		"replaceBatchSpecInput":              {},
+		"upsertBatchSpecInput":               {},
+		"deleteBatchSpec":                    {},
+		"executeBatchSpec":                   {},
+		"applyBatchChange":                   {},
+		"closeBatchChange":                   {},
+		"moveBatchChange":                    {},
+		"deleteBatchChange":                  {},
+		"detachChangesets":                   {},
+		"createChangesetComments":            {},
+		"reenqueueChangesets":                {},
+		"mergeChangesets":                    {},
+		"closeChangesets":                    {},
+		"publishChangesets":                  {},
+		"cancelBatchSpecExecution":           {},
+		"cancelBatchSpecWorkspaceExecution":  {},
+		"retryBatchSpecWorkspaceExecution":   {},
+		"retryBatchSpecExecution":            {},
+		"enqueueBatchSpecWorkspaceExecution": {},
+		"toggleBatchSpecAutoApply":           {},
+	}
+
+	// restrictedTypeFields are the only fields of the given types that may be selected with a
+	// scope narrower than authz.ScopeUserAll, wherever the types occur in the query. Users,
+	// organizations and the site are reachable from many other types (e.g. the author of a
+	// commit), but only their identifying fields are covered by the narrower scopes.
+	restrictedTypeFields = map[string]map[string]struct{}{
+		"User":                   {"id": {}, "databaseID": {}, "username": {}, "displayName": {}, "avatarURL": {}, "url": {}, "namespaceName": {}},
+		"Org":                    {"id": {}, "name": {}, "displayName": {}, "url": {}, "namespaceName": {}},
+		"Site":                   {"id": {}, "productVersion": {}},
+		"AccessToken":            {},
+		"ExternalAccount":        {},
+		"UserEmail":              {},
+		"Settings":               {},
+		"SettingsCascade":        {},
+		"ExternalService":        {},
+		"OrganizationInvitation": {},
+	}
+)
+
+// graphQLQueryScope returns the narrowest access token scope that allows every operation of the
+// GraphQL document. The scope is determined by the top-level fields that the operations select,
+// and every field selected at any depth must be allowed by restrictedTypeFields. Documents that
+// cannot be parsed or checked against the schema, or that select top-level fields through
+// fragments, require authz.ScopeUserAll.
+func graphQLQueryScope(schema *gqltypes.Schema, query string) string {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return authz.ScopeUserAll
+	}
+
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, def := range doc.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok && fragment.Name != nil {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	var fields []string
+	operationType := ""
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationType != "" && operationType != op.Operation {
+			return authz.ScopeUserAll
+		}
+		operationType = op.Operation
+
+		if op.SelectionSet == nil {
+			return authz.ScopeUserAll
+		}
+		for _, selection := range op.SelectionSet.Selections {
+			field, ok := selection.(*ast.Field)
+			if !ok || field.Name == nil {
+				return authz.ScopeUserAll
+			}
+			if _, ok := introspectionFields[field.Name.Value]; !ok {
+				fields = append(fields, field.Name.Value)
+			}
+		}
+
+		root, ok := schema.EntryPoints[op.Operation]
+		if !ok {
+			return authz.ScopeUserAll
+		}
+		checker := &selectionChecker{schema: schema, fragments: fragments, visiting: map[string]bool{}}
+		if !checker.allowed(root, op.SelectionSet) {
+			return authz.ScopeUserAll
+		}
+	}
+
+	allIn := func(sets ...map[string]struct{}) bool {
+		for _, field := range fields {
+			found := false
+			for _, set := range sets {
+				if _, ok := set[field]; ok {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch operationType {
+	case ast.OperationTypeQuery:
+		switch {
+		case allIn(searchQueryFields):
+			return authz.ScopeSearchRead
+		case allIn(searchQueryFields, repoQueryFields):
+			return authz.ScopeRepoRead
+		case allIn(searchQueryFields, repoQueryFields, batchChangesFields):
+			return authz.ScopeBatchesWrite
+		}
+	case ast.OperationTypeMutation:
+		if len(fields) > 0 && allIn(batchChangesFields) {
+			return authz.ScopeBatchesWrite
+		}
+	}
+	return authz.ScopeUserAll
+}
+
+// selectionChecker checks the selections of a GraphQL document against restrictedTypeFields,
+// resolving the type of each selected field with the schema.
+type selectionChecker struct {
+	schema    *gqltypes.Schema
+	fragments map[string]*ast.FragmentDefinition
+	visiting  map[string]bool
+}
+
+// allowed reports whether every field selected by set on the given type, at any depth, may be
+// selected with a scope narrower than authz.ScopeUserAll. Fields that are not in the schema are
+// not allowed.
+func (c *selectionChecker) allowed(typ gqltypes.NamedType, set *ast.SelectionSet) bool {
+	if set == nil {
+		return true
+	}
+
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if selection.Name == nil {
+				return false
+			}
+			name := selection.Name.Value
+			if _, ok := introspectionFields[name]; ok {
+				continue
+			}
+			if restricted(typ, name) {
+				return false
+			}
+
+			var fieldDefs gqltypes.FieldsDefinition
+			switch t := typ.(type) {
+			case *gqltypes.ObjectTypeDefinition:
+				fieldDefs = t.Fields
+			case *gqltypes.InterfaceTypeDefinition:
+				fieldDefs = t.Fields
+			}
+			fieldDef := fieldDefs.Get(name)
+			if fieldDef == nil {
+				return false
+			}
+			fieldType, ok := namedType(fieldDef.Type)
+			if !ok || !c.allowed(fieldType, selection.SelectionSet) {
+				return false
+			}
+
+		case *ast.InlineFragment:
+			fragmentType := typ
+			if selection.TypeCondition != nil && selection.TypeCondition.Name != nil {
+				t, ok := c.schema.Types[selection.TypeCondition.Name.Value]
+				if !ok {
+					return false
+				}
+				fragmentType = t
+			}
+			if !c.allowed(fragmentType, selection.SelectionSet) {
+				return false
+			}
+
+		case *ast.FragmentSpread:
+			if selection.Name == nil {
+				return false
+			}
+			fragment, ok := c.fragments[selection.Name.Value]
+			if !ok || fragment.TypeCondition == nil || fragment.TypeCondition.Name == nil || c.visiting[selection.Name.Value] {
+				return false
+			}
+			fragmentType, ok := c.schema.Types[fragment.TypeCondition.Name.Value]
+			if !ok {
+				return false
+			}
+			c.visiting[selection.Name.Value] = true
+			ok = c.allowed(fragmentType, fragment.SelectionSet)
+			c.visiting[selection.Name.Value] = false
+			if !ok {
+				return false
+			}
+
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// restricted reports whether restrictedTypeFields excludes the given field of the type. A field
+// of an interface is excluded if it is excluded for any of the types implementing it.
+func restricted(typ gqltypes.NamedType, field string) bool {
+	isRestricted := func(typeName string) bool {
+		allowed, ok := restrictedTypeFields[typeName]
+		if !ok {
+			return false
+		}
+		_, ok = allowed[field]
+		return !ok
+	}
+
+	if isRestricted(typ.TypeName()) {
+		return true
+	}
+	if iface, ok := typ.(*gqltypes.InterfaceTypeDefinition); ok {
+		for _, possibleType := range iface.PossibleTypes {
+			if isRestricted(possibleType.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// namedType returns the named type wrapped by the given list and non-null types.
+func namedType(typ gqltypes.Type) (gqltypes.NamedType, bool) {
+	for {
+		switch t := typ.(type) {
+		case *gqltypes.List:
+			typ = t.OfType
+		case *gqltypes.NonNull:
+			typ = t.OfType
+		case gqltypes.NamedType:
+			return t, true
+		default:
+			return nil, false
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package httpapi
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -9,6 +11,7 @@ import (
 	"testing"
 
 	mockrequire "github.com/derision-test/go-mockgen/testutil/require"
+	"github.com/graph-gophers/graphql-go"
 
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/authz"
@@ -19,7 +22,7 @@ import (
 
 func TestAccessTokenAuthMiddleware(t *testing.T) {
 	newHandler := func(db database.DB) http.Handler {
-		return AccessTokenAuthMiddleware(db, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		return AccessTokenAuthMiddleware(db, testScopeSchema, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			actor := actor.FromContext(r.Context())
 			if actor.IsAuthenticated() {
 				fmt.Fprintf(w, "user %v", actor.UID)
@@ -163,6 +166,24 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		})
 	}
 
+	t.Run("valid non-sudo token for restricted request", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/.api/search/stream?q=foo", nil)
+		req.Header.Set("Authorization", "token abcdef")
+
+		accessTokens := database.NewMockAccessTokenStore()
+		accessTokens.LookupFunc.SetDefaultHook(func(_ context.Context, tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+			if want := authz.ScopeSearchRead; requiredScope != want {
+				t.Errorf("got %q, want %q", requiredScope, want)
+			}
+			return 123, nil
+		})
+		db := database.NewMockDB()
+		db.AccessTokensFunc.SetDefaultReturn(accessTokens)
+
+		checkHTTPResponse(t, db, req, http.StatusOK, "user 123")
+		mockrequire.Called(t, accessTokens.LookupFunc)
+	})
+
 	t.Run("valid sudo token", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
@@ -275,3 +296,190 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		mockrequire.Called(t, users.GetByUsernameFunc)
 	})
 }
+
+// testScopeSchema is a subset of the GraphQL schema used to determine the scopes of GraphQL
+// requests.
+var testScopeSchema = graphql.MustParseSchema(`
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+type Query {
+	search(query: String!): SearchResults
+	repository(name: String!): Repository
+	currentUser: User
+	user(username: String!): User
+	organization(name: String!): Org
+	site: Site!
+	batchChange(namespace: ID!, name: String!): BatchChange
+	node(id: ID!): Node
+}
+
+type Mutation {
+	applyBatchChange(batchSpec: ID!): BatchChange!
+	createBatchChangesCredential(externalServiceKind: String!, externalServiceURL: String!, credential: String!): BatchChangesCredential!
+	deleteUser(user: ID!): EmptyResponse
+}
+
+interface Node {
+	id: ID!
+}
+
+interface Namespace {
+	id: ID!
+	namespaceName: String!
+	url: String!
+}
+
+type SearchResults {
+	matchCount: Int!
+	results: [SearchResult!]!
+}
+
+union SearchResult = FileMatch | CommitSearchResult
+
+type FileMatch {
+	repository: Repository!
+}
+
+type CommitSearchResult {
+	commit: GitCommit!
+}
+
+type Repository implements Node {
+	id: ID!
+	name: String!
+	commit(rev: String!): GitCommit
+}
+
+type GitCommit implements Node {
+	id: ID!
+	author: Signature!
+}
+
+type Signature {
+	person: Person!
+}
+
+type Person {
+	email: String!
+	user: User
+}
+
+type User implements Node & Namespace {
+	id: ID!
+	username: String!
+	namespaceName: String!
+	url: String!
+	emails: [UserEmail!]!
+	accessTokens: [AccessToken!]!
+}
+
+type UserEmail {
+	email: String!
+}
+
+type AccessToken implements Node {
+	id: ID!
+	note: String!
+}
+
+type Org implements Node & Namespace {
+	id: ID!
+	name: String!
+	namespaceName: String!
+	url: String!
+	members: [User!]!
+}
+
+type Site {
+	productVersion: String!
+	configuration: String!
+}
+
+type BatchChange implements Node {
+	id: ID!
+	namespace: Namespace!
+	creator: User
+}
+
+type BatchChangesCredential {
+	id: ID!
+}
+
+type EmptyResponse {
+	alwaysNil: String
+}
+`, nil)
+
+func TestRequiredRequestScope(t *testing.T) {
+	newGraphQLRequest := func(query string) *http.Request {
+		body, _ := json.Marshal(graphQLQueryParams{Query: query})
+		req, _ := http.NewRequest("POST", "/.api/graphql", bytes.NewReader(body))
+		return req
+	}
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{"app page", httptest.NewRequest("GET", "/github.com/a/b", nil), authz.ScopeUserAll},
+		{"raw file", httptest.NewRequest("GET", "/github.com/a/b/-/raw/README.md", nil), authz.ScopeRepoRead},
+		{"streaming search", httptest.NewRequest("GET", "/.api/search/stream?q=foo", nil), authz.ScopeSearchRead},
+		{"search export download", httptest.NewRequest("GET", "/.api/search/export/1/abc.csv", nil), authz.ScopeSearchRead},
+		{"lsif upload", httptest.NewRequest("POST", "/.api/lsif/upload", nil), authz.ScopeCodeIntelUpload},
+		{"batch spec file upload", httptest.NewRequest("POST", "/.api/files/batch-changes/QmF0Y2hTcGVjOjE=", nil), authz.ScopeBatchesWrite},
+		{"graphql search", newGraphQLRequest(`query Search { search(query: "foo") { matchCount } }`), authz.ScopeSearchRead},
+		{"graphql search results", newGraphQLRequest(`{ search(query: "foo") { results { __typename ... on FileMatch { repository { name } } } } }`), authz.ScopeSearchRead},
+		{"graphql repository", newGraphQLRequest(`{ repository(name: "a") { id } __typename }`), authz.ScopeRepoRead},
+		{"graphql commit author", newGraphQLRequest(`{ repository(name: "a") { commit(rev: "HEAD") { author { person { email user { username url } } } } } }`), authz.ScopeRepoRead},
+		{"graphql commit author emails", newGraphQLRequest(`{ repository(name: "a") { commit(rev: "HEAD") { author { person { user { emails { email } } } } } } }`), authz.ScopeUserAll},
+		{"graphql search author access tokens", newGraphQLRequest(`{ search(query: "foo") { results { ... on CommitSearchResult { commit { author { person { user { accessTokens { note } } } } } } } } }`), authz.ScopeUserAll},
+		{"graphql author through fragment", newGraphQLRequest(`{ repository(name: "a") { commit(rev: "HEAD") { author { person { user { ...U } } } } } } fragment U on User { emails { email } }`), authz.ScopeUserAll},
+		{"graphql unknown field", newGraphQLRequest(`{ repository(name: "a") { secrets } }`), authz.ScopeUserAll},
+		{"graphql current user", newGraphQLRequest(`{ currentUser { id } }`), authz.ScopeBatchesWrite},
+		{"graphql current user emails", newGraphQLRequest(`{ currentUser { id emails { email } } }`), authz.ScopeUserAll},
+		{"graphql namespace lookup", newGraphQLRequest(`query { user(username: "a") { id } organization(name: "b") { id } }`), authz.ScopeBatchesWrite},
+		{"graphql organization members", newGraphQLRequest(`{ organization(name: "b") { members { username } } }`), authz.ScopeUserAll},
+		{"graphql site version", newGraphQLRequest(`query SourcegraphVersion { site { productVersion } }`), authz.ScopeBatchesWrite},
+		{"graphql site configuration", newGraphQLRequest(`{ site { configuration } }`), authz.ScopeUserAll},
+		{"graphql batch change", newGraphQLRequest(`{ batchChange(namespace: "a", name: "b") { namespace { namespaceName url } creator { username } } repository(name: "a") { id } }`), authz.ScopeBatchesWrite},
+		{"graphql batch change namespace fragment", newGraphQLRequest(`{ batchChange(namespace: "a", name: "b") { namespace { ... on User { emails { email } } } } }`), authz.ScopeUserAll},
+		{"graphql node", newGraphQLRequest(`{ node(id: "a") { id } }`), authz.ScopeUserAll},
+		{"graphql batch changes", newGraphQLRequest(`mutation { applyBatchChange(batchSpec: "x") { id } }`), authz.ScopeBatchesWrite},
+		{"graphql batch change credential", newGraphQLRequest(`mutation { createBatchChangesCredential(externalServiceKind: "GITHUB", externalServiceURL: "u", credential: "c") { id } }`), authz.ScopeUserAll},
+		{"graphql other mutation", newGraphQLRequest(`mutation { deleteUser(user: "x") { alwaysNil } }`), authz.ScopeUserAll},
+		{"graphql mixed operations", newGraphQLRequest(`query A { search(query: "foo") { __typename } } mutation B { deleteUser(user: "x") { alwaysNil } }`), authz.ScopeUserAll},
+		{"graphql fragment on root", newGraphQLRequest(`query { ...F } fragment F on Query { currentUser { id } }`), authz.ScopeUserAll},
+		{"graphql invalid", newGraphQLRequest(`{`), authz.ScopeUserAll},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := requiredRequestScope(test.req, testScopeSchema); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	t.Run("graphql without schema", func(t *testing.T) {
+		req := newGraphQLRequest(`{ search(query: "foo") { matchCount } }`)
+		if got, want := requiredRequestScope(req, nil), authz.ScopeUserAll; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("graphql body is restored", func(t *testing.T) {
+		req := newGraphQLRequest(`{ search(query: "foo") { __typename } }`)
+		requiredRequestScope(req, testScopeSchema)
+
+		var params graphQLQueryParams
+		if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+			t.Fatal(err)
+		}
+		if want := `{ search(query: "foo") { __typename } }`; params.Query != want {
+			t.Errorf("got query %q, want %q", params.Query, want)
+		}
+	})
+}
@@ -12,6 +12,7 @@ const (
 	GraphQL    = "graphql"
 
 	SearchStream  = "search.stream"
+	SearchExport  = "search.export"
 	ComputeStream = "compute.stream"
 
 	SrcCliVersion  = "src-cli.version"
@@ -69,6 +70,7 @@ func New(base *mux.Router) *mux.Router {
 	base.Path("/bitbucket-cloud-webhooks").Methods("POST").Name(BitbucketCloudWebhooks)
 	base.Path("/lsif/upload").Methods("POST").Name(LSIFUpload)
 	base.Path("/search/stream").Methods("GET").Name(SearchStream)
+	base.Path("/search/export/{Key:.*}").Methods("GET").Name(SearchExport)
 	base.Path("/compute/stream").Methods("GET", "POST").Name(ComputeStream)
 	base.Path("/src-cli/version").Methods("GET").Name(SrcCliVersion)
 	base.Path("/src-cli/{rest:.*}").Methods("GET").Name(SrcCliDownload)
@@ -0,0 +1,80 @@
+package webhookhandlers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	gh "github.com/google/go-github/v43/github"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/repoupdater"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// handleGitHubPushEvent handles GitHub push events, which are sent for pushed branches as well
+// as tags, and enqueues an update of the pushed repo so that new commits don't have to wait for
+// the repo to be polled.
+func handleGitHubPushEvent(db database.DB) func(ctx context.Context, extSvc *types.ExternalService, payload any) error {
+	return func(ctx context.Context, extSvc *types.ExternalService, payload any) error {
+		log15.Debug("handleGitHubPushEvent: Got github event", "type", fmt.Sprintf("%T", payload))
+
+		e, ok := payload.(*gh.PushEvent)
+		if !ok {
+			return errors.Errorf("incorrect event type sent to github push event handler: %T", payload)
+		}
+		if e.GetRepo().GetNodeID() == "" {
+			return nil
+		}
+
+		serviceID, err := githubServiceID(extSvc)
+		if err != nil {
+			return err
+		}
+
+		// The webhook handler already runs as an internal actor, so private repos are found too.
+		rs, err := db.Repos().List(ctx, database.ReposListOptions{
+			ExternalRepos: []api.ExternalRepoSpec{{
+				ID:          e.GetRepo().GetNodeID(),
+				ServiceType: extsvc.TypeGitHub,
+				ServiceID:   serviceID,
+			}},
+		})
+		if err != nil {
+			return errors.Wrap(err, "listing repos")
+		}
+		if len(rs) == 0 {
+			// The repo has not been synced from the code host (yet).
+			log15.Debug("handleGitHubPushEvent: Pushed repo not found", "repo", e.GetRepo().GetFullName())
+			return nil
+		}
+
+		_, err = repoupdater.DefaultClient.EnqueueWebhookRepoUpdate(ctx, rs[0].Name)
+		return err
+	}
+}
+
+// githubServiceID returns the normalized URL of the GitHub instance of the external service, which
+// is the service ID of its repos.
+func githubServiceID(extSvc *types.ExternalService) (string, error) {
+	c, err := extSvc.Configuration()
+	if err != nil {
+		return "", errors.Wrap(err, "getting external service configuration")
+	}
+
+	config, ok := c.(*schema.GitHubConnection)
+	if !ok {
+		return "", errors.Errorf("external service %d is not a GitHub connection", extSvc.ID)
+	}
+
+	u, err := url.Parse(config.Url)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing GitHub URL")
+	}
+	return extsvc.NormalizeBaseURL(u).String(), nil
+}
@@ -23,4 +23,6 @@ func Init(db database.DB, w *webhooks.GitHubWebhook) {
 	w.Register(handleGitHubUserAuthzEvent(db, authz.FetchPermsOptions{InvalidateCaches: true}), "organisation")
 	w.Register(handleGitHubUserAuthzEvent(db, authz.FetchPermsOptions{InvalidateCaches: true}), "membership")
 
+	// Push events are sent for pushed branches and tags, and trigger an immediate repo update
+	w.Register(handleGitHubPushEvent(db), "push")
 }
@@ -0,0 +1,354 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	sglog "github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/exportstore"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/search/run"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/internal/uploadstore"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// exportStoreConfig is read from the environment by LoadExportStoreConfig. Uploaded exports are
+// disabled if it has not been loaded.
+var exportStoreConfig *exportstore.Config
+
+// LoadExportStoreConfig reads the configuration of the store that uploaded exports are written
+// to. It must be called at startup, before the environment is locked.
+func LoadExportStoreConfig() {
+	exportStoreConfig = &exportstore.Config{}
+	exportStoreConfig.Load()
+}
+
+var (
+	exportStoreOnce sync.Once
+	exportStore     uploadstore.Store
+	exportStoreErr  error
+)
+
+// getExportStore returns the store that uploaded exports are written to.
+func getExportStore() (uploadstore.Store, error) {
+	if exportStoreConfig == nil || !exportStoreConfig.Enabled() {
+		return nil, errors.New("uploaded search exports are disabled: SEARCH_EXPORT_UPLOAD_BACKEND is not set")
+	}
+
+	exportStoreOnce.Do(func() {
+		if exportStoreErr = exportStoreConfig.Validate(); exportStoreErr != nil {
+			return
+		}
+
+		observationContext := &observation.Context{
+			Logger:     sglog.Scoped("search.exportstore", "search result export store"),
+			Tracer:     &trace.Tracer{Tracer: opentracing.GlobalTracer()},
+			Registerer: prometheus.DefaultRegisterer,
+		}
+		exportStore, exportStoreErr = exportstore.New(context.Background(), exportStoreConfig, observationContext)
+	})
+
+	return exportStore, exportStoreErr
+}
+
+// Trailers sent at the end of an export written directly to the response, as the response
+// status has already been sent when they are known.
+const (
+	exportRowsTrailer     = "X-Sourcegraph-Export-Rows"
+	exportLimitHitTrailer = "X-Sourcegraph-Export-Limit-Hit"
+	exportAlertTrailer    = "X-Sourcegraph-Export-Alert"
+	exportErrorTrailer    = "X-Sourcegraph-Export-Error"
+)
+
+// badRequestError is returned for invalid export parameters.
+type badRequestError struct{ err error }
+
+func (e *badRequestError) Error() string    { return e.err.Error() }
+func (e *badRequestError) Unwrap() error    { return e.err }
+func (e *badRequestError) BadRequest() bool { return true }
+
+// exportErrorCode returns the value of the error trailer for an export which failed after the
+// response started. The error itself is only logged, as it may contain characters which are
+// not valid in a header value.
+func exportErrorCode(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled) || ctx.Err() != nil:
+		return "canceled"
+	default:
+		return "internal-error"
+	}
+}
+
+// trailerValue replaces the characters which are not valid in a header value.
+func trailerValue(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// exportResult is the response to a request for an uploaded export.
+type exportResult struct {
+	URL      string `json:"url"`
+	Rows     int    `json:"rows"`
+	LimitHit bool   `json:"limitHit"`
+	Alert    string `json:"alert,omitempty"`
+}
+
+// serveExport runs the search and writes every match as a row of a CSV or JSON Lines file
+// rather than as a stream of events. The display limit does not apply: every match up to the
+// query's count (e.g., count:all) is exported.
+//
+// If the upload parameter is set, the file is written to the export store and the response is
+// a JSON object with a link to download it once the search completes.
+func (h *streamHandler) serveExport(w http.ResponseWriter, r *http.Request, tr *trace.Trace, args *args) error {
+	ctx := r.Context()
+
+	format, err := streamhttp.ParseExportFormat(args.Export)
+	if err != nil {
+		return &badRequestError{err}
+	}
+	columns, err := streamhttp.ParseExportColumns(args.ExportColumns)
+	if err != nil {
+		return &badRequestError{err}
+	}
+
+	settings, err := graphqlbackend.DecodedViewerFinalSettings(ctx, h.db)
+	if err != nil {
+		return err
+	}
+
+	inputs, err := h.searchClient.Plan(ctx, args.Version, strPtr(args.PatternType), args.Query, search.Streaming, settings, envvar.SourcegraphDotComMode())
+	if err != nil {
+		var queryErr *run.QueryError
+		if errors.As(err, &queryErr) {
+			return &badRequestError{errors.Errorf("%s: %s", queryErr.Query, queryErr.Err)}
+		}
+		return err
+	}
+
+	if args.ExportUpload {
+		return h.serveExportUpload(w, r, format, columns, inputs)
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "search-results"+format.Extension()))
+	w.Header().Set("Trailer", strings.Join([]string{exportRowsTrailer, exportLimitHitTrailer, exportAlertTrailer, exportErrorTrailer}, ", "))
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	exportWriter := streamhttp.NewExportWriter(w, format, columns)
+	result, err := h.runExport(ctx, exportWriter, inputs)
+	if err != nil {
+		// The response has already started, so the error can only be reported in a trailer
+		tr.SetError(err)
+		log15.Error("search export failed", "query", args.Query, "error", err)
+		w.Header().Set(exportErrorTrailer, exportErrorCode(ctx, err))
+	}
+
+	w.Header().Set(exportRowsTrailer, fmt.Sprintf("%d", result.Rows))
+	w.Header().Set(exportLimitHitTrailer, fmt.Sprintf("%t", result.LimitHit))
+	if result.Alert != "" {
+		w.Header().Set(exportAlertTrailer, trailerValue(result.Alert))
+	}
+
+	return nil
+}
+
+func (h *streamHandler) serveExportUpload(w http.ResponseWriter, r *http.Request, format streamhttp.ExportFormat, columns []string, inputs *run.SearchInputs) error {
+	ctx := r.Context()
+
+	a := actor.FromContext(ctx)
+	if !a.IsAuthenticated() {
+		return &errcode.HTTPErr{Status: http.StatusUnauthorized, Err: errors.New("uploaded search exports require an authenticated user")}
+	}
+
+	store, err := getExportStore()
+	if err != nil {
+		return err
+	}
+
+	key := exportKey(a.UID, uuid.New().String(), format)
+
+	pr, pw := io.Pipe()
+	uploadErrs := make(chan error, 1)
+	go func() {
+		_, err := store.Upload(ctx, key, pr)
+		// Unblock the export if the upload fails before consuming all rows
+		pr.CloseWithError(err)
+		uploadErrs <- err
+	}()
+
+	result, err := h.runExport(ctx, streamhttp.NewExportWriter(pw, format, columns), inputs)
+	pw.CloseWithError(err)
+	if uploadErr := <-uploadErrs; err == nil {
+		err = uploadErr
+	}
+	if err != nil {
+		return err
+	}
+
+	result.URL = conf.ExternalURL() + "/.api/search/export/" + key
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
+
+// runExport executes the search and writes every match the actor has access to.
+func (h *streamHandler) runExport(ctx context.Context, exportWriter *streamhttp.ExportWriter, inputs *run.SearchInputs) (exportResult, error) {
+	start := time.Now()
+
+	var (
+		mu       sync.Mutex
+		limitHit bool
+		writeErr error
+	)
+
+	stream := streaming.StreamFunc(func(event streaming.SearchEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		limitHit = limitHit || event.Stats.IsLimitHit
+		if writeErr != nil {
+			return
+		}
+
+		repoMetadata, err := getEventRepoMetadata(ctx, h.db, event)
+		if err != nil {
+			writeErr = err
+			return
+		}
+
+		for _, match := range event.Results {
+			repo := match.RepoName()
+
+			// Don't export matches which we cannot map to a repo the actor has access to.
+			// Author matches aggregate matched lines across repositories.
+			if _, ok := match.(*result.AuthorMatch); !ok {
+				if md, ok := repoMetadata[repo.ID]; !ok || md.Name != repo.Name {
+					continue
+				}
+			}
+
+			if err := exportWriter.Write(fromMatch(match, repoMetadata, false)); err != nil {
+				writeErr = err
+				return
+			}
+		}
+	})
+
+	alert, err := h.searchClient.Execute(ctx, stream, inputs)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err == nil {
+		err = writeErr
+	}
+	if err == nil {
+		err = exportWriter.Flush()
+	}
+
+	result := exportResult{
+		Rows:     exportWriter.Rows,
+		LimitHit: limitHit,
+	}
+	if alert != nil {
+		result.Alert = alert.Title
+	}
+
+	metricExportRows.Observe(float64(result.Rows))
+	metricExportDuration.Observe(time.Since(start).Seconds())
+
+	return result, err
+}
+
+func exportKey(userID int32, id string, format streamhttp.ExportFormat) string {
+	return fmt.Sprintf("%d/%s%s", userID, id, format.Extension())
+}
+
+// ExportDownloadHandler is an http handler which serves uploaded search result exports. An
+// export can only be downloaded by the user who created it.
+func ExportDownloadHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		a := actor.FromContext(ctx)
+		if !a.IsAuthenticated() {
+			http.Error(w, "not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		// 🚨 SECURITY: Keys are prefixed with the ID of the user who created the export.
+		key := mux.Vars(r)["Key"]
+		if !strings.HasPrefix(key, a.UIDString()+"/") || strings.Contains(key, "..") {
+			http.Error(w, "export not found", http.StatusNotFound)
+			return
+		}
+
+		store, err := getExportStore()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		rc, err := store.Get(ctx, key)
+		if err != nil {
+			log15.Warn("failed to read search export", "key", key, "error", err)
+			http.Error(w, "export not found", http.StatusNotFound)
+			return
+		}
+		defer rc.Close()
+
+		format := streamhttp.ExportFormatCSV
+		if strings.HasSuffix(key, streamhttp.ExportFormatJSONL.Extension()) {
+			format = streamhttp.ExportFormatJSONL
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "search-results"+format.Extension()))
+		if _, err := io.Copy(w, rc); err != nil {
+			log15.Warn("failed to write search export", "key", key, "error", err)
+		}
+	})
+}
+
+var (
+	metricExportRows = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "src_search_export_rows",
+		Help:    "Histogram with the number of rows in search result exports",
+		Buckets: prometheus.ExponentialBuckets(10, 10, 7),
+	})
+
+	metricExportDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "src_search_export_duration_seconds",
+		Help:    "Histogram with the time to export search results in seconds",
+		Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+	})
+)
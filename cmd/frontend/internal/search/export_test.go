@@ -0,0 +1,157 @@
+package search
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/client"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/search/run"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestServeStream_export(t *testing.T) {
+	graphqlbackend.MockDecodedViewerFinalSettings = &schema.Settings{}
+	t.Cleanup(func() { graphqlbackend.MockDecodedViewerFinalSettings = nil })
+
+	mock := client.NewMockSearchClient()
+	mock.PlanFunc.SetDefaultReturn(&run.SearchInputs{}, nil)
+	mock.ExecuteFunc.SetDefaultHook(func(_ context.Context, s streaming.Sender, _ *run.SearchInputs) (*search.Alert, error) {
+		s.Send(streaming.SearchEvent{
+			Results: result.Matches{
+				&result.FileMatch{
+					File: result.File{
+						Repo:     types.MinimalRepo{ID: 1, Name: "github.com/a/b"},
+						CommitID: "deadbeef",
+						Path:     "main.go",
+					},
+					ChunkMatches: result.ChunkMatches{{
+						Content:      "func main() {",
+						ContentStart: result.Location{Offset: 20, Line: 2, Column: 0},
+						Ranges: result.Ranges{{
+							Start: result.Location{Offset: 25, Line: 2, Column: 5},
+							End:   result.Location{Offset: 29, Line: 2, Column: 9},
+						}},
+					}},
+				},
+				&result.FileMatch{
+					File: result.File{
+						Repo: types.MinimalRepo{ID: 2, Name: "github.com/private/repo"},
+						Path: "secret.go",
+					},
+				},
+			},
+			Stats: streaming.Stats{IsLimitHit: true},
+		})
+		return nil, nil
+	})
+
+	// Only the first repository is visible to the actor
+	mockRepos := database.NewMockRepoStore()
+	mockRepos.MetadataFunc.SetDefaultReturn([]*types.SearchedRepo{{ID: 1, Name: "github.com/a/b"}}, nil)
+	db := database.NewMockDB()
+	db.ReposFunc.SetDefaultReturn(mockRepos)
+
+	ts := httptest.NewServer(&streamHandler{
+		db:           db,
+		searchClient: mock,
+	})
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "?q=main&export=csv&columns=repository,path,line,content")
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/csv", res.Header.Get("Content-Type"))
+	require.Equal(t, ""+
+		"repository,path,line,content\n"+
+		"github.com/a/b,main.go,3,func main() {\n",
+		string(body))
+	require.Equal(t, "1", res.Trailer.Get(exportRowsTrailer))
+	require.Equal(t, "true", res.Trailer.Get(exportLimitHitTrailer))
+	require.Empty(t, res.Trailer.Get(exportErrorTrailer))
+}
+
+func TestServeStream_exportInvalidColumn(t *testing.T) {
+	ts := httptest.NewServer(&streamHandler{searchClient: client.NewMockSearchClient()})
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "?q=main&export=csv&columns=stars")
+	require.NoError(t, err)
+	res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestServeStream_exportUpload(t *testing.T) {
+	graphqlbackend.MockDecodedViewerFinalSettings = &schema.Settings{}
+	t.Cleanup(func() { graphqlbackend.MockDecodedViewerFinalSettings = nil })
+
+	mock := client.NewMockSearchClient()
+	mock.PlanFunc.SetDefaultReturn(&run.SearchInputs{}, nil)
+
+	ts := httptest.NewServer(&streamHandler{searchClient: mock})
+	defer ts.Close()
+
+	// Uploads require an authenticated user and a configured export store
+	res, err := http.Get(ts.URL + "?q=main&export=jsonl&upload=true")
+	require.NoError(t, err)
+	res.Body.Close()
+
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	require.Empty(t, mock.ExecuteFunc.History())
+}
+
+func TestServeStream_exportInvalidUpload(t *testing.T) {
+	ts := httptest.NewServer(&streamHandler{searchClient: client.NewMockSearchClient()})
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "?q=main&export=csv&upload=maybe")
+	require.NoError(t, err)
+	res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestServeStream_exportError(t *testing.T) {
+	graphqlbackend.MockDecodedViewerFinalSettings = &schema.Settings{}
+	t.Cleanup(func() { graphqlbackend.MockDecodedViewerFinalSettings = nil })
+
+	mock := client.NewMockSearchClient()
+	mock.PlanFunc.SetDefaultReturn(&run.SearchInputs{}, nil)
+	mock.ExecuteFunc.SetDefaultReturn(&search.Alert{Title: "first\r\nsecond"}, errors.New("failed:\r\nX-Injected: true"))
+
+	ts := httptest.NewServer(&streamHandler{searchClient: mock})
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "?q=main&export=csv")
+	require.NoError(t, err)
+	_, err = io.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	// The response has already started, so the error is reported in a trailer
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "internal-error", res.Trailer.Get(exportErrorTrailer))
+	require.Equal(t, "first  second", res.Trailer.Get(exportAlertTrailer))
+	require.Empty(t, res.Trailer.Get("X-Injected"))
+}
+
+func TestExportKey(t *testing.T) {
+	require.Equal(t, "42/abc.jsonl", exportKey(42, "abc", streamhttp.ExportFormatJSONL))
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
 	"github.com/sourcegraph/sourcegraph/internal/honey"
 	searchhoney "github.com/sourcegraph/sourcegraph/internal/honey/search"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
@@ -61,6 +62,20 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer tr.Finish()
 	r = r.WithContext(ctx)
 
+	if r.URL.Query().Get("export") != "" {
+		args, err := parseURLQuery(r.URL.Query())
+		if err != nil {
+			err = &badRequestError{err}
+		} else {
+			err = h.serveExport(w, r, tr, args)
+		}
+		if err != nil {
+			tr.SetError(err)
+			http.Error(w, err.Error(), errcode.HTTP(err))
+		}
+		return
+	}
+
 	streamWriter, err := streamhttp.NewWriter(w)
 	if err != nil {
 		tr.SetError(err)
@@ -209,6 +224,12 @@ type args struct {
 	DecorationLimit        int    // The initial number of files to decorate in the result set.
 	DecorationKind         string // The kind of decoration to apply (HTML highlighting, plaintext, etc.)
 	DecorationContextLines int    // The number of lines of context to include around lines with matches.
+
+	// Optional export parameters for writing the result set as a file rather than
+	// as a stream of events.
+	Export        string // The export format (csv or jsonl). Events are streamed if empty.
+	ExportColumns string // A comma-separated list of columns to export.
+	ExportUpload  bool   // Whether to write the export to the export store and return a download link.
 }
 
 func parseURLQuery(q url.Values) (*args, error) {
@@ -225,6 +246,8 @@ func parseURLQuery(q url.Values) (*args, error) {
 		Version:        get("v", "V2"),
 		PatternType:    get("t", ""),
 		DecorationKind: get("dk", "html"),
+		Export:         get("export", ""),
+		ExportColumns:  get("columns", ""),
 	}
 
 	if a.Query == "" {
@@ -252,6 +275,11 @@ func parseURLQuery(q url.Values) (*args, error) {
 		return nil, errors.Errorf("decorationContextLines must be an integer, got %q: %w", decorationContextLines, err)
 	}
 
+	exportUpload := get("upload", "f")
+	if a.ExportUpload, err = strconv.ParseBool(exportUpload); err != nil {
+		return nil, errors.Errorf("upload must be parseable as a boolean, got %q: %w", exportUpload, err)
+	}
+
 	return &a, nil
 }
 
@@ -291,11 +319,33 @@ func fromMatch(match result.Match, repoCache map[api.RepoID]*types.SearchedRepo,
 		return fromRepository(v, repoCache)
 	case *result.CommitMatch:
 		return fromCommit(v, repoCache)
+	case *result.OwnerMatch:
+		return fromOwner(v)
+	case *result.AuthorMatch:
+		return fromAuthor(v)
 	default:
 		panic(fmt.Sprintf("unknown match type %T", v))
 	}
 }
 
+func fromOwner(o *result.OwnerMatch) *streamhttp.EventOwnerMatch {
+	return &streamhttp.EventOwnerMatch{
+		Type:         streamhttp.OwnerMatchType,
+		Handle:       o.Handle,
+		Repository:   string(o.Repo.Name),
+		RepositoryID: int32(o.Repo.ID),
+	}
+}
+
+func fromAuthor(a *result.AuthorMatch) *streamhttp.EventAuthorMatch {
+	return &streamhttp.EventAuthorMatch{
+		Type:  streamhttp.AuthorMatchType,
+		Name:  a.Name,
+		Email: a.Email,
+		Count: a.Count,
+	}
+}
+
 func fromFileMatch(fm *result.FileMatch, repoCache map[api.RepoID]*types.SearchedRepo, enableChunkMatches bool) streamhttp.EventMatch {
 	if len(fm.Symbols) > 0 {
 		return fromSymbolMatch(fm, repoCache)
@@ -547,7 +597,10 @@ func GuessSource(r *http.Request) trace.SourceType {
 func repoIDs(results []result.Match) []api.RepoID {
 	ids := make(map[api.RepoID]struct{}, 5)
 	for _, result := range results {
-		ids[result.RepoName().ID] = struct{}{}
+		// Author matches are not associated with a repo.
+		if id := result.RepoName().ID; id != 0 {
+			ids[id] = struct{}{}
+		}
 	}
 
 	res := make([]api.RepoID, 0, len(ids))
@@ -651,9 +704,12 @@ func (h *eventHandler) Send(event streaming.SearchEvent) {
 
 		// Don't send matches which we cannot map to a repo the actor has access to. This
 		// check is expected to always pass. Missing metadata is a sign that we have
-		// searched repos that user shouldn't have access to.
-		if md, ok := repoMetadata[repo.ID]; !ok || md.Name != repo.Name {
-			continue
+		// searched repos that user shouldn't have access to. Author matches aggregate
+		// matched lines across repositories and so are not associated with a repo.
+		if _, ok := match.(*result.AuthorMatch); !ok {
+			if md, ok := repoMetadata[repo.ID]; !ok || md.Name != repo.Name {
+				continue
+			}
 		}
 
 		eventMatch := fromMatch(match, repoMetadata, h.enableChunkMatches)
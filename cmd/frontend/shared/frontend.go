@@ -7,6 +7,7 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/enterprise"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/cli"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/conf/conftypes"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/env"
@@ -20,6 +21,8 @@ import (
 // main package implementations such as Sourcegraph Enterprise, which import
 // proprietary/private code.
 func Main(enterpriseSetupHook func(db database.DB, conf conftypes.UnifiedWatchable) enterprise.Services) {
+	search.LoadExportStoreConfig()
+
 	env.Lock()
 	err := cli.Main(enterpriseSetupHook)
 	if err != nil {
@@ -47,6 +47,8 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gomodproxy"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/npm"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/pypi"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/rubygems"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/backupstore"
 	"github.com/sourcegraph/sourcegraph/internal/goroutine"
 	"github.com/sourcegraph/sourcegraph/internal/hostname"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
@@ -72,12 +74,19 @@ var (
 	syncRepoStateInterval          = env.MustGetDuration("SRC_REPOS_SYNC_STATE_INTERVAL", 10*time.Minute, "Interval between state syncs")
 	syncRepoStateBatchSize         = env.MustGetInt("SRC_REPOS_SYNC_STATE_BATCH_SIZE", 500, "Number of upserts to perform per batch")
 	syncRepoStateUpsertPerSecond   = env.MustGetInt("SRC_REPOS_SYNC_STATE_UPSERT_PER_SEC", 500, "The number of upserted rows allowed per second across all gitserver instances")
+	syncReplicasInterval           = env.MustGetDuration("SRC_REPOS_SYNC_REPLICAS_INTERVAL", 1*time.Minute, "Interval between syncs of repository replicas with their primary")
 	batchLogGlobalConcurrencyLimit = env.MustGetInt("SRC_BATCH_LOG_GLOBAL_CONCURRENCY_LIMIT", 256, "The maximum number of in-flight Git commands from all /batch-log requests combined")
 
 	// 80 per second (4800 per minute) is well below our alert threshold of 30k per minute.
 	rateLimitSyncerLimitPerSecond = env.MustGetInt("SRC_REPOS_SYNC_RATE_LIMIT_RATE_PER_SECOND", 80, "Rate limit applied to rate limit syncing")
 )
 
+var backupStoreConfig = &backupstore.Config{}
+
+func init() {
+	backupStoreConfig.Load()
+}
+
 func main() {
 	ctx := context.Background()
 
@@ -151,6 +160,16 @@ func main() {
 	}
 	gitserver.RegisterMetrics(db, observationContext)
 
+	if backupStoreConfig.Enabled() {
+		if err := backupStoreConfig.Validate(); err != nil {
+			logger.Fatal("invalid backup store configuration", log.Error(err))
+		}
+		gitserver.BackupStore, err = backupstore.New(ctx, backupStoreConfig, observationContext)
+		if err != nil {
+			logger.Fatal("failed to create backup store", log.Error(err))
+		}
+	}
+
 	if tmpDir, err := gitserver.SetupAndClearTmp(); err != nil {
 		logger.Fatal("failed to setup temporary directory", log.Error(err))
 	} else if err := os.Setenv("TMP_DIR", tmpDir); err != nil {
@@ -182,6 +201,7 @@ func main() {
 	go debugserver.NewServerRoutine(ready).Start()
 	go gitserver.Janitor(janitorInterval)
 	go gitserver.SyncRepoState(syncRepoStateInterval, syncRepoStateBatchSize, syncRepoStateUpsertPerSecond)
+	go gitserver.SyncReplicas(syncReplicasInterval)
 
 	gitserver.StartClonePipeline(ctx)
 
@@ -476,6 +496,14 @@ func getVCSSyncer(
 		}
 		cli := pypi.NewClient(urn, c.Urls, httpcli.ExternalDoer)
 		return server.NewPythonPackagesSyncer(&c, depsSvc, cli), nil
+	case extsvc.TypeRubyPackages:
+		var c schema.RubyPackagesConnection
+		urn, err := extractOptions(&c)
+		if err != nil {
+			return nil, err
+		}
+		cli := rubygems.NewClient(urn, c.Repository, httpcli.ExternalDoer)
+		return server.NewRubyPackagesSyncer(&c, depsSvc, cli), nil
 	case extsvc.TypeRustPackages:
 		var c schema.RustPackagesConnection
 		urn, err := extractOptions(&c)
@@ -0,0 +1,435 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/uploadstore"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// backupInterval is how often a repository is backed up. Repositories whose refs have not
+// changed since their last backup are skipped.
+var backupInterval = env.MustGetDuration("SRC_REPOS_BACKUP_INTERVAL", 24*time.Hour, "how often repositories are backed up to GITSERVER_BACKUP_UPLOAD_BACKEND")
+
+// maxIncrementalBackups is the number of incremental backups that are written after a full
+// backup before the next full backup is written. Restoring a repository has to fetch every
+// bundle since the last full backup.
+var maxIncrementalBackups = env.MustGetInt("SRC_REPOS_BACKUP_MAX_INCREMENTAL", 6, "the maximum number of incremental repository backups written between full backups")
+
+// gitConfigBackupTimestamp is the key in git config that stores when a repository was last
+// backed up.
+const gitConfigBackupTimestamp = "sourcegraph.backupTimestamp"
+
+var (
+	repoBackupsWritten = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "src_gitserver_repo_backups",
+		Help: "number of repository bundle backups written to the backup store",
+	}, []string{"type"})
+	repoBackupsRestored = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "src_gitserver_repo_restores",
+		Help: "number of repositories restored from bundle backups",
+	}, []string{"reason"})
+)
+
+// errNoBackup is returned by restoreRepo if there is no backup of the repository.
+var errNoBackup = errors.New("no backup of repository")
+
+// restoreReason is why a repository is restored from a backup instead of cloned from its
+// code host.
+type restoreReason string
+
+const (
+	// restoreNewOwner is used when the repository was cloned on another gitserver shard
+	// before. Restoring from the backup and fetching the changes since is cheaper for the
+	// code host than a full clone.
+	restoreNewOwner restoreReason = "new-owner"
+	// restoreNotCloneable is used when the code host reports that the repository cannot be
+	// cloned, e.g. because it is down or the repository was deleted upstream.
+	restoreNotCloneable restoreReason = "not-cloneable"
+	// restoreCloneFailed is used when cloning the repository from the code host failed.
+	restoreCloneFailed restoreReason = "clone-failed"
+)
+
+// backupsEnabled returns true if a store for bundle backups of repositories is configured.
+func (s *Server) backupsEnabled() bool {
+	return s.BackupStore != nil && s.DB != nil
+}
+
+// backupRepo writes a bundle of the repository in dir to the backup store. The bundle is
+// incremental, i.e. it only contains the objects that are not reachable from the refs of
+// the previous backup, unless there are already maxIncrementalBackups incremental backups
+// since the last full backup. Nothing is written if the refs of the repository have not
+// changed since the previous backup.
+func (s *Server) backupRepo(ctx context.Context, dir GitDir) error {
+	name := s.name(dir)
+	logger := s.Logger.Scoped("backupRepo", "writes a bundle backup of a repository").With(log.String("repo", string(name)))
+
+	repo, err := s.DB.Repos().GetByName(ctx, name)
+	if err != nil {
+		if errcode.IsNotFound(err) {
+			// The repository was deleted and will be removed by the janitor.
+			return nil
+		}
+		return err
+	}
+
+	refs, err := listRefs(ctx, dir)
+	if err != nil {
+		return err
+	}
+	if len(refs) == 0 {
+		// git refuses to write bundles of empty repositories.
+		return nil
+	}
+	headRef, err := quickSymbolicRefHead(dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to read HEAD")
+	}
+
+	store := newRepoBackupStore(s.DB)
+	chain, err := store.latestChain(ctx, repo.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list backups")
+	}
+
+	backup := &repoBackup{
+		RepoID:  repo.ID,
+		HeadRef: headRef,
+		Refs:    refs,
+	}
+
+	tmpDir, err := s.tempDir("backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, "repo.bundle")
+
+	if len(chain) > 0 {
+		last := chain[len(chain)-1]
+		if last.HeadRef == headRef && equalStrings(last.Refs, refs) {
+			return nil
+		}
+
+		if len(chain) <= maxIncrementalBackups {
+			err := createBundle(ctx, dir, path, refObjectNames(last.Refs))
+			if err == nil {
+				backup.BaseID = chain[0].ID
+			} else {
+				// The objects of the previous backup may no longer exist, e.g. after a force
+				// push and garbage collection, or there are no new objects. We write a full
+				// backup instead.
+				logger.Debug("failed to write incremental bundle, writing full bundle", log.Error(err))
+			}
+		}
+	}
+	if backup.BaseID == 0 {
+		if err := createBundle(ctx, dir, path, nil); err != nil {
+			return err
+		}
+	}
+
+	backup.Key = fmt.Sprintf("%d/%s.bundle", repo.ID, uuid.New())
+	if backup.SizeBytes, err = uploadFile(ctx, s.BackupStore, backup.Key, path); err != nil {
+		return errors.Wrap(err, "failed to upload bundle")
+	}
+
+	if backup.ID, err = store.insert(ctx, backup); err != nil {
+		if err := s.BackupStore.Delete(ctx, backup.Key); err != nil {
+			logger.Warn("failed to delete unrecorded bundle", log.String("key", backup.Key), log.Error(err))
+		}
+		return errors.Wrap(err, "failed to record backup")
+	}
+
+	backupType := "incremental"
+	if backup.BaseID == 0 {
+		backupType = "full"
+
+		// A full backup supersedes all previous backups of the repository.
+		keys, err := store.deleteSuperseded(ctx, repo.ID, backup.ID)
+		if err != nil {
+			logger.Warn("failed to delete superseded backups", log.Error(err))
+		}
+		for _, key := range keys {
+			if err := s.BackupStore.Delete(ctx, key); err != nil {
+				logger.Warn("failed to delete superseded bundle", log.String("key", key), log.Error(err))
+			}
+		}
+	}
+	repoBackupsWritten.WithLabelValues(backupType).Inc()
+	logger.Debug("repo backed up", log.String("type", backupType), log.Int64("size-bytes", backup.SizeBytes))
+
+	return nil
+}
+
+// restoreRepo restores the repository from its latest full backup and the incremental
+// backups based on it into the bare repository at tmp, which must not exist yet. It returns
+// errNoBackup if the repository has not been backed up.
+func (s *Server) restoreRepo(ctx context.Context, repo api.RepoName, tmp GitDir) error {
+	chain, err := s.latestBackupChain(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if len(chain) == 0 {
+		return errNoBackup
+	}
+
+	for i, backup := range chain {
+		path := filepath.Join(filepath.Dir(string(tmp)), fmt.Sprintf("%d.bundle", backup.ID))
+		if err := downloadFile(ctx, s.BackupStore, backup.Key, path); err != nil {
+			return errors.Wrapf(err, "failed to download bundle %s", backup.Key)
+		}
+
+		var cmd *exec.Cmd
+		if i == 0 {
+			cmd = exec.CommandContext(ctx, "git", "clone", "--mirror", path, string(tmp))
+		} else {
+			cmd = exec.CommandContext(ctx, "git", "fetch", path, "+refs/*:refs/*")
+			tmp.Set(cmd)
+		}
+		if _, err := cmd.Output(); err != nil {
+			return errors.Wrap(wrapCmdError(cmd, err), "failed to restore bundle")
+		}
+
+		// The bundle is no longer needed and may be large.
+		_ = os.Remove(path)
+	}
+
+	// Fetching a bundle does not delete refs that were deleted since the previous bundle,
+	// so we make the refs match the latest backup exactly.
+	latest := chain[len(chain)-1]
+	if err := updateRefs(ctx, tmp, latest.Refs); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", "HEAD", latest.HeadRef)
+	tmp.Set(cmd)
+	if _, err := cmd.Output(); err != nil {
+		return errors.Wrap(wrapCmdError(cmd, err), "failed to set HEAD")
+	}
+
+	// We don't want to store the path of the bundle as remote.
+	cmd = exec.CommandContext(ctx, "git", "remote", "remove", "origin")
+	tmp.Set(cmd)
+	_ = cmd.Run()
+
+	return nil
+}
+
+// latestBackupChain returns the backups that are needed to restore the repository, or nil
+// if backups are disabled or the repository has not been backed up.
+func (s *Server) latestBackupChain(ctx context.Context, repo api.RepoName) ([]*repoBackup, error) {
+	if !s.backupsEnabled() {
+		return nil, nil
+	}
+
+	r, err := s.DB.Repos().GetByName(ctx, repo)
+	if err != nil {
+		if errcode.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	chain, err := newRepoBackupStore(s.DB).latestChain(ctx, r.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list backups")
+	}
+	return chain, nil
+}
+
+// hasBackup returns true if the repository can be restored from a backup. Errors are
+// logged and treated as if there is no backup.
+func (s *Server) hasBackup(ctx context.Context, repo api.RepoName) bool {
+	chain, err := s.latestBackupChain(ctx, repo)
+	if err != nil {
+		s.Logger.Warn("failed to look up backups", log.String("repo", string(repo)), log.Error(err))
+		return false
+	}
+	return len(chain) > 0
+}
+
+// isNewOwner returns true if the repository has been cloned on another gitserver shard
+// before, i.e. this shard took over ownership of the repository.
+func (s *Server) isNewOwner(ctx context.Context, repo api.RepoName) bool {
+	if s.DB == nil {
+		return false
+	}
+	gr, err := s.DB.GitserverRepos().GetByName(ctx, repo)
+	if err != nil {
+		return false
+	}
+	return gr.ShardID != "" && gr.ShardID != s.Hostname
+}
+
+// listRefs returns the refs of the repository as "<object name> <ref name>" pairs sorted by
+// ref name.
+func listRefs(ctx context.Context, dir GitDir) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "for-each-ref", "--format=%(objectname) %(refname)")
+	dir.Set(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(wrapCmdError(cmd, err), "failed to list refs")
+	}
+
+	var refs []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line != "" {
+			refs = append(refs, line)
+		}
+	}
+	return refs, nil
+}
+
+// refObjectNames returns the unique object names of the given refs.
+func refObjectNames(refs []string) []string {
+	seen := make(map[string]struct{}, len(refs))
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		name := strings.SplitN(ref, " ", 2)[0]
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	return names
+}
+
+// createBundle writes a bundle of all refs of the repository to path. The bundle does not
+// contain the objects that are reachable from the objects in exclude, which must exist in
+// the repository.
+func createBundle(ctx context.Context, dir GitDir, path string, exclude []string) error {
+	var stdin bytes.Buffer
+	for _, name := range exclude {
+		stdin.WriteString("^" + name + "\n")
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "bundle", "create", path, "--all", "--stdin")
+	dir.Set(cmd)
+	cmd.Stdin = &stdin
+	if _, err := cmd.Output(); err != nil {
+		return errors.Wrap(wrapCmdError(cmd, err), "failed to create bundle")
+	}
+	return nil
+}
+
+// updateRefs makes the refs of the repository match refs, which are "<object name> <ref
+// name>" pairs. Refs that are not in refs are deleted.
+func updateRefs(ctx context.Context, dir GitDir, refs []string) error {
+	current, err := listRefs(ctx, dir)
+	if err != nil {
+		return err
+	}
+
+	want := make(map[string]struct{}, len(refs))
+	var stdin bytes.Buffer
+	for _, ref := range refs {
+		parts := strings.SplitN(ref, " ", 2)
+		if len(parts) != 2 {
+			return errors.Errorf("invalid ref %q", ref)
+		}
+		want[parts[1]] = struct{}{}
+		fmt.Fprintf(&stdin, "update %s %s\n", parts[1], parts[0])
+	}
+	for _, ref := range current {
+		name := strings.SplitN(ref, " ", 2)[1]
+		if _, ok := want[name]; !ok {
+			fmt.Fprintf(&stdin, "delete %s\n", name)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "update-ref", "--stdin")
+	dir.Set(cmd)
+	cmd.Stdin = &stdin
+	if _, err := cmd.Output(); err != nil {
+		return errors.Wrap(wrapCmdError(cmd, err), "failed to update refs")
+	}
+	return nil
+}
+
+func uploadFile(ctx context.Context, store uploadstore.Store, key, path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return store.Upload(ctx, key, f)
+}
+
+func downloadFile(ctx context.Context, store uploadstore.Store, key, path string) error {
+	rc, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, rc); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// setBackupTime sets the time a repository was last backed up.
+func setBackupTime(dir GitDir, now time.Time) error {
+	err := gitConfigSet(dir, gitConfigBackupTimestamp, strconv.FormatInt(now.Unix(), 10))
+	if err != nil {
+		return errors.Wrap(err, "failed to update backupTimestamp")
+	}
+	return nil
+}
+
+// getBackupTime returns the time a repository was last backed up. If the value is not
+// stored in the repository, the zero time is returned so that the repository is backed up.
+func getBackupTime(dir GitDir) (time.Time, error) {
+	value, err := gitConfigGet(dir, gitConfigBackupTimestamp)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to determine backup timestamp")
+	}
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	sec, err := strconv.ParseInt(value, 10, 0)
+	if err != nil {
+		// If the value is bad, the repository is backed up again.
+		return time.Time{}, nil
+	}
+	return time.Unix(sec, 0), nil
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+)
+
+// repoBackup is a git bundle of a repository in the backup store.
+type repoBackup struct {
+	ID     int
+	RepoID api.RepoID
+	BaseID int // the full backup this incremental backup is based on; zero for full backups
+	Key    string
+
+	// HeadRef is the ref that HEAD pointed to when the bundle was written.
+	HeadRef string
+
+	// Refs are the refs of the repository when the bundle was written, as
+	// "<object name> <ref name>" pairs sorted by ref name.
+	Refs []string
+
+	SizeBytes int64
+	CreatedAt time.Time
+}
+
+// repoBackupStore tracks the bundles in the backup store in the database.
+type repoBackupStore struct {
+	*basestore.Store
+}
+
+func newRepoBackupStore(db database.DB) *repoBackupStore {
+	return &repoBackupStore{Store: basestore.NewWithHandle(db.Handle())}
+}
+
+// latestChain returns the latest full backup of the repo followed by the
+// incremental backups based on it, in the order in which they were written.
+func (s *repoBackupStore) latestChain(ctx context.Context, repoID api.RepoID) ([]*repoBackup, error) {
+	return basestore.NewSliceScanner(scanRepoBackup)(s.Query(ctx, sqlf.Sprintf(latestChainQuery, repoID)))
+}
+
+const latestChainQuery = `
+-- source: cmd/gitserver/server/backup_store.go:latestChain
+WITH base AS (
+	SELECT id
+	FROM gitserver_repo_backups
+	WHERE repo_id = %s AND base_id IS NULL
+	ORDER BY id DESC
+	LIMIT 1
+)
+SELECT b.id, b.repo_id, COALESCE(b.base_id, 0), b.key, b.head_ref, b.refs, b.size_bytes, b.created_at
+FROM gitserver_repo_backups b
+JOIN base ON b.id = base.id OR b.base_id = base.id
+ORDER BY b.id
+`
+
+func scanRepoBackup(sc dbutil.Scanner) (*repoBackup, error) {
+	var b repoBackup
+	err := sc.Scan(
+		&b.ID,
+		&b.RepoID,
+		&b.BaseID,
+		&b.Key,
+		&b.HeadRef,
+		pq.Array(&b.Refs),
+		&b.SizeBytes,
+		&b.CreatedAt,
+	)
+	return &b, err
+}
+
+// insert records a backup that has been written to the backup store and
+// returns its ID.
+func (s *repoBackupStore) insert(ctx context.Context, b *repoBackup) (int, error) {
+	id, _, err := basestore.ScanFirstInt(s.Query(ctx, sqlf.Sprintf(
+		insertRepoBackupQuery,
+		b.RepoID,
+		b.BaseID,
+		b.Key,
+		b.HeadRef,
+		pq.Array(b.Refs),
+		b.SizeBytes,
+	)))
+	return id, err
+}
+
+const insertRepoBackupQuery = `
+-- source: cmd/gitserver/server/backup_store.go:insert
+INSERT INTO gitserver_repo_backups (repo_id, base_id, key, head_ref, refs, size_bytes)
+VALUES (%s, NULLIF(%s, 0), %s, %s, %s, %s)
+RETURNING id
+`
+
+// deleteSuperseded deletes the backups of the repo that were written before
+// the full backup with the given ID, and returns their keys so that they can
+// be removed from the backup store.
+func (s *repoBackupStore) deleteSuperseded(ctx context.Context, repoID api.RepoID, id int) ([]string, error) {
+	return basestore.ScanStrings(s.Query(ctx, sqlf.Sprintf(deleteSupersededRepoBackupsQuery, repoID, id)))
+}
+
+const deleteSupersededRepoBackupsQuery = `
+-- source: cmd/gitserver/server/backup_store.go:deleteSuperseded
+DELETE FROM gitserver_repo_backups
+WHERE repo_id = %s AND id < %s
+RETURNING key
+`
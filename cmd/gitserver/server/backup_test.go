@@ -0,0 +1,254 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/log/logtest"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/uploadstore"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+func TestBackupAndRestoreRepo(t *testing.T) {
+	logger := logtest.Scoped(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	remote := t.TempDir()
+	repoName := api.RepoName("example.com/foo/bar")
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	dbRepo := &types.Repo{
+		Name:        repoName,
+		Description: "Test",
+	}
+	if err := db.Repos().Create(ctx, dbRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := remote
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		return runCmd(t, repo, name, arg...)
+	}
+	makeSingleCommitRepo(cmd)
+	cmd("git", "tag", "v1")
+
+	reposDir := t.TempDir()
+	s := makeTestServer(ctx, t, reposDir, remote, db)
+	store := newMemoryStore()
+	s.BackupStore = store
+
+	if _, err := s.cloneRepo(ctx, repoName, &cloneOptions{Block: true}); err != nil {
+		t.Fatal(err)
+	}
+	dir := s.dir(repoName)
+
+	backupStore := newRepoBackupStore(db)
+	assertChain := func(wantBaseIDs ...bool) []*repoBackup {
+		t.Helper()
+		chain, err := backupStore.latestChain(ctx, dbRepo.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chain) != len(wantBaseIDs) {
+			t.Fatalf("unexpected number of backups. want=%d have=%d", len(wantBaseIDs), len(chain))
+		}
+		for i, b := range chain {
+			if hasBase := b.BaseID != 0; hasBase != wantBaseIDs[i] {
+				t.Fatalf("unexpected base of backup %d. want incremental=%v have=%v", i, wantBaseIDs[i], hasBase)
+			}
+		}
+		return chain
+	}
+
+	// The first backup is a full backup
+	if err := s.backupRepo(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+	assertChain(false)
+
+	// Nothing is written if the refs did not change
+	if err := s.backupRepo(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+	assertChain(false)
+
+	// New commits and deleted refs are written to an incremental backup
+	cmd("sh", "-c", "echo goodbye world > hello.txt")
+	addCommitToRepo(cmd)
+	repo = filepath.Dir(string(dir))
+	cmd("git", "fetch", remote, "+refs/heads/*:refs/heads/*")
+	cmd("git", "tag", "-d", "v1")
+	if err := s.backupRepo(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+	chain := assertChain(false, true)
+	if chain[1].BaseID != chain[0].ID {
+		t.Fatalf("unexpected base of incremental backup. want=%d have=%d", chain[0].ID, chain[1].BaseID)
+	}
+
+	wantRefs, err := listRefs(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(wantRefs, chain[1].Refs); diff != "" {
+		t.Fatalf("unexpected refs of backup (-want +have):\n%s", diff)
+	}
+
+	// Restoring applies the incremental backup on top of the full backup
+	tmp := GitDir(filepath.Join(t.TempDir(), ".git"))
+	if err := s.restoreRepo(ctx, repoName, tmp); err != nil {
+		t.Fatal(err)
+	}
+	haveRefs, err := listRefs(ctx, tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(wantRefs, haveRefs); diff != "" {
+		t.Fatalf("unexpected refs of restored repo (-want +have):\n%s", diff)
+	}
+	wantHead, _ := quickSymbolicRefHead(dir)
+	if haveHead, _ := quickSymbolicRefHead(tmp); haveHead != wantHead {
+		t.Fatalf("unexpected HEAD of restored repo. want=%q have=%q", wantHead, haveHead)
+	}
+
+	// Once there are too many incremental backups, a full backup replaces all of them
+	old := maxIncrementalBackups
+	maxIncrementalBackups = 1
+	defer func() { maxIncrementalBackups = old }()
+
+	cmd("git", "tag", "v2")
+	if err := s.backupRepo(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+	assertChain(false)
+	if n := store.len(); n != 1 {
+		t.Fatalf("expected superseded bundles to be deleted. have %d bundles", n)
+	}
+
+	// Repos without backups cannot be restored
+	if err := s.restoreRepo(ctx, "example.com/foo/missing", GitDir(filepath.Join(t.TempDir(), ".git"))); !errors.Is(err, errNoBackup) {
+		t.Fatalf("unexpected error. want=%q have=%v", errNoBackup, err)
+	}
+}
+
+func TestCloneRepoRestoresBackup(t *testing.T) {
+	logger := logtest.Scoped(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	remote := t.TempDir()
+	repoName := api.RepoName("example.com/foo/bar")
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	dbRepo := &types.Repo{
+		Name:        repoName,
+		Description: "Test",
+	}
+	if err := db.Repos().Create(ctx, dbRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		return runCmd(t, remote, name, arg...)
+	}
+	wantCommit := makeSingleCommitRepo(cmd)
+
+	reposDir := t.TempDir()
+	s := makeTestServer(ctx, t, reposDir, remote, db)
+	s.BackupStore = newMemoryStore()
+
+	if _, err := s.cloneRepo(ctx, repoName, &cloneOptions{Block: true}); err != nil {
+		t.Fatal(err)
+	}
+	dir := s.dir(repoName)
+	if err := s.backupRepo(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// The repo is deleted on the code host, so it can only be restored from the backup
+	if err := os.RemoveAll(remote); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.cloneRepo(ctx, repoName, &cloneOptions{Block: true, Overwrite: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	gotCommit := runCmd(t, filepath.Dir(string(dir)), "git", "rev-parse", "HEAD")
+	if gotCommit != wantCommit {
+		t.Fatalf("unexpected HEAD of restored repo. want=%q have=%q", wantCommit, gotCommit)
+	}
+}
+
+// memoryStore is an upload store that keeps objects in memory.
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+var _ uploadstore.Store = &memoryStore{}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: map[string][]byte{}}
+}
+
+func (s *memoryStore) Init(ctx context.Context) error {
+	return nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.objects[key]
+	if !ok {
+		return nil, errors.Newf("object %q not found", key)
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (s *memoryStore) Upload(ctx context.Context, key string, r io.Reader) (int64, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = b
+	return int64(len(b)), nil
+}
+
+func (s *memoryStore) Compose(ctx context.Context, destination string, sources ...string) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (s *memoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memoryStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.objects)
+}
@@ -137,7 +137,8 @@ const reposStatsName = "repos-stats.json"
 // 8. Remove repos based on disk pressure.
 // 9. Perform sg-maintenance
 // 10. Git prune
-// 11. Only during first run: Set sizes of repos which don't have it in a database.
+// 11. Write bundle backups of repos
+// 12. Only during first run: Set sizes of repos which don't have it in a database.
 func (s *Server) cleanupRepos(gitServerAddrs []string) {
 	janitorRunning.Set(1)
 	janitorStart := time.Now()
@@ -191,7 +192,7 @@ func (s *Server) cleanupRepos(gitServerAddrs []string) {
 		// Record the number and disk usage used of repos that should
 		// not belong on this instance and remove up to SRC_WRONG_SHARD_DELETE_LIMIT in a single Janitor run.
 		addr := addrForKey(name, gitServerAddrs)
-		if !s.hostnameMatch(addr) {
+		if !s.hostnameMatch(addr) && !s.isReplica(bCtx, name) {
 			wrongShardRepoCount++
 			wrongShardRepoSize += size
 			if isKnownGitServerShard && wrongShardReposDeleteLimit > 0 && wrongShardReposDeleted < int64(wrongShardReposDeleteLimit) {
@@ -322,6 +323,36 @@ func (s *Server) cleanupRepos(gitServerAddrs []string) {
 		return true, nil
 	}
 
+	maybeBackup := func(dir GitDir) (done bool, err error) {
+		// Only the shard that owns a repository backs it up.
+		repo := s.name(dir)
+		if !s.hostnameMatch(addrForKey(repo, gitServerAddrs)) {
+			return false, nil
+		}
+
+		backupTime, err := getBackupTime(dir)
+		if err != nil {
+			return false, err
+		}
+		// Add a jitter to spread out backups of repos cloned at the same time.
+		if time.Since(backupTime) < backupInterval+jitterDuration(string(dir), backupInterval/4) {
+			return false, nil
+		}
+
+		ctx, cancel := context.WithTimeout(bCtx, conf.GitLongCommandTimeout())
+		defer cancel()
+
+		// update the backup time even if the backup fails so that we don't constantly
+		// retry backing up a repo that can't be backed up.
+		defer func() {
+			if err := setBackupTime(dir, time.Now()); err != nil {
+				cleanupLogger.Warn("setting backup time failed", log.String("repo", string(repo)), log.Error(err))
+			}
+		}()
+
+		return false, s.backupRepo(ctx, dir)
+	}
+
 	removeStaleLocks := func(gitDir GitDir) (done bool, err error) {
 		// if removing a lock fails, we still want to try the other locks.
 		var multi error
@@ -409,6 +440,16 @@ func (s *Server) cleanupRepos(gitServerAddrs []string) {
 		cleanups = append(cleanups, cleanupFn{"git prune", performGitPrune})
 	}
 
+	if s.backupsEnabled() {
+		// Periodically write bundles of repos to the backup store, so that they can be
+		// restored if the code host is unavailable or a new shard takes ownership of them.
+		// This runs before re-cloning so that a repo is not lost if the re-clone fails.
+		cleanups = append(cleanups, cleanupFn{
+			Name: "maybe backup",
+			Do:   maybeBackup,
+		})
+	}
+
 	if !conf.Get().DisableAutoGitUpdates {
 		// Old git clones accumulate loose git objects that waste space and slow down git
 		// operations. Periodically do a fresh clone to avoid these problems. git gc is
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// replicaPrimary returns the address of the gitserver instance that owns the repo if this
+// instance keeps a replica of it, and the empty string otherwise.
+func (s *Server) replicaPrimary(ctx context.Context, repo api.RepoName) string {
+	cfg := conf.Get()
+	if cfg.ExperimentalFeatures == nil {
+		return ""
+	}
+	repo = protocol.NormalizeRepo(repo)
+	factor := cfg.ExperimentalFeatures.GitServerReplicatedRepos[string(repo)]
+	if factor <= 1 {
+		return ""
+	}
+
+	addrs := cfg.ServiceConnectionConfig.GitServers
+	primary, err := gitserver.AddrForRepo(ctx, "gitserver", s.DB, repo, gitserver.GitServerAddresses{
+		Addresses:     addrs,
+		PinnedServers: cfg.ExperimentalFeatures.GitServerPinnedRepos,
+	})
+	if err != nil {
+		s.Logger.Warn("failed to determine primary of replicated repo", log.String("repo", string(repo)), log.Error(err))
+		return ""
+	}
+	for _, addr := range gitserver.ReplicaAddrsForRepo(primary, addrs, factor) {
+		if s.hostnameMatch(addr) {
+			return primary
+		}
+	}
+	return ""
+}
+
+// isReplica returns true if this instance keeps a replica of the repo. Replicas don't write
+// the state of the repo to the database, since it is owned by the primary.
+func (s *Server) isReplica(ctx context.Context, repo api.RepoName) bool {
+	return s.replicaPrimary(ctx, repo) != ""
+}
+
+// SyncReplicas clones and updates the replicas of repositories kept by this instance. It is
+// expected to run in a background goroutine.
+func (s *Server) SyncReplicas(interval time.Duration) {
+	for {
+		s.syncReplicas()
+		time.Sleep(interval)
+	}
+}
+
+func (s *Server) syncReplicas() {
+	cfg := conf.Get()
+	if cfg.ExperimentalFeatures == nil {
+		return
+	}
+
+	for name := range cfg.ExperimentalFeatures.GitServerReplicatedRepos {
+		repo := api.RepoName(name)
+		ctx, cancel := s.serverContext()
+		if primary := s.replicaPrimary(ctx, repo); primary != "" {
+			if err := s.syncReplica(ctx, repo); err != nil {
+				s.Logger.Warn("failed to sync replica", log.String("repo", name), log.String("primary", primary), log.Error(err))
+			}
+		}
+		cancel()
+	}
+}
+
+// syncReplica clones the replica of the repo if it doesn't exist yet, and updates it
+// otherwise.
+func (s *Server) syncReplica(ctx context.Context, repo api.RepoName) error {
+	if !repoCloned(s.dir(repo)) {
+		// cloneRepo clones replicas from their primary.
+		if _, err := s.cloneRepo(ctx, repo, &cloneOptions{Block: true}); err != nil {
+			return err
+		}
+	}
+	// The clone only contains the refs we fetch from code hosts, so we also update newly
+	// cloned replicas to mirror all refs of the primary.
+	return s.doRepoUpdate(ctx, repo)
+}
+
+// fetchFromPrimary updates the replica of the repo in dir to mirror the refs and HEAD of the
+// repo on the primary gitserver instance.
+func fetchFromPrimary(ctx context.Context, repo api.RepoName, dir GitDir, primary string) error {
+	remoteURL, err := vcs.ParseURL("http://" + primary + "/git/" + string(repo))
+	if err != nil {
+		return err
+	}
+
+	// We already have janitor jobs that run git gc, so we disable it here like for any other
+	// fetch.
+	cmd := exec.CommandContext(ctx, "git", "fetch", "--no-auto-gc", "--prune", remoteURL.String(), "+refs/*:refs/*")
+	dir.Set(cmd)
+	if output, err := runWith(ctx, cmd, false, nil); err != nil {
+		return errors.Wrapf(err, "failed to fetch from primary with output %q", string(output))
+	}
+
+	removeBadRefs(ctx, dir)
+
+	if err := setHEAD(ctx, dir, &GitRepoSyncer{}, repo, remoteURL); err != nil {
+		return errors.Wrap(err, "failed to ensure HEAD exists")
+	}
+	return nil
+}
+
+// handleRefState responds with a hash of the refs of a repository, which clients use to
+// check that a replica is up to date with its primary.
+func (s *Server) handleRefState(w http.ResponseWriter, r *http.Request) {
+	var req protocol.RefStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var resp protocol.RefStateResponse
+	dir := s.dir(req.Repo)
+	if repoCloned(dir) {
+		hash, err := refStateHash(r.Context(), dir)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp.Cloned = true
+		resp.Hash = hash
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// refStateHash returns a hash of HEAD and all refs of the repository in dir.
+func refStateHash(ctx context.Context, dir GitDir) (string, error) {
+	head, err := os.ReadFile(dir.Path("HEAD"))
+	if err != nil {
+		return "", err
+	}
+	refs, err := listRefs(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write(bytes.TrimSpace(head))
+	h.Write([]byte{'\n'})
+	for _, ref := range refs {
+		h.Write([]byte(ref))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
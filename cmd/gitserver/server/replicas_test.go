@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/log/logtest"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/conf/conftypes"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestSyncReplica(t *testing.T) {
+	logger := logtest.Scoped(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reposDirPrimary := t.TempDir()
+	remote := filepath.Join(reposDirPrimary, "example.com/foo/bar")
+	os.MkdirAll(remote, 0755)
+	repoName := api.RepoName("example.com/foo/bar")
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	dbRepo := &types.Repo{
+		Name:        repoName,
+		Description: "Test",
+	}
+	if err := db.Repos().Create(ctx, dbRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		return runCmd(t, remote, name, arg...)
+	}
+	makeSingleCommitRepo(cmd)
+	cmd("git", "tag", "v1")
+
+	// The primary serves the repo directly from the directory it was created in.
+	primary := makeTestServer(ctx, t, reposDirPrimary, remote, db)
+	srv := httptest.NewServer(primary.Handler())
+	defer srv.Close()
+	primaryAddr := strings.TrimPrefix(srv.URL, "http://")
+
+	// example.com/foo/bar is owned by the first instance.
+	conf.Mock(&conf.Unified{
+		SiteConfiguration: schema.SiteConfiguration{
+			ExperimentalFeatures: &schema.ExperimentalFeatures{
+				GitServerReplicatedRepos: map[string]int{string(repoName): 2},
+			},
+		},
+		ServiceConnectionConfig: conftypes.ServiceConnections{
+			GitServers: []string{primaryAddr, "replica"},
+		},
+	})
+	defer conf.Mock(nil)
+
+	// The replica has no remote URL, so it can only fetch from the primary.
+	s := makeTestServer(ctx, t, t.TempDir(), "", db)
+	s.Hostname = "replica"
+	_ = s.Handler()
+
+	if have := s.replicaPrimary(ctx, repoName); have != primaryAddr {
+		t.Fatalf("unexpected primary. want=%q have=%q", primaryAddr, have)
+	}
+
+	assertInSync := func(want bool) {
+		t.Helper()
+		wantHash, err := refStateHash(ctx, primary.dir(repoName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		haveHash, err := refStateHash(ctx, s.dir(repoName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inSync := wantHash == haveHash; inSync != want {
+			t.Fatalf("unexpected ref state of replica. want in sync=%v have in sync=%v", want, inSync)
+		}
+	}
+
+	// The first sync clones the replica
+	if err := s.syncReplica(ctx, repoName); err != nil {
+		t.Fatal(err)
+	}
+	assertInSync(true)
+
+	// New refs on the primary are fetched by the next sync, including refs we don't fetch
+	// from code hosts.
+	cmd("git", "tag", "v2")
+	cmd("git", "update-ref", "refs/sourcegraph/test", "HEAD")
+	assertInSync(false)
+	if err := s.syncReplica(ctx, repoName); err != nil {
+		t.Fatal(err)
+	}
+	assertInSync(true)
+
+	// Deleted refs are pruned
+	cmd("git", "tag", "-d", "v1")
+	if err := s.syncReplica(ctx, repoName); err != nil {
+		t.Fatal(err)
+	}
+	assertInSync(true)
+
+	// The replica doesn't claim the repo in the database
+	fromDB, err := db.GitserverRepos().GetByID(ctx, dbRepo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromDB.ShardID == s.Hostname {
+		t.Fatalf("expected replica not to set the shard of the repo")
+	}
+}
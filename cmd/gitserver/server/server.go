@@ -58,6 +58,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
 	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/uploadstore"
 	"github.com/sourcegraph/sourcegraph/internal/vcs"
 	"github.com/sourcegraph/sourcegraph/lib/errors"
 )
@@ -223,6 +224,10 @@ type Server struct {
 	// shared db handle
 	DB database.DB
 
+	// BackupStore is the store that bundle backups of repositories are written to and
+	// restored from. Repositories are not backed up if it is nil.
+	BackupStore uploadstore.Store
+
 	// CloneQueue is a threadsafe queue used by DoBackgroundClones to process incoming clone
 	// requests asynchronously.
 	CloneQueue *cloneQueue
@@ -371,6 +376,7 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/list-gitolite", trace.WithRouteName("list-gitolite", s.handleListGitolite))
 	mux.HandleFunc("/is-repo-cloneable", trace.WithRouteName("is-repo-cloneable", s.handleIsRepoCloneable))
 	mux.HandleFunc("/is-repo-cloned", trace.WithRouteName("is-repo-cloned", s.handleIsRepoCloned))
+	mux.HandleFunc("/ref-state", trace.WithRouteName("ref-state", s.handleRefState))
 	mux.HandleFunc("/repos", trace.WithRouteName("repos", s.handleRepoInfo))
 	mux.HandleFunc("/repos-stats", trace.WithRouteName("repos-stats", s.handleReposStats))
 	mux.HandleFunc("/repo-clone-progress", trace.WithRouteName("repo-clone-progress", s.handleRepoCloneProgress))
@@ -1759,14 +1765,14 @@ func (s *Server) p4exec(w http.ResponseWriter, r *http.Request, req *protocol.P4
 }
 
 func (s *Server) setLastError(ctx context.Context, name api.RepoName, error string) (err error) {
-	if s.DB == nil {
+	if s.DB == nil || s.isReplica(ctx, name) {
 		return nil
 	}
 	return s.DB.GitserverRepos().SetLastError(ctx, name, error, s.Hostname)
 }
 
 func (s *Server) setLastFetched(ctx context.Context, name api.RepoName) error {
-	if s.DB == nil {
+	if s.DB == nil || s.isReplica(ctx, name) {
 		return nil
 	}
 
@@ -1801,7 +1807,7 @@ func (s *Server) setLastErrorNonFatal(ctx context.Context, name api.RepoName, er
 }
 
 func (s *Server) setCloneStatus(ctx context.Context, name api.RepoName, status types.CloneStatus) (err error) {
-	if s.DB == nil {
+	if s.DB == nil || s.isReplica(ctx, name) {
 		return nil
 	}
 	return s.DB.GitserverRepos().SetCloneStatus(ctx, name, status, s.Hostname)
@@ -1816,7 +1822,7 @@ func (s *Server) setCloneStatusNonFatal(ctx context.Context, name api.RepoName,
 
 // setRepoSize calculates the size of the repo and stores it in the database.
 func (s *Server) setRepoSize(ctx context.Context, name api.RepoName) error {
-	if s.DB == nil {
+	if s.DB == nil || s.isReplica(ctx, name) {
 		return nil
 	}
 
@@ -1864,6 +1870,10 @@ type cloneOptions struct {
 	// repository. If this is a non-zero string, then gitserver will attempt to clone the repo from
 	// that gitserver instance instead of the upstream repo URL of the external service.
 	CloneFromShard string
+
+	// RestoreFromBackup is set if the repository is restored from its latest backup instead of
+	// cloned. It is set by cloneRepo.
+	RestoreFromBackup restoreReason
 }
 
 // cloneRepo performs a clone operation for the given repository. It is
@@ -1894,6 +1904,18 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, opts *cloneOp
 		return "", errors.Wrap(err, "get VCS syncer")
 	}
 
+	if opts == nil || opts.CloneFromShard == "" {
+		// Replicas are cloned from the gitserver instance that owns the repo.
+		if primary := s.replicaPrimary(ctx, repo); primary != "" {
+			o := cloneOptions{}
+			if opts != nil {
+				o = *opts
+			}
+			o.CloneFromShard = "http://" + primary
+			opts = &o
+		}
+	}
+
 	var remoteURL *vcs.URL
 	if opts != nil && opts.CloneFromShard != "" {
 		// are we cloning from the same gitserver instance?
@@ -1925,9 +1947,26 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, opts *cloneOp
 		return "", err
 	}
 
-	if err := syncer.IsCloneable(ctx, remoteURL); err != nil {
+	var restore restoreReason
+	if s.backupsEnabled() && (opts == nil || opts.CloneFromShard == "") && s.isNewOwner(ctx, repo) && s.hasBackup(ctx, repo) {
+		// Another shard owned the repository before, so we restore it from the backup and
+		// fetch the changes since rather than cloning it from the code host.
+		restore = restoreNewOwner
+	} else if err := syncer.IsCloneable(ctx, remoteURL); err != nil {
 		redactedErr := newURLRedactor(remoteURL).redact(err.Error())
-		return "", errors.Errorf("error cloning repo: repo %s not cloneable: %s", repo, redactedErr)
+		if !s.hasBackup(ctx, repo) {
+			return "", errors.Errorf("error cloning repo: repo %s not cloneable: %s", repo, redactedErr)
+		}
+		s.Logger.Warn("repo not cloneable, restoring from backup", log.String("repo", string(repo)), log.String("error", redactedErr))
+		restore = restoreNotCloneable
+	}
+	if restore != "" {
+		o := cloneOptions{}
+		if opts != nil {
+			o = *opts
+		}
+		o.RestoreFromBackup = restore
+		opts = &o
 	}
 
 	// Mark this repo as currently being cloned. We have to check again if someone else isn't already
@@ -2020,25 +2059,23 @@ func (s *Server) doClone(ctx context.Context, repo api.RepoName, dir GitDir, syn
 		s.setCloneStatusNonFatal(context.Background(), repo, cloneStatus(repoCloned(dir), false))
 	}()
 
-	cmd, err := syncer.CloneCommand(ctx, remoteURL, tmpPath)
-	if err != nil {
-		return errors.Wrap(err, "get clone command")
-	}
-	if cmd.Env == nil {
-		cmd.Env = os.Environ()
-	}
-
-	// see issue #7322: skip LFS content in repositories with Git LFS configured
-	cmd.Env = append(cmd.Env, "GIT_LFS_SKIP_SMUDGE=1")
-	s.Logger.Info("cloning repo", log.String("repo", string(repo)), log.String("tmp", tmpPath), log.String("dst", dstPath))
-
-	pr, pw := io.Pipe()
-	defer pw.Close()
-
-	go readCloneProgress(newURLRedactor(remoteURL), lock, pr, repo)
-
-	if output, err := runWithRemoteOpts(ctx, cmd, pw); err != nil {
-		return errors.Wrapf(err, "clone failed. Output: %s", string(output))
+	var restored restoreReason
+	if opts != nil && opts.RestoreFromBackup != "" {
+		if err := s.restoreClone(ctx, repo, lock, tmp, opts.RestoreFromBackup); err != nil {
+			return err
+		}
+		restored = opts.RestoreFromBackup
+	} else if cloneErr := s.cloneWithSyncer(ctx, repo, syncer, lock, remoteURL, tmpPath, dstPath); cloneErr != nil {
+		if !s.backupsEnabled() {
+			return cloneErr
+		}
+		if err := s.restoreClone(ctx, repo, lock, tmp, restoreCloneFailed); err != nil {
+			if errors.Is(err, errNoBackup) {
+				return cloneErr
+			}
+			return errors.Append(cloneErr, err)
+		}
+		restored = restoreCloneFailed
 	}
 
 	if testRepoCorrupter != nil {
@@ -2047,7 +2084,10 @@ func (s *Server) doClone(ctx context.Context, repo api.RepoName, dir GitDir, syn
 
 	removeBadRefs(ctx, tmp)
 
-	if err := setHEAD(ctx, tmp, syncer, repo, remoteURL); err != nil {
+	if restored != "" {
+		// HEAD was restored from the backup.
+		ensureHEAD(tmp)
+	} else if err := setHEAD(ctx, tmp, syncer, repo, remoteURL); err != nil {
 		s.Logger.Error("Failed to ensure HEAD exists", log.String("repo", string(repo)), log.Error(err))
 		return errors.Wrap(err, "failed to ensure HEAD exists")
 	}
@@ -2095,6 +2135,62 @@ func (s *Server) doClone(ctx context.Context, repo api.RepoName, dir GitDir, syn
 	s.Logger.Info("repo cloned", log.String("repo", string(repo)))
 	repoClonedCounter.Inc()
 
+	if restored == restoreNewOwner {
+		// The backup may be older than the clone on the previous owner, so we fetch the
+		// changes since right away.
+		go func() {
+			ctx, cancel := s.serverContext()
+			defer cancel()
+			if err := s.doRepoUpdate(ctx, repo); err != nil {
+				s.Logger.Warn("failed to update restored repo", log.String("repo", string(repo)), log.Error(err))
+			}
+		}()
+	}
+
+	return nil
+}
+
+// cloneWithSyncer clones the repository from remoteURL into tmpPath.
+func (s *Server) cloneWithSyncer(ctx context.Context, repo api.RepoName, syncer VCSSyncer, lock *RepositoryLock, remoteURL *vcs.URL, tmpPath, dstPath string) error {
+	cmd, err := syncer.CloneCommand(ctx, remoteURL, tmpPath)
+	if err != nil {
+		return errors.Wrap(err, "get clone command")
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+
+	// see issue #7322: skip LFS content in repositories with Git LFS configured
+	cmd.Env = append(cmd.Env, "GIT_LFS_SKIP_SMUDGE=1")
+	s.Logger.Info("cloning repo", log.String("repo", string(repo)), log.String("tmp", tmpPath), log.String("dst", dstPath))
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	go readCloneProgress(newURLRedactor(remoteURL), lock, pr, repo)
+
+	if output, err := runWithRemoteOpts(ctx, cmd, pw); err != nil {
+		return errors.Wrapf(err, "clone failed. Output: %s", string(output))
+	}
+	return nil
+}
+
+// restoreClone restores the repository from its latest backup into tmp instead of cloning
+// it. Anything a failed clone left behind in tmp is removed first.
+func (s *Server) restoreClone(ctx context.Context, repo api.RepoName, lock *RepositoryLock, tmp GitDir, reason restoreReason) error {
+	lock.SetStatus("restoring from backup")
+	if err := os.RemoveAll(string(tmp)); err != nil {
+		return err
+	}
+
+	s.Logger.Info("restoring repo from backup", log.String("repo", string(repo)), log.String("reason", string(reason)))
+	if err := s.restoreRepo(ctx, repo, tmp); err != nil {
+		if errors.Is(err, errNoBackup) {
+			return err
+		}
+		return errors.Wrap(err, "restore from backup failed")
+	}
+	repoBackupsRestored.WithLabelValues(string(reason)).Inc()
 	return nil
 }
 
@@ -2379,6 +2475,20 @@ func (s *Server) doBackgroundRepoUpdate(repo api.RepoName) error {
 	repo = protocol.NormalizeRepo(repo)
 	dir := s.dir(repo)
 
+	if primary := s.replicaPrimary(ctx, repo); primary != "" {
+		defer s.cleanTmpFiles(dir)
+
+		// Replicas mirror the gitserver instance that owns the repo rather than the code host.
+		if err := fetchFromPrimary(ctx, repo, dir, primary); err != nil {
+			s.Logger.Error("Failed to fetch from primary", log.String("repo", string(repo)), log.String("primary", primary), log.Error(err))
+			return err
+		}
+		if err := setLastChanged(dir); err != nil {
+			s.Logger.Warn("Failed to update last changed time", log.String("repo", string(repo)), log.Error(err))
+		}
+		return nil
+	}
+
 	remoteURL, err := s.getRemoteURL(ctx, repo)
 	if err != nil {
 		return errors.Wrap(err, "failed to determine Git remote URL")
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/rubygems"
+	"github.com/sourcegraph/sourcegraph/internal/unpack"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func assertRubyParsesPlaceholder() *reposource.RubyDependency {
+	placeholder, err := reposource.ParseRubyDependency("sourcegraph.com/placeholder@0.0.0")
+	if err != nil {
+		panic(fmt.Sprintf("expected placeholder dependency to parse but got %v", err))
+	}
+
+	return placeholder
+}
+
+func NewRubyPackagesSyncer(
+	connection *schema.RubyPackagesConnection,
+	svc *dependencies.Service,
+	client *rubygems.Client,
+) VCSSyncer {
+	placeholder := assertRubyParsesPlaceholder()
+
+	return &vcsDependenciesSyncer{
+		logger:      log.Scoped("RubyPackagesSyncer", "sync Ruby packages"),
+		typ:         "ruby_packages",
+		scheme:      dependencies.RubyPackagesScheme,
+		placeholder: placeholder,
+		svc:         svc,
+		configDeps:  connection.Dependencies,
+		source:      &rubyDependencySource{client: client},
+	}
+}
+
+// rubyDependencySource implements dependenciesSource
+type rubyDependencySource struct {
+	client *rubygems.Client
+}
+
+func (rubyDependencySource) ParseDependency(dep string) (reposource.PackageDependency, error) {
+	return reposource.ParseRubyDependency(dep)
+}
+
+func (rubyDependencySource) ParseDependencyFromRepoName(repoName string) (reposource.PackageDependency, error) {
+	return reposource.ParseRubyDependencyFromRepoName(repoName)
+}
+
+func (s *rubyDependencySource) Get(ctx context.Context, name, version string) (reposource.PackageDependency, error) {
+	gem, err := s.client.GetGem(ctx, name, version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch gem metadata for %s", reposource.NewRubyDependency(name, version).PackageManagerSyntax())
+	}
+	return reposource.NewRubyDependency(name, gem.Version), nil
+}
+
+func (s *rubyDependencySource) Download(ctx context.Context, dir string, dep reposource.PackageDependency) error {
+	pkg, err := s.client.Download(ctx, dep.PackageSyntax(), dep.PackageVersion())
+	if err != nil {
+		return errors.Wrapf(err, "error downloading gem %s", dep.PackageManagerSyntax())
+	}
+
+	if err = unpackRubyPackage(pkg, dir); err != nil {
+		return errors.Wrap(err, "failed to unpack gem")
+	}
+
+	return nil
+}
+
+// unpackRubyPackage unpacks the source files of the given .gem archive into
+// workDir, skipping any files that aren't valid or that are potentially
+// malicious.
+//
+// A .gem file is an uncompressed tarball that contains the gzipped gemspec as
+// metadata.gz and the files of the gem as data.tar.gz. Only the latter ends up
+// in the repository.
+func unpackRubyPackage(pkg []byte, workDir string) error {
+	tr := tar.NewReader(bytes.NewReader(pkg))
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return errors.New("data.tar.gz not found in gem")
+		}
+		if err != nil {
+			return errors.Wrap(err, "reading gem")
+		}
+
+		if h.Name != "data.tar.gz" {
+			continue
+		}
+
+		opts := unpack.Opts{
+			SkipInvalid: true,
+			Filter: func(path string, file fs.FileInfo) bool {
+				size := file.Size()
+
+				const sizeLimit = 15 * 1024 * 1024
+				if size >= sizeLimit {
+					return false
+				}
+
+				_, malicious := isPotentiallyMaliciousFilepathInArchive(path, workDir)
+				return !malicious
+			},
+		}
+
+		// Unlike other package formats, the files of a gem are not nested in a
+		// directory named after the gem, so there is no outermost directory to
+		// strip.
+		return unpack.Tgz(tr, workDir, opts)
+	}
+}
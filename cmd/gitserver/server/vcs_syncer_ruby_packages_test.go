@@ -0,0 +1,64 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnpackRubyPackage(t *testing.T) {
+	data := createTgz(t, []fileInfo{
+		{path: "lib/example.rb", contents: []byte("module Example; end")},
+		{path: "lib/example/version.rb", contents: []byte("VERSION = '1.0.0'")},
+		{path: "README.md", contents: []byte("# Example")},
+		{path: ".git/index", contents: []byte("filter me")},
+		{path: "../escape.rb", contents: []byte("filter me")},
+	})
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range []fileInfo{
+		{path: "metadata.gz", contents: []byte("gemspec")},
+		{path: "data.tar.gz", contents: data},
+		{path: "checksums.yaml.gz", contents: []byte("checksums")},
+	} {
+		require.NoError(t, addFileToTarball(t, tw, f))
+	}
+	require.NoError(t, tw.Close())
+
+	tmp := t.TempDir()
+	require.NoError(t, unpackRubyPackage(buf.Bytes(), tmp))
+
+	var got []string
+	require.NoError(t, filepath.Walk(tmp, func(path string, info fs.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(tmp, path)
+		got = append(got, rel)
+		return err
+	}))
+	sort.Strings(got)
+
+	// Files of a gem share the lib directory more often than not, which must
+	// not be stripped.
+	want := []string{"README.md", "lib/example.rb", "lib/example/version.rb"}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Fatalf("-want,+got\n%s", d)
+	}
+}
+
+func TestUnpackRubyPackage_MissingData(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, addFileToTarball(t, tw, fileInfo{path: "metadata.gz", contents: []byte("gemspec")}))
+	require.NoError(t, tw.Close())
+
+	require.Error(t, unpackRubyPackage(buf.Bytes(), t.TempDir()))
+}
@@ -14,6 +14,7 @@ import (
 
 	connections "github.com/sourcegraph/sourcegraph/internal/database/connections/live"
 	"github.com/sourcegraph/sourcegraph/internal/database/migration/cliutil"
+	"github.com/sourcegraph/sourcegraph/internal/database/migration/multiversion"
 	"github.com/sourcegraph/sourcegraph/internal/database/migration/store"
 	"github.com/sourcegraph/sourcegraph/internal/database/postgresdsn"
 	"github.com/sourcegraph/sourcegraph/internal/env"
@@ -65,6 +66,7 @@ func mainErr(ctx context.Context, args []string) error {
 			cliutil.Up(appName, runnerFactory, outputFactory, false),
 			cliutil.UpTo(appName, runnerFactory, outputFactory, false),
 			cliutil.DownTo(appName, runnerFactory, outputFactory, false),
+			cliutil.Upgrade(appName, runnerFactory, newOutOfBandMigrationStoreFactory(), outputFactory),
 			cliutil.Validate(appName, runnerFactory, outputFactory),
 			cliutil.Describe(appName, runnerFactory, outputFactory),
 			cliutil.Drift(appName, runnerFactory, outputFactory, cliutil.GCSExpectedSchemaFactory, cliutil.GitHubExpectedSchemaFactory),
@@ -100,3 +102,25 @@ func newRunnerFactory() func(ctx context.Context, schemaNames []string) (cliutil
 		return cliutil.NewShim(r), nil
 	}
 }
+
+func newOutOfBandMigrationStoreFactory() cliutil.OutOfBandMigrationStoreFactory {
+	logger := log.Scoped("oobmigrations", "")
+	observationContext := &observation.Context{
+		Logger:     logger,
+		Tracer:     &trace.Tracer{Tracer: opentracing.GlobalTracer()},
+		Registerer: prometheus.DefaultRegisterer,
+	}
+
+	return func(ctx context.Context) (cliutil.OutOfBandMigrationStore, error) {
+		dsns, err := postgresdsn.DSNsBySchema([]string{"frontend"})
+		if err != nil {
+			return nil, err
+		}
+		db, err := connections.RawNewFrontendDB(dsns["frontend"], appName, observationContext)
+		if err != nil {
+			return nil, err
+		}
+
+		return multiversion.NewStoreWithDB(db), nil
+	}
+}
@@ -33,8 +33,9 @@ type Server struct {
 	Logger                log.Logger
 	SourcegraphDotComMode bool
 	Scheduler             interface {
-		UpdateOnce(id api.RepoID, name api.RepoName)
-		ScheduleInfo(id api.RepoID) *protocol.RepoUpdateSchedulerInfoResult
+		UpdateOnce(ctx context.Context, id api.RepoID, name api.RepoName) error
+		UpdateFromWebhook(ctx context.Context, id api.RepoID, name api.RepoName) error
+		ScheduleInfo(ctx context.Context, id api.RepoID) (*protocol.RepoUpdateSchedulerInfoResult, error)
 	}
 	GitserverClient interface {
 		ListCloned(context.Context) ([]string, error)
@@ -103,7 +104,11 @@ func (s *Server) handleRepoUpdateSchedulerInfo(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	result := s.Scheduler.ScheduleInfo(args.ID)
+	result, err := s.Scheduler.ScheduleInfo(r.Context(), args.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	if err := json.NewEncoder(w).Encode(result); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -180,7 +185,14 @@ func (s *Server) enqueueRepoUpdate(ctx context.Context, req *protocol.RepoUpdate
 
 	repo := rs[0]
 
-	s.Scheduler.UpdateOnce(repo.ID, repo.Name)
+	if req.Webhook {
+		err = s.Scheduler.UpdateFromWebhook(ctx, repo.ID, repo.Name)
+	} else {
+		err = s.Scheduler.UpdateOnce(ctx, repo.ID, repo.Name)
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(err, "scheduler.update")
+	}
 
 	return &protocol.RepoUpdateResponse{
 		ID:   repo.ID,
@@ -343,7 +355,9 @@ func (s *Server) repoLookup(ctx context.Context, args protocol.RepoLookupArgs) (
 
 	if s.Scheduler != nil && args.Update {
 		// Enqueue a high priority update for this repo.
-		s.Scheduler.UpdateOnce(repo.ID, repo.Name)
+		if err := s.Scheduler.UpdateOnce(ctx, repo.ID, repo.Name); err != nil {
+			s.Logger.Warn("enqueuing repo update", log.String("repo", string(repo.Name)), log.Error(err))
+		}
 	}
 
 	repoInfo := protocol.NewRepoInfo(repo)
@@ -489,7 +489,9 @@ func watchSyncer(
 			return
 		case diff := <-syncer.Synced:
 			if !conf.Get().DisableAutoGitUpdates {
-				sched.UpdateFromDiff(diff)
+				if err := sched.UpdateFromDiff(ctx, diff); err != nil {
+					logger.Error("updating scheduler from sync diff", log.Error(err))
+				}
 			}
 
 			// PermsSyncer is only available in enterprise mode.
@@ -543,12 +545,19 @@ func syncScheduler(ctx context.Context, logger log.Logger, sched *repos.UpdateSc
 			return
 		} else {
 			// Ensure that uncloned indexable repos are known to the scheduler
-			sched.EnsureScheduled(u)
+			if err := sched.EnsureScheduled(ctx, u); err != nil {
+				logger.Error("scheduling uncloned indexable repos", log.Error(err))
+				return
+			}
 		}
 
 		// Next, move any repos managed by the scheduler that are uncloned to the front
 		// of the queue
-		managed := sched.ListRepoIDs()
+		managed, err := sched.ListRepoIDs(ctx)
+		if err != nil {
+			logger.Error("listing scheduled repos", log.Error(err))
+			return
+		}
 
 		uncloned, err := baseRepoStore.ListMinimalRepos(ctx, database.ReposListOptions{IDs: managed, NoCloned: true})
 		if err != nil {
@@ -556,7 +565,9 @@ func syncScheduler(ctx context.Context, logger log.Logger, sched *repos.UpdateSc
 			return
 		}
 
-		sched.PrioritiseUncloned(uncloned)
+		if err := sched.PrioritiseUncloned(ctx, uncloned); err != nil {
+			logger.Error("prioritising uncloned repos", log.Error(err))
+		}
 	}
 
 	for ctx.Err() == nil {
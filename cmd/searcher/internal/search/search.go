@@ -198,6 +198,11 @@ func (s *Service) search(ctx context.Context, p *protocol.Request, sender matchS
 			log.Error(err))
 	}(time.Now())
 
+	if p.IsStructuralPat {
+		p.StructuralEngine = structuralEngine(p)
+		span.SetTag("structuralEngine", p.StructuralEngine)
+	}
+
 	if p.IsStructuralPat && p.Indexed {
 		// Execute the new structural search path that directly calls Zoekt.
 		// TODO use limit in indexed structural search
@@ -287,6 +287,23 @@ func lookupMatcher(language string) string {
 	return ".generic"
 }
 
+// structuralEngine returns the engine that runs the structural search p. The
+// engine selected by the query is always used. Otherwise, the feature flag
+// selects tree-sitter for searches that it supports, and comby runs searches
+// with a rule or with holes that tree-sitter can't parse.
+func structuralEngine(p *protocol.Request) string {
+	if p.StructuralEngine != "" || !p.FeatStructuralTreeSitter {
+		return p.StructuralEngine
+	}
+	if p.CombyRule != "" {
+		return protocol.StructuralEngineComby
+	}
+	if _, err := parseStructuralPattern(p.Pattern); err != nil {
+		return protocol.StructuralEngineComby
+	}
+	return protocol.StructuralEngineTreeSitter
+}
+
 func structuralSearchWithZoekt(ctx context.Context, p *protocol.Request, sender matchSender) (err error) {
 	patternInfo := &search.TextPatternInfo{
 		Pattern:                      p.Pattern,
@@ -294,6 +311,7 @@ func structuralSearchWithZoekt(ctx context.Context, p *protocol.Request, sender
 		IsRegExp:                     p.IsRegExp,
 		IsStructuralPat:              p.IsStructuralPat,
 		CombyRule:                    p.CombyRule,
+		StructuralEngine:             p.StructuralEngine,
 		IsWordMatch:                  p.IsWordMatch,
 		IsCaseSensitive:              p.IsCaseSensitive,
 		FileMatchLimit:               int32(p.Limit),
@@ -317,7 +335,7 @@ func structuralSearchWithZoekt(ctx context.Context, p *protocol.Request, sender
 	return nil
 }
 
-// filteredStructuralSearch filters the list of files with a regex search before passing the zip to comby
+// filteredStructuralSearch filters the list of files with a regex search before passing the zip to the structural search engine
 func filteredStructuralSearch(ctx context.Context, zipPath string, zf *zipFile, p *protocol.PatternInfo, repo api.RepoName, sender matchSender) error {
 	// Make a copy of the pattern info to modify it to work for a regex search
 	rp := *p
@@ -347,7 +365,7 @@ func filteredStructuralSearch(ctx context.Context, zipPath string, zf *zipFile,
 		extensionHint = filepath.Ext(matchedPaths[0])
 	}
 
-	return structuralSearch(ctx, comby.ZipPath(zipPath), subset(matchedPaths), extensionHint, p.Pattern, p.CombyRule, p.Languages, repo, sender)
+	return structuralSearch(ctx, comby.ZipPath(zipPath), subset(matchedPaths), extensionHint, p.Pattern, p.CombyRule, p.StructuralEngine, p.Languages, repo, sender)
 }
 
 // toMatcher returns the matcher that parameterizes structural search. It
@@ -384,9 +402,10 @@ type subset []string
 
 var all universalSet = struct{}{}
 
-func structuralSearch(ctx context.Context, inputType comby.Input, paths filePatterns, extensionHint, pattern, rule string, languages []string, repo api.RepoName, sender matchSender) (err error) {
+func structuralSearch(ctx context.Context, inputType comby.Input, paths filePatterns, extensionHint, pattern, rule, engine string, languages []string, repo api.RepoName, sender matchSender) (err error) {
 	span, ctx := ot.StartSpanFromContext(ctx, "StructuralSearch")
 	span.SetTag("repo", repo)
+	span.SetTag("engine", engine)
 	defer func() {
 		if err != nil {
 			ext.Error.Set(span, true)
@@ -395,6 +414,10 @@ func structuralSearch(ctx context.Context, inputType comby.Input, paths filePatt
 		span.Finish()
 	}()
 
+	if engine == protocol.StructuralEngineTreeSitter {
+		return treeSitterStructuralSearch(ctx, inputType, paths, pattern, rule, sender)
+	}
+
 	// Cap the number of forked processes to limit the size of zip contents being mapped to memory. Resolving #7133 could help to lift this restriction.
 	numWorkers := 4
 
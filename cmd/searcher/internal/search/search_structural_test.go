@@ -73,7 +73,7 @@ func foo(go string) {}
 
 				ctx, cancel, sender := newLimitedStreamCollector(context.Background(), 100000000)
 				defer cancel()
-				err := structuralSearch(ctx, comby.ZipPath(zf), subset(p.IncludePatterns), "", p.Pattern, p.CombyRule, p.Languages, "repo_foo", sender)
+				err := structuralSearch(ctx, comby.ZipPath(zf), subset(p.IncludePatterns), "", p.Pattern, p.CombyRule, "", p.Languages, "repo_foo", sender)
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -130,7 +130,7 @@ func foo(go.txt) {}
 		extensionHint := filepath.Ext(filename)
 		ctx, cancel, sender := newLimitedStreamCollector(context.Background(), 1000000000)
 		defer cancel()
-		err := structuralSearch(ctx, comby.ZipPath(zf), all, extensionHint, "foo(:[args])", "", languages, "repo_foo", sender)
+		err := structuralSearch(ctx, comby.ZipPath(zf), all, extensionHint, "foo(:[args])", "", "", languages, "repo_foo", sender)
 		if err != nil {
 			return "ERROR: " + err.Error()
 		}
@@ -323,7 +323,7 @@ func TestIncludePatterns(t *testing.T) {
 	}
 	ctx, cancel, sender := newLimitedStreamCollector(context.Background(), 1000000000)
 	defer cancel()
-	err = structuralSearch(ctx, comby.ZipPath(zf), subset(p.IncludePatterns), "", p.Pattern, p.CombyRule, p.Languages, "foo", sender)
+	err = structuralSearch(ctx, comby.ZipPath(zf), subset(p.IncludePatterns), "", p.Pattern, p.CombyRule, "", p.Languages, "foo", sender)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -363,7 +363,7 @@ func TestRule(t *testing.T) {
 
 	ctx, cancel, sender := newLimitedStreamCollector(context.Background(), 1000000000)
 	defer cancel()
-	err = structuralSearch(ctx, comby.ZipPath(zf), subset(p.IncludePatterns), "", p.Pattern, p.CombyRule, p.Languages, "repo", sender)
+	err = structuralSearch(ctx, comby.ZipPath(zf), subset(p.IncludePatterns), "", p.Pattern, p.CombyRule, "", p.Languages, "repo", sender)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -431,7 +431,7 @@ func bar() {
 		return func(t *testing.T) {
 			ctx, cancel, sender := newLimitedStreamCollector(context.Background(), limit)
 			defer cancel()
-			err := structuralSearch(ctx, comby.ZipPath(zf), subset(p.IncludePatterns), "", p.Pattern, p.CombyRule, p.Languages, "repo_foo", sender)
+			err := structuralSearch(ctx, comby.ZipPath(zf), subset(p.IncludePatterns), "", p.Pattern, p.CombyRule, "", p.Languages, "repo_foo", sender)
 			require.NoError(t, err)
 
 			require.Equal(t, wantCount, count(sender.collected))
@@ -475,7 +475,7 @@ func bar() {
 	t.Run("Strutural search match count", func(t *testing.T) {
 		ctx, cancel, sender := newLimitedStreamCollector(context.Background(), 1000000000)
 		defer cancel()
-		err := structuralSearch(ctx, comby.ZipPath(zf), subset(p.IncludePatterns), "", p.Pattern, p.CombyRule, p.Languages, "repo_foo", sender)
+		err := structuralSearch(ctx, comby.ZipPath(zf), subset(p.IncludePatterns), "", p.Pattern, p.CombyRule, "", p.Languages, "repo_foo", sender)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -519,7 +519,7 @@ func bar() {
 	t.Run("Strutural search match count", func(t *testing.T) {
 		ctx, cancel, sender := newLimitedStreamCollector(context.Background(), 1000000000)
 		defer cancel()
-		err := structuralSearch(ctx, comby.ZipPath(zf), subset(p.IncludePatterns), "", p.Pattern, p.CombyRule, p.Languages, "repo_foo", sender)
+		err := structuralSearch(ctx, comby.ZipPath(zf), subset(p.IncludePatterns), "", p.Pattern, p.CombyRule, "", p.Languages, "repo_foo", sender)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -722,7 +722,7 @@ func bar() {
 	t.Run("Structural search tar input to comby", func(t *testing.T) {
 		ctx, cancel, sender := newLimitedStreamCollector(context.Background(), 1000000000)
 		defer cancel()
-		err := structuralSearch(ctx, comby.Tar{TarInputEventC: tarInputEventC}, all, "", p.Pattern, p.CombyRule, p.Languages, "repo_foo", sender)
+		err := structuralSearch(ctx, comby.Tar{TarInputEventC: tarInputEventC}, all, "", p.Pattern, p.CombyRule, "", p.Languages, "repo_foo", sender)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -748,3 +748,38 @@ func bar() {
 		require.Equal(t, expected, matches)
 	})
 }
+
+func TestStructuralEngine(t *testing.T) {
+	request := func(engine string, featTreeSitter bool, pattern, rule string) *protocol.Request {
+		return &protocol.Request{
+			PatternInfo: protocol.PatternInfo{
+				Pattern:          pattern,
+				IsStructuralPat:  true,
+				CombyRule:        rule,
+				StructuralEngine: engine,
+			},
+			FeatStructuralTreeSitter: featTreeSitter,
+		}
+	}
+
+	tests := []struct {
+		name string
+		req  *protocol.Request
+		want string
+	}{
+		{"default", request("", false, "foo(:[x])", ""), ""},
+		{"feature flag", request("", true, "foo(:[x])", ""), protocol.StructuralEngineTreeSitter},
+		{"feature flag with rule", request("", true, "foo(:[x])", `where :[x] == "bar"`), protocol.StructuralEngineComby},
+		{"feature flag with comby-only hole", request("", true, "foo(:[x.])", ""), protocol.StructuralEngineComby},
+		{"explicit engine", request(protocol.StructuralEngineTreeSitter, false, "foo(:[x])", ""), protocol.StructuralEngineTreeSitter},
+		{"explicit engine with comby-only hole", request(protocol.StructuralEngineTreeSitter, true, "foo(:[x.])", ""), protocol.StructuralEngineTreeSitter},
+		{"explicit comby", request(protocol.StructuralEngineComby, true, "foo(:[x])", ""), protocol.StructuralEngineComby},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := structuralEngine(test.req); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+//go:build cgo
+
+package search
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/cpp"
+	"github.com/smacker/go-tree-sitter/csharp"
+	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/java"
+	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/ruby"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
+	"github.com/smacker/go-tree-sitter/typescript/typescript"
+
+	"github.com/sourcegraph/log"
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/comby"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// treeSitterLanguages maps file extensions to the tree-sitter grammars used
+// to parse them for structural search. Files with other extensions are not
+// searched by the tree-sitter engine.
+var treeSitterLanguages = map[string]*sitter.Language{
+	".go":   golang.GetLanguage(),
+	".java": java.GetLanguage(),
+	".js":   javascript.GetLanguage(),
+	".jsx":  javascript.GetLanguage(),
+	".mjs":  javascript.GetLanguage(),
+	".ts":   typescript.GetLanguage(),
+	".tsx":  tsx.GetLanguage(),
+	".py":   python.GetLanguage(),
+	".rb":   ruby.GetLanguage(),
+	".c":    cpp.GetLanguage(),
+	".h":    cpp.GetLanguage(),
+	".cc":   cpp.GetLanguage(),
+	".cpp":  cpp.GetLanguage(),
+	".hpp":  cpp.GetLanguage(),
+	".cs":   csharp.GetLanguage(),
+}
+
+var metricTreeSitterSkippedFiles = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "searcher_structural_tree_sitter_skipped_files_total",
+	Help: "Number of files skipped by tree-sitter structural search because they could not be parsed.",
+})
+
+// treeSitterStructuralSearch is the in-process alternative to comby. It parses
+// every file with a known grammar with tree-sitter and matches the pattern
+// against the syntax tree with a structuralMatcher.
+func treeSitterStructuralSearch(ctx context.Context, inputType comby.Input, paths filePatterns, pattern, rule string, sender matchSender) error {
+	if rule != "" {
+		return errors.New("rules are not supported by the tree-sitter structural search engine")
+	}
+
+	matcher, err := newStructuralMatcher(pattern)
+	if err != nil {
+		return err
+	}
+
+	parser := sitter.NewParser()
+	defer parser.Close()
+
+	logger := log.Scoped("treeSitterStructuralSearch", "in-process structural search with tree-sitter")
+
+	searchFile := func(path string, buf []byte) {
+		fm, err := treeSitterFileMatch(ctx, parser, matcher, path, buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				// Parsing was canceled with the search.
+				return
+			}
+			metricTreeSitterSkippedFiles.Inc()
+			logger.Warn("skipping file that could not be parsed", log.String("path", path), log.Error(err))
+			return
+		}
+		if fm != nil {
+			sender.Send(*fm)
+		}
+	}
+
+	switch input := inputType.(type) {
+	case comby.Tar:
+		// The channel must be drained even after the search is done, since
+		// its producer blocks on every send.
+		for tb := range input.TarInputEventC {
+			if ctx.Err() != nil || sender.LimitHit() {
+				continue
+			}
+			searchFile(tb.Header.Name, tb.Content)
+		}
+		return ctx.Err()
+
+	case comby.ZipPath:
+		zipReader, err := zip.OpenReader(string(input))
+		if err != nil {
+			return err
+		}
+		defer zipReader.Close()
+
+		var include map[string]struct{}
+		if s, ok := paths.(subset); ok {
+			include = make(map[string]struct{}, len(s))
+			for _, path := range s {
+				include[path] = struct{}{}
+			}
+		}
+
+		for _, f := range zipReader.File {
+			if ctx.Err() != nil || sender.LimitHit() {
+				break
+			}
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			if include != nil {
+				if _, ok := include[f.Name]; !ok {
+					continue
+				}
+			}
+			if _, ok := treeSitterLanguages[filepath.Ext(f.Name)]; !ok {
+				continue
+			}
+
+			buf, err := readZipFile(f)
+			if err != nil {
+				return err
+			}
+			searchFile(f.Name, buf)
+		}
+		return ctx.Err()
+	}
+
+	return errors.New("structural search input must be either a tar stream or a zip file")
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// treeSitterFileMatch returns the matches of matcher in the file, or nil if
+// the file has no matches or no grammar.
+func treeSitterFileMatch(ctx context.Context, parser *sitter.Parser, matcher *structuralMatcher, path string, buf []byte) (*protocol.FileMatch, error) {
+	language, ok := treeSitterLanguages[filepath.Ext(path)]
+	if !ok {
+		return nil, nil
+	}
+
+	parser.SetLanguage(language)
+	tree, err := parser.ParseCtx(ctx, nil, buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing file")
+	}
+	defer tree.Close()
+
+	syntax := newTreeSitterSyntaxTree(buf, tree.RootNode())
+	matches := matcher.Match(syntax)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	ranges := make([]protocol.Range, 0, len(matches))
+	for _, m := range matches {
+		ranges = append(ranges, protocol.Range{
+			Start: offsetToLocation(buf, syntax.tokens[m.start].start),
+			End:   offsetToLocation(buf, syntax.tokens[m.end-1].end),
+		})
+	}
+
+	return &protocol.FileMatch{
+		Path:         path,
+		ChunkMatches: chunksToMatches(buf, chunkRanges(ranges, 0)),
+		LimitHit:     false,
+	}, nil
+}
+
+// newTreeSitterSyntaxTree converts a tree-sitter syntax tree into the tokens
+// and syntax tree that a structuralMatcher works on. The leaves of the
+// tree-sitter tree become tokens, except that nodes with text that is not
+// covered by their children, such as string literals, are a single token.
+// Comments are dropped, so that patterns match regardless of comments.
+func newTreeSitterSyntaxTree(buf []byte, root *sitter.Node) *syntaxTree {
+	var tokens []structuralToken
+
+	var convert func(n *sitter.Node) *syntaxNode
+	convert = func(n *sitter.Node) *syntaxNode {
+		start, end := int(n.StartByte()), int(n.EndByte())
+		if start == end || strings.Contains(n.Type(), "comment") {
+			return nil
+		}
+
+		if n.ChildCount() == 0 || hasUncoveredText(buf, n) {
+			tokens = append(tokens, structuralToken{start: start, end: end})
+			return &syntaxNode{first: len(tokens) - 1, last: len(tokens)}
+		}
+
+		node := &syntaxNode{first: len(tokens)}
+		for i := 0; i < int(n.ChildCount()); i++ {
+			if child := convert(n.Child(i)); child != nil {
+				node.children = append(node.children, child)
+			}
+		}
+		node.last = len(tokens)
+		if len(node.children) == 0 {
+			return nil
+		}
+		return node
+	}
+
+	rootNode := convert(root)
+	if rootNode == nil {
+		rootNode = &syntaxNode{}
+	}
+	return newSyntaxTree(buf, tokens, rootNode)
+}
+
+// hasUncoveredText reports whether n spans non-whitespace text that is not
+// covered by any of its children.
+func hasUncoveredText(buf []byte, n *sitter.Node) bool {
+	pos := n.StartByte()
+	for i := 0; i < int(n.ChildCount()); i++ {
+		child := n.Child(i)
+		if child.StartByte() > pos && len(bytes.TrimSpace(buf[pos:child.StartByte()])) > 0 {
+			return true
+		}
+		if child.EndByte() > pos {
+			pos = child.EndByte()
+		}
+	}
+	return len(bytes.TrimSpace(buf[pos:n.EndByte()])) > 0
+}
+
+// offsetToLocation returns the location of the byte offset in buf, with a
+// 0-based line and a column counted in runes.
+func offsetToLocation(buf []byte, offset int) protocol.Location {
+	lineStart := bytes.LastIndexByte(buf[:offset], '\n') + 1
+	return protocol.Location{
+		Offset: int32(offset),
+		Line:   int32(bytes.Count(buf[:offset], []byte{'\n'})),
+		Column: int32(utf8.RuneCount(buf[lineStart:offset])),
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !cgo
+
+package search
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/internal/comby"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// treeSitterStructuralSearch is unavailable without cgo, which the tree-sitter
+// grammars require.
+func treeSitterStructuralSearch(ctx context.Context, inputType comby.Input, paths filePatterns, pattern, rule string, sender matchSender) error {
+	if tar, ok := inputType.(comby.Tar); ok {
+		// Drain the channel so that its producer does not block.
+		for range tar.TarInputEventC {
+		}
+	}
+	return errors.New("the tree-sitter structural search engine requires searcher to be built with cgo")
+}
@@ -0,0 +1,68 @@
+//go:build cgo
+
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/comby"
+)
+
+func TestTreeSitterStructuralSearch(t *testing.T) {
+	input := map[string]string{
+		"main.go": `package main
+
+import "fmt"
+
+func main() {
+	// fmt.Println(commented)
+	fmt.Println("hello", name)
+	fmt.Printf("%d", 1)
+}
+`,
+		"README.md": "fmt.Println(readme)",
+	}
+
+	zipData, err := createZip(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zf := tempZipFileOnDisk(t, zipData)
+
+	t.Run("matches", func(t *testing.T) {
+		ctx, cancel, sender := newLimitedStreamCollector(context.Background(), 100000000)
+		defer cancel()
+		err := structuralSearch(ctx, comby.ZipPath(zf), all, "", "fmt.Println(:[args])", "", protocol.StructuralEngineTreeSitter, nil, "repo_foo", sender)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []protocol.FileMatch{{
+			Path: "main.go",
+			ChunkMatches: []protocol.ChunkMatch{{
+				Content:      "\tfmt.Println(\"hello\", name)",
+				ContentStart: protocol.Location{Offset: 69, Line: 6, Column: 0},
+				Ranges: []protocol.Range{{
+					Start: protocol.Location{Offset: 70, Line: 6, Column: 1},
+					End:   protocol.Location{Offset: 96, Line: 6, Column: 27},
+				}},
+			}},
+		}}
+		if diff := cmp.Diff(want, sender.collected); diff != "" {
+			t.Fatalf("unexpected file matches (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("rules are unsupported", func(t *testing.T) {
+		ctx, cancel, sender := newLimitedStreamCollector(context.Background(), 100000000)
+		defer cancel()
+		err := structuralSearch(ctx, comby.ZipPath(zf), all, "", "fmt.Println(:[args])", `where :[args] == "x"`, protocol.StructuralEngineTreeSitter, nil, "repo_foo", sender)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
@@ -0,0 +1,366 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/grafana/regexp"
+
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// structuralToken is a token of a parsed file, represented by its byte range
+// in the file contents.
+type structuralToken struct {
+	start, end int
+}
+
+// syntaxNode is a node of the syntax tree of a parsed file. It covers the
+// tokens [first, last). Leaves of the tree cover exactly one token.
+type syntaxNode struct {
+	first, last int
+	parent      *syntaxNode
+	index       int // position of the node in parent.children
+	children    []*syntaxNode
+}
+
+// syntaxTree is a tokenized file together with its syntax tree.
+type syntaxTree struct {
+	buf    []byte
+	tokens []structuralToken
+	root   *syntaxNode
+	leaves []*syntaxNode // leaves[i] is the leaf covering tokens[i]
+}
+
+func (t *syntaxTree) text(i int) string {
+	return string(t.buf[t.tokens[i].start:t.tokens[i].end])
+}
+
+// newSyntaxTree returns the syntax tree with the given root, computing the
+// parent links of the nodes and the leaves of the tokens.
+func newSyntaxTree(buf []byte, tokens []structuralToken, root *syntaxNode) *syntaxTree {
+	t := &syntaxTree{buf: buf, tokens: tokens, root: root, leaves: make([]*syntaxNode, len(tokens))}
+	var link func(n *syntaxNode)
+	link = func(n *syntaxNode) {
+		if len(n.children) == 0 && n.last-n.first == 1 {
+			t.leaves[n.first] = n
+		}
+		for i, c := range n.children {
+			c.parent, c.index = n, i
+			link(c)
+		}
+	}
+	link(root)
+	return t
+}
+
+// holeEnds returns the token indices at which a hole starting at token start
+// may end, in increasing order. A hole matches a sequence of consecutive
+// sibling nodes of the syntax tree, so the text it binds to is always a
+// syntactically complete fragment such as an expression, an argument list or
+// a sequence of statements. The first end is always start, the empty match.
+func (t *syntaxTree) holeEnds(start int) []int {
+	ends := []int{start}
+	if start >= len(t.tokens) {
+		return ends
+	}
+	for n := t.leaves[start]; n != nil && n.first == start; n = n.parent {
+		if n.parent == nil {
+			ends = append(ends, n.last)
+			break
+		}
+		for _, sibling := range n.parent.children[n.index:] {
+			ends = append(ends, sibling.last)
+		}
+	}
+	sort.Ints(ends)
+
+	// Nested nodes that start at the same token can end at the same token,
+	// so deduplicate.
+	unique := ends[:1]
+	for _, e := range ends[1:] {
+		if e != unique[len(unique)-1] {
+			unique = append(unique, e)
+		}
+	}
+	return unique
+}
+
+// structuralPatternElement is either literal text or a hole of a structural
+// pattern.
+type structuralPatternElement struct {
+	// literal is the text to match. It is only set if the element is not a
+	// hole.
+	literal string
+
+	hole holeKind
+	// name binds the hole. Holes with the same name must match the same
+	// text. The name "_" never binds.
+	name string
+	// regexp constrains a holeRegexp to tokens that it fully matches.
+	regexp *regexp.Regexp
+}
+
+type holeKind int
+
+const (
+	holeNone holeKind = iota
+	// holeAny matches any sequence of sibling syntax nodes: :[x] and ...
+	holeAny
+	// holeWord matches a single token of word characters: :[[x]]
+	holeWord
+	// holeRegexp matches a single token matching a regexp: :[x~re]
+	holeRegexp
+)
+
+var wordTokenRegexp = regexp.MustCompile(`^\w+$`)
+
+// parseStructuralPattern parses a comby-compatible structural pattern into
+// its literal text and holes. The supported holes are :[x], :[[x]], :[x~re]
+// and ...; other comby hole syntaxes are rejected.
+func parseStructuralPattern(pattern string) ([]structuralPatternElement, error) {
+	var elements []structuralPatternElement
+	var literal strings.Builder
+	flushLiteral := func() {
+		if s := literal.String(); strings.TrimSpace(s) != "" {
+			elements = append(elements, structuralPatternElement{literal: s})
+		}
+		literal.Reset()
+	}
+
+	for i := 0; i < len(pattern); {
+		switch {
+		case strings.HasPrefix(pattern[i:], "..."):
+			flushLiteral()
+			elements = append(elements, structuralPatternElement{hole: holeAny, name: "_"})
+			i += len("...")
+
+		case strings.HasPrefix(pattern[i:], ":["):
+			flushLiteral()
+			e, n, err := parseHole(pattern[i:])
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, e)
+			i += n
+
+		default:
+			literal.WriteByte(pattern[i])
+			i++
+		}
+	}
+	flushLiteral()
+
+	if len(elements) == 0 {
+		return nil, errors.New("empty structural pattern")
+	}
+	return elements, nil
+}
+
+// parseHole parses the hole at the start of s, which begins with ":[". It
+// returns the hole and its length in bytes.
+func parseHole(s string) (structuralPatternElement, int, error) {
+	if strings.HasPrefix(s, ":[[") {
+		end := strings.Index(s, "]]")
+		if end < 0 || !wordTokenRegexp.MatchString(s[3:end]) {
+			return structuralPatternElement{}, 0, errors.Errorf("invalid hole in structural pattern %q", s)
+		}
+		return structuralPatternElement{hole: holeWord, name: s[3:end]}, end + 2, nil
+	}
+
+	i := 2
+	for i < len(s) && isWordRune(rune(s[i])) {
+		i++
+	}
+	name := s[2:i]
+	if name == "" {
+		return structuralPatternElement{}, 0, errors.Errorf("invalid hole in structural pattern %q: missing name", s)
+	}
+
+	switch {
+	case strings.HasPrefix(s[i:], "]"):
+		return structuralPatternElement{hole: holeAny, name: name}, i + 1, nil
+
+	case strings.HasPrefix(s[i:], "~"):
+		// The regular expression extends to the "]" that balances the
+		// opening ":[", so that it may contain character classes.
+		depth := 0
+		for j := i + 1; j < len(s); j++ {
+			switch s[j] {
+			case '\\':
+				j++
+			case '[':
+				depth++
+			case ']':
+				if depth > 0 {
+					depth--
+					continue
+				}
+				re, err := regexp.Compile("^(?:" + s[i+1:j] + ")$")
+				if err != nil {
+					return structuralPatternElement{}, 0, errors.Wrapf(err, "invalid regular expression in structural pattern hole %q", s[:j+1])
+				}
+				return structuralPatternElement{hole: holeRegexp, name: name, regexp: re}, j + 1, nil
+			}
+		}
+	}
+	return structuralPatternElement{}, 0, errors.Errorf("unsupported hole in structural pattern %q", s)
+}
+
+// structuralMatcher matches a parsed structural pattern against syntax trees.
+type structuralMatcher struct {
+	elements []structuralPatternElement
+}
+
+func newStructuralMatcher(pattern string) (*structuralMatcher, error) {
+	elements, err := parseStructuralPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &structuralMatcher{elements: elements}, nil
+}
+
+// tokenRange is a half-open range of token indices.
+type tokenRange struct {
+	start, end int
+}
+
+// Match returns the leftmost non-overlapping matches of the pattern in t.
+// Literal text matches tokens ignoring whitespace between them, and holes
+// match lazily.
+func (m *structuralMatcher) Match(t *syntaxTree) []tokenRange {
+	var matches []tokenRange
+	for start := 0; start < len(t.tokens); {
+		end, ok := m.match(t, 0, start, map[string]tokenRange{})
+		if !ok || end == start {
+			start++
+			continue
+		}
+		matches = append(matches, tokenRange{start: start, end: end})
+		start = end
+	}
+	return matches
+}
+
+// match matches m.elements[i:] at token pos, returning the end of the match.
+func (m *structuralMatcher) match(t *syntaxTree, i, pos int, env map[string]tokenRange) (int, bool) {
+	if i == len(m.elements) {
+		return pos, true
+	}
+
+	e := m.elements[i]
+	if e.hole == holeNone {
+		end, ok := matchLiteral(t, e.literal, pos)
+		if !ok {
+			return 0, false
+		}
+		return m.match(t, i+1, end, env)
+	}
+
+	var ends []int
+	switch e.hole {
+	case holeAny:
+		ends = t.holeEnds(pos)
+	case holeWord:
+		if pos < len(t.tokens) && wordTokenRegexp.MatchString(t.text(pos)) {
+			ends = []int{pos + 1}
+		}
+	case holeRegexp:
+		if pos < len(t.tokens) && e.regexp.MatchString(t.text(pos)) {
+			ends = []int{pos + 1}
+		}
+	}
+
+	for _, end := range ends {
+		r := tokenRange{start: pos, end: end}
+		binds := false
+		if e.name != "_" {
+			if bound, ok := env[e.name]; ok {
+				if !sameTokens(t, bound, r) {
+					continue
+				}
+			} else {
+				env[e.name] = r
+				binds = true
+			}
+		}
+		if matchEnd, ok := m.match(t, i+1, end, env); ok {
+			return matchEnd, true
+		}
+		if binds {
+			delete(env, e.name)
+		}
+	}
+	return 0, false
+}
+
+// sameTokens reports whether the token ranges a and b have the same text,
+// ignoring whitespace between tokens.
+func sameTokens(t *syntaxTree, a, b tokenRange) bool {
+	if a.end-a.start != b.end-b.start {
+		return false
+	}
+	for i := 0; i < a.end-a.start; i++ {
+		if t.text(a.start+i) != t.text(b.start+i) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchLiteral matches the literal text at token pos and returns the index of
+// the token after the match. The literal must cover whole tokens. Whitespace
+// in the literal matches any whitespace between tokens, including none, and
+// whitespace within a token. Adjacent tokens that are not separated by
+// whitespace in the literal must not both be word characters where they
+// meet, so that "funcmain" does not match "func main".
+func matchLiteral(t *syntaxTree, literal string, pos int) (int, bool) {
+	literal = strings.TrimSpace(literal)
+	for i := pos; i < len(t.tokens); i++ {
+		text := t.text(i)
+		if i > pos {
+			trimmed := strings.TrimLeftFunc(literal, unicode.IsSpace)
+			if len(trimmed) == len(literal) && isWordBoundaryJoin(t.text(i-1), text) {
+				return 0, false
+			}
+			literal = trimmed
+		}
+
+		for text != "" {
+			if literal == "" {
+				// The literal ends within the token.
+				return 0, false
+			}
+			lr, ln := utf8.DecodeRuneInString(literal)
+			tr, tn := utf8.DecodeRuneInString(text)
+			if unicode.IsSpace(lr) && unicode.IsSpace(tr) {
+				literal = strings.TrimLeftFunc(literal, unicode.IsSpace)
+				text = strings.TrimLeftFunc(text, unicode.IsSpace)
+				continue
+			}
+			if lr != tr {
+				return 0, false
+			}
+			literal, text = literal[ln:], text[tn:]
+		}
+
+		if literal == "" {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// isWordBoundaryJoin reports whether joining the tokens a and b without
+// whitespace would merge two words.
+func isWordBoundaryJoin(a, b string) bool {
+	ar, _ := utf8.DecodeLastRuneInString(a)
+	br, _ := utf8.DecodeRuneInString(b)
+	return isWordRune(ar) && isWordRune(br)
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,171 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// toySyntaxTree tokenizes src into words, double quoted strings and single
+// punctuation characters, and builds a syntax tree in which every bracketed
+// group is a node and every other token is a leaf. It lets the matcher be
+// tested independently of tree-sitter grammars.
+func toySyntaxTree(src string) *syntaxTree {
+	buf := []byte(src)
+	var tokens []structuralToken
+	root := &syntaxNode{}
+	stack := []*syntaxNode{root}
+
+	for i := 0; i < len(buf); {
+		start := i
+		switch c := buf[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+			continue
+		case isWordRune(rune(c)):
+			for i < len(buf) && isWordRune(rune(buf[i])) {
+				i++
+			}
+		case c == '"':
+			i++
+			for i < len(buf) && buf[i] != '"' {
+				i++
+			}
+			i++
+		default:
+			i++
+		}
+
+		leaf := &syntaxNode{first: len(tokens), last: len(tokens) + 1}
+		tokens = append(tokens, structuralToken{start: start, end: i})
+
+		top := stack[len(stack)-1]
+		switch buf[start] {
+		case '(', '[', '{':
+			group := &syntaxNode{first: leaf.first, children: []*syntaxNode{leaf}}
+			top.children = append(top.children, group)
+			stack = append(stack, group)
+		case ')', ']', '}':
+			top.children = append(top.children, leaf)
+			top.last = leaf.last
+			stack = stack[:len(stack)-1]
+		default:
+			top.children = append(top.children, leaf)
+		}
+	}
+	root.last = len(tokens)
+	return newSyntaxTree(buf, tokens, root)
+}
+
+func TestStructuralMatcher(t *testing.T) {
+	cases := []struct {
+		name    string
+		pattern string
+		src     string
+		want    []string
+	}{{
+		name:    "literal",
+		pattern: "foo(bar)",
+		src:     "x := foo( bar ); foo(baz)",
+		want:    []string{"foo( bar )"},
+	}, {
+		name:    "literal does not join words",
+		pattern: "funcmain",
+		src:     "func main",
+		want:    nil,
+	}, {
+		name:    "literal must cover whole tokens",
+		pattern: "fo(",
+		src:     "foo(x)",
+		want:    nil,
+	}, {
+		name:    "hole matches balanced arguments",
+		pattern: "foo(:[args])",
+		src:     "foo(a, bar(b, c)) + foo()",
+		want:    []string{"foo(a, bar(b, c))", "foo()"},
+	}, {
+		name:    "hole does not escape its group",
+		pattern: "(:[x] + b)",
+		src:     "(a + (c) + b)",
+		want:    []string{"(a + (c) + b)"},
+	}, {
+		name:    "hole cannot end inside a group",
+		pattern: "f(:[x]) + 1",
+		src:     "f(g(a) + 1)",
+		want:    nil,
+	}, {
+		name:    "ellipsis",
+		pattern: "if ... {",
+		src:     "if x > (y) { z }",
+		want:    []string{"if x > (y) {"},
+	}, {
+		name:    "repeated hole",
+		pattern: ":[[x]] = :[[x]]",
+		src:     "a = b; c = c",
+		want:    []string{"c = c"},
+	}, {
+		name:    "repeated hole over several tokens",
+		pattern: "assert(:[x] == :[x])",
+		src:     "assert(f(a) == f(a)) assert(f(a) == f(b))",
+		want:    []string{"assert(f(a) == f(a))"},
+	}, {
+		name:    "word hole",
+		pattern: "print(:[[x]])",
+		src:     `print(y) print("s") print(a.b)`,
+		want:    []string{"print(y)"},
+	}, {
+		name:    "regexp hole",
+		pattern: `print(:[x~"[a-z]+"])`,
+		src:     `print("abc") print("ABC") print(abc)`,
+		want:    []string{`print("abc")`},
+	}, {
+		name:    "string tokens are atomic",
+		pattern: `"a b"`,
+		src:     `x("a  b") y("ab")`,
+		want:    []string{`"a  b"`},
+	}, {
+		name:    "matches do not overlap",
+		pattern: "a a",
+		src:     "a a a a a",
+		want:    []string{"a a", "a a"},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := newStructuralMatcher(tc.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tree := toySyntaxTree(tc.src)
+
+			var got []string
+			for _, r := range m.Match(tree) {
+				got = append(got, tc.src[tree.tokens[r.start].start:tree.tokens[r.end-1].end])
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected matches (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseStructuralPattern(t *testing.T) {
+	for _, pattern := range []string{"", "  ", "foo(:[x.])", ":[]", ":[[x]", ":[x~(]"} {
+		if _, err := parseStructuralPattern(pattern); err == nil {
+			t.Errorf("expected error for pattern %q", pattern)
+		}
+	}
+
+	elements, err := parseStructuralPattern(`f(:[x], :[[y]], :[z~[a-z]+], ...)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var holes []holeKind
+	for _, e := range elements {
+		holes = append(holes, e.hole)
+	}
+	want := []holeKind{holeNone, holeAny, holeNone, holeWord, holeNone, holeRegexp, holeNone, holeAny, holeNone}
+	if diff := cmp.Diff(want, holes); diff != "" {
+		t.Errorf("unexpected elements (-want +got):\n%s", diff)
+	}
+}
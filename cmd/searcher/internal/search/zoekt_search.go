@@ -167,7 +167,7 @@ func zoektSearch(ctx context.Context, args *search.TextPatternInfo, branchRepos
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		err := structuralSearch(ctx, comby.Tar{TarInputEventC: tarInputEventC}, all, extensionHint, args.Pattern, args.CombyRule, args.Languages, repo, sender)
+		err := structuralSearch(ctx, comby.Tar{TarInputEventC: tarInputEventC}, all, extensionHint, args.Pattern, args.CombyRule, args.StructuralEngine, args.Languages, repo, sender)
 		if err != nil {
 			log.NamedError("structural search error", err)
 		}
@@ -57,6 +57,11 @@ type Request struct {
 	// will only search what has changed since Zoekt has indexed as well as
 	// including Zoekt results.
 	FeatHybrid bool `json:"feat_hybrid,omitempty"`
+
+	// FeatStructuralTreeSitter is a feature flag which runs structural searches
+	// without an explicit StructuralEngine with tree-sitter, unless they use a
+	// rule or a hole syntax that only comby supports.
+	FeatStructuralTreeSitter bool `json:"feat_structural_tree_sitter,omitempty"`
 }
 
 // PatternInfo describes a search request on a repo. Most of the fields
@@ -127,12 +132,28 @@ type PatternInfo struct {
 	// file list in the frontend and passes it to searcher.
 	CombyRule string
 
+	// StructuralEngine selects the engine that runs structural searches. It
+	// is one of the StructuralEngine constants, and defaults to comby when
+	// empty. It only applies when IsStructuralPat is true.
+	StructuralEngine string
+
 	// Select is the value of the the select field in the query. It is not necessary to
 	// use it since selection is done after the query completes, but exposing it can enable
 	// optimizations.
 	Select string
 }
 
+const (
+	// StructuralEngineComby runs structural searches with the comby binary.
+	StructuralEngineComby = "comby"
+
+	// StructuralEngineTreeSitter runs structural searches in-process,
+	// matching patterns against syntax trees parsed with tree-sitter. It
+	// does not support CombyRule, and only searches files in languages that
+	// searcher has a tree-sitter grammar for.
+	StructuralEngineTreeSitter = "tree-sitter"
+)
+
 func (p *PatternInfo) String() string {
 	args := []string{fmt.Sprintf("%q", p.Pattern)}
 	if p.IsRegExp {
@@ -144,6 +165,9 @@ func (p *PatternInfo) String() string {
 		} else {
 			args = append(args, "comby")
 		}
+		if p.StructuralEngine != "" {
+			args = append(args, fmt.Sprintf("engine:%s", p.StructuralEngine))
+		}
 	}
 	if p.IsWordMatch {
 		args = append(args, "word")
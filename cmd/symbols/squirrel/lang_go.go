@@ -0,0 +1,311 @@
+package squirrel
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/regexp"
+	sitter "github.com/smacker/go-tree-sitter"
+
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func (squirrel *SquirrelService) getDefGo(ctx context.Context, node Node) (ret *Node, err error) {
+	defer squirrel.onCall(node, String(node.Type()), lazyNodeStringer(&ret))()
+
+	parent := node.Parent()
+
+	switch node.Type() {
+	case "identifier", "type_identifier":
+		// Check for the name in a qualified type, e.g. `Buffer` in `bytes.Buffer`
+		if parent != nil && parent.Type() == "qualified_type" {
+			pkg := parent.ChildByFieldName("package")
+			name := parent.ChildByFieldName("name")
+			if pkg != nil && name != nil && nodeId(name) == nodeId(node.Node) {
+				return squirrel.getQualifiedDefGo(ctx, swapNode(node, pkg), node.Content(node.Contents))
+			}
+		}
+
+		ident := node.Content(node.Contents)
+
+		// Check for definitions in the file
+		found, err := findLocalDef(node)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			return found, nil
+		}
+		found = findDeclGo(node, ident)
+		if found != nil {
+			return found, nil
+		}
+
+		// Check for imported packages, e.g. `fmt` in `fmt.Println()`
+		imports, err := squirrel.getImportsGo(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+		for _, imp := range imports {
+			if imp.name == ident {
+				return dirNode(node, imp.dir), nil
+			}
+		}
+
+		// Check for definitions in the other files of the package
+		found, err = squirrel.symbolSearchOne(ctx, node.RepoCommitPath.Repo, node.RepoCommitPath.Commit, []string{goPackagePattern(filepath.Dir(node.RepoCommitPath.Path))}, ident)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			return found, nil
+		}
+
+		// Check for definitions in dot imports, e.g. `import . "fmt"`
+		for _, imp := range imports {
+			if imp.name != "." {
+				continue
+			}
+			found, err = squirrel.symbolSearchOne(ctx, node.RepoCommitPath.Repo, node.RepoCommitPath.Commit, []string{goPackagePattern(imp.dir)}, ident)
+			if err != nil {
+				return nil, err
+			}
+			if found != nil {
+				return found, nil
+			}
+		}
+		return nil, nil
+
+	// Check for the selector in a selector expression, e.g. `Println` in `fmt.Println()`
+	case "field_identifier":
+		if parent == nil || parent.Type() != "selector_expression" {
+			return nil, nil
+		}
+		operand := parent.ChildByFieldName("operand")
+		if operand == nil || operand.Type() != "identifier" {
+			return nil, nil
+		}
+		return squirrel.getQualifiedDefGo(ctx, swapNode(node, operand), node.Content(node.Contents))
+
+	// Check for the package in a qualified type or an import, e.g. `bytes` in `bytes.Buffer`
+	case "package_identifier":
+		if parent == nil {
+			return nil, nil
+		}
+		switch parent.Type() {
+		case "qualified_type":
+			imp, err := squirrel.getImportGo(ctx, node)
+			if err != nil {
+				return nil, err
+			}
+			if imp == nil {
+				return nil, nil
+			}
+			return dirNode(node, imp.dir), nil
+		case "import_spec":
+			return squirrel.getImportSpecDirGo(ctx, swapNode(node, parent))
+		}
+		return nil, nil
+
+	// Check for the path of an import, e.g. `"fmt"` in `import "fmt"`
+	case "interpreted_string_literal", "raw_string_literal":
+		if parent == nil || parent.Type() != "import_spec" {
+			return nil, nil
+		}
+		return squirrel.getImportSpecDirGo(ctx, swapNode(node, parent))
+
+	// No other nodes have a definition
+	default:
+		return nil, nil
+	}
+}
+
+// getQualifiedDefGo returns the definition of name in the package that pkg refers to, e.g. `Println`
+// in `fmt.Println()`.
+func (squirrel *SquirrelService) getQualifiedDefGo(ctx context.Context, pkg Node, name string) (ret *Node, err error) {
+	defer squirrel.onCall(pkg, &Tuple{String(pkg.Type()), String(name)}, lazyNodeStringer(&ret))()
+
+	// The operand might be a variable that shadows a package, in which case we'd need its type to
+	// find the field.
+	if pkg.Type() == "identifier" {
+		found, err := findLocalDef(pkg)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			squirrel.breadcrumb(pkg, "getQualifiedDefGo: operand is a local variable")
+			return nil, nil
+		}
+	}
+
+	imp, err := squirrel.getImportGo(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+	if imp == nil {
+		return nil, nil
+	}
+	return squirrel.symbolSearchOne(ctx, pkg.RepoCommitPath.Repo, pkg.RepoCommitPath.Commit, []string{goPackagePattern(imp.dir)}, name)
+}
+
+// getImportSpecDirGo returns the directory of the package imported by the given import_spec.
+func (squirrel *SquirrelService) getImportSpecDirGo(ctx context.Context, spec Node) (*Node, error) {
+	imports, err := squirrel.getImportsGo(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	for _, imp := range imports {
+		if nodeId(imp.spec) == nodeId(spec.Node) {
+			return dirNode(spec, imp.dir), nil
+		}
+	}
+	return nil, nil
+}
+
+// A package imported by a Go file.
+type importGo struct {
+	spec *sitter.Node
+	// The name that the package is referred to by in the file.
+	name string
+	// The directory of the package in the repository.
+	dir string
+}
+
+// getImportGo returns the import that the given package name refers to, or nil if it's not a package
+// in the repository.
+func (squirrel *SquirrelService) getImportGo(ctx context.Context, pkg Node) (*importGo, error) {
+	imports, err := squirrel.getImportsGo(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+	name := pkg.Content(pkg.Contents)
+	for _, imp := range imports {
+		if imp.name == name {
+			return &imp, nil
+		}
+	}
+	return nil, nil
+}
+
+// getImportsGo returns the packages in the repository that the file containing the given node
+// imports.
+func (squirrel *SquirrelService) getImportsGo(ctx context.Context, node Node) ([]importGo, error) {
+	specs, err := allCaptures("(import_spec) @spec", swapNode(node, getRoot(node.Node)))
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	module := squirrel.getModuleGo(ctx, node.RepoCommitPath)
+
+	imports := []importGo{}
+	for _, spec := range specs {
+		pathNode := spec.ChildByFieldName("path")
+		if pathNode == nil {
+			continue
+		}
+		importPath, err := strconv.Unquote(pathNode.Content(node.Contents))
+		if err != nil {
+			continue
+		}
+		dir, ok := importDirGo(node.RepoCommitPath.Repo, module, importPath)
+		if !ok {
+			continue
+		}
+		name := defaultPackageNameGo(importPath)
+		if nameNode := spec.ChildByFieldName("name"); nameNode != nil {
+			name = nameNode.Content(node.Contents)
+		}
+		imports = append(imports, importGo{spec: spec.Node, name: name, dir: dir})
+	}
+	return imports, nil
+}
+
+var goModuleRegex = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+
+// getModuleGo returns the module path declared in the go.mod file at the root of the repository, or
+// the empty string if there is none.
+func (squirrel *SquirrelService) getModuleGo(ctx context.Context, repoCommitPath types.RepoCommitPath) string {
+	contents, err := squirrel.readFile(ctx, types.RepoCommitPath{
+		Repo:   repoCommitPath.Repo,
+		Commit: repoCommitPath.Commit,
+		Path:   "go.mod",
+	})
+	if err != nil {
+		return ""
+	}
+	matches := goModuleRegex.FindSubmatch(contents)
+	if matches == nil {
+		return ""
+	}
+	return strings.Trim(string(matches[1]), `"`)
+}
+
+// importDirGo returns the directory in the repository of the package with the given import path, and
+// false if the package is not in the repository. Packages are in the repository when their import
+// path starts with the module path or with the name of the repository, e.g. `github.com/foo/bar/baz`
+// is the directory `baz` in the repository `github.com/foo/bar`.
+func importDirGo(repo, module, importPath string) (string, bool) {
+	for _, prefix := range []string{module, repo} {
+		if prefix == "" {
+			continue
+		}
+		if importPath == prefix {
+			return "", true
+		}
+		if strings.HasPrefix(importPath, prefix+"/") {
+			return strings.TrimPrefix(importPath, prefix+"/"), true
+		}
+	}
+	return "", false
+}
+
+var goMajorVersionRegex = regexp.MustCompile(`^v[0-9]+$`)
+
+// defaultPackageNameGo returns the name a package is referred to by when it's imported without a
+// name, which by convention is the last element of the import path without the major version.
+func defaultPackageNameGo(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	if len(elems) > 1 && goMajorVersionRegex.MatchString(elems[len(elems)-1]) {
+		elems = elems[:len(elems)-1]
+	}
+	return elems[len(elems)-1]
+}
+
+// goPackagePattern returns the include pattern for the files of the package in the given directory.
+func goPackagePattern(dir string) string {
+	if dir == "" || dir == "." {
+		return `^[^/]+\.go$`
+	}
+	return fmt.Sprintf(`^%s/[^/]+\.go$`, regexp.QuoteMeta(dir))
+}
+
+// findDeclGo finds function and type declarations in the blocks enclosing the given node, which the
+// localsQuery doesn't cover.
+func findDeclGo(node Node, ident string) *Node {
+	for cur := node.Node.Parent(); cur != nil; cur = cur.Parent() {
+		if cur.Type() != "block" && cur.Type() != "source_file" {
+			continue
+		}
+		for _, child := range children(cur) {
+			switch child.Type() {
+			case "function_declaration":
+				if name := child.ChildByFieldName("name"); name != nil && name.Content(node.Contents) == ident {
+					return swapNodePtr(node, name)
+				}
+			case "type_declaration":
+				for _, spec := range children(child) {
+					if name := spec.ChildByFieldName("name"); name != nil && name.Content(node.Contents) == ident {
+						return swapNodePtr(node, name)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,365 @@
+package squirrel
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/regexp"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+func (squirrel *SquirrelService) getDefPython(ctx context.Context, node Node) (ret *Node, err error) {
+	defer squirrel.onCall(node, String(node.Type()), lazyNodeStringer(&ret))()
+
+	if node.Type() != "identifier" {
+		// No other nodes have a definition
+		return nil, nil
+	}
+
+	ident := node.Content(node.Contents)
+
+	// Check for the attribute in an attribute access, e.g. `f` in `self.f` or `module.f`
+	if parent := node.Parent(); parent != nil && parent.Type() == "attribute" {
+		object := parent.ChildByFieldName("object")
+		attribute := parent.ChildByFieldName("attribute")
+		if object != nil && attribute != nil && nodeId(attribute) == nodeId(node.Node) {
+			return squirrel.getAttributePython(ctx, swapNode(node, object), ident)
+		}
+	}
+
+	// Check for names in import statements
+	cur := node.Node.Parent()
+	for cur != nil && (cur.Type() == "dotted_name" || cur.Type() == "aliased_import" || cur.Type() == "relative_import") {
+		cur = cur.Parent()
+	}
+	if cur != nil {
+		switch cur.Type() {
+		case "import_statement":
+			// Modules aren't symbols
+			return nil, nil
+		case "import_from_statement":
+			moduleName := cur.ChildByFieldName("module_name")
+			if moduleName != nil && moduleName.StartByte() <= node.StartByte() && node.EndByte() <= moduleName.EndByte() {
+				// Modules aren't symbols
+				return nil, nil
+			}
+			imports := getImportsPython(swapNode(node, cur))
+			for _, imp := range imports {
+				if imp.local == ident || imp.name == ident {
+					return squirrel.getImportedDefPython(ctx, node, imp)
+				}
+			}
+			return nil, nil
+		}
+	}
+
+	// Check for definitions in the file
+	found, err := findLocalDef(node)
+	if err != nil {
+		return nil, err
+	}
+	if found != nil {
+		return found, nil
+	}
+	found = findDeclPython(node, ident)
+	if found != nil {
+		return found, nil
+	}
+
+	// Check for imported names
+	imports := getImportsPython(swapNode(node, getRoot(node.Node)))
+	for _, imp := range imports {
+		if imp.local == ident {
+			return squirrel.getImportedDefPython(ctx, node, imp)
+		}
+	}
+
+	// Check for names in wildcard imports, e.g. `from a import *`
+	for _, imp := range imports {
+		if imp.name != "*" {
+			continue
+		}
+		found, err := squirrel.symbolSearchOne(ctx, node.RepoCommitPath.Repo, node.RepoCommitPath.Commit, []string{modulePatternPython(node.RepoCommitPath.Path, imp.module)}, ident)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			return found, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getAttributePython returns the definition of the attribute of the given object, e.g. `f` in
+// `self.f` or `module.f`.
+func (squirrel *SquirrelService) getAttributePython(ctx context.Context, object Node, attribute string) (ret *Node, err error) {
+	defer squirrel.onCall(object, &Tuple{String(object.Type()), String(attribute)}, lazyNodeStringer(&ret))()
+
+	if object.Type() == "identifier" {
+		name := object.Content(object.Contents)
+
+		// Check for members of the enclosing class
+		if name == "self" || name == "cls" {
+			for cur := object.Node; cur != nil; cur = cur.Parent() {
+				if cur.Type() == "class_definition" {
+					return squirrel.getClassMemberPython(ctx, swapNode(object, cur), attribute)
+				}
+			}
+			return nil, nil
+		}
+
+		// Check for members of classes in the file
+		found, err := findLocalDef(object)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			// It's a variable, and we'd need its type to find the attribute.
+			return nil, nil
+		}
+		found = findDeclPython(object, name)
+		if found != nil {
+			if found.Parent() != nil && found.Parent().Type() == "class_definition" {
+				return squirrel.getClassMemberPython(ctx, swapNode(object, found.Parent()), attribute)
+			}
+			return nil, nil
+		}
+	}
+
+	// Check for attributes of imported modules, e.g. `a.b.f` after `import a.b`
+	if !pythonDottedNameRegex.MatchString(object.Content(object.Contents)) {
+		return nil, nil
+	}
+	components := strings.Split(object.Content(object.Contents), ".")
+	for _, imp := range getImportsPython(swapNode(object, getRoot(object.Node))) {
+		if imp.local != components[0] || imp.name == "*" {
+			continue
+		}
+		module := imp.module
+		if imp.name != "" {
+			module = joinModulesPython(imp.module, imp.name)
+		}
+		for _, component := range components[1:] {
+			module = joinModulesPython(module, component)
+		}
+		return squirrel.symbolSearchOne(ctx, object.RepoCommitPath.Repo, object.RepoCommitPath.Commit, []string{modulePatternPython(object.RepoCommitPath.Path, module)}, attribute)
+	}
+
+	return nil, nil
+}
+
+var pythonDottedNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// getClassMemberPython returns the definition of the method or class attribute with the given name
+// in the given class_definition or its superclasses.
+func (squirrel *SquirrelService) getClassMemberPython(ctx context.Context, class Node, name string) (ret *Node, err error) {
+	defer squirrel.onCall(class, &Tuple{String(class.Type()), String(name)}, lazyNodeStringer(&ret))()
+
+	body := class.ChildByFieldName("body")
+	if body == nil {
+		return nil, nil
+	}
+	for _, child := range children(body) {
+		if found := findNamePython(class, child, name); found != nil {
+			return found, nil
+		}
+		// Class attributes, e.g. `x = 5`
+		if child.Type() == "expression_statement" {
+			for _, assignment := range children(child) {
+				if assignment.Type() != "assignment" {
+					continue
+				}
+				left := assignment.ChildByFieldName("left")
+				if left != nil && left.Type() == "identifier" && left.Content(class.Contents) == name {
+					return swapNodePtr(class, left), nil
+				}
+			}
+		}
+	}
+
+	// Check the superclasses, e.g. `B` in `class A(B): ...`
+	superclasses := class.ChildByFieldName("superclasses")
+	if superclasses == nil {
+		return nil, nil
+	}
+	for _, superclass := range children(superclasses) {
+		if superclass.Type() != "identifier" {
+			continue
+		}
+		def, err := squirrel.getDefPython(ctx, swapNode(class, superclass))
+		if err != nil {
+			return nil, err
+		}
+		if def == nil || def.Node == nil || def.Parent() == nil || def.Parent().Type() != "class_definition" {
+			continue
+		}
+		found, err := squirrel.getClassMemberPython(ctx, swapNode(*def, def.Parent()), name)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			return found, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getImportedDefPython returns the definition of the name bound by the given import.
+func (squirrel *SquirrelService) getImportedDefPython(ctx context.Context, node Node, imp importPython) (*Node, error) {
+	if imp.name == "" || imp.name == "*" {
+		// Modules aren't symbols
+		return nil, nil
+	}
+	return squirrel.symbolSearchOne(ctx, node.RepoCommitPath.Repo, node.RepoCommitPath.Commit, []string{modulePatternPython(node.RepoCommitPath.Path, imp.module)}, imp.name)
+}
+
+// A name bound by a Python import statement.
+type importPython struct {
+	// The name bound in the file.
+	local string
+	// The module the name refers to for `import a.b` statements, or the module the name is imported
+	// from for `from a.b import c` statements. Relative modules start with dots, e.g. `..a`.
+	module string
+	// The name imported from the module for `from a.b import c` statements, "*" for wildcard
+	// imports, and the empty string for `import a.b` statements.
+	name string
+}
+
+// getImportsPython returns the names bound by the import statements in the given node.
+func getImportsPython(node Node) []importPython {
+	imports := []importPython{}
+	walkFilter(node.Node, func(cur *sitter.Node) bool {
+		switch cur.Type() {
+		case "import_statement":
+			for _, child := range children(cur) {
+				switch child.Type() {
+				case "dotted_name":
+					// `import a.b` binds `a`
+					first := strings.Split(child.Content(node.Contents), ".")[0]
+					imports = append(imports, importPython{local: first, module: first})
+				case "aliased_import":
+					name := child.ChildByFieldName("name")
+					alias := child.ChildByFieldName("alias")
+					if name == nil || alias == nil {
+						continue
+					}
+					imports = append(imports, importPython{local: alias.Content(node.Contents), module: name.Content(node.Contents)})
+				}
+			}
+			return false
+		case "import_from_statement":
+			moduleName := cur.ChildByFieldName("module_name")
+			if moduleName == nil {
+				return false
+			}
+			module := moduleName.Content(node.Contents)
+			for _, child := range children(cur) {
+				if nodeId(child) == nodeId(moduleName) {
+					continue
+				}
+				switch child.Type() {
+				case "dotted_name":
+					name := child.Content(node.Contents)
+					imports = append(imports, importPython{local: name, module: module, name: name})
+				case "aliased_import":
+					name := child.ChildByFieldName("name")
+					alias := child.ChildByFieldName("alias")
+					if name == nil || alias == nil {
+						continue
+					}
+					imports = append(imports, importPython{local: alias.Content(node.Contents), module: module, name: name.Content(node.Contents)})
+				case "wildcard_import":
+					imports = append(imports, importPython{module: module, name: "*"})
+				}
+			}
+			return false
+		}
+		return true
+	})
+	return imports
+}
+
+// joinModulesPython returns the module for name in module, e.g. `a.b` for `b` in `a` and `..b` for
+// `b` in `..`.
+func joinModulesPython(module, name string) string {
+	if strings.HasSuffix(module, ".") {
+		return module + name
+	}
+	return module + "." + name
+}
+
+// modulePatternPython returns the include pattern for the file of the given module imported from the
+// file at path. Absolute modules can be in any directory because the roots of Python packages
+// aren't known, while relative modules are resolved against the directory of the file.
+func modulePatternPython(path string, module string) string {
+	dots := len(module) - len(strings.TrimLeft(module, "."))
+	components := []string{}
+	for _, component := range strings.Split(module[dots:], ".") {
+		if component != "" {
+			components = append(components, component)
+		}
+	}
+
+	if dots == 0 {
+		return fmt.Sprintf(`(^|/)%s(\.py|/__init__\.py)$`, regexp.QuoteMeta(strings.Join(components, "/")))
+	}
+
+	dir := filepath.Dir(path)
+	for i := 1; i < dots; i++ {
+		dir = filepath.Dir(dir)
+	}
+	dir = filepath.Join(append([]string{dir}, components...)...)
+	if len(components) == 0 {
+		// `from . import a` imports from the package of the directory
+		if dir == "." {
+			return `^__init__\.py$`
+		}
+		return fmt.Sprintf(`^%s/__init__\.py$`, regexp.QuoteMeta(dir))
+	}
+	return fmt.Sprintf(`^%s(\.py|/__init__\.py)$`, regexp.QuoteMeta(dir))
+}
+
+// findDeclPython finds function and class definitions in the blocks enclosing the given node, which
+// the localsQuery doesn't cover.
+func findDeclPython(node Node, ident string) *Node {
+	inFunction := false
+	for cur := node.Node.Parent(); cur != nil; cur = cur.Parent() {
+		switch cur.Type() {
+		case "function_definition", "lambda":
+			inFunction = true
+		case "block", "module":
+			// Class members aren't in scope in the methods of the class.
+			if inFunction && cur.Parent() != nil && cur.Parent().Type() == "class_definition" {
+				continue
+			}
+			for _, child := range children(cur) {
+				if found := findNamePython(node, child, ident); found != nil {
+					return found
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findNamePython returns the name of the given function or class definition if it matches ident.
+func findNamePython(other Node, def *sitter.Node, ident string) *Node {
+	if def.Type() == "decorated_definition" {
+		def = def.ChildByFieldName("definition")
+		if def == nil {
+			return nil
+		}
+	}
+	if def.Type() != "function_definition" && def.Type() != "class_definition" {
+		return nil
+	}
+	name := def.ChildByFieldName("name")
+	if name == nil || name.Content(other.Contents) != ident {
+		return nil
+	}
+	return swapNodePtr(other, name)
+}
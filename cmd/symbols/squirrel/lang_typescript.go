@@ -0,0 +1,282 @@
+package squirrel
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/regexp"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+func (squirrel *SquirrelService) getDefTypescript(ctx context.Context, node Node) (ret *Node, err error) {
+	defer squirrel.onCall(node, String(node.Type()), lazyNodeStringer(&ret))()
+
+	parent := node.Parent()
+
+	switch node.Type() {
+	case "identifier", "type_identifier":
+		ident := node.Content(node.Contents)
+
+		// Check for the name in a namespaced type, e.g. `T` in `ns.T`
+		if parent != nil && parent.Type() == "nested_type_identifier" {
+			module := parent.ChildByFieldName("module")
+			name := parent.ChildByFieldName("name")
+			if module != nil && name != nil && nodeId(name) == nodeId(node.Node) {
+				return squirrel.getNamespaceMemberTypescript(ctx, swapNode(node, module), ident)
+			}
+		}
+
+		// Check for names in import statements
+		for cur := parent; cur != nil; cur = cur.Parent() {
+			if cur.Type() == "import_statement" {
+				for _, imp := range getImportsTypescript(swapNode(node, cur)) {
+					if imp.local == ident || imp.name == ident {
+						return squirrel.getImportedDefTypescript(ctx, node, imp)
+					}
+				}
+				return nil, nil
+			}
+			if cur.Type() != "import_clause" && cur.Type() != "named_imports" && cur.Type() != "import_specifier" && cur.Type() != "namespace_import" {
+				break
+			}
+		}
+
+		// Check for definitions in the file
+		found, err := findLocalDef(node)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			return found, nil
+		}
+		found = findDeclTypescript(node, ident)
+		if found != nil {
+			return found, nil
+		}
+
+		// Check for imported names
+		for _, imp := range getImportsTypescript(swapNode(node, getRoot(node.Node))) {
+			if imp.local == ident {
+				return squirrel.getImportedDefTypescript(ctx, node, imp)
+			}
+		}
+		return nil, nil
+
+	// Check for the property in a member expression, e.g. `f` in `this.f` or `ns.f`
+	case "property_identifier":
+		if parent == nil || parent.Type() != "member_expression" {
+			return nil, nil
+		}
+		object := parent.ChildByFieldName("object")
+		property := parent.ChildByFieldName("property")
+		if object == nil || property == nil || nodeId(property) != nodeId(node.Node) {
+			return nil, nil
+		}
+		ident := node.Content(node.Contents)
+		switch object.Type() {
+		case "this":
+			for cur := object; cur != nil; cur = cur.Parent() {
+				switch cur.Type() {
+				case "class_declaration", "abstract_class_declaration", "class":
+					return findClassMemberTypescript(swapNode(node, cur), ident), nil
+				}
+			}
+			return nil, nil
+		case "identifier":
+			return squirrel.getNamespaceMemberTypescript(ctx, swapNode(node, object), ident)
+		}
+		return nil, nil
+
+	// No other nodes have a definition
+	default:
+		return nil, nil
+	}
+}
+
+// getNamespaceMemberTypescript returns the definition of name in the module that the given namespace
+// import refers to, e.g. `f` in `ns.f` after `import * as ns from "./ns"`.
+func (squirrel *SquirrelService) getNamespaceMemberTypescript(ctx context.Context, namespace Node, name string) (ret *Node, err error) {
+	defer squirrel.onCall(namespace, &Tuple{String(namespace.Type()), String(name)}, lazyNodeStringer(&ret))()
+
+	// The object might be a variable that shadows the import, in which case we'd need its type to
+	// find the property.
+	found, err := findLocalDef(namespace)
+	if err != nil {
+		return nil, err
+	}
+	if found != nil {
+		return nil, nil
+	}
+
+	for _, imp := range getImportsTypescript(swapNode(namespace, getRoot(namespace.Node))) {
+		if imp.local != namespace.Content(namespace.Contents) || imp.name != "*" {
+			continue
+		}
+		pattern, ok := modulePatternTypescript(namespace.RepoCommitPath.Path, imp.source)
+		if !ok {
+			return nil, nil
+		}
+		return squirrel.symbolSearchOne(ctx, namespace.RepoCommitPath.Repo, namespace.RepoCommitPath.Commit, []string{pattern}, name)
+	}
+	return nil, nil
+}
+
+// getImportedDefTypescript returns the definition of the name bound by the given import.
+func (squirrel *SquirrelService) getImportedDefTypescript(ctx context.Context, node Node, imp importTypescript) (*Node, error) {
+	pattern, ok := modulePatternTypescript(node.RepoCommitPath.Path, imp.source)
+	if !ok {
+		return nil, nil
+	}
+	name := imp.name
+	switch name {
+	case "*":
+		// Modules aren't symbols
+		return nil, nil
+	case "default":
+		// The default export is usually declared with the same name that it's imported as.
+		name = imp.local
+	}
+	return squirrel.symbolSearchOne(ctx, node.RepoCommitPath.Repo, node.RepoCommitPath.Commit, []string{pattern}, name)
+}
+
+// A name bound by a TypeScript import statement.
+type importTypescript struct {
+	// The name bound in the file.
+	local string
+	// The name exported by the module, "default" for default imports, and "*" for namespace imports.
+	name string
+	// The module specifier without quotes, e.g. `./a`.
+	source string
+}
+
+// getImportsTypescript returns the names bound by the import statements in the given node.
+func getImportsTypescript(node Node) []importTypescript {
+	imports := []importTypescript{}
+	walkFilter(node.Node, func(cur *sitter.Node) bool {
+		if cur.Type() != "import_statement" {
+			return true
+		}
+		sourceNode := cur.ChildByFieldName("source")
+		if sourceNode == nil {
+			return false
+		}
+		source := strings.Trim(sourceNode.Content(node.Contents), "\"'`")
+		for _, clause := range children(cur) {
+			if clause.Type() != "import_clause" {
+				continue
+			}
+			for _, child := range children(clause) {
+				switch child.Type() {
+				case "identifier":
+					// import a from "./a"
+					imports = append(imports, importTypescript{local: child.Content(node.Contents), name: "default", source: source})
+				case "namespace_import":
+					// import * as a from "./a"
+					for _, ident := range children(child) {
+						if ident.Type() == "identifier" {
+							imports = append(imports, importTypescript{local: ident.Content(node.Contents), name: "*", source: source})
+						}
+					}
+				case "named_imports":
+					// import { a, b as c } from "./a"
+					for _, specifier := range children(child) {
+						if specifier.Type() != "import_specifier" {
+							continue
+						}
+						name := specifier.ChildByFieldName("name")
+						if name == nil {
+							continue
+						}
+						local := name
+						if alias := specifier.ChildByFieldName("alias"); alias != nil {
+							local = alias
+						}
+						imports = append(imports, importTypescript{local: local.Content(node.Contents), name: name.Content(node.Contents), source: source})
+					}
+				}
+			}
+		}
+		return false
+	})
+	return imports
+}
+
+// modulePatternTypescript returns the include pattern for the files of the module with the given
+// specifier imported from the file at path, and false for modules outside of the repository such as
+// packages in node_modules.
+func modulePatternTypescript(path string, source string) (string, bool) {
+	if source != "." && source != ".." && !strings.HasPrefix(source, "./") && !strings.HasPrefix(source, "../") {
+		return "", false
+	}
+
+	module := filepath.Join(filepath.Dir(path), source)
+	if module == ".." || strings.HasPrefix(module, "../") {
+		return "", false
+	}
+	switch filepath.Ext(module) {
+	case ".js", ".jsx", ".ts", ".tsx":
+		module = strings.TrimSuffix(module, filepath.Ext(module))
+	}
+
+	if module == "." {
+		return `^index(\.d\.ts|\.tsx?|\.jsx?)$`, true
+	}
+	return fmt.Sprintf(`^%s(\.d\.ts|\.tsx?|\.jsx?|/index\.d\.ts|/index\.tsx?|/index\.jsx?)$`, regexp.QuoteMeta(module)), true
+}
+
+// findDeclTypescript finds declarations in the blocks enclosing the given node, which the
+// localsQuery doesn't cover, e.g. classes and interfaces.
+func findDeclTypescript(node Node, ident string) *Node {
+	for cur := node.Node.Parent(); cur != nil; cur = cur.Parent() {
+		if cur.Type() != "statement_block" && cur.Type() != "program" {
+			continue
+		}
+		for _, child := range children(cur) {
+			// export function f() { ... }
+			if child.Type() == "export_statement" {
+				child = child.ChildByFieldName("declaration")
+				if child == nil {
+					continue
+				}
+			}
+
+			switch child.Type() {
+			case "function_declaration", "generator_function_declaration", "class_declaration", "abstract_class_declaration", "interface_declaration", "type_alias_declaration", "enum_declaration":
+				if name := child.ChildByFieldName("name"); name != nil && name.Content(node.Contents) == ident {
+					return swapNodePtr(node, name)
+				}
+			case "lexical_declaration", "variable_declaration":
+				for _, declarator := range children(child) {
+					if declarator.Type() != "variable_declarator" {
+						continue
+					}
+					if name := declarator.ChildByFieldName("name"); name != nil && name.Content(node.Contents) == ident {
+						return swapNodePtr(node, name)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findClassMemberTypescript returns the definition of the method or field with the given name in the
+// given class.
+func findClassMemberTypescript(class Node, name string) *Node {
+	body := class.ChildByFieldName("body")
+	if body == nil {
+		return nil
+	}
+	for _, member := range children(body) {
+		switch member.Type() {
+		case "method_definition", "method_signature", "abstract_method_signature", "public_field_definition":
+			ident := member.ChildByFieldName("name")
+			if ident != nil && ident.Content(class.Contents) == name {
+				return swapNodePtr(class, ident)
+			}
+		}
+	}
+	return nil
+}
@@ -124,6 +124,13 @@ var langToLangSpec = map[string]LangSpec{
 (short_var_declaration left: (expression_list (identifier) @definition)) ; x, y := ...
 (range_clause          left: (expression_list (identifier) @definition)) ; for i := range ... { ... }
 (receive_statement     left: (expression_list (identifier) @definition)) ; case x := <-ch: ...
+`,
+		topLevelSymbolsQuery: `
+(source_file (function_declaration name: (identifier) @symbol))
+(source_file (method_declaration   name: (field_identifier) @symbol))
+(source_file (type_declaration     (type_spec  name: (type_identifier) @symbol)))
+(source_file (var_declaration      (var_spec   name: (identifier) @symbol)))
+(source_file (const_declaration    (const_spec name: (identifier) @symbol)))
 `,
 	},
 	"csharp": {
@@ -176,6 +183,13 @@ var langToLangSpec = map[string]LangSpec{
 (for_statement           left: (pattern_list (identifier) @definition))                    ; for x, y in ...: ...
 (for_in_clause           left: (identifier) @definition)                                   ; (... for x in xs)
 (for_in_clause           left: (pattern_list (identifier) @definition))                    ; (... for x, y in xs)
+`,
+		topLevelSymbolsQuery: `
+(module (function_definition name: (identifier) @symbol))
+(module (class_definition    name: (identifier) @symbol))
+(module (decorated_definition definition: (function_definition name: (identifier) @symbol)))
+(module (decorated_definition definition: (class_definition    name: (identifier) @symbol)))
+(module (expression_statement (assignment left: (identifier) @symbol)))
 `,
 	},
 	"javascript": {
@@ -244,6 +258,20 @@ var langToLangSpec = map[string]LangSpec{
 (arrow_function parameter: (identifier) @definition)            ; x => ...
 (for_in_statement left: (identifier) @definition)               ; for (const x of xs) ...
 (catch_clause parameter: (identifier) @definition)              ; catch (e) ...
+`,
+		topLevelSymbolsQuery: `
+(program (function_declaration   name: (identifier) @symbol))
+(program (class_declaration      name: (type_identifier) @symbol))
+(program (interface_declaration  name: (type_identifier) @symbol))
+(program (type_alias_declaration name: (type_identifier) @symbol))
+(program (enum_declaration       name: (identifier) @symbol))
+(program (lexical_declaration    (variable_declarator name: (identifier) @symbol)))
+(program (export_statement declaration: (function_declaration   name: (identifier) @symbol)))
+(program (export_statement declaration: (class_declaration      name: (type_identifier) @symbol)))
+(program (export_statement declaration: (interface_declaration  name: (type_identifier) @symbol)))
+(program (export_statement declaration: (type_alias_declaration name: (type_identifier) @symbol)))
+(program (export_statement declaration: (enum_declaration       name: (identifier) @symbol)))
+(program (export_statement declaration: (lexical_declaration    (variable_declarator name: (identifier) @symbol))))
 `,
 	},
 	"cpp": {
@@ -135,6 +135,52 @@ func (squirrel *SquirrelService) localCodeIntel(ctx context.Context, repoCommitP
 	return &types.LocalCodeIntelPayload{Symbols: symbols}, nil
 }
 
+// findLocalDef returns the definition of the identifier at the given node in the scopes that enclose
+// it according to the localsQuery, or nil if it's not defined in the file.
+func findLocalDef(node Node) (*Node, error) {
+	ident := node.Content(node.Contents)
+	root := swapNode(node, getRoot(node.Node))
+
+	// Collect scopes and the defs of the identifier
+	scopes := map[NodeId]struct{}{
+		nodeId(root.Node): {},
+	}
+	defs := []Node{}
+	err := forEachCapture(root.LangSpec.localsQuery, &root, func(captureName string, capture Node) {
+		if captureName == "scope" {
+			scopes[nodeId(capture.Node)] = struct{}{}
+			return
+		}
+		if strings.HasPrefix(captureName, "definition") && capture.Content(capture.Contents) == ident {
+			defs = append(defs, capture)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Put each def in its nearest scope, keeping the first def in each scope
+	scopeToDef := map[NodeId]Node{}
+	for _, def := range defs {
+		for cur := def.Node; cur != nil; cur = cur.Parent() {
+			if _, ok := scopes[nodeId(cur)]; ok {
+				if _, ok := scopeToDef[nodeId(cur)]; !ok {
+					scopeToDef[nodeId(cur)] = def
+				}
+				break
+			}
+		}
+	}
+
+	// Find the nearest scope that defines the identifier
+	for cur := node.Node; cur != nil; cur = cur.Parent() {
+		if def, ok := scopeToDef[nodeId(cur)]; ok {
+			return &def, nil
+		}
+	}
+	return nil, nil
+}
+
 // Pretty prints the local code intel payload for debugging.
 func prettyPrintLocalCodeIntelPayload(w io.Writer, payload types.LocalCodeIntelPayload, contents string) {
 	lines := strings.Split(contents, "\n")
@@ -154,11 +154,14 @@ func (squirrel *SquirrelService) getDef(ctx context.Context, node Node) (*Node,
 	switch node.LangSpec.name {
 	case "java":
 		return squirrel.getDefJava(ctx, node)
-	// case "go":
+	case "go":
+		return squirrel.getDefGo(ctx, node)
+	case "python":
+		return squirrel.getDefPython(ctx, node)
+	case "typescript":
+		return squirrel.getDefTypescript(ctx, node)
 	// case "csharp":
-	// case "python":
 	// case "javascript":
-	// case "typescript":
 	// case "cpp":
 	// case "ruby":
 	default:
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+
+	sh "example.com/go1/pkg/shapes" // < "sh" pkg/shapes path
+	"example.com/go1/pkg/util"      // < "example.com/go1/pkg/util" pkg/util path
+)
+
+func main() {
+	var cfg Config                     // < "Config" go1.Config ref
+	c := sh.Circle{}                   // < "Circle" go1.Circle ref
+	fmt.Println(util.Helper(), c, cfg) // < "Helper" go1.Helper ref
+	fmt.Println(util.Answer)           // < "util" pkg/util path
+	fmt.Println(otherFunc())           // < "otherFunc" go1.otherFunc ref
+}
@@ -0,0 +1,7 @@
+package main
+
+type Config struct{} // < "Config" go1.Config def
+
+func otherFunc() int { // < "otherFunc" go1.otherFunc def
+	return 0
+}
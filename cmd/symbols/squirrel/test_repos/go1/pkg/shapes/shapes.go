@@ -0,0 +1,9 @@
+package shapes
+
+type Circle struct { // < "Circle" go1.Circle def
+	R int
+}
+
+func (c Circle) Area() int {
+	return c.R * c.R * 3
+}
@@ -0,0 +1,6 @@
+package util
+
+func helper2() int { // < "helper2" go1.helper2 def
+	total := 1            // < "total" go1.total def
+	return total + Answer // < "total" go1.total ref < "Answer" go1.Answer ref
+}
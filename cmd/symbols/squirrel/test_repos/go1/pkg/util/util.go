@@ -0,0 +1,7 @@
+package util
+
+const Answer = 42 // < "Answer" go1.Answer def
+
+func Helper() int { // < "Helper" go1.Helper def
+	return helper2() // < "helper2" go1.helper2 ref
+}
@@ -0,0 +1,16 @@
+import app.models
+from app.util import helper as h  # < "helper" py1.helper ref
+from .models import Model  # < "Model" py1.Model ref
+from . import util
+
+
+def run():  # < "run" py1.run def
+    m = Model()  # < "Model" py1.Model ref
+    h()  # < "h" py1.helper ref
+    util.helper()  # < "helper" py1.helper ref
+    app.models.make_model()  # < "make_model" py1.make_model ref
+    return m.describe()
+
+
+def main():
+    run()  # < "run" py1.run ref
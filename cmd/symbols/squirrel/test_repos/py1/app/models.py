@@ -0,0 +1,17 @@
+def make_model():  # < "make_model" py1.make_model def
+    return Model()  # < "Model" py1.Model ref
+
+
+class Model:  # < "Model" py1.Model def
+    kind = "model"  # < "kind" py1.kind def
+
+    def describe(self):
+        return self.label() + self.kind  # < "label" py1.label ref < "kind" py1.kind ref
+
+    def label(self):  # < "label" py1.label def
+        return "label"
+
+
+class Special(Model):
+    def title(self):
+        return self.label()  # < "label" py1.label ref
@@ -0,0 +1,2 @@
+def helper():  # < "helper" py1.helper def
+    return 1
@@ -0,0 +1,5 @@
+class Config { // < "Config" ts1.Config def
+    size = 1;
+}
+
+export default Config;
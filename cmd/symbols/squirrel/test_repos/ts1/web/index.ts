@@ -0,0 +1,14 @@
+import { greet, Greeter as G, Name } from "./lib/greeter"; // < "Greeter" ts1.Greeter ref
+import * as math from "./lib/math";
+import Config from "./config"; // < "Config" ts1.Config ref
+
+export function main(): number { // < "main" ts1.main def
+    const who: Name = "world"; // < "Name" ts1.Name ref
+    const g = new G(); // < "G" ts1.Greeter ref
+    greet(who); // < "greet" ts1.greet ref
+    g.hello();
+    const config = new Config(); // < "Config" ts1.Config ref
+    return math.add(1, 2); // < "add" ts1.add ref
+}
+
+main(); // < "main" ts1.main ref
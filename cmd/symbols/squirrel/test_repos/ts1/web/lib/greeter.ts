@@ -0,0 +1,19 @@
+export type Name = string; // < "Name" ts1.Name def
+
+export function greet(name: Name): string { // < "greet" ts1.greet def < "Name" ts1.Name ref
+    return format(name); // < "format" ts1.format ref
+}
+
+function format(name: string): string { // < "format" ts1.format def
+    return "hello " + name;
+}
+
+export class Greeter { // < "Greeter" ts1.Greeter def
+    hello(): string {
+        return this.title(); // < "title" ts1.title ref
+    }
+
+    title(): string { // < "title" ts1.title def
+        return "greeter";
+    }
+}
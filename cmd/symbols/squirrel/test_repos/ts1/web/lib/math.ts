@@ -0,0 +1,3 @@
+export function add(a: number, b: number): number { // < "add" ts1.add def
+    return a + b;
+}
@@ -235,6 +235,21 @@ func swapNodePtr(other Node, newNode *sitter.Node) *Node {
 	return &ret
 }
 
+// dirNode returns a Node without a tree-sitter node that represents the given directory in the same
+// repository and commit as other, e.g. the definition of a Go package.
+func dirNode(other Node, dir string) *Node {
+	return &Node{
+		RepoCommitPath: types.RepoCommitPath{
+			Repo:   other.RepoCommitPath.Repo,
+			Commit: other.RepoCommitPath.Commit,
+			Path:   dir,
+		},
+		Node:     nil,
+		Contents: other.Contents,
+		LangSpec: other.LangSpec,
+	}
+}
+
 var unrecognizedFileExtensionError = errors.New("unrecognized file extension")
 var unsupportedLanguageError = errors.New("unsupported language")
 
@@ -11,6 +11,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/background/cratesyncer"
 	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/background/indexer"
 	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/background/resolver"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/background/vulnerabilities"
 	livedependencies "github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/live"
 	policies "github.com/sourcegraph/sourcegraph/internal/codeintel/policies/enterprise"
 	"github.com/sourcegraph/sourcegraph/internal/database"
@@ -33,6 +34,7 @@ func (j *dependenciesJob) Config() []env.Config {
 	return []env.Config{
 		indexer.ConfigInst,
 		resolver.ConfigInst,
+		vulnerabilities.ConfigInst,
 	}
 }
 
@@ -58,5 +60,7 @@ func (j *dependenciesJob) Routines(ctx context.Context, logger log.Logger) ([]go
 		indexer.NewIndexer(database.NewDB(logger, db), livedependencies.NewSyncer(), dbStore, policyMatcher),
 		resolver.NewResolver(database.NewDB(logger, db), livedependencies.NewSyncer()),
 		cratesyncer.NewCratesSyncer(database.NewDB(logger, db)),
+		vulnerabilities.NewImporter(database.NewDB(logger, db), livedependencies.NewSyncer()),
+		vulnerabilities.NewMatcher(database.NewDB(logger, db), livedependencies.NewSyncer()),
 	}, nil
 }
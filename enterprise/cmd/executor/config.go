@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,6 +23,8 @@ type Config struct {
 	FrontendURL                string
 	FrontendAuthorizationToken string
 	QueueName                  string
+	QueueNames                 string
+	Queues                     []apiworker.QueueOptions
 	QueuePollInterval          time.Duration
 	MaximumNumJobs             int
 	FirecrackerImage           string
@@ -42,7 +46,8 @@ type Config struct {
 func (c *Config) Load() {
 	c.FrontendURL = c.Get("EXECUTOR_FRONTEND_URL", "", "The external URL of the sourcegraph instance.")
 	c.FrontendAuthorizationToken = c.Get("EXECUTOR_FRONTEND_PASSWORD", "", "The authorization token supplied to the frontend.")
-	c.QueueName = c.Get("EXECUTOR_QUEUE_NAME", "", "The name of the queue to listen to.")
+	c.QueueName = c.GetOptional("EXECUTOR_QUEUE_NAME", "The name of the queue to listen to.")
+	c.QueueNames = c.GetOptional("EXECUTOR_QUEUE_NAMES", "A comma-separated list of queues to listen to, each of the form name[:weight[:maximumNumJobs]]. Queues with a higher weight are polled more often. Mutually exclusive with EXECUTOR_QUEUE_NAME.")
 	c.QueuePollInterval = c.GetInterval("EXECUTOR_QUEUE_POLL_INTERVAL", "1s", "Interval between dequeue requests.")
 	c.MaximumNumJobs = c.GetInt("EXECUTOR_MAXIMUM_NUM_JOBS", "1", "Number of virtual machines or containers that can be running at once.")
 	c.UseFirecracker = c.GetBool("EXECUTOR_USE_FIRECRACKER", "true", "Whether to isolate commands in virtual machines.")
@@ -70,6 +75,21 @@ func (c *Config) Validate() error {
 		c.AddError(errors.Newf("EXECUTOR_JOB_NUM_CPUS must be 1 or an even number"))
 	}
 
+	switch {
+	case c.QueueName != "" && c.QueueNames != "":
+		c.AddError(errors.New("only one of EXECUTOR_QUEUE_NAME and EXECUTOR_QUEUE_NAMES may be set"))
+	case c.QueueName != "":
+		c.Queues = []apiworker.QueueOptions{{Name: c.QueueName}}
+	case c.QueueNames != "":
+		queues, err := parseQueues(c.QueueNames)
+		if err != nil {
+			c.AddError(errors.Wrap(err, "invalid value for EXECUTOR_QUEUE_NAMES"))
+		}
+		c.Queues = queues
+	default:
+		c.AddError(errors.New("one of EXECUTOR_QUEUE_NAME and EXECUTOR_QUEUE_NAMES must be set"))
+	}
+
 	return c.BaseConfig.Validate()
 }
 
@@ -77,7 +97,7 @@ func (c *Config) APIWorkerOptions(telemetryOptions apiclient.TelemetryOptions) a
 	return apiworker.Options{
 		VMPrefix:           c.VMPrefix,
 		KeepWorkspaces:     c.KeepWorkspaces,
-		QueueName:          c.QueueName,
+		Queues:             c.Queues,
 		WorkerOptions:      c.WorkerOptions(),
 		FirecrackerOptions: c.FirecrackerOptions(),
 		ResourceOptions:    c.ResourceOptions(),
@@ -93,11 +113,11 @@ func (c *Config) APIWorkerOptions(telemetryOptions apiclient.TelemetryOptions) a
 
 func (c *Config) WorkerOptions() workerutil.WorkerOptions {
 	return workerutil.WorkerOptions{
-		Name:                 fmt.Sprintf("executor_%s_worker", c.QueueName),
+		Name:                 fmt.Sprintf("executor_%s_worker", strings.Join(c.queueNames(), "_")),
 		NumHandlers:          c.MaximumNumJobs,
 		Interval:             c.QueuePollInterval,
 		HeartbeatInterval:    5 * time.Second,
-		Metrics:              makeWorkerMetrics(c.QueueName),
+		Metrics:              makeWorkerMetrics(),
 		NumTotalJobs:         c.NumTotalJobs,
 		MaxActiveTime:        c.MaxActiveTime,
 		WorkerHostname:       c.WorkerHostname,
@@ -105,6 +125,50 @@ func (c *Config) WorkerOptions() workerutil.WorkerOptions {
 	}
 }
 
+func (c *Config) queueNames() []string {
+	names := make([]string, 0, len(c.Queues))
+	for _, queue := range c.Queues {
+		names = append(names, queue.Name)
+	}
+	return names
+}
+
+// parseQueues parses a comma-separated list of queues of the form name[:weight[:maximumNumJobs]].
+func parseQueues(value string) ([]apiworker.QueueOptions, error) {
+	var queues []apiworker.QueueOptions
+	seen := map[string]struct{}{}
+	for _, spec := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(spec), ":")
+		if parts[0] == "" || len(parts) > 3 {
+			return nil, errors.Newf("malformed queue %q", spec)
+		}
+		if _, ok := seen[parts[0]]; ok {
+			return nil, errors.Newf("duplicate queue %q", parts[0])
+		}
+		seen[parts[0]] = struct{}{}
+
+		queue := apiworker.QueueOptions{Name: parts[0], Weight: 1}
+		if len(parts) > 1 {
+			weight, err := strconv.Atoi(parts[1])
+			if err != nil || weight <= 0 {
+				return nil, errors.Newf("invalid weight %q for queue %q", parts[1], queue.Name)
+			}
+			queue.Weight = weight
+		}
+		if len(parts) > 2 {
+			maxNumJobs, err := strconv.Atoi(parts[2])
+			if err != nil || maxNumJobs <= 0 {
+				return nil, errors.Newf("invalid maximum number of jobs %q for queue %q", parts[2], queue.Name)
+			}
+			queue.MaxNumJobs = maxNumJobs
+		}
+
+		queues = append(queues, queue)
+	}
+
+	return queues, nil
+}
+
 func (c *Config) FirecrackerOptions() command.FirecrackerOptions {
 	return command.FirecrackerOptions{
 		Enabled:             c.UseFirecracker,
@@ -125,6 +189,7 @@ func (c *Config) ResourceOptions() command.ResourceOptions {
 func (c *Config) ClientOptions(telemetryOptions apiclient.TelemetryOptions) apiclient.Options {
 	return apiclient.Options{
 		ExecutorName:      c.WorkerHostname,
+		QueueNames:        c.queueNames(),
 		PathPrefix:        "/.executors/queue",
 		EndpointOptions:   c.EndpointOptions(),
 		BaseClientOptions: c.BaseClientOptions(),
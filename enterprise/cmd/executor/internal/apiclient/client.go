@@ -27,6 +27,10 @@ type Options struct {
 	// ExecutorName is a unique identifier for the requesting executor.
 	ExecutorName string
 
+	// QueueNames are the names of all queues the executor processes jobs from, which are
+	// reported in heartbeat requests.
+	QueueNames []string
+
 	// PathPrefix is the path prefix added to all requests.
 	PathPrefix string
 
@@ -205,6 +209,7 @@ func (c *Client) Heartbeat(ctx context.Context, queueName string, jobIDs []int)
 	req, err := c.makeRequest("POST", fmt.Sprintf("%s/heartbeat", queueName), executor.HeartbeatRequest{
 		ExecutorName: c.options.ExecutorName,
 		JobIDs:       jobIDs,
+		QueueNames:   c.options.QueueNames,
 
 		OS:              c.options.TelemetryOptions.OS,
 		Architecture:    c.options.TelemetryOptions.Architecture,
@@ -63,8 +63,9 @@ func (h *handler) PreDequeue(ctx context.Context, logger log.Logger) (dequeueabl
 // Handle clones the target code into a temporary directory, invokes the target indexer in a
 // fresh docker container, and uploads the results to the external frontend API.
 func (h *handler) Handle(ctx context.Context, logger log.Logger, record workerutil.Record) (err error) {
-	job := record.(executor.Job)
+	job := jobFromRecord(record)
 	logger = logger.With(
+		log.String("queue", job.Queue),
 		log.Int("jobID", job.ID),
 		log.String("repositoryName", job.RepositoryName),
 		log.String("commit", job.Commit))
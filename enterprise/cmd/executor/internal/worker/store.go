@@ -2,15 +2,29 @@ package worker
 
 import (
 	"context"
+	"sort"
+	"sync"
 
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/executor"
 	"github.com/sourcegraph/sourcegraph/internal/workerutil"
 	"github.com/sourcegraph/sourcegraph/lib/errors"
 )
 
+// storeShim converts a QueueStore into a workerutil.Store that dequeues jobs from one or more
+// queues. When there are several queues, each dequeue attempt prefers queues by a smooth
+// weighted round-robin over the queues that are below their job limit, and falls back to the
+// other queues when the preferred queue is empty.
+//
+// Job identifiers are only unique within a queue, so the record identifiers seen by the worker
+// encode the index of the queue of the job: recordID = jobID*len(queues) + queueIndex. With a
+// single queue, record identifiers are job identifiers.
 type storeShim struct {
-	queueName  string
+	queues     []QueueOptions
 	queueStore QueueStore
+
+	mu             sync.Mutex
+	currentWeights []int
+	numRunning     []int
 }
 
 type QueueStore interface {
@@ -25,40 +39,176 @@ type QueueStore interface {
 
 var _ workerutil.Store = &storeShim{}
 
+func newStoreShim(queues []QueueOptions, queueStore QueueStore) *storeShim {
+	return &storeShim{
+		queues:         queues,
+		queueStore:     queueStore,
+		currentWeights: make([]int, len(queues)),
+		numRunning:     make([]int, len(queues)),
+	}
+}
+
+// queuedJob is a job dequeued from one of several queues. Its record identifier is unique
+// across all queues polled by the worker.
+type queuedJob struct {
+	executor.Job
+	recordID int
+}
+
+func (j queuedJob) RecordID() int {
+	return j.recordID
+}
+
+// QueueName returns the name of the queue from which the given record, as processed by a worker
+// created by NewWorker, was dequeued.
+func QueueName(record workerutil.Record) string {
+	return jobFromRecord(record).Queue
+}
+
+// jobFromRecord returns the job of a record returned by storeShim.Dequeue.
+func jobFromRecord(record workerutil.Record) executor.Job {
+	if j, ok := record.(queuedJob); ok {
+		return j.Job
+	}
+	return record.(executor.Job)
+}
+
 func (s *storeShim) QueuedCount(ctx context.Context, extraArguments any) (int, error) {
 	return 0, errors.New("unimplemented")
 }
 
+// Dequeue polls the queues in the order given by dequeueOrder until one of them returns a job.
+// A queue that fails to dequeue does not stop the others from being polled: the errors are only
+// returned if no queue returned a job.
 func (s *storeShim) Dequeue(ctx context.Context, workerHostname string, extraArguments any) (workerutil.Record, bool, error) {
-	var job executor.Job
-	dequeued, err := s.queueStore.Dequeue(ctx, s.queueName, &job)
-	if err != nil {
-		return nil, false, err
+	var errs error
+	for _, queueIndex := range s.dequeueOrder() {
+		var job executor.Job
+		dequeued, err := s.queueStore.Dequeue(ctx, s.queues[queueIndex].Name, &job)
+		if err != nil {
+			errs = errors.Append(errs, errors.Wrapf(err, "dequeueing from queue %q", s.queues[queueIndex].Name))
+			continue
+		}
+		if !dequeued {
+			continue
+		}
+
+		// Older instances don't report the queue of a job.
+		job.Queue = s.queues[queueIndex].Name
+
+		s.mu.Lock()
+		s.numRunning[queueIndex]++
+		s.mu.Unlock()
+
+		if len(s.queues) == 1 {
+			return job, true, nil
+		}
+		return queuedJob{Job: job, recordID: s.recordID(queueIndex, job.ID)}, true, nil
 	}
 
-	return job, dequeued, nil
+	return nil, false, errs
 }
 
+// dequeueOrder returns the indexes of the queues that are below their job limit, in the order in
+// which they should be polled. The first queue is chosen by smooth weighted round-robin, so that
+// over time each queue is preferred in proportion to its weight. The remaining queues follow in
+// order of their current weight.
+func (s *storeShim) dequeueOrder() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var eligible []int
+	totalWeight := 0
+	for i, queue := range s.queues {
+		if queue.MaxNumJobs > 0 && s.numRunning[i] >= queue.MaxNumJobs {
+			continue
+		}
+
+		eligible = append(eligible, i)
+		s.currentWeights[i] += queue.weight()
+		totalWeight += queue.weight()
+	}
+	if len(eligible) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(eligible, func(i, j int) bool {
+		return s.currentWeights[eligible[i]] > s.currentWeights[eligible[j]]
+	})
+	s.currentWeights[eligible[0]] -= totalWeight
+
+	return eligible
+}
+
+// recordID returns the record identifier of the job with the given identifier in the given queue.
+func (s *storeShim) recordID(queueIndex, jobID int) int {
+	return jobID*len(s.queues) + queueIndex
+}
+
+// job returns the queue and the job identifier of the given record identifier.
+func (s *storeShim) job(recordID int) (queueIndex, jobID int) {
+	return recordID % len(s.queues), recordID / len(s.queues)
+}
+
+// finished releases the job limit slot held by the given record.
+func (s *storeShim) finished(recordID int) {
+	queueIndex, _ := s.job(recordID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.numRunning[queueIndex] > 0 {
+		s.numRunning[queueIndex]--
+	}
+}
+
+// Heartbeat sends a heartbeat to every queue, including the queues without running jobs so that
+// the executor is reported as active for each of them.
 func (s *storeShim) Heartbeat(ctx context.Context, ids []int) (knownIDs []int, err error) {
-	return s.queueStore.Heartbeat(ctx, s.queueName, ids)
+	jobIDsByQueue := make([][]int, len(s.queues))
+	for _, id := range ids {
+		queueIndex, jobID := s.job(id)
+		jobIDsByQueue[queueIndex] = append(jobIDsByQueue[queueIndex], jobID)
+	}
+
+	for queueIndex, queue := range s.queues {
+		knownJobIDs, heartbeatErr := s.queueStore.Heartbeat(ctx, queue.Name, jobIDsByQueue[queueIndex])
+		if heartbeatErr != nil {
+			err = errors.Append(err, heartbeatErr)
+			continue
+		}
+
+		for _, jobID := range knownJobIDs {
+			knownIDs = append(knownIDs, s.recordID(queueIndex, jobID))
+		}
+	}
+
+	return knownIDs, err
 }
 
 func (s *storeShim) AddExecutionLogEntry(ctx context.Context, id int, entry workerutil.ExecutionLogEntry) (int, error) {
-	return s.queueStore.AddExecutionLogEntry(ctx, s.queueName, id, entry)
+	queueIndex, jobID := s.job(id)
+	return s.queueStore.AddExecutionLogEntry(ctx, s.queues[queueIndex].Name, jobID, entry)
 }
 
-func (s *storeShim) UpdateExecutionLogEntry(ctx context.Context, jobID, entryID int, entry workerutil.ExecutionLogEntry) error {
-	return s.queueStore.UpdateExecutionLogEntry(ctx, s.queueName, jobID, entryID, entry)
+func (s *storeShim) UpdateExecutionLogEntry(ctx context.Context, id, entryID int, entry workerutil.ExecutionLogEntry) error {
+	queueIndex, jobID := s.job(id)
+	return s.queueStore.UpdateExecutionLogEntry(ctx, s.queues[queueIndex].Name, jobID, entryID, entry)
 }
 
 func (s *storeShim) MarkComplete(ctx context.Context, id int) (bool, error) {
-	return true, s.queueStore.MarkComplete(ctx, s.queueName, id)
+	defer s.finished(id)
+	queueIndex, jobID := s.job(id)
+	return true, s.queueStore.MarkComplete(ctx, s.queues[queueIndex].Name, jobID)
 }
 
 func (s *storeShim) MarkErrored(ctx context.Context, id int, errorMessage string) (bool, error) {
-	return true, s.queueStore.MarkErrored(ctx, s.queueName, id, errorMessage)
+	defer s.finished(id)
+	queueIndex, jobID := s.job(id)
+	return true, s.queueStore.MarkErrored(ctx, s.queues[queueIndex].Name, jobID, errorMessage)
 }
 
 func (s *storeShim) MarkFailed(ctx context.Context, id int, errorMessage string) (bool, error) {
-	return true, s.queueStore.MarkFailed(ctx, s.queueName, id, errorMessage)
+	defer s.finished(id)
+	queueIndex, jobID := s.job(id)
+	return true, s.queueStore.MarkFailed(ctx, s.queues[queueIndex].Name, jobID, errorMessage)
 }
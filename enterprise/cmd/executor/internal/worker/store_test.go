@@ -0,0 +1,228 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/executor"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// testQueueStore is a QueueStore backed by a fixed list of job identifiers per queue.
+type testQueueStore struct {
+	jobIDs      map[string][]int
+	dequeueErrs map[string]error
+	heartbeat   map[string][]int
+	completed   map[string][]int
+}
+
+func newTestQueueStore(jobIDs map[string][]int) *testQueueStore {
+	return &testQueueStore{
+		jobIDs:    jobIDs,
+		heartbeat: map[string][]int{},
+		completed: map[string][]int{},
+	}
+}
+
+func (s *testQueueStore) Dequeue(ctx context.Context, queueName string, payload *executor.Job) (bool, error) {
+	if err := s.dequeueErrs[queueName]; err != nil {
+		return false, err
+	}
+	if len(s.jobIDs[queueName]) == 0 {
+		return false, nil
+	}
+
+	*payload = executor.Job{ID: s.jobIDs[queueName][0]}
+	s.jobIDs[queueName] = s.jobIDs[queueName][1:]
+	return true, nil
+}
+
+func (s *testQueueStore) AddExecutionLogEntry(ctx context.Context, queueName string, jobID int, entry workerutil.ExecutionLogEntry) (int, error) {
+	return 0, nil
+}
+
+func (s *testQueueStore) UpdateExecutionLogEntry(ctx context.Context, queueName string, jobID, entryID int, entry workerutil.ExecutionLogEntry) error {
+	return nil
+}
+
+func (s *testQueueStore) MarkComplete(ctx context.Context, queueName string, jobID int) error {
+	s.completed[queueName] = append(s.completed[queueName], jobID)
+	return nil
+}
+
+func (s *testQueueStore) MarkErrored(ctx context.Context, queueName string, jobID int, errorMessage string) error {
+	return nil
+}
+
+func (s *testQueueStore) MarkFailed(ctx context.Context, queueName string, jobID int, errorMessage string) error {
+	return nil
+}
+
+func (s *testQueueStore) Heartbeat(ctx context.Context, queueName string, jobIDs []int) ([]int, error) {
+	s.heartbeat[queueName] = jobIDs
+	return jobIDs, nil
+}
+
+func TestStoreShimSingleQueue(t *testing.T) {
+	queueStore := newTestQueueStore(map[string][]int{"batches": {42}})
+	store := newStoreShim([]QueueOptions{{Name: "batches"}}, queueStore)
+
+	record, dequeued, err := store.Dequeue(context.Background(), "deadbeef", nil)
+	if err != nil {
+		t.Fatalf("unexpected error dequeueing job: %s", err)
+	}
+	if !dequeued {
+		t.Fatalf("expected a job to be dequeued")
+	}
+	if record.RecordID() != 42 {
+		t.Errorf("unexpected record id. want=%d have=%d", 42, record.RecordID())
+	}
+	if job := jobFromRecord(record); job.ID != 42 || QueueName(record) != "batches" {
+		t.Errorf("unexpected job. want=%d (batches) have=%d (%s)", 42, job.ID, QueueName(record))
+	}
+}
+
+func TestStoreShimWeightedQueues(t *testing.T) {
+	queueStore := newTestQueueStore(map[string][]int{
+		"batches":   {1, 2, 3, 4, 5, 6},
+		"codeintel": {1, 2, 3, 4, 5, 6},
+	})
+	store := newStoreShim([]QueueOptions{
+		{Name: "batches", Weight: 2},
+		{Name: "codeintel", Weight: 1},
+	}, queueStore)
+
+	var queues []string
+	for i := 0; i < 6; i++ {
+		record, dequeued, err := store.Dequeue(context.Background(), "deadbeef", nil)
+		if err != nil {
+			t.Fatalf("unexpected error dequeueing job: %s", err)
+		}
+		if !dequeued {
+			t.Fatalf("expected a job to be dequeued")
+		}
+		queues = append(queues, jobFromRecord(record).Queue)
+	}
+
+	expectedQueues := []string{"batches", "codeintel", "batches", "batches", "codeintel", "batches"}
+	if diff := cmp.Diff(expectedQueues, queues); diff != "" {
+		t.Errorf("unexpected queues (-want +got):\n%s", diff)
+	}
+}
+
+func TestStoreShimFallsBackToNonEmptyQueue(t *testing.T) {
+	queueStore := newTestQueueStore(map[string][]int{"codeintel": {7}})
+	store := newStoreShim([]QueueOptions{
+		{Name: "batches", Weight: 10},
+		{Name: "codeintel", Weight: 1},
+	}, queueStore)
+
+	record, dequeued, err := store.Dequeue(context.Background(), "deadbeef", nil)
+	if err != nil {
+		t.Fatalf("unexpected error dequeueing job: %s", err)
+	}
+	if !dequeued {
+		t.Fatalf("expected a job to be dequeued")
+	}
+	if job := jobFromRecord(record); job.ID != 7 || job.Queue != "codeintel" {
+		t.Errorf("unexpected job. want=%d (codeintel) have=%d (%s)", 7, job.ID, job.Queue)
+	}
+}
+
+func TestStoreShimDequeueError(t *testing.T) {
+	queueStore := newTestQueueStore(map[string][]int{"codeintel": {7}})
+	queueStore.dequeueErrs = map[string]error{"batches": errors.New("connection refused")}
+	store := newStoreShim([]QueueOptions{
+		{Name: "batches", Weight: 10},
+		{Name: "codeintel", Weight: 1},
+	}, queueStore)
+
+	// A failing queue does not prevent dequeueing from the other queues
+	record, dequeued, err := store.Dequeue(context.Background(), "deadbeef", nil)
+	if err != nil {
+		t.Fatalf("unexpected error dequeueing job: %s", err)
+	}
+	if !dequeued {
+		t.Fatalf("expected a job to be dequeued")
+	}
+	if job := jobFromRecord(record); job.ID != 7 || job.Queue != "codeintel" {
+		t.Errorf("unexpected job. want=%d (codeintel) have=%d (%s)", 7, job.ID, job.Queue)
+	}
+
+	// The error is returned once no queue has a job
+	if _, dequeued, err := store.Dequeue(context.Background(), "deadbeef", nil); err == nil || dequeued {
+		t.Fatalf("expected an error and no job. have dequeued=%v err=%v", dequeued, err)
+	}
+}
+
+func TestStoreShimMaxNumJobs(t *testing.T) {
+	queueStore := newTestQueueStore(map[string][]int{
+		"batches":   {1, 2},
+		"codeintel": {1, 2},
+	})
+	store := newStoreShim([]QueueOptions{
+		{Name: "batches", Weight: 1, MaxNumJobs: 1},
+		{Name: "codeintel", Weight: 1},
+	}, queueStore)
+
+	var records []workerutil.Record
+	for i := 0; i < 3; i++ {
+		record, dequeued, err := store.Dequeue(context.Background(), "deadbeef", nil)
+		if err != nil {
+			t.Fatalf("unexpected error dequeueing job: %s", err)
+		}
+		if !dequeued {
+			t.Fatalf("expected a job to be dequeued")
+		}
+		records = append(records, record)
+	}
+	for _, record := range records[1:] {
+		if queue := jobFromRecord(record).Queue; queue != "codeintel" {
+			t.Errorf("unexpected queue while batches is at its limit. want=%q have=%q", "codeintel", queue)
+		}
+	}
+
+	// Completing the batches job frees its slot.
+	if _, err := store.MarkComplete(context.Background(), records[0].RecordID()); err != nil {
+		t.Fatalf("unexpected error completing job: %s", err)
+	}
+	if diff := cmp.Diff(map[string][]int{"batches": {1}}, queueStore.completed); diff != "" {
+		t.Errorf("unexpected completed jobs (-want +got):\n%s", diff)
+	}
+
+	record, dequeued, err := store.Dequeue(context.Background(), "deadbeef", nil)
+	if err != nil {
+		t.Fatalf("unexpected error dequeueing job: %s", err)
+	}
+	if !dequeued {
+		t.Fatalf("expected a job to be dequeued")
+	}
+	if job := jobFromRecord(record); job.ID != 2 || job.Queue != "batches" {
+		t.Errorf("unexpected job. want=%d (batches) have=%d (%s)", 2, job.ID, job.Queue)
+	}
+}
+
+func TestStoreShimHeartbeat(t *testing.T) {
+	queueStore := newTestQueueStore(nil)
+	store := newStoreShim([]QueueOptions{{Name: "batches"}, {Name: "codeintel"}}, queueStore)
+
+	ids := []int{store.recordID(0, 10), store.recordID(1, 10), store.recordID(1, 11)}
+	knownIDs, err := store.Heartbeat(context.Background(), ids)
+	if err != nil {
+		t.Fatalf("unexpected error performing heartbeat: %s", err)
+	}
+	if diff := cmp.Diff(ids, knownIDs); diff != "" {
+		t.Errorf("unexpected known ids (-want +got):\n%s", diff)
+	}
+
+	expectedHeartbeats := map[string][]int{
+		"batches":   {10},
+		"codeintel": {10, 11},
+	}
+	if diff := cmp.Diff(expectedHeartbeats, queueStore.heartbeat); diff != "" {
+		t.Errorf("unexpected heartbeats (-want +got):\n%s", diff)
+	}
+}
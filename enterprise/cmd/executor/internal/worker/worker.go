@@ -33,10 +33,11 @@ type Options struct {
 	// be used as a debugging mechanism.
 	KeepWorkspaces bool
 
-	// QueueName is the name of the queue to process work from. Having this configurable
-	// allows us to have multiple worker pools with different resource requirements and
-	// horizontal scaling factors while still uniformly processing events.
-	QueueName string
+	// Queues are the queues to process work from. Having this configurable allows us to
+	// have multiple worker pools with different resource requirements and horizontal
+	// scaling factors while still uniformly processing events, or to have a single pool
+	// share its capacity between several queues.
+	Queues []QueueOptions
 
 	// GitServicePath is the path to the internal git service API proxy in the frontend.
 	// This path should contain the endpoints info/refs and git-upload-pack.
@@ -62,6 +63,27 @@ type Options struct {
 	ResourceOptions command.ResourceOptions
 }
 
+// QueueOptions configures a queue that the worker processes work from.
+type QueueOptions struct {
+	// Name is the name of the queue.
+	Name string
+
+	// Weight is the relative share of dequeue attempts in which this queue is polled
+	// first when the worker processes several queues. Non-positive weights count as 1.
+	Weight int
+
+	// MaxNumJobs, if positive, is the maximum number of jobs of this queue that the
+	// worker processes at once.
+	MaxNumJobs int
+}
+
+func (q QueueOptions) weight() int {
+	if q.Weight <= 0 {
+		return 1
+	}
+	return q.Weight
+}
+
 // NewWorker creates a worker that polls a remote job queue API for work. The returned
 // routine contains both a worker that periodically polls for new work to perform, as well
 // as a heartbeat routine that will periodically hit the remote API with the work that is
@@ -69,7 +91,7 @@ type Options struct {
 // it thinks may have been dropped.
 func NewWorker(nameSet *janitor.NameSet, options Options, observationContext *observation.Context) (worker goroutine.WaitableBackgroundRoutine, canceler goroutine.BackgroundRoutine) {
 	queueStore := apiclient.New(options.ClientOptions, observationContext)
-	store := &storeShim{queueName: options.QueueName, queueStore: queueStore}
+	store := newStoreShim(options.Queues, queueStore)
 
 	if !connectToFrontend(queueStore, options) {
 		os.Exit(1)
@@ -90,13 +112,15 @@ func NewWorker(nameSet *janitor.NameSet, options Options, observationContext *ob
 		ctx,
 		canceledJobsPollInterval,
 		goroutine.NewHandlerWithErrorMessage("executor.worker.pollCanceled", func(ctx context.Context) error {
-			canceled, err := queueStore.Canceled(ctx, options.QueueName)
-			if err != nil {
-				return err
-			}
-
-			for _, id := range canceled {
-				w.Cancel(id)
+			for queueIndex, queue := range options.Queues {
+				canceled, err := queueStore.Canceled(ctx, queue.Name)
+				if err != nil {
+					return err
+				}
+
+				for _, id := range canceled {
+					w.Cancel(store.recordID(queueIndex, id))
+				}
 			}
 
 			return nil
@@ -122,7 +146,7 @@ func connectToFrontend(queueStore *apiclient.Client, options Options) bool {
 	defer signal.Stop(signals)
 
 	for {
-		err := queueStore.Ping(context.Background(), options.QueueName, nil)
+		err := queueStore.Ping(context.Background(), options.Queues[0].Name, nil)
 		if err == nil {
 			log15.Info("Connected to Sourcegraph instance")
 			return true
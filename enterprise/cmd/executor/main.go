@@ -110,7 +110,7 @@ func main() {
 	goroutine.MonitorBackgroundRoutines(ctx, routines...)
 }
 
-func makeWorkerMetrics(queueName string) workerutil.WorkerMetrics {
+func makeWorkerMetrics() workerutil.WorkerMetrics {
 	observationContext := &observation.Context{
 		Logger:     log.Scoped("executor_processor", "executor worker processor"),
 		Tracer:     &trace.Tracer{Tracer: opentracing.GlobalTracer()},
@@ -121,8 +121,9 @@ func makeWorkerMetrics(queueName string) workerutil.WorkerMetrics {
 		// derived from historic data, ideally we will use spare high-res histograms once they're a reality
 		// 										 30s 1m	 2.5m 5m   7.5m 10m  15m  20m	30m	  45m	1hr
 		workerutil.WithDurationBuckets([]float64{30, 60, 150, 300, 450, 600, 900, 1200, 1800, 2700, 3600}),
-		workerutil.WithLabels(map[string]string{
-			"queue": queueName,
+		// Executors may process several queues, so each job is labeled with its own queue.
+		workerutil.WithRecordLabels([]string{"queue"}, func(record workerutil.Record) []string {
+			return []string{worker.QueueName(record)}
 		}),
 	)
 }
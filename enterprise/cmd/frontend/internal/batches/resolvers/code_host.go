@@ -30,7 +30,11 @@ func (c *batchChangesCodeHostResolver) RequiresSSH() bool {
 }
 
 func (c *batchChangesCodeHostResolver) RequiresUsername() bool {
-	return c.codeHost.ExternalServiceType == extsvc.TypeBitbucketCloud
+	switch c.codeHost.ExternalServiceType {
+	case extsvc.TypeBitbucketCloud, extsvc.TypeGerrit:
+		return true
+	}
+	return false
 }
 
 func (c *batchChangesCodeHostResolver) HasWebhooks() bool {
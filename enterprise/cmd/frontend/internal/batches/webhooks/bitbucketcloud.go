@@ -48,6 +48,17 @@ func (h *BitbucketCloudWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Pushed branches and tags trigger an immediate update of the repo, rather
+	// than waiting for it to be polled.
+	if e, ok := e.(*bitbucketcloud.RepoPushEvent); ok {
+		if err := h.enqueueRepoUpdate(ctx, externalServiceID, e.Repository.UUID); err != nil {
+			respond(w, http.StatusInternalServerError, err)
+		} else {
+			respond(w, http.StatusNoContent, nil)
+		}
+		return
+	}
+
 	prs, ev, err := h.convertEvent(r.Context(), e, externalServiceID)
 	if err != nil {
 		if !errors.Is(err, bitbucketcloud.UnknownWebhookEventKey("")) {
@@ -49,6 +49,17 @@ func (h *BitbucketServerWebhook) ServeHTTP(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Pushed branches and tags trigger an immediate update of the repo, rather
+	// than waiting for it to be polled.
+	if e, ok := e.(*bitbucketserver.RefsChangedEvent); ok {
+		if err := h.enqueueRepoUpdate(ctx, externalServiceID, strconv.Itoa(e.Repository.ID)); err != nil {
+			respond(w, http.StatusInternalServerError, err)
+		} else {
+			respond(w, http.StatusNoContent, nil)
+		}
+		return
+	}
+
 	prs, ev := h.convertEvent(e)
 
 	var m error
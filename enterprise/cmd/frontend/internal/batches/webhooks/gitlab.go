@@ -207,6 +207,14 @@ func (h *GitLabWebhook) handleEvent(ctx context.Context, extSvc *types.ExternalS
 			}
 		}
 		return nil
+
+	// Pushed branches and tags trigger an immediate update of the repo, rather
+	// than waiting for it to be polled.
+	case *webhooks.PushEvent:
+		return h.handlePushEvent(ctx, esID, &e.EventCommon)
+
+	case *webhooks.TagPushEvent:
+		return h.handlePushEvent(ctx, esID, &e.EventCommon)
 	}
 
 	// We don't want to return a non-2XX status code and have GitLab retry the
@@ -256,6 +264,16 @@ func (h *GitLabWebhook) handlePipelineEvent(ctx context.Context, esID string, ev
 	return nil
 }
 
+func (h *GitLabWebhook) handlePushEvent(ctx context.Context, esID string, event *webhooks.EventCommon) *httpError {
+	if err := h.enqueueRepoUpdate(ctx, esID, strconv.Itoa(event.Project.ID)); err != nil {
+		return &httpError{
+			code: http.StatusInternalServerError,
+			err:  err,
+		}
+	}
+	return nil
+}
+
 func (h *GitLabWebhook) getChangesetForPR(ctx context.Context, tx *store.Store, pr *PR, repo *types.Repo) (*btypes.Changeset, error) {
 	return tx.GetChangeset(ctx, store.GetChangesetOpts{
 		RepoID:              repo.ID,
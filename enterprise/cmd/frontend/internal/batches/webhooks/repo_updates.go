@@ -0,0 +1,41 @@
+package webhooks
+
+import (
+	"context"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/repoupdater"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// enqueueRepoUpdate asks repo-updater to update the repo with the given
+// external ID right away, since its code host sent a push webhook for it. This
+// also backs off the polling of the repo. Repos that have not been synced from
+// the code host are ignored.
+func (h Webhook) enqueueRepoUpdate(ctx context.Context, externalServiceID, repoExternalID string) error {
+	rs, err := h.Store.Repos().List(ctx, database.ReposListOptions{
+		ExternalRepos: []api.ExternalRepoSpec{
+			{
+				ID:          repoExternalID,
+				ServiceType: h.ServiceType,
+				ServiceID:   externalServiceID,
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to load repository")
+	}
+
+	if len(rs) == 0 {
+		log15.Debug("Push webhook event could not be matched to repo", "serviceType", h.ServiceType, "externalID", repoExternalID)
+		return nil
+	}
+
+	if _, err := repoupdater.DefaultClient.EnqueueWebhookRepoUpdate(ctx, rs[0].Name); err != nil {
+		return errors.Wrap(err, "enqueuing repo update")
+	}
+	return nil
+}
@@ -68,6 +68,10 @@ var errUnprocessableRequest = errors.New("unprocessable request: missing expecte
 //   - POST `/upload?uploadId={id},index={i}`
 //   - POST `/upload?uploadId={id},done=true`
 //
+// The payload may be either a gzipped LSIF (newline-delimited JSON) or a gzipped SCIP (protobuf)
+// index. The format is not declared by the client; it is detected by the worker that processes the
+// upload.
+//
 // See the functions the following functions for details on how each request is handled:
 //
 //   - handleEnqueueSinglePayload
@@ -2,7 +2,9 @@ package resolvers
 
 import (
 	"context"
+	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/graph-gophers/graphql-go"
@@ -274,7 +276,11 @@ func (r *Resolver) createActions(ctx context.Context, monitorID int64, args []*g
 				return err
 			}
 		case a.Webhook != nil:
-			_, err := r.db.CodeMonitors().CreateWebhookAction(ctx, monitorID, a.Webhook.Enabled, a.Webhook.IncludeResults, a.Webhook.URL)
+			tmpl, err := webhookTemplate(a.Webhook.Template)
+			if err != nil {
+				return err
+			}
+			_, err = r.db.CodeMonitors().CreateWebhookAction(ctx, monitorID, a.Webhook.Enabled, a.Webhook.IncludeResults, a.Webhook.URL, tmpl)
 			if err != nil {
 				return err
 			}
@@ -386,7 +392,12 @@ func (r *Resolver) TriggerTestWebhookAction(ctx context.Context, args *graphqlba
 		return nil, err
 	}
 
-	if err := background.SendTestWebhook(ctx, httpcli.ExternalDoer, args.Description, args.Webhook.URL); err != nil {
+	tmpl, err := webhookTemplate(args.Webhook.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := background.SendTestWebhook(ctx, httpcli.ExternalDoer, args.Description, args.Webhook.URL, tmpl); err != nil {
 		return nil, err
 	}
 
@@ -617,10 +628,41 @@ func (r *Resolver) updateWebhookAction(ctx context.Context, args graphqlbackend.
 		return err
 	}
 
-	_, err = r.db.CodeMonitors().UpdateWebhookAction(ctx, id, args.Update.Enabled, args.Update.IncludeResults, args.Update.URL)
+	tmpl, err := webhookTemplate(args.Update.Template)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.CodeMonitors().UpdateWebhookAction(ctx, id, args.Update.Enabled, args.Update.IncludeResults, args.Update.URL, tmpl)
 	return err
 }
 
+// webhookTemplate converts and validates the template of a webhook action
+// input.
+func webhookTemplate(args *graphqlbackend.MonitorWebhookTemplateArgs) (*edb.WebhookTemplate, error) {
+	if args == nil {
+		return nil, nil
+	}
+
+	tmpl := &edb.WebhookTemplate{Body: args.Body}
+	if args.Method != nil {
+		tmpl.Method = strings.ToUpper(*args.Method)
+	}
+	if args.Headers != nil {
+		for _, h := range *args.Headers {
+			tmpl.Headers = append(tmpl.Headers, edb.WebhookHeader{Name: h.Name, Value: h.Value})
+		}
+	}
+	if args.Secret != nil {
+		tmpl.Secret = *args.Secret
+	}
+
+	if err := background.ValidateWebhookTemplate(tmpl); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
 func (r *Resolver) updateSlackWebhookAction(ctx context.Context, args graphqlbackend.EditActionSlackWebhookArgs) error {
 	var id int64
 	err := relay.UnmarshalSpec(*args.Id, &id)
@@ -1188,6 +1230,52 @@ func (m *monitorWebhook) URL() string {
 	return m.WebhookAction.URL
 }
 
+func (m *monitorWebhook) Template() graphqlbackend.MonitorWebhookTemplateResolver {
+	if m.WebhookAction.Template == nil {
+		return nil
+	}
+	return &monitorWebhookTemplate{m.WebhookAction.Template}
+}
+
+type monitorWebhookTemplate struct {
+	*edb.WebhookTemplate
+}
+
+func (t *monitorWebhookTemplate) Method() string {
+	if t.WebhookTemplate.Method == "" {
+		return http.MethodPost
+	}
+	return t.WebhookTemplate.Method
+}
+
+func (t *monitorWebhookTemplate) Headers() []graphqlbackend.MonitorWebhookHeaderResolver {
+	headers := make([]graphqlbackend.MonitorWebhookHeaderResolver, 0, len(t.WebhookTemplate.Headers))
+	for _, h := range t.WebhookTemplate.Headers {
+		headers = append(headers, &monitorWebhookHeader{h})
+	}
+	return headers
+}
+
+func (t *monitorWebhookTemplate) Body() string {
+	return t.WebhookTemplate.Body
+}
+
+func (t *monitorWebhookTemplate) HasSecret() bool {
+	return t.WebhookTemplate.Secret != ""
+}
+
+type monitorWebhookHeader struct {
+	edb.WebhookHeader
+}
+
+func (h *monitorWebhookHeader) Name() string {
+	return h.WebhookHeader.Name
+}
+
+func (h *monitorWebhookHeader) Value() string {
+	return h.WebhookHeader.Value
+}
+
 func (m *monitorWebhook) Events(ctx context.Context, args *graphqlbackend.ListEventsArgs) (graphqlbackend.MonitorActionEventConnectionResolver, error) {
 	after, err := unmarshalAfter(args.After)
 	if err != nil {
@@ -100,6 +100,45 @@ func toLspRange(r compute.Range) lsp.Range {
 	}
 }
 
+type computePatchResolver struct {
+	repository *gql.RepositoryResolver
+	commit     string
+	path       string
+	p          *compute.Patch
+}
+
+func (c *computePatchResolver) Repository() *gql.RepositoryResolver { return c.repository }
+func (c *computePatchResolver) Commit() string                      { return c.commit }
+func (c *computePatchResolver) Path() string                        { return c.path }
+func (c *computePatchResolver) Kind() string                        { return c.p.Kind }
+func (c *computePatchResolver) Diff() string                        { return c.p.Value }
+
+func (c *computePatchResolver) Edits() []gql.ComputeEditResolver {
+	edits := make([]gql.ComputeEditResolver, 0, len(c.p.Edits))
+	for _, e := range c.p.Edits {
+		edits = append(edits, &computeEditResolver{e: e})
+	}
+	return edits
+}
+
+func (c *computePatchResolver) Definitions() []gql.RangeResolver {
+	definitions := make([]gql.RangeResolver, 0, len(c.p.Definitions))
+	for _, r := range c.p.Definitions {
+		definitions = append(definitions, gql.NewRangeResolver(toLspRange(r)))
+	}
+	return definitions
+}
+
+type computeEditResolver struct {
+	e compute.Edit
+}
+
+func (r *computeEditResolver) Range() gql.RangeResolver {
+	return gql.NewRangeResolver(toLspRange(r.e.Range))
+}
+
+func (r *computeEditResolver) Value() string { return r.e.Value }
+
 func (c *computeTextResolver) Repository() *gql.RepositoryResolver { return c.repository }
 
 func (c *computeTextResolver) Commit() *string {
@@ -118,7 +157,7 @@ func (c *computeTextResolver) Value() string { return c.t.Value }
 // A dummy type to express the union of compute results. This how its done by the GQL library we use.
 // https://github.com/graph-gophers/graphql-go/blob/af5bb93e114f0cd4cc095dd8eae0b67070ae8f20/example/starwars/starwars.go#L485-L487
 //
-// union ComputeResult = ComputeMatchContext | ComputeText
+// union ComputeResult = ComputeMatchContext | ComputeText | ComputePatch
 
 type computeResultResolver struct {
 	result any
@@ -134,6 +173,11 @@ func (r *computeResultResolver) ToComputeText() (gql.ComputeTextResolver, bool)
 	return res, ok
 }
 
+func (r *computeResultResolver) ToComputePatch() (gql.ComputePatchResolver, bool) {
+	res, ok := r.result.(*computePatchResolver)
+	return res, ok
+}
+
 func toComputeMatchContextResolver(mc *compute.MatchContext, repository *gql.RepositoryResolver, path, commit string) *computeMatchContextResolver {
 	computeMatches := make([]gql.ComputeMatchResolver, 0, len(mc.Matches))
 	for _, m := range mc.Matches {
@@ -157,12 +201,23 @@ func toComputeTextResolver(result *compute.Text, repository *gql.RepositoryResol
 	}
 }
 
+func toComputePatchResolver(result *compute.Patch, repository *gql.RepositoryResolver, path, commit string) *computePatchResolver {
+	return &computePatchResolver{
+		repository: repository,
+		commit:     commit,
+		path:       path,
+		p:          result,
+	}
+}
+
 func toComputeResultResolver(result compute.Result, repoResolver *gql.RepositoryResolver, path, commit string) gql.ComputeResultResolver {
 	switch r := result.(type) {
 	case *compute.MatchContext:
 		return &computeResultResolver{result: toComputeMatchContextResolver(r, repoResolver, path, commit)}
 	case *compute.Text:
 		return &computeResultResolver{result: toComputeTextResolver(r, repoResolver, path, commit)}
+	case *compute.Patch:
+		return &computeResultResolver{result: toComputePatchResolver(r, repoResolver, path, commit)}
 	default:
 		panic(fmt.Sprintf("unsupported compute result %T", r))
 	}
@@ -70,6 +70,9 @@ func (h *handler) dequeue(ctx context.Context, executorName string) (_ apiclient
 		return apiclient.Job{}, false, errors.Wrap(err, "RecordTransformer")
 	}
 
+	// Executors may process several queues, and job identifiers are only unique within a queue.
+	job.Queue = h.Name
+
 	return job, true, nil
 }
 
@@ -69,6 +69,27 @@ func TestDequeueNoRecord(t *testing.T) {
 	}
 }
 
+func TestDequeueSetsQueue(t *testing.T) {
+	store := workerstoremocks.NewMockStore()
+	store.DequeueFunc.SetDefaultReturn(testRecord{ID: 42}, true, nil)
+	recordTransformer := func(ctx context.Context, record workerutil.Record) (apiclient.Job, error) {
+		return apiclient.Job{ID: 42}, nil
+	}
+
+	handler := newHandler(NewMockStore(), QueueOptions{Name: "batches", Store: store, RecordTransformer: recordTransformer})
+
+	job, dequeued, err := handler.dequeue(context.Background(), "deadbeef")
+	if err != nil {
+		t.Fatalf("unexpected error dequeueing job: %s", err)
+	}
+	if !dequeued {
+		t.Fatalf("expected job to be dequeued")
+	}
+	if job.Queue != "batches" {
+		t.Errorf("unexpected queue. want=%q have=%q", "batches", job.Queue)
+	}
+}
+
 func TestAddExecutionLogEntry(t *testing.T) {
 	store := workerstoremocks.NewMockStore()
 	store.DequeueFunc.SetDefaultReturn(testRecord{ID: 42}, true, nil)
@@ -395,8 +416,8 @@ func TestHeartbeat(t *testing.T) {
 
 	if callCount := len(executorStore.UpsertHeartbeatFunc.History()); callCount != 1 {
 		t.Errorf("unexpected heartbeat upsert count. want=%d have=%d", 1, callCount)
-	} else if name := executorStore.UpsertHeartbeatFunc.History()[0].Arg1; name != executor {
-		t.Errorf("unexpected heartbeat name. want=%q have=%q", "deadbeef", name)
+	} else if diff := cmp.Diff(executor, executorStore.UpsertHeartbeatFunc.History()[0].Arg1); diff != "" {
+		t.Errorf("unexpected heartbeat executor (-want +got):\n%s", diff)
 	}
 }
 
@@ -123,6 +123,7 @@ func (h *handler) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
 		executor := types.Executor{
 			Hostname:        payload.ExecutorName,
 			QueueName:       h.QueueOptions.Name,
+			QueueNames:      payload.QueueNames,
 			OS:              payload.OS,
 			Architecture:    payload.Architecture,
 			DockerVersion:   payload.DockerVersion,
@@ -131,6 +132,11 @@ func (h *handler) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
 			IgniteVersion:   payload.IgniteVersion,
 			SrcCliVersion:   payload.SrcCliVersion,
 		}
+		if len(payload.QueueNames) > 1 {
+			// An executor that processes several queues sends a heartbeat to each of
+			// them, so the queue of this request doesn't describe the executor.
+			executor.QueueName = ""
+		}
 
 		unknownIDs, err := h.heartbeat(r.Context(), executor, payload.JobIDs)
 		return http.StatusOK, unknownIDs, err
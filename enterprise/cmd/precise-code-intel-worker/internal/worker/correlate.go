@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"unicode"
+
+	"github.com/sourcegraph/scip/bindings/go/scip"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/conversion"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/pathexistence"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// correlate reads the raw (decompressed) upload payload and returns the grouped bundle data
+// that is written to the codeintel database. Both LSIF (newline-delimited JSON) and SCIP
+// (protobuf) payloads are accepted; the format is detected from the content of the payload.
+func correlate(ctx context.Context, r io.Reader, root string, getChildren pathexistence.GetChildrenFunc) (*precise.GroupedBundleDataChans, error) {
+	br := bufio.NewReaderSize(r, payloadPeekSize)
+
+	isLSIF, err := isLSIFPayload(br)
+	if err != nil {
+		return nil, err
+	}
+
+	if isLSIF {
+		groupedBundleData, err := conversion.Correlate(ctx, br, root, getChildren)
+		if err != nil {
+			return nil, errors.Wrap(err, "conversion.Correlate")
+		}
+
+		return groupedBundleData, nil
+	}
+
+	return correlateSCIP(ctx, br, root, getChildren)
+}
+
+// correlateSCIP reads the given SCIP index and converts it into the same grouped bundle data
+// shape produced for LSIF uploads, so that the rest of the processing pipeline (and the query
+// path) does not need to distinguish between the two formats.
+func correlateSCIP(ctx context.Context, r io.Reader, root string, getChildren pathexistence.GetChildrenFunc) (*precise.GroupedBundleDataChans, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "io.ReadAll")
+	}
+
+	var index scip.Index
+	if err := proto.Unmarshal(content, &index); err != nil {
+		return nil, errors.Wrap(err, "proto.Unmarshal")
+	}
+
+	elements, err := scip.ConvertSCIPToLSIF(&index)
+	if err != nil {
+		return nil, errors.Wrap(err, "scip.ConvertSCIPToLSIF")
+	}
+
+	groupedBundleData, err := conversion.CorrelateElements(ctx, elements, root, getChildren)
+	if err != nil {
+		return nil, errors.Wrap(err, "conversion.CorrelateElements")
+	}
+
+	return groupedBundleData, nil
+}
+
+// payloadPeekSize is the maximum number of bytes inspected at the head of an upload payload
+// when determining its format.
+const payloadPeekSize = 64 * 1024
+
+// isLSIFPayload determines if the given reader contains an LSIF (JSON) payload by inspecting the
+// first line of the stream without consuming it. Every LSIF element is a JSON object on its own
+// line, so the first line of a valid LSIF index (the metadata vertex) is a JSON object. Protobuf
+// encoded SCIP indexes may contain arbitrary bytes (including whitespace and braces) at the head
+// of the stream, but will not form a valid JSON object.
+func isLSIFPayload(r *bufio.Reader) (bool, error) {
+	head, err := r.Peek(payloadPeekSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return false, errors.Wrap(err, "bufio.Peek")
+	}
+
+	head = bytes.TrimLeftFunc(head, unicode.IsSpace)
+	if len(head) == 0 {
+		// Treat an empty payload as LSIF so that the existing error reporting for malformed
+		// LSIF uploads (missing metadata) is triggered.
+		return true, nil
+	}
+	if head[0] != '{' {
+		return false, nil
+	}
+
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		return json.Valid(head[:i]), nil
+	}
+	if len(head) < payloadPeekSize {
+		return json.Valid(head), nil
+	}
+
+	// The first line does not fit in the peek window; this cannot be verified cheaply, so
+	// we fall back to trusting the leading brace.
+	return true, nil
+}
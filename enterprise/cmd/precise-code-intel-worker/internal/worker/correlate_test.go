@@ -0,0 +1,175 @@
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/scip/bindings/go/scip"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+func TestIsLSIFPayload(t *testing.T) {
+	index := &scip.Index{
+		Metadata: &scip.Metadata{
+			ProjectRoot:          "file:///{root}",
+			ToolInfo:             &scip.ToolInfo{Name: "scip-test"},
+			TextDocumentEncoding: scip.TextEncoding_UTF8,
+		},
+	}
+	scipPayload, err := proto.Marshal(index)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling index: %s", err)
+	}
+
+	testCases := []struct {
+		name     string
+		payload  []byte
+		expected bool
+	}{
+		{name: "lsif", payload: []byte(`{"id": "01", "type": "vertex", "label": "metaData"}` + "\n"), expected: true},
+		{name: "lsif with leading whitespace", payload: []byte("\n\t" + `{"id": 1, "type": "vertex"}`), expected: true},
+		{name: "empty", payload: nil, expected: true},
+		{name: "scip", payload: scipPayload, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			isLSIF, err := isLSIFPayload(bufio.NewReader(bytes.NewReader(testCase.payload)))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if isLSIF != testCase.expected {
+				t.Errorf("unexpected format. want=%v have=%v", testCase.expected, isLSIF)
+			}
+		})
+	}
+}
+
+func TestCorrelateSCIP(t *testing.T) {
+	const (
+		localSymbol    = "scip-go gomod github.com/test/repo v1.0.0 pkg/Foo#"
+		externalSymbol = "scip-go gomod github.com/test/dep v2.0.0 pkg/Bar#"
+	)
+
+	index := &scip.Index{
+		Metadata: &scip.Metadata{
+			ProjectRoot:          "file:///src/root",
+			ToolInfo:             &scip.ToolInfo{Name: "scip-go"},
+			TextDocumentEncoding: scip.TextEncoding_UTF8,
+		},
+		Documents: []*scip.Document{
+			{
+				RelativePath: "foo.go",
+				Occurrences: []*scip.Occurrence{
+					{Range: []int32{1, 2, 5}, Symbol: localSymbol, SymbolRoles: int32(scip.SymbolRole_Definition)},
+					{Range: []int32{3, 4, 7}, Symbol: localSymbol},
+					{Range: []int32{5, 6, 9}, Symbol: externalSymbol},
+				},
+				Symbols: []*scip.SymbolInformation{
+					{Symbol: localSymbol, Documentation: []string{"Foo docs"}},
+				},
+			},
+		},
+	}
+	payload, err := proto.Marshal(index)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling index: %s", err)
+	}
+
+	groupedBundleData, err := correlate(context.Background(), bytes.NewReader(payload), "root/", nil)
+	if err != nil {
+		t.Fatalf("unexpected error correlating index: %s", err)
+	}
+
+	var paths []string
+	var hovers []string
+	for document := range groupedBundleData.Documents {
+		paths = append(paths, document.Path)
+
+		for _, hover := range document.Document.HoverResults {
+			if hover != "" {
+				hovers = append(hovers, hover)
+			}
+		}
+	}
+	for range groupedBundleData.ResultChunks {
+		// drain channel
+	}
+	for range groupedBundleData.Implementations {
+		// drain channel
+	}
+
+	var definitions []precise.MonikerLocations
+	for definition := range groupedBundleData.Definitions {
+		definitions = append(definitions, definition)
+	}
+	var references []precise.MonikerLocations
+	for reference := range groupedBundleData.References {
+		references = append(references, reference)
+	}
+	sort.Slice(references, func(i, j int) bool { return references[i].Identifier < references[j].Identifier })
+
+	if diff := cmp.Diff([]string{"foo.go"}, paths); diff != "" {
+		t.Errorf("unexpected paths (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"Foo docs"}, hovers); diff != "" {
+		t.Errorf("unexpected hovers (-want +got):\n%s", diff)
+	}
+
+	expectedDefinitions := []precise.MonikerLocations{
+		{
+			Kind:       "export",
+			Scheme:     "scip-go",
+			Identifier: localSymbol,
+			Locations: []precise.LocationData{
+				{URI: "foo.go", StartLine: 1, StartCharacter: 2, EndLine: 1, EndCharacter: 5},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedDefinitions, definitions); diff != "" {
+		t.Errorf("unexpected definitions (-want +got):\n%s", diff)
+	}
+
+	expectedReferences := []precise.MonikerLocations{
+		{
+			Kind:       "import",
+			Scheme:     "scip-go",
+			Identifier: externalSymbol,
+			Locations: []precise.LocationData{
+				{URI: "foo.go", StartLine: 5, StartCharacter: 6, EndLine: 5, EndCharacter: 9},
+			},
+		},
+		{
+			Kind:       "export",
+			Scheme:     "scip-go",
+			Identifier: localSymbol,
+			Locations: []precise.LocationData{
+				{URI: "foo.go", StartLine: 1, StartCharacter: 2, EndLine: 1, EndCharacter: 5},
+				{URI: "foo.go", StartLine: 3, StartCharacter: 4, EndLine: 3, EndCharacter: 7},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedReferences, references); diff != "" {
+		t.Errorf("unexpected references (-want +got):\n%s", diff)
+	}
+
+	expectedPackages := []precise.Package{
+		{Scheme: "scip-go", Name: "github.com/test/repo", Version: "v1.0.0"},
+	}
+	if diff := cmp.Diff(expectedPackages, groupedBundleData.Packages); diff != "" {
+		t.Errorf("unexpected packages (-want +got):\n%s", diff)
+	}
+
+	expectedPackageReferences := []precise.PackageReference{
+		{Package: precise.Package{Scheme: "scip-go", Name: "github.com/test/dep", Version: "v2.0.0"}},
+	}
+	if diff := cmp.Diff(expectedPackageReferences, groupedBundleData.PackageReferences); diff != "" {
+		t.Errorf("unexpected package references (-want +got):\n%s", diff)
+	}
+}
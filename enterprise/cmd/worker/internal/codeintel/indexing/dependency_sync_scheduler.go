@@ -26,6 +26,7 @@ var schemeToExternalService = map[string]string{
 	dependencies.JVMPackagesScheme:  extsvc.KindJVMPackages,
 	dependencies.NpmPackagesScheme:  extsvc.KindNpmPackages,
 	dependencies.RustPackagesScheme: extsvc.KindRustPackages,
+	dependencies.RubyPackagesScheme: extsvc.KindRubyPackages,
 }
 
 // NewDependencySyncScheduler returns a new worker instance that processes
@@ -3535,6 +3535,9 @@ type MockWorkerStore struct {
 	// QueuedCountFunc is an instance of a mock function object controlling
 	// the behavior of the method QueuedCount.
 	QueuedCountFunc *WorkerStoreQueuedCountFunc
+	// QueuedCountByKeyFunc is an instance of a mock function object
+	// controlling the behavior of the method QueuedCountByKey.
+	QueuedCountByKeyFunc *WorkerStoreQueuedCountByKeyFunc
 	// RequeueFunc is an instance of a mock function object controlling the
 	// behavior of the method Requeue.
 	RequeueFunc *WorkerStoreRequeueFunc
@@ -3598,6 +3601,11 @@ func NewMockWorkerStore() *MockWorkerStore {
 				return
 			},
 		},
+		QueuedCountByKeyFunc: &WorkerStoreQueuedCountByKeyFunc{
+			defaultHook: func(context.Context, int) (r0 map[string]int, r1 error) {
+				return
+			},
+		},
 		RequeueFunc: &WorkerStoreRequeueFunc{
 			defaultHook: func(context.Context, int, time.Time) (r0 error) {
 				return
@@ -3670,6 +3678,11 @@ func NewStrictMockWorkerStore() *MockWorkerStore {
 				panic("unexpected invocation of MockWorkerStore.QueuedCount")
 			},
 		},
+		QueuedCountByKeyFunc: &WorkerStoreQueuedCountByKeyFunc{
+			defaultHook: func(context.Context, int) (map[string]int, error) {
+				panic("unexpected invocation of MockWorkerStore.QueuedCountByKey")
+			},
+		},
 		RequeueFunc: &WorkerStoreRequeueFunc{
 			defaultHook: func(context.Context, int, time.Time) error {
 				panic("unexpected invocation of MockWorkerStore.Requeue")
@@ -3725,6 +3738,9 @@ func NewMockWorkerStoreFrom(i store.Store) *MockWorkerStore {
 		QueuedCountFunc: &WorkerStoreQueuedCountFunc{
 			defaultHook: i.QueuedCount,
 		},
+		QueuedCountByKeyFunc: &WorkerStoreQueuedCountByKeyFunc{
+			defaultHook: i.QueuedCountByKey,
+		},
 		RequeueFunc: &WorkerStoreRequeueFunc{
 			defaultHook: i.Requeue,
 		},
@@ -4737,6 +4753,115 @@ func (c WorkerStoreQueuedCountFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// WorkerStoreQueuedCountByKeyFunc describes the behavior when the
+// QueuedCountByKey method of the parent MockWorkerStore instance is
+// invoked.
+type WorkerStoreQueuedCountByKeyFunc struct {
+	defaultHook func(context.Context, int) (map[string]int, error)
+	hooks       []func(context.Context, int) (map[string]int, error)
+	history     []WorkerStoreQueuedCountByKeyFuncCall
+	mutex       sync.Mutex
+}
+
+// QueuedCountByKey delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockWorkerStore) QueuedCountByKey(v0 context.Context, v1 int) (map[string]int, error) {
+	r0, r1 := m.QueuedCountByKeyFunc.nextHook()(v0, v1)
+	m.QueuedCountByKeyFunc.appendCall(WorkerStoreQueuedCountByKeyFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the QueuedCountByKey
+// method of the parent MockWorkerStore instance is invoked and the hook
+// queue is empty.
+func (f *WorkerStoreQueuedCountByKeyFunc) SetDefaultHook(hook func(context.Context, int) (map[string]int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// QueuedCountByKey method of the parent MockWorkerStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *WorkerStoreQueuedCountByKeyFunc) PushHook(hook func(context.Context, int) (map[string]int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *WorkerStoreQueuedCountByKeyFunc) SetDefaultReturn(r0 map[string]int, r1 error) {
+	f.SetDefaultHook(func(context.Context, int) (map[string]int, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *WorkerStoreQueuedCountByKeyFunc) PushReturn(r0 map[string]int, r1 error) {
+	f.PushHook(func(context.Context, int) (map[string]int, error) {
+		return r0, r1
+	})
+}
+
+func (f *WorkerStoreQueuedCountByKeyFunc) nextHook() func(context.Context, int) (map[string]int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *WorkerStoreQueuedCountByKeyFunc) appendCall(r0 WorkerStoreQueuedCountByKeyFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of WorkerStoreQueuedCountByKeyFuncCall objects
+// describing the invocations of this function.
+func (f *WorkerStoreQueuedCountByKeyFunc) History() []WorkerStoreQueuedCountByKeyFuncCall {
+	f.mutex.Lock()
+	history := make([]WorkerStoreQueuedCountByKeyFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// WorkerStoreQueuedCountByKeyFuncCall is an object that describes an
+// invocation of method QueuedCountByKey on an instance of MockWorkerStore.
+type WorkerStoreQueuedCountByKeyFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 map[string]int
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c WorkerStoreQueuedCountByKeyFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c WorkerStoreQueuedCountByKeyFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // WorkerStoreRequeueFunc describes the behavior when the Requeue method of
 // the parent MockWorkerStore instance is invoked.
 type WorkerStoreRequeueFunc struct {
@@ -487,7 +487,7 @@ func TestAuthzProvidersFromConfig(t *testing.T) {
 								Config: mustMarshalJSONString(bbs),
 							})
 						}
-					case extsvc.KindGitHub, extsvc.KindPerforce:
+					case extsvc.KindGitHub, extsvc.KindBitbucketCloud, extsvc.KindPerforce:
 					default:
 						return nil, errors.Errorf("unexpected kind: %s", kind)
 					}
@@ -0,0 +1,260 @@
+// Package bitbucketcloud contains an authorization provider for Bitbucket Cloud.
+package bitbucketcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	otlog "github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// Provider is an implementation of AuthzProvider that provides repository permissions as
+// determined from the Bitbucket Cloud API.
+//
+// Permissions are looked up in the workspaces the connection mirrors repositories from,
+// which requires the configured user to be an administrator of those workspaces.
+type Provider struct {
+	urn        string
+	client     bitbucketcloud.Client
+	codeHost   *extsvc.CodeHost
+	workspaces []string
+}
+
+var _ authz.Provider = (*Provider)(nil)
+
+// NewProvider returns a new Bitbucket Cloud authorization provider that uses the given
+// bitbucketcloud.Client to look up permissions in the given workspaces. It assumes
+// usernames of Sourcegraph accounts match 1-1 with nicknames of Bitbucket Cloud accounts.
+func NewProvider(cli bitbucketcloud.Client, urn string, baseURL *url.URL, workspaces []string) *Provider {
+	return &Provider{
+		urn:        urn,
+		client:     cli,
+		codeHost:   extsvc.NewCodeHost(baseURL, extsvc.TypeBitbucketCloud),
+		workspaces: workspaces,
+	}
+}
+
+// ValidateConnection validates that the Provider has access to the permissions of the
+// configured workspaces with the credentials it was configured with.
+func (p *Provider) ValidateConnection(ctx context.Context) (warnings []string) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if len(p.workspaces) == 0 {
+		return []string{"no workspaces to fetch permissions from: set the \"teams\" field of the connection"}
+	}
+
+	for _, ws := range p.workspaces {
+		rs, err := p.client.WorkspaceRepoPermissions(ws, "")
+		if err == nil {
+			_, err = rs.WithPageLength(1).Next(ctx)
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Unable to list repository permissions of workspace %q (the configured user must be a workspace administrator): %v", ws, err))
+		}
+	}
+
+	return warnings
+}
+
+func (p *Provider) URN() string {
+	return p.urn
+}
+
+// ServiceID returns the absolute URL that identifies the Bitbucket Cloud instance
+// this provider is configured with.
+func (p *Provider) ServiceID() string { return p.codeHost.ServiceID }
+
+// ServiceType returns the type of this Provider, namely, "bitbucketCloud".
+func (p *Provider) ServiceType() string { return p.codeHost.ServiceType }
+
+// FetchAccount satisfies the authz.Provider interface. It looks for a member of the
+// configured workspaces whose nickname is the username of the given user.
+func (p *Provider) FetchAccount(ctx context.Context, user *types.User, _ []*extsvc.Account, _ []string) (acct *extsvc.Account, err error) {
+	if user == nil {
+		return nil, nil
+	}
+
+	tr, ctx := trace.New(ctx, "bitbucketcloud.authz.provider.FetchAccount", "")
+	defer func() {
+		tr.LogFields(
+			otlog.String("user.name", user.Username),
+			otlog.Int32("user.id", user.ID),
+		)
+
+		if err != nil {
+			tr.SetError(err)
+		}
+
+		tr.Finish()
+	}()
+
+	bitbucketUser, err := p.member(ctx, user.Username)
+	if err != nil || bitbucketUser == nil {
+		return nil, err
+	}
+
+	accountData, err := json.Marshal(bitbucketUser)
+	if err != nil {
+		return nil, err
+	}
+
+	return &extsvc.Account{
+		UserID: user.ID,
+		AccountSpec: extsvc.AccountSpec{
+			ServiceType: p.codeHost.ServiceType,
+			ServiceID:   p.codeHost.ServiceID,
+			AccountID:   bitbucketUser.UUID,
+		},
+		AccountData: extsvc.AccountData{
+			Data: (*json.RawMessage)(&accountData),
+		},
+	}, nil
+}
+
+// FetchUserPerms returns a list of repository IDs (on code host) that the given account
+// has read access on the code host. The repository ID has the same value as it would be
+// used as api.ExternalRepoSpec.ID, which is the UUID of the repository.
+//
+// This method may return partial but valid results in case of error, and it is up to
+// callers to decide whether to discard.
+//
+// API docs: https://developer.atlassian.com/cloud/bitbucket/rest/api-group-workspaces/#api-workspaces-workspace-permissions-repositories-get
+func (p *Provider) FetchUserPerms(ctx context.Context, account *extsvc.Account, opts authz.FetchPermsOptions) (*authz.ExternalUserPermissions, error) {
+	switch {
+	case account == nil:
+		return nil, errors.New("no account provided")
+	case account.Data == nil:
+		return nil, errors.New("no account data provided")
+	case !extsvc.IsHostOfAccount(p.codeHost, account):
+		return nil, errors.Errorf("not a code host of the account: want %q but have %q",
+			p.codeHost.ServiceID, account.AccountSpec.ServiceID)
+	}
+
+	var user bitbucketcloud.Account
+	if err := json.Unmarshal(*account.Data, &user); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling account data")
+	}
+
+	var extIDs []extsvc.RepoID
+	for _, ws := range p.workspaces {
+		perms, err := p.permissions(ctx, func() (*bitbucketcloud.PaginatedResultSet, error) {
+			return p.client.WorkspaceRepoPermissions(ws, fmt.Sprintf("user.uuid=%q", user.UUID))
+		})
+		for _, perm := range perms {
+			if perm.Repository != nil {
+				extIDs = append(extIDs, extsvc.RepoID(perm.Repository.UUID))
+			}
+		}
+		if err != nil {
+			return &authz.ExternalUserPermissions{Exacts: extIDs}, errors.Wrapf(err, "listing repository permissions of workspace %q", ws)
+		}
+	}
+
+	return &authz.ExternalUserPermissions{
+		Exacts: extIDs,
+	}, nil
+}
+
+// FetchUserPermsByToken is the same as FetchUserPerms, but it only requires a
+// token.
+func (p *Provider) FetchUserPermsByToken(ctx context.Context, token string, opts authz.FetchPermsOptions) (*authz.ExternalUserPermissions, error) {
+	return nil, &authz.ErrUnimplemented{Feature: "bitbucketcloud.FetchUserPermsByToken"}
+}
+
+// FetchRepoPerms returns a list of user IDs (on code host) who have read access to
+// the given repo on the code host. The user ID has the same value as it would
+// be used as extsvc.Account.AccountID. The returned list includes both direct access
+// and inherited from the group membership.
+//
+// This method may return partial but valid results in case of error, and it is up to
+// callers to decide whether to discard.
+//
+// API docs: https://developer.atlassian.com/cloud/bitbucket/rest/api-group-workspaces/#api-workspaces-workspace-permissions-repositories-repo-slug-get
+func (p *Provider) FetchRepoPerms(ctx context.Context, repo *extsvc.Repository, opts authz.FetchPermsOptions) ([]extsvc.AccountID, error) {
+	switch {
+	case repo == nil:
+		return nil, errors.New("no repo provided")
+	case !extsvc.IsHostOfRepo(p.codeHost, &repo.ExternalRepoSpec):
+		return nil, errors.Errorf("not a code host of the repo: want %q but have %q",
+			p.codeHost.ServiceID, repo.ServiceID)
+	}
+
+	// The URI of a Bitbucket Cloud repository is "<host>/<workspace>/<slug>".
+	parts := strings.Split(repo.URI, "/")
+	if len(parts) < 3 {
+		return nil, errors.Errorf("malformed Bitbucket Cloud repository URI %q", repo.URI)
+	}
+	workspace, slug := parts[len(parts)-2], parts[len(parts)-1]
+
+	perms, err := p.permissions(ctx, func() (*bitbucketcloud.PaginatedResultSet, error) {
+		return p.client.RepoUserPermissions(workspace, slug)
+	})
+
+	extIDs := make([]extsvc.AccountID, 0, len(perms))
+	for _, perm := range perms {
+		if perm.User != nil {
+			extIDs = append(extIDs, extsvc.AccountID(perm.User.UUID))
+		}
+	}
+
+	return extIDs, err
+}
+
+// permissions returns all repository permissions of the result set returned by list.
+// Every permission level grants read access, so they are not filtered.
+func (p *Provider) permissions(ctx context.Context, list func() (*bitbucketcloud.PaginatedResultSet, error)) ([]*bitbucketcloud.RepoPermission, error) {
+	rs, err := list()
+	if err != nil {
+		return nil, err
+	}
+
+	var perms []*bitbucketcloud.RepoPermission
+	for {
+		v, err := rs.Next(ctx)
+		if err != nil {
+			return perms, err
+		}
+		if v == nil {
+			return perms, nil
+		}
+		perms = append(perms, v.(*bitbucketcloud.RepoPermission))
+	}
+}
+
+// member returns the account of the member of the configured workspaces with the
+// given nickname, or nil if there is none.
+func (p *Provider) member(ctx context.Context, nickname string) (*bitbucketcloud.Account, error) {
+	for _, ws := range p.workspaces {
+		rs, err := p.client.WorkspaceMembers(ws)
+		if err != nil {
+			return nil, err
+		}
+
+		for {
+			v, err := rs.Next(ctx)
+			if err != nil {
+				return nil, errors.Wrapf(err, "listing members of workspace %q", ws)
+			}
+			if v == nil {
+				break
+			}
+			if m := v.(*bitbucketcloud.WorkspaceMembership); m.User != nil && m.User.Nickname == nickname {
+				return m.User, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,148 @@
+package bitbucketcloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestNewAuthzProviders(t *testing.T) {
+	conn := func(authorization *schema.BitbucketCloudAuthorization) *types.BitbucketCloudConnection {
+		return &types.BitbucketCloudConnection{
+			URN: "extsvc:bitbucketcloud:1",
+			BitbucketCloudConnection: &schema.BitbucketCloudConnection{
+				Url:           "https://bitbucket.org",
+				Username:      "admin",
+				AppPassword:   "secret",
+				Teams:         []string{"sourcegraph"},
+				Authorization: authorization,
+			},
+		}
+	}
+
+	t.Run("no authorization", func(t *testing.T) {
+		ps, problems, warnings := NewAuthzProviders([]*types.BitbucketCloudConnection{conn(nil)})
+		assert.Empty(t, ps)
+		assert.Empty(t, problems)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("no identity provider", func(t *testing.T) {
+		ps, problems, _ := NewAuthzProviders([]*types.BitbucketCloudConnection{conn(&schema.BitbucketCloudAuthorization{})})
+		assert.Empty(t, ps)
+		assert.Equal(t, []string{"No identityProvider was specified"}, problems)
+	})
+
+	t.Run("username identity provider", func(t *testing.T) {
+		ps, problems, _ := NewAuthzProviders([]*types.BitbucketCloudConnection{conn(&schema.BitbucketCloudAuthorization{
+			IdentityProvider: schema.BitbucketCloudIdentityProvider{
+				Username: &schema.BitbucketCloudUsernameIdentity{Type: "username"},
+			},
+		})})
+		assert.Empty(t, problems)
+		require.Len(t, ps, 1)
+
+		p := ps[0].(*Provider)
+		assert.Equal(t, "https://bitbucket.org/", p.ServiceID())
+		assert.Equal(t, extsvc.TypeBitbucketCloud, p.ServiceType())
+		assert.Equal(t, []string{"sourcegraph"}, p.workspaces)
+	})
+}
+
+func TestProvider(t *testing.T) {
+	alice := &bitbucketcloud.Account{Nickname: "alice", UUID: "{a11ce}"}
+	bob := &bitbucketcloud.Account{Nickname: "bob", UUID: "{b0b}"}
+	repo := &bitbucketcloud.Repo{UUID: "{2e90}", FullName: "sourcegraph/sourcegraph"}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var values []any
+		switch r.URL.Path {
+		case "/2.0/workspaces/sourcegraph/members":
+			values = []any{
+				&bitbucketcloud.WorkspaceMembership{User: bob},
+				&bitbucketcloud.WorkspaceMembership{User: alice},
+			}
+		case "/2.0/workspaces/sourcegraph/permissions/repositories":
+			if r.URL.Query().Get("q") == `user.uuid="{a11ce}"` {
+				values = []any{&bitbucketcloud.RepoPermission{Permission: "read", User: alice, Repository: repo}}
+			}
+		case "/2.0/workspaces/sourcegraph/permissions/repositories/sourcegraph":
+			values = []any{
+				&bitbucketcloud.RepoPermission{Permission: "admin", User: bob, Repository: repo},
+				&bitbucketcloud.RepoPermission{Permission: "read", User: alice, Repository: repo},
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"values": values})
+	}))
+	t.Cleanup(srv.Close)
+
+	cli, err := bitbucketcloud.NewClient("extsvc:bitbucketcloud:1", &schema.BitbucketCloudConnection{
+		ApiURL:      srv.URL,
+		Url:         "https://bitbucket.org",
+		Username:    "admin",
+		AppPassword: "secret",
+	}, nil)
+	require.NoError(t, err)
+
+	baseURL, _ := url.Parse("https://bitbucket.org/")
+	p := NewProvider(cli, "extsvc:bitbucketcloud:1", baseURL, []string{"sourcegraph"})
+	ctx := context.Background()
+
+	var account *extsvc.Account
+	t.Run("FetchAccount", func(t *testing.T) {
+		account, err = p.FetchAccount(ctx, &types.User{ID: 1, Username: "alice"}, nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, account)
+		assert.Equal(t, extsvc.AccountSpec{
+			ServiceType: extsvc.TypeBitbucketCloud,
+			ServiceID:   "https://bitbucket.org/",
+			AccountID:   "{a11ce}",
+		}, account.AccountSpec)
+
+		unknown, err := p.FetchAccount(ctx, &types.User{ID: 2, Username: "mallory"}, nil, nil)
+		require.NoError(t, err)
+		assert.Nil(t, unknown)
+	})
+
+	t.Run("FetchUserPerms", func(t *testing.T) {
+		require.NotNil(t, account)
+
+		perms, err := p.FetchUserPerms(ctx, account, authz.FetchPermsOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []extsvc.RepoID{"{2e90}"}, perms.Exacts)
+
+		other := *account
+		other.ServiceID = "https://bitbucket.example.com/"
+		_, err = p.FetchUserPerms(ctx, &other, authz.FetchPermsOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("FetchRepoPerms", func(t *testing.T) {
+		ids, err := p.FetchRepoPerms(ctx, &extsvc.Repository{
+			URI: "bitbucket.org/sourcegraph/sourcegraph",
+			ExternalRepoSpec: api.ExternalRepoSpec{
+				ID:          "{2e90}",
+				ServiceType: extsvc.TypeBitbucketCloud,
+				ServiceID:   "https://bitbucket.org/",
+			},
+		}, authz.FetchPermsOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []extsvc.AccountID{"{b0b}", "{a11ce}"}, ids)
+	})
+}
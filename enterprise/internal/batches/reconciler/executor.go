@@ -166,6 +166,23 @@ func (e *executor) pushChangesetPatch(ctx context.Context) (err error) {
 	if err != nil {
 		return err
 	}
+
+	// Some code hosts need the commit to be pushed in a specific way.
+	if pcss, ok := css.(sources.PushPreparingChangesetSource); ok {
+		cs := &sources.Changeset{
+			Title:      e.spec.Spec.Title,
+			Body:       e.spec.Spec.Body,
+			BaseRef:    e.spec.Spec.BaseRef,
+			HeadRef:    e.spec.Spec.HeadRef,
+			RemoteRepo: e.remoteRepo,
+			TargetRepo: e.targetRepo,
+			Changeset:  e.ch,
+		}
+		if err := pcss.PreparePush(ctx, cs, &opts); err != nil {
+			return errors.Wrap(err, "preparing push")
+		}
+	}
+
 	return e.pushCommit(ctx, opts)
 }
 
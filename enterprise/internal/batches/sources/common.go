@@ -52,6 +52,17 @@ type ForkableChangesetSource interface {
 	GetUserFork(ctx context.Context, targetRepo *types.Repo) (*types.Repo, error)
 }
 
+// A PushPreparingChangesetSource needs to adjust the commit that is created and
+// pushed for a changeset, because its code host expects changes to be pushed in
+// a specific way.
+type PushPreparingChangesetSource interface {
+	ChangesetSource
+
+	// PreparePush modifies the request to create and push the commit of the
+	// given Changeset before it is sent to gitserver.
+	PreparePush(context.Context, *Changeset, *protocol.CreateCommitFromPatchRequest) error
+}
+
 // A ChangesetSource can load the latest state of a list of Changesets.
 type ChangesetSource interface {
 	// GitserverPushConfig returns an authenticated push config used for pushing
@@ -0,0 +1,293 @@
+package sources
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+
+	gerritbatches "github.com/sourcegraph/sourcegraph/enterprise/internal/batches/sources/gerrit"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/auth"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gerrit"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/gitdomain"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// GerritSource publishes changesets as Gerrit changes.
+//
+// Gerrit has no concept of pull requests from a branch: a change is created
+// (or a new patch set is added to it) by pushing a commit carrying a Change-Id
+// trailer to the magic refs/for/<branch> ref. The commit message of the change
+// is its description, so the changeset's title and body are kept in sync with
+// it.
+type GerritSource struct {
+	client *gerrit.Client
+}
+
+var (
+	_ ChangesetSource              = GerritSource{}
+	_ DraftChangesetSource         = GerritSource{}
+	_ PushPreparingChangesetSource = GerritSource{}
+)
+
+func NewGerritSource(svc *types.ExternalService, cf *httpcli.Factory) (*GerritSource, error) {
+	var c schema.GerritConnection
+	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
+		return nil, errors.Wrapf(err, "external service id=%d", svc.ID)
+	}
+
+	if cf == nil {
+		cf = httpcli.ExternalClientFactory
+	}
+
+	cli, err := cf.Doer()
+	if err != nil {
+		return nil, errors.Wrap(err, "creating external client")
+	}
+
+	client, err := gerrit.NewClient(svc.URN(), &c, cli)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating Gerrit client")
+	}
+
+	return &GerritSource{client: client}, nil
+}
+
+// GitserverPushConfig returns an authenticated push config used for pushing
+// commits to the code host.
+func (s GerritSource) GitserverPushConfig(ctx context.Context, store database.ExternalServiceStore, repo *types.Repo) (*protocol.PushConfig, error) {
+	return gitserverPushConfig(ctx, store, repo, s.client.Authenticator())
+}
+
+// WithAuthenticator returns a copy of the original Source configured to use the
+// given authenticator, provided that authenticator type is supported by the
+// code host.
+func (s GerritSource) WithAuthenticator(a auth.Authenticator) (ChangesetSource, error) {
+	switch a.(type) {
+	case *auth.BasicAuth,
+		*auth.BasicAuthWithSSH:
+		break
+
+	default:
+		return nil, newUnsupportedAuthenticatorError("GerritSource", a)
+	}
+
+	return &GerritSource{client: s.client.WithAuthenticator(a)}, nil
+}
+
+// ValidateAuthenticator validates the currently set authenticator is usable.
+// Returns an error, when validating the Authenticator yielded an error.
+func (s GerritSource) ValidateAuthenticator(ctx context.Context) error {
+	_, err := s.client.GetAuthenticatedAccount(ctx)
+	return err
+}
+
+// PreparePush adds the Change-Id trailer to the commit and makes gitserver
+// push it to refs/for/<base branch>, which creates the change or adds a new
+// patch set to it.
+func (s GerritSource) PreparePush(ctx context.Context, cs *Changeset, opts *protocol.CreateCommitFromPatchRequest) error {
+	changeID := changeIDFor(cs)
+
+	// Once the change exists, its commit message is the source of truth for
+	// the title and body, which are updated separately. Reusing it keeps new
+	// patch sets from reverting an updated description.
+	if change, ok := cs.Metadata.(*gerritbatches.AnnotatedChange); ok && change.CommitMessage() != "" {
+		opts.CommitInfo.Message = change.CommitMessage()
+	} else {
+		opts.CommitInfo.Message = gerritbatches.CommitMessage(opts.CommitInfo.Message, "", changeID)
+	}
+
+	pushRef := "refs/for/" + gitdomain.AbbreviateRef(cs.BaseRef)
+	opts.PushRef = &pushRef
+	return nil
+}
+
+// LoadChangeset loads the given Changeset from the source and updates it. If
+// the Changeset could not be found on the source, a ChangesetNotFoundError is
+// returned.
+func (s GerritSource) LoadChangeset(ctx context.Context, cs *Changeset) error {
+	change, err := s.client.GetChange(ctx, cs.ExternalID)
+	if err != nil {
+		if errcode.IsNotFound(err) {
+			return ChangesetNotFoundError{Changeset: cs}
+		}
+		return errors.Wrap(err, "getting change")
+	}
+
+	return s.setChangesetMetadata(change, cs)
+}
+
+// CreateChangeset will create the Changeset on the source. If it already
+// exists, *Changeset will be populated and the return value will be true.
+//
+// The change has already been created by pushing the commit, so this only
+// loads it.
+func (s GerritSource) CreateChangeset(ctx context.Context, cs *Changeset) (bool, error) {
+	project, err := gerritProject(cs.TargetRepo)
+	if err != nil {
+		return false, err
+	}
+
+	id := gerrit.ChangeTriplet(project.Name, gitdomain.AbbreviateRef(cs.BaseRef), changeIDFor(cs))
+	change, err := s.client.GetChange(ctx, id)
+	if err != nil {
+		if errcode.IsNotFound(err) {
+			return false, errors.Newf("change %q not found, even though the commit has been pushed", id)
+		}
+		return false, errors.Wrap(err, "getting change")
+	}
+
+	// Gerrit changes are not associated with a source branch, so we record the
+	// branch of the changeset spec instead.
+	cs.Changeset.ExternalBranch = gitdomain.EnsureRefPrefix(cs.HeadRef)
+
+	if err := s.setChangesetMetadata(change, cs); err != nil {
+		return false, err
+	}
+
+	// The commit message of the change is the one of the pushed commit, which
+	// is likely not the title and body of the changeset yet. Reporting that the
+	// change already existed makes the reconciler check whether it's outdated.
+	return true, nil
+}
+
+// CreateDraftChangeset creates the Changeset on the source as a work in
+// progress change. If it already exists, *Changeset will be populated and the
+// return value will be true.
+func (s GerritSource) CreateDraftChangeset(ctx context.Context, cs *Changeset) (bool, error) {
+	exists, err := s.CreateChangeset(ctx, cs)
+	if err != nil {
+		return exists, err
+	}
+
+	change := cs.Metadata.(*gerritbatches.AnnotatedChange)
+	if !change.WorkInProgress {
+		if err := s.client.SetWorkInProgress(ctx, cs.ExternalID); err != nil {
+			return exists, errors.Wrap(err, "marking change as work in progress")
+		}
+		if err := s.LoadChangeset(ctx, cs); err != nil {
+			return exists, err
+		}
+	}
+	return exists, nil
+}
+
+// UndraftChangeset marks the work in progress change as ready for review.
+func (s GerritSource) UndraftChangeset(ctx context.Context, cs *Changeset) error {
+	change := cs.Metadata.(*gerritbatches.AnnotatedChange)
+
+	if change.WorkInProgress {
+		if err := s.client.SetReadyForReview(ctx, cs.ExternalID); err != nil {
+			return errors.Wrap(err, "marking change as ready for review")
+		}
+	}
+
+	return s.UpdateChangeset(ctx, cs)
+}
+
+// CloseChangeset will close the Changeset on the source, where "close"
+// means the appropriate final state on the codehost (e.g. "abandoned" on
+// Gerrit).
+func (s GerritSource) CloseChangeset(ctx context.Context, cs *Changeset) error {
+	change := cs.Metadata.(*gerritbatches.AnnotatedChange)
+
+	if change.Status != gerrit.ChangeStatusAbandoned {
+		if err := s.client.AbandonChange(ctx, cs.ExternalID); err != nil {
+			return errors.Wrap(err, "abandoning change")
+		}
+	}
+
+	return s.LoadChangeset(ctx, cs)
+}
+
+// UpdateChangeset can update Changesets.
+func (s GerritSource) UpdateChangeset(ctx context.Context, cs *Changeset) error {
+	change := cs.Metadata.(*gerritbatches.AnnotatedChange)
+
+	if base := gitdomain.AbbreviateRef(cs.BaseRef); base != change.Branch {
+		if err := s.client.MoveChange(ctx, cs.ExternalID, base); err != nil {
+			return errors.Wrap(err, "moving change")
+		}
+	}
+
+	// Gerrit rejects commit messages that don't change anything.
+	if message := gerritbatches.CommitMessage(cs.Title, cs.Body, change.ChangeID); message != change.CommitMessage() {
+		if err := s.client.SetCommitMessage(ctx, cs.ExternalID, message); err != nil {
+			return errors.Wrap(err, "setting commit message")
+		}
+	}
+
+	return s.LoadChangeset(ctx, cs)
+}
+
+// ReopenChangeset will reopen the Changeset on the source, if it's closed.
+// If not, it's a noop.
+func (s GerritSource) ReopenChangeset(ctx context.Context, cs *Changeset) error {
+	change := cs.Metadata.(*gerritbatches.AnnotatedChange)
+
+	if change.Status == gerrit.ChangeStatusAbandoned {
+		if err := s.client.RestoreChange(ctx, cs.ExternalID); err != nil {
+			return errors.Wrap(err, "restoring change")
+		}
+	}
+
+	return s.LoadChangeset(ctx, cs)
+}
+
+// CreateComment posts a comment on the Changeset.
+func (s GerritSource) CreateComment(ctx context.Context, cs *Changeset, comment string) error {
+	return s.client.SetReview(ctx, cs.ExternalID, gerrit.ReviewInput{Message: comment})
+}
+
+// MergeChangeset merges a Changeset on the code host, if in a mergeable state.
+// Gerrit changes consist of a single commit, so squash has no effect: how the
+// change is merged is determined by the submit type of the project.
+func (s GerritSource) MergeChangeset(ctx context.Context, cs *Changeset, squash bool) error {
+	if err := s.client.SubmitChange(ctx, cs.ExternalID); err != nil {
+		if gerrit.IsConflict(err) {
+			return ChangesetNotMergeableError{ErrorMsg: err.Error()}
+		}
+		return errors.Wrap(err, "submitting change")
+	}
+
+	return s.LoadChangeset(ctx, cs)
+}
+
+func (s GerritSource) setChangesetMetadata(change *gerrit.Change, cs *Changeset) error {
+	if err := cs.SetMetadata(&gerritbatches.AnnotatedChange{
+		Change:      change,
+		CodeHostURL: s.client.URL.String(),
+	}); err != nil {
+		return errors.Wrap(err, "setting changeset metadata")
+	}
+
+	return nil
+}
+
+// changeIDFor returns the Change-Id of the Gerrit change of the given
+// changeset. Imported changes already have one; for changesets we publish
+// ourselves it is derived from the changeset, so that pushing again adds a new
+// patch set to the same change rather than creating another one.
+func changeIDFor(cs *Changeset) string {
+	if change, ok := cs.Metadata.(*gerritbatches.AnnotatedChange); ok && change.ChangeID != "" {
+		return change.ChangeID
+	}
+
+	h := sha1.New()
+	fmt.Fprintf(h, "%s:%d", cs.TargetRepo.Name, cs.Changeset.ID)
+	return fmt.Sprintf("I%x", h.Sum(nil))
+}
+
+func gerritProject(repo *types.Repo) (*gerrit.Project, error) {
+	project, ok := repo.Metadata.(*gerrit.Project)
+	if !ok {
+		return nil, errors.Errorf("unexpected repo metadata type %T for Gerrit repository", repo.Metadata)
+	}
+	return project, nil
+}
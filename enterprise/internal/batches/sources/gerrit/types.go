@@ -0,0 +1,75 @@
+package gerrit
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gerrit"
+)
+
+// AnnotatedChange adds metadata we need that lives outside the main Change type
+// returned by the Gerrit API alongside the change. This type is used as the
+// primary metadata type for Gerrit changesets.
+type AnnotatedChange struct {
+	*gerrit.Change
+
+	// CodeHostURL is the base URL of the Gerrit instance, which is required to
+	// build the web URL of the change.
+	CodeHostURL string
+}
+
+// URL returns the web URL of the change.
+func (c *AnnotatedChange) URL() (string, error) {
+	u, err := url.Parse(c.CodeHostURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, "c", c.Project, "+", strconv.Itoa(c.Number))
+	return u.String(), nil
+}
+
+// Body returns the description of the change, which is the commit message of
+// the current revision without its subject and Change-Id trailer.
+func (c *AnnotatedChange) Body() string {
+	msg := strings.TrimSpace(c.CommitMessage())
+
+	// The subject is the first paragraph of the commit message.
+	parts := strings.SplitN(msg, "\n\n", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	body := parts[1]
+
+	// The Change-Id trailer is always the last line of the message.
+	if i := strings.LastIndex(body, "\n"); i >= 0 && isChangeIDTrailer(body[i+1:]) {
+		body = body[:i]
+	} else if isChangeIDTrailer(body) {
+		body = ""
+	}
+
+	return strings.TrimSpace(body)
+}
+
+// CommitMessage builds the commit message for a change with the given title,
+// body and Change-Id. It is the inverse of Body.
+func CommitMessage(title, body, changeID string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSpace(title))
+	sb.WriteString("\n\n")
+	if body = strings.TrimSpace(body); body != "" {
+		sb.WriteString(body)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString(changeIDTrailer)
+	sb.WriteString(changeID)
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+const changeIDTrailer = "Change-Id: "
+
+func isChangeIDTrailer(line string) bool {
+	return strings.HasPrefix(line, changeIDTrailer)
+}
@@ -0,0 +1,327 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gerritbatches "github.com/sourcegraph/sourcegraph/enterprise/internal/batches/sources/gerrit"
+	btypes "github.com/sourcegraph/sourcegraph/enterprise/internal/batches/types"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/auth"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gerrit"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestNewGerritSource(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		for name, input := range map[string]string{
+			"invalid JSON":   "invalid JSON",
+			"invalid schema": `{"password": ["not a string"]}`,
+			"bad URL":        `{"url": "http://[::1]:namedport"}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				s, err := NewGerritSource(&types.ExternalService{
+					Config: input,
+				}, nil)
+				assert.Nil(t, s)
+				assert.NotNil(t, err)
+			})
+		}
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		s, err := NewGerritSource(&types.ExternalService{
+			Config: `{"url": "https://gerrit.sgdev.org"}`,
+		}, nil)
+		assert.NotNil(t, s)
+		assert.Nil(t, err)
+	})
+}
+
+func TestGerritSource_WithAuthenticator(t *testing.T) {
+	s, _ := newFakeGerritSource(t)
+
+	t.Run("supported", func(t *testing.T) {
+		for name, a := range map[string]auth.Authenticator{
+			"BasicAuth":        &auth.BasicAuth{},
+			"BasicAuthWithSSH": &auth.BasicAuthWithSSH{},
+		} {
+			t.Run(name, func(t *testing.T) {
+				src, err := s.WithAuthenticator(a)
+				assert.Nil(t, err)
+				assert.Same(t, a, src.(*GerritSource).client.Authenticator())
+			})
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		for name, a := range map[string]auth.Authenticator{
+			"OAuthBearerToken": &auth.OAuthBearerToken{},
+			"nil":              nil,
+		} {
+			t.Run(name, func(t *testing.T) {
+				src, err := s.WithAuthenticator(a)
+				assert.Nil(t, src)
+				assert.NotNil(t, err)
+			})
+		}
+	})
+}
+
+func TestGerritSource_PreparePush(t *testing.T) {
+	s, _ := newFakeGerritSource(t)
+	cs := newGerritChangeset()
+
+	t.Run("new change", func(t *testing.T) {
+		opts := protocol.CreateCommitFromPatchRequest{
+			TargetRef:  "refs/heads/my-branch",
+			CommitInfo: protocol.PatchCommitInfo{Message: "Do the thing"},
+		}
+		assert.Nil(t, s.PreparePush(context.Background(), cs, &opts))
+
+		assert.Equal(t, "Do the thing\n\nChange-Id: "+changeIDFor(cs)+"\n", opts.CommitInfo.Message)
+		assert.Equal(t, "refs/heads/my-branch", opts.TargetRef)
+		if assert.NotNil(t, opts.PushRef) {
+			assert.Equal(t, "refs/for/main", *opts.PushRef)
+		}
+	})
+
+	t.Run("existing change", func(t *testing.T) {
+		cs := newGerritChangeset()
+		assert.Nil(t, cs.SetMetadata(&gerritbatches.AnnotatedChange{
+			Change: newGerritChange("Ifeedface", "Title\n\nBody\n\nChange-Id: Ifeedface\n"),
+		}))
+
+		opts := protocol.CreateCommitFromPatchRequest{
+			CommitInfo: protocol.PatchCommitInfo{Message: "Do the thing"},
+		}
+		assert.Nil(t, s.PreparePush(context.Background(), cs, &opts))
+		assert.Equal(t, "Title\n\nBody\n\nChange-Id: Ifeedface\n", opts.CommitInfo.Message)
+	})
+}
+
+func TestGerritSource_changeIDFor(t *testing.T) {
+	cs := newGerritChangeset()
+	id := changeIDFor(cs)
+
+	assert.Regexp(t, "^I[0-9a-f]{40}$", id)
+	assert.Equal(t, id, changeIDFor(newGerritChangeset()), "Change-Id should be stable")
+
+	other := newGerritChangeset()
+	other.Changeset.ID = 2
+	assert.NotEqual(t, id, changeIDFor(other))
+}
+
+func TestGerritSource_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	s, fake := newFakeGerritSource(t)
+
+	cs := newGerritChangeset()
+	changeID := changeIDFor(cs)
+
+	// Pushing the commit creates the change.
+	fake.change = newGerritChange(changeID, "Do the thing\n\nChange-Id: "+changeID+"\n")
+
+	exists, err := s.CreateChangeset(ctx, cs)
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, "42", cs.ExternalID)
+	assert.Equal(t, extsvc.TypeGerrit, cs.ExternalServiceType)
+	assert.Equal(t, "refs/heads/my-branch", cs.ExternalBranch)
+	assert.Equal(t, "project/name~main~"+changeID, fake.lastGet)
+
+	changeURL, err := cs.Changeset.URL()
+	assert.Nil(t, err)
+	assert.Equal(t, fake.srv.URL+"/c/project/name/+/42", changeURL)
+
+	outdated, err := cs.IsOutdated()
+	assert.Nil(t, err)
+	assert.True(t, outdated)
+
+	assert.Nil(t, s.UpdateChangeset(ctx, cs))
+	assert.Equal(t, "Title\n\nBody\n\nChange-Id: "+changeID+"\n", fake.change.CommitMessage())
+
+	outdated, err = cs.IsOutdated()
+	assert.Nil(t, err)
+	assert.False(t, outdated)
+
+	// Updating again doesn't create another patch set.
+	fake.messageUpdates = 0
+	assert.Nil(t, s.UpdateChangeset(ctx, cs))
+	assert.Equal(t, 0, fake.messageUpdates)
+
+	assert.Nil(t, s.CloseChangeset(ctx, cs))
+	assert.Equal(t, gerrit.ChangeStatusAbandoned, cs.Metadata.(*gerritbatches.AnnotatedChange).Status)
+
+	assert.Nil(t, s.ReopenChangeset(ctx, cs))
+	assert.Equal(t, gerrit.ChangeStatusNew, cs.Metadata.(*gerritbatches.AnnotatedChange).Status)
+
+	fake.submitConflict = true
+	err = s.MergeChangeset(ctx, cs, false)
+	assert.ErrorAs(t, err, &ChangesetNotMergeableError{})
+
+	fake.submitConflict = false
+	assert.Nil(t, s.MergeChangeset(ctx, cs, true))
+	assert.Equal(t, gerrit.ChangeStatusMerged, cs.Metadata.(*gerritbatches.AnnotatedChange).Status)
+}
+
+func TestGerritSource_Draft(t *testing.T) {
+	ctx := context.Background()
+	s, fake := newFakeGerritSource(t)
+
+	cs := newGerritChangeset()
+	changeID := changeIDFor(cs)
+	fake.change = newGerritChange(changeID, "Title\n\nBody\n\nChange-Id: "+changeID+"\n")
+
+	exists, err := s.CreateDraftChangeset(ctx, cs)
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	assert.True(t, cs.Metadata.(*gerritbatches.AnnotatedChange).WorkInProgress)
+
+	assert.Nil(t, s.UndraftChangeset(ctx, cs))
+	assert.False(t, cs.Metadata.(*gerritbatches.AnnotatedChange).WorkInProgress)
+}
+
+func TestGerritSource_LoadChangeset_NotFound(t *testing.T) {
+	s, _ := newFakeGerritSource(t)
+
+	cs := newGerritChangeset()
+	cs.ExternalID = "43"
+
+	err := s.LoadChangeset(context.Background(), cs)
+	assert.ErrorAs(t, err, &ChangesetNotFoundError{})
+}
+
+func newGerritChangeset() *Changeset {
+	repo := &types.Repo{
+		Name:     "gerrit.sgdev.org/project/name",
+		Metadata: &gerrit.Project{ID: "project%2Fname", Name: "project/name"},
+	}
+	return &Changeset{
+		Title:      "Title",
+		Body:       "Body",
+		HeadRef:    "refs/heads/my-branch",
+		BaseRef:    "refs/heads/main",
+		TargetRepo: repo,
+		RemoteRepo: repo,
+		Changeset:  &btypes.Changeset{ID: 1},
+	}
+}
+
+func newGerritChange(changeID, message string) *gerrit.Change {
+	return &gerrit.Change{
+		Project:         "project/name",
+		Branch:          "main",
+		ChangeID:        changeID,
+		Subject:         strings.SplitN(message, "\n", 2)[0],
+		Status:          gerrit.ChangeStatusNew,
+		Number:          42,
+		CurrentRevision: "deadbeef",
+		Revisions: map[string]gerrit.Revision{
+			"deadbeef": {
+				Number: 1,
+				Ref:    "refs/changes/42/42/1",
+				Commit: &gerrit.Commit{Message: message},
+			},
+		},
+	}
+}
+
+// fakeGerrit is a minimal stand-in for the Gerrit REST API that holds a single
+// change with the number 42.
+type fakeGerrit struct {
+	srv *httptest.Server
+
+	change         *gerrit.Change
+	lastGet        string
+	messageUpdates int
+	submitConflict bool
+}
+
+func newFakeGerritSource(t *testing.T) (*GerritSource, *fakeGerrit) {
+	t.Helper()
+
+	fake := &fakeGerrit{}
+	fake.srv = httptest.NewServer(http.HandlerFunc(fake.serveHTTP))
+	t.Cleanup(fake.srv.Close)
+
+	s, err := NewGerritSource(&types.ExternalService{
+		Kind:   extsvc.KindGerrit,
+		Config: fmt.Sprintf(`{"url": %q, "username": "admin", "password": "secret"}`, fake.srv.URL),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, fake
+}
+
+func (f *fakeGerrit) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// Project names in change triplets contain escaped slashes, so we need to
+	// split the path before unescaping it.
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.EscapedPath(), "/a/changes/"), "/", 2)
+	id, _ := url.PathUnescape(parts[0])
+	if f.change == nil || (id != "42" && id != gerrit.ChangeTriplet(f.change.Project, f.change.Branch, f.change.ChangeID)) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var action string
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+
+	switch action {
+	case "":
+		f.lastGet = id
+		bs, _ := json.Marshal(f.change)
+		_, _ = w.Write(append([]byte(")]}'\n"), bs...))
+		return
+
+	case "abandon":
+		f.change.Status = gerrit.ChangeStatusAbandoned
+
+	case "restore":
+		f.change.Status = gerrit.ChangeStatusNew
+
+	case "wip":
+		f.change.WorkInProgress = true
+
+	case "ready":
+		f.change.WorkInProgress = false
+
+	case "submit":
+		if f.submitConflict {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte("change is not submittable"))
+			return
+		}
+		f.change.Status = gerrit.ChangeStatusMerged
+
+	case "message":
+		var input gerrit.CommitMessageInput
+		bs, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(bs, &input)
+
+		f.messageUpdates++
+		f.change.Subject = strings.SplitN(input.Message, "\n", 2)[0]
+		f.change.Revisions[f.change.CurrentRevision].Commit.Message = input.Message
+		w.WriteHeader(http.StatusNoContent)
+		return
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	_, _ = w.Write([]byte(")]}'\n{}"))
+}
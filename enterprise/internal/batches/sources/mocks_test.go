@@ -2198,6 +2198,9 @@ type MockBitbucketCloudClient struct {
 	// RepoFunc is an instance of a mock function object controlling the
 	// behavior of the method Repo.
 	RepoFunc *BitbucketCloudClientRepoFunc
+	// RepoUserPermissionsFunc is an instance of a mock function object
+	// controlling the behavior of the method RepoUserPermissions.
+	RepoUserPermissionsFunc *BitbucketCloudClientRepoUserPermissionsFunc
 	// ReposFunc is an instance of a mock function object controlling the
 	// behavior of the method Repos.
 	ReposFunc *BitbucketCloudClientReposFunc
@@ -2207,6 +2210,12 @@ type MockBitbucketCloudClient struct {
 	// WithAuthenticatorFunc is an instance of a mock function object
 	// controlling the behavior of the method WithAuthenticator.
 	WithAuthenticatorFunc *BitbucketCloudClientWithAuthenticatorFunc
+	// WorkspaceMembersFunc is an instance of a mock function object controlling
+	// the behavior of the method WorkspaceMembers.
+	WorkspaceMembersFunc *BitbucketCloudClientWorkspaceMembersFunc
+	// WorkspaceRepoPermissionsFunc is an instance of a mock function object
+	// controlling the behavior of the method WorkspaceRepoPermissions.
+	WorkspaceRepoPermissionsFunc *BitbucketCloudClientWorkspaceRepoPermissionsFunc
 }
 
 // NewMockBitbucketCloudClient creates a new mock of the Client interface.
@@ -2268,6 +2277,11 @@ func NewMockBitbucketCloudClient() *MockBitbucketCloudClient {
 				return
 			},
 		},
+		RepoUserPermissionsFunc: &BitbucketCloudClientRepoUserPermissionsFunc{
+			defaultHook: func(string, string) (r0 *bitbucketcloud.PaginatedResultSet, r1 error) {
+				return
+			},
+		},
 		ReposFunc: &BitbucketCloudClientReposFunc{
 			defaultHook: func(context.Context, *bitbucketcloud.PageToken, string) (r0 []*bitbucketcloud.Repo, r1 *bitbucketcloud.PageToken, r2 error) {
 				return
@@ -2283,6 +2297,16 @@ func NewMockBitbucketCloudClient() *MockBitbucketCloudClient {
 				return
 			},
 		},
+		WorkspaceMembersFunc: &BitbucketCloudClientWorkspaceMembersFunc{
+			defaultHook: func(string) (r0 *bitbucketcloud.PaginatedResultSet, r1 error) {
+				return
+			},
+		},
+		WorkspaceRepoPermissionsFunc: &BitbucketCloudClientWorkspaceRepoPermissionsFunc{
+			defaultHook: func(string, string) (r0 *bitbucketcloud.PaginatedResultSet, r1 error) {
+				return
+			},
+		},
 	}
 }
 
@@ -2345,6 +2369,11 @@ func NewStrictMockBitbucketCloudClient() *MockBitbucketCloudClient {
 				panic("unexpected invocation of MockBitbucketCloudClient.Repo")
 			},
 		},
+		RepoUserPermissionsFunc: &BitbucketCloudClientRepoUserPermissionsFunc{
+			defaultHook: func(string, string) (*bitbucketcloud.PaginatedResultSet, error) {
+				panic("unexpected invocation of MockBitbucketCloudClient.RepoUserPermissions")
+			},
+		},
 		ReposFunc: &BitbucketCloudClientReposFunc{
 			defaultHook: func(context.Context, *bitbucketcloud.PageToken, string) ([]*bitbucketcloud.Repo, *bitbucketcloud.PageToken, error) {
 				panic("unexpected invocation of MockBitbucketCloudClient.Repos")
@@ -2360,6 +2389,16 @@ func NewStrictMockBitbucketCloudClient() *MockBitbucketCloudClient {
 				panic("unexpected invocation of MockBitbucketCloudClient.WithAuthenticator")
 			},
 		},
+		WorkspaceMembersFunc: &BitbucketCloudClientWorkspaceMembersFunc{
+			defaultHook: func(string) (*bitbucketcloud.PaginatedResultSet, error) {
+				panic("unexpected invocation of MockBitbucketCloudClient.WorkspaceMembers")
+			},
+		},
+		WorkspaceRepoPermissionsFunc: &BitbucketCloudClientWorkspaceRepoPermissionsFunc{
+			defaultHook: func(string, string) (*bitbucketcloud.PaginatedResultSet, error) {
+				panic("unexpected invocation of MockBitbucketCloudClient.WorkspaceRepoPermissions")
+			},
+		},
 	}
 }
 
@@ -2401,6 +2440,9 @@ func NewMockBitbucketCloudClientFrom(i bitbucketcloud.Client) *MockBitbucketClou
 		RepoFunc: &BitbucketCloudClientRepoFunc{
 			defaultHook: i.Repo,
 		},
+		RepoUserPermissionsFunc: &BitbucketCloudClientRepoUserPermissionsFunc{
+			defaultHook: i.RepoUserPermissions,
+		},
 		ReposFunc: &BitbucketCloudClientReposFunc{
 			defaultHook: i.Repos,
 		},
@@ -2410,6 +2452,12 @@ func NewMockBitbucketCloudClientFrom(i bitbucketcloud.Client) *MockBitbucketClou
 		WithAuthenticatorFunc: &BitbucketCloudClientWithAuthenticatorFunc{
 			defaultHook: i.WithAuthenticator,
 		},
+		WorkspaceMembersFunc: &BitbucketCloudClientWorkspaceMembersFunc{
+			defaultHook: i.WorkspaceMembers,
+		},
+		WorkspaceRepoPermissionsFunc: &BitbucketCloudClientWorkspaceRepoPermissionsFunc{
+			defaultHook: i.WorkspaceRepoPermissions,
+		},
 	}
 }
 
@@ -3642,6 +3690,116 @@ func (c BitbucketCloudClientRepoFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// BitbucketCloudClientRepoUserPermissionsFunc describes the behavior when
+// the RepoUserPermissions method of the parent MockBitbucketCloudClient
+// instance is invoked.
+type BitbucketCloudClientRepoUserPermissionsFunc struct {
+	defaultHook func(string, string) (*bitbucketcloud.PaginatedResultSet, error)
+	hooks       []func(string, string) (*bitbucketcloud.PaginatedResultSet, error)
+	history     []BitbucketCloudClientRepoUserPermissionsFuncCall
+	mutex       sync.Mutex
+}
+
+// RepoUserPermissions delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockBitbucketCloudClient) RepoUserPermissions(v0 string, v1 string) (*bitbucketcloud.PaginatedResultSet, error) {
+	r0, r1 := m.RepoUserPermissionsFunc.nextHook()(v0, v1)
+	m.RepoUserPermissionsFunc.appendCall(BitbucketCloudClientRepoUserPermissionsFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the RepoUserPermissions
+// method of the parent MockBitbucketCloudClient instance is invoked and the
+// hook queue is empty.
+func (f *BitbucketCloudClientRepoUserPermissionsFunc) SetDefaultHook(hook func(string, string) (*bitbucketcloud.PaginatedResultSet, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// RepoUserPermissions method of the parent MockBitbucketCloudClient
+// instance invokes the hook at the front of the queue and discards it.
+// After the queue is empty, the default hook function is invoked for any
+// future action.
+func (f *BitbucketCloudClientRepoUserPermissionsFunc) PushHook(hook func(string, string) (*bitbucketcloud.PaginatedResultSet, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *BitbucketCloudClientRepoUserPermissionsFunc) SetDefaultReturn(r0 *bitbucketcloud.PaginatedResultSet, r1 error) {
+	f.SetDefaultHook(func(string, string) (*bitbucketcloud.PaginatedResultSet, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *BitbucketCloudClientRepoUserPermissionsFunc) PushReturn(r0 *bitbucketcloud.PaginatedResultSet, r1 error) {
+	f.PushHook(func(string, string) (*bitbucketcloud.PaginatedResultSet, error) {
+		return r0, r1
+	})
+}
+
+func (f *BitbucketCloudClientRepoUserPermissionsFunc) nextHook() func(string, string) (*bitbucketcloud.PaginatedResultSet, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *BitbucketCloudClientRepoUserPermissionsFunc) appendCall(r0 BitbucketCloudClientRepoUserPermissionsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// BitbucketCloudClientRepoUserPermissionsFuncCall objects describing the
+// invocations of this function.
+func (f *BitbucketCloudClientRepoUserPermissionsFunc) History() []BitbucketCloudClientRepoUserPermissionsFuncCall {
+	f.mutex.Lock()
+	history := make([]BitbucketCloudClientRepoUserPermissionsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// BitbucketCloudClientRepoUserPermissionsFuncCall is an object that
+// describes an invocation of method RepoUserPermissions on an instance of
+// MockBitbucketCloudClient.
+type BitbucketCloudClientRepoUserPermissionsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method invocation.
+	Arg0 string
+	// Arg1 is the value of the 2nd argument passed to this method invocation.
+	Arg1 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 *bitbucketcloud.PaginatedResultSet
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c BitbucketCloudClientRepoUserPermissionsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c BitbucketCloudClientRepoUserPermissionsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // BitbucketCloudClientReposFunc describes the behavior when the Repos
 // method of the parent MockBitbucketCloudClient instance is invoked.
 type BitbucketCloudClientReposFunc struct {
@@ -3979,3 +4137,221 @@ func (c BitbucketCloudClientWithAuthenticatorFuncCall) Args() []interface{} {
 func (c BitbucketCloudClientWithAuthenticatorFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
+
+// BitbucketCloudClientWorkspaceMembersFunc describes the behavior when the
+// WorkspaceMembers method of the parent MockBitbucketCloudClient instance
+// is invoked.
+type BitbucketCloudClientWorkspaceMembersFunc struct {
+	defaultHook func(string) (*bitbucketcloud.PaginatedResultSet, error)
+	hooks       []func(string) (*bitbucketcloud.PaginatedResultSet, error)
+	history     []BitbucketCloudClientWorkspaceMembersFuncCall
+	mutex       sync.Mutex
+}
+
+// WorkspaceMembers delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockBitbucketCloudClient) WorkspaceMembers(v0 string) (*bitbucketcloud.PaginatedResultSet, error) {
+	r0, r1 := m.WorkspaceMembersFunc.nextHook()(v0)
+	m.WorkspaceMembersFunc.appendCall(BitbucketCloudClientWorkspaceMembersFuncCall{v0, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the WorkspaceMembers
+// method of the parent MockBitbucketCloudClient instance is invoked and the
+// hook queue is empty.
+func (f *BitbucketCloudClientWorkspaceMembersFunc) SetDefaultHook(hook func(string) (*bitbucketcloud.PaginatedResultSet, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// WorkspaceMembers method of the parent MockBitbucketCloudClient instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *BitbucketCloudClientWorkspaceMembersFunc) PushHook(hook func(string) (*bitbucketcloud.PaginatedResultSet, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *BitbucketCloudClientWorkspaceMembersFunc) SetDefaultReturn(r0 *bitbucketcloud.PaginatedResultSet, r1 error) {
+	f.SetDefaultHook(func(string) (*bitbucketcloud.PaginatedResultSet, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *BitbucketCloudClientWorkspaceMembersFunc) PushReturn(r0 *bitbucketcloud.PaginatedResultSet, r1 error) {
+	f.PushHook(func(string) (*bitbucketcloud.PaginatedResultSet, error) {
+		return r0, r1
+	})
+}
+
+func (f *BitbucketCloudClientWorkspaceMembersFunc) nextHook() func(string) (*bitbucketcloud.PaginatedResultSet, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *BitbucketCloudClientWorkspaceMembersFunc) appendCall(r0 BitbucketCloudClientWorkspaceMembersFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// BitbucketCloudClientWorkspaceMembersFuncCall objects describing the
+// invocations of this function.
+func (f *BitbucketCloudClientWorkspaceMembersFunc) History() []BitbucketCloudClientWorkspaceMembersFuncCall {
+	f.mutex.Lock()
+	history := make([]BitbucketCloudClientWorkspaceMembersFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// BitbucketCloudClientWorkspaceMembersFuncCall is an object that describes
+// an invocation of method WorkspaceMembers on an instance of
+// MockBitbucketCloudClient.
+type BitbucketCloudClientWorkspaceMembersFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method invocation.
+	Arg0 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 *bitbucketcloud.PaginatedResultSet
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c BitbucketCloudClientWorkspaceMembersFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c BitbucketCloudClientWorkspaceMembersFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
+// BitbucketCloudClientWorkspaceRepoPermissionsFunc describes the behavior
+// when the WorkspaceRepoPermissions method of the parent
+// MockBitbucketCloudClient instance is invoked.
+type BitbucketCloudClientWorkspaceRepoPermissionsFunc struct {
+	defaultHook func(string, string) (*bitbucketcloud.PaginatedResultSet, error)
+	hooks       []func(string, string) (*bitbucketcloud.PaginatedResultSet, error)
+	history     []BitbucketCloudClientWorkspaceRepoPermissionsFuncCall
+	mutex       sync.Mutex
+}
+
+// WorkspaceRepoPermissions delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockBitbucketCloudClient) WorkspaceRepoPermissions(v0 string, v1 string) (*bitbucketcloud.PaginatedResultSet, error) {
+	r0, r1 := m.WorkspaceRepoPermissionsFunc.nextHook()(v0, v1)
+	m.WorkspaceRepoPermissionsFunc.appendCall(BitbucketCloudClientWorkspaceRepoPermissionsFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// WorkspaceRepoPermissions method of the parent MockBitbucketCloudClient
+// instance is invoked and the hook queue is empty.
+func (f *BitbucketCloudClientWorkspaceRepoPermissionsFunc) SetDefaultHook(hook func(string, string) (*bitbucketcloud.PaginatedResultSet, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// WorkspaceRepoPermissions method of the parent MockBitbucketCloudClient
+// instance invokes the hook at the front of the queue and discards it.
+// After the queue is empty, the default hook function is invoked for any
+// future action.
+func (f *BitbucketCloudClientWorkspaceRepoPermissionsFunc) PushHook(hook func(string, string) (*bitbucketcloud.PaginatedResultSet, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *BitbucketCloudClientWorkspaceRepoPermissionsFunc) SetDefaultReturn(r0 *bitbucketcloud.PaginatedResultSet, r1 error) {
+	f.SetDefaultHook(func(string, string) (*bitbucketcloud.PaginatedResultSet, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *BitbucketCloudClientWorkspaceRepoPermissionsFunc) PushReturn(r0 *bitbucketcloud.PaginatedResultSet, r1 error) {
+	f.PushHook(func(string, string) (*bitbucketcloud.PaginatedResultSet, error) {
+		return r0, r1
+	})
+}
+
+func (f *BitbucketCloudClientWorkspaceRepoPermissionsFunc) nextHook() func(string, string) (*bitbucketcloud.PaginatedResultSet, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *BitbucketCloudClientWorkspaceRepoPermissionsFunc) appendCall(r0 BitbucketCloudClientWorkspaceRepoPermissionsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// BitbucketCloudClientWorkspaceRepoPermissionsFuncCall objects describing
+// the invocations of this function.
+func (f *BitbucketCloudClientWorkspaceRepoPermissionsFunc) History() []BitbucketCloudClientWorkspaceRepoPermissionsFuncCall {
+	f.mutex.Lock()
+	history := make([]BitbucketCloudClientWorkspaceRepoPermissionsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// BitbucketCloudClientWorkspaceRepoPermissionsFuncCall is an object that
+// describes an invocation of method WorkspaceRepoPermissions on an instance
+// of MockBitbucketCloudClient.
+type BitbucketCloudClientWorkspaceRepoPermissionsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method invocation.
+	Arg0 string
+	// Arg1 is the value of the 2nd argument passed to this method invocation.
+	Arg1 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 *bitbucketcloud.PaginatedResultSet
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c BitbucketCloudClientWorkspaceRepoPermissionsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c BitbucketCloudClientWorkspaceRepoPermissionsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
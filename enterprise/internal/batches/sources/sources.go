@@ -329,6 +329,10 @@ func loadExternalService(ctx context.Context, s database.ExternalServiceStore, o
 			if cfg.AppPassword != "" {
 				return e, nil
 			}
+		case *schema.GerritConnection:
+			if cfg.Password != "" {
+				return e, nil
+			}
 		}
 	}
 
@@ -348,6 +352,8 @@ func buildChangesetSource(cf *httpcli.Factory, externalService *types.ExternalSe
 		return NewBitbucketServerSource(externalService, cf)
 	case extsvc.KindBitbucketCloud:
 		return NewBitbucketCloudSource(externalService, cf)
+	case extsvc.KindGerrit:
+		return NewGerritSource(externalService, cf)
 	default:
 		return nil, errors.Errorf("unsupported external service type %q", extsvc.KindToType(externalService.Kind))
 	}
@@ -400,6 +406,9 @@ func setOAuthTokenAuth(u *vcs.URL, extSvcType, token string) error {
 	case extsvc.TypeBitbucketServer:
 		return errors.New("require username/token to push commits to BitbucketServer")
 
+	case extsvc.TypeGerrit:
+		return errors.New("require username/password to push commits to Gerrit")
+
 	default:
 		panic(fmt.Sprintf("setOAuthTokenAuth: invalid external service type %q", extSvcType))
 	}
@@ -413,7 +422,7 @@ func setBasicAuth(u *vcs.URL, extSvcType, username, password string) error {
 	case extsvc.TypeGitHub, extsvc.TypeGitLab:
 		return errors.New("need token to push commits to " + extSvcType)
 
-	case extsvc.TypeBitbucketServer, extsvc.TypeBitbucketCloud:
+	case extsvc.TypeBitbucketServer, extsvc.TypeBitbucketCloud, extsvc.TypeGerrit:
 		u.User = url.UserPassword(username, password)
 
 	default:
@@ -12,6 +12,7 @@ import (
 	"github.com/sourcegraph/log"
 
 	bbcs "github.com/sourcegraph/sourcegraph/enterprise/internal/batches/sources/bitbucketcloud"
+	gerritbatches "github.com/sourcegraph/sourcegraph/enterprise/internal/batches/sources/gerrit"
 	btypes "github.com/sourcegraph/sourcegraph/enterprise/internal/batches/types"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
@@ -19,6 +20,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gerrit"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
@@ -113,6 +115,9 @@ func computeCheckState(c *btypes.Changeset, events ChangesetEvents) btypes.Chang
 
 	case *bbcs.AnnotatedPullRequest:
 		return computeBitbucketCloudBuildState(c.UpdatedAt, m, events)
+
+	case *gerritbatches.AnnotatedChange:
+		return computeGerritVerifiedState(m)
 	}
 
 	return btypes.ChangesetCheckStateUnknown
@@ -236,6 +241,30 @@ func computeBitbucketCloudBuildState(lastSynced time.Time, apr *bbcs.AnnotatedPu
 	return combineCheckStates(states)
 }
 
+// computeGerritVerifiedState computes the check state of a Gerrit change from
+// the votes on its Verified label, which is where CI systems report to.
+func computeGerritVerifiedState(c *gerritbatches.AnnotatedChange) btypes.ChangesetCheckState {
+	label, ok := c.Labels[gerrit.LabelVerified]
+	if !ok {
+		return btypes.ChangesetCheckStateUnknown
+	}
+
+	states := []btypes.ChangesetCheckState{}
+	for _, vote := range label.All {
+		switch {
+		case vote.Value < 0:
+			states = append(states, btypes.ChangesetCheckStateFailed)
+		case vote.Value > 0:
+			states = append(states, btypes.ChangesetCheckStatePassed)
+		}
+	}
+	if len(states) == 0 {
+		return btypes.ChangesetCheckStatePending
+	}
+
+	return combineCheckStates(states)
+}
+
 func parseBitbucketCloudBuildState(s bitbucketcloud.PullRequestStatusState) btypes.ChangesetCheckState {
 	switch s {
 	case bitbucketcloud.PullRequestStatusStateFailed, bitbucketcloud.PullRequestStatusStateStopped:
@@ -513,6 +542,21 @@ func computeSingleChangesetExternalState(c *btypes.Changeset) (s btypes.Changese
 		default:
 			return "", errors.Errorf("unknown Bitbucket Cloud pull request state: %s", m.State)
 		}
+	case *gerritbatches.AnnotatedChange:
+		switch m.Status {
+		case gerrit.ChangeStatusAbandoned:
+			s = btypes.ChangesetExternalStateClosed
+		case gerrit.ChangeStatusMerged:
+			s = btypes.ChangesetExternalStateMerged
+		case gerrit.ChangeStatusNew:
+			if m.WorkInProgress {
+				s = btypes.ChangesetExternalStateDraft
+			} else {
+				s = btypes.ChangesetExternalStateOpen
+			}
+		default:
+			return "", errors.Errorf("unknown Gerrit change status: %s", m.Status)
+		}
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -582,6 +626,21 @@ func computeSingleChangesetReviewState(c *btypes.Changeset) (s btypes.ChangesetR
 			}
 		}
 
+	case *gerritbatches.AnnotatedChange:
+		// Negative votes on the Code-Review label block or discourage
+		// submitting a change, while the maximum vote approves it. Every other
+		// reviewer hasn't made up their mind yet.
+		for _, vote := range m.Labels[gerrit.LabelCodeReview].All {
+			switch {
+			case vote.Value < 0:
+				states[btypes.ChangesetReviewStateChangesRequested] = true
+			case vote.Value >= 2:
+				states[btypes.ChangesetReviewStateApproved] = true
+			default:
+				states[btypes.ChangesetReviewStatePending] = true
+			}
+		}
+
 	default:
 		return "", errors.New("unknown changeset type")
 	}
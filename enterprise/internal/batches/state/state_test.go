@@ -7,9 +7,11 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 
+	gerritbatches "github.com/sourcegraph/sourcegraph/enterprise/internal/batches/sources/gerrit"
 	btypes "github.com/sourcegraph/sourcegraph/enterprise/internal/batches/types"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gerrit"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/timeutil"
@@ -406,6 +408,39 @@ func TestComputeGitLabCheckState(t *testing.T) {
 	})
 }
 
+func TestComputeGerritVerifiedState(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		labels map[string]gerrit.ChangeLabel
+		want   btypes.ChangesetCheckState
+	}{
+		"no verified label": {
+			labels: nil,
+			want:   btypes.ChangesetCheckStateUnknown,
+		},
+		"no votes": {
+			labels: map[string]gerrit.ChangeLabel{gerrit.LabelVerified: {All: []gerrit.Approval{{Value: 0}}}},
+			want:   btypes.ChangesetCheckStatePending,
+		},
+		"verified": {
+			labels: map[string]gerrit.ChangeLabel{gerrit.LabelVerified: {All: []gerrit.Approval{{Value: 1}}}},
+			want:   btypes.ChangesetCheckStatePassed,
+		},
+		"failed": {
+			labels: map[string]gerrit.ChangeLabel{gerrit.LabelVerified: {All: []gerrit.Approval{{Value: 1}, {Value: -1}}}},
+			want:   btypes.ChangesetCheckStateFailed,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := gerritChangeset(time.Now(), gerrit.ChangeStatusNew, tc.labels)
+			if have := computeCheckState(c, nil); have != tc.want {
+				t.Errorf("wrong check state. have=%s, want=%s", have, tc.want)
+			}
+		})
+	}
+}
+
 func TestComputeReviewState(t *testing.T) {
 	t.Parallel()
 
@@ -535,6 +570,46 @@ func TestComputeReviewState(t *testing.T) {
 			},
 			want: btypes.ChangesetReviewStateChangesRequested,
 		},
+		{
+			name:      "gerrit - no votes",
+			changeset: gerritChangeset(daysAgo(0), gerrit.ChangeStatusNew, nil),
+			history:   []changesetStatesAtTime{},
+			want:      btypes.ChangesetReviewStatePending,
+		},
+		{
+			name: "gerrit - looks good to me, but someone else must approve",
+			changeset: gerritChangeset(daysAgo(0), gerrit.ChangeStatusNew, map[string]gerrit.ChangeLabel{
+				gerrit.LabelCodeReview: {All: []gerrit.Approval{{Value: 1}}},
+			}),
+			history: []changesetStatesAtTime{},
+			want:    btypes.ChangesetReviewStatePending,
+		},
+		{
+			name: "gerrit - approved",
+			changeset: gerritChangeset(daysAgo(0), gerrit.ChangeStatusNew, map[string]gerrit.ChangeLabel{
+				gerrit.LabelCodeReview: {All: []gerrit.Approval{{Value: 1}, {Value: 2}}},
+			}),
+			history: []changesetStatesAtTime{},
+			want:    btypes.ChangesetReviewStateApproved,
+		},
+		{
+			name: "gerrit - negative vote takes precedence",
+			changeset: gerritChangeset(daysAgo(0), gerrit.ChangeStatusNew, map[string]gerrit.ChangeLabel{
+				gerrit.LabelCodeReview: {All: []gerrit.Approval{{Value: 2}, {Value: -1}}},
+			}),
+			history: []changesetStatesAtTime{},
+			want:    btypes.ChangesetReviewStateChangesRequested,
+		},
+		{
+			name: "gerrit - changeset newer than events",
+			changeset: gerritChangeset(daysAgo(0), gerrit.ChangeStatusNew, map[string]gerrit.ChangeLabel{
+				gerrit.LabelCodeReview: {All: []gerrit.Approval{{Value: 2}}},
+			}),
+			history: []changesetStatesAtTime{
+				{t: daysAgo(10), reviewState: btypes.ChangesetReviewStatePending},
+			},
+			want: btypes.ChangesetReviewStateApproved,
+		},
 	}
 
 	for i, tc := range tests {
@@ -715,6 +790,30 @@ func TestComputeExternalState(t *testing.T) {
 			},
 			want: btypes.ChangesetExternalStateDraft,
 		},
+		{
+			name:      "gerrit - no events, new",
+			changeset: gerritChangeset(daysAgo(0), gerrit.ChangeStatusNew, nil),
+			history:   []changesetStatesAtTime{},
+			want:      btypes.ChangesetExternalStateOpen,
+		},
+		{
+			name:      "gerrit - no events, abandoned",
+			changeset: gerritChangeset(daysAgo(0), gerrit.ChangeStatusAbandoned, nil),
+			history:   []changesetStatesAtTime{},
+			want:      btypes.ChangesetExternalStateClosed,
+		},
+		{
+			name:      "gerrit - no events, merged",
+			changeset: gerritChangeset(daysAgo(0), gerrit.ChangeStatusMerged, nil),
+			history:   []changesetStatesAtTime{},
+			want:      btypes.ChangesetExternalStateMerged,
+		},
+		{
+			name:      "gerrit draft - work in progress",
+			changeset: setDraft(gerritChangeset(daysAgo(0), gerrit.ChangeStatusNew, nil)),
+			history:   []changesetStatesAtTime{},
+			want:      btypes.ChangesetExternalStateDraft,
+		},
 	}
 
 	for i, tc := range tests {
@@ -866,6 +965,19 @@ func gitLabChangeset(updatedAt time.Time, state gitlab.MergeRequestState, notes
 	}
 }
 
+func gerritChangeset(updatedAt time.Time, status gerrit.ChangeStatus, labels map[string]gerrit.ChangeLabel) *btypes.Changeset {
+	return &btypes.Changeset{
+		ExternalServiceType: extsvc.TypeGerrit,
+		UpdatedAt:           updatedAt,
+		Metadata: &gerritbatches.AnnotatedChange{
+			Change: &gerrit.Change{
+				Status: status,
+				Labels: labels,
+			},
+		},
+	}
+}
+
 func setDeletedAt(c *btypes.Changeset, deletedAt time.Time) *btypes.Changeset {
 	c.ExternalDeletedAt = deletedAt
 	return c
@@ -3,17 +3,26 @@ package background
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"text/template"
 
+	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
 	"github.com/sourcegraph/sourcegraph/lib/errors"
 )
 
-func sendWebhookNotification(ctx context.Context, url string, args actionArgs) error {
+func sendWebhookNotification(ctx context.Context, url string, tmpl *edb.WebhookTemplate, args actionArgs) error {
+	if tmpl != nil {
+		return sendTemplatedWebhook(ctx, httpcli.ExternalDoer, url, tmpl, generateWebhookPayload(args))
+	}
 	return postWebhook(ctx, httpcli.ExternalDoer, url, generateWebhookPayload(args))
 }
 
@@ -47,15 +56,158 @@ func postWebhook(ctx context.Context, doer httpcli.Doer, url string, payload web
 	return nil
 }
 
-func SendTestWebhook(ctx context.Context, doer httpcli.Doer, description string, u string) error {
+// sendTemplatedWebhook sends the request described by tmpl, rendered over the
+// given payload, to url.
+func sendTemplatedWebhook(ctx context.Context, doer httpcli.Doer, url string, tmpl *edb.WebhookTemplate, payload webhookPayload) error {
+	req, err := newTemplatedWebhookRequest(ctx, url, tmpl, payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := doer.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send webhook")
+	}
+	defer resp.Body.Close()
+
+	// Unlike our own payload, templated requests target arbitrary APIs, many
+	// of which reply with other successful status codes than 200 OK.
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return StatusCodeError{
+			Code:   resp.StatusCode,
+			Status: resp.Status,
+			Body:   string(body),
+		}
+	}
+
+	return nil
+}
+
+func SendTestWebhook(ctx context.Context, doer httpcli.Doer, description string, u string, tmpl *edb.WebhookTemplate) error {
 	args := actionArgs{
 		ExternalURL:        &url.URL{},
 		MonitorDescription: description,
 		Query:              "test query",
 	}
+	if tmpl != nil {
+		return sendTemplatedWebhook(ctx, httpcli.ExternalDoer, u, tmpl, generateWebhookPayload(args))
+	}
 	return postWebhook(ctx, httpcli.ExternalDoer, u, generateWebhookPayload(args))
 }
 
+// webhookMethods are the HTTP methods a webhook template can use.
+var webhookMethods = map[string]struct{}{
+	http.MethodPost:  {},
+	http.MethodPut:   {},
+	http.MethodPatch: {},
+}
+
+// ValidateWebhookTemplate returns an error if the method of the given template
+// is not supported, or if its body or one of its headers is not a valid
+// template.
+func ValidateWebhookTemplate(tmpl *edb.WebhookTemplate) error {
+	_, _, err := parseWebhookTemplate(tmpl)
+	return err
+}
+
+func newTemplatedWebhookRequest(ctx context.Context, url string, tmpl *edb.WebhookTemplate, payload webhookPayload) (*http.Request, error) {
+	body, headers, err := parseWebhookTemplate(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := body.Execute(&buf, payload); err != nil {
+		return nil, errors.Wrap(err, "rendering webhook body")
+	}
+	raw := buf.Bytes()
+
+	req, err := http.NewRequestWithContext(ctx, webhookMethod(tmpl), url, bytes.NewReader(raw))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed new request")
+	}
+
+	// Header values can sign the rendered body, so they are rendered last.
+	data := webhookHeaderData{webhookPayload: payload, Body: string(raw)}
+	for i, h := range headers {
+		var value strings.Builder
+		if err := h.Funcs(template.FuncMap{"signature": signatureFunc(tmpl.Secret, raw)}).Execute(&value, data); err != nil {
+			return nil, errors.Wrapf(err, "rendering webhook header %q", tmpl.Headers[i].Name)
+		}
+		req.Header.Add(tmpl.Headers[i].Name, value.String())
+	}
+
+	return req, nil
+}
+
+// webhookHeaderData is the data header templates are rendered over.
+type webhookHeaderData struct {
+	webhookPayload
+
+	// Body is the rendered request body.
+	Body string
+}
+
+func parseWebhookTemplate(tmpl *edb.WebhookTemplate) (body *template.Template, headers []*template.Template, err error) {
+	if _, ok := webhookMethods[webhookMethod(tmpl)]; !ok {
+		return nil, nil, errors.Errorf("unsupported webhook method %q", tmpl.Method)
+	}
+
+	body, err = template.New("body").Funcs(webhookTemplateFuncs).Option("missingkey=error").Parse(tmpl.Body)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parsing webhook body template")
+	}
+
+	headers = make([]*template.Template, 0, len(tmpl.Headers))
+	for _, h := range tmpl.Headers {
+		if strings.TrimSpace(h.Name) == "" {
+			return nil, nil, errors.New("webhook header name must not be empty")
+		}
+
+		// The signature function is bound to the rendered body when the
+		// header is rendered.
+		t, err := template.New(h.Name).Funcs(webhookTemplateFuncs).Funcs(template.FuncMap{
+			"signature": signatureFunc("", nil),
+		}).Option("missingkey=error").Parse(h.Value)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "parsing webhook header %q template", h.Name)
+		}
+		headers = append(headers, t)
+	}
+
+	return body, headers, nil
+}
+
+func webhookMethod(tmpl *edb.WebhookTemplate) string {
+	if tmpl.Method == "" {
+		return http.MethodPost
+	}
+	return strings.ToUpper(tmpl.Method)
+}
+
+var webhookTemplateFuncs = template.FuncMap{
+	// json encodes a value as JSON, so that it can be embedded in JSON bodies
+	// without worrying about escaping.
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// signatureFunc returns a template function that returns the hex encoded
+// HMAC-SHA256 of body keyed with secret.
+func signatureFunc(secret string, body []byte) func() (string, error) {
+	return func() (string, error) {
+		if secret == "" {
+			return "", errors.New("signature requires the webhook to have a secret")
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil)), nil
+	}
+}
+
 type webhookPayload struct {
 	MonitorDescription string          `json:"monitorDescription"`
 	MonitorURL         string          `json:"monitorURL"`
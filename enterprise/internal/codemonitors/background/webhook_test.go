@@ -2,6 +2,9 @@ package background
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -12,6 +15,7 @@ import (
 	"github.com/hexops/autogold"
 	"github.com/stretchr/testify/require"
 
+	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
 )
 
@@ -69,6 +73,92 @@ func TestWebhook(t *testing.T) {
 	})
 }
 
+func TestTemplatedWebhook(t *testing.T) {
+	eu, err := url.Parse("https://sourcegraph.com")
+	require.NoError(t, err)
+
+	payload := generateWebhookPayload(actionArgs{
+		MonitorDescription: `My "test" monitor`,
+		ExternalURL:        eu,
+		MonitorID:          42,
+		Query:              "repo:camdentest -file:id_rsa.pub BEGIN",
+		Results:            []*result.CommitMatch{&diffResultMock, &commitResultMock},
+		IncludeResults:     true,
+	})
+
+	tmpl := &edb.WebhookTemplate{
+		Method: "put",
+		Headers: []edb.WebhookHeader{
+			{Name: "Authorization", Value: "Token abc"},
+			{Name: "X-Signature", Value: "sha256={{ signature }}"},
+		},
+		Body:   `{"summary": {{ json .MonitorDescription }}, "count": {{ len .Results }}, "link": "{{ .MonitorURL }}"}`,
+		Secret: "s3cr3t",
+	}
+
+	t.Run("renders request", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			mac := hmac.New(sha256.New, []byte("s3cr3t"))
+			mac.Write(b)
+
+			require.Equal(t, http.MethodPut, r.Method)
+			require.Equal(t, "Token abc", r.Header.Get("Authorization"))
+			require.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get("X-Signature"))
+			require.Equal(t, `{"summary": "My \"test\" monitor", "count": 2, "link": "https://sourcegraph.com/code-monitoring/Q29kZU1vbml0b3I6NDI=?utm_source="}`, string(b))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer s.Close()
+
+		err := sendTemplatedWebhook(context.Background(), s.Client(), s.URL, tmpl, payload)
+		require.NoError(t, err)
+	})
+
+	t.Run("error is returned", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer s.Close()
+
+		err := sendTemplatedWebhook(context.Background(), s.Client(), s.URL, tmpl, payload)
+		require.Error(t, err)
+	})
+
+	t.Run("signature without secret", func(t *testing.T) {
+		noSecret := *tmpl
+		noSecret.Secret = ""
+
+		_, err := newTemplatedWebhookRequest(context.Background(), "https://example.com", &noSecret, payload)
+		require.Error(t, err)
+	})
+}
+
+func TestValidateWebhookTemplate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		tmpl  edb.WebhookTemplate
+		valid bool
+	}{
+		"default method": {tmpl: edb.WebhookTemplate{Body: "{{ .Query }}"}, valid: true},
+		"patch":          {tmpl: edb.WebhookTemplate{Method: "PATCH", Body: "{}"}, valid: true},
+		"get":            {tmpl: edb.WebhookTemplate{Method: "GET", Body: "{}"}},
+		"invalid body":   {tmpl: edb.WebhookTemplate{Body: "{{ .Query "}},
+		"unknown func":   {tmpl: edb.WebhookTemplate{Body: "{{ signature }}"}},
+		"invalid header": {tmpl: edb.WebhookTemplate{Body: "{}", Headers: []edb.WebhookHeader{{Name: "X", Value: "{{"}}}},
+		"empty header":   {tmpl: edb.WebhookTemplate{Body: "{}", Headers: []edb.WebhookHeader{{Name: " ", Value: "x"}}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateWebhookTemplate(&tc.tmpl)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
 func TestTriggerTestWebhookAction(t *testing.T) {
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		b, err := io.ReadAll(r.Body)
@@ -79,6 +169,6 @@ func TestTriggerTestWebhookAction(t *testing.T) {
 	defer s.Close()
 
 	client := s.Client()
-	err := SendTestWebhook(context.Background(), client, "My test monitor", s.URL)
+	err := SendTestWebhook(context.Background(), client, "My test monitor", s.URL, nil)
 	require.NoError(t, err)
 }
@@ -324,7 +324,7 @@ func (r *actionRunner) handleWebhook(ctx context.Context, j *edb.ActionJob) erro
 		IncludeResults:     w.IncludeResults,
 	}
 
-	return sendWebhookNotification(ctx, w.URL, args)
+	return sendWebhookNotification(ctx, w.URL, w.Template, args)
 }
 
 func (r *actionRunner) handleSlackWebhook(ctx context.Context, j *edb.ActionJob) error {
@@ -0,0 +1,27 @@
+package compute
+
+// Patch is a change to a single file, represented as a unified diff that can
+// be applied with `git apply`.
+type Patch struct {
+	Value        string `json:"value"`
+	Kind         string `json:"kind"`
+	Repository   string `json:"repository"`
+	RepositoryID int32  `json:"repositoryID"`
+	Commit       string `json:"commit"`
+	Path         string `json:"path"`
+
+	// Edits are the changes that Value applies to the file, in the order
+	// they appear in the file.
+	Edits []Edit `json:"edits"`
+
+	// Definitions are the ranges of the changed file that define the
+	// changed symbol, as reported by the symbols service.
+	Definitions []Range `json:"definitions"`
+}
+
+// Edit replaces the text within a single line of a file. Columns are byte
+// offsets within the line.
+type Edit struct {
+	Range Range  `json:"range"`
+	Value string `json:"value"`
+}
@@ -50,6 +50,14 @@ func TestParse(t *testing.T) {
 	autogold.Want("replace no left hand side",
 		"Command: `Replace in place: () -> (b)`").
 		Equal(t, test("content:replace(->b)"))
+
+	autogold.Want("rename",
+		"Command: `Rename symbol: (NewClient) -> (NewHTTPClient)`").
+		Equal(t, test("content:rename(NewClient -> NewHTTPClient)"))
+
+	autogold.Want("rename invalid symbol",
+		"rename command: \"New.Client\" is not a valid symbol name").
+		Equal(t, test("content:rename(New.Client -> NewHTTPClient)"))
 }
 
 func TestToSearchQuery(t *testing.T) {
@@ -73,4 +81,8 @@ func TestToSearchQuery(t *testing.T) {
 	autogold.Want("allow expressions on search parameters (filters)",
 		"((repo:foo file:bar lang:go OR repo:foo file:bar lang:text) AND colarado)").
 		Equal(t, test("content:replace(colarado -> colorodo) repo:foo file:bar (lang:go or lang:text)"))
+
+	autogold.Want("convert rename to search query",
+		`(repo:foo AND \bNewClient\b)`).
+		Equal(t, test("content:rename(NewClient -> NewHTTPClient) repo:foo"))
 }
@@ -0,0 +1,328 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/grafana/regexp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/symbols"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// Rename renames every definition and reference of a symbol. Definitions are
+// located with the symbols service. A whole-word occurrence of the symbol name
+// is only renamed if it is one of those definitions, or if the symbols service
+// resolves it to a definition of the symbol in any repository, so references
+// to a symbol defined in another repository are renamed too. Occurrences in
+// strings, comments and of unrelated symbols with the same name are left
+// unchanged. Each changed file produces a Patch.
+type Rename struct {
+	Symbol      string
+	Replacement string
+
+	// definitions caches the definitions of Symbol per repository revision,
+	// so that the symbols service is searched once per revision rather than
+	// once per file.
+	mu          sync.Mutex
+	definitions map[revision]*definitions
+}
+
+type revision struct {
+	repo   api.RepoName
+	commit api.CommitID
+}
+
+// definitions are the definitions of the symbol in a repository revision.
+// They are searched by the first caller that asks for them, with its own
+// context. Failed searches are not cached, so that they are retried by the
+// next caller.
+type definitions struct {
+	mu      sync.Mutex
+	done    bool
+	symbols result.Symbols
+}
+
+// searchSymbols and symbolInfo are the functions used to look up symbol
+// definitions and to resolve occurrences to their definition. They are
+// replaced in tests.
+var (
+	searchSymbols = symbols.DefaultClient.Search
+	symbolInfo    = symbols.DefaultClient.SymbolInfo
+)
+
+// diffContextLines is the number of unchanged lines around each change in a
+// generated patch.
+const diffContextLines = 3
+
+// resolveConcurrency is the number of occurrences in a file that are resolved
+// concurrently.
+const resolveConcurrency = 8
+
+var identifierPattern = regexp.MustCompile(`^\w+$`)
+
+func (c *Rename) ToSearchPattern() string {
+	return `\b` + regexp.QuoteMeta(c.Symbol) + `\b`
+}
+
+func (c *Rename) String() string {
+	return fmt.Sprintf("Rename symbol: (%s) -> (%s)", c.Symbol, c.Replacement)
+}
+
+func (c *Rename) Run(ctx context.Context, db database.DB, r result.Match) (Result, error) {
+	m, ok := r.(*result.FileMatch)
+	if !ok {
+		return nil, nil
+	}
+
+	defs, err := c.findDefinitions(ctx, m.Repo.Name, m.CommitID)
+	if err != nil {
+		return nil, errors.Wrap(err, "rename command: searching symbols")
+	}
+
+	content, err := gitserver.NewClient(db).ReadFile(ctx, m.Repo.Name, m.CommitID, m.Path, authz.DefaultSubRepoPermsChecker)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := findOccurrences(string(content), c.Symbol)
+	renamed := make([]bool, len(occurrences))
+	g := goroutine.NewBounded(resolveConcurrency)
+	for i, occurrence := range occurrences {
+		i, occurrence := i, occurrence
+		g.Go(func() error {
+			ok, err := c.resolvesTo(ctx, m, occurrence, defs)
+			if err != nil {
+				// An occurrence that can't be resolved is left unchanged,
+				// rather than failing the rename of the whole file.
+				return nil
+			}
+			renamed[i] = ok
+			return nil
+		})
+	}
+	g.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Wrap(err, "rename command: resolving symbol")
+	}
+
+	var edits []Edit
+	for i, occurrence := range occurrences {
+		if renamed[i] {
+			edits = append(edits, Edit{Range: occurrence, Value: c.Replacement})
+		}
+	}
+
+	patch := rename(m.Path, string(content), edits)
+	if patch == nil {
+		return nil, nil
+	}
+	patch.Repository = string(m.Repo.Name)
+	patch.RepositoryID = int32(m.Repo.ID)
+	patch.Commit = string(m.CommitID)
+	patch.Path = m.Path
+	for _, s := range defs {
+		if s.Path == m.Path {
+			patch.Definitions = append(patch.Definitions, newRange(s.Line, s.Line, s.Character, s.Character+len(s.Name)))
+		}
+	}
+	return patch, nil
+}
+
+// findDefinitions returns the definitions of the symbol in the given
+// repository revision. Concurrent and repeated calls for the same revision
+// share a single successful search.
+func (c *Rename) findDefinitions(ctx context.Context, repo api.RepoName, commit api.CommitID) (result.Symbols, error) {
+	c.mu.Lock()
+	if c.definitions == nil {
+		c.definitions = map[revision]*definitions{}
+	}
+	key := revision{repo: repo, commit: commit}
+	d, ok := c.definitions[key]
+	if !ok {
+		d = &definitions{}
+		c.definitions[key] = d
+	}
+	c.mu.Unlock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.done {
+		return d.symbols, nil
+	}
+
+	symbols, err := searchSymbols(ctx, search.SymbolsParameters{
+		Repo:            repo,
+		CommitID:        commit,
+		Query:           "^" + regexp.QuoteMeta(c.Symbol) + "$",
+		IsRegExp:        true,
+		IsCaseSensitive: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.symbols, d.done = symbols, true
+	return d.symbols, nil
+}
+
+// resolvesTo reports whether the occurrence of the symbol at the given range
+// of the file is one of defs, the definitions in the file's repository, or a
+// reference that the symbols service resolves to a definition of the symbol in
+// any repository.
+func (c *Rename) resolvesTo(ctx context.Context, m *result.FileMatch, occurrence Range, defs result.Symbols) (bool, error) {
+	if isDefinition(defs, m.Path, occurrence.Start.Line, occurrence.Start.Column) {
+		return true, nil
+	}
+
+	info, err := symbolInfo(ctx, types.RepoCommitPathPoint{
+		RepoCommitPath: types.RepoCommitPath{
+			Repo:   string(m.Repo.Name),
+			Commit: string(m.CommitID),
+			Path:   m.Path,
+		},
+		Point: types.Point{
+			Row:    occurrence.Start.Line,
+			Column: occurrence.Start.Column,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	if info == nil || info.Definition.Range == nil {
+		// Strings, comments and names the symbols service can't resolve.
+		return false, nil
+	}
+
+	defRepo, defCommit := api.RepoName(info.Definition.Repo), api.CommitID(info.Definition.Commit)
+	if defRepo != m.Repo.Name || defCommit != m.CommitID {
+		// The reference resolves to a definition in another repository
+		// revision, whose definitions of the symbol are searched too.
+		defs, err = c.findDefinitions(ctx, defRepo, defCommit)
+		if err != nil {
+			return false, err
+		}
+	}
+	return isDefinition(defs, info.Definition.Path, info.Definition.Row, info.Definition.Column), nil
+}
+
+func isDefinition(defs result.Symbols, path string, line, column int) bool {
+	for _, s := range defs {
+		if s.Path == path && s.Line == line && s.Character == column {
+			return true
+		}
+	}
+	return false
+}
+
+// findOccurrences returns the ranges of the whole-word occurrences of symbol
+// in content.
+func findOccurrences(content, symbol string) []Range {
+	symbolRegexp := regexp.MustCompile(`\b` + regexp.QuoteMeta(symbol) + `\b`)
+
+	var occurrences []Range
+	for i, line := range splitLines(content) {
+		for _, loc := range symbolRegexp.FindAllStringIndex(line, -1) {
+			occurrences = append(occurrences, newRange(i, i, loc[0], loc[1]))
+		}
+	}
+	return occurrences
+}
+
+// rename applies edits to content and returns the change as a Patch, or nil
+// if there are no edits. Edits must be sorted and not overlap.
+func rename(path, content string, edits []Edit) *Patch {
+	if len(edits) == 0 {
+		return nil
+	}
+
+	lines := splitLines(content)
+	renamed := make([]string, len(lines))
+	copy(renamed, lines)
+
+	var changed []int
+	// Apply the edits of each line from last to first so that the columns
+	// of earlier edits remain valid.
+	for i := len(edits) - 1; i >= 0; i-- {
+		e := edits[i]
+		l := e.Range.Start.Line
+		renamed[l] = renamed[l][:e.Range.Start.Column] + e.Value + renamed[l][e.Range.End.Column:]
+		if len(changed) == 0 || changed[0] != l {
+			changed = append([]int{l}, changed...)
+		}
+	}
+
+	return &Patch{
+		Value: unifiedDiff(path, lines, renamed, changed),
+		Kind:  "rename",
+		Edits: edits,
+	}
+}
+
+// splitLines splits content into lines that keep their trailing newline.
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// unifiedDiff returns a git-style unified diff between lines and newLines,
+// which differ only at the (sorted) line indices in changed.
+func unifiedDiff(path string, lines, newLines []string, changed []int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n", path, path)
+	fmt.Fprintf(&b, "--- a/%s\n", path)
+	fmt.Fprintf(&b, "+++ b/%s\n", path)
+
+	writeLine := func(prefix, line string) {
+		b.WriteString(prefix)
+		b.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+
+	for i := 0; i < len(changed); {
+		// Extend the hunk for as long as the context of the next change
+		// overlaps with the context of the previous one.
+		j := i
+		for j+1 < len(changed) && changed[j+1]-changed[j] <= 2*diffContextLines {
+			j++
+		}
+
+		start := changed[i] - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := changed[j] + diffContextLines + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", start+1, end-start, start+1, end-start)
+		k := i
+		for l := start; l < end; l++ {
+			if k <= j && changed[k] == l {
+				writeLine("-", lines[l])
+				writeLine("+", newLines[l])
+				k++
+				continue
+			}
+			writeLine(" ", lines[l])
+		}
+
+		i = j + 1
+	}
+	return b.String()
+}
@@ -0,0 +1,246 @@
+package compute
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hexops/autogold"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+func Test_rename(t *testing.T) {
+	test := func(content string) string {
+		var edits []Edit
+		for _, occurrence := range findOccurrences(content, "NewClient") {
+			edits = append(edits, Edit{Range: occurrence, Value: "NewHTTPClient"})
+		}
+		patch := rename("client.go", content, edits)
+		if patch == nil {
+			return "<no patch>"
+		}
+		return patch.Value
+	}
+
+	autogold.Want("no references", "<no patch>").
+		Equal(t, test("func NewClientFactory() {}\n"))
+
+	autogold.Want("single hunk", `diff --git a/client.go b/client.go
+--- a/client.go
++++ b/client.go
+@@ -1,3 +1,3 @@
+ package client
+-func NewClient() *Client { return &Client{} }
++func NewHTTPClient() *Client { return &Client{} }
+ var c = NewClientFactory()
+`).Equal(t, test("package client\nfunc NewClient() *Client { return &Client{} }\nvar c = NewClientFactory()\n"))
+
+	autogold.Want("separate hunks", `diff --git a/client.go b/client.go
+--- a/client.go
++++ b/client.go
+@@ -1,4 +1,4 @@
+-NewClient
++NewHTTPClient
+ 1
+ 2
+ 3
+@@ -8,4 +8,4 @@
+ 7
+ 8
+ 9
+-x := NewClient(NewClient())
++x := NewHTTPClient(NewHTTPClient())
+`).Equal(t, test("NewClient\n1\n2\n3\n4\n5\n6\n7\n8\n9\nx := NewClient(NewClient())\n"))
+
+	autogold.Want("no newline at end of file", `diff --git a/client.go b/client.go
+--- a/client.go
++++ b/client.go
+@@ -1,2 +1,2 @@
+ package client
+-var c = NewClient()
+\ No newline at end of file
++var c = NewHTTPClient()
+\ No newline at end of file
+`).Equal(t, test("package client\nvar c = NewClient()"))
+}
+
+func TestRename_Run(t *testing.T) {
+	gitserver.Mocks.ReadFile = func(_ api.CommitID, _ string) ([]byte, error) {
+		return []byte(`package client
+
+// NewClient returns a client.
+func NewClient() {}
+
+var name = "NewClient"
+
+func main() {
+	NewClient()
+	NewClient := 1
+	_ = NewClient
+}
+`), nil
+	}
+	t.Cleanup(gitserver.ResetMocks)
+
+	oldSearchSymbols, oldSymbolInfo := searchSymbols, symbolInfo
+	t.Cleanup(func() { searchSymbols, symbolInfo = oldSearchSymbols, oldSymbolInfo })
+
+	searches := 0
+	searchSymbols = func(_ context.Context, args search.SymbolsParameters) (result.Symbols, error) {
+		searches++
+		if args.Query != "^NewClient$" || len(args.IncludePatterns) != 0 {
+			t.Fatalf("unexpected symbols query %+v", args)
+		}
+		return result.Symbols{{Name: "NewClient", Path: "client.go", Line: 3, Character: 5}}, nil
+	}
+	symbolInfo = func(_ context.Context, args types.RepoCommitPathPoint) (*types.SymbolInfo, error) {
+		def := types.RepoCommitPathMaybeRange{RepoCommitPath: args.RepoCommitPath}
+		switch args.Point {
+		case types.Point{Row: 9, Column: 1}:
+			// The call resolves to the function.
+			def.Range = &types.Range{Row: 3, Column: 5, Length: 9}
+		case types.Point{Row: 10, Column: 1}, types.Point{Row: 11, Column: 5}:
+			// The local variable shadows the function.
+			def.Range = &types.Range{Row: 10, Column: 1, Length: 9}
+		default:
+			// Comments and strings have no definition.
+			return nil, nil
+		}
+		return &types.SymbolInfo{Definition: def}, nil
+	}
+
+	match := &result.FileMatch{File: result.File{
+		Repo:     types.MinimalRepo{ID: 5, Name: "codehost.com/myorg/myrepo"},
+		CommitID: "deadbeef",
+		Path:     "client.go",
+	}}
+
+	cmd := &Rename{Symbol: "NewClient", Replacement: "NewHTTPClient"}
+	for i := 0; i < 2; i++ {
+		got, err := cmd.Run(context.Background(), database.NewMockDB(), match)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		patch := got.(*Patch)
+		autogold.Want("rename patch", Patch{
+			Value: `diff --git a/client.go b/client.go
+--- a/client.go
++++ b/client.go
+@@ -1,13 +1,13 @@
+ package client
+ 
+ // NewClient returns a client.
+-func NewClient() {}
++func NewHTTPClient() {}
+ 
+ var name = "NewClient"
+ 
+ func main() {
+-	NewClient()
++	NewHTTPClient()
+ 	NewClient := 1
+ 	_ = NewClient
+ }
+`,
+			Kind:         "rename",
+			Repository:   "codehost.com/myorg/myrepo",
+			RepositoryID: 5,
+			Commit:       "deadbeef",
+			Path:         "client.go",
+			Edits: []Edit{
+				{
+					Range: Range{
+						Start: Location{Offset: -1, Line: 3, Column: 5},
+						End:   Location{Offset: -1, Line: 3, Column: 14},
+					},
+					Value: "NewHTTPClient",
+				},
+				{
+					Range: Range{
+						Start: Location{Offset: -1, Line: 9, Column: 1},
+						End:   Location{Offset: -1, Line: 9, Column: 10},
+					},
+					Value: "NewHTTPClient",
+				},
+			},
+			Definitions: []Range{{
+				Start: Location{Offset: -1, Line: 3, Column: 5},
+				End:   Location{Offset: -1, Line: 3, Column: 14},
+			}},
+		}).Equal(t, *patch)
+	}
+
+	if searches != 1 {
+		t.Fatalf("expected definitions to be searched once per revision, got %d searches", searches)
+	}
+}
+
+func TestRename_RunCrossRepository(t *testing.T) {
+	gitserver.Mocks.ReadFile = func(_ api.CommitID, _ string) ([]byte, error) {
+		return []byte(`package main
+
+import "codehost.com/myorg/client"
+
+func main() {
+	client.NewClient()
+	client.NewClient()
+}
+`), nil
+	}
+	t.Cleanup(gitserver.ResetMocks)
+
+	oldSearchSymbols, oldSymbolInfo := searchSymbols, symbolInfo
+	t.Cleanup(func() { searchSymbols, symbolInfo = oldSearchSymbols, oldSymbolInfo })
+
+	searchSymbols = func(_ context.Context, args search.SymbolsParameters) (result.Symbols, error) {
+		if args.Repo == "codehost.com/myorg/client" && args.CommitID == "cafebabe" {
+			return result.Symbols{{Name: "NewClient", Path: "client.go", Line: 3, Character: 5}}, nil
+		}
+		// The symbol isn't defined in the repository of the match.
+		return nil, nil
+	}
+	symbolInfo = func(_ context.Context, args types.RepoCommitPathPoint) (*types.SymbolInfo, error) {
+		switch args.Point {
+		case types.Point{Row: 5, Column: 8}:
+			return &types.SymbolInfo{Definition: types.RepoCommitPathMaybeRange{
+				RepoCommitPath: types.RepoCommitPath{Repo: "codehost.com/myorg/client", Commit: "cafebabe", Path: "client.go"},
+				Range:          &types.Range{Row: 3, Column: 5, Length: 9},
+			}}, nil
+		default:
+			// A single occurrence that can't be resolved doesn't fail the
+			// rename of the file.
+			return nil, errors.New("symbols service unavailable")
+		}
+	}
+
+	match := &result.FileMatch{File: result.File{
+		Repo:     types.MinimalRepo{ID: 6, Name: "codehost.com/myorg/app"},
+		CommitID: "deadbeef",
+		Path:     "main.go",
+	}}
+
+	cmd := &Rename{Symbol: "NewClient", Replacement: "NewHTTPClient"}
+	got, err := cmd.Run(context.Background(), database.NewMockDB(), match)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patch := got.(*Patch)
+	autogold.Want("cross-repository rename edits", []Edit{{
+		Range: Range{
+			Start: Location{Offset: -1, Line: 5, Column: 8},
+			End:   Location{Offset: -1, Line: 5, Column: 17},
+		},
+		Value: "NewHTTPClient",
+	}}).Equal(t, patch.Edits)
+	if len(patch.Definitions) != 0 {
+		t.Fatalf("expected no definitions in the file, got %v", patch.Definitions)
+	}
+}
@@ -7,7 +7,9 @@ type Result interface {
 var (
 	_ Result = (*MatchContext)(nil)
 	_ Result = (*Text)(nil)
+	_ Result = (*Patch)(nil)
 )
 
 func (*MatchContext) result() {}
 func (*Text) result()         {}
+func (*Patch) result()        {}
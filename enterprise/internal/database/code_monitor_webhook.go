@@ -3,12 +3,17 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/keegancsmith/sqlf"
 
 	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/encryption"
+	"github.com/sourcegraph/sourcegraph/internal/encryption/keyring"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
 )
 
 type WebhookAction struct {
@@ -18,17 +23,48 @@ type WebhookAction struct {
 	URL            string
 	IncludeResults bool
 
+	// Template customizes the request sent to URL. If nil, the default JSON
+	// payload is posted.
+	Template *WebhookTemplate
+
 	CreatedBy int32
 	CreatedAt time.Time
 	ChangedBy int32
 	ChangedAt time.Time
 }
 
+// WebhookTemplate describes the HTTP request sent by a webhook action. The
+// header values and the body are text/template templates rendered over the
+// code monitor event.
+type WebhookTemplate struct {
+	Method  string
+	Headers []WebhookHeader
+	Body    string
+
+	// Secret is the key used to sign the rendered body in header templates.
+	// When updating a webhook action, an empty secret leaves the stored secret
+	// unchanged.
+	Secret string
+}
+
+type WebhookHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// updateWebhookActionQuery leaves the template columns unchanged when they
+// are null, so that updates which omit the template or the secret don't erase
+// them.
 const updateWebhookActionQuery = `
 UPDATE cm_webhooks
 SET enabled = %s,
     include_results = %s,
 	url = %s,
+	method = COALESCE(%s, method),
+	headers = COALESCE(%s, headers),
+	body_template = COALESCE(%s, body_template),
+	secret = COALESCE(%s, secret),
+	secret_key_id = COALESCE(%s, secret_key_id),
 	changed_by = %s,
 	changed_at = %s
 WHERE
@@ -41,13 +77,23 @@ WHERE
 RETURNING %s;
 `
 
-func (s *codeMonitorStore) UpdateWebhookAction(ctx context.Context, id int64, enabled, includeResults bool, url string) (*WebhookAction, error) {
+func (s *codeMonitorStore) UpdateWebhookAction(ctx context.Context, id int64, enabled, includeResults bool, url string, tmpl *WebhookTemplate) (*WebhookAction, error) {
+	method, headers, body, secret, keyID, err := s.webhookTemplateColumns(ctx, tmpl)
+	if err != nil {
+		return nil, err
+	}
+
 	a := actor.FromContext(ctx)
 	q := sqlf.Sprintf(
 		updateWebhookActionQuery,
 		enabled,
 		includeResults,
 		url,
+		method,
+		headers,
+		body,
+		secret,
+		keyID,
 		a.UID,
 		s.Now(),
 		id,
@@ -56,17 +102,22 @@ func (s *codeMonitorStore) UpdateWebhookAction(ctx context.Context, id int64, en
 	)
 
 	row := s.QueryRow(ctx, q)
-	return scanWebhookAction(row)
+	return s.scanWebhookAction(ctx, row)
 }
 
 const createWebhookActionQuery = `
 INSERT INTO cm_webhooks
-(monitor, enabled, include_results, url, created_by, created_at, changed_by, changed_at)
-VALUES (%s,%s,%s,%s,%s,%s,%s,%s)
+(monitor, enabled, include_results, url, method, headers, body_template, secret, secret_key_id, created_by, created_at, changed_by, changed_at)
+VALUES (%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s)
 RETURNING %s;
 `
 
-func (s *codeMonitorStore) CreateWebhookAction(ctx context.Context, monitorID int64, enabled, includeResults bool, url string) (*WebhookAction, error) {
+func (s *codeMonitorStore) CreateWebhookAction(ctx context.Context, monitorID int64, enabled, includeResults bool, url string, tmpl *WebhookTemplate) (*WebhookAction, error) {
+	method, headers, body, secret, keyID, err := s.webhookTemplateColumns(ctx, tmpl)
+	if err != nil {
+		return nil, err
+	}
+
 	now := s.Now()
 	a := actor.FromContext(ctx)
 	q := sqlf.Sprintf(
@@ -75,6 +126,11 @@ func (s *codeMonitorStore) CreateWebhookAction(ctx context.Context, monitorID in
 		enabled,
 		includeResults,
 		url,
+		method,
+		headers,
+		body,
+		secret,
+		keyID,
 		a.UID,
 		now,
 		a.UID,
@@ -83,7 +139,7 @@ func (s *codeMonitorStore) CreateWebhookAction(ctx context.Context, monitorID in
 	)
 
 	row := s.QueryRow(ctx, q)
-	return scanWebhookAction(row)
+	return s.scanWebhookAction(ctx, row)
 }
 
 const deleteWebhookActionQuery = `
@@ -135,7 +191,7 @@ func (s *codeMonitorStore) GetWebhookAction(ctx context.Context, webhookID int64
 		webhookID,
 	)
 	row := s.QueryRow(ctx, q)
-	return scanWebhookAction(row)
+	return s.scanWebhookAction(ctx, row)
 }
 
 const listWebhookActionsQuery = `
@@ -158,7 +214,7 @@ func (s *codeMonitorStore) ListWebhookActions(ctx context.Context, opts ListActi
 		return nil, err
 	}
 	defer rows.Close()
-	return scanWebhookActions(rows)
+	return s.scanWebhookActions(ctx, rows)
 }
 
 // webhookActionColumns is the set of columns in the cm_webhooks table
@@ -169,16 +225,21 @@ var webhookActionColumns = []*sqlf.Query{
 	sqlf.Sprintf("cm_webhooks.enabled"),
 	sqlf.Sprintf("cm_webhooks.url"),
 	sqlf.Sprintf("cm_webhooks.include_results"),
+	sqlf.Sprintf("cm_webhooks.method"),
+	sqlf.Sprintf("cm_webhooks.headers"),
+	sqlf.Sprintf("cm_webhooks.body_template"),
+	sqlf.Sprintf("cm_webhooks.secret"),
+	sqlf.Sprintf("cm_webhooks.secret_key_id"),
 	sqlf.Sprintf("cm_webhooks.created_by"),
 	sqlf.Sprintf("cm_webhooks.created_at"),
 	sqlf.Sprintf("cm_webhooks.changed_by"),
 	sqlf.Sprintf("cm_webhooks.changed_at"),
 }
 
-func scanWebhookActions(rows *sql.Rows) ([]*WebhookAction, error) {
+func (s *codeMonitorStore) scanWebhookActions(ctx context.Context, rows *sql.Rows) ([]*WebhookAction, error) {
 	var ws []*WebhookAction
 	for rows.Next() {
-		w, err := scanWebhookAction(rows)
+		w, err := s.scanWebhookAction(ctx, rows)
 		if err != nil {
 			return nil, err
 		}
@@ -187,20 +248,83 @@ func scanWebhookActions(rows *sql.Rows) ([]*WebhookAction, error) {
 	return ws, rows.Err()
 }
 
-// scanWebhookAction scans a WebhookAction from a *sql.Row or *sql.Rows.
-// It must be kept in sync with webhookActionColumns.
-func scanWebhookAction(scanner dbutil.Scanner) (*WebhookAction, error) {
-	var w WebhookAction
+// scanWebhookAction scans a WebhookAction from a *sql.Row or *sql.Rows and
+// decrypts its secret. It must be kept in sync with webhookActionColumns.
+func (s *codeMonitorStore) scanWebhookAction(ctx context.Context, scanner dbutil.Scanner) (*WebhookAction, error) {
+	var (
+		w                           WebhookAction
+		method, body, secret, keyID sql.NullString
+		headers                     []byte
+	)
 	err := scanner.Scan(
 		&w.ID,
 		&w.Monitor,
 		&w.Enabled,
 		&w.URL,
 		&w.IncludeResults,
+		&method,
+		&headers,
+		&body,
+		&secret,
+		&keyID,
 		&w.CreatedBy,
 		&w.CreatedAt,
 		&w.ChangedBy,
 		&w.ChangedAt,
 	)
-	return &w, err
+	if err != nil {
+		return &w, err
+	}
+
+	if body.Valid {
+		decrypted, err := database.MaybeDecrypt(ctx, s.webhookSecretKey(), secret.String, keyID.String)
+		if err != nil {
+			return &w, errors.Wrap(err, "decrypting webhook secret")
+		}
+		w.Template = &WebhookTemplate{
+			Method: method.String,
+			Body:   body.String,
+			Secret: decrypted,
+		}
+		if len(headers) > 0 {
+			if err := json.Unmarshal(headers, &w.Template.Headers); err != nil {
+				return &w, errors.Wrap(err, "unmarshaling webhook headers")
+			}
+		}
+	}
+
+	return &w, nil
+}
+
+// webhookTemplateColumns returns the values of the template columns of
+// cm_webhooks for the given template with the secret encrypted. The values
+// are null if the template is nil, and the secret and its key ID are null if
+// the secret is empty.
+func (s *codeMonitorStore) webhookTemplateColumns(ctx context.Context, tmpl *WebhookTemplate) (method, headers, body, secret, keyID any, err error) {
+	if tmpl == nil {
+		return nil, nil, nil, nil, nil, nil
+	}
+
+	rawHeaders, err := json.Marshal(tmpl.Headers)
+	if err != nil {
+		return nil, nil, nil, nil, nil, errors.Wrap(err, "marshaling webhook headers")
+	}
+
+	if tmpl.Secret == "" {
+		return tmpl.Method, rawHeaders, tmpl.Body, nil, nil, nil
+	}
+	encrypted, encryptionKeyID, err := database.MaybeEncrypt(ctx, s.webhookSecretKey(), tmpl.Secret)
+	if err != nil {
+		return nil, nil, nil, nil, nil, errors.Wrap(err, "encrypting webhook secret")
+	}
+	return tmpl.Method, rawHeaders, tmpl.Body, encrypted, encryptionKeyID, nil
+}
+
+// webhookSecretKey returns the key used to encrypt the secrets of webhook
+// actions, which may be nil if encryption is not configured.
+func (s *codeMonitorStore) webhookSecretKey() encryption.Key {
+	if s.key != nil {
+		return s.key
+	}
+	return keyring.Default().CodeMonitorWebhookKey
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/keegancsmith/sqlf"
 	"github.com/stretchr/testify/require"
 
 	"github.com/sourcegraph/log/logtest"
@@ -11,6 +12,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	et "github.com/sourcegraph/sourcegraph/internal/encryption/testing"
 )
 
 func TestCodeMonitorStoreWebhooks(t *testing.T) {
@@ -28,7 +30,7 @@ func TestCodeMonitorStoreWebhooks(t *testing.T) {
 		s := CodeMonitors(db)
 		fixtures := s.insertTestMonitor(ctx, t)
 
-		action, err := s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1)
+		action, err := s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1, nil)
 		require.NoError(t, err)
 
 		got, err := s.GetWebhookAction(ctx, action.ID)
@@ -45,10 +47,10 @@ func TestCodeMonitorStoreWebhooks(t *testing.T) {
 		s := CodeMonitors(db)
 		fixtures := s.insertTestMonitor(ctx, t)
 
-		action, err := s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1)
+		action, err := s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1, nil)
 		require.NoError(t, err)
 
-		updated, err := s.UpdateWebhookAction(ctx, action.ID, false, false, url2)
+		updated, err := s.UpdateWebhookAction(ctx, action.ID, false, false, url2, nil)
 		require.NoError(t, err)
 		require.Equal(t, false, updated.Enabled)
 		require.Equal(t, url2, updated.URL)
@@ -58,6 +60,65 @@ func TestCodeMonitorStoreWebhooks(t *testing.T) {
 		require.Equal(t, updated, got)
 	})
 
+	t.Run("CreateUpdateTemplate", func(t *testing.T) {
+		t.Parallel()
+
+		db := database.NewDB(logger, dbtest.NewDB(logger, t))
+		_, _, ctx := newTestUser(ctx, t, db)
+		s := CodeMonitors(db)
+		fixtures := s.insertTestMonitor(ctx, t)
+
+		tmpl := &WebhookTemplate{
+			Method:  "PUT",
+			Headers: []WebhookHeader{{Name: "X-Signature", Value: "{{ signature }}"}},
+			Body:    `{"summary": {{ json .MonitorDescription }}}`,
+			Secret:  "s3cr3t",
+		}
+		action, err := s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1, tmpl)
+		require.NoError(t, err)
+		require.Equal(t, tmpl, action.Template)
+
+		got, err := s.GetWebhookAction(ctx, action.ID)
+		require.NoError(t, err)
+		require.Equal(t, action, got)
+
+		// Omitting the template leaves it unchanged
+		updated, err := s.UpdateWebhookAction(ctx, action.ID, false, false, url2, nil)
+		require.NoError(t, err)
+		require.Equal(t, tmpl, updated.Template)
+
+		// Omitting the secret leaves it unchanged
+		newTmpl := &WebhookTemplate{Method: "POST", Body: "{{ .Query }}"}
+		updated, err = s.UpdateWebhookAction(ctx, action.ID, false, false, url2, newTmpl)
+		require.NoError(t, err)
+		require.Equal(t, &WebhookTemplate{Method: "POST", Headers: nil, Body: "{{ .Query }}", Secret: "s3cr3t"}, updated.Template)
+	})
+
+	t.Run("EncryptSecret", func(t *testing.T) {
+		t.Parallel()
+
+		db := database.NewDB(logger, dbtest.NewDB(logger, t))
+		_, _, ctx := newTestUser(ctx, t, db)
+		s := CodeMonitors(db)
+		s.key = et.TestKey{}
+		fixtures := s.insertTestMonitor(ctx, t)
+
+		tmpl := &WebhookTemplate{Method: "POST", Body: "{{ .Query }}", Secret: "s3cr3t"}
+		action, err := s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1, tmpl)
+		require.NoError(t, err)
+		require.Equal(t, "s3cr3t", action.Template.Secret)
+
+		var stored, keyID string
+		err = s.QueryRow(ctx, sqlf.Sprintf("SELECT secret, secret_key_id FROM cm_webhooks WHERE id = %s", action.ID)).Scan(&stored, &keyID)
+		require.NoError(t, err)
+		require.NotEqual(t, "s3cr3t", stored)
+		require.NotEmpty(t, keyID)
+
+		got, err := s.GetWebhookAction(ctx, action.ID)
+		require.NoError(t, err)
+		require.Equal(t, "s3cr3t", got.Template.Secret)
+	})
+
 	t.Run("ErrorOnUpdateNonexistent", func(t *testing.T) {
 		t.Parallel()
 
@@ -65,7 +126,7 @@ func TestCodeMonitorStoreWebhooks(t *testing.T) {
 		_, _, ctx := newTestUser(ctx, t, db)
 		s := CodeMonitors(db)
 
-		_, err := s.UpdateWebhookAction(ctx, 383838, false, false, url2)
+		_, err := s.UpdateWebhookAction(ctx, 383838, false, false, url2, nil)
 		require.Error(t, err)
 	})
 
@@ -77,10 +138,10 @@ func TestCodeMonitorStoreWebhooks(t *testing.T) {
 		s := CodeMonitors(db)
 		fixtures := s.insertTestMonitor(ctx, t)
 
-		action1, err := s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1)
+		action1, err := s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1, nil)
 		require.NoError(t, err)
 
-		action2, err := s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1)
+		action2, err := s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1, nil)
 		require.NoError(t, err)
 
 		err = s.DeleteWebhookActions(ctx, fixtures.monitor.ID, action1.ID)
@@ -105,7 +166,7 @@ func TestCodeMonitorStoreWebhooks(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 0, count)
 
-		_, err = s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1)
+		_, err = s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1, nil)
 		require.NoError(t, err)
 
 		count, err = s.CountWebhookActions(ctx, fixtures.monitor.ID)
@@ -125,10 +186,10 @@ func TestCodeMonitorStoreWebhooks(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, actions, 0)
 
-		_, err = s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1)
+		_, err = s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url1, nil)
 		require.NoError(t, err)
 
-		_, err = s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url2)
+		_, err = s.CreateWebhookAction(ctx, fixtures.monitor.ID, true, false, url2, nil)
 		require.NoError(t, err)
 
 		actions2, err := s.ListWebhookActions(ctx, ListActionsOpts{MonitorID: &fixtures.monitor.ID})
@@ -149,15 +210,15 @@ func TestCodeMonitorStoreWebhooks(t *testing.T) {
 		ctx2 := actor.WithActor(ctx, actor.FromUser(uid2))
 		fixtures := s.insertTestMonitor(ctx1, t)
 
-		wa, err := s.CreateWebhookAction(ctx1, fixtures.monitor.ID, true, true, "https://true.com")
+		wa, err := s.CreateWebhookAction(ctx1, fixtures.monitor.ID, true, true, "https://true.com", nil)
 		require.NoError(t, err)
 
 		// User1 can update it
-		_, err = s.UpdateWebhookAction(ctx1, wa.ID, true, true, "https://false.com")
+		_, err = s.UpdateWebhookAction(ctx1, wa.ID, true, true, "https://false.com", nil)
 		require.NoError(t, err)
 
 		// User2 cannot update it
-		_, err = s.UpdateWebhookAction(ctx2, wa.ID, true, true, "https://truer.com")
+		_, err = s.UpdateWebhookAction(ctx2, wa.ID, true, true, "https://truer.com", nil)
 		require.Error(t, err)
 
 		wa, err = s.GetWebhookAction(ctx1, wa.ID)
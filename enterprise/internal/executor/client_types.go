@@ -8,6 +8,9 @@ type Job struct {
 	// that different queues can share identifiers.
 	ID int `json:"id"`
 
+	// Queue is the name of the queue from which the job was dequeued.
+	Queue string `json:"queue"`
+
 	// RepositoryName is the name of the repository to be cloned into the
 	// workspace prior to job execution.
 	RepositoryName string `json:"repositoryName"`
@@ -113,6 +116,10 @@ type HeartbeatRequest struct {
 	ExecutorName string `json:"executorName"`
 	JobIDs       []int  `json:"jobIds"`
 
+	// QueueNames are the names of all queues the executor processes jobs from. An
+	// executor that processes several queues sends a heartbeat to each of them.
+	QueueNames []string `json:"queueNames,omitempty"`
+
 	// Telemetry data.
 
 	OS              string `json:"os"`
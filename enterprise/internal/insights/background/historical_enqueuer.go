@@ -378,7 +378,15 @@ func (h *historicalEnqueuer) Handler(ctx context.Context) error {
 	for _, series := range foundInsights {
 		log15.Info("Loaded insight data series for historical processing", "series_id", series.SeriesID)
 	}
-	if err := h.buildFrames(ctx, foundInsights); err != nil {
+	// Repository metadata is not versioned, so series over it have no history to backfill and
+	// are marked complete right away.
+	searchInsights := make([]itypes.InsightSeries, 0, len(foundInsights))
+	for _, series := range foundInsights {
+		if series.GenerationMethod != itypes.RepoMetadata {
+			searchInsights = append(searchInsights, series)
+		}
+	}
+	if err := h.buildFrames(ctx, searchInsights); err != nil {
 		multi = errors.Append(multi, err)
 	}
 	if err == nil {
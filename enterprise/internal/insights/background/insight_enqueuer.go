@@ -9,6 +9,7 @@ import (
 	"github.com/inconshreveable/log15"
 
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/background/queryrunner"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query/repometadata"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
 	"github.com/sourcegraph/sourcegraph/internal/database"
@@ -111,7 +112,11 @@ func enqueue(ctx context.Context, dataSeries []types.InsightSeries, mode store.P
 		defaultQueryParams := querybuilder.CodeInsightsQueryDefaults(len(series.Repositories) == 0)
 		var modifiedQuery string
 		var err error
-		if len(series.Repositories) > 0 {
+		if series.GenerationMethod == types.RepoMetadata {
+			// Repository metadata queries are not search queries, so they are only scoped to the
+			// repositories of the series.
+			modifiedQuery = repometadata.ScopedQuery(series.Query, series.Repositories)
+		} else if len(series.Repositories) > 0 {
 			modifiedQuery, err = querybuilder.MultiRepoQuery(series.Query, series.Repositories, defaultQueryParams)
 		} else {
 			modifiedQuery, err = querybuilder.GlobalQuery(series.Query, defaultQueryParams)
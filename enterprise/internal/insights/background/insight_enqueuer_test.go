@@ -183,3 +183,38 @@ func Test_discoverAndEnqueueInsights(t *testing.T) {
   }
 ]`).Equal(t, string(enqueuedJSON))
 }
+
+func Test_enqueueRepoMetadata(t *testing.T) {
+	ctx := context.Background()
+	var enqueued []*queryrunner.Job
+	enqueueQueryRunnerJob := func(ctx context.Context, job *queryrunner.Job) error {
+		enqueued = append(enqueued, job)
+		return nil
+	}
+	stamp := func(ctx context.Context, series types.InsightSeries) (types.InsightSeries, error) {
+		return series, nil
+	}
+
+	series := []types.InsightSeries{{
+		ID:               1,
+		SeriesID:         "series1",
+		Query:            "archived:no lastcommit:>90d",
+		GenerationMethod: types.RepoMetadata,
+	}}
+	if err := enqueue(ctx, series, store.SnapshotMode, stamp, enqueueQueryRunnerJob); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(enqueued) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(enqueued))
+	}
+	// Repository metadata queries are not search queries, so no search defaults are added.
+	autogold.Want("repo metadata query", "archived:no lastcommit:>90d").Equal(t, enqueued[0].SearchQuery)
+
+	enqueued = nil
+	series[0].Repositories = []string{"github.com/sourcegraph/sourcegraph"}
+	if err := enqueue(ctx, series, store.SnapshotMode, stamp, enqueueQueryRunnerJob); err != nil {
+		t.Fatal(err)
+	}
+	autogold.Want("scoped repo metadata query", "archived:no lastcommit:>90d repo:github.com/sourcegraph/sourcegraph").Equal(t, enqueued[0].SearchQuery)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/discovery"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query/repometadata"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query/streaming"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
@@ -46,6 +47,8 @@ type workHandler struct {
 
 	computeSearch       func(context.Context, string) ([]query.ComputeResult, error)
 	computeSearchStream func(context.Context, string) (*streaming.ComputeTabulationResult, error)
+
+	repoMetadata func(context.Context, *repometadata.Query, time.Time) ([]repometadata.Match, error)
 }
 
 type insightsHandler func(ctx context.Context, job *Job, series *types.InsightSeries, recordTime time.Time) error
@@ -367,6 +370,45 @@ func (r *workHandler) computeHandler(ctx context.Context, job *Job, series *type
 	return err
 }
 
+// generateRepoMetadataRecordings records one point per repository that matches the repository
+// metadata query of the job. If the series is generated from capture groups, points are captured
+// by the code host kind of the repository.
+func (r *workHandler) generateRepoMetadataRecordings(ctx context.Context, job *Job, series *types.InsightSeries, recordTime time.Time) ([]store.RecordSeriesPointArgs, error) {
+	q, err := repometadata.Parse(job.SearchQuery)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := r.repoMetadata(ctx, q, recordTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "repoMetadata")
+	}
+
+	recordings := make([]store.RecordSeriesPointArgs, 0, len(matches))
+	for _, match := range matches {
+		var capture *string
+		if series.GeneratedFromCaptureGroups {
+			codeHost := match.CodeHost
+			capture = &codeHost
+		}
+		recordings = append(recordings, ToRecording(job, 1, recordTime, match.RepoName, match.RepoID, capture)...)
+	}
+	return recordings, nil
+}
+
+func (r *workHandler) repoMetadataHandler(ctx context.Context, job *Job, series *types.InsightSeries, recordTime time.Time) (err error) {
+	if series.JustInTime {
+		return errors.Newf("just in time series are not eligible for background processing, series_id: %s", series.ID)
+	}
+
+	recordings, err := r.generateRepoMetadataRecordings(ctx, job, series, recordTime)
+	if err != nil {
+		return err
+	}
+
+	err = r.persistRecordings(ctx, job, series, recordings)
+	return err
+}
+
 func (r *workHandler) persistRecordings(ctx context.Context, job *Job, series *types.InsightSeries, recordings []store.RecordSeriesPointArgs) (err error) {
 
 	tx, err := r.insightsStore.Transact(ctx)
@@ -456,6 +498,7 @@ func (r *workHandler) Handle(ctx context.Context, logger log.Logger, record work
 	handlersByType := map[types.GenerationMethod]insightsHandler{
 		types.SearchCompute: r.computeHandler,
 		types.Search:        r.searchHandler,
+		types.RepoMetadata:  r.repoMetadataHandler,
 	}
 
 	executableHandler, ok := handlersByType[series.GenerationMethod]
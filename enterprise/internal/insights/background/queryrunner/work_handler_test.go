@@ -16,6 +16,7 @@ import (
 
 	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query/repometadata"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query/streaming"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
@@ -1490,3 +1491,57 @@ func TestGetSeries(t *testing.T) {
 	})
 
 }
+
+func TestGenerateRepoMetadataRecordings(t *testing.T) {
+	ctx := context.Background()
+	date := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	job := Job{
+		SeriesID:    "testseries1",
+		SearchQuery: "archived:no lastcommit:>90d",
+		RecordTime:  &date,
+		PersistMode: "record",
+		ID:          1,
+		State:       "queued",
+	}
+
+	handler := workHandler{
+		repoMetadata: func(_ context.Context, q *repometadata.Query, now time.Time) ([]repometadata.Match, error) {
+			if q.Archived == nil || *q.Archived || q.LastCommit == nil || !now.Equal(date) {
+				return nil, errors.Newf("unexpected query %+v at %s", q, now)
+			}
+			return []repometadata.Match{
+				{RepoID: 11, RepoName: "github.com/sourcegraph/sourcegraph", CodeHost: "github"},
+				{RepoID: 12, RepoName: "gitlab.com/sourcegraph/sourcegraph", CodeHost: "gitlab"},
+			}, nil
+		},
+	}
+
+	t.Run("count repositories", func(t *testing.T) {
+		recordings, err := handler.generateRepoMetadataRecordings(ctx, &job, &types.InsightSeries{}, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		autogold.Want("repo metadata recordings", []string{
+			"github.com/sourcegraph/sourcegraph 11 2021-12-01 00:00:00 +0000 UTC  1.000000",
+			"gitlab.com/sourcegraph/sourcegraph 12 2021-12-01 00:00:00 +0000 UTC  1.000000",
+		}).Equal(t, stringify(recordings))
+	})
+
+	t.Run("group by code host", func(t *testing.T) {
+		recordings, err := handler.generateRepoMetadataRecordings(ctx, &job, &types.InsightSeries{GeneratedFromCaptureGroups: true}, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		autogold.Want("repo metadata recordings by code host", []string{
+			"github.com/sourcegraph/sourcegraph 11 2021-12-01 00:00:00 +0000 UTC github 1.000000",
+			"gitlab.com/sourcegraph/sourcegraph 12 2021-12-01 00:00:00 +0000 UTC gitlab 1.000000",
+		}).Equal(t, stringify(recordings))
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		invalid := job
+		invalid.SearchQuery = "lang:go"
+		_, err := handler.generateRepoMetadataRecordings(ctx, &invalid, &types.InsightSeries{}, date)
+		autogold.Want("repo metadata invalid query", `unsupported repository metadata field "lang"`).Equal(t, fmt.Sprint(err))
+	})
+}
@@ -3,6 +3,7 @@ package queryrunner
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/keegancsmith/sqlf"
@@ -16,11 +17,17 @@ import (
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/compression"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/discovery"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query/repometadata"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query/streaming"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/authz"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/gitdomain"
 	"github.com/sourcegraph/sourcegraph/internal/insights/priority"
 	"github.com/sourcegraph/sourcegraph/internal/observation"
 	"github.com/sourcegraph/sourcegraph/internal/workerutil"
@@ -66,6 +73,10 @@ func NewWorker(ctx context.Context, logger log.Logger, workerStore dbworkerstore
 
 	sharedCache := make(map[string]*types.InsightSeries)
 
+	// The worker store lives in the main app database, which holds the repository metadata.
+	repoMetadataStore := basestore.NewWithHandle(workerStore.Handle())
+	gitserverClient := gitserver.NewClient(database.NewDBWith(logger, repoMetadataStore))
+
 	prometheus.DefaultRegisterer.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
 		Name: "src_insights_search_queue_total",
 		Help: "Total number of jobs in the queued state.",
@@ -103,9 +114,32 @@ func NewWorker(ctx context.Context, logger log.Logger, workerStore dbworkerstore
 			}
 			return streamResults, nil
 		},
+		repoMetadata: func(ctx context.Context, q *repometadata.Query, now time.Time) ([]repometadata.Match, error) {
+			return repometadata.Matches(ctx, logger, repoMetadataStore, q, now, func(ctx context.Context, repoName api.RepoName, before time.Time) (time.Time, bool, error) {
+				commits, err := gitserverClient.Commits(ctx, repoName, gitserver.CommitsOptions{N: 1, Before: before.Format(time.RFC3339), DateOrder: true}, authz.DefaultSubRepoPermsChecker)
+				if err != nil {
+					if isNoCommitErr(err) {
+						return time.Time{}, false, nil
+					}
+					return time.Time{}, false, err
+				}
+				if len(commits) == 0 || commits[0].Committer == nil {
+					return time.Time{}, false, nil
+				}
+				return commits[0].Committer.Date, true, nil
+			})
+		},
 	}, options)
 }
 
+// isNoCommitErr reports whether err means that a repository has no commit to
+// look up: it doesn't exist (anymore), it is empty or its HEAD doesn't resolve.
+func isNoCommitErr(err error) bool {
+	return gitdomain.IsRepoNotExist(err) ||
+		errors.HasType(err, &gitdomain.RevisionNotFoundError{}) ||
+		strings.Contains(err.Error(), "does not have any commits yet")
+}
+
 func getRateLimit(defaultValue rate.Limit) func() rate.Limit {
 	return func() rate.Limit {
 		val := conf.Get().InsightsQueryWorkerRateLimit
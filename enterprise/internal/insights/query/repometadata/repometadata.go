@@ -0,0 +1,290 @@
+// Package repometadata implements the queries of code insights series that are
+// generated from repository metadata rather than from search results, such as
+// "archived repositories" or "repositories with no commits in 90 days".
+package repometadata
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+
+	"github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// Query is a parsed repository metadata query. A repository matches a query if
+// it satisfies all of its predicates.
+//
+// Queries are written as space separated field:value pairs:
+//
+//	archived:yes|no               the repository is (not) archived
+//	fork:yes|no                   the repository is (not) a fork
+//	stars:>N, >=N, <N, <=N or N   the star count of the repository
+//	lastcommit:>D, >=D, <D or <=D the last commit of the default branch of the repository
+//	                              is more (less) than D old, where D is a number of hours,
+//	                              days or weeks (e.g. 90d)
+//	codehost:KIND                 the repository is hosted on a code host of that kind
+//	externalservice:ID            the repository is synced by that external service
+//	repo:NAME                     the repository has that name
+//
+// codehost, externalservice and repo may be repeated to match any of the values,
+// all other fields may appear at most once.
+type Query struct {
+	Archived      *bool
+	Fork          *bool
+	Stars         *Comparison
+	LastCommit    *Comparison
+	CodeHostKinds []string
+
+	ExternalServiceIDs []int64
+	RepoNames          []string
+}
+
+// Comparison compares a repository attribute against Value with Operator, one
+// of <, <=, =, >= or >. For lastcommit, Value is an age in seconds.
+type Comparison struct {
+	Operator string
+	Value    int64
+}
+
+// Match is a repository that matches a query.
+type Match struct {
+	RepoID   api.RepoID
+	RepoName string
+	CodeHost string
+}
+
+// Parse parses a repository metadata query.
+func Parse(query string) (*Query, error) {
+	var q Query
+	seen := map[string]bool{}
+	for _, term := range strings.Fields(query) {
+		i := strings.Index(term, ":")
+		if i < 0 {
+			return nil, errors.Errorf("invalid repository metadata predicate %q: expected field:value", term)
+		}
+		field, value := strings.ToLower(term[:i]), term[i+1:]
+		if seen[field] && field != "codehost" && field != "externalservice" && field != "repo" {
+			return nil, errors.Errorf("repository metadata field %q may only appear once", field)
+		}
+		seen[field] = true
+
+		var err error
+		switch field {
+		case "archived":
+			q.Archived, err = parseBool(value)
+		case "fork":
+			q.Fork, err = parseBool(value)
+		case "stars":
+			q.Stars, err = parseComparison(value, strconv.ParseInt)
+		case "lastcommit":
+			q.LastCommit, err = parseComparison(value, parseAge)
+			if err == nil && q.LastCommit.Operator == "=" {
+				err = errors.Errorf("expected an age prefixed with < or >, got %q", value)
+			}
+		case "codehost":
+			q.CodeHostKinds = append(q.CodeHostKinds, strings.ToLower(value))
+		case "externalservice":
+			var id int64
+			id, err = strconv.ParseInt(value, 10, 64)
+			q.ExternalServiceIDs = append(q.ExternalServiceIDs, id)
+		case "repo":
+			q.RepoNames = append(q.RepoNames, value)
+		default:
+			return nil, errors.Errorf("unsupported repository metadata field %q", field)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value for repository metadata field %q", field)
+		}
+	}
+	if len(seen) == 0 {
+		return nil, errors.New("repository metadata query must have at least one predicate")
+	}
+	return &q, nil
+}
+
+func parseBool(value string) (*bool, error) {
+	var b bool
+	switch strings.ToLower(value) {
+	case "yes", "true":
+		b = true
+	case "no", "false":
+		b = false
+	default:
+		return nil, errors.Errorf("expected yes or no, got %q", value)
+	}
+	return &b, nil
+}
+
+func parseComparison(value string, parse func(string, int, int) (int64, error)) (*Comparison, error) {
+	operator := "="
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, op) {
+			operator, value = op, value[len(op):]
+			break
+		}
+	}
+	v, err := parse(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &Comparison{Operator: operator, Value: v}, nil
+}
+
+var ageUnits = map[byte]time.Duration{
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// parseAge parses an age such as 90d into seconds. It has the signature of
+// strconv.ParseInt so that it can be used with parseComparison.
+func parseAge(value string, base, bitSize int) (int64, error) {
+	if value == "" {
+		return 0, errors.New("empty age")
+	}
+	unit, ok := ageUnits[value[len(value)-1]]
+	if !ok {
+		return 0, errors.Errorf("age %q must end in h, d or w", value)
+	}
+	n, err := strconv.ParseInt(value[:len(value)-1], base, bitSize)
+	if err != nil {
+		return 0, err
+	}
+	return int64((time.Duration(n) * unit).Seconds()), nil
+}
+
+// ScopedQuery returns the given repository metadata query restricted to the
+// given repositories.
+func ScopedQuery(query string, repoNames []string) string {
+	terms := []string{query}
+	for _, name := range repoNames {
+		terms = append(terms, "repo:"+name)
+	}
+	return strings.Join(terms, " ")
+}
+
+// conds returns the SQL conditions on the repo and gitserver_repos tables
+// that implement the predicates of the query, except for lastcommit which
+// is checked against gitserver by Matches.
+func (q *Query) conds() []*sqlf.Query {
+	conds := []*sqlf.Query{sqlf.Sprintf("repo.deleted_at IS NULL"), sqlf.Sprintf("repo.blocked IS NULL")}
+	if q.Archived != nil {
+		conds = append(conds, sqlf.Sprintf("repo.archived = %s", *q.Archived))
+	}
+	if q.Fork != nil {
+		conds = append(conds, sqlf.Sprintf("COALESCE(repo.fork, false) = %s", *q.Fork))
+	}
+	if c := q.Stars; c != nil {
+		conds = append(conds, sqlf.Sprintf("repo.stars "+c.Operator+" %s", c.Value))
+	}
+	if q.LastCommit != nil {
+		// Only cloned repositories have commits.
+		conds = append(conds, sqlf.Sprintf("gr.clone_status = 'cloned'"))
+	}
+	if len(q.CodeHostKinds) > 0 {
+		conds = append(conds, sqlf.Sprintf("lower(repo.external_service_type) = ANY(%s)", pq.Array(q.CodeHostKinds)))
+	}
+	if len(q.ExternalServiceIDs) > 0 {
+		conds = append(conds, sqlf.Sprintf(
+			"EXISTS (SELECT 1 FROM external_service_repos esr WHERE esr.repo_id = repo.id AND esr.external_service_id = ANY(%s))",
+			pq.Array(q.ExternalServiceIDs),
+		))
+	}
+	if len(q.RepoNames) > 0 {
+		conds = append(conds, sqlf.Sprintf("repo.name = ANY(%s)", pq.Array(q.RepoNames)))
+	}
+	return conds
+}
+
+// compare reports whether value compares to the value of the comparison with
+// its operator.
+func (c *Comparison) compare(value int64) bool {
+	switch c.Operator {
+	case "<":
+		return value < c.Value
+	case "<=":
+		return value <= c.Value
+	case ">":
+		return value > c.Value
+	case ">=":
+		return value >= c.Value
+	}
+	return value == c.Value
+}
+
+const matchesQueryFmtstr = `
+-- source: enterprise/internal/insights/query/repometadata/repometadata.go:Matches
+SELECT repo.id, repo.name, COALESCE(repo.external_service_type, '')
+FROM repo
+LEFT JOIN gitserver_repos gr ON gr.repo_id = repo.id
+WHERE %s
+ORDER BY repo.id
+`
+
+// LastCommitFunc returns the date of the last commit of the default branch of
+// the given repository before the given time, and false if there is none.
+type LastCommitFunc func(ctx context.Context, repoName api.RepoName, before time.Time) (time.Time, bool, error)
+
+// lastCommitConcurrency is the number of repositories whose last commit is
+// looked up concurrently.
+const lastCommitConcurrency = 10
+
+// Matches returns the repositories of the main application database that match
+// the query at the given time. The lastcommit predicate is checked with
+// lastCommit for each repository that matches all other predicates.
+func Matches(ctx context.Context, logger log.Logger, store *basestore.Store, q *Query, now time.Time, lastCommit LastCommitFunc) ([]Match, error) {
+	matches, err := scanMatches(store.Query(ctx, sqlf.Sprintf(matchesQueryFmtstr, sqlf.Join(q.conds(), "AND"))))
+	if err != nil || q.LastCommit == nil {
+		return matches, err
+	}
+	return filterByLastCommit(ctx, logger, matches, q.LastCommit, now, lastCommit)
+}
+
+// filterByLastCommit returns the matches whose last commit before now satisfies
+// the comparison. Repositories whose last commit can't be looked up are logged
+// and left out, rather than failing the whole snapshot.
+func filterByLastCommit(ctx context.Context, logger log.Logger, matches []Match, c *Comparison, now time.Time, lastCommit LastCommitFunc) ([]Match, error) {
+	keep := make([]bool, len(matches))
+	g := goroutine.NewBounded(lastCommitConcurrency)
+	for i, m := range matches {
+		i, m := i, m
+		g.Go(func() error {
+			date, ok, err := lastCommit(ctx, api.RepoName(m.RepoName), now)
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Warn("failed to get last commit of repository", log.String("repo", m.RepoName), log.Error(err))
+				}
+				return nil
+			}
+			keep[i] = ok && c.compare(int64(now.Sub(date).Seconds()))
+			return nil
+		})
+	}
+	g.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	filtered := matches[:0]
+	for i, m := range matches {
+		if keep[i] {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered, nil
+}
+
+var scanMatches = basestore.NewSliceScanner(func(s dbutil.Scanner) (m Match, err error) {
+	err = s.Scan(&m.RepoID, &m.RepoName, &m.CodeHost)
+	return m, err
+})
@@ -0,0 +1,132 @@
+package repometadata
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/log/logtest"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+func TestParse(t *testing.T) {
+	yes, no := true, false
+
+	for _, tc := range []struct {
+		query string
+		want  *Query
+		err   string
+	}{
+		{
+			query: "archived:yes fork:no",
+			want:  &Query{Archived: &yes, Fork: &no},
+		},
+		{
+			query: "stars:>=100 lastcommit:>90d",
+			want: &Query{
+				Stars:      &Comparison{Operator: ">=", Value: 100},
+				LastCommit: &Comparison{Operator: ">", Value: 90 * 24 * 60 * 60},
+			},
+		},
+		{
+			query: "codehost:GitHub codehost:gitlab externalservice:3",
+			want:  &Query{CodeHostKinds: []string{"github", "gitlab"}, ExternalServiceIDs: []int64{3}},
+		},
+		{
+			query: ScopedQuery("fork:no", []string{"github.com/a/b", "github.com/c/d"}),
+			want:  &Query{Fork: &no, RepoNames: []string{"github.com/a/b", "github.com/c/d"}},
+		},
+		{query: "", err: "repository metadata query must have at least one predicate"},
+		{query: "archived", err: `invalid repository metadata predicate "archived": expected field:value`},
+		{query: "archived:maybe", err: `invalid value for repository metadata field "archived": expected yes or no, got "maybe"`},
+		{query: "stars:1 stars:2", err: `repository metadata field "stars" may only appear once`},
+		{query: "lastcommit:90d", err: `invalid value for repository metadata field "lastcommit": expected an age prefixed with < or >, got "90d"`},
+		{query: "lastcommit:<90m", err: `invalid value for repository metadata field "lastcommit": age "90m" must end in h, d or w`},
+		{query: "lang:go", err: `unsupported repository metadata field "lang"`},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			got, err := Parse(tc.query)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("unexpected error: want %q, have %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected query (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestQueryConds(t *testing.T) {
+	q, err := Parse("archived:no stars:<10 lastcommit:>2d codehost:github repo:github.com/a/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conds := sqlf.Join(q.conds(), "AND")
+	wantQuery := "repo.deleted_at IS NULL AND repo.blocked IS NULL AND repo.archived = $1 AND repo.stars < $2 AND gr.clone_status = 'cloned' AND lower(repo.external_service_type) = ANY($3) AND repo.name = ANY($4)"
+	if have := conds.Query(sqlf.PostgresBindVar); have != wantQuery {
+		t.Errorf("unexpected query:\nwant %s\nhave %s", wantQuery, have)
+	}
+
+	args := conds.Args()
+	if args[0] != false || args[1] != int64(10) {
+		t.Errorf("unexpected args %v", args)
+	}
+}
+
+func TestComparison(t *testing.T) {
+	c := Comparison{Operator: ">", Value: 90}
+	if !c.compare(91) || c.compare(90) {
+		t.Errorf("unexpected result for %+v", c)
+	}
+	c = Comparison{Operator: "<=", Value: 90}
+	if !c.compare(90) || c.compare(91) {
+		t.Errorf("unexpected result for %+v", c)
+	}
+}
+
+func TestFilterByLastCommit(t *testing.T) {
+	now := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	matches := []Match{
+		{RepoID: 1, RepoName: "stale"},
+		{RepoID: 2, RepoName: "active"},
+		{RepoID: 3, RepoName: "empty"},
+		{RepoID: 4, RepoName: "broken"},
+		{RepoID: 5, RepoName: "stale-too"},
+	}
+	lastCommit := func(_ context.Context, repoName api.RepoName, before time.Time) (time.Time, bool, error) {
+		if before != now {
+			t.Errorf("unexpected time %s", before)
+		}
+		switch repoName {
+		case "stale", "stale-too":
+			return now.Add(-100 * 24 * time.Hour), true, nil
+		case "active":
+			return now.Add(-time.Hour), true, nil
+		case "broken":
+			return time.Time{}, false, errors.New("gitserver unavailable")
+		}
+		return time.Time{}, false, nil
+	}
+
+	// The error of a single repository doesn't fail the snapshot.
+	got, err := filterByLastCommit(context.Background(), logtest.Scoped(t), matches, &Comparison{Operator: ">", Value: 90 * 24 * 60 * 60}, now, lastCommit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Match{{RepoID: 1, RepoName: "stale"}, {RepoID: 5, RepoName: "stale-too"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected matches (-want +got):\n%s", diff)
+	}
+}
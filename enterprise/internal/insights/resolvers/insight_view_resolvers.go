@@ -24,6 +24,7 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query/repometadata"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
@@ -1008,6 +1009,12 @@ func createAndAttachSeries(ctx context.Context, tx *store.InsightStore, scopedBa
 	if series.GeneratedFromCaptureGroups != nil {
 		dynamic = *series.GeneratedFromCaptureGroups
 	}
+	generationMethod := searchGenerationMethod(series)
+	if generationMethod == types.RepoMetadata {
+		if _, err := repometadata.Parse(series.Query); err != nil {
+			return nil, err
+		}
+	}
 
 	// Don't try to match on non-global series, since they are always replaced
 	if len(series.RepositoryScope.Repositories) == 0 {
@@ -1016,6 +1023,7 @@ func createAndAttachSeries(ctx context.Context, tx *store.InsightStore, scopedBa
 			StepIntervalUnit:          series.TimeScope.StepInterval.Unit,
 			StepIntervalValue:         int(series.TimeScope.StepInterval.Value),
 			GenerateFromCaptureGroups: dynamic,
+			RepoMetadata:              generationMethod == types.RepoMetadata,
 		})
 		if err != nil {
 			return nil, errors.Wrap(err, "FindMatchingSeries")
@@ -1035,17 +1043,20 @@ func createAndAttachSeries(ctx context.Context, tx *store.InsightStore, scopedBa
 			SampleIntervalUnit:         series.TimeScope.StepInterval.Unit,
 			SampleIntervalValue:        int(series.TimeScope.StepInterval.Value),
 			GeneratedFromCaptureGroups: dynamic,
-			JustInTime:                 len(repos) > 0 && !deprecateJustInTime,
+			JustInTime:                 len(repos) > 0 && !deprecateJustInTime && generationMethod != types.RepoMetadata,
 			// JustInTime:       false,
-			GenerationMethod: searchGenerationMethod(series),
+			GenerationMethod: generationMethod,
 		})
 		if err != nil {
 			return nil, errors.Wrap(err, "CreateSeries")
 		}
-		if len(seriesToAdd.Repositories) > 0 && deprecateJustInTime {
-			err := scopedBackfiller.ScopedBackfill(ctx, []types.InsightSeries{seriesToAdd})
-			if err != nil {
-				return nil, errors.Wrap(err, "ScopedBackfill")
+		if len(seriesToAdd.Repositories) > 0 && (deprecateJustInTime || generationMethod == types.RepoMetadata) {
+			// Repository metadata is not versioned, so there is nothing to backfill.
+			if generationMethod != types.RepoMetadata {
+				err := scopedBackfiller.ScopedBackfill(ctx, []types.InsightSeries{seriesToAdd})
+				if err != nil {
+					return nil, errors.Wrap(err, "ScopedBackfill")
+				}
 			}
 			_, err = tx.StampBackfill(ctx, seriesToAdd) // note that this isn't transactional with the backfill above until the queue is migrated to the insights DB
 			if err != nil {
@@ -1071,6 +1082,9 @@ func createAndAttachSeries(ctx context.Context, tx *store.InsightStore, scopedBa
 }
 
 func searchGenerationMethod(series graphqlbackend.LineChartSearchInsightDataSeriesInput) types.GenerationMethod {
+	if series.RepoMetadata != nil && *series.RepoMetadata {
+		return types.RepoMetadata
+	}
 	if series.GeneratedFromCaptureGroups != nil && *series.GeneratedFromCaptureGroups {
 		return types.SearchCompute
 	}
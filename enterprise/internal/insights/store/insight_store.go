@@ -787,12 +787,19 @@ type MatchSeriesArgs struct {
 	StepIntervalUnit          string
 	StepIntervalValue         int
 	GenerateFromCaptureGroups bool
+	RepoMetadata              bool
 }
 
 func (s *InsightStore) FindMatchingSeries(ctx context.Context, args MatchSeriesArgs) (_ types.InsightSeries, found bool, _ error) {
+	// Repository metadata queries may look like search queries, so they are never matched
+	// against search series or vice versa.
+	generationMethod := sqlf.Sprintf("generation_method != %s", types.RepoMetadata)
+	if args.RepoMetadata {
+		generationMethod = sqlf.Sprintf("generation_method = %s", types.RepoMetadata)
+	}
 	where := sqlf.Sprintf(
-		"(repositories = '{}' OR repositories is NULL) AND query = %s AND sample_interval_unit = %s AND sample_interval_value = %s AND generated_from_capture_groups = %s",
-		args.Query, args.StepIntervalUnit, args.StepIntervalValue, args.GenerateFromCaptureGroups,
+		"(repositories = '{}' OR repositories is NULL) AND query = %s AND sample_interval_unit = %s AND sample_interval_value = %s AND generated_from_capture_groups = %s AND %s",
+		args.Query, args.StepIntervalUnit, args.StepIntervalValue, args.GenerateFromCaptureGroups, generationMethod,
 	)
 
 	q := sqlf.Sprintf(getInsightDataSeriesSql, where)
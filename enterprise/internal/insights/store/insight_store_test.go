@@ -1958,6 +1958,37 @@ func TestFindMatchingSeries(t *testing.T) {
 		autogold.Equal(t, gotSeries, autogold.ExportedOnly())
 		autogold.Want("FoundTrueCaptureGroups", true).Equal(t, gotFound)
 	})
+	t.Run("match repo metadata series", func(t *testing.T) {
+		_, err := store.CreateSeries(ctx, types.InsightSeries{
+			SeriesID:            "series id repo metadata",
+			Query:               "query 1",
+			CreatedAt:           now,
+			OldestHistoricalAt:  now,
+			LastRecordedAt:      now,
+			NextRecordingAfter:  now,
+			LastSnapshotAt:      now,
+			NextSnapshotAfter:   now,
+			BackfillQueuedAt:    now,
+			SampleIntervalUnit:  string(types.Week),
+			SampleIntervalValue: 1,
+			GenerationMethod:    types.RepoMetadata,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotSeries, gotFound, err := store.FindMatchingSeries(ctx, MatchSeriesArgs{Query: "query 1", StepIntervalUnit: string(types.Week), StepIntervalValue: 1, RepoMetadata: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		autogold.Want("FoundTrueRepoMetadata", true).Equal(t, gotFound)
+		autogold.Want("FoundRepoMetadataSeriesID", "series id repo metadata").Equal(t, gotSeries.SeriesID)
+
+		gotSeries, _, err = store.FindMatchingSeries(ctx, MatchSeriesArgs{Query: "query 1", StepIntervalUnit: string(types.Week), StepIntervalValue: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		autogold.Want("FoundSearchSeriesID", "series id 1").Equal(t, gotSeries.SeriesID)
+	})
 }
 
 func TestUpdateFrontendSeries(t *testing.T) {
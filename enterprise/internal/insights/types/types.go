@@ -119,6 +119,7 @@ const (
 	Search        GenerationMethod = "search"
 	SearchCompute GenerationMethod = "search-compute"
 	LanguageStats GenerationMethod = "language-stats"
+	RepoMetadata  GenerationMethod = "repo-metadata"
 )
 
 type DirtyQuery struct {
@@ -2,12 +2,44 @@ package authz
 
 const (
 	// Access token scopes.
-	ScopeUserAll       = "user:all"        // Full control of all resources accessible to the user account.
-	ScopeSiteAdminSudo = "site-admin:sudo" // Ability to perform any action as any other user.
+	ScopeUserAll         = "user:all"         // Full control of all resources accessible to the user account.
+	ScopeSiteAdminSudo   = "site-admin:sudo"  // Ability to perform any action as any other user.
+	ScopeRepoRead        = "repo:read"        // Read-only access to the repositories, code and search results accessible to the user account.
+	ScopeSearchRead      = "search:read"      // Ability to run searches over the repositories accessible to the user account.
+	ScopeCodeIntelUpload = "codeintel:upload" // Ability to upload code intelligence indexes for the repositories accessible to the user account.
+	ScopeBatchesWrite    = "batches:write"    // Ability to create, apply and manage batch changes as the user account.
 )
 
 // AllScopes is a list of all known access token scopes.
 var AllScopes = []string{
 	ScopeUserAll,
 	ScopeSiteAdminSudo,
+	ScopeRepoRead,
+	ScopeSearchRead,
+	ScopeCodeIntelUpload,
+	ScopeBatchesWrite,
+}
+
+// impliedScopes maps a scope to the other scopes that it grants.
+var impliedScopes = map[string][]string{
+	// 🚨 SECURITY: user:all must never imply site-admin:sudo.
+	ScopeUserAll:  {ScopeRepoRead, ScopeSearchRead, ScopeCodeIntelUpload, ScopeBatchesWrite},
+	ScopeRepoRead: {ScopeSearchRead},
+	// Running a batch change with src-cli resolves the repositories it applies to.
+	ScopeBatchesWrite: {ScopeRepoRead, ScopeSearchRead},
+}
+
+// GrantingScopes returns the scopes that grant the given scope, which are the scope itself
+// and the broader scopes that imply it. An access token is allowed to perform an action
+// requiring the given scope if it has any of the returned scopes.
+func GrantingScopes(scope string) []string {
+	scopes := []string{scope}
+	for _, granting := range AllScopes {
+		for _, implied := range impliedScopes[granting] {
+			if implied == scope {
+				scopes = append(scopes, granting)
+			}
+		}
+	}
+	return scopes
 }
@@ -0,0 +1,26 @@
+package authz
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGrantingScopes(t *testing.T) {
+	tests := map[string][]string{
+		ScopeUserAll:         {ScopeUserAll},
+		ScopeSiteAdminSudo:   {ScopeSiteAdminSudo},
+		ScopeRepoRead:        {ScopeRepoRead, ScopeUserAll, ScopeBatchesWrite},
+		ScopeSearchRead:      {ScopeSearchRead, ScopeUserAll, ScopeRepoRead, ScopeBatchesWrite},
+		ScopeCodeIntelUpload: {ScopeCodeIntelUpload, ScopeUserAll},
+		ScopeBatchesWrite:    {ScopeBatchesWrite, ScopeUserAll},
+		"unknown":            {"unknown"},
+	}
+	for scope, want := range tests {
+		t.Run(scope, func(t *testing.T) {
+			if diff := cmp.Diff(want, GrantingScopes(scope)); diff != "" {
+				t.Errorf("unexpected granting scopes (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ func InferRepositoryAndRevision(pkg precise.Package) (repoName api.RepoName, git
 		inferJVMRepositoryAndRevision,
 		inferNpmRepositoryAndRevision,
 		inferRustRepositoryAndRevision,
+		inferRubyRepositoryAndRevision,
 	} {
 		if repoName, gitTagOrCommit, ok := fn(pkg); ok {
 			return repoName, gitTagOrCommit, true
@@ -81,3 +82,17 @@ func inferRustRepositoryAndRevision(pkg precise.Package) (api.RepoName, string,
 
 	return rustPkg.RepoName(), "v" + pkg.Version, true
 }
+
+func inferRubyRepositoryAndRevision(pkg precise.Package) (api.RepoName, string, bool) {
+	if pkg.Scheme != dependencies.RubyPackagesScheme {
+		return "", "", false
+	}
+
+	rubyPkg, err := reposource.ParseRubyDependency(pkg.Name)
+	if err != nil {
+		log15.Error("invalid ruby package name in database", "error", err, "pkg", pkg.Name)
+		return "", "", false
+	}
+
+	return rubyPkg.RepoName(), "v" + pkg.Version, true
+}
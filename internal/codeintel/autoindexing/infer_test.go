@@ -58,6 +58,15 @@ func TestInferRepositoryAndRevision(t *testing.T) {
 				repoName: "npm/myscope/mypackage",
 				revision: "v1.0.0",
 			},
+			{
+				pkg: precise.Package{
+					Scheme:  "scip-ruby",
+					Name:    "rails",
+					Version: "7.0.4",
+				},
+				repoName: "rubygems/rails",
+				revision: "v7.0.4",
+			},
 		}
 
 		for _, testCase := range testCases {
@@ -0,0 +1,25 @@
+package vulnerabilities
+
+import (
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+type config struct {
+	env.BaseConfig
+
+	AdvisoryDir    string
+	ImportInterval time.Duration
+	MatchInterval  time.Duration
+	MatchBatchSize int
+}
+
+var ConfigInst = &config{}
+
+func (c *config) Load() {
+	c.AdvisoryDir = c.GetOptional("CODEINTEL_DEPENDENCIES_VULNERABILITIES_ADVISORY_DIR", "A directory containing OSV-format advisories (JSON files or zip archives) to import. Vulnerability import is disabled if unset.")
+	c.ImportInterval = c.GetInterval("CODEINTEL_DEPENDENCIES_VULNERABILITIES_IMPORT_INTERVAL", "1h", "How frequently to import advisories from the advisory directory.")
+	c.MatchInterval = c.GetInterval("CODEINTEL_DEPENDENCIES_VULNERABILITIES_MATCH_INTERVAL", "1h", "How frequently to match advisories against lockfile dependencies.")
+	c.MatchBatchSize = c.GetInt("CODEINTEL_DEPENDENCIES_VULNERABILITIES_MATCH_BATCH_SIZE", "1000", "How many affected packages to match against lockfile dependencies at a time.")
+}
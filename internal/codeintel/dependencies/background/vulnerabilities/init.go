@@ -0,0 +1,28 @@
+package vulnerabilities
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies"
+	livedependencies "github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/live"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+)
+
+// NewImporter returns a routine that periodically imports OSV advisories from the configured
+// advisory directory.
+func NewImporter(db database.DB, syncer dependencies.Syncer) goroutine.BackgroundRoutine {
+	return goroutine.NewPeriodicGoroutine(context.Background(), ConfigInst.ImportInterval, &importer{
+		dependenciesSvc: livedependencies.GetService(db, syncer),
+		dir:             ConfigInst.AdvisoryDir,
+	})
+}
+
+// NewMatcher returns a routine that periodically matches imported advisories against the
+// resolved lockfile dependencies of every repository.
+func NewMatcher(db database.DB, syncer dependencies.Syncer) goroutine.BackgroundRoutine {
+	return goroutine.NewPeriodicGoroutine(context.Background(), ConfigInst.MatchInterval, &matcher{
+		dependenciesSvc: livedependencies.GetService(db, syncer),
+		batchSize:       ConfigInst.MatchBatchSize,
+	})
+}
@@ -0,0 +1,55 @@
+package vulnerabilities
+
+import (
+	"context"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+type importer struct {
+	dependenciesSvc *dependencies.Service
+	dir             string
+}
+
+var _ goroutine.Handler = &importer{}
+var _ goroutine.ErrorHandler = &importer{}
+
+func (i *importer) Handle(ctx context.Context) error {
+	if i.dir == "" {
+		return nil
+	}
+
+	if _, err := i.dependenciesSvc.ImportVulnerabilities(ctx, i.dir); err != nil {
+		return errors.Wrap(err, "dependencies.ImportVulnerabilities")
+	}
+
+	return nil
+}
+
+func (i *importer) HandleError(err error) {
+	log15.Error("Failed to import vulnerabilities", "error", err)
+}
+
+type matcher struct {
+	dependenciesSvc *dependencies.Service
+	batchSize       int
+}
+
+var _ goroutine.Handler = &matcher{}
+var _ goroutine.ErrorHandler = &matcher{}
+
+func (m *matcher) Handle(ctx context.Context) error {
+	if _, err := m.dependenciesSvc.MatchVulnerabilities(ctx, m.batchSize); err != nil {
+		return errors.Wrap(err, "dependencies.MatchVulnerabilities")
+	}
+
+	return nil
+}
+
+func (m *matcher) HandleError(err error) {
+	log15.Error("Failed to match vulnerabilities", "error", err)
+}
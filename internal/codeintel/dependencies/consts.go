@@ -7,5 +7,6 @@ const (
 	NpmPackagesScheme    = shared.NpmPackagesScheme
 	GoModulesScheme      = shared.GoModulesScheme
 	PythonPackagesScheme = shared.PythonPackagesScheme
+	RubyPackagesScheme   = shared.RubyPackagesScheme
 	RustPackagesScheme   = shared.RustPackagesScheme
 )
@@ -0,0 +1,109 @@
+package lockfiles
+
+import (
+	"io"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+//
+// Cargo.lock
+//
+
+type cargoPackage struct {
+	Name         string   `toml:"name"`
+	Version      string   `toml:"version"`
+	Source       string   `toml:"source"`
+	Dependencies []string `toml:"dependencies"`
+}
+
+// parseCargoLockFile extracts the crates.io dependencies of a Cargo.lock file along with the
+// graph of dependencies between them.
+//
+// Packages without a source are the members of the workspace, which are not dependencies
+// themselves: their dependencies are the direct dependencies of the lockfile. Packages from
+// other sources (e.g., git repositories or alternate registries) are not crates.io packages
+// and are omitted.
+func parseCargoLockFile(r io.Reader) ([]reposource.PackageDependency, *DependencyGraph, error) {
+	var lockfile struct {
+		Packages []cargoPackage `toml:"package"`
+	}
+
+	if _, err := toml.DecodeReader(r, &lockfile); err != nil {
+		return nil, nil, errors.Errorf("error decoding Cargo.lock: %w", err)
+	}
+
+	deps, graph := buildCargoDependencyGraph(lockfile.Packages)
+	return deps, graph, nil
+}
+
+const cratesIORegistrySource = "registry+https://github.com/rust-lang/crates.io-index"
+
+func buildCargoDependencyGraph(packages []cargoPackage) ([]reposource.PackageDependency, *DependencyGraph) {
+	var (
+		deps      = make([]reposource.PackageDependency, 0, len(packages))
+		graph     = newDependencyGraph()
+		crates    = make(map[string]*reposource.RustDependency, len(packages))
+		byName    = make(map[string][]*reposource.RustDependency, len(packages))
+		workspace = make([]cargoPackage, 0, 1)
+	)
+
+	for _, pkg := range packages {
+		switch pkg.Source {
+		case "":
+			workspace = append(workspace, pkg)
+
+		case cratesIORegistrySource:
+			dep := reposource.NewRustDependency(pkg.Name, pkg.Version)
+			crates[pkg.Name+" "+pkg.Version] = dep
+			byName[pkg.Name] = append(byName[pkg.Name], dep)
+			deps = append(deps, dep)
+			graph.addPackage(dep)
+		}
+	}
+
+	// Entries of a package's dependency list are `name`, `name version`, or `name version (source)`.
+	// The version is omitted when only one version of the named package is in the lockfile.
+	resolve := func(entry string) (*reposource.RustDependency, bool) {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			return nil, false
+		}
+		if len(fields) == 1 {
+			if candidates := byName[fields[0]]; len(candidates) == 1 {
+				return candidates[0], true
+			}
+			return nil, false
+		}
+
+		dep, ok := crates[fields[0]+" "+fields[1]]
+		return dep, ok
+	}
+
+	for _, pkg := range workspace {
+		for _, entry := range pkg.Dependencies {
+			if dep, ok := resolve(entry); ok {
+				graph.addRoot(dep)
+			}
+		}
+	}
+
+	for _, pkg := range packages {
+		if pkg.Source != cratesIORegistrySource {
+			continue
+		}
+
+		source := crates[pkg.Name+" "+pkg.Version]
+		for _, entry := range pkg.Dependencies {
+			if dep, ok := resolve(entry); ok {
+				graph.addDependency(source, dep)
+			}
+		}
+	}
+
+	return deps, graph
+}
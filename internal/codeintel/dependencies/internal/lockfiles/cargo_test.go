@@ -0,0 +1,41 @@
+package lockfiles
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCargoLockFileGraph(t *testing.T) {
+	f, err := os.Open("testdata/parse/Cargo.lock/cargo_workspace.lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_, graph, err := parseCargoLockFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bitflags 2.0.0 is a direct dependency of the util workspace member, while bitflags 1.3.2
+	// is only a transitive dependency of tokio. The git dependency private-crate is omitted.
+	want := `` +
+		`crates/tokio:
+	crates/memchr
+	crates/bytes
+	crates/bitflags
+crates/serde
+crates/regex:
+	crates/regex-syntax
+	crates/memchr
+	crates/aho-corasick:
+		crates/memchr
+crates/bitflags
+`
+
+	if d := cmp.Diff(want, graph.String()); d != "" {
+		t.Fatalf("+want,-got\n%s", d)
+	}
+}
@@ -17,12 +17,18 @@ func newDependencyGraph() *DependencyGraph {
 	return &DependencyGraph{
 		dependencies: make(map[reposource.PackageDependency][]reposource.PackageDependency),
 		edges:        map[Edge]struct{}{},
+		roots:        map[reposource.PackageDependency]struct{}{},
 	}
 }
 
 type DependencyGraph struct {
 	dependencies map[reposource.PackageDependency][]reposource.PackageDependency
 	edges        map[Edge]struct{}
+
+	// roots are the packages explicitly marked as direct dependencies. Lockfiles that
+	// record direct dependencies separately (e.g., Cargo.lock or Gemfile.lock) mark
+	// them, as a direct dependency may also be a transitive dependency of another.
+	roots map[reposource.PackageDependency]struct{}
 }
 
 func (dg *DependencyGraph) addPackage(pkg reposource.PackageDependency) {
@@ -31,11 +37,31 @@ func (dg *DependencyGraph) addPackage(pkg reposource.PackageDependency) {
 	}
 }
 func (dg *DependencyGraph) addDependency(a, b reposource.PackageDependency) {
+	if _, ok := dg.edges[Edge{a, b}]; ok {
+		return
+	}
+
 	dg.dependencies[a] = append(dg.dependencies[a], b)
 	dg.edges[Edge{a, b}] = struct{}{}
 }
 
+// addRoot adds the given package to the graph as a direct dependency.
+func (dg *DependencyGraph) addRoot(pkg reposource.PackageDependency) {
+	dg.addPackage(pkg)
+	dg.roots[pkg] = struct{}{}
+}
+
+// Roots returns the direct dependencies. If no package was explicitly marked as a direct
+// dependency, the roots are the packages that no other package depends on.
 func (dg *DependencyGraph) Roots() (roots []reposource.PackageDependency) {
+	if len(dg.roots) > 0 {
+		for pkg := range dg.roots {
+			roots = append(roots, pkg)
+		}
+
+		return roots
+	}
+
 	set := make(map[reposource.PackageDependency]struct{}, len(dg.dependencies))
 	for pkg := range dg.dependencies {
 		set[pkg] = struct{}{}
@@ -0,0 +1,170 @@
+package lockfiles
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+//
+// Gemfile.lock
+//
+
+/* Gemfile.lock
+
+GIT
+  remote: https://github.com/rails/rails.git
+  revision: 5c1b2b4d36a1b4e1e7b3a5c1b8e5ed1d1ad1a2e3
+  specs:
+    rails (7.1.0.alpha)
+      actionpack (= 7.1.0.alpha)
+
+GEM
+  remote: https://rubygems.org/
+  specs:
+    actionpack (7.1.0.alpha)
+      rack (~> 2.0, >= 2.2.0)
+    rack (2.2.3.1)
+
+PLATFORMS
+  ruby
+
+DEPENDENCIES
+  rack
+  rails!
+*/
+
+var (
+	gemfileSpecRegexp       = lazyregexp.New(`^    ([^ (]+)(?: \(([^)]+)\))?$`)
+	gemfileSpecDepRegexp    = lazyregexp.New(`^      ([^ (]+)(?: \([^)]*\))?$`)
+	gemfileDependencyRegexp = lazyregexp.New(`^  ([^ (!]+)!?(?: \([^)]*\))?$`)
+)
+
+type gemfileSpec struct {
+	name     string
+	version  string
+	fromGem  bool
+	depNames []string
+}
+
+// parseGemfileLockFile extracts the rubygems.org dependencies of a Gemfile.lock file along
+// with the graph of dependencies between them.
+//
+// Gems from GIT and PATH sections are not rubygems.org packages and are omitted. When such a
+// gem is a direct dependency, its own dependencies are treated as direct dependencies.
+func parseGemfileLockFile(r io.Reader) ([]reposource.PackageDependency, *DependencyGraph, error) {
+	var (
+		section    string
+		specs      []*gemfileSpec
+		current    *gemfileSpec
+		directDeps []string
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") {
+			section = line
+			current = nil
+			continue
+		}
+
+		switch section {
+		case "GEM", "GIT", "PATH", "PLUGIN SOURCE":
+			if match := gemfileSpecRegexp.FindStringSubmatch(line); match != nil {
+				current = &gemfileSpec{
+					name:    match[1],
+					version: gemVersion(match[2]),
+					fromGem: section == "GEM",
+				}
+				specs = append(specs, current)
+				continue
+			}
+
+			if match := gemfileSpecDepRegexp.FindStringSubmatch(line); match != nil && current != nil {
+				current.depNames = append(current.depNames, match[1])
+			}
+
+		case "DEPENDENCIES":
+			if match := gemfileDependencyRegexp.FindStringSubmatch(line); match != nil {
+				directDeps = append(directDeps, match[1])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, errors.Errorf("error reading Gemfile.lock: %w", err)
+	}
+
+	var (
+		deps       []reposource.PackageDependency
+		graph      = newDependencyGraph()
+		gems       = make(map[string]*reposource.RubyDependency, len(specs))
+		localSpecs = make(map[string]*gemfileSpec)
+	)
+
+	for _, spec := range specs {
+		if !spec.fromGem {
+			localSpecs[spec.name] = spec
+			continue
+		}
+
+		// Platform-specific builds of the same gem version (e.g., nokogiri (1.13.6-x86_64-linux)
+		// and nokogiri (1.13.6-arm64-darwin)) are listed separately but are the same package
+		if _, ok := gems[spec.name]; ok {
+			continue
+		}
+
+		dep := reposource.NewRubyDependency(spec.name, spec.version)
+		gems[spec.name] = dep
+		deps = append(deps, dep)
+		graph.addPackage(dep)
+	}
+
+	for _, name := range directDeps {
+		if dep, ok := gems[name]; ok {
+			graph.addRoot(dep)
+			continue
+		}
+
+		if spec, ok := localSpecs[name]; ok {
+			for _, depName := range spec.depNames {
+				if dep, ok := gems[depName]; ok {
+					graph.addRoot(dep)
+				}
+			}
+		}
+	}
+
+	for _, spec := range specs {
+		if !spec.fromGem {
+			continue
+		}
+
+		source := gems[spec.name]
+		for _, depName := range spec.depNames {
+			if dep, ok := gems[depName]; ok {
+				graph.addDependency(source, dep)
+			}
+		}
+	}
+
+	return deps, graph, nil
+}
+
+// gemVersion strips the platform suffix from a locked gem version. Gem versions separate
+// pre-release segments with dots, so a dash always introduces a platform.
+func gemVersion(version string) string {
+	if i := strings.Index(version, "-"); i >= 0 {
+		return version[:i]
+	}
+
+	return version
+}
@@ -0,0 +1,45 @@
+package lockfiles
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseGemfileLockFileGraph(t *testing.T) {
+	f, err := os.Open("testdata/parse/Gemfile.lock/gemfile_rails_app.lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_, graph, err := parseGemfileLockFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The dependencies of the billing (PATH) and feature_flags (GIT) gems are direct
+	// dependencies. i18n is both a direct and a transitive dependency.
+	want := `` +
+		`rubygems/redis
+rubygems/nokogiri:
+	rubygems/racc
+rubygems/money:
+	rubygems/i18n:
+		rubygems/concurrent-ruby
+rubygems/i18n:
+	rubygems/concurrent-ruby
+rubygems/activesupport:
+	rubygems/tzinfo:
+		rubygems/concurrent-ruby
+	rubygems/minitest
+	rubygems/i18n:
+		rubygems/concurrent-ruby
+	rubygems/concurrent-ruby
+`
+
+	if d := cmp.Diff(want, graph.String()); d != "" {
+		t.Fatalf("+want,-got\n%s", d)
+	}
+}
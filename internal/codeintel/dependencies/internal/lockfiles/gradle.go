@@ -0,0 +1,62 @@
+package lockfiles
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+//
+// gradle.lockfile
+//
+
+/* gradle.lockfile
+
+# This is a Gradle generated file for dependency locking.
+# Manual edits can break the build and are not advised.
+# This file is expected to be part of source control.
+com.google.code.gson:gson:2.8.6=compileClasspath,runtimeClasspath
+org.slf4j:slf4j-api:1.7.30=runtimeClasspath
+empty=annotationProcessor
+*/
+
+// parseGradleLockFile extracts the dependencies locked by a gradle.lockfile file.
+//
+// Gradle locks every resolved dependency of each configuration, both direct and transitive,
+// but does not record which dependency requires which. No dependency graph is returned.
+func parseGradleLockFile(r io.Reader) (deps []reposource.PackageDependency, err error) {
+	var errs errors.MultiError
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "empty=") {
+			continue
+		}
+
+		coordinates := line
+		if i := strings.Index(line, "="); i >= 0 {
+			coordinates = line[:i]
+		}
+
+		if strings.Count(coordinates, ":") != 2 {
+			errs = errors.Append(errs, errors.Newf("invalid gradle.lockfile entry %q", line))
+			continue
+		}
+
+		dep, err := reposource.ParseMavenDependency(coordinates)
+		if err != nil {
+			errs = errors.Append(errs, err)
+			continue
+		}
+		deps = append(deps, dep)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Errorf("error reading gradle.lockfile: %w", err)
+	}
+
+	return deps, errs
+}
@@ -0,0 +1,174 @@
+package lockfiles
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+//
+// pom.xml
+//
+
+type mavenDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+type mavenProperties map[string]string
+
+func (p *mavenProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*p = mavenProperties{}
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			var value string
+			if err := d.DecodeElement(&value, &t); err != nil {
+				return err
+			}
+			(*p)[t.Name.Local] = strings.TrimSpace(value)
+
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type mavenProject struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Parent     struct {
+		GroupID string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Properties           mavenProperties   `xml:"properties"`
+	DependencyManagement []mavenDependency `xml:"dependencyManagement>dependencies>dependency"`
+	Dependencies         []mavenDependency `xml:"dependencies>dependency"`
+}
+
+var mavenPropertyRegexp = lazyregexp.New(`\$\{([^}]+)\}`)
+
+// parsePomFile extracts the direct dependencies declared by a Maven pom.xml file.
+//
+// A pom.xml file does not lock transitive dependencies, so every dependency is a root of the
+// returned graph. Versions are resolved from the properties and the dependencyManagement section
+// of the same file; dependencies whose versions are inherited from a parent or imported BOM, or
+// that are declared as version ranges, cannot be resolved and are omitted.
+func parsePomFile(r io.Reader) ([]reposource.PackageDependency, *DependencyGraph, error) {
+	var project mavenProject
+	if err := xml.NewDecoder(bufio.NewReader(r)).Decode(&project); err != nil {
+		return nil, nil, errors.Errorf("error decoding pom.xml: %w", err)
+	}
+
+	properties := map[string]string{}
+	for name, value := range project.Properties {
+		properties[name] = value
+	}
+	properties["project.groupId"] = firstNonEmpty(project.GroupID, project.Parent.GroupID)
+	properties["project.artifactId"] = project.ArtifactID
+	properties["project.version"] = firstNonEmpty(project.Version, project.Parent.Version)
+	properties["project.parent.groupId"] = project.Parent.GroupID
+	properties["project.parent.version"] = project.Parent.Version
+	for _, name := range []string{"groupId", "artifactId", "version"} {
+		// Deprecated aliases of the project.* properties
+		properties["pom."+name] = properties["project."+name]
+	}
+
+	managedVersions := map[string]string{}
+	for _, dep := range project.DependencyManagement {
+		groupID := resolveMavenProperties(dep.GroupID, properties)
+		artifactID := resolveMavenProperties(dep.ArtifactID, properties)
+		managedVersions[groupID+":"+artifactID] = resolveMavenProperties(dep.Version, properties)
+	}
+
+	var (
+		deps  []reposource.PackageDependency
+		graph = newDependencyGraph()
+		seen  = map[string]struct{}{}
+	)
+
+	for _, dep := range project.Dependencies {
+		if dep.Scope == "system" {
+			// System dependencies refer to files on the local disk
+			continue
+		}
+
+		groupID := resolveMavenProperties(dep.GroupID, properties)
+		artifactID := resolveMavenProperties(dep.ArtifactID, properties)
+		version := resolveMavenProperties(dep.Version, properties)
+		if version == "" {
+			version = managedVersions[groupID+":"+artifactID]
+		}
+		if !validMavenCoordinates(groupID, artifactID, version) {
+			continue
+		}
+
+		key := groupID + ":" + artifactID + ":" + version
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		mavenDep := &reposource.MavenDependency{
+			MavenModule: &reposource.MavenModule{GroupID: groupID, ArtifactID: artifactID},
+			Version:     version,
+		}
+		deps = append(deps, mavenDep)
+		graph.addRoot(mavenDep)
+	}
+
+	return deps, graph, nil
+}
+
+// resolveMavenProperties substitutes `${name}` references with the values of the given
+// properties. Properties may refer to other properties.
+func resolveMavenProperties(value string, properties map[string]string) string {
+	value = strings.TrimSpace(value)
+
+	// Bound the number of substitution passes to guard against cyclic references
+	for i := 0; i < 10 && strings.Contains(value, "${"); i++ {
+		value = mavenPropertyRegexp.ReplaceAllStringFunc(value, func(reference string) string {
+			if resolved, ok := properties[reference[2:len(reference)-1]]; ok {
+				return resolved
+			}
+			return reference
+		})
+	}
+
+	return value
+}
+
+func validMavenCoordinates(groupID, artifactID, version string) bool {
+	for _, value := range []string{groupID, artifactID, version} {
+		if value == "" || strings.Contains(value, "${") || strings.ContainsAny(value, ":/") {
+			return false
+		}
+	}
+
+	// Version ranges such as [1.0,2.0) do not identify a single version
+	return !strings.ContainsAny(version, "[](),")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
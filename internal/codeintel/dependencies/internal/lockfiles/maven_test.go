@@ -0,0 +1,70 @@
+package lockfiles
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePomFile(t *testing.T) {
+	pom := `<project>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>1.0.0</version>
+  <properties>
+    <netty.major>4.1</netty.major>
+    <netty.version>${netty.major}.78.Final</netty.version>
+    <cyclic>${cyclic}</cyclic>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>io.netty</groupId>
+      <artifactId>netty-handler</artifactId>
+      <version>${netty.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>io.netty</groupId>
+      <artifactId>netty-handler</artifactId>
+      <version>${netty.version}</version>
+      <classifier>linux</classifier>
+    </dependency>
+    <dependency>
+      <groupId>com.example</groupId>
+      <artifactId>cyclic</artifactId>
+      <version>${cyclic}</version>
+    </dependency>
+    <dependency>
+      <groupId>com.example</groupId>
+      <artifactId>inherited</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>${project.groupId}</groupId>
+      <artifactId>app-core</artifactId>
+      <version>${project.version}</version>
+    </dependency>
+  </dependencies>
+</project>
+`
+
+	deps, graph, err := parsePomFile(strings.NewReader(pom))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, dep := range deps {
+		got = append(got, dep.PackageManagerSyntax())
+	}
+	want := []string{
+		"io.netty:netty-handler:4.1.78.Final",
+		"com.example:app-core:1.0.0",
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Fatalf("unexpected dependencies (-want +got):\n%s", d)
+	}
+
+	if roots := graph.Roots(); len(roots) != len(deps) {
+		t.Fatalf("unexpected number of roots. want=%d have=%d", len(deps), len(roots))
+	}
+}
@@ -24,6 +24,10 @@ var parsers = map[string]parser{
 	"go.mod":            wrapNonGraphParser(parseGoModFile),
 	"poetry.lock":       wrapNonGraphParser(parsePoetryLockFile),
 	"Pipfile.lock":      wrapNonGraphParser(parsePipfileLockFile),
+	"Cargo.lock":        parseCargoLockFile,
+	"Gemfile.lock":      parseGemfileLockFile,
+	"pom.xml":           parsePomFile,
+	"gradle.lockfile":   wrapNonGraphParser(parseGradleLockFile),
 }
 
 // lockfilePathspecs is the list of git pathspecs that match lockfiles.
@@ -0,0 +1,269 @@
+package osv
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/shared"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// advisory is the subset of the OSV schema (https://ossf.github.io/osv-schema/) that is
+// imported into the vulnerabilities tables.
+type advisory struct {
+	ID        string     `json:"id"`
+	Aliases   []string   `json:"aliases"`
+	Summary   string     `json:"summary"`
+	Details   string     `json:"details"`
+	Published *time.Time `json:"published"`
+	Modified  *time.Time `json:"modified"`
+	Withdrawn *time.Time `json:"withdrawn"`
+	Severity  []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Type   string  `json:"type"`
+			Events []event `json:"events"`
+		} `json:"ranges"`
+		Versions []string `json:"versions"`
+	} `json:"affected"`
+}
+
+type event struct {
+	Introduced   string `json:"introduced"`
+	Fixed        string `json:"fixed"`
+	LastAffected string `json:"last_affected"`
+	Limit        string `json:"limit"`
+}
+
+func (e event) version() string {
+	for _, version := range []string{e.Introduced, e.Fixed, e.LastAffected, e.Limit} {
+		if version != "" {
+			return version
+		}
+	}
+
+	return ""
+}
+
+// ecosystemSchemes maps OSV ecosystems to the package schemes of lockfile dependencies.
+var ecosystemSchemes = map[string]string{
+	"Go":        shared.GoModulesScheme,
+	"npm":       shared.NpmPackagesScheme,
+	"PyPI":      shared.PythonPackagesScheme,
+	"crates.io": shared.RustPackagesScheme,
+	"RubyGems":  shared.RubyPackagesScheme,
+	"Maven":     shared.JVMPackagesScheme,
+}
+
+// ReadAdvisories reads every OSV advisory in the given directory and invokes the given
+// function with each advisory that affects a package in a supported ecosystem. Advisories
+// may be stored as individual JSON files or within zip archives, as distributed by osv.dev.
+func ReadAdvisories(dir string, f func(vulnerability shared.Vulnerability) error) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		switch filepath.Ext(path) {
+		case ".json":
+			return readFile(path, f)
+		case ".zip":
+			return readArchive(path, f)
+		}
+
+		return nil
+	})
+}
+
+func readFile(path string, f func(vulnerability shared.Vulnerability) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return parseAndHandle(path, file, f)
+}
+
+func readArchive(path string, f func(vulnerability shared.Vulnerability) error) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if filepath.Ext(file.Name) != ".json" {
+			continue
+		}
+
+		if err := func() error {
+			r, err := file.Open()
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+
+			return parseAndHandle(path+":"+file.Name, r, f)
+		}(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseAndHandle(name string, r io.Reader, f func(vulnerability shared.Vulnerability) error) error {
+	vulnerability, ok, err := ParseAdvisory(r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse advisory %s", name)
+	}
+	if !ok {
+		return nil
+	}
+
+	return f(vulnerability)
+}
+
+// ParseAdvisory parses a single OSV advisory. The returned flag is false if the advisory
+// does not affect any package in a supported ecosystem.
+func ParseAdvisory(r io.Reader) (shared.Vulnerability, bool, error) {
+	var a advisory
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return shared.Vulnerability{}, false, err
+	}
+	if a.ID == "" {
+		return shared.Vulnerability{}, false, errors.New("advisory has no id")
+	}
+
+	var affectedPackages []shared.AffectedPackage
+	for _, affected := range a.Affected {
+		// Ecosystems may carry a release suffix (e.g., "Debian:11"); only the name selects the scheme
+		ecosystem := affected.Package.Ecosystem
+		if i := strings.Index(ecosystem, ":"); i >= 0 {
+			ecosystem = ecosystem[:i]
+		}
+		scheme, ok := ecosystemSchemes[ecosystem]
+		if !ok || affected.Package.Name == "" {
+			continue
+		}
+
+		var ranges []shared.VersionRange
+		for _, r := range affected.Ranges {
+			if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+				// GIT ranges refer to commits, which lockfile dependencies do not record
+				continue
+			}
+
+			ranges = append(ranges, convertEvents(r.Events)...)
+		}
+		if len(ranges) == 0 && len(affected.Versions) == 0 {
+			continue
+		}
+
+		affectedPackages = append(affectedPackages, shared.AffectedPackage{
+			PackageScheme: scheme,
+			PackageName:   NormalizePackageName(scheme, affected.Package.Name),
+			Versions:      affected.Versions,
+			Ranges:        ranges,
+		})
+	}
+	if len(affectedPackages) == 0 {
+		return shared.Vulnerability{}, false, nil
+	}
+
+	var severity string
+	for _, s := range a.Severity {
+		if strings.HasPrefix(s.Type, "CVSS_") {
+			severity = s.Score
+			break
+		}
+	}
+
+	return shared.Vulnerability{
+		SourceID:         a.ID,
+		Aliases:          a.Aliases,
+		Summary:          a.Summary,
+		Details:          a.Details,
+		Severity:         severity,
+		PublishedAt:      a.Published,
+		ModifiedAt:       a.Modified,
+		WithdrawnAt:      a.Withdrawn,
+		AffectedPackages: affectedPackages,
+	}, true, nil
+}
+
+// convertEvents converts the events of an OSV range into a set of version ranges. Each
+// introduced event opens a range, which is closed by the next fixed or last_affected event.
+func convertEvents(events []event) []shared.VersionRange {
+	sorted := make([]event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return CompareVersions(sorted[i].version(), sorted[j].version()) < 0
+	})
+
+	var (
+		ranges  []shared.VersionRange
+		current *shared.VersionRange
+	)
+	for _, e := range sorted {
+		switch {
+		case e.Introduced != "":
+			if current != nil {
+				// Overlapping ranges collapse into the earlier one
+				continue
+			}
+			introduced := e.Introduced
+			if introduced == "0" {
+				introduced = ""
+			}
+			current = &shared.VersionRange{Introduced: introduced}
+
+		case e.Fixed != "" && current != nil:
+			current.Fixed = e.Fixed
+			ranges = append(ranges, *current)
+			current = nil
+
+		case e.LastAffected != "" && current != nil:
+			current.LastAffected = e.LastAffected
+			ranges = append(ranges, *current)
+			current = nil
+
+		case e.Limit != "" && current != nil:
+			// A limit bounds the range like a fix does, but is not itself a release
+			current.Fixed = e.Limit
+			ranges = append(ranges, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		ranges = append(ranges, *current)
+	}
+
+	return ranges
+}
+
+// NormalizePackageName returns the canonical form of a package name within the given scheme.
+// Python package names are compared case-insensitively and treat runs of `-`, `_`, and `.`
+// as equivalent (PEP 503); names in other schemes are returned unchanged.
+func NormalizePackageName(scheme, name string) string {
+	if scheme != shared.PythonPackagesScheme {
+		return name
+	}
+
+	return pythonSeparatorPattern.ReplaceAllString(strings.ToLower(name), "-")
+}
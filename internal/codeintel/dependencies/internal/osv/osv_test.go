@@ -0,0 +1,128 @@
+package osv
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/shared"
+)
+
+const testAdvisory = `{
+	"id": "GHSA-p6mc-m468-83gw",
+	"modified": "2022-06-01T12:00:00Z",
+	"published": "2020-07-15T19:15:00Z",
+	"aliases": ["CVE-2020-8203"],
+	"summary": "Prototype Pollution in lodash",
+	"details": "Prototype pollution attack when using _.zipObjectDeep in lodash before 4.17.20.",
+	"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:H/A:H"}],
+	"affected": [
+		{
+			"package": {"ecosystem": "npm", "name": "lodash"},
+			"ranges": [{"type": "SEMVER", "events": [{"fixed": "4.17.20"}, {"introduced": "3.7.0"}]}]
+		},
+		{
+			"package": {"ecosystem": "PyPI", "name": "Py_Lodash.Port"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"last_affected": "1.2"}]}],
+			"versions": ["1.0", "1.1", "1.2"]
+		},
+		{
+			"package": {"ecosystem": "npm", "name": "lodash-git"},
+			"ranges": [{"type": "GIT", "repo": "https://github.com/lodash/lodash", "events": [{"introduced": "0"}, {"fixed": "c84fe82"}]}]
+		},
+		{
+			"package": {"ecosystem": "Debian:11", "name": "node-lodash"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+		}
+	]
+}`
+
+func TestParseAdvisory(t *testing.T) {
+	vulnerability, ok, err := ParseAdvisory(strings.NewReader(testAdvisory))
+	if err != nil {
+		t.Fatalf("unexpected error parsing advisory: %s", err)
+	}
+	if !ok {
+		t.Fatalf("expected advisory to affect a supported package")
+	}
+
+	published := time.Date(2020, 7, 15, 19, 15, 0, 0, time.UTC)
+	modified := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	expected := shared.Vulnerability{
+		SourceID:    "GHSA-p6mc-m468-83gw",
+		Aliases:     []string{"CVE-2020-8203"},
+		Summary:     "Prototype Pollution in lodash",
+		Details:     "Prototype pollution attack when using _.zipObjectDeep in lodash before 4.17.20.",
+		Severity:    "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:H/A:H",
+		PublishedAt: &published,
+		ModifiedAt:  &modified,
+		AffectedPackages: []shared.AffectedPackage{
+			{
+				PackageScheme: shared.NpmPackagesScheme,
+				PackageName:   "lodash",
+				Ranges:        []shared.VersionRange{{Introduced: "3.7.0", Fixed: "4.17.20"}},
+			},
+			{
+				PackageScheme: shared.PythonPackagesScheme,
+				PackageName:   "py-lodash-port",
+				Versions:      []string{"1.0", "1.1", "1.2"},
+				Ranges:        []shared.VersionRange{{LastAffected: "1.2"}},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, vulnerability); diff != "" {
+		t.Errorf("unexpected vulnerability (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseAdvisoryUnsupportedEcosystem(t *testing.T) {
+	_, ok, err := ParseAdvisory(strings.NewReader(`{"id": "DSA-1234", "affected": [{"package": {"ecosystem": "Debian", "name": "openssl"}, "versions": ["1.1.1"]}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error parsing advisory: %s", err)
+	}
+	if ok {
+		t.Fatalf("expected advisory without supported packages to be skipped")
+	}
+}
+
+func TestReadAdvisories(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "GHSA-p6mc-m468-83gw.json"), []byte(testAdvisory), 0644); err != nil {
+		t.Fatalf("unexpected error writing advisory: %s", err)
+	}
+
+	archive, err := os.Create(filepath.Join(dir, "all.zip"))
+	if err != nil {
+		t.Fatalf("unexpected error creating archive: %s", err)
+	}
+	zw := zip.NewWriter(archive)
+	w, err := zw.Create("GO-2022-0001.json")
+	if err != nil {
+		t.Fatalf("unexpected error writing archive: %s", err)
+	}
+	if _, err := w.Write([]byte(`{"id": "GO-2022-0001", "affected": [{"package": {"ecosystem": "Go", "name": "github.com/example/lib"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.2.0"}]}]}]}`)); err != nil {
+		t.Fatalf("unexpected error writing archive: %s", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("unexpected error closing archive: %s", err)
+	}
+	archive.Close()
+
+	var sourceIDs []string
+	if err := ReadAdvisories(dir, func(vulnerability shared.Vulnerability) error {
+		sourceIDs = append(sourceIDs, vulnerability.SourceID)
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error reading advisories: %s", err)
+	}
+
+	if diff := cmp.Diff([]string{"GHSA-p6mc-m468-83gw", "GO-2022-0001"}, sourceIDs); diff != "" {
+		t.Errorf("unexpected advisories (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,181 @@
+package osv
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/shared"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+)
+
+var pythonSeparatorPattern = lazyregexp.New(`[-_.]+`)
+
+// Affected returns true if the given version of the package is affected.
+func Affected(pkg shared.AffectedPackage, version string) bool {
+	for _, affectedVersion := range pkg.Versions {
+		if CompareVersions(version, affectedVersion) == 0 {
+			return true
+		}
+	}
+
+	for _, r := range pkg.Ranges {
+		if r.Introduced != "" && CompareVersions(version, r.Introduced) < 0 {
+			continue
+		}
+		if r.Fixed != "" && CompareVersions(version, r.Fixed) >= 0 {
+			continue
+		}
+		if r.LastAffected != "" && CompareVersions(version, r.LastAffected) > 0 {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// CompareVersions returns -1, 0, or 1 if version a is before, equal to, or after version b.
+//
+// Versions are compared segment by segment, where a segment is a maximal run of digits or
+// of letters. This approximates the orderings of semver (Go, npm, crates.io), PEP 440 (PyPI),
+// RubyGems, and Maven closely enough for advisory matching: numeric segments compare by value,
+// and a trailing pre-release segment (e.g., `-rc.1`, `b2`) orders before the release itself
+// while a trailing post-release segment (e.g., `.post1`, `-sp1`) orders after it. Build
+// metadata and local version labels following `+` are ignored.
+func CompareVersions(a, b string) int {
+	as, bs := segments(a), segments(b)
+
+	i, j := 0, 0
+	for i < len(as) || j < len(bs) {
+		// Zero segments are insignificant unless compared to another number (1.0 == 1.0.0)
+		if i < len(as) && as[i].isZero() && (j >= len(bs) || !bs[j].numeric) {
+			i++
+			continue
+		}
+		if j < len(bs) && bs[j].isZero() && (i >= len(as) || !as[i].numeric) {
+			j++
+			continue
+		}
+
+		if i >= len(as) {
+			return -tailOrder(bs[j])
+		}
+		if j >= len(bs) {
+			return tailOrder(as[i])
+		}
+		if cmp := compareSegments(as[i], bs[j]); cmp != 0 {
+			return cmp
+		}
+
+		i++
+		j++
+	}
+
+	return 0
+}
+
+type segment struct {
+	numeric bool
+	number  uint64
+	text    string
+}
+
+func (s segment) isZero() bool {
+	return s.numeric && s.number == 0
+}
+
+func segments(version string) []segment {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.Index(version, "+"); i >= 0 {
+		version = version[:i]
+	}
+
+	var segs []segment
+	for len(version) > 0 {
+		r := rune(version[0])
+
+		var n int
+		switch {
+		case unicode.IsDigit(r):
+			for n < len(version) && unicode.IsDigit(rune(version[n])) {
+				n++
+			}
+			number, _ := strconv.ParseUint(version[:n], 10, 64)
+			segs = append(segs, segment{numeric: true, number: number})
+
+		case unicode.IsLetter(r):
+			for n < len(version) && unicode.IsLetter(rune(version[n])) {
+				n++
+			}
+			if text := strings.ToLower(version[:n]); !releaseMarkers[text] {
+				segs = append(segs, segment{text: text})
+			}
+
+		default:
+			// Separators only delimit segments
+			n = 1
+		}
+
+		version = version[n:]
+	}
+
+	return segs
+}
+
+// releaseMarkers are qualifiers that denote a final release and are ignored.
+var releaseMarkers = map[string]bool{
+	"final":   true,
+	"ga":      true,
+	"release": true,
+}
+
+// postReleaseMarkers are qualifiers that order after the release they qualify.
+var postReleaseMarkers = map[string]bool{
+	"post":  true,
+	"patch": true,
+	"pl":    true,
+	"sp":    true,
+	"p":     true,
+	"r":     true,
+}
+
+// tailOrder returns the order of a version relative to its prefix, given the first segment
+// that extends the prefix: 1 if the version is after the prefix, and -1 if it is before.
+func tailOrder(s segment) int {
+	if s.numeric || postReleaseMarkers[s.text] {
+		return 1
+	}
+
+	return -1
+}
+
+func compareSegments(a, b segment) int {
+	switch {
+	case a.numeric && b.numeric:
+		if a.number < b.number {
+			return -1
+		}
+		if a.number > b.number {
+			return 1
+		}
+		return 0
+
+	case a.numeric != b.numeric:
+		// Numeric segments order after any qualifier (1.0.1 > 1.0rc1 and 1.0.1 > 1.0.post1)
+		if a.numeric {
+			return 1
+		}
+		return -1
+	}
+
+	if pa, pb := postReleaseMarkers[a.text], postReleaseMarkers[b.text]; pa != pb {
+		if pa {
+			return 1
+		}
+		return -1
+	}
+
+	return strings.Compare(a.text, b.text)
+}
@@ -0,0 +1,71 @@
+package osv
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/shared"
+)
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.0", "1.0.0", 0},
+		{"1.2.3+build.5", "1.2.3", 0},
+		{"1.2.3", "1.2.10", -1},
+		{"1.10.0", "1.9.9", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0rc1", "1.0", -1},
+		{"1.0.post1", "1.0", 1},
+		{"1.0.post1", "1.0.1", -1},
+		{"1.0.0.Final", "1.0.0", 0},
+		{"2.0-SNAPSHOT", "2.0", -1},
+		{"0", "0.0.1", -1},
+		{"v0.0.0-20220101000000-abcdef123456", "v0.0.0-20220201000000-abcdef123456", -1},
+	}
+
+	for _, testCase := range testCases {
+		if cmp := CompareVersions(testCase.a, testCase.b); cmp != testCase.expected {
+			t.Errorf("unexpected comparison of %q and %q. want=%d have=%d", testCase.a, testCase.b, testCase.expected, cmp)
+		}
+		if cmp := CompareVersions(testCase.b, testCase.a); cmp != -testCase.expected {
+			t.Errorf("unexpected comparison of %q and %q. want=%d have=%d", testCase.b, testCase.a, -testCase.expected, cmp)
+		}
+	}
+}
+
+func TestAffected(t *testing.T) {
+	pkg := shared.AffectedPackage{
+		PackageScheme: shared.NpmPackagesScheme,
+		PackageName:   "lodash",
+		Versions:      []string{"3.0.0-beta.1"},
+		Ranges: []shared.VersionRange{
+			{Fixed: "1.0.1"},
+			{Introduced: "2.0.0", Fixed: "2.4.2"},
+			{Introduced: "4.0.0", LastAffected: "4.17.20"},
+			{Introduced: "6.0.0"},
+		},
+	}
+
+	for version, expected := range map[string]bool{
+		"0.9.0":        true,
+		"1.0.1":        false,
+		"1.5.0":        false,
+		"2.0.0":        true,
+		"2.4.1":        true,
+		"2.4.2":        false,
+		"3.0.0-beta.1": true,
+		"3.0.0":        false,
+		"4.17.20":      true,
+		"4.17.21":      false,
+		"7.1.0":        true,
+	} {
+		if affected := Affected(pkg, version); affected != expected {
+			t.Errorf("unexpected result for version %s. want=%v have=%v", version, expected, affected)
+		}
+	}
+}
@@ -8,16 +8,22 @@ import (
 )
 
 type operations struct {
-	deleteDependencyReposByID    *observation.Operation
-	listDependencyRepos          *observation.Operation
-	lockfileDependencies         *observation.Operation
-	lockfileDependents           *observation.Operation
-	preciseDependencies          *observation.Operation
-	preciseDependents            *observation.Operation
-	selectRepoRevisionsToResolve *observation.Operation
-	updateResolvedRevisions      *observation.Operation
-	upsertDependencyRepos        *observation.Operation
-	upsertLockfileDependencies   *observation.Operation
+	deleteDependencyReposByID         *observation.Operation
+	listDependencyRepos               *observation.Operation
+	lockfileDependencies              *observation.Operation
+	listVulnerabilityAffectedPackages *observation.Operation
+	listVulnerabilityMatches          *observation.Operation
+	lockfileDependents                *observation.Operation
+	preciseDependencies               *observation.Operation
+	preciseDependents                 *observation.Operation
+	selectRepoRevisionsToResolve      *observation.Operation
+	updateResolvedRevisions           *observation.Operation
+	upsertDependencyRepos             *observation.Operation
+	updateVulnerabilityMatches        *observation.Operation
+	upsertLockfileDependencies        *observation.Operation
+	upsertVulnerabilities             *observation.Operation
+	vulnerabilityMatchCandidates      *observation.Operation
+	vulnerableRepositoryNames         *observation.Operation
 }
 
 func newOperations(observationContext *observation.Context) *operations {
@@ -37,15 +43,21 @@ func newOperations(observationContext *observation.Context) *operations {
 	}
 
 	return &operations{
-		deleteDependencyReposByID:    op("DeleteDependencyReposByID"),
-		listDependencyRepos:          op("ListDependencyRepos"),
-		lockfileDependencies:         op("LockfileDependencies"),
-		lockfileDependents:           op("LockfileDependents"),
-		preciseDependencies:          op("PreciseDependencies"),
-		preciseDependents:            op("PreciseDependents"),
-		selectRepoRevisionsToResolve: op("SelectRepoRevisionsToResolve"),
-		updateResolvedRevisions:      op("UpdateResolvedRevisions"),
-		upsertDependencyRepos:        op("UpsertDependencyRepos"),
-		upsertLockfileDependencies:   op("UpsertLockfileDependencies"),
+		deleteDependencyReposByID:         op("DeleteDependencyReposByID"),
+		listDependencyRepos:               op("ListDependencyRepos"),
+		lockfileDependencies:              op("LockfileDependencies"),
+		listVulnerabilityAffectedPackages: op("ListVulnerabilityAffectedPackages"),
+		listVulnerabilityMatches:          op("ListVulnerabilityMatches"),
+		lockfileDependents:                op("LockfileDependents"),
+		preciseDependencies:               op("PreciseDependencies"),
+		preciseDependents:                 op("PreciseDependents"),
+		selectRepoRevisionsToResolve:      op("SelectRepoRevisionsToResolve"),
+		updateResolvedRevisions:           op("UpdateResolvedRevisions"),
+		upsertDependencyRepos:             op("UpsertDependencyRepos"),
+		updateVulnerabilityMatches:        op("UpdateVulnerabilityMatches"),
+		upsertLockfileDependencies:        op("UpsertLockfileDependencies"),
+		upsertVulnerabilities:             op("UpsertVulnerabilities"),
+		vulnerabilityMatchCandidates:      op("VulnerabilityMatchCandidates"),
+		vulnerableRepositoryNames:         op("VulnerableRepositoryNames"),
 	}
 }
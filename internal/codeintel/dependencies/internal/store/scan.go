@@ -2,6 +2,9 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
+
+	"github.com/lib/pq"
 
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/shared"
@@ -98,3 +101,54 @@ func scanIdNames(rows *sql.Rows, queryErr error) (nameIDs map[string]int, ids []
 
 	return nameIDs, ids, nil
 }
+
+//
+// Scans `[]shared.AffectedPackage`
+
+var scanAffectedPackages = basestore.NewSliceScanner(func(s dbutil.Scanner) (shared.AffectedPackage, error) {
+	var (
+		v      shared.AffectedPackage
+		ranges []byte
+	)
+	if err := s.Scan(&v.ID, &v.PackageScheme, &v.PackageName, pq.Array(&v.Versions), &ranges); err != nil {
+		return v, err
+	}
+
+	err := json.Unmarshal(ranges, &v.Ranges)
+	return v, err
+})
+
+//
+// Scans `[]shared.VulnerabilityMatchCandidate`
+
+var scanVulnerabilityMatchCandidates = basestore.NewSliceScanner(func(s dbutil.Scanner) (shared.VulnerabilityMatchCandidate, error) {
+	var v shared.VulnerabilityMatchCandidate
+	err := s.Scan(&v.AffectedPackageID, &v.LockfileReferenceID, &v.PackageVersion)
+	return v, err
+})
+
+//
+// Scans `[]shared.VulnerabilityMatch`, count
+
+var scanVulnerabilityMatchesWithCount = basestore.NewSliceWithCountScanner(func(s dbutil.Scanner) (v shared.VulnerabilityMatch, count int, _ error) {
+	err := s.Scan(
+		&v.Vulnerability.ID,
+		&v.Vulnerability.SourceID,
+		pq.Array(&v.Vulnerability.Aliases),
+		&v.Vulnerability.Summary,
+		&v.Vulnerability.Details,
+		&v.Vulnerability.Severity,
+		&v.Vulnerability.PublishedAt,
+		&v.Vulnerability.ModifiedAt,
+		&v.Vulnerability.WithdrawnAt,
+		&v.RepositoryID,
+		&v.RepositoryName,
+		&dbutil.NullString{S: &v.Commit},
+		&dbutil.NullString{S: &v.Lockfile},
+		&v.PackageScheme,
+		&v.PackageName,
+		&v.PackageVersion,
+		&count,
+	)
+	return v, count, err
+})
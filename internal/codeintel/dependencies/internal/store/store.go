@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/keegancsmith/sqlf"
@@ -31,6 +32,12 @@ type Store interface {
 	ListDependencyRepos(ctx context.Context, opts ListDependencyReposOpts) (dependencyRepos []shared.Repo, err error)
 	UpsertDependencyRepos(ctx context.Context, deps []shared.Repo) (newDeps []shared.Repo, err error)
 	DeleteDependencyReposByID(ctx context.Context, ids ...int) (err error)
+	UpsertVulnerabilities(ctx context.Context, vulnerabilities []shared.Vulnerability) (err error)
+	ListVulnerabilityAffectedPackages(ctx context.Context, afterID, limit int) (pkgs []shared.AffectedPackage, err error)
+	VulnerabilityMatchCandidates(ctx context.Context, affectedPackageIDs []int) (candidates []shared.VulnerabilityMatchCandidate, err error)
+	UpdateVulnerabilityMatches(ctx context.Context, affectedPackageIDs []int, matches []shared.VulnerabilityMatchCandidate) (err error)
+	ListVulnerabilityMatches(ctx context.Context, opts ListVulnerabilityMatchesOpts) (matches []shared.VulnerabilityMatch, totalCount int, err error)
+	VulnerableRepositoryNames(ctx context.Context, vulnerabilityID string) (names []string, err error)
 }
 
 // store manages the database tables for package dependencies.
@@ -709,6 +716,334 @@ DELETE FROM lsif_dependency_repos
 WHERE id = ANY(%s)
 `
 
+// UpsertVulnerabilities inserts or updates the given vulnerabilities and their affected packages.
+// Vulnerabilities whose modification time is unchanged since the last import are not rewritten.
+func (s *store) UpsertVulnerabilities(ctx context.Context, vulnerabilities []shared.Vulnerability) (err error) {
+	ctx, _, endObservation := s.operations.upsertVulnerabilities.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("numVulnerabilities", len(vulnerabilities)),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	tx, err := s.Transact(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { err = tx.db.Done(err) }()
+
+	for _, v := range vulnerabilities {
+		id, ok, err := basestore.ScanFirstInt(tx.db.Query(ctx, sqlf.Sprintf(
+			upsertVulnerabilityQuery,
+			v.SourceID,
+			pq.Array(emptyIfNil(v.Aliases)),
+			v.Summary,
+			v.Details,
+			v.Severity,
+			v.PublishedAt,
+			v.ModifiedAt,
+			v.WithdrawnAt,
+		)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// Unchanged since the last import
+			continue
+		}
+
+		if err := tx.db.Exec(ctx, sqlf.Sprintf(deleteVulnerabilityAffectedPackagesQuery, id)); err != nil {
+			return err
+		}
+
+		if err := batch.WithInserter(
+			ctx,
+			tx.db.Handle(),
+			"vulnerability_affected_packages",
+			batch.MaxNumPostgresParameters,
+			[]string{"vulnerability_id", "package_scheme", "package_name", "versions", "ranges"},
+			func(inserter *batch.Inserter) error {
+				for _, pkg := range v.AffectedPackages {
+					ranges := pkg.Ranges
+					if ranges == nil {
+						ranges = []shared.VersionRange{}
+					}
+					serializedRanges, err := json.Marshal(ranges)
+					if err != nil {
+						return err
+					}
+
+					if err := inserter.Insert(
+						ctx,
+						id,
+						pkg.PackageScheme,
+						pkg.PackageName,
+						pq.Array(emptyIfNil(pkg.Versions)),
+						string(serializedRanges),
+					); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const upsertVulnerabilityQuery = `
+-- source: internal/codeintel/dependencies/internal/store/store.go:UpsertVulnerabilities
+INSERT INTO vulnerabilities (source_id, aliases, summary, details, severity, published_at, modified_at, withdrawn_at)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
+ON CONFLICT (source_id) DO UPDATE SET
+	aliases = EXCLUDED.aliases,
+	summary = EXCLUDED.summary,
+	details = EXCLUDED.details,
+	severity = EXCLUDED.severity,
+	published_at = EXCLUDED.published_at,
+	modified_at = EXCLUDED.modified_at,
+	withdrawn_at = EXCLUDED.withdrawn_at
+WHERE
+	vulnerabilities.modified_at IS NULL OR
+	vulnerabilities.modified_at IS DISTINCT FROM EXCLUDED.modified_at
+RETURNING id
+`
+
+const deleteVulnerabilityAffectedPackagesQuery = `
+-- source: internal/codeintel/dependencies/internal/store/store.go:UpsertVulnerabilities
+DELETE FROM vulnerability_affected_packages
+WHERE vulnerability_id = %s
+`
+
+func emptyIfNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+
+	return values
+}
+
+// ListVulnerabilityAffectedPackages returns a page of affected packages ordered by identifier,
+// starting after the given identifier.
+func (s *store) ListVulnerabilityAffectedPackages(ctx context.Context, afterID, limit int) (pkgs []shared.AffectedPackage, err error) {
+	ctx, _, endObservation := s.operations.listVulnerabilityAffectedPackages.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("afterID", afterID),
+		log.Int("limit", limit),
+	}})
+	defer func() {
+		endObservation(1, observation.Args{LogFields: []log.Field{
+			log.Int("numAffectedPackages", len(pkgs)),
+		}})
+	}()
+
+	return scanAffectedPackages(s.db.Query(ctx, sqlf.Sprintf(listVulnerabilityAffectedPackagesQuery, afterID, makeLimit(limit))))
+}
+
+const listVulnerabilityAffectedPackagesQuery = `
+-- source: internal/codeintel/dependencies/internal/store/store.go:ListVulnerabilityAffectedPackages
+SELECT id, package_scheme, package_name, versions, ranges
+FROM vulnerability_affected_packages
+WHERE id > %s
+ORDER BY id
+%s
+`
+
+// VulnerabilityMatchCandidates returns the resolved lockfile dependencies that refer to any of the
+// given affected packages by name. Dependencies on packages affected only by withdrawn vulnerabilities
+// are not returned. The caller is expected to filter the candidates by version.
+func (s *store) VulnerabilityMatchCandidates(ctx context.Context, affectedPackageIDs []int) (candidates []shared.VulnerabilityMatchCandidate, err error) {
+	ctx, _, endObservation := s.operations.vulnerabilityMatchCandidates.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("numAffectedPackageIDs", len(affectedPackageIDs)),
+	}})
+	defer func() {
+		endObservation(1, observation.Args{LogFields: []log.Field{
+			log.Int("numCandidates", len(candidates)),
+		}})
+	}()
+
+	if len(affectedPackageIDs) == 0 {
+		return nil, nil
+	}
+
+	return scanVulnerabilityMatchCandidates(s.db.Query(ctx, sqlf.Sprintf(
+		vulnerabilityMatchCandidatesQuery,
+		shared.PythonPackagesScheme,
+		pq.Array(affectedPackageIDs),
+	)))
+}
+
+const vulnerabilityMatchCandidatesQuery = `
+-- source: internal/codeintel/dependencies/internal/store/store.go:VulnerabilityMatchCandidates
+SELECT vap.id, r.id, r.package_version
+FROM vulnerability_affected_packages vap
+JOIN vulnerabilities v ON v.id = vap.vulnerability_id
+JOIN codeintel_lockfile_references r
+ON
+	r.package_scheme = vap.package_scheme AND
+	-- Affected package names are normalized on import (see osv.NormalizePackageName)
+	vap.package_name = CASE
+		WHEN r.package_scheme = %s THEN regexp_replace(lower(r.package_name), '[-_.]+', '-', 'g')
+		ELSE r.package_name
+	END
+WHERE
+	vap.id = ANY(%s) AND
+	v.withdrawn_at IS NULL AND
+	r.resolution_repository_id IS NOT NULL
+ORDER BY vap.id, r.id
+`
+
+// UpdateVulnerabilityMatches replaces the matches of the given affected packages.
+func (s *store) UpdateVulnerabilityMatches(ctx context.Context, affectedPackageIDs []int, matches []shared.VulnerabilityMatchCandidate) (err error) {
+	ctx, _, endObservation := s.operations.updateVulnerabilityMatches.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("numAffectedPackageIDs", len(affectedPackageIDs)),
+		log.Int("numMatches", len(matches)),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	tx, err := s.Transact(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { err = tx.db.Done(err) }()
+
+	if err := tx.db.Exec(ctx, sqlf.Sprintf(deleteVulnerabilityMatchesQuery, pq.Array(affectedPackageIDs))); err != nil {
+		return err
+	}
+
+	return batch.WithInserter(
+		ctx,
+		tx.db.Handle(),
+		"vulnerability_matches",
+		batch.MaxNumPostgresParameters,
+		[]string{"vulnerability_affected_package_id", "codeintel_lockfile_reference_id"},
+		func(inserter *batch.Inserter) error {
+			for _, match := range matches {
+				if err := inserter.Insert(ctx, match.AffectedPackageID, match.LockfileReferenceID); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	)
+}
+
+const deleteVulnerabilityMatchesQuery = `
+-- source: internal/codeintel/dependencies/internal/store/store.go:UpdateVulnerabilityMatches
+DELETE FROM vulnerability_matches
+WHERE vulnerability_affected_package_id = ANY(%s)
+`
+
+// ListVulnerabilityMatchesOpts are options for listing vulnerability matches.
+type ListVulnerabilityMatchesOpts struct {
+	// Query filters matches by a case-insensitive substring of the vulnerability identifier,
+	// any of its aliases, or the name of the affected package.
+	Query        string
+	RepositoryID int
+	Limit        int
+	Offset       int
+}
+
+// ListVulnerabilityMatches returns a page of vulnerable lockfile dependencies along with the
+// total number of matches.
+func (s *store) ListVulnerabilityMatches(ctx context.Context, opts ListVulnerabilityMatchesOpts) (matches []shared.VulnerabilityMatch, totalCount int, err error) {
+	ctx, _, endObservation := s.operations.listVulnerabilityMatches.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("query", opts.Query),
+		log.Int("repositoryID", opts.RepositoryID),
+	}})
+	defer func() {
+		endObservation(1, observation.Args{LogFields: []log.Field{
+			log.Int("numMatches", len(matches)),
+			log.Int("totalCount", totalCount),
+		}})
+	}()
+
+	conds := make([]*sqlf.Query, 0, 3)
+	conds = append(conds, sqlf.Sprintf("repo.deleted_at IS NULL"))
+	if opts.Query != "" {
+		pattern := "%" + opts.Query + "%"
+		conds = append(conds, sqlf.Sprintf(
+			"(v.source_id ILIKE %s OR EXISTS (SELECT 1 FROM unnest(v.aliases) alias WHERE alias ILIKE %s) OR r.package_name ILIKE %s)",
+			pattern, pattern, pattern,
+		))
+	}
+	if opts.RepositoryID != 0 {
+		conds = append(conds, sqlf.Sprintf("repo.id = %s", opts.RepositoryID))
+	}
+
+	return scanVulnerabilityMatchesWithCount(s.db.Query(ctx, sqlf.Sprintf(
+		listVulnerabilityMatchesQuery,
+		sqlf.Join(conds, "AND"),
+		makeLimit(opts.Limit),
+		opts.Offset,
+	)))
+}
+
+const listVulnerabilityMatchesQuery = `
+-- source: internal/codeintel/dependencies/internal/store/store.go:ListVulnerabilityMatches
+SELECT
+	v.id,
+	v.source_id,
+	v.aliases,
+	v.summary,
+	v.details,
+	v.severity,
+	v.published_at,
+	v.modified_at,
+	v.withdrawn_at,
+	repo.id,
+	repo.name,
+	encode(r.resolution_commit_bytea, 'hex'),
+	r.resolution_lockfile,
+	r.package_scheme,
+	r.package_name,
+	r.package_version,
+	COUNT(*) OVER() AS count
+FROM vulnerability_matches m
+JOIN vulnerability_affected_packages vap ON vap.id = m.vulnerability_affected_package_id
+JOIN vulnerabilities v ON v.id = vap.vulnerability_id
+JOIN codeintel_lockfile_references r ON r.id = m.codeintel_lockfile_reference_id
+JOIN repo ON repo.id = r.resolution_repository_id
+WHERE %s
+ORDER BY v.source_id, repo.name, r.resolution_lockfile, r.package_name, r.package_version, m.id
+%s
+OFFSET %s
+`
+
+// VulnerableRepositoryNames returns the names of repositories with a lockfile dependency matching
+// the given vulnerability identifier or alias. An empty identifier matches any vulnerability.
+func (s *store) VulnerableRepositoryNames(ctx context.Context, vulnerabilityID string) (names []string, err error) {
+	ctx, _, endObservation := s.operations.vulnerableRepositoryNames.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("vulnerabilityID", vulnerabilityID),
+	}})
+	defer func() {
+		endObservation(1, observation.Args{LogFields: []log.Field{
+			log.Int("numNames", len(names)),
+		}})
+	}()
+
+	cond := sqlf.Sprintf("TRUE")
+	if vulnerabilityID != "" {
+		cond = sqlf.Sprintf("(lower(v.source_id) = lower(%s) OR lower(%s) = ANY(SELECT lower(alias) FROM unnest(v.aliases) alias))", vulnerabilityID, vulnerabilityID)
+	}
+
+	return basestore.ScanStrings(s.db.Query(ctx, sqlf.Sprintf(vulnerableRepositoryNamesQuery, cond)))
+}
+
+const vulnerableRepositoryNamesQuery = `
+-- source: internal/codeintel/dependencies/internal/store/store.go:VulnerableRepositoryNames
+SELECT DISTINCT repo.name
+FROM vulnerability_matches m
+JOIN vulnerability_affected_packages vap ON vap.id = m.vulnerability_affected_package_id
+JOIN vulnerabilities v ON v.id = vap.vulnerability_id
+JOIN codeintel_lockfile_references r ON r.id = m.codeintel_lockfile_reference_id
+JOIN repo ON repo.id = r.resolution_repository_id
+WHERE repo.deleted_at IS NULL AND %s
+ORDER BY repo.name
+`
+
 // Transact returns a store in a transaction.
 func (s *store) Transact(ctx context.Context) (*store, error) {
 	txBase, err := s.db.Transact(ctx)
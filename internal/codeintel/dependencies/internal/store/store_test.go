@@ -1003,3 +1003,107 @@ func TestLockfileDependents(t *testing.T) {
 		t.Errorf("unexpected lockfile dependents (-want +got):\n%s", diff)
 	}
 }
+
+func TestVulnerabilities(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	logger := logtest.Scoped(t)
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+	store := New(db, &observation.TestContext)
+
+	for _, repo := range []string{"repo-1", "repo-2"} {
+		if err := store.db.Exec(ctx, sqlf.Sprintf(`INSERT INTO repo (name) VALUES (%s)`, repo)); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	var (
+		packageA = shared.TestPackageDependencyLiteral(api.RepoName("pkg-a"), "v1.2.0", "npm", "left-pad", "1.2.0")
+		packageB = shared.TestPackageDependencyLiteral(api.RepoName("pkg-b"), "v2.0.0", "python", "Django_Utils", "2.0.0")
+	)
+	if err := store.UpsertLockfileGraph(ctx, "repo-1", "cafebabe", "package-lock.json", []shared.PackageDependency{packageA}, nil); err != nil {
+		t.Fatalf("unexpected error upserting lockfile dependencies: %s", err)
+	}
+	if err := store.UpsertLockfileGraph(ctx, "repo-2", "deadbeef", "poetry.lock", []shared.PackageDependency{packageB}, nil); err != nil {
+		t.Fatalf("unexpected error upserting lockfile dependencies: %s", err)
+	}
+
+	modified := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	vulnerabilities := []shared.Vulnerability{
+		{
+			SourceID:   "GHSA-0001",
+			Aliases:    []string{"CVE-2022-0001"},
+			Summary:    "left-pad pads too much",
+			ModifiedAt: &modified,
+			AffectedPackages: []shared.AffectedPackage{
+				{PackageScheme: "npm", PackageName: "left-pad", Ranges: []shared.VersionRange{{Fixed: "1.3.0"}}},
+			},
+		},
+		{
+			SourceID:   "PYSEC-0002",
+			ModifiedAt: &modified,
+			AffectedPackages: []shared.AffectedPackage{
+				{PackageScheme: "python", PackageName: "django-utils", Versions: []string{"2.0.0"}},
+			},
+		},
+	}
+	if err := store.UpsertVulnerabilities(ctx, vulnerabilities); err != nil {
+		t.Fatalf("unexpected error upserting vulnerabilities: %s", err)
+	}
+	// Re-importing unchanged vulnerabilities must not rewrite affected packages
+	if err := store.UpsertVulnerabilities(ctx, vulnerabilities); err != nil {
+		t.Fatalf("unexpected error upserting vulnerabilities: %s", err)
+	}
+
+	pkgs, err := store.ListVulnerabilityAffectedPackages(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("unexpected error listing affected packages: %s", err)
+	}
+	if len(pkgs) != 2 {
+		t.Fatalf("unexpected number of affected packages. want=%d have=%d", 2, len(pkgs))
+	}
+	if diff := cmp.Diff([]shared.VersionRange{{Fixed: "1.3.0"}}, pkgs[0].Ranges); diff != "" {
+		t.Errorf("unexpected ranges (-want +got):\n%s", diff)
+	}
+
+	ids := []int{pkgs[0].ID, pkgs[1].ID}
+	candidates, err := store.VulnerabilityMatchCandidates(ctx, ids)
+	if err != nil {
+		t.Fatalf("unexpected error listing match candidates: %s", err)
+	}
+	if len(candidates) != 2 {
+		t.Fatalf("unexpected number of candidates. want=%d have=%d", 2, len(candidates))
+	}
+	if err := store.UpdateVulnerabilityMatches(ctx, ids, candidates); err != nil {
+		t.Fatalf("unexpected error updating matches: %s", err)
+	}
+
+	matches, totalCount, err := store.ListVulnerabilityMatches(ctx, ListVulnerabilityMatchesOpts{Query: "cve-2022"})
+	if err != nil {
+		t.Fatalf("unexpected error listing matches: %s", err)
+	}
+	if totalCount != 1 || len(matches) != 1 {
+		t.Fatalf("unexpected number of matches. want=%d have=%d (total=%d)", 1, len(matches), totalCount)
+	}
+	if matches[0].Vulnerability.SourceID != "GHSA-0001" || matches[0].RepositoryName != "repo-1" || matches[0].Commit != "cafebabe" || matches[0].PackageVersion != "1.2.0" {
+		t.Errorf("unexpected match: %+v", matches[0])
+	}
+
+	for vulnerabilityID, expectedNames := range map[string][]string{
+		"":              {"repo-1", "repo-2"},
+		"cve-2022-0001": {"repo-1"},
+		"PYSEC-0002":    {"repo-2"},
+		"GHSA-9999":     nil,
+	} {
+		names, err := store.VulnerableRepositoryNames(ctx, vulnerabilityID)
+		if err != nil {
+			t.Fatalf("unexpected error listing vulnerable repositories: %s", err)
+		}
+		if diff := cmp.Diff(expectedNames, names); diff != "" {
+			t.Errorf("unexpected repository names for %q (-want +got):\n%s", vulnerabilityID, diff)
+		}
+	}
+}
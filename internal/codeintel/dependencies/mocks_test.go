@@ -504,6 +504,13 @@ type MockStore struct {
 	// ListDependencyReposFunc is an instance of a mock function object
 	// controlling the behavior of the method ListDependencyRepos.
 	ListDependencyReposFunc *StoreListDependencyReposFunc
+	// ListVulnerabilityAffectedPackagesFunc is an instance of a mock
+	// function object controlling the behavior of the method
+	// ListVulnerabilityAffectedPackages.
+	ListVulnerabilityAffectedPackagesFunc *StoreListVulnerabilityAffectedPackagesFunc
+	// ListVulnerabilityMatchesFunc is an instance of a mock function object
+	// controlling the behavior of the method ListVulnerabilityMatches.
+	ListVulnerabilityMatchesFunc *StoreListVulnerabilityMatchesFunc
 	// LockfileDependenciesFunc is an instance of a mock function object
 	// controlling the behavior of the method LockfileDependencies.
 	LockfileDependenciesFunc *StoreLockfileDependenciesFunc
@@ -523,12 +530,27 @@ type MockStore struct {
 	// UpdateResolvedRevisionsFunc is an instance of a mock function object
 	// controlling the behavior of the method UpdateResolvedRevisions.
 	UpdateResolvedRevisionsFunc *StoreUpdateResolvedRevisionsFunc
+	// UpdateVulnerabilityMatchesFunc is an instance of a mock function
+	// object controlling the behavior of the method
+	// UpdateVulnerabilityMatches.
+	UpdateVulnerabilityMatchesFunc *StoreUpdateVulnerabilityMatchesFunc
 	// UpsertDependencyReposFunc is an instance of a mock function object
 	// controlling the behavior of the method UpsertDependencyRepos.
 	UpsertDependencyReposFunc *StoreUpsertDependencyReposFunc
 	// UpsertLockfileGraphFunc is an instance of a mock function object
 	// controlling the behavior of the method UpsertLockfileGraph.
 	UpsertLockfileGraphFunc *StoreUpsertLockfileGraphFunc
+	// UpsertVulnerabilitiesFunc is an instance of a mock function object
+	// controlling the behavior of the method UpsertVulnerabilities.
+	UpsertVulnerabilitiesFunc *StoreUpsertVulnerabilitiesFunc
+	// VulnerabilityMatchCandidatesFunc is an instance of a mock function
+	// object controlling the behavior of the method
+	// VulnerabilityMatchCandidates.
+	VulnerabilityMatchCandidatesFunc *StoreVulnerabilityMatchCandidatesFunc
+	// VulnerableRepositoryNamesFunc is an instance of a mock function
+	// object controlling the behavior of the method
+	// VulnerableRepositoryNames.
+	VulnerableRepositoryNamesFunc *StoreVulnerableRepositoryNamesFunc
 }
 
 // NewMockStore creates a new mock of the Store interface. All methods
@@ -545,6 +567,16 @@ func NewMockStore() *MockStore {
 				return
 			},
 		},
+		ListVulnerabilityAffectedPackagesFunc: &StoreListVulnerabilityAffectedPackagesFunc{
+			defaultHook: func(context.Context, int, int) (r0 []shared.AffectedPackage, r1 error) {
+				return
+			},
+		},
+		ListVulnerabilityMatchesFunc: &StoreListVulnerabilityMatchesFunc{
+			defaultHook: func(context.Context, store.ListVulnerabilityMatchesOpts) (r0 []shared.VulnerabilityMatch, r1 int, r2 error) {
+				return
+			},
+		},
 		LockfileDependenciesFunc: &StoreLockfileDependenciesFunc{
 			defaultHook: func(context.Context, store.LockfileDependenciesOpts) (r0 []shared.PackageDependency, r1 bool, r2 error) {
 				return
@@ -575,6 +607,11 @@ func NewMockStore() *MockStore {
 				return
 			},
 		},
+		UpdateVulnerabilityMatchesFunc: &StoreUpdateVulnerabilityMatchesFunc{
+			defaultHook: func(context.Context, []int, []shared.VulnerabilityMatchCandidate) (r0 error) {
+				return
+			},
+		},
 		UpsertDependencyReposFunc: &StoreUpsertDependencyReposFunc{
 			defaultHook: func(context.Context, []shared.Repo) (r0 []shared.Repo, r1 error) {
 				return
@@ -585,6 +622,21 @@ func NewMockStore() *MockStore {
 				return
 			},
 		},
+		UpsertVulnerabilitiesFunc: &StoreUpsertVulnerabilitiesFunc{
+			defaultHook: func(context.Context, []shared.Vulnerability) (r0 error) {
+				return
+			},
+		},
+		VulnerabilityMatchCandidatesFunc: &StoreVulnerabilityMatchCandidatesFunc{
+			defaultHook: func(context.Context, []int) (r0 []shared.VulnerabilityMatchCandidate, r1 error) {
+				return
+			},
+		},
+		VulnerableRepositoryNamesFunc: &StoreVulnerableRepositoryNamesFunc{
+			defaultHook: func(context.Context, string) (r0 []string, r1 error) {
+				return
+			},
+		},
 	}
 }
 
@@ -602,6 +654,16 @@ func NewStrictMockStore() *MockStore {
 				panic("unexpected invocation of MockStore.ListDependencyRepos")
 			},
 		},
+		ListVulnerabilityAffectedPackagesFunc: &StoreListVulnerabilityAffectedPackagesFunc{
+			defaultHook: func(context.Context, int, int) ([]shared.AffectedPackage, error) {
+				panic("unexpected invocation of MockStore.ListVulnerabilityAffectedPackages")
+			},
+		},
+		ListVulnerabilityMatchesFunc: &StoreListVulnerabilityMatchesFunc{
+			defaultHook: func(context.Context, store.ListVulnerabilityMatchesOpts) ([]shared.VulnerabilityMatch, int, error) {
+				panic("unexpected invocation of MockStore.ListVulnerabilityMatches")
+			},
+		},
 		LockfileDependenciesFunc: &StoreLockfileDependenciesFunc{
 			defaultHook: func(context.Context, store.LockfileDependenciesOpts) ([]shared.PackageDependency, bool, error) {
 				panic("unexpected invocation of MockStore.LockfileDependencies")
@@ -632,6 +694,11 @@ func NewStrictMockStore() *MockStore {
 				panic("unexpected invocation of MockStore.UpdateResolvedRevisions")
 			},
 		},
+		UpdateVulnerabilityMatchesFunc: &StoreUpdateVulnerabilityMatchesFunc{
+			defaultHook: func(context.Context, []int, []shared.VulnerabilityMatchCandidate) error {
+				panic("unexpected invocation of MockStore.UpdateVulnerabilityMatches")
+			},
+		},
 		UpsertDependencyReposFunc: &StoreUpsertDependencyReposFunc{
 			defaultHook: func(context.Context, []shared.Repo) ([]shared.Repo, error) {
 				panic("unexpected invocation of MockStore.UpsertDependencyRepos")
@@ -642,6 +709,21 @@ func NewStrictMockStore() *MockStore {
 				panic("unexpected invocation of MockStore.UpsertLockfileGraph")
 			},
 		},
+		UpsertVulnerabilitiesFunc: &StoreUpsertVulnerabilitiesFunc{
+			defaultHook: func(context.Context, []shared.Vulnerability) error {
+				panic("unexpected invocation of MockStore.UpsertVulnerabilities")
+			},
+		},
+		VulnerabilityMatchCandidatesFunc: &StoreVulnerabilityMatchCandidatesFunc{
+			defaultHook: func(context.Context, []int) ([]shared.VulnerabilityMatchCandidate, error) {
+				panic("unexpected invocation of MockStore.VulnerabilityMatchCandidates")
+			},
+		},
+		VulnerableRepositoryNamesFunc: &StoreVulnerableRepositoryNamesFunc{
+			defaultHook: func(context.Context, string) ([]string, error) {
+				panic("unexpected invocation of MockStore.VulnerableRepositoryNames")
+			},
+		},
 	}
 }
 
@@ -655,6 +737,12 @@ func NewMockStoreFrom(i store.Store) *MockStore {
 		ListDependencyReposFunc: &StoreListDependencyReposFunc{
 			defaultHook: i.ListDependencyRepos,
 		},
+		ListVulnerabilityAffectedPackagesFunc: &StoreListVulnerabilityAffectedPackagesFunc{
+			defaultHook: i.ListVulnerabilityAffectedPackages,
+		},
+		ListVulnerabilityMatchesFunc: &StoreListVulnerabilityMatchesFunc{
+			defaultHook: i.ListVulnerabilityMatches,
+		},
 		LockfileDependenciesFunc: &StoreLockfileDependenciesFunc{
 			defaultHook: i.LockfileDependencies,
 		},
@@ -673,12 +761,24 @@ func NewMockStoreFrom(i store.Store) *MockStore {
 		UpdateResolvedRevisionsFunc: &StoreUpdateResolvedRevisionsFunc{
 			defaultHook: i.UpdateResolvedRevisions,
 		},
+		UpdateVulnerabilityMatchesFunc: &StoreUpdateVulnerabilityMatchesFunc{
+			defaultHook: i.UpdateVulnerabilityMatches,
+		},
 		UpsertDependencyReposFunc: &StoreUpsertDependencyReposFunc{
 			defaultHook: i.UpsertDependencyRepos,
 		},
 		UpsertLockfileGraphFunc: &StoreUpsertLockfileGraphFunc{
 			defaultHook: i.UpsertLockfileGraph,
 		},
+		UpsertVulnerabilitiesFunc: &StoreUpsertVulnerabilitiesFunc{
+			defaultHook: i.UpsertVulnerabilities,
+		},
+		VulnerabilityMatchCandidatesFunc: &StoreVulnerabilityMatchCandidatesFunc{
+			defaultHook: i.VulnerabilityMatchCandidates,
+		},
+		VulnerableRepositoryNamesFunc: &StoreVulnerableRepositoryNamesFunc{
+			defaultHook: i.VulnerableRepositoryNames,
+		},
 	}
 }
 
@@ -904,6 +1004,234 @@ func (c StoreListDependencyReposFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// StoreListVulnerabilityAffectedPackagesFunc describes the behavior when
+// the ListVulnerabilityAffectedPackages method of the parent MockStore
+// instance is invoked.
+type StoreListVulnerabilityAffectedPackagesFunc struct {
+	defaultHook func(context.Context, int, int) ([]shared.AffectedPackage, error)
+	hooks       []func(context.Context, int, int) ([]shared.AffectedPackage, error)
+	history     []StoreListVulnerabilityAffectedPackagesFuncCall
+	mutex       sync.Mutex
+}
+
+// ListVulnerabilityAffectedPackages delegates to the next hook function in
+// the queue and stores the parameter and result values of this invocation.
+func (m *MockStore) ListVulnerabilityAffectedPackages(v0 context.Context, v1 int, v2 int) ([]shared.AffectedPackage, error) {
+	r0, r1 := m.ListVulnerabilityAffectedPackagesFunc.nextHook()(v0, v1, v2)
+	m.ListVulnerabilityAffectedPackagesFunc.appendCall(StoreListVulnerabilityAffectedPackagesFuncCall{v0, v1, v2, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// ListVulnerabilityAffectedPackages method of the parent MockStore instance
+// is invoked and the hook queue is empty.
+func (f *StoreListVulnerabilityAffectedPackagesFunc) SetDefaultHook(hook func(context.Context, int, int) ([]shared.AffectedPackage, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// ListVulnerabilityAffectedPackages method of the parent MockStore instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *StoreListVulnerabilityAffectedPackagesFunc) PushHook(hook func(context.Context, int, int) ([]shared.AffectedPackage, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *StoreListVulnerabilityAffectedPackagesFunc) SetDefaultReturn(r0 []shared.AffectedPackage, r1 error) {
+	f.SetDefaultHook(func(context.Context, int, int) ([]shared.AffectedPackage, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *StoreListVulnerabilityAffectedPackagesFunc) PushReturn(r0 []shared.AffectedPackage, r1 error) {
+	f.PushHook(func(context.Context, int, int) ([]shared.AffectedPackage, error) {
+		return r0, r1
+	})
+}
+
+func (f *StoreListVulnerabilityAffectedPackagesFunc) nextHook() func(context.Context, int, int) ([]shared.AffectedPackage, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *StoreListVulnerabilityAffectedPackagesFunc) appendCall(r0 StoreListVulnerabilityAffectedPackagesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// StoreListVulnerabilityAffectedPackagesFuncCall objects describing the
+// invocations of this function.
+func (f *StoreListVulnerabilityAffectedPackagesFunc) History() []StoreListVulnerabilityAffectedPackagesFuncCall {
+	f.mutex.Lock()
+	history := make([]StoreListVulnerabilityAffectedPackagesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// StoreListVulnerabilityAffectedPackagesFuncCall is an object that
+// describes an invocation of method ListVulnerabilityAffectedPackages on an
+// instance of MockStore.
+type StoreListVulnerabilityAffectedPackagesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []shared.AffectedPackage
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c StoreListVulnerabilityAffectedPackagesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c StoreListVulnerabilityAffectedPackagesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
+// StoreListVulnerabilityMatchesFunc describes the behavior when the
+// ListVulnerabilityMatches method of the parent MockStore instance is
+// invoked.
+type StoreListVulnerabilityMatchesFunc struct {
+	defaultHook func(context.Context, store.ListVulnerabilityMatchesOpts) ([]shared.VulnerabilityMatch, int, error)
+	hooks       []func(context.Context, store.ListVulnerabilityMatchesOpts) ([]shared.VulnerabilityMatch, int, error)
+	history     []StoreListVulnerabilityMatchesFuncCall
+	mutex       sync.Mutex
+}
+
+// ListVulnerabilityMatches delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockStore) ListVulnerabilityMatches(v0 context.Context, v1 store.ListVulnerabilityMatchesOpts) ([]shared.VulnerabilityMatch, int, error) {
+	r0, r1, r2 := m.ListVulnerabilityMatchesFunc.nextHook()(v0, v1)
+	m.ListVulnerabilityMatchesFunc.appendCall(StoreListVulnerabilityMatchesFuncCall{v0, v1, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the
+// ListVulnerabilityMatches method of the parent MockStore instance is
+// invoked and the hook queue is empty.
+func (f *StoreListVulnerabilityMatchesFunc) SetDefaultHook(hook func(context.Context, store.ListVulnerabilityMatchesOpts) ([]shared.VulnerabilityMatch, int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// ListVulnerabilityMatches method of the parent MockStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *StoreListVulnerabilityMatchesFunc) PushHook(hook func(context.Context, store.ListVulnerabilityMatchesOpts) ([]shared.VulnerabilityMatch, int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *StoreListVulnerabilityMatchesFunc) SetDefaultReturn(r0 []shared.VulnerabilityMatch, r1 int, r2 error) {
+	f.SetDefaultHook(func(context.Context, store.ListVulnerabilityMatchesOpts) ([]shared.VulnerabilityMatch, int, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *StoreListVulnerabilityMatchesFunc) PushReturn(r0 []shared.VulnerabilityMatch, r1 int, r2 error) {
+	f.PushHook(func(context.Context, store.ListVulnerabilityMatchesOpts) ([]shared.VulnerabilityMatch, int, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *StoreListVulnerabilityMatchesFunc) nextHook() func(context.Context, store.ListVulnerabilityMatchesOpts) ([]shared.VulnerabilityMatch, int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *StoreListVulnerabilityMatchesFunc) appendCall(r0 StoreListVulnerabilityMatchesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of StoreListVulnerabilityMatchesFuncCall
+// objects describing the invocations of this function.
+func (f *StoreListVulnerabilityMatchesFunc) History() []StoreListVulnerabilityMatchesFuncCall {
+	f.mutex.Lock()
+	history := make([]StoreListVulnerabilityMatchesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// StoreListVulnerabilityMatchesFuncCall is an object that describes an
+// invocation of method ListVulnerabilityMatches on an instance of
+// MockStore.
+type StoreListVulnerabilityMatchesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 store.ListVulnerabilityMatchesOpts
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []shared.VulnerabilityMatch
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 int
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c StoreListVulnerabilityMatchesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c StoreListVulnerabilityMatchesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
 // StoreLockfileDependenciesFunc describes the behavior when the
 // LockfileDependencies method of the parent MockStore instance is invoked.
 type StoreLockfileDependenciesFunc struct {
@@ -1568,35 +1896,37 @@ func (c StoreUpdateResolvedRevisionsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
-// StoreUpsertDependencyReposFunc describes the behavior when the
-// UpsertDependencyRepos method of the parent MockStore instance is invoked.
-type StoreUpsertDependencyReposFunc struct {
-	defaultHook func(context.Context, []shared.Repo) ([]shared.Repo, error)
-	hooks       []func(context.Context, []shared.Repo) ([]shared.Repo, error)
-	history     []StoreUpsertDependencyReposFuncCall
+// StoreUpdateVulnerabilityMatchesFunc describes the behavior when the
+// UpdateVulnerabilityMatches method of the parent MockStore instance is
+// invoked.
+type StoreUpdateVulnerabilityMatchesFunc struct {
+	defaultHook func(context.Context, []int, []shared.VulnerabilityMatchCandidate) error
+	hooks       []func(context.Context, []int, []shared.VulnerabilityMatchCandidate) error
+	history     []StoreUpdateVulnerabilityMatchesFuncCall
 	mutex       sync.Mutex
 }
 
-// UpsertDependencyRepos delegates to the next hook function in the queue
-// and stores the parameter and result values of this invocation.
-func (m *MockStore) UpsertDependencyRepos(v0 context.Context, v1 []shared.Repo) ([]shared.Repo, error) {
-	r0, r1 := m.UpsertDependencyReposFunc.nextHook()(v0, v1)
-	m.UpsertDependencyReposFunc.appendCall(StoreUpsertDependencyReposFuncCall{v0, v1, r0, r1})
-	return r0, r1
+// UpdateVulnerabilityMatches delegates to the next hook function in the
+// queue and stores the parameter and result values of this invocation.
+func (m *MockStore) UpdateVulnerabilityMatches(v0 context.Context, v1 []int, v2 []shared.VulnerabilityMatchCandidate) error {
+	r0 := m.UpdateVulnerabilityMatchesFunc.nextHook()(v0, v1, v2)
+	m.UpdateVulnerabilityMatchesFunc.appendCall(StoreUpdateVulnerabilityMatchesFuncCall{v0, v1, v2, r0})
+	return r0
 }
 
 // SetDefaultHook sets function that is called when the
-// UpsertDependencyRepos method of the parent MockStore instance is invoked
-// and the hook queue is empty.
-func (f *StoreUpsertDependencyReposFunc) SetDefaultHook(hook func(context.Context, []shared.Repo) ([]shared.Repo, error)) {
+// UpdateVulnerabilityMatches method of the parent MockStore instance is
+// invoked and the hook queue is empty.
+func (f *StoreUpdateVulnerabilityMatchesFunc) SetDefaultHook(hook func(context.Context, []int, []shared.VulnerabilityMatchCandidate) error) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// UpsertDependencyRepos method of the parent MockStore instance invokes the
-// hook at the front of the queue and discards it. After the queue is empty,
-// the default hook function is invoked for any future action.
-func (f *StoreUpsertDependencyReposFunc) PushHook(hook func(context.Context, []shared.Repo) ([]shared.Repo, error)) {
+// UpdateVulnerabilityMatches method of the parent MockStore instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *StoreUpdateVulnerabilityMatchesFunc) PushHook(hook func(context.Context, []int, []shared.VulnerabilityMatchCandidate) error) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -1604,20 +1934,20 @@ func (f *StoreUpsertDependencyReposFunc) PushHook(hook func(context.Context, []s
 
 // SetDefaultReturn calls SetDefaultHook with a function that returns the
 // given values.
-func (f *StoreUpsertDependencyReposFunc) SetDefaultReturn(r0 []shared.Repo, r1 error) {
-	f.SetDefaultHook(func(context.Context, []shared.Repo) ([]shared.Repo, error) {
-		return r0, r1
+func (f *StoreUpdateVulnerabilityMatchesFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, []int, []shared.VulnerabilityMatchCandidate) error {
+		return r0
 	})
 }
 
 // PushReturn calls PushHook with a function that returns the given values.
-func (f *StoreUpsertDependencyReposFunc) PushReturn(r0 []shared.Repo, r1 error) {
-	f.PushHook(func(context.Context, []shared.Repo) ([]shared.Repo, error) {
-		return r0, r1
+func (f *StoreUpdateVulnerabilityMatchesFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, []int, []shared.VulnerabilityMatchCandidate) error {
+		return r0
 	})
 }
 
-func (f *StoreUpsertDependencyReposFunc) nextHook() func(context.Context, []shared.Repo) ([]shared.Repo, error) {
+func (f *StoreUpdateVulnerabilityMatchesFunc) nextHook() func(context.Context, []int, []shared.VulnerabilityMatchCandidate) error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -1630,43 +1960,152 @@ func (f *StoreUpsertDependencyReposFunc) nextHook() func(context.Context, []shar
 	return hook
 }
 
-func (f *StoreUpsertDependencyReposFunc) appendCall(r0 StoreUpsertDependencyReposFuncCall) {
+func (f *StoreUpdateVulnerabilityMatchesFunc) appendCall(r0 StoreUpdateVulnerabilityMatchesFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
-// History returns a sequence of StoreUpsertDependencyReposFuncCall objects
-// describing the invocations of this function.
-func (f *StoreUpsertDependencyReposFunc) History() []StoreUpsertDependencyReposFuncCall {
+// History returns a sequence of StoreUpdateVulnerabilityMatchesFuncCall
+// objects describing the invocations of this function.
+func (f *StoreUpdateVulnerabilityMatchesFunc) History() []StoreUpdateVulnerabilityMatchesFuncCall {
 	f.mutex.Lock()
-	history := make([]StoreUpsertDependencyReposFuncCall, len(f.history))
+	history := make([]StoreUpdateVulnerabilityMatchesFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// StoreUpsertDependencyReposFuncCall is an object that describes an
-// invocation of method UpsertDependencyRepos on an instance of MockStore.
-type StoreUpsertDependencyReposFuncCall struct {
+// StoreUpdateVulnerabilityMatchesFuncCall is an object that describes an
+// invocation of method UpdateVulnerabilityMatches on an instance of
+// MockStore.
+type StoreUpdateVulnerabilityMatchesFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
 	// Arg1 is the value of the 2nd argument passed to this method
 	// invocation.
-	Arg1 []shared.Repo
-	// Result0 is the value of the 1st result returned from this method
+	Arg1 []int
+	// Arg2 is the value of the 3rd argument passed to this method
 	// invocation.
-	Result0 []shared.Repo
-	// Result1 is the value of the 2nd result returned from this method
+	Arg2 []shared.VulnerabilityMatchCandidate
+	// Result0 is the value of the 1st result returned from this method
 	// invocation.
-	Result1 error
+	Result0 error
 }
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c StoreUpsertDependencyReposFuncCall) Args() []interface{} {
+func (c StoreUpdateVulnerabilityMatchesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c StoreUpdateVulnerabilityMatchesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// StoreUpsertDependencyReposFunc describes the behavior when the
+// UpsertDependencyRepos method of the parent MockStore instance is invoked.
+type StoreUpsertDependencyReposFunc struct {
+	defaultHook func(context.Context, []shared.Repo) ([]shared.Repo, error)
+	hooks       []func(context.Context, []shared.Repo) ([]shared.Repo, error)
+	history     []StoreUpsertDependencyReposFuncCall
+	mutex       sync.Mutex
+}
+
+// UpsertDependencyRepos delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockStore) UpsertDependencyRepos(v0 context.Context, v1 []shared.Repo) ([]shared.Repo, error) {
+	r0, r1 := m.UpsertDependencyReposFunc.nextHook()(v0, v1)
+	m.UpsertDependencyReposFunc.appendCall(StoreUpsertDependencyReposFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// UpsertDependencyRepos method of the parent MockStore instance is invoked
+// and the hook queue is empty.
+func (f *StoreUpsertDependencyReposFunc) SetDefaultHook(hook func(context.Context, []shared.Repo) ([]shared.Repo, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// UpsertDependencyRepos method of the parent MockStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *StoreUpsertDependencyReposFunc) PushHook(hook func(context.Context, []shared.Repo) ([]shared.Repo, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *StoreUpsertDependencyReposFunc) SetDefaultReturn(r0 []shared.Repo, r1 error) {
+	f.SetDefaultHook(func(context.Context, []shared.Repo) ([]shared.Repo, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *StoreUpsertDependencyReposFunc) PushReturn(r0 []shared.Repo, r1 error) {
+	f.PushHook(func(context.Context, []shared.Repo) ([]shared.Repo, error) {
+		return r0, r1
+	})
+}
+
+func (f *StoreUpsertDependencyReposFunc) nextHook() func(context.Context, []shared.Repo) ([]shared.Repo, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *StoreUpsertDependencyReposFunc) appendCall(r0 StoreUpsertDependencyReposFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of StoreUpsertDependencyReposFuncCall objects
+// describing the invocations of this function.
+func (f *StoreUpsertDependencyReposFunc) History() []StoreUpsertDependencyReposFuncCall {
+	f.mutex.Lock()
+	history := make([]StoreUpsertDependencyReposFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// StoreUpsertDependencyReposFuncCall is an object that describes an
+// invocation of method UpsertDependencyRepos on an instance of MockStore.
+type StoreUpsertDependencyReposFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 []shared.Repo
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []shared.Repo
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c StoreUpsertDependencyReposFuncCall) Args() []interface{} {
 	return []interface{}{c.Arg0, c.Arg1}
 }
 
@@ -1792,3 +2231,329 @@ func (c StoreUpsertLockfileGraphFuncCall) Args() []interface{} {
 func (c StoreUpsertLockfileGraphFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
+
+// StoreUpsertVulnerabilitiesFunc describes the behavior when the
+// UpsertVulnerabilities method of the parent MockStore instance is invoked.
+type StoreUpsertVulnerabilitiesFunc struct {
+	defaultHook func(context.Context, []shared.Vulnerability) error
+	hooks       []func(context.Context, []shared.Vulnerability) error
+	history     []StoreUpsertVulnerabilitiesFuncCall
+	mutex       sync.Mutex
+}
+
+// UpsertVulnerabilities delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockStore) UpsertVulnerabilities(v0 context.Context, v1 []shared.Vulnerability) error {
+	r0 := m.UpsertVulnerabilitiesFunc.nextHook()(v0, v1)
+	m.UpsertVulnerabilitiesFunc.appendCall(StoreUpsertVulnerabilitiesFuncCall{v0, v1, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the
+// UpsertVulnerabilities method of the parent MockStore instance is invoked
+// and the hook queue is empty.
+func (f *StoreUpsertVulnerabilitiesFunc) SetDefaultHook(hook func(context.Context, []shared.Vulnerability) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// UpsertVulnerabilities method of the parent MockStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *StoreUpsertVulnerabilitiesFunc) PushHook(hook func(context.Context, []shared.Vulnerability) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *StoreUpsertVulnerabilitiesFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, []shared.Vulnerability) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *StoreUpsertVulnerabilitiesFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, []shared.Vulnerability) error {
+		return r0
+	})
+}
+
+func (f *StoreUpsertVulnerabilitiesFunc) nextHook() func(context.Context, []shared.Vulnerability) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *StoreUpsertVulnerabilitiesFunc) appendCall(r0 StoreUpsertVulnerabilitiesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of StoreUpsertVulnerabilitiesFuncCall objects
+// describing the invocations of this function.
+func (f *StoreUpsertVulnerabilitiesFunc) History() []StoreUpsertVulnerabilitiesFuncCall {
+	f.mutex.Lock()
+	history := make([]StoreUpsertVulnerabilitiesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// StoreUpsertVulnerabilitiesFuncCall is an object that describes an
+// invocation of method UpsertVulnerabilities on an instance of MockStore.
+type StoreUpsertVulnerabilitiesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 []shared.Vulnerability
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c StoreUpsertVulnerabilitiesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c StoreUpsertVulnerabilitiesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// StoreVulnerabilityMatchCandidatesFunc describes the behavior when the
+// VulnerabilityMatchCandidates method of the parent MockStore instance is
+// invoked.
+type StoreVulnerabilityMatchCandidatesFunc struct {
+	defaultHook func(context.Context, []int) ([]shared.VulnerabilityMatchCandidate, error)
+	hooks       []func(context.Context, []int) ([]shared.VulnerabilityMatchCandidate, error)
+	history     []StoreVulnerabilityMatchCandidatesFuncCall
+	mutex       sync.Mutex
+}
+
+// VulnerabilityMatchCandidates delegates to the next hook function in the
+// queue and stores the parameter and result values of this invocation.
+func (m *MockStore) VulnerabilityMatchCandidates(v0 context.Context, v1 []int) ([]shared.VulnerabilityMatchCandidate, error) {
+	r0, r1 := m.VulnerabilityMatchCandidatesFunc.nextHook()(v0, v1)
+	m.VulnerabilityMatchCandidatesFunc.appendCall(StoreVulnerabilityMatchCandidatesFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// VulnerabilityMatchCandidates method of the parent MockStore instance is
+// invoked and the hook queue is empty.
+func (f *StoreVulnerabilityMatchCandidatesFunc) SetDefaultHook(hook func(context.Context, []int) ([]shared.VulnerabilityMatchCandidate, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// VulnerabilityMatchCandidates method of the parent MockStore instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *StoreVulnerabilityMatchCandidatesFunc) PushHook(hook func(context.Context, []int) ([]shared.VulnerabilityMatchCandidate, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *StoreVulnerabilityMatchCandidatesFunc) SetDefaultReturn(r0 []shared.VulnerabilityMatchCandidate, r1 error) {
+	f.SetDefaultHook(func(context.Context, []int) ([]shared.VulnerabilityMatchCandidate, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *StoreVulnerabilityMatchCandidatesFunc) PushReturn(r0 []shared.VulnerabilityMatchCandidate, r1 error) {
+	f.PushHook(func(context.Context, []int) ([]shared.VulnerabilityMatchCandidate, error) {
+		return r0, r1
+	})
+}
+
+func (f *StoreVulnerabilityMatchCandidatesFunc) nextHook() func(context.Context, []int) ([]shared.VulnerabilityMatchCandidate, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *StoreVulnerabilityMatchCandidatesFunc) appendCall(r0 StoreVulnerabilityMatchCandidatesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of StoreVulnerabilityMatchCandidatesFuncCall
+// objects describing the invocations of this function.
+func (f *StoreVulnerabilityMatchCandidatesFunc) History() []StoreVulnerabilityMatchCandidatesFuncCall {
+	f.mutex.Lock()
+	history := make([]StoreVulnerabilityMatchCandidatesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// StoreVulnerabilityMatchCandidatesFuncCall is an object that describes an
+// invocation of method VulnerabilityMatchCandidates on an instance of
+// MockStore.
+type StoreVulnerabilityMatchCandidatesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 []int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []shared.VulnerabilityMatchCandidate
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c StoreVulnerabilityMatchCandidatesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c StoreVulnerabilityMatchCandidatesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
+// StoreVulnerableRepositoryNamesFunc describes the behavior when the
+// VulnerableRepositoryNames method of the parent MockStore instance is
+// invoked.
+type StoreVulnerableRepositoryNamesFunc struct {
+	defaultHook func(context.Context, string) ([]string, error)
+	hooks       []func(context.Context, string) ([]string, error)
+	history     []StoreVulnerableRepositoryNamesFuncCall
+	mutex       sync.Mutex
+}
+
+// VulnerableRepositoryNames delegates to the next hook function in the
+// queue and stores the parameter and result values of this invocation.
+func (m *MockStore) VulnerableRepositoryNames(v0 context.Context, v1 string) ([]string, error) {
+	r0, r1 := m.VulnerableRepositoryNamesFunc.nextHook()(v0, v1)
+	m.VulnerableRepositoryNamesFunc.appendCall(StoreVulnerableRepositoryNamesFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// VulnerableRepositoryNames method of the parent MockStore instance is
+// invoked and the hook queue is empty.
+func (f *StoreVulnerableRepositoryNamesFunc) SetDefaultHook(hook func(context.Context, string) ([]string, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// VulnerableRepositoryNames method of the parent MockStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *StoreVulnerableRepositoryNamesFunc) PushHook(hook func(context.Context, string) ([]string, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *StoreVulnerableRepositoryNamesFunc) SetDefaultReturn(r0 []string, r1 error) {
+	f.SetDefaultHook(func(context.Context, string) ([]string, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *StoreVulnerableRepositoryNamesFunc) PushReturn(r0 []string, r1 error) {
+	f.PushHook(func(context.Context, string) ([]string, error) {
+		return r0, r1
+	})
+}
+
+func (f *StoreVulnerableRepositoryNamesFunc) nextHook() func(context.Context, string) ([]string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *StoreVulnerableRepositoryNamesFunc) appendCall(r0 StoreVulnerableRepositoryNamesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of StoreVulnerableRepositoryNamesFuncCall
+// objects describing the invocations of this function.
+func (f *StoreVulnerableRepositoryNamesFunc) History() []StoreVulnerableRepositoryNamesFuncCall {
+	f.mutex.Lock()
+	history := make([]StoreVulnerableRepositoryNamesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// StoreVulnerableRepositoryNamesFuncCall is an object that describes an
+// invocation of method VulnerableRepositoryNames on an instance of
+// MockStore.
+type StoreVulnerableRepositoryNamesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []string
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c StoreVulnerableRepositoryNamesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c StoreVulnerableRepositoryNamesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
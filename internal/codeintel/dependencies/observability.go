@@ -9,6 +9,8 @@ import (
 
 type operations struct {
 	dependencies                           *observation.Operation
+	importVulnerabilities                  *observation.Operation
+	matchVulnerabilities                   *observation.Operation
 	resolveLockfileDependenciesFromArchive *observation.Operation
 	resolveLockfileDependenciesFromStore   *observation.Operation
 }
@@ -31,6 +33,8 @@ func newOperations(observationContext *observation.Context) *operations {
 
 	return &operations{
 		dependencies:                           op("Dependencies"),
+		importVulnerabilities:                  op("ImportVulnerabilities"),
+		matchVulnerabilities:                   op("MatchVulnerabilities"),
 		resolveLockfileDependenciesFromArchive: op("resolveLockfileDependenciesFromArchive"),
 		resolveLockfileDependenciesFromStore:   op("resolveLockfileDependenciesFromStore"),
 	}
@@ -5,5 +5,6 @@ const (
 	JVMPackagesScheme    = "semanticdb"
 	NpmPackagesScheme    = "npm"
 	PythonPackagesScheme = "python"
+	RubyPackagesScheme   = "scip-ruby"
 	RustPackagesScheme   = "rust-analyzer"
 )
@@ -0,0 +1,59 @@
+package shared
+
+import "time"
+
+// Vulnerability is a security advisory imported from an OSV-format advisory database.
+type Vulnerability struct {
+	ID          int
+	SourceID    string
+	Aliases     []string
+	Summary     string
+	Details     string
+	Severity    string
+	PublishedAt *time.Time
+	ModifiedAt  *time.Time
+	WithdrawnAt *time.Time
+
+	AffectedPackages []AffectedPackage
+}
+
+// AffectedPackage is a package affected by a vulnerability. A version of the package is
+// affected if it is explicitly listed in Versions or if it falls within any of Ranges.
+type AffectedPackage struct {
+	ID            int
+	PackageScheme string
+	PackageName   string
+	Versions      []string
+	Ranges        []VersionRange
+}
+
+// VersionRange is a half-open range of affected versions. A version is in the range if it
+// is not before Introduced and is either before Fixed or not after LastAffected. An empty
+// Introduced value denotes the earliest version; a range with neither Fixed nor LastAffected
+// is unbounded above.
+type VersionRange struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"lastAffected,omitempty"`
+}
+
+// VulnerabilityMatchCandidate is a lockfile dependency that refers to a package affected
+// by some vulnerability. The dependency is vulnerable if its version is affected.
+type VulnerabilityMatchCandidate struct {
+	AffectedPackageID   int
+	LockfileReferenceID int
+	PackageVersion      string
+}
+
+// VulnerabilityMatch is a lockfile dependency of a repository that resolves to a vulnerable
+// version of a package.
+type VulnerabilityMatch struct {
+	Vulnerability  Vulnerability
+	RepositoryID   int
+	RepositoryName string
+	Commit         string
+	Lockfile       string
+	PackageScheme  string
+	PackageName    string
+	PackageVersion string
+}
@@ -0,0 +1,157 @@
+package dependencies
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/internal/osv"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/internal/store"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/shared"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+type (
+	Vulnerability                = shared.Vulnerability
+	VulnerabilityMatch           = shared.VulnerabilityMatch
+	ListVulnerabilityMatchesOpts = store.ListVulnerabilityMatchesOpts
+)
+
+// vulnerabilityImportBatchSize is the number of advisories written to the database in a
+// single transaction during import.
+const vulnerabilityImportBatchSize = 100
+
+// ImportVulnerabilities reads the OSV advisories stored in the given directory and writes them
+// to the database. The number of advisories read is returned.
+func (s *Service) ImportVulnerabilities(ctx context.Context, dir string) (numAdvisories int, err error) {
+	ctx, _, endObservation := s.operations.importVulnerabilities.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("dir", dir),
+	}})
+	defer func() {
+		endObservation(1, observation.Args{LogFields: []log.Field{
+			log.Int("numAdvisories", numAdvisories),
+		}})
+	}()
+
+	batch := make([]shared.Vulnerability, 0, vulnerabilityImportBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := s.dependenciesStore.UpsertVulnerabilities(ctx, batch); err != nil {
+			return errors.Wrap(err, "store.UpsertVulnerabilities")
+		}
+
+		batch = batch[:0]
+		return nil
+	}
+
+	if err := osv.ReadAdvisories(dir, func(vulnerability shared.Vulnerability) error {
+		numAdvisories++
+		batch = append(batch, vulnerability)
+
+		if len(batch) < vulnerabilityImportBatchSize {
+			return nil
+		}
+		return flush()
+	}); err != nil {
+		return numAdvisories, err
+	}
+
+	return numAdvisories, flush()
+}
+
+// MatchVulnerabilities recomputes the set of lockfile dependencies that resolve to a vulnerable
+// version of a package, processing the given number of affected packages at a time. The number
+// of matches is returned.
+func (s *Service) MatchVulnerabilities(ctx context.Context, batchSize int) (numMatches int, err error) {
+	ctx, _, endObservation := s.operations.matchVulnerabilities.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("batchSize", batchSize),
+	}})
+	defer func() {
+		endObservation(1, observation.Args{LogFields: []log.Field{
+			log.Int("numMatches", numMatches),
+		}})
+	}()
+
+	afterID := 0
+	for {
+		pkgs, err := s.dependenciesStore.ListVulnerabilityAffectedPackages(ctx, afterID, batchSize)
+		if err != nil {
+			return numMatches, errors.Wrap(err, "store.ListVulnerabilityAffectedPackages")
+		}
+		if len(pkgs) == 0 {
+			return numMatches, nil
+		}
+
+		ids := make([]int, 0, len(pkgs))
+		pkgsByID := make(map[int]shared.AffectedPackage, len(pkgs))
+		for _, pkg := range pkgs {
+			ids = append(ids, pkg.ID)
+			pkgsByID[pkg.ID] = pkg
+		}
+
+		candidates, err := s.dependenciesStore.VulnerabilityMatchCandidates(ctx, ids)
+		if err != nil {
+			return numMatches, errors.Wrap(err, "store.VulnerabilityMatchCandidates")
+		}
+
+		matches := make([]shared.VulnerabilityMatchCandidate, 0, len(candidates))
+		for _, candidate := range candidates {
+			if osv.Affected(pkgsByID[candidate.AffectedPackageID], candidate.PackageVersion) {
+				matches = append(matches, candidate)
+			}
+		}
+
+		if err := s.dependenciesStore.UpdateVulnerabilityMatches(ctx, ids, matches); err != nil {
+			return numMatches, errors.Wrap(err, "store.UpdateVulnerabilityMatches")
+		}
+
+		numMatches += len(matches)
+		afterID = ids[len(ids)-1]
+	}
+}
+
+// ListVulnerabilityMatches returns a page of vulnerable lockfile dependencies along with the
+// total number of matches.
+func (s *Service) ListVulnerabilityMatches(ctx context.Context, opts ListVulnerabilityMatchesOpts) ([]VulnerabilityMatch, int, error) {
+	return s.dependenciesStore.ListVulnerabilityMatches(ctx, opts)
+}
+
+// VulnerableRepositoryNames returns the names of repositories with a vulnerable lockfile dependency.
+// If vulnerability identifiers are given, only repositories affected by every one of them (matched
+// by identifier or alias) are returned.
+func (s *Service) VulnerableRepositoryNames(ctx context.Context, vulnerabilityIDs []string) ([]string, error) {
+	if len(vulnerabilityIDs) == 0 {
+		return s.dependenciesStore.VulnerableRepositoryNames(ctx, "")
+	}
+
+	var names []string
+	for i, vulnerabilityID := range vulnerabilityIDs {
+		matchingNames, err := s.dependenciesStore.VulnerableRepositoryNames(ctx, vulnerabilityID)
+		if err != nil {
+			return nil, errors.Wrap(err, "store.VulnerableRepositoryNames")
+		}
+
+		if i == 0 {
+			names = matchingNames
+			continue
+		}
+
+		matchingNamesSet := make(map[string]struct{}, len(matchingNames))
+		for _, name := range matchingNames {
+			matchingNamesSet[name] = struct{}{}
+		}
+
+		filtered := make([]string, 0, len(names))
+		for _, name := range names {
+			if _, ok := matchingNamesSet[name]; ok {
+				filtered = append(filtered, name)
+			}
+		}
+		names = filtered
+	}
+
+	return names, nil
+}
@@ -0,0 +1,91 @@
+package dependencies
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/dependencies/shared"
+)
+
+func TestMatchVulnerabilities(t *testing.T) {
+	ctx := context.Background()
+	mockStore := NewMockStore()
+	service := testService(mockStore, NewMockLocalGitService(), NewMockLockfilesService(), NewMockSyncer())
+
+	mockStore.ListVulnerabilityAffectedPackagesFunc.PushReturn([]shared.AffectedPackage{
+		{ID: 1, PackageScheme: "npm", PackageName: "left-pad", Ranges: []shared.VersionRange{{Introduced: "1.0.0", Fixed: "1.3.0"}}},
+		{ID: 2, PackageScheme: "python", PackageName: "django", Versions: []string{"2.0"}},
+	}, nil)
+	mockStore.ListVulnerabilityAffectedPackagesFunc.PushReturn(nil, nil)
+
+	mockStore.VulnerabilityMatchCandidatesFunc.SetDefaultReturn([]shared.VulnerabilityMatchCandidate{
+		{AffectedPackageID: 1, LockfileReferenceID: 10, PackageVersion: "0.9.0"},
+		{AffectedPackageID: 1, LockfileReferenceID: 11, PackageVersion: "1.2.5"},
+		{AffectedPackageID: 1, LockfileReferenceID: 12, PackageVersion: "1.3.0"},
+		{AffectedPackageID: 2, LockfileReferenceID: 13, PackageVersion: "2.0.0"},
+		{AffectedPackageID: 2, LockfileReferenceID: 14, PackageVersion: "2.0.1"},
+	}, nil)
+
+	numMatches, err := service.MatchVulnerabilities(ctx, 100)
+	if err != nil {
+		t.Fatalf("unexpected error matching vulnerabilities: %s", err)
+	}
+	if numMatches != 2 {
+		t.Errorf("unexpected number of matches. want=%d have=%d", 2, numMatches)
+	}
+
+	history := mockStore.UpdateVulnerabilityMatchesFunc.History()
+	if len(history) != 1 {
+		t.Fatalf("unexpected number of calls to UpdateVulnerabilityMatches. want=%d have=%d", 1, len(history))
+	}
+	if diff := cmp.Diff([]int{1, 2}, history[0].Arg1); diff != "" {
+		t.Errorf("unexpected affected package ids (-want +got):\n%s", diff)
+	}
+	expectedMatches := []shared.VulnerabilityMatchCandidate{
+		{AffectedPackageID: 1, LockfileReferenceID: 11, PackageVersion: "1.2.5"},
+		{AffectedPackageID: 2, LockfileReferenceID: 13, PackageVersion: "2.0.0"},
+	}
+	if diff := cmp.Diff(expectedMatches, history[0].Arg2); diff != "" {
+		t.Errorf("unexpected matches (-want +got):\n%s", diff)
+	}
+
+	if history := mockStore.ListVulnerabilityAffectedPackagesFunc.History(); len(history) != 2 || history[1].Arg1 != 2 {
+		t.Errorf("expected second page to start after the last affected package")
+	}
+}
+
+func TestVulnerableRepositoryNames(t *testing.T) {
+	ctx := context.Background()
+	mockStore := NewMockStore()
+	service := testService(mockStore, NewMockLocalGitService(), NewMockLockfilesService(), NewMockSyncer())
+
+	mockStore.VulnerableRepositoryNamesFunc.SetDefaultHook(func(ctx context.Context, vulnerabilityID string) ([]string, error) {
+		switch vulnerabilityID {
+		case "CVE-1":
+			return []string{"repo-1", "repo-2", "repo-3"}, nil
+		case "CVE-2":
+			return []string{"repo-2", "repo-3", "repo-4"}, nil
+		}
+
+		return []string{"repo-1", "repo-2", "repo-3", "repo-4", "repo-5"}, nil
+	})
+
+	for _, testCase := range []struct {
+		vulnerabilityIDs []string
+		expected         []string
+	}{
+		{nil, []string{"repo-1", "repo-2", "repo-3", "repo-4", "repo-5"}},
+		{[]string{"CVE-1"}, []string{"repo-1", "repo-2", "repo-3"}},
+		{[]string{"CVE-1", "CVE-2"}, []string{"repo-2", "repo-3"}},
+	} {
+		names, err := service.VulnerableRepositoryNames(ctx, testCase.vulnerabilityIDs)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if diff := cmp.Diff(testCase.expected, names); diff != "" {
+			t.Errorf("unexpected names for %v (-want +got):\n%s", testCase.vulnerabilityIDs, diff)
+		}
+	}
+}
@@ -25,6 +25,9 @@ func New(ctx context.Context, conf *Config, observationContext *observation.Cont
 			CredentialsFile:         conf.GCSCredentialsFile,
 			CredentialsFileContents: conf.GCSCredentialsFileContents,
 		},
+		Filesystem: uploadstore.FilesystemConfig{
+			Root: conf.FilesystemRoot,
+		},
 	}
 
 	return uploadstore.CreateLazy(ctx, c, uploadstore.NewOperations(observationContext, "codeintel", "uploadstore"))
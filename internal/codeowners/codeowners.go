@@ -0,0 +1,270 @@
+// Package codeowners parses CODEOWNERS files as used by GitHub and GitLab, and
+// resolves the owners of paths according to them.
+//
+// See https://docs.github.com/en/repositories/managing-your-repositorys-settings-and-features/customizing-your-repository/about-code-owners
+// and https://docs.gitlab.com/ee/user/project/code_owners.html.
+package codeowners
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/grafana/regexp"
+
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// Paths are the locations at which a CODEOWNERS file is looked up in a
+// repository, in order of precedence.
+var Paths = []string{
+	".github/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// Ruleset is a parsed CODEOWNERS file.
+type Ruleset struct {
+	// Sections are the GitLab sections of the file. Rules before the first
+	// section header, and all rules of GitHub files, are in an unnamed section.
+	Sections []*Section
+}
+
+// Section is a group of rules. Within a section, the last matching rule
+// determines the owners of a path.
+type Section struct {
+	Name  string
+	Rules []*Rule
+}
+
+// Rule assigns owners to the paths matching a pattern.
+type Rule struct {
+	Pattern string
+	Owners  []string
+
+	re *regexp.Regexp
+}
+
+// Parse parses a CODEOWNERS file.
+func Parse(r io.Reader) (*Ruleset, error) {
+	current := &Section{}
+	rs := &Ruleset{Sections: []*Section{current}}
+
+	var (
+		defaultOwners []string
+		lineNumber    int
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if name, owners, ok := parseSectionHeader(line); ok {
+			current = &Section{Name: name}
+			rs.Sections = append(rs.Sections, current)
+			defaultOwners = owners
+			continue
+		}
+
+		fields := splitFields(line)
+		rule, err := newRule(fields[0], fields[1:])
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", lineNumber)
+		}
+		if len(rule.Owners) == 0 && current.Name != "" {
+			rule.Owners = defaultOwners
+		}
+		current.Rules = append(current.Rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// Owners returns the owners of the given path, which is relative to the root
+// of the repository. The owners of all sections are combined. A nil Ruleset,
+// as for a repository without a CODEOWNERS file, owns nothing.
+func (rs *Ruleset) Owners(path string) []string {
+	if rs == nil {
+		return nil
+	}
+
+	path = strings.TrimPrefix(path, "/")
+
+	var owners []string
+	seen := make(map[string]struct{})
+	for _, s := range rs.Sections {
+		rule := s.match(path)
+		if rule == nil {
+			continue
+		}
+		for _, owner := range rule.Owners {
+			key := NormalizeOwner(owner)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			owners = append(owners, owner)
+		}
+	}
+	return owners
+}
+
+// IsOwnedBy returns true if the given owner is one of the owners of path.
+func (rs *Ruleset) IsOwnedBy(path, owner string) bool {
+	for _, o := range rs.Owners(path) {
+		if MatchOwner(o, owner) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchOwner returns true if the owner as written in a CODEOWNERS file refers
+// to the given owner. Owners are compared case insensitively and the leading @
+// of user and team handles is optional.
+func MatchOwner(owner, query string) bool {
+	return NormalizeOwner(owner) == NormalizeOwner(query)
+}
+
+// NormalizeOwner returns the form of owner that is the same for all spellings
+// of the owner matched by MatchOwner.
+func NormalizeOwner(owner string) string {
+	return strings.ToLower(strings.TrimPrefix(owner, "@"))
+}
+
+func (s *Section) match(path string) *Rule {
+	for i := len(s.Rules) - 1; i >= 0; i-- {
+		if s.Rules[i].re.MatchString(path) {
+			return s.Rules[i]
+		}
+	}
+	return nil
+}
+
+var sectionHeaderPattern = regexp.MustCompile(`^\^?\[([^\]]+)\](?:\[\d+\])?(.*)$`)
+
+// parseSectionHeader parses GitLab section headers such as
+// "^[Section name][2] @default-owner".
+func parseSectionHeader(line string) (name string, owners []string, ok bool) {
+	m := sectionHeaderPattern.FindStringSubmatch(line)
+	if m == nil {
+		return "", nil, false
+	}
+	return strings.TrimSpace(m[1]), splitFields(m[2]), true
+}
+
+// splitFields splits a line on whitespace that isn't escaped, and drops
+// trailing comments.
+func splitFields(line string) []string {
+	var (
+		fields []string
+		cur    strings.Builder
+	)
+	flush := func() {
+		if cur.Len() > 0 {
+			fields = append(fields, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			i++
+			if line[i] != ' ' && line[i] != '#' {
+				cur.WriteByte('\\')
+			}
+			cur.WriteByte(line[i])
+		case c == '#' && cur.Len() == 0:
+			flush()
+			return fields
+		case c == ' ' || c == '\t':
+			flush()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+	return fields
+}
+
+func newRule(pattern string, owners []string) (*Rule, error) {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid pattern %q", pattern)
+	}
+	return &Rule{Pattern: pattern, Owners: owners, re: re}, nil
+}
+
+// compilePattern compiles a gitignore style pattern into a regular expression
+// matching the paths it applies to. Like on GitHub, a pattern matches the path
+// itself and everything below it, except for patterns ending in "/*", which
+// only match the direct children of a directory.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	trimmed := strings.Trim(pattern, "/")
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(trimmed, "/")
+	if trimmed == "" {
+		return nil, errors.New("empty pattern")
+	}
+
+	var sb strings.Builder
+	if anchored {
+		sb.WriteString("^")
+	} else {
+		sb.WriteString("^(?:.*/)?")
+	}
+
+	segments := strings.Split(trimmed, "/")
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if segment == "**" {
+			if last {
+				sb.WriteString(".*")
+			} else {
+				sb.WriteString("(?:.*/)?")
+			}
+			continue
+		}
+
+		for j := 0; j < len(segment); j++ {
+			switch c := segment[j]; c {
+			case '*':
+				sb.WriteString("[^/]*")
+			case '?':
+				sb.WriteString("[^/]")
+			case '\\':
+				if j+1 < len(segment) {
+					j++
+					sb.WriteString(regexp.QuoteMeta(string(segment[j])))
+				}
+			default:
+				sb.WriteString(regexp.QuoteMeta(string(c)))
+			}
+		}
+		if !last {
+			sb.WriteString("/")
+		}
+	}
+
+	switch {
+	case len(segments) > 1 && segments[len(segments)-1] == "*" && !dirOnly:
+		sb.WriteString("$")
+	case dirOnly:
+		sb.WriteString("/.*$")
+	default:
+		sb.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(sb.String())
+}
@@ -0,0 +1,102 @@
+package codeowners
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleset_Owners(t *testing.T) {
+	rs, err := Parse(strings.NewReader(`
+# Default owners of everything.
+*       @global-owner1 @global-owner2
+
+*.js    @js-owner # inline comment
+*.go    docs@example.com
+**/logs @logs-owner
+/build/logs/ @doctocat
+docs/*  @docs-owner
+apps/   @octocat
+/scripts/ @doctocat @octocat
+/vendor/
+path\ with\ spaces/ @spaces
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string][]string{
+		"README.md":                         {"@global-owner1", "@global-owner2"},
+		"src/index.js":                      {"@js-owner"},
+		"cmd/main.go":                       {"docs@example.com"},
+		"build/logs/out.txt":                {"@doctocat"},
+		"sub/build/logs/out.txt":            {"@logs-owner"},
+		"docs/getting-started.md":           {"@docs-owner"},
+		"docs/build-app/troubleshooting.md": {"@global-owner1", "@global-owner2"},
+		"apps/web/index.html":               {"@octocat"},
+		"nested/apps/web/index.html":        {"@octocat"},
+		"scripts/build.sh":                  {"@doctocat", "@octocat"},
+		"nested/scripts/build.sh":           {"@global-owner1", "@global-owner2"},
+		"deep/down/logs/today.txt":          {"@logs-owner"},
+		"vendor/lib/lib.go":                 nil,
+		"path with spaces/file.txt":         {"@spaces"},
+	} {
+		if diff := cmp.Diff(want, rs.Owners(path)); diff != "" {
+			t.Errorf("unexpected owners of %q (-want +got):\n%s", path, diff)
+		}
+	}
+}
+
+func TestRuleset_GitLabSections(t *testing.T) {
+	rs, err := Parse(strings.NewReader(`
+*.rb @ruby-owner
+
+[Documentation] @docs-team
+docs/
+README.md @docs-team @tech-writers
+
+^[Database][2] @database-team
+/db/
+*.sql @sql-owner @Ruby-Owner
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string][]string{
+		"app/models/user.rb":  {"@ruby-owner"},
+		"docs/index.md":       {"@docs-team"},
+		"README.md":           {"@docs-team", "@tech-writers"},
+		"db/schema.rb":        {"@ruby-owner", "@database-team"},
+		"db/migrate/init.sql": {"@sql-owner", "@Ruby-Owner"},
+		"lib/tasks.py":        nil,
+	} {
+		if diff := cmp.Diff(want, rs.Owners(path)); diff != "" {
+			t.Errorf("unexpected owners of %q (-want +got):\n%s", path, diff)
+		}
+	}
+
+	if !rs.IsOwnedBy("db/migrate/init.sql", "sql-owner") {
+		t.Error("expected sql-owner to own init.sql")
+	}
+	if rs.IsOwnedBy("docs/index.md", "@ruby-owner") {
+		t.Error("expected ruby-owner not to own docs/index.md")
+	}
+}
+
+func TestMatchOwner(t *testing.T) {
+	for _, tc := range []struct {
+		owner, query string
+		want         bool
+	}{
+		{"@sourcegraph/search", "@sourcegraph/search", true},
+		{"@sourcegraph/search", "sourcegraph/Search", true},
+		{"@alice", "@bob", false},
+		{"alice@example.com", "Alice@Example.com", true},
+	} {
+		if have := MatchOwner(tc.owner, tc.query); have != tc.want {
+			t.Errorf("MatchOwner(%q, %q): want %t, have %t", tc.owner, tc.query, tc.want, have)
+		}
+	}
+}
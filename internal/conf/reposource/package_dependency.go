@@ -54,4 +54,5 @@ var (
 	_ PackageDependency = (*NpmDependency)(nil)
 	_ PackageDependency = (*GoDependency)(nil)
 	_ PackageDependency = (*PythonDependency)(nil)
+	_ PackageDependency = (*RubyDependency)(nil)
 )
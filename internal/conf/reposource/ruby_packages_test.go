@@ -0,0 +1,59 @@
+package reposource
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRubyDependency(t *testing.T) {
+	for _, tc := range []struct {
+		input   string
+		name    string
+		version string
+	}{
+		{"rails@7.0.4", "rails", "7.0.4"},
+		{"rails", "rails", ""},
+		{"shopify_api@12.0.0.rc1", "shopify_api", "12.0.0.rc1"},
+		{" rspec-core @ 3.11.0 ", "rspec-core", "3.11.0"},
+	} {
+		dep, err := ParseRubyDependency(tc.input)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %s", tc.input, err)
+		}
+		assert.Equal(t, tc.name, dep.Name)
+		assert.Equal(t, tc.version, dep.Version)
+	}
+
+	_, err := ParseRubyDependency("@1.0.0")
+	assert.Error(t, err)
+}
+
+func TestParseRubyDependencyFromRepoName(t *testing.T) {
+	dep, err := ParseRubyDependencyFromRepoName("rubygems/rails")
+	assert.NoError(t, err)
+	assert.Equal(t, "rails", dep.Name)
+	assert.Equal(t, "rubygems/rails", string(dep.RepoName()))
+
+	_, err = ParseRubyDependencyFromRepoName("crates/rails")
+	assert.Error(t, err)
+}
+
+func TestRubyDependency_Less(t *testing.T) {
+	dependencies := []*RubyDependency{
+		NewRubyDependency("rake", "13.0.6"),
+		NewRubyDependency("rails", "6.1.7"),
+		NewRubyDependency("rails", "7.0.4"),
+		NewRubyDependency("rails", "7.0.10"),
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].Less(dependencies[j])
+	})
+
+	var have []string
+	for _, dep := range dependencies {
+		have = append(have, dep.PackageManagerSyntax())
+	}
+	assert.Equal(t, []string{"rake@13.0.6", "rails@7.0.10", "rails@7.0.4", "rails@6.1.7"}, have)
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/log/logtest"
 
+	"github.com/sourcegraph/sourcegraph/internal/authz"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
 )
 
@@ -38,7 +40,7 @@ func TestAccessTokens_Create(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tid0, tv0, err := db.AccessTokens().Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID)
+	tid0, tv0, err := db.AccessTokens().Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -114,11 +116,11 @@ func TestAccessTokens_List(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, _, err = db.AccessTokens().Create(ctx, subject1.ID, []string{"a", "b"}, "n0", subject1.ID)
+	_, _, err = db.AccessTokens().Create(ctx, subject1.ID, []string{"a", "b"}, "n0", subject1.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	_, _, err = db.AccessTokens().Create(ctx, subject1.ID, []string{"a", "b"}, "n1", subject1.ID)
+	_, _, err = db.AccessTokens().Create(ctx, subject1.ID, []string{"a", "b"}, "n1", subject1.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -195,7 +197,7 @@ func TestAccessTokens_Lookup(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tid0, tv0, err := db.AccessTokens().Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID)
+	tid0, tv0, err := db.AccessTokens().Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -265,7 +267,7 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		_, tv0, err := db.AccessTokens().Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID)
+		_, tv0, err := db.AccessTokens().Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -276,7 +278,7 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal("Lookup: want error looking up token for deleted subject user")
 		}
 
-		if _, _, err := db.AccessTokens().Create(ctx, subject.ID, nil, "n0", creator.ID); err == nil {
+		if _, _, err := db.AccessTokens().Create(ctx, subject.ID, nil, "n0", creator.ID, nil); err == nil {
 			t.Fatal("Create: want error creating token for deleted subject user")
 		}
 	})
@@ -301,7 +303,7 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		_, tv0, err := db.AccessTokens().Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID)
+		_, tv0, err := db.AccessTokens().Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -312,8 +314,105 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal("Lookup: want error looking up token for deleted creator user")
 		}
 
-		if _, _, err := db.AccessTokens().Create(ctx, subject.ID, nil, "n0", creator.ID); err == nil {
+		if _, _, err := db.AccessTokens().Create(ctx, subject.ID, nil, "n0", creator.ID, nil); err == nil {
 			t.Fatal("Create: want error creating token for deleted creator user")
 		}
 	})
 }
+
+// 🚨 SECURITY: This tests that broader scopes grant the scopes they imply, and only those.
+func TestAccessTokens_Lookup_impliedScopes(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	logger := logtest.Scoped(t)
+	t.Parallel()
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	ctx := context.Background()
+
+	subject, err := db.Users().Create(ctx, NewUser{
+		Email:                 "u1@example.com",
+		Username:              "u1",
+		Password:              "p1",
+		EmailVerificationCode: "c1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, userAllToken, err := db.AccessTokens().Create(ctx, subject.ID, []string{authz.ScopeUserAll}, "n0", subject.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, scope := range []string{authz.ScopeUserAll, authz.ScopeRepoRead, authz.ScopeSearchRead, authz.ScopeCodeIntelUpload, authz.ScopeBatchesWrite} {
+		if _, err := db.AccessTokens().Lookup(ctx, userAllToken, scope); err != nil {
+			t.Errorf("Lookup(%q): unexpected error for user:all token: %s", scope, err)
+		}
+	}
+	if _, err := db.AccessTokens().Lookup(ctx, userAllToken, authz.ScopeSiteAdminSudo); err != ErrAccessTokenNotFound {
+		t.Errorf("Lookup(%q): want ErrAccessTokenNotFound for user:all token, got %v", authz.ScopeSiteAdminSudo, err)
+	}
+
+	_, searchToken, err := db.AccessTokens().Create(ctx, subject.ID, []string{authz.ScopeSearchRead}, "n1", subject.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.AccessTokens().Lookup(ctx, searchToken, authz.ScopeSearchRead); err != nil {
+		t.Errorf("Lookup(%q): unexpected error for search:read token: %s", authz.ScopeSearchRead, err)
+	}
+	for _, scope := range []string{authz.ScopeUserAll, authz.ScopeRepoRead, authz.ScopeCodeIntelUpload} {
+		if _, err := db.AccessTokens().Lookup(ctx, searchToken, scope); err != ErrAccessTokenNotFound {
+			t.Errorf("Lookup(%q): want ErrAccessTokenNotFound for search:read token, got %v", scope, err)
+		}
+	}
+}
+
+// 🚨 SECURITY: This tests that expired access tokens are invalid.
+func TestAccessTokens_Lookup_expired(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	logger := logtest.Scoped(t)
+	t.Parallel()
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	ctx := context.Background()
+
+	subject, err := db.Users().Create(ctx, NewUser{
+		Email:                 "u1@example.com",
+		Username:              "u1",
+		Password:              "p1",
+		EmailVerificationCode: "c1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	if _, _, err := db.AccessTokens().Create(ctx, subject.ID, []string{"a"}, "n0", subject.ID, &past); err == nil {
+		t.Fatal("Create: want error creating token that has already expired")
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	tid0, tv0, err := db.AccessTokens().Create(ctx, subject.ID, []string{"a"}, "n0", subject.ID, &expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.AccessTokens().Lookup(ctx, tv0, "a"); err != nil {
+		t.Fatalf("Lookup: unexpected error for unexpired token: %s", err)
+	}
+
+	token, err := db.AccessTokens().GetByID(ctx, tid0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.ExpiresAt == nil || !token.ExpiresAt.Equal(expiresAt.Truncate(time.Microsecond)) {
+		t.Errorf("unexpected expiry. want=%s have=%v", expiresAt, token.ExpiresAt)
+	}
+
+	if _, err := db.ExecContext(ctx, "UPDATE access_tokens SET expires_at = now() - interval '1 minute' WHERE id = $1", tid0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.AccessTokens().Lookup(ctx, tv0, "a"); err != ErrAccessTokenNotFound {
+		t.Fatalf("Lookup: want ErrAccessTokenNotFound for expired token, got %v", err)
+	}
+}
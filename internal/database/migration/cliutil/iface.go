@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/sourcegraph/sourcegraph/internal/database/migration/definition"
+	"github.com/sourcegraph/sourcegraph/internal/database/migration/multiversion"
 	"github.com/sourcegraph/sourcegraph/internal/database/migration/runner"
 	"github.com/sourcegraph/sourcegraph/internal/database/migration/schemas"
 	"github.com/sourcegraph/sourcegraph/lib/output"
@@ -26,6 +27,15 @@ type OutputFactory func() *output.Output
 
 type RunnerFactory func(ctx context.Context, schemaNames []string) (Runner, error)
 
+// OutOfBandMigrationStore reads and updates out-of-band migration records in the frontend
+// database.
+type OutOfBandMigrationStore interface {
+	List(ctx context.Context) ([]multiversion.OutOfBandMigration, error)
+	UpdateDirection(ctx context.Context, id int, applyReverse bool) error
+}
+
+type OutOfBandMigrationStoreFactory func(ctx context.Context) (OutOfBandMigrationStore, error)
+
 type runnerShim struct {
 	*runner.Runner
 }
@@ -0,0 +1,284 @@
+package cliutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/migration/multiversion"
+	"github.com/sourcegraph/sourcegraph/internal/database/migration/runner"
+	"github.com/sourcegraph/sourcegraph/internal/database/migration/schemas"
+	"github.com/sourcegraph/sourcegraph/internal/version"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/lib/output"
+)
+
+func Upgrade(commandName string, factory RunnerFactory, oobFactory OutOfBandMigrationStoreFactory, outFactory OutputFactory) *cli.Command {
+	fromFlag := &cli.StringFlag{
+		Name:     "from",
+		Usage:    "The `version` the instance is currently running, e.g. 3.38.",
+		Required: true,
+	}
+	toFlag := &cli.StringFlag{
+		Name:     "to",
+		Usage:    "The `version` to upgrade to, e.g. 3.42.",
+		Required: true,
+	}
+	dryRunFlag := &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Print the upgrade plan without applying it.",
+		Value: false,
+	}
+	pollIntervalFlag := &cli.DurationFlag{
+		Name:  "poll-interval",
+		Usage: "The `interval` at which to check the progress of out-of-band migrations.",
+		Value: 30 * time.Second,
+	}
+	unprivilegedOnlyFlag := &cli.BoolFlag{
+		Name:  "unprivileged-only",
+		Usage: `Do not apply privileged migrations.`,
+		Value: false,
+	}
+
+	action := makeAction(outFactory, func(ctx context.Context, cmd *cli.Context, out *output.Output) error {
+		from, err := multiversion.ParseVersion(fromFlag.Get(cmd))
+		if err != nil {
+			return flagHelp(out, "invalid -from: %s", err)
+		}
+		to, err := multiversion.ParseVersion(toFlag.Get(cmd))
+		if err != nil {
+			return flagHelp(out, "invalid -to: %s", err)
+		}
+
+		target, err := targetLeaves(to)
+		if err != nil {
+			return err
+		}
+		oobStore, err := oobFactory(ctx)
+		if err != nil {
+			return err
+		}
+		migrations, err := oobStore.List(ctx)
+		if err != nil {
+			return err
+		}
+		plan, err := multiversion.PlanUpgrade(from, to, multiversion.ReleaseLeaves, target, migrations)
+		if err != nil {
+			return err
+		}
+
+		out.Write(plan.String())
+		if dryRunFlag.Get(cmd) {
+			// Out-of-band migrations registered by the schema migrations of an
+			// intermediate release are not known until that release's schema
+			// migrations are applied, so the actual plan may contain more steps.
+			return nil
+		}
+
+		r, err := setupRunner(ctx, factory, schemas.SchemaNames...)
+		if err != nil {
+			return err
+		}
+
+		// Every step is idempotent: schema migrations that are already applied and
+		// out-of-band migrations that are already complete are skipped. An interrupted
+		// upgrade is resumed by re-running this command with the same arguments.
+		//
+		// Schema migrations register the out-of-band migrations of their release, so
+		// the schemas are migrated one known release at a time and the remainder of the
+		// plan is recomputed after each of them.
+		planned := plannedOutOfBandMigrations(plan)
+		for checkpoint := 1; ; checkpoint++ {
+			step := plan.Steps[0]
+			if release, ok := nextRelease(plan.From, step.Version); ok {
+				step = multiversion.Step{Version: release, Leaves: multiversion.ReleaseLeaves[release]}
+			}
+
+			if step.Leaves != nil {
+				out.WriteLine(output.Linef("", output.StylePending, "Applying schema migrations up to %s...", step.Version))
+
+				if err := r.Run(ctx, runner.Options{
+					Operations:       targetedUpOperations(step.Leaves),
+					UnprivilegedOnly: unprivilegedOnlyFlag.Get(cmd),
+				}); err != nil {
+					return errors.Wrapf(err, "failed to apply schema migrations up to %s", step.Version)
+				}
+			}
+
+			for _, m := range step.OutOfBandMigrations {
+				if err := waitForOutOfBandMigration(ctx, out, oobStore, m, step.Version, pollIntervalFlag.Get(cmd)); err != nil {
+					return err
+				}
+			}
+
+			out.WriteLine(output.Linef(output.EmojiSuccess, output.StyleSuccess, "Checkpoint %d: schemas are at %s", checkpoint, step.Version))
+			if step.Version == to {
+				break
+			}
+
+			migrations, err := oobStore.List(ctx)
+			if err != nil {
+				return err
+			}
+			if plan, err = multiversion.PlanUpgrade(step.Version, to, multiversion.ReleaseLeaves, target, migrations); err != nil {
+				return err
+			}
+
+			if discovered := plannedOutOfBandMigrations(plan); !isSubset(discovered, planned) {
+				out.WriteLine(output.Linef(output.EmojiInfo, output.StyleReset, "Schema migrations up to %s registered new out-of-band migrations", step.Version))
+				out.Write(plan.String())
+				planned = discovered
+			}
+		}
+
+		return nil
+	})
+
+	return &cli.Command{
+		Name:        "upgrade",
+		UsageText:   fmt.Sprintf("%s upgrade -from=<version> -to=<version> [-dry-run]", commandName),
+		Usage:       "Upgrade across multiple releases, interleaving schema and out-of-band migrations",
+		Description: ConstructLongHelp(),
+		Action:      action,
+		Flags: []cli.Flag{
+			fromFlag,
+			toFlag,
+			dryRunFlag,
+			pollIntervalFlag,
+			unprivilegedOnlyFlag,
+		},
+	}
+}
+
+// nextRelease returns the earliest release with a known schema state that is after from and
+// before to.
+func nextRelease(from, to multiversion.Version) (next multiversion.Version, ok bool) {
+	for version := range multiversion.ReleaseLeaves {
+		if !from.Before(version) || !version.Before(to) {
+			continue
+		}
+		if !ok || version.Before(next) {
+			next, ok = version, true
+		}
+	}
+
+	return next, ok
+}
+
+// plannedOutOfBandMigrations returns the identifiers of the out-of-band migrations that the
+// given plan waits for.
+func plannedOutOfBandMigrations(plan multiversion.Plan) map[int]struct{} {
+	ids := map[int]struct{}{}
+	for _, step := range plan.Steps {
+		for _, m := range step.OutOfBandMigrations {
+			ids[m.ID] = struct{}{}
+		}
+	}
+
+	return ids
+}
+
+func isSubset(a, b map[int]struct{}) bool {
+	for id := range a {
+		if _, ok := b[id]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// targetLeaves returns the leaf migrations of each schema for the given release. Releases
+// without a recorded schema state can only be targeted by the migrator of that release,
+// which uses its embedded migration definitions.
+func targetLeaves(to multiversion.Version) (multiversion.SchemaLeaves, error) {
+	if leaves, ok := multiversion.ReleaseLeaves[to]; ok {
+		return leaves, nil
+	}
+	if current, err := multiversion.ParseVersion(version.Version()); err == nil && current != to {
+		return nil, errors.Newf("no known schema state for %s; use the migrator of release %s (this is %s)", to, to, current)
+	}
+
+	leaves := make(multiversion.SchemaLeaves, len(schemas.Schemas))
+	for _, schema := range schemas.Schemas {
+		for _, definition := range schema.Definitions.Leaves() {
+			leaves[schema.Name] = append(leaves[schema.Name], definition.ID)
+		}
+	}
+
+	return leaves, nil
+}
+
+// targetedUpOperations returns operations that migrate each schema up to the given leaves.
+func targetedUpOperations(leaves multiversion.SchemaLeaves) []runner.MigrationOperation {
+	schemaNames := make([]string, 0, len(leaves))
+	for schemaName := range leaves {
+		schemaNames = append(schemaNames, schemaName)
+	}
+	sort.Strings(schemaNames)
+
+	operations := make([]runner.MigrationOperation, 0, len(schemaNames))
+	for _, schemaName := range schemaNames {
+		operations = append(operations, runner.MigrationOperation{
+			SchemaName:     schemaName,
+			Type:           runner.MigrationOperationTypeTargetedUp,
+			TargetVersions: leaves[schemaName],
+		})
+	}
+
+	return operations
+}
+
+// waitForOutOfBandMigration blocks until the given out-of-band migration completes. The
+// migrator cannot run out-of-band migrations itself; they are run by the instance, which
+// must be running the given release while this function waits.
+func waitForOutOfBandMigration(ctx context.Context, out *output.Output, store OutOfBandMigrationStore, m multiversion.OutOfBandMigration, runningVersion multiversion.Version, interval time.Duration) error {
+	if m.ApplyReverse {
+		// A previous downgrade may have reversed this migration; run it forward again
+		if err := store.UpdateDirection(ctx, m.ID, false); err != nil {
+			return err
+		}
+	}
+
+	out.WriteLine(output.Linef(output.EmojiInfo, output.StyleReset, "Run Sourcegraph %s until out-of-band migration %d (%s) completes", runningVersion, m.ID, m.Description))
+	pending := out.Pending(output.Linef("", output.StylePending, "Waiting for out-of-band migration %d (%.2f%%)...", m.ID, m.Progress*100))
+
+	for {
+		migrations, err := store.List(ctx)
+		if err != nil {
+			pending.Destroy()
+			return err
+		}
+
+		migration, ok := findOutOfBandMigration(migrations, m.ID)
+		if !ok {
+			pending.Destroy()
+			return errors.Newf("out-of-band migration %d (%s) no longer exists", m.ID, m.Description)
+		}
+		if migration.Complete() {
+			pending.Complete(output.Linef(output.EmojiSuccess, output.StyleSuccess, "Out-of-band migration %d is complete", m.ID))
+			return nil
+		}
+		pending.Updatef("Waiting for out-of-band migration %d (%.2f%%)...", m.ID, migration.Progress*100)
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			pending.Destroy()
+			return ctx.Err()
+		}
+	}
+}
+
+func findOutOfBandMigration(migrations []multiversion.OutOfBandMigration, id int) (multiversion.OutOfBandMigration, bool) {
+	for _, m := range migrations {
+		if m.ID == id {
+			return m, true
+		}
+	}
+
+	return multiversion.OutOfBandMigration{}, false
+}
@@ -0,0 +1,154 @@
+package multiversion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// Plan is the ordered sequence of steps that upgrades an instance between two releases.
+type Plan struct {
+	From  Version
+	To    Version
+	Steps []Step
+}
+
+// Step applies schema migrations up to the state of a release, then waits for the given
+// out-of-band migrations to complete. Out-of-band migrations are run by the instance, not
+// by the migrator, so a step that lists them must be paired with running the release
+// named by Version until they finish.
+type Step struct {
+	// Version is the release whose schema state this step reaches.
+	Version Version
+
+	// Leaves are the leaf migrations of each schema at Version. This value is nil if the
+	// step does not apply any schema migrations.
+	Leaves SchemaLeaves
+
+	// OutOfBandMigrations must be complete before any schema migration of the next step
+	// is applied.
+	OutOfBandMigrations []OutOfBandMigration
+}
+
+// PlanUpgrade returns the steps required to upgrade from one release to another. The given
+// releases map supplies the schema state of intermediate releases at which the upgrade may
+// stop, and target supplies the schema state of the final release.
+//
+// An out-of-band migration that is deprecated in a release after from and no later than to
+// must complete before the schema is migrated to that release. Each such migration is
+// scheduled at the latest release that still runs it, so that migrations deprecated in the
+// same release share a single stop.
+func PlanUpgrade(from, to Version, releases map[Version]SchemaLeaves, target SchemaLeaves, migrations []OutOfBandMigration) (Plan, error) {
+	if to.Before(from) {
+		return Plan{}, errors.Newf("cannot upgrade from %s to the earlier release %s", from, to)
+	}
+	if target == nil {
+		return Plan{}, errors.Newf("unknown schema state for target release %s", to)
+	}
+
+	stops := map[Version][]OutOfBandMigration{}
+	for _, m := range migrations {
+		if m.Deprecated == nil || m.Complete() || to.Before(*m.Deprecated) {
+			continue
+		}
+
+		if !from.Before(*m.Deprecated) {
+			return Plan{}, errors.Newf(
+				"out-of-band migration %d (%s) was deprecated in %s but is not complete; it must be completed by a release prior to %s before upgrading",
+				m.ID, m.Description, m.Deprecated, m.Deprecated,
+			)
+		}
+
+		stop, ok := latestStop(from, m.Introduced, *m.Deprecated, releases)
+		if !ok {
+			return Plan{}, errors.Newf(
+				"out-of-band migration %d (%s) must complete between %s and %s, but no release in that range has a known schema state",
+				m.ID, m.Description, m.Introduced, m.Deprecated,
+			)
+		}
+
+		stops[stop] = append(stops[stop], m)
+	}
+
+	versions := make([]Version, 0, len(stops))
+	for version := range stops {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Before(versions[j]) })
+
+	steps := make([]Step, 0, len(versions)+1)
+	for _, version := range versions {
+		migrations := stops[version]
+		sort.Slice(migrations, func(i, j int) bool { return migrations[i].ID < migrations[j].ID })
+
+		step := Step{Version: version, OutOfBandMigrations: migrations}
+		if version != from {
+			step.Leaves = releases[version]
+		}
+		steps = append(steps, step)
+	}
+	steps = append(steps, Step{Version: to, Leaves: target})
+
+	return Plan{From: from, To: to, Steps: steps}, nil
+}
+
+// latestStop returns the latest release at which an out-of-band migration introduced and
+// deprecated in the given releases can be run to completion. Candidates are the starting
+// release and any later release with a known schema state.
+func latestStop(from, introduced, deprecated Version, releases map[Version]SchemaLeaves) (stop Version, ok bool) {
+	if !from.Before(introduced) {
+		stop, ok = from, true
+	}
+
+	for version := range releases {
+		if !from.Before(version) || version.Before(introduced) || !version.Before(deprecated) {
+			continue
+		}
+		if !ok || stop.Before(version) {
+			stop, ok = version, true
+		}
+	}
+
+	return stop, ok
+}
+
+// String returns a human-readable description of the plan.
+func (p Plan) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Upgrade from %s to %s:\n", p.From, p.To)
+
+	for i, step := range p.Steps {
+		if step.Leaves != nil {
+			fmt.Fprintf(&sb, "  %d. Apply schema migrations up to %s (%s)\n", i+1, step.Version, formatLeaves(step.Leaves))
+		} else {
+			fmt.Fprintf(&sb, "  %d. Remain at %s\n", i+1, step.Version)
+		}
+
+		for _, m := range step.OutOfBandMigrations {
+			fmt.Fprintf(&sb, "     - Wait for out-of-band migration %d (%s: %s) to complete\n", m.ID, m.Component, m.Description)
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func formatLeaves(leaves SchemaLeaves) string {
+	schemaNames := make([]string, 0, len(leaves))
+	for schemaName := range leaves {
+		schemaNames = append(schemaNames, schemaName)
+	}
+	sort.Strings(schemaNames)
+
+	parts := make([]string, 0, len(schemaNames))
+	for _, schemaName := range schemaNames {
+		ids := make([]string, 0, len(leaves[schemaName]))
+		for _, id := range leaves[schemaName] {
+			ids = append(ids, fmt.Sprintf("%d", id))
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", schemaName, strings.Join(ids, ", ")))
+	}
+
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,101 @@
+package multiversion
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlanUpgrade(t *testing.T) {
+	v := NewVersion
+	deprecated := func(major, minor int) *Version {
+		version := v(major, minor)
+		return &version
+	}
+
+	releases := map[Version]SchemaLeaves{
+		v(3, 38): {"frontend": {138}},
+		v(3, 39): {"frontend": {139}},
+		v(3, 40): {"frontend": {140}},
+	}
+	target := SchemaLeaves{"frontend": {142}}
+
+	migrations := []OutOfBandMigration{
+		{ID: 1, Introduced: v(3, 30)}, // never deprecated
+		{ID: 2, Introduced: v(3, 30), Deprecated: deprecated(3, 38), Progress: 1}, // already complete
+		{ID: 3, Introduced: v(3, 36), Deprecated: deprecated(3, 39)},
+		{ID: 4, Introduced: v(3, 38), Deprecated: deprecated(3, 41)},
+		{ID: 5, Introduced: v(3, 39), Deprecated: deprecated(3, 41)},
+		{ID: 6, Introduced: v(3, 40), Deprecated: deprecated(3, 45)}, // deprecated after target
+	}
+
+	plan, err := PlanUpgrade(v(3, 37), v(3, 42), releases, target, migrations)
+	if err != nil {
+		t.Fatalf("unexpected error planning upgrade: %s", err)
+	}
+
+	expectedPlan := Plan{
+		From: v(3, 37),
+		To:   v(3, 42),
+		Steps: []Step{
+			{Version: v(3, 38), Leaves: SchemaLeaves{"frontend": {138}}, OutOfBandMigrations: []OutOfBandMigration{migrations[2]}},
+			{Version: v(3, 40), Leaves: SchemaLeaves{"frontend": {140}}, OutOfBandMigrations: []OutOfBandMigration{migrations[3], migrations[4]}},
+			{Version: v(3, 42), Leaves: target},
+		},
+	}
+	if diff := cmp.Diff(expectedPlan, plan); diff != "" {
+		t.Errorf("unexpected plan (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlanUpgradeStopsAtStartingVersion(t *testing.T) {
+	deprecated := NewVersion(3, 42)
+	migrations := []OutOfBandMigration{{ID: 1, Introduced: NewVersion(3, 35), Deprecated: &deprecated, Progress: 0.5}}
+	target := SchemaLeaves{"frontend": {142}}
+
+	plan, err := PlanUpgrade(NewVersion(3, 40), NewVersion(3, 42), nil, target, migrations)
+	if err != nil {
+		t.Fatalf("unexpected error planning upgrade: %s", err)
+	}
+
+	expectedSteps := []Step{
+		{Version: NewVersion(3, 40), OutOfBandMigrations: migrations},
+		{Version: NewVersion(3, 42), Leaves: target},
+	}
+	if diff := cmp.Diff(expectedSteps, plan.Steps); diff != "" {
+		t.Errorf("unexpected steps (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlanUpgradeErrors(t *testing.T) {
+	deprecated := NewVersion(3, 40)
+	target := SchemaLeaves{"frontend": {142}}
+
+	testCases := map[string]struct {
+		from, to   Version
+		migrations []OutOfBandMigration
+	}{
+		"downgrade": {
+			from: NewVersion(3, 42),
+			to:   NewVersion(3, 40),
+		},
+		"incomplete deprecated migration": {
+			from:       NewVersion(3, 41),
+			to:         NewVersion(3, 42),
+			migrations: []OutOfBandMigration{{ID: 1, Introduced: NewVersion(3, 30), Deprecated: &deprecated}},
+		},
+		"no known stop": {
+			from:       NewVersion(3, 37),
+			to:         NewVersion(3, 42),
+			migrations: []OutOfBandMigration{{ID: 1, Introduced: NewVersion(3, 38), Deprecated: &deprecated}},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := PlanUpgrade(testCase.from, testCase.to, nil, target, testCase.migrations); err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package multiversion
+
+// SchemaLeaves maps a schema name to the identifiers of its leaf migrations.
+type SchemaLeaves map[string][]int
+
+// ReleaseLeaves records the leaf migrations of each schema as shipped in a tagged release.
+// Upgrades can only stop at intermediate releases listed here (or at the starting version,
+// whose schema state is already applied). Add the release to this map when cutting a
+// release branch, copying the leaves printed by `sg migration leaves`.
+//
+// Releases prior to 3.38 are not listed, as their migrations have been squashed.
+var ReleaseLeaves = map[Version]SchemaLeaves{
+	NewVersion(3, 38): {
+		"frontend":     {1647849753, 1647860082},
+		"codeintel":    {1000000034},
+		"codeinsights": {1647894746},
+	},
+	NewVersion(3, 39): {
+		"frontend":     {1649159359, 1650456734},
+		"codeintel":    {1000000034},
+		"codeinsights": {1649801281},
+	},
+	NewVersion(3, 40): {
+		"frontend":     {1652946496, 1652964210},
+		"codeintel":    {1000000034},
+		"codeinsights": {1651021000, 1652289966},
+	},
+	NewVersion(3, 41): {
+		"frontend":     {1653472246, 1655157509, 1655454264, 1655843069},
+		"codeintel":    {1000000034},
+		"codeinsights": {1651021000, 1652289966},
+	},
+}
@@ -0,0 +1,25 @@
+package multiversion
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/migration/schemas"
+)
+
+func TestReleaseLeaves(t *testing.T) {
+	for version, leaves := range ReleaseLeaves {
+		for _, schema := range schemas.Schemas {
+			ids, ok := leaves[schema.Name]
+			if !ok {
+				t.Errorf("release %s has no leaves for schema %q", version, schema.Name)
+				continue
+			}
+
+			for _, id := range ids {
+				if _, ok := schema.Definitions.GetByID(id); !ok {
+					t.Errorf("release %s has unknown %s migration %d", version, schema.Name, id)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package multiversion
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+)
+
+// OutOfBandMigration is the subset of an out-of-band migration record needed to plan an
+// upgrade. It mirrors oobmigration.Migration, which cannot be imported by the migrator
+// without also importing the frontend.
+type OutOfBandMigration struct {
+	ID           int
+	Team         string
+	Component    string
+	Description  string
+	Introduced   Version
+	Deprecated   *Version
+	Progress     float64
+	ApplyReverse bool
+}
+
+// Complete returns true if the migration has finished migrating records forward.
+func (m OutOfBandMigration) Complete() bool {
+	return m.Progress == 1 && !m.ApplyReverse
+}
+
+// Store reads and updates out-of-band migration records in the frontend database.
+type Store struct {
+	*basestore.Store
+}
+
+func NewStoreWithDB(db *sql.DB) *Store {
+	return &Store{Store: basestore.NewWithHandle(basestore.NewHandleWithDB(db, sql.TxOptions{}))}
+}
+
+// List returns all out-of-band migrations, including enterprise migrations.
+func (s *Store) List(ctx context.Context) ([]OutOfBandMigration, error) {
+	return scanOutOfBandMigrations(s.Query(ctx, sqlf.Sprintf(listQuery)))
+}
+
+const listQuery = `
+-- source: internal/database/migration/multiversion/store.go:List
+SELECT
+	m.id,
+	m.team,
+	m.component,
+	m.description,
+	m.introduced_version_major,
+	m.introduced_version_minor,
+	m.deprecated_version_major,
+	m.deprecated_version_minor,
+	m.progress,
+	m.apply_reverse
+FROM out_of_band_migrations m
+ORDER BY m.id
+`
+
+// UpdateDirection updates the direction for the given migration.
+func (s *Store) UpdateDirection(ctx context.Context, id int, applyReverse bool) error {
+	return s.Exec(ctx, sqlf.Sprintf(updateDirectionQuery, applyReverse, id))
+}
+
+const updateDirectionQuery = `
+-- source: internal/database/migration/multiversion/store.go:UpdateDirection
+UPDATE out_of_band_migrations SET apply_reverse = %s WHERE id = %s
+`
+
+var scanOutOfBandMigrations = basestore.NewSliceScanner(scanOutOfBandMigration)
+
+func scanOutOfBandMigration(s dbutil.Scanner) (m OutOfBandMigration, _ error) {
+	var deprecatedMajor, deprecatedMinor *int
+	if err := s.Scan(
+		&m.ID,
+		&m.Team,
+		&m.Component,
+		&m.Description,
+		&m.Introduced.Major,
+		&m.Introduced.Minor,
+		&deprecatedMajor,
+		&deprecatedMinor,
+		&m.Progress,
+		&m.ApplyReverse,
+	); err != nil {
+		return m, err
+	}
+
+	if deprecatedMajor != nil && deprecatedMinor != nil {
+		deprecated := NewVersion(*deprecatedMajor, *deprecatedMinor)
+		m.Deprecated = &deprecated
+	}
+
+	return m, nil
+}
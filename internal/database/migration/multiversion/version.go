@@ -0,0 +1,51 @@
+package multiversion
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// Version is a Sourcegraph minor release. Upgrades are planned between minor releases, as
+// patch releases never contain schema or out-of-band migrations.
+type Version struct {
+	Major int
+	Minor int
+}
+
+func NewVersion(major, minor int) Version {
+	return Version{
+		Major: major,
+		Minor: minor,
+	}
+}
+
+var versionPattern = lazyregexp.New(`^v?(\d+)\.(\d+)(?:\.\d+)?$`)
+
+// ParseVersion parses a version of the form `3.41`, `3.41.2`, or `v3.41.2`. The patch
+// component, if any, is discarded.
+func ParseVersion(rawVersion string) (Version, error) {
+	matches := versionPattern.FindStringSubmatch(rawVersion)
+	if len(matches) == 0 {
+		return Version{}, errors.Newf("invalid version %q: expected a version of the form 3.41 or v3.41.2", rawVersion)
+	}
+
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	return NewVersion(major, minor), nil
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// Before returns true if v is a release prior to other.
+func (v Version) Before(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+
+	return v.Minor < other.Minor
+}
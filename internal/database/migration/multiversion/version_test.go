@@ -0,0 +1,44 @@
+package multiversion
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	for rawVersion, expected := range map[string]Version{
+		"3.41":    NewVersion(3, 41),
+		"3.41.2":  NewVersion(3, 41),
+		"v3.41.2": NewVersion(3, 41),
+		"v4.0":    NewVersion(4, 0),
+	} {
+		version, err := ParseVersion(rawVersion)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %s", rawVersion, err)
+		}
+		if version != expected {
+			t.Errorf("unexpected version for %q. want=%s have=%s", rawVersion, expected, version)
+		}
+	}
+
+	for _, rawVersion := range []string{"", "3", "3.41-rc.1", "main", "0.0.0+dev"} {
+		if _, err := ParseVersion(rawVersion); err == nil {
+			t.Errorf("expected error parsing %q", rawVersion)
+		}
+	}
+}
+
+func TestVersionBefore(t *testing.T) {
+	testCases := []struct {
+		a, b     Version
+		expected bool
+	}{
+		{NewVersion(3, 40), NewVersion(3, 41), true},
+		{NewVersion(3, 41), NewVersion(3, 41), false},
+		{NewVersion(3, 43), NewVersion(4, 0), true},
+		{NewVersion(4, 0), NewVersion(3, 43), false},
+	}
+
+	for _, testCase := range testCases {
+		if have := testCase.a.Before(testCase.b); have != testCase.expected {
+			t.Errorf("unexpected result for %s < %s. want=%v have=%v", testCase.a, testCase.b, testCase.expected, have)
+		}
+	}
+}
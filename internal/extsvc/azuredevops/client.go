@@ -0,0 +1,197 @@
+//nolint:bodyclose // Body is closed in Client.Do, but the response is still returned to provide access to the headers
+package azuredevops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/auth"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// apiVersion is the version of the Azure DevOps REST API requested by the client.
+// See https://docs.microsoft.com/en-us/rest/api/azure/devops/?view=azure-devops-rest-7.0#api-and-tfs-version-mapping.
+const apiVersion = "7.0"
+
+// Client access an Azure DevOps instance via the REST API.
+type Client struct {
+	// HTTP Client used to communicate with the API
+	httpClient httpcli.Doer
+
+	// URL is the base URL of Azure DevOps.
+	URL *url.URL
+
+	// Auth is the authentication method used when accessing the server. Only
+	// auth.BasicAuth is currently supported.
+	Auth auth.Authenticator
+
+	// RateLimit is the self-imposed rate limiter (since Azure DevOps throttles based on
+	// resource consumption rather than a documented request budget).
+	rateLimit *ratelimit.InstrumentedLimiter
+}
+
+// NewClient returns an authenticated Azure DevOps API client with
+// the provided configuration. If a nil httpClient is provided, http.DefaultClient
+// will be used.
+func NewClient(urn string, config *schema.AzureDevOpsConnection, httpClient httpcli.Doer) (*Client, error) {
+	u, err := url.Parse(config.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpClient == nil {
+		httpClient = httpcli.ExternalDoer
+	}
+
+	return &Client{
+		httpClient: httpClient,
+		URL:        u,
+		Auth: &auth.BasicAuth{
+			Username: config.Username,
+			Password: config.Token,
+		},
+		rateLimit: ratelimit.DefaultRegistry.Get(urn),
+	}, nil
+}
+
+// ListRepositoriesByProjectOrOrgArgs defines options to be set on
+// ListRepositoriesByProjectOrOrg method calls.
+type ListRepositoriesByProjectOrOrgArgs struct {
+	// ProjectOrOrgName is either the name of an organization ("org") or the
+	// name of a project qualified by its organization ("org/project").
+	ProjectOrOrgName string
+}
+
+// ListRepositoriesByProjectOrOrg returns all Git repositories of the given
+// organization or project. The Azure DevOps API does not paginate this endpoint.
+func (c *Client) ListRepositoriesByProjectOrOrg(ctx context.Context, opts ListRepositoriesByProjectOrOrgArgs) ([]Repository, error) {
+	if opts.ProjectOrOrgName == "" {
+		return nil, errors.New("no organization or project name given")
+	}
+
+	qs := make(url.Values)
+	qs.Set("api-version", apiVersion)
+
+	urlRepositories := url.URL{
+		Path:     path.Join(opts.ProjectOrOrgName, "_apis/git/repositories"),
+		RawQuery: qs.Encode(),
+	}
+
+	req, err := http.NewRequest("GET", urlRepositories.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ListRepositoriesResponse
+	if _, err = c.do(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Value, nil
+}
+
+// ListRepositoriesResponse defines a response struct returned from
+// ListRepositoriesByProjectOrOrg method calls.
+type ListRepositoriesResponse struct {
+	Value []Repository `json:"value"`
+	Count int          `json:"count"`
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request, result any) (*http.Response, error) {
+	// The base URL may include a path (e.g. the collection of an Azure DevOps
+	// Server instance), so we join the paths rather than resolving a reference.
+	u := *c.URL
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + strings.TrimPrefix(req.URL.Path, "/")
+	u.RawPath = ""
+	u.RawQuery = req.URL.RawQuery
+	req.URL = &u
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+
+	if err := c.Auth.Authenticate(req); err != nil {
+		return nil, err
+	}
+
+	if err := c.rateLimit.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	bs, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Azure DevOps responds to requests with invalid credentials with a 203 and
+	// an HTML sign in page rather than a 401, so we treat it as an error too.
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || resp.StatusCode == http.StatusNonAuthoritativeInfo {
+		return nil, &httpError{
+			URL:        req.URL,
+			StatusCode: resp.StatusCode,
+			Body:       bs,
+		}
+	}
+
+	return resp, json.Unmarshal(bs, result)
+}
+
+// Repository is a Git repository hosted on Azure DevOps.
+type Repository struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	CloneURL   string  `json:"remoteUrl"`
+	APIURL     string  `json:"url"`
+	SSHURL     string  `json:"sshUrl"`
+	WebURL     string  `json:"webUrl"`
+	IsDisabled bool    `json:"isDisabled"`
+	IsFork     bool    `json:"isFork"`
+	Project    Project `json:"project"`
+}
+
+// Project is the Azure DevOps project a repository belongs to.
+type Project struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	State      string `json:"state"`
+	Visibility string `json:"visibility"`
+	URL        string `json:"url"`
+}
+
+// IsPrivate returns true if the repository is not visible to anonymous users.
+func (r *Repository) IsPrivate() bool {
+	return r.Project.Visibility != "public"
+}
+
+type httpError struct {
+	StatusCode int
+	URL        *url.URL
+	Body       []byte
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("Azure DevOps API HTTP error: code=%d url=%q body=%q", e.StatusCode, e.URL, e.Body)
+}
+
+func (e *httpError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusNonAuthoritativeInfo
+}
+
+func (e *httpError) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,118 @@
+package azuredevops
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestClient_ListRepositoriesByProjectOrOrg(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		baseURL      string
+		projectOrOrg string
+		wantPath     string
+	}{
+		{
+			name:         "organization",
+			projectOrOrg: "sgtest",
+			wantPath:     "/sgtest/_apis/git/repositories",
+		},
+		{
+			name:         "project",
+			projectOrOrg: "sgtest/my project",
+			wantPath:     "/sgtest/my project/_apis/git/repositories",
+		},
+		{
+			name:         "base url with path",
+			baseURL:      "/tfs",
+			projectOrOrg: "DefaultCollection",
+			wantPath:     "/tfs/DefaultCollection/_apis/git/repositories",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tc.wantPath {
+					t.Errorf("unexpected path. want=%q have=%q", tc.wantPath, r.URL.Path)
+				}
+				if have := r.URL.Query().Get("api-version"); have != apiVersion {
+					t.Errorf("unexpected api version. want=%q have=%q", apiVersion, have)
+				}
+				if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "token" {
+					t.Errorf("unexpected credentials. have=%q:%q", username, password)
+				}
+
+				_, _ = w.Write([]byte(`{
+					"count": 1,
+					"value": [{
+						"id": "5febef5a-833d-4e14-b9c0-14cb638f91e6",
+						"name": "repo",
+						"remoteUrl": "https://sgtest@dev.azure.com/sgtest/project/_git/repo",
+						"sshUrl": "git@ssh.dev.azure.com:v3/sgtest/project/repo",
+						"project": {"id": "2f9d1f9f-0b1b-4f5d-b3fd-7c0c1c2a8d3e", "name": "project", "visibility": "private"}
+					}]
+				}`))
+			}))
+			defer srv.Close()
+
+			cli, err := NewClient("urn", &schema.AzureDevOpsConnection{
+				Url:      srv.URL + tc.baseURL,
+				Username: "user",
+				Token:    "token",
+			}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			repos, err := cli.ListRepositoriesByProjectOrOrg(context.Background(), ListRepositoriesByProjectOrOrgArgs{
+				ProjectOrOrgName: tc.projectOrOrg,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := []Repository{{
+				ID:       "5febef5a-833d-4e14-b9c0-14cb638f91e6",
+				Name:     "repo",
+				CloneURL: "https://sgtest@dev.azure.com/sgtest/project/_git/repo",
+				SSHURL:   "git@ssh.dev.azure.com:v3/sgtest/project/repo",
+				Project: Project{
+					ID:         "2f9d1f9f-0b1b-4f5d-b3fd-7c0c1c2a8d3e",
+					Name:       "project",
+					Visibility: "private",
+				},
+			}}
+			if diff := cmp.Diff(want, repos); diff != "" {
+				t.Errorf("unexpected repositories (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClient_ListRepositoriesByProjectOrOrg_Unauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Azure DevOps serves a sign in page for invalid credentials.
+		w.WriteHeader(http.StatusNonAuthoritativeInfo)
+		_, _ = w.Write([]byte(`<html></html>`))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient("urn", &schema.AzureDevOpsConnection{Url: srv.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cli.ListRepositoriesByProjectOrOrg(context.Background(), ListRepositoriesByProjectOrOrgArgs{
+		ProjectOrOrgName: "sgtest",
+	})
+	var e *httpError
+	if !errors.As(err, &e) || !e.Unauthorized() {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+}
@@ -45,6 +45,10 @@ type Client interface {
 	ForkRepository(ctx context.Context, upstream *Repo, input ForkInput) (*Repo, error)
 
 	CurrentUser(ctx context.Context) (*User, error)
+
+	WorkspaceMembers(workspace string) (*PaginatedResultSet, error)
+	WorkspaceRepoPermissions(workspace, query string) (*PaginatedResultSet, error)
+	RepoUserPermissions(workspace, slug string) (*PaginatedResultSet, error)
 }
 
 // client access a Bitbucket Cloud via the REST API 2.0.
@@ -33,6 +33,8 @@ func ParseWebhookEvent(eventKey string, payload []byte) (any, error) {
 		target = &RepoCommitStatusCreatedEvent{}
 	case "repo:commit_status_updated":
 		target = &RepoCommitStatusUpdatedEvent{}
+	case "repo:push":
+		target = &RepoPushEvent{}
 	default:
 		return nil, UnknownWebhookEventKey(eventKey)
 	}
@@ -132,6 +134,30 @@ type RepoCommitStatusUpdatedEvent struct {
 	RepoCommitStatusEvent
 }
 
+type RepoPushEvent struct {
+	RepoEvent
+	Push RepoPush `json:"push"`
+}
+
+type RepoPush struct {
+	Changes []RepoPushChange `json:"changes"`
+}
+
+type RepoPushChange struct {
+	New     *RepoPushRef `json:"new"`
+	Old     *RepoPushRef `json:"old"`
+	Created bool         `json:"created"`
+	Closed  bool         `json:"closed"`
+	Forced  bool         `json:"forced"`
+}
+
+// RepoPushRef is the state of a branch or tag before or after a push.
+type RepoPushRef struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Target Commit `json:"target"`
+}
+
 type CommitStatus struct {
 	Name        string                 `json:"name"`
 	Description string                 `json:"description"`
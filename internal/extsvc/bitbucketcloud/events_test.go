@@ -59,6 +59,10 @@ func TestParseWebhookEvent(t *testing.T) {
 			payload:  `{"commit_status":{},"pullrequest":{},"repository":{}}`,
 			wantType: &RepoCommitStatusUpdatedEvent{},
 		},
+		"repo:push": {
+			payload:  `{"push":{"changes":[{"new":{"type":"branch","name":"main"},"old":null}]},"repository":{}}`,
+			wantType: &RepoPushEvent{},
+		},
 	} {
 		t.Run(key, func(t *testing.T) {
 			t.Run("success", func(t *testing.T) {
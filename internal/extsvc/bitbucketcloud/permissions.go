@@ -0,0 +1,119 @@
+package bitbucketcloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// WorkspaceMembers retrieves the members of a workspace.
+//
+// Each item in the result set is a *WorkspaceMembership.
+func (c *client) WorkspaceMembers(workspace string) (*PaginatedResultSet, error) {
+	u, err := url.Parse(fmt.Sprintf("/2.0/workspaces/%s/members", url.PathEscape(workspace)))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing URL")
+	}
+
+	return NewPaginatedResultSet(u, func(ctx context.Context, req *http.Request) (*PageToken, []any, error) {
+		var page struct {
+			*PageToken
+			Values []*WorkspaceMembership `json:"values"`
+		}
+
+		if err := c.do(ctx, req, &page); err != nil {
+			return nil, nil, err
+		}
+
+		values := []any{}
+		for _, value := range page.Values {
+			values = append(values, value)
+		}
+
+		return page.PageToken, values, nil
+	}), nil
+}
+
+// WorkspaceRepoPermissions retrieves the permissions of all users on all
+// repositories of a workspace, including the ones granted through groups. The
+// permissions can be filtered with a query, such as `user.uuid="{...}"`. See
+// https://developer.atlassian.com/cloud/bitbucket/rest/intro/#filtering for
+// the query syntax.
+//
+// Listing permissions requires the authenticated user to be an administrator
+// of the workspace.
+//
+// Each item in the result set is a *RepoPermission.
+func (c *client) WorkspaceRepoPermissions(workspace, query string) (*PaginatedResultSet, error) {
+	u, err := url.Parse(fmt.Sprintf("/2.0/workspaces/%s/permissions/repositories", url.PathEscape(workspace)))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing URL")
+	}
+	if query != "" {
+		u.RawQuery = url.Values{"q": []string{query}}.Encode()
+	}
+
+	return c.repoPermissions(u), nil
+}
+
+// RepoUserPermissions retrieves the permissions of all users on a single
+// repository, including the ones granted through groups.
+//
+// Listing permissions requires the authenticated user to be an administrator
+// of the workspace.
+//
+// Each item in the result set is a *RepoPermission.
+func (c *client) RepoUserPermissions(workspace, slug string) (*PaginatedResultSet, error) {
+	u, err := url.Parse(fmt.Sprintf("/2.0/workspaces/%s/permissions/repositories/%s", url.PathEscape(workspace), url.PathEscape(slug)))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing URL")
+	}
+
+	return c.repoPermissions(u), nil
+}
+
+func (c *client) repoPermissions(u *url.URL) *PaginatedResultSet {
+	return NewPaginatedResultSet(u, func(ctx context.Context, req *http.Request) (*PageToken, []any, error) {
+		var page struct {
+			*PageToken
+			Values []*RepoPermission `json:"values"`
+		}
+
+		if err := c.do(ctx, req, &page); err != nil {
+			return nil, nil, err
+		}
+
+		values := []any{}
+		for _, value := range page.Values {
+			values = append(values, value)
+		}
+
+		return page.PageToken, values, nil
+	})
+}
+
+// WorkspaceMembership is the membership of a user in a workspace.
+type WorkspaceMembership struct {
+	User      *Account   `json:"user"`
+	Workspace *Workspace `json:"workspace"`
+}
+
+// RepoPermission is the permission of a user on a repository.
+type RepoPermission struct {
+	Permission RepoPermissionLevel `json:"permission"`
+	User       *Account            `json:"user"`
+	Repository *Repo               `json:"repository"`
+}
+
+// RepoPermissionLevel is the level of a RepoPermission. Each level includes
+// the permissions of the levels below it.
+type RepoPermissionLevel string
+
+const (
+	RepoPermissionLevelRead  RepoPermissionLevel = "read"
+	RepoPermissionLevelWrite RepoPermissionLevel = "write"
+	RepoPermissionLevelAdmin RepoPermissionLevel = "admin"
+)
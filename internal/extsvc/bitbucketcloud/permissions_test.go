@@ -0,0 +1,84 @@
+package bitbucketcloud
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestClient_WorkspaceRepoPermissions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/2.0/workspaces/sourcegraph/permissions/repositories", r.URL.Path)
+		assert.Equal(t, `user.uuid="{1234}"`, r.URL.Query().Get("q"))
+
+		if r.URL.Query().Get("page") == "" {
+			_, _ = w.Write([]byte(`{
+  "pagelen": 1,
+  "next": "` + "http://" + r.Host + r.URL.Path + `?q=user.uuid%3D%22%7B1234%7D%22&page=2",
+  "values": [{"permission": "read", "user": {"uuid": "{1234}"}, "repository": {"uuid": "{a}", "full_name": "sourcegraph/a"}}]
+}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{
+  "pagelen": 1,
+  "values": [{"permission": "admin", "user": {"uuid": "{1234}"}, "repository": {"uuid": "{b}", "full_name": "sourcegraph/b"}}]
+}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := newClient("urn", &schema.BitbucketCloudConnection{ApiURL: srv.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := c.WorkspaceRepoPermissions("sourcegraph", `user.uuid="{1234}"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	perms, err := rs.All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []any{
+		&RepoPermission{Permission: RepoPermissionLevelRead, User: &Account{UUID: "{1234}"}, Repository: &Repo{UUID: "{a}", FullName: "sourcegraph/a"}},
+		&RepoPermission{Permission: RepoPermissionLevelAdmin, User: &Account{UUID: "{1234}"}, Repository: &Repo{UUID: "{b}", FullName: "sourcegraph/b"}},
+	}, perms)
+}
+
+func TestClient_RepoUserPermissions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/2.0/workspaces/sourcegraph/permissions/repositories/src-cli", r.URL.Path)
+
+		_, _ = w.Write([]byte(`{
+  "values": [{"permission": "write", "user": {"uuid": "{1234}", "nickname": "alice"}}]
+}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := newClient("urn", &schema.BitbucketCloudConnection{ApiURL: srv.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := c.RepoUserPermissions("sourcegraph", "src-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	perms, err := rs.All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []any{
+		&RepoPermission{Permission: RepoPermissionLevelWrite, User: &Account{UUID: "{1234}", Nickname: "alice"}},
+	}, perms)
+}
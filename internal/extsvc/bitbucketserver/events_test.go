@@ -0,0 +1,39 @@
+package bitbucketserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseWebhookEvent_RefsChanged(t *testing.T) {
+	payload := []byte(`{
+		"eventKey": "repo:refs_changed",
+		"repository": {"id": 42, "slug": "sourcegraph", "project": {"key": "SG"}},
+		"changes": [
+			{"refId": "refs/heads/main", "fromHash": "abc", "toHash": "def", "type": "UPDATE"},
+			{"refId": "refs/tags/v1.0.0", "fromHash": "0000000000000000000000000000000000000000", "toHash": "def", "type": "ADD"}
+		]
+	}`)
+
+	e, err := ParseWebhookEvent("repo:refs_changed", payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, ok := e.(*RefsChangedEvent)
+	if !ok {
+		t.Fatalf("unexpected event type: %T", e)
+	}
+	if want := 42; event.Repository.ID != want {
+		t.Errorf("unexpected repository ID: have %d; want %d", event.Repository.ID, want)
+	}
+
+	want := []RefChange{
+		{RefID: "refs/heads/main", FromHash: "abc", ToHash: "def", Type: "UPDATE"},
+		{RefID: "refs/tags/v1.0.0", FromHash: "0000000000000000000000000000000000000000", ToHash: "def", Type: "ADD"},
+	}
+	if diff := cmp.Diff(want, event.Changes); diff != "" {
+		t.Errorf("unexpected changes (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,262 @@
+package gerrit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// changeOptions are the additional fields requested whenever we load a change,
+// see https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#query-options.
+var changeOptions = []string{
+	"CURRENT_REVISION",
+	"CURRENT_COMMIT",
+	"DETAILED_LABELS",
+	"DETAILED_ACCOUNTS",
+}
+
+// ChangeTriplet returns the "project~branch~Change-Id" identifier of a change,
+// which can be used to look up a change before its number is known.
+func ChangeTriplet(project, branch, changeID string) string {
+	return project + "~" + branch + "~" + changeID
+}
+
+// GetChange returns the change with the given identifier, which can either be
+// the change number or a triplet as returned by ChangeTriplet.
+func (c *Client) GetChange(ctx context.Context, id string) (*Change, error) {
+	qs := make(url.Values)
+	for _, o := range changeOptions {
+		qs.Add("o", o)
+	}
+
+	req, err := http.NewRequest("GET", changeURL(id, "")+"?"+qs.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var change Change
+	if _, err := c.do(ctx, req, &change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// AbandonChange abandons the change with the given identifier.
+func (c *Client) AbandonChange(ctx context.Context, id string) error {
+	return c.changeAction(ctx, "POST", id, "abandon", struct{}{})
+}
+
+// RestoreChange restores the abandoned change with the given identifier.
+func (c *Client) RestoreChange(ctx context.Context, id string) error {
+	return c.changeAction(ctx, "POST", id, "restore", struct{}{})
+}
+
+// SubmitChange submits the change with the given identifier, merging it into
+// its target branch. If the change can't be submitted, an error for which
+// IsConflict returns true is returned.
+func (c *Client) SubmitChange(ctx context.Context, id string) error {
+	return c.changeAction(ctx, "POST", id, "submit", struct{}{})
+}
+
+// SetWorkInProgress marks the change with the given identifier as work in
+// progress, which hides it from reviewers until it is marked as ready.
+func (c *Client) SetWorkInProgress(ctx context.Context, id string) error {
+	return c.changeAction(ctx, "POST", id, "wip", struct{}{})
+}
+
+// SetReadyForReview marks the work in progress change with the given
+// identifier as ready for review.
+func (c *Client) SetReadyForReview(ctx context.Context, id string) error {
+	return c.changeAction(ctx, "POST", id, "ready", struct{}{})
+}
+
+// MoveChange moves the change with the given identifier to another branch.
+func (c *Client) MoveChange(ctx context.Context, id, branch string) error {
+	return c.changeAction(ctx, "POST", id, "move", MoveInput{DestinationBranch: branch})
+}
+
+// SetCommitMessage creates a new patch set of the change with the given
+// identifier that only differs in its commit message. The message must contain
+// the Change-Id trailer of the change.
+func (c *Client) SetCommitMessage(ctx context.Context, id, message string) error {
+	return c.changeAction(ctx, "PUT", id, "message", CommitMessageInput{Message: message})
+}
+
+// SetReview posts a review on the current revision of the change with the
+// given identifier.
+func (c *Client) SetReview(ctx context.Context, id string, input ReviewInput) error {
+	return c.changeAction(ctx, "POST", id, "revisions/current/review", input)
+}
+
+// GetAuthenticatedAccount returns the account the client is authenticated as.
+func (c *Client) GetAuthenticatedAccount(ctx context.Context) (*Account, error) {
+	req, err := http.NewRequest("GET", "a/accounts/self", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var account Account
+	if _, err := c.do(ctx, req, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (c *Client) changeAction(ctx context.Context, method, id, action string, input any) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, changeURL(id, action), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	// We're not interested in the responses of actions, since they are not
+	// consistent across endpoints: we reload the change afterwards anyway.
+	_, err = c.do(ctx, req, nil)
+	return err
+}
+
+// changeURL returns the relative URL of the change with the given identifier,
+// optionally followed by the given action. The identifier is escaped, since
+// project names in triplets may contain slashes.
+func changeURL(id, action string) string {
+	u := "a/changes/" + url.PathEscape(id)
+	if action != "" {
+		u += "/" + action
+	}
+	return u
+}
+
+// ChangeStatus is the status of a Gerrit change.
+type ChangeStatus string
+
+const (
+	ChangeStatusNew       ChangeStatus = "NEW"
+	ChangeStatusMerged    ChangeStatus = "MERGED"
+	ChangeStatusAbandoned ChangeStatus = "ABANDONED"
+)
+
+// Well-known labels that most Gerrit instances use for review and CI votes.
+const (
+	LabelCodeReview = "Code-Review"
+	LabelVerified   = "Verified"
+)
+
+// Change is a Gerrit change, see
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#change-info.
+type Change struct {
+	ID              string                 `json:"id"`
+	Project         string                 `json:"project"`
+	Branch          string                 `json:"branch"`
+	ChangeID        string                 `json:"change_id"`
+	Subject         string                 `json:"subject"`
+	Status          ChangeStatus           `json:"status"`
+	Created         Timestamp              `json:"created"`
+	Updated         Timestamp              `json:"updated"`
+	Number          int                    `json:"_number"`
+	Owner           Account                `json:"owner"`
+	Labels          map[string]ChangeLabel `json:"labels,omitempty"`
+	CurrentRevision string                 `json:"current_revision"`
+	Revisions       map[string]Revision    `json:"revisions,omitempty"`
+	WorkInProgress  bool                   `json:"work_in_progress,omitempty"`
+}
+
+// Current returns the current revision of the change, if the change has been
+// loaded with it.
+func (c *Change) Current() (Revision, bool) {
+	r, ok := c.Revisions[c.CurrentRevision]
+	return r, ok
+}
+
+// CommitMessage returns the commit message of the current revision of the
+// change, or an empty string if the change has been loaded without it.
+func (c *Change) CommitMessage() string {
+	if r, ok := c.Current(); ok && r.Commit != nil {
+		return r.Commit.Message
+	}
+	return ""
+}
+
+// ChangeLabel holds the votes of a label on a change.
+type ChangeLabel struct {
+	All      []Approval `json:"all,omitempty"`
+	Approved *Account   `json:"approved,omitempty"`
+	Rejected *Account   `json:"rejected,omitempty"`
+}
+
+// Approval is a single vote on a label.
+type Approval struct {
+	Account
+	Value int        `json:"value"`
+	Date  *Timestamp `json:"date,omitempty"`
+}
+
+// Revision is a patch set of a change.
+type Revision struct {
+	Number int     `json:"_number"`
+	Ref    string  `json:"ref"`
+	Commit *Commit `json:"commit,omitempty"`
+}
+
+// Commit is the commit of a revision.
+type Commit struct {
+	Parents []CommitParent `json:"parents"`
+	Subject string         `json:"subject"`
+	Message string         `json:"message"`
+}
+
+// CommitParent is a parent of a commit.
+type CommitParent struct {
+	Commit  string `json:"commit"`
+	Subject string `json:"subject"`
+}
+
+// MoveInput is the input to MoveChange.
+type MoveInput struct {
+	DestinationBranch string `json:"destination_branch"`
+}
+
+// CommitMessageInput is the input to SetCommitMessage.
+type CommitMessageInput struct {
+	Message string `json:"message"`
+}
+
+// ReviewInput is the input to SetReview.
+type ReviewInput struct {
+	Message string `json:"message,omitempty"`
+}
+
+// timestampFormat is the format of timestamps in the Gerrit REST API, which
+// are always in UTC.
+const timestampFormat = "2006-01-02 15:04:05.000000000"
+
+// Timestamp is a time.Time that is (un)marshalled from and to the timestamp
+// format used by Gerrit.
+type Timestamp struct {
+	time.Time
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.UTC().Format(timestampFormat))
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	parsed, err := time.Parse(timestampFormat, strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
@@ -0,0 +1,170 @@
+package gerrit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestClient_GetChange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if want := "/a/changes/sourcegraph%2Fsrc-cli~main~I0123456789abcdef"; r.URL.EscapedPath() != want {
+			t.Errorf("unexpected path. want=%q have=%q", want, r.URL.EscapedPath())
+		}
+		if want, have := changeOptions, r.URL.Query()["o"]; !cmp.Equal(want, have) {
+			t.Errorf("unexpected options. want=%q have=%q", want, have)
+		}
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			t.Errorf("unexpected credentials. have=%q:%q", username, password)
+		}
+
+		_, _ = w.Write([]byte(`)]}'
+{
+  "id": "sourcegraph%2Fsrc-cli~main~I0123456789abcdef",
+  "project": "sourcegraph/src-cli",
+  "branch": "main",
+  "change_id": "I0123456789abcdef",
+  "subject": "Fix the thing",
+  "status": "NEW",
+  "created": "2022-08-01 10:11:12.000000000",
+  "updated": "2022-08-02 13:14:15.123000000",
+  "_number": 42,
+  "owner": {"_account_id": 1000000, "name": "Admin", "username": "admin"},
+  "labels": {
+    "Code-Review": {
+      "all": [{"_account_id": 1000001, "username": "reviewer", "value": 2}],
+      "approved": {"_account_id": 1000001, "username": "reviewer"}
+    }
+  },
+  "current_revision": "deadbeef",
+  "revisions": {
+    "deadbeef": {
+      "_number": 2,
+      "ref": "refs/changes/42/42/2",
+      "commit": {
+        "parents": [{"commit": "cafebabe", "subject": "Initial commit"}],
+        "subject": "Fix the thing",
+        "message": "Fix the thing\n\nChange-Id: I0123456789abcdef\n"
+      }
+    }
+  }
+}`))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient("urn", &schema.GerritConnection{
+		Url:      srv.URL,
+		Username: "admin",
+		Password: "secret",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	change, err := cli.GetChange(context.Background(), ChangeTriplet("sourcegraph/src-cli", "main", "I0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reviewer := Account{ID: 1000001, Username: "reviewer"}
+	want := &Change{
+		ID:       "sourcegraph%2Fsrc-cli~main~I0123456789abcdef",
+		Project:  "sourcegraph/src-cli",
+		Branch:   "main",
+		ChangeID: "I0123456789abcdef",
+		Subject:  "Fix the thing",
+		Status:   ChangeStatusNew,
+		Created:  Timestamp{time.Date(2022, 8, 1, 10, 11, 12, 0, time.UTC)},
+		Updated:  Timestamp{time.Date(2022, 8, 2, 13, 14, 15, 123000000, time.UTC)},
+		Number:   42,
+		Owner:    Account{ID: 1000000, Name: "Admin", Username: "admin"},
+		Labels: map[string]ChangeLabel{
+			LabelCodeReview: {
+				All:      []Approval{{Account: reviewer, Value: 2}},
+				Approved: &reviewer,
+			},
+		},
+		CurrentRevision: "deadbeef",
+		Revisions: map[string]Revision{
+			"deadbeef": {
+				Number: 2,
+				Ref:    "refs/changes/42/42/2",
+				Commit: &Commit{
+					Parents: []CommitParent{{Commit: "cafebabe", Subject: "Initial commit"}},
+					Subject: "Fix the thing",
+					Message: "Fix the thing\n\nChange-Id: I0123456789abcdef\n",
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, change); diff != "" {
+		t.Errorf("unexpected change (-want +got):\n%s", diff)
+	}
+
+	// Metadata is persisted as JSON, so the timestamps need to survive a
+	// round trip.
+	bs, err := json.Marshal(change)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTripped Change
+	if err := json.Unmarshal(bs, &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(change, &roundTripped); diff != "" {
+		t.Errorf("unexpected change after round trip (-want +got):\n%s", diff)
+	}
+}
+
+func TestClient_SetCommitMessage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/a/changes/42/message" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		bs, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, have := `{"message":"Title\n\nChange-Id: I0123\n"}`, string(bs); want != have {
+			t.Errorf("unexpected body. want=%q have=%q", want, have)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient("urn", &schema.GerritConnection{Url: srv.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cli.SetCommitMessage(context.Background(), "42", "Title\n\nChange-Id: I0123\n"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_SubmitChange_Conflict(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte("change is new"))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient("urn", &schema.GerritConnection{Url: srv.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cli.SubmitChange(context.Background(), "42"); !IsConflict(err) {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+}
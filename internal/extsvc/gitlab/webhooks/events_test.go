@@ -109,4 +109,54 @@ func TestUnmarshalEvent(t *testing.T) {
 			t.Errorf("unexpected IID: have %d; want %d", pe.Pipeline.ID, want)
 		}
 	})
+
+	t.Run("valid push", func(t *testing.T) {
+		event, err := UnmarshalEvent([]byte(`
+			{
+				"object_kind": "push",
+				"event_name": "push",
+				"ref": "refs/heads/main",
+				"project": {
+					"id": 42
+				}
+			}
+		`))
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+
+		pe, ok := event.(*PushEvent)
+		if !ok {
+			t.Fatalf("unexpected event type: %T", event)
+		}
+		if want := 42; pe.Project.ID != want {
+			t.Errorf("unexpected project ID: have %d; want %d", pe.Project.ID, want)
+		}
+		if want := "refs/heads/main"; pe.Ref != want {
+			t.Errorf("unexpected ref: have %s; want %s", pe.Ref, want)
+		}
+	})
+
+	t.Run("valid tag push", func(t *testing.T) {
+		event, err := UnmarshalEvent([]byte(`
+			{
+				"object_kind": "tag_push",
+				"ref": "refs/tags/v1.0.0",
+				"project": {
+					"id": 42
+				}
+			}
+		`))
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+
+		te, ok := event.(*TagPushEvent)
+		if !ok {
+			t.Fatalf("unexpected event type: %T", event)
+		}
+		if want := 42; te.Project.ID != want {
+			t.Errorf("unexpected project ID: have %d; want %d", te.Project.ID, want)
+		}
+	})
 }
@@ -0,0 +1,130 @@
+// Package rubygems implements a client for gem repositories compatible with
+// the RubyGems.org API, see https://guides.rubygems.org/rubygems-org-api/.
+package rubygems
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// DefaultRepositoryURL is the URL of the public gem repository, which is used
+// if no other repository is configured.
+const DefaultRepositoryURL = "https://rubygems.org/"
+
+type Client struct {
+	repositoryURL string
+	cli           httpcli.Doer
+
+	// Self-imposed rate-limiter.
+	limiter *ratelimit.InstrumentedLimiter
+}
+
+func NewClient(urn, repositoryURL string, cli httpcli.Doer) *Client {
+	if repositoryURL == "" {
+		repositoryURL = DefaultRepositoryURL
+	}
+
+	return &Client{
+		repositoryURL: repositoryURL,
+		cli:           cli,
+		limiter:       ratelimit.DefaultRegistry.Get(urn),
+	}
+}
+
+// Gem is the metadata of a single version of a gem.
+type Gem struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Info    string `json:"info"`
+	SHA     string `json:"sha"`
+}
+
+// GetGem returns the metadata of the given version of a gem. If version is
+// empty, the metadata of the latest version is returned.
+func (c *Client) GetGem(ctx context.Context, name, version string) (*Gem, error) {
+	var reqPath string
+	if version == "" {
+		reqPath = path.Join("api/v1/gems", name+".json")
+	} else {
+		reqPath = path.Join("api/v2/rubygems", name, "versions", version+".json")
+	}
+
+	bs, err := c.get(ctx, reqPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var gem Gem
+	if err := json.Unmarshal(bs, &gem); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse metadata of gem %q", name)
+	}
+	return &gem, nil
+}
+
+// Download downloads the .gem file of the given version of a gem.
+func (c *Client) Download(ctx context.Context, name, version string) ([]byte, error) {
+	return c.get(ctx, path.Join("gems", fmt.Sprintf("%s-%s.gem", name, version)))
+}
+
+func (c *Client) get(ctx context.Context, reqPath string) ([]byte, error) {
+	u, err := url.Parse(c.repositoryURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid gem repository URL")
+	}
+	u.Path = path.Join(u.Path, reqPath)
+
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("User-Agent", "sourcegraph-rubygems-syncer (sourcegraph.com)")
+
+	return c.do(req)
+}
+
+type Error struct {
+	path    string
+	code    int
+	message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("bad response with status code %d for %s: %s", e.code, e.path, e.message)
+}
+
+func (e *Error) NotFound() bool {
+	return e.code == http.StatusNotFound
+}
+
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	bs, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &Error{path: req.URL.Path, code: resp.StatusCode, message: string(bs)}
+	}
+
+	return bs, nil
+}
@@ -0,0 +1,71 @@
+package rubygems
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/private/api/v1/gems/rails.json":
+			_, _ = w.Write([]byte(`{"name":"rails","version":"7.0.4","info":"Ruby on Rails","sha":"latest"}`))
+		case "/private/api/v2/rubygems/rails/versions/6.1.7.json":
+			_, _ = w.Write([]byte(`{"name":"rails","version":"6.1.7","info":"Ruby on Rails","sha":"older"}`))
+		case "/private/gems/rails-6.1.7.gem":
+			_, _ = w.Write([]byte("gem contents"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("This rubygem could not be found."))
+		}
+	}))
+	defer srv.Close()
+
+	cli := NewClient("urn", srv.URL+"/private/", http.DefaultClient)
+	ctx := context.Background()
+
+	t.Run("latest version", func(t *testing.T) {
+		gem, err := cli.GetGem(ctx, "rails", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &Gem{Name: "rails", Version: "7.0.4", Info: "Ruby on Rails", SHA: "latest"}
+		if diff := cmp.Diff(want, gem); diff != "" {
+			t.Errorf("unexpected gem (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("specific version", func(t *testing.T) {
+		gem, err := cli.GetGem(ctx, "rails", "6.1.7")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &Gem{Name: "rails", Version: "6.1.7", Info: "Ruby on Rails", SHA: "older"}
+		if diff := cmp.Diff(want, gem); diff != "" {
+			t.Errorf("unexpected gem (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := cli.GetGem(ctx, "rails", "0.0.0")
+		if !errcode.IsNotFound(err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("download", func(t *testing.T) {
+		bs, err := cli.Download(ctx, "rails", "6.1.7")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have, want := string(bs), "gem contents"; have != want {
+			t.Errorf("unexpected contents. want=%q have=%q", want, have)
+		}
+	})
+}
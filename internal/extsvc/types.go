@@ -75,6 +75,7 @@ const (
 	// in preference to the Type values below.
 
 	KindAWSCodeCommit   = "AWSCODECOMMIT"
+	KindAzureDevOps     = "AZUREDEVOPS"
 	KindBitbucketServer = "BITBUCKETSERVER"
 	KindBitbucketCloud  = "BITBUCKETCLOUD"
 	KindGerrit          = "GERRIT"
@@ -86,6 +87,7 @@ const (
 	KindGoModules       = "GOMODULES"
 	KindJVMPackages     = "JVMPACKAGES"
 	KindPythonPackages  = "PYTHONPACKAGES"
+	KindRubyPackages    = "RUBYPACKAGES"
 	KindRustPackages    = "RUSTPACKAGES"
 	KindPagure          = "PAGURE"
 	KindNpmPackages     = "NPMPACKAGES"
@@ -100,6 +102,11 @@ const (
 	// suffix (e.g., "arn:aws:codecommit:us-west-1:123456789:").
 	TypeAWSCodeCommit = "awscodecommit"
 
+	// TypeAzureDevOps is the (api.ExternalRepoSpec).ServiceType value for Azure DevOps repositories. The
+	// ServiceID value is the base URL to the Azure DevOps instance (https://dev.azure.com or an Azure
+	// DevOps Server URL).
+	TypeAzureDevOps = "azureDevOps"
+
 	// TypeBitbucketServer is the (api.ExternalRepoSpec).ServiceType value for Bitbucket Server projects. The
 	// ServiceID value is the base URL to the Bitbucket Server instance.
 	TypeBitbucketServer = "bitbucketServer"
@@ -143,6 +150,9 @@ const (
 	// TypePythonPackages is the (api.ExternalRepoSpec).ServiceType value for Python packages.
 	TypePythonPackages = "pythonPackages"
 
+	// TypeRubyPackages is the (api.ExternalRepoSpec).ServiceType value for Ruby packages.
+	TypeRubyPackages = "rubyPackages"
+
 	// TypeRustPackages is the (api.ExternalRepoSpec).ServiceType value for Python packages.
 	TypeRustPackages = "rustPackages"
 
@@ -156,6 +166,8 @@ func KindToType(kind string) string {
 	switch kind {
 	case KindAWSCodeCommit:
 		return TypeAWSCodeCommit
+	case KindAzureDevOps:
+		return TypeAzureDevOps
 	case KindBitbucketServer:
 		return TypeBitbucketServer
 	case KindBitbucketCloud:
@@ -176,6 +188,8 @@ func KindToType(kind string) string {
 		return TypeJVMPackages
 	case KindPythonPackages:
 		return TypePythonPackages
+	case KindRubyPackages:
+		return TypeRubyPackages
 	case KindRustPackages:
 		return TypeRustPackages
 	case KindNpmPackages:
@@ -197,6 +211,8 @@ func TypeToKind(t string) string {
 	switch t {
 	case TypeAWSCodeCommit:
 		return KindAWSCodeCommit
+	case TypeAzureDevOps:
+		return KindAzureDevOps
 	case TypeBitbucketServer:
 		return KindBitbucketServer
 	case TypeBitbucketCloud:
@@ -219,6 +235,8 @@ func TypeToKind(t string) string {
 		return KindJVMPackages
 	case TypePythonPackages:
 		return KindPythonPackages
+	case TypeRubyPackages:
+		return KindRubyPackages
 	case TypeRustPackages:
 		return KindRustPackages
 	case TypeGoModules:
@@ -234,12 +252,14 @@ func TypeToKind(t string) string {
 
 var (
 	// Precompute these for use in ParseServiceType below since the constants are mixed case
+	adoLower    = strings.ToLower(TypeAzureDevOps)
 	bbsLower    = strings.ToLower(TypeBitbucketServer)
 	bbcLower    = strings.ToLower(TypeBitbucketCloud)
 	jvmLower    = strings.ToLower(TypeJVMPackages)
 	npmLower    = strings.ToLower(TypeNpmPackages)
 	goLower     = strings.ToLower(TypeGoModules)
 	pythonLower = strings.ToLower(TypePythonPackages)
+	rubyLower   = strings.ToLower(TypeRubyPackages)
 	rustLower   = strings.ToLower(TypeRustPackages)
 )
 
@@ -249,6 +269,8 @@ func ParseServiceType(s string) (string, bool) {
 	switch strings.ToLower(s) {
 	case TypeAWSCodeCommit:
 		return TypeAWSCodeCommit, true
+	case adoLower:
+		return TypeAzureDevOps, true
 	case bbsLower:
 		return TypeBitbucketServer, true
 	case bbcLower:
@@ -273,6 +295,8 @@ func ParseServiceType(s string) (string, bool) {
 		return TypeNpmPackages, true
 	case pythonLower:
 		return TypePythonPackages, true
+	case rubyLower:
+		return TypeRubyPackages, true
 	case rustLower:
 		return TypeRustPackages, true
 	case TypePagure:
@@ -290,6 +314,8 @@ func ParseServiceKind(s string) (string, bool) {
 	switch strings.ToUpper(s) {
 	case KindAWSCodeCommit:
 		return KindAWSCodeCommit, true
+	case KindAzureDevOps:
+		return KindAzureDevOps, true
 	case KindBitbucketServer:
 		return KindBitbucketServer, true
 	case KindBitbucketCloud:
@@ -312,6 +338,8 @@ func ParseServiceKind(s string) (string, bool) {
 		return KindJVMPackages, true
 	case KindPythonPackages:
 		return KindPythonPackages, true
+	case KindRubyPackages:
+		return KindRubyPackages, true
 	case KindRustPackages:
 		return KindRustPackages, true
 	case KindPagure:
@@ -341,6 +369,8 @@ func ParseConfig(kind, config string) (cfg any, _ error) {
 	switch strings.ToUpper(kind) {
 	case KindAWSCodeCommit:
 		cfg = &schema.AWSCodeCommitConnection{}
+	case KindAzureDevOps:
+		cfg = &schema.AzureDevOpsConnection{}
 	case KindBitbucketServer:
 		cfg = &schema.BitbucketServerConnection{}
 	case KindBitbucketCloud:
@@ -367,6 +397,8 @@ func ParseConfig(kind, config string) (cfg any, _ error) {
 		cfg = &schema.NpmPackagesConnection{}
 	case KindPythonPackages:
 		cfg = &schema.PythonPackagesConnection{}
+	case KindRubyPackages:
+		cfg = &schema.RubyPackagesConnection{}
 	case KindRustPackages:
 		cfg = &schema.RustPackagesConnection{}
 	case KindOther:
@@ -427,6 +459,8 @@ func ExtractToken(config string, kind string) (string, error) {
 		return c.Token, nil
 	case *schema.PagureConnection:
 		return c.Token, nil
+	case *schema.AzureDevOpsConnection:
+		return c.Token, nil
 	default:
 		return "", errors.Errorf("unable to extract token for service kind %q", kind)
 	}
@@ -480,6 +514,13 @@ func GetLimitFromConfig(kind string, config any) (rate.Limit, error) {
 		if c != nil && c.RateLimit != nil {
 			limit = limitOrInf(c.RateLimit.Enabled, c.RateLimit.RequestsPerHour)
 		}
+	case *schema.AzureDevOpsConnection:
+		// Azure DevOps throttles per user based on resource consumption rather than
+		// a fixed request count, so we default to a conservative 5000 per hour.
+		limit = rate.Limit(5000.0 / 3600.0)
+		if c != nil && c.RateLimit != nil {
+			limit = limitOrInf(c.RateLimit.Enabled, c.RateLimit.RequestsPerHour)
+		}
 	case *schema.PerforceConnection:
 		limit = rate.Limit(5000.0 / 3600.0)
 		if c != nil && c.RateLimit != nil {
@@ -516,6 +557,11 @@ func GetLimitFromConfig(kind string, config any) (rate.Limit, error) {
 		if c != nil && c.RateLimit != nil {
 			limit = limitOrInf(c.RateLimit.Enabled, c.RateLimit.RequestsPerHour)
 		}
+	case *schema.RubyPackagesConnection:
+		limit = rate.Limit(3600.0 / 3600.0) // Same as the default in ruby-packages.schema.json
+		if c != nil && c.RateLimit != nil {
+			limit = limitOrInf(c.RateLimit.Enabled, c.RateLimit.RequestsPerHour)
+		}
 	case *schema.RustPackagesConnection:
 		// 1 request per second is default policy for crates.io
 		limit = rate.Limit(32)
@@ -604,6 +650,8 @@ func UniqueCodeHostIdentifier(kind, config string) (string, error) {
 		rawURL = c.Url
 	case *schema.BitbucketCloudConnection:
 		rawURL = c.Url
+	case *schema.AzureDevOpsConnection:
+		rawURL = c.Url
 	case *schema.GerritConnection:
 		rawURL = c.Url
 	case *schema.PhabricatorConnection:
@@ -627,6 +675,8 @@ func UniqueCodeHostIdentifier(kind, config string) (string, error) {
 		return KindNpmPackages, nil
 	case *schema.PythonPackagesConnection:
 		return KindPythonPackages, nil
+	case *schema.RubyPackagesConnection:
+		return KindRubyPackages, nil
 	case *schema.RustPackagesConnection:
 		return KindRustPackages, nil
 	case *schema.PagureConnection:
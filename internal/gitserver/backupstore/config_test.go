@@ -0,0 +1,93 @@
+package backupstore
+
+import "testing"
+
+func TestConfigDisabled(t *testing.T) {
+	config := Config{}
+	config.SetMockGetter(mapGetter(nil))
+	config.Load()
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+
+	if config.Enabled() {
+		t.Errorf("expected backups to be disabled without a backend")
+	}
+}
+
+func TestConfigS3(t *testing.T) {
+	env := map[string]string{
+		"GITSERVER_BACKUP_UPLOAD_BACKEND":               "S3",
+		"GITSERVER_BACKUP_UPLOAD_BUCKET":                "repo-backups",
+		"GITSERVER_BACKUP_UPLOAD_AWS_REGION":            "eu-west-1",
+		"GITSERVER_BACKUP_UPLOAD_AWS_ACCESS_KEY_ID":     "access-key-id",
+		"GITSERVER_BACKUP_UPLOAD_AWS_SECRET_ACCESS_KEY": "secret-access-key",
+	}
+
+	config := Config{}
+	config.SetMockGetter(mapGetter(env))
+	config.Load()
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+
+	if !config.Enabled() {
+		t.Errorf("expected backups to be enabled")
+	}
+	if config.Backend != "s3" {
+		t.Errorf("unexpected value for Backend. want=%s have=%s", "s3", config.Backend)
+	}
+	if config.Bucket != "repo-backups" {
+		t.Errorf("unexpected value for Bucket. want=%s have=%s", "repo-backups", config.Bucket)
+	}
+	if config.S3Region != "eu-west-1" {
+		t.Errorf("unexpected value for S3Region. want=%s have=%s", "eu-west-1", config.S3Region)
+	}
+	if config.S3AccessKeyID != "access-key-id" {
+		t.Errorf("unexpected value for S3AccessKeyID. want=%s have=%s", "access-key-id", config.S3AccessKeyID)
+	}
+}
+
+func TestConfigFilesystem(t *testing.T) {
+	env := map[string]string{
+		"GITSERVER_BACKUP_UPLOAD_BACKEND":         "Filesystem",
+		"GITSERVER_BACKUP_UPLOAD_FILESYSTEM_ROOT": "/mnt/backups",
+	}
+
+	config := Config{}
+	config.SetMockGetter(mapGetter(env))
+	config.Load()
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+
+	if config.Bucket != "gitserver-backups" {
+		t.Errorf("unexpected value for Bucket. want=%s have=%s", "gitserver-backups", config.Bucket)
+	}
+	if config.FilesystemRoot != "/mnt/backups" {
+		t.Errorf("unexpected value for FilesystemRoot. want=%s have=%s", "/mnt/backups", config.FilesystemRoot)
+	}
+}
+
+func TestConfigInvalidBackend(t *testing.T) {
+	config := Config{}
+	config.SetMockGetter(mapGetter(map[string]string{"GITSERVER_BACKUP_UPLOAD_BACKEND": "ftp"}))
+	config.Load()
+
+	if err := config.Validate(); err == nil {
+		t.Fatalf("expected validation error")
+	}
+}
+
+func mapGetter(env map[string]string) func(name, defaultValue, description string) string {
+	return func(name, defaultValue, description string) string {
+		if v, ok := env[name]; ok {
+			return v
+		}
+
+		return defaultValue
+	}
+}
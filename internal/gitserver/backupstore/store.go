@@ -0,0 +1,32 @@
+package backupstore
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/uploadstore"
+)
+
+func New(ctx context.Context, conf *Config, observationContext *observation.Context) (uploadstore.Store, error) {
+	c := uploadstore.Config{
+		Backend: conf.Backend,
+		Bucket:  conf.Bucket,
+		S3: uploadstore.S3Config{
+			Region:          conf.S3Region,
+			Endpoint:        conf.S3Endpoint,
+			AccessKeyID:     conf.S3AccessKeyID,
+			SecretAccessKey: conf.S3SecretAccessKey,
+			SessionToken:    conf.S3SessionToken,
+		},
+		GCS: uploadstore.GCSConfig{
+			ProjectID:               conf.GCSProjectID,
+			CredentialsFile:         conf.GCSCredentialsFile,
+			CredentialsFileContents: conf.GCSCredentialsFileContents,
+		},
+		Filesystem: uploadstore.FilesystemConfig{
+			Root: conf.FilesystemRoot,
+		},
+	}
+
+	return uploadstore.CreateLazy(ctx, c, uploadstore.NewOperations(observationContext, "gitserver", "backupstore"))
+}
@@ -92,6 +92,13 @@ func NewClient(db database.DB) *ClientImplementor {
 			}
 			return map[string]string{}
 		},
+		replicated: func() map[string]int {
+			cfg := conf.Get()
+			if cfg.ExperimentalFeatures != nil && cfg.ExperimentalFeatures.GitServerReplicatedRepos != nil {
+				return cfg.ExperimentalFeatures.GitServerReplicatedRepos
+			}
+			return map[string]int{}
+		},
 		db:          db,
 		HTTPClient:  defaultDoer,
 		HTTPLimiter: defaultLimiter,
@@ -113,6 +120,12 @@ func NewTestClient(cli httpcli.Doer, db database.DB, addrs []string) *ClientImpl
 			// nothing needs to be pinned for the tests
 			return conf.Get().ExperimentalFeatures.GitServerPinnedRepos
 		},
+		replicated: func() map[string]int {
+			if cfg := conf.Get(); cfg.ExperimentalFeatures != nil {
+				return cfg.ExperimentalFeatures.GitServerReplicatedRepos
+			}
+			return nil
+		},
 		HTTPClient:  cli,
 		HTTPLimiter: parallel.NewRun(500),
 		// Use the binary name for UserAgent. This should effectively identify
@@ -145,6 +158,10 @@ type ClientImplementor struct {
 	// and sync the pinned map.
 	pinned func() map[string]string
 
+	// replicated holds a map of repositories(key) to their replication factor(value). Like
+	// pinned, this function should query the conf to fetch a fresh map of replicated repos.
+	replicated func() map[string]int
+
 	// UserAgent is a string identifying who the client is. It will be logged in
 	// the telemetry in gitserver.
 	UserAgent string
@@ -450,7 +467,7 @@ func (c *ClientImplementor) archiveURL(ctx context.Context, repo api.RepoName, o
 		q.Add("path", string(pathspec))
 	}
 
-	addrForRepo, err := c.AddrForRepo(ctx, repo)
+	addrForRepo, err := c.readAddrForRepo(ctx, repo)
 	if err != nil {
 		return nil, err
 	}
@@ -601,7 +618,7 @@ func (c *ClientImplementor) Search(ctx context.Context, args *protocol.SearchReq
 		return false, err
 	}
 
-	addrForRepo, err := c.AddrForRepo(ctx, repoName)
+	addrForRepo, err := c.readAddrForRepo(ctx, repoName)
 	if err != nil {
 		return false, err
 	}
@@ -653,6 +653,85 @@ func TestClient_AddrForRepo_Rendezvous(t *testing.T) {
 	}
 }
 
+func TestReplicaAddrsForRepo(t *testing.T) {
+	addrs := []string{"gitserver1", "gitserver2", "gitserver3"}
+
+	tests := []struct {
+		name    string
+		primary string
+		factor  int
+		want    []string
+	}{
+		{name: "not replicated", primary: "gitserver1", factor: 1, want: nil},
+		{name: "next instance", primary: "gitserver1", factor: 2, want: []string{"gitserver2"}},
+		{name: "wraps around", primary: "gitserver3", factor: 3, want: []string{"gitserver1", "gitserver2"}},
+		{name: "factor larger than instances", primary: "gitserver2", factor: 5, want: []string{"gitserver3", "gitserver1"}},
+		{name: "unknown primary", primary: "gitserver4", factor: 2, want: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, gitserver.ReplicaAddrsForRepo(tc.primary, addrs, tc.factor))
+		})
+	}
+}
+
+func TestClient_Archive_ReadReplicas(t *testing.T) {
+	// github.com/test/foo is owned by the third instance, so it is replicated on the first
+	// and second instance.
+	addrs := []string{"172.16.9.1:8080", "172.16.9.2:8080", "172.16.9.3:8080"}
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		ExperimentalFeatures: &schema.ExperimentalFeatures{
+			GitServerReplicatedRepos: map[string]int{"github.com/test/foo": 3},
+		},
+	}})
+	t.Cleanup(func() { conf.Mock(nil) })
+
+	refStates := map[string]protocol.RefStateResponse{
+		"172.16.9.1:8080": {Cloned: true, Hash: "up-to-date"},
+		"172.16.9.2:8080": {Cloned: true, Hash: "stale"},
+		"172.16.9.3:8080": {Cloned: true, Hash: "up-to-date"},
+	}
+
+	var mu sync.Mutex
+	served := map[string]int{}
+	cli := gitserver.NewTestClient(
+		httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+			switch r.URL.Path {
+			case "/ref-state":
+				encoded, _ := json.Marshal(refStates[r.URL.Host])
+				return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewReader(encoded))}, nil
+			case "/archive":
+				mu.Lock()
+				served[r.URL.Host]++
+				mu.Unlock()
+				return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(""))}, nil
+			default:
+				return nil, errors.Errorf("unexpected request %s", r.URL)
+			}
+		}),
+		database.NewMockDB(),
+		addrs,
+	)
+
+	for i := 0; i < 50; i++ {
+		rc, err := cli.Archive(context.Background(), "github.com/test/foo", gitserver.ArchiveOptions{Treeish: "HEAD", Format: "zip"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rc.Close()
+	}
+
+	// Requests are balanced across the primary and the replica that is up to date, but
+	// never sent to the stale replica.
+	if served["172.16.9.1:8080"] == 0 || served["172.16.9.3:8080"] == 0 {
+		t.Fatalf("expected requests to be balanced across the primary and the replica, got %v", served)
+	}
+	if n := served["172.16.9.2:8080"]; n != 0 {
+		t.Fatalf("expected no requests to be sent to the stale replica, got %d", n)
+	}
+}
+
 func TestClient_BatchLog(t *testing.T) {
 	addrs := []string{"172.16.8.1:8080", "172.16.8.2:8080", "172.16.8.3:8080"}
 
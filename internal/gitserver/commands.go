@@ -678,7 +678,7 @@ func (c *ClientImplementor) BlameFile(ctx context.Context, repo api.RepoName, pa
 	span.SetTag("path", path)
 	span.SetTag("opt", opt)
 	defer span.Finish()
-	return blameFileCmd(ctx, c.gitserverGitCommandFunc(repo), path, opt, repo, checker)
+	return blameFileCmd(ctx, c.readGitCommandFunc(repo), path, opt, repo, checker)
 }
 
 func blameFileCmd(ctx context.Context, command gitCommandFunc, path string, opt *BlameOptions, repo api.RepoName, checker authz.SubRepoPermissionChecker) ([]*Hunk, error) {
@@ -812,9 +812,9 @@ func blameFileCmd(ctx context.Context, command gitCommandFunc, path string, opt
 	return hunks, nil
 }
 
-func (c *ClientImplementor) gitserverGitCommandFunc(repo api.RepoName) gitCommandFunc {
+func (c *ClientImplementor) readGitCommandFunc(repo api.RepoName) gitCommandFunc {
 	return func(args []string) GitCommand {
-		return c.GitCommand(repo, args...)
+		return c.readGitCommand(repo, args...)
 	}
 }
 
@@ -1380,7 +1380,7 @@ func (c *ClientImplementor) newBlobReader(ctx context.Context, repo api.RepoName
 		return nil, err
 	}
 
-	cmd := c.GitCommand(repo, "show", string(commit)+":"+name)
+	cmd := c.readGitCommand(repo, "show", string(commit)+":"+name)
 	stdout, err := cmd.StdoutReader(ctx)
 	if err != nil {
 		return nil, err
@@ -1738,7 +1738,7 @@ func (c *ClientImplementor) getWrappedCommits(ctx context.Context, repo api.Repo
 		return nil, err
 	}
 
-	cmd := c.GitCommand(repo, args...)
+	cmd := c.readGitCommand(repo, args...)
 	if !opt.NoEnsureRevision {
 		cmd.SetEnsureRevision(opt.Range)
 	}
@@ -214,6 +214,22 @@ type IsRepoClonedRequest struct {
 	Repo api.RepoName
 }
 
+// RefStateRequest is a request for the state of the refs of a repository on gitserver.
+type RefStateRequest struct {
+	// Repo is the repository to get the ref state of.
+	Repo api.RepoName
+}
+
+// RefStateResponse is the response type for the RefStateRequest.
+type RefStateResponse struct {
+	Cloned bool // whether the repository is cloned
+
+	// Hash is a hash of HEAD and the names and object names of all refs of the
+	// repository. Two clones of a repository have the same hash if and only if their refs
+	// are the same.
+	Hash string
+}
+
 // RepoDeleteRequest is a request to delete a repository clone on gitserver
 type RepoDeleteRequest struct {
 	// Repo is the repository to delete.
@@ -300,6 +316,10 @@ type CreateCommitFromPatchRequest struct {
 	// Push specifies whether the target ref will be pushed to the code host: if
 	// nil, no push will be attempted, if non-nil, a push will be attempted.
 	Push *PushConfig
+	// PushRef is the ref on the code host the commit will be pushed to. If
+	// nil, TargetRef is used. Code hosts such as Gerrit expect commits to be
+	// pushed to refs that differ from the branch they end up on.
+	PushRef *string
 	// GitApplyArgs are the arguments that will be passed to `git apply` along
 	// with `--cached`.
 	GitApplyArgs []string
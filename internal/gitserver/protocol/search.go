@@ -85,6 +85,64 @@ func (d *DiffModifiesFile) String() string {
 	return fmt.Sprintf("%T(%s)", d, d.Expr)
 }
 
+// TrailerMatches is a predicate that matches if the commit message has a
+// trailer, such as "Signed-off-by: Alice <alice@example.com>", whose key is Key
+// and whose value matches the regex pattern. Keys are compared
+// case-insensitively.
+type TrailerMatches struct {
+	Key        string
+	Expr       string
+	IgnoreCase bool
+}
+
+func (t *TrailerMatches) String() string {
+	return fmt.Sprintf("%T(%s: %s)", t, t.Key, t.Expr)
+}
+
+// SignatureStatus is a class of commit signature verification results.
+type SignatureStatus string
+
+const (
+	// SignatureSigned is any commit with a GPG or SSH signature, whether or
+	// not it can be verified.
+	SignatureSigned SignatureStatus = "signed"
+	// SignatureUnsigned is any commit without a signature.
+	SignatureUnsigned SignatureStatus = "unsigned"
+	// SignatureValid is a commit with a good signature.
+	SignatureValid SignatureStatus = "valid"
+	// SignatureInvalid is a commit with a signature that is bad or was made
+	// by an expired or revoked key.
+	SignatureInvalid SignatureStatus = "invalid"
+	// SignatureUnverified is a commit with a signature that could not be
+	// checked, e.g. because the signing key is not known to gitserver.
+	SignatureUnverified SignatureStatus = "unverified"
+)
+
+// SignatureMatches is a predicate that matches if the verification result of
+// the commit's signature falls in the given class.
+type SignatureMatches struct {
+	Status SignatureStatus
+}
+
+func (s *SignatureMatches) String() string {
+	return fmt.Sprintf("%T(%s)", s, s.Status)
+}
+
+// ParentCount is a predicate that matches if the number of parents of the
+// commit is between Min and Max inclusive. A negative Max means there is no
+// upper bound. Merge commits have more than one parent.
+//
+// Commit search skips merge commits unless the query contains a ParentCount
+// node.
+type ParentCount struct {
+	Min int
+	Max int
+}
+
+func (p *ParentCount) String() string {
+	return fmt.Sprintf("%T(%d, %d)", p, p.Min, p.Max)
+}
+
 // Boolean is a predicate that will either always match or never match
 type Boolean struct {
 	Value bool
@@ -219,6 +277,9 @@ func RegisterGob() {
 		gob.Register(&MessageMatches{})
 		gob.Register(&DiffMatches{})
 		gob.Register(&DiffModifiesFile{})
+		gob.Register(&TrailerMatches{})
+		gob.Register(&SignatureMatches{})
+		gob.Register(&ParentCount{})
 		gob.Register(&Boolean{})
 		gob.Register(&Operator{})
 	})
@@ -250,7 +250,7 @@ func estimatedNodeCost(n Node) float64 {
 		return 1
 	case *AuthorMatches, *CommitterMatches:
 		return 5
-	case *MessageMatches:
+	case *MessageMatches, *TrailerMatches:
 		return 10
 	case *DiffModifiesFile:
 		return 1000
@@ -0,0 +1,188 @@
+package gitserver
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// ReplicaAddrsForRepo returns the addresses of the gitserver instances that keep a mirror
+// of a repository with the given replication factor, in addition to its primary address.
+// They are the (factor - 1) addresses that follow the primary address in addrs.
+func ReplicaAddrsForRepo(primary string, addrs []string, factor int) []string {
+	if factor <= 1 {
+		return nil
+	}
+
+	start := -1
+	for i, addr := range addrs {
+		if addr == primary {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		// The repository is pinned to an instance that is not a known gitserver.
+		return nil
+	}
+
+	if factor > len(addrs) {
+		factor = len(addrs)
+	}
+	replicas := make([]string, 0, factor-1)
+	for i := 1; i < factor; i++ {
+		replicas = append(replicas, addrs[(start+i)%len(addrs)])
+	}
+	return replicas
+}
+
+var (
+	// refStateTTL is how long the ref state of a repository on a gitserver instance is cached
+	// for. A replica may lag behind its primary by up to this long.
+	refStateTTL = 5 * time.Second
+
+	// refStateTimeout is how long we wait for the ref state of a repository. Replicas that
+	// don't respond in time are not used until the ref state is requested again.
+	refStateTimeout = time.Second
+)
+
+// refStates caches the ref states of replicated repositories on gitserver instances.
+// Clients are created frequently, so the cache is shared by all of them.
+var refStates = &refStateCache{entries: map[refStateKey]refStateEntry{}}
+
+type refStateCache struct {
+	mu      sync.Mutex
+	entries map[refStateKey]refStateEntry
+}
+
+type refStateKey struct {
+	addr string
+	repo api.RepoName
+}
+
+type refStateEntry struct {
+	state     *protocol.RefStateResponse
+	err       error
+	fetchedAt time.Time
+}
+
+var replicatedReads = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "src_gitserver_replicated_reads",
+	Help: "Number of read requests for replicated repositories by whether they were sent to the primary or a replica",
+}, []string{"served_by"})
+
+// readAddrForRepo returns the gitserver address to send a read-only request for the given
+// repo to. If the repo is replicated, the address is chosen at random from the primary and
+// the healthy replicas whose refs match the primary's. Otherwise, it is the primary address.
+func (c *ClientImplementor) readAddrForRepo(ctx context.Context, repo api.RepoName) (string, error) {
+	primary, err := c.AddrForRepo(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	replicas := ReplicaAddrsForRepo(primary, c.Addrs(), c.replicated()[string(protocol.NormalizeRepo(repo))])
+	if len(replicas) == 0 {
+		return primary, nil
+	}
+
+	want, err := c.refState(ctx, primary, repo)
+	if err != nil || !want.Cloned {
+		// We can't tell if the replicas are up to date, so let the primary handle the
+		// request and report any errors.
+		replicatedReads.WithLabelValues("primary").Inc()
+		return primary, nil
+	}
+
+	candidates := []string{primary}
+	for _, addr := range replicas {
+		state, err := c.refState(ctx, addr, repo)
+		if err != nil || !state.Cloned || state.Hash != want.Hash {
+			continue
+		}
+		candidates = append(candidates, addr)
+	}
+
+	addr := candidates[rand.Intn(len(candidates))]
+	if addr == primary {
+		replicatedReads.WithLabelValues("primary").Inc()
+	} else {
+		replicatedReads.WithLabelValues("replica").Inc()
+	}
+	return addr, nil
+}
+
+// refState returns the ref state of the repo on the gitserver instance at addr. Results,
+// including errors, are cached for refStateTTL.
+func (c *ClientImplementor) refState(ctx context.Context, addr string, repo api.RepoName) (*protocol.RefStateResponse, error) {
+	key := refStateKey{addr: addr, repo: repo}
+
+	refStates.mu.Lock()
+	entry, ok := refStates.entries[key]
+	refStates.mu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < refStateTTL {
+		return entry.state, entry.err
+	}
+
+	state, err := c.fetchRefState(ctx, addr, repo)
+	if ctx.Err() != nil {
+		// Don't mark the instance as unhealthy because the request was canceled.
+		return nil, err
+	}
+
+	refStates.mu.Lock()
+	refStates.entries[key] = refStateEntry{state: state, err: err, fetchedAt: time.Now()}
+	refStates.mu.Unlock()
+
+	return state, err
+}
+
+func (c *ClientImplementor) fetchRefState(ctx context.Context, addr string, repo api.RepoName) (*protocol.RefStateResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, refStateTimeout)
+	defer cancel()
+
+	resp, err := c.httpPostWithURI(ctx, repo, "http://"+addr+"/ref-state", &protocol.RefStateRequest{Repo: repo})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("ref state: http status %d", resp.StatusCode)
+	}
+
+	var state protocol.RefStateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// httpPostRead is like httpPost, but for read-only requests which may be sent to a replica
+// of the repo.
+func (c *ClientImplementor) httpPostRead(ctx context.Context, repo api.RepoName, op string, payload any) (resp *http.Response, err error) {
+	addr, err := c.readAddrForRepo(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return c.httpPostWithURI(ctx, repo, "http://"+addr+"/"+op, payload)
+}
+
+// readGitCommand is like GitCommand, but for read-only commands which may be executed on a
+// replica of the repo.
+func (c *ClientImplementor) readGitCommand(repo api.RepoName, arg ...string) GitCommand {
+	cmd := c.GitCommand(repo, arg...)
+	if remote, ok := cmd.(*RemoteGitCommand); ok {
+		remote.execFn = c.httpPostRead
+	}
+	return cmd
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/sourcegraph/go-diff/diff"
 
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
 )
 
 // LazyCommit wraps a RawCommit and a DiffFetcher so that we can have a unified interface
@@ -77,6 +78,46 @@ func (l *LazyCommit) ParentIDs() []api.CommitID {
 	return commitIDs
 }
 
+// ParentCount returns the number of parents of the commit.
+func (l *LazyCommit) ParentCount() int {
+	return len(bytes.Fields(l.ParentHashes))
+}
+
+// Trailer is a "Key: value" line in the trailer block of a commit message.
+type Trailer struct {
+	Key   []byte
+	Value []byte
+}
+
+// Trailers returns the trailers of the commit message. Like git, it considers
+// the last paragraph of a message with more than one paragraph to be the
+// trailer block if every line of it is a "Key: value" trailer or the indented
+// continuation of the previous line. Continuation lines are not unfolded into
+// the value.
+func (l *LazyCommit) Trailers() []Trailer {
+	message := bytes.TrimSpace(l.Message)
+	idx := bytes.LastIndex(message, []byte("\n\n"))
+	if idx < 0 {
+		return nil
+	}
+
+	var trailers []Trailer
+	for _, line := range bytes.Split(bytes.TrimSpace(message[idx:]), []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		if len(trailers) > 0 && len(line) > 0 && (line[0] == ' ' || line[0] == '\t') {
+			continue
+		}
+		match := trailerPattern.FindSubmatch(line)
+		if match == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: match[1], Value: match[2]})
+	}
+	return trailers
+}
+
+var trailerPattern = lazyregexp.New(`^([A-Za-z0-9][A-Za-z0-9-]*)[ \t]*:[ \t]*(.*?)[ \t]*$`)
+
 func (l *LazyCommit) RefNames() []string {
 	return strings.Split(string(l.RawCommit.RefNames), ", ")
 }
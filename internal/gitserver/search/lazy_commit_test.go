@@ -0,0 +1,44 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazyCommit_Trailers(t *testing.T) {
+	cases := []struct {
+		name    string
+		message string
+		want    []Trailer
+	}{{
+		name:    "no body",
+		message: "Signed-off-by: Alice <alice@example.com>",
+		want:    nil,
+	}, {
+		name:    "trailers",
+		message: "Fix bug\n\nDetails.\n\nSigned-off-by: Alice <alice@example.com>\nCo-authored-by:  Bob <bob@example.com> ",
+		want: []Trailer{
+			{Key: []byte("Signed-off-by"), Value: []byte("Alice <alice@example.com>")},
+			{Key: []byte("Co-authored-by"), Value: []byte("Bob <bob@example.com>")},
+		},
+	}, {
+		name:    "continuation line",
+		message: "Fix bug\n\nReviewed-by: Alice\n  and Bob\nFixes: #123",
+		want: []Trailer{
+			{Key: []byte("Reviewed-by"), Value: []byte("Alice")},
+			{Key: []byte("Fixes"), Value: []byte("#123")},
+		},
+	}, {
+		name:    "last paragraph is not a trailer block",
+		message: "Fix bug\n\nSigned-off-by: Alice\nThis is prose.",
+		want:    nil,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lc := &LazyCommit{RawCommit: &RawCommit{Message: []byte(tc.message)}}
+			require.Equal(t, tc.want, lc.Trailers())
+		})
+	}
+}
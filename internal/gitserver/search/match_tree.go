@@ -2,6 +2,7 @@ package search
 
 import (
 	"bytes"
+	"strings"
 	"unicode/utf8"
 
 	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
@@ -34,6 +35,17 @@ func ToMatchTree(q protocol.Node) (MatchTree, error) {
 	case *protocol.DiffModifiesFile:
 		re, err := casetransform.CompileRegexp(v.Expr, v.IgnoreCase)
 		return &DiffModifiesFile{re}, err
+	case *protocol.TrailerMatches:
+		re, err := casetransform.CompileRegexp(v.Expr, v.IgnoreCase)
+		return &TrailerMatches{Key: []byte(v.Key), Regexp: re}, err
+	case *protocol.SignatureMatches:
+		codes, ok := signatureStatusCodes[v.Status]
+		if !ok {
+			return nil, errors.Errorf("unknown signature status %q", v.Status)
+		}
+		return &SignatureMatches{Codes: codes}, nil
+	case *protocol.ParentCount:
+		return &ParentCount{*v}, nil
 	case *protocol.Boolean:
 		return &Constant{v.Value}, nil
 	case *protocol.Operator:
@@ -58,6 +70,16 @@ type MatchTree interface {
 	Match(*LazyCommit) (CommitFilterResult, MatchedCommit, error)
 }
 
+// visitMatchTree calls f for every node of the match tree.
+func visitMatchTree(mt MatchTree, f func(MatchTree)) {
+	f(mt)
+	if o, ok := mt.(*Operator); ok {
+		for _, operand := range o.Operands {
+			visitMatchTree(operand, f)
+		}
+	}
+}
+
 // AuthorMatches is a predicate that matches if the author's name or email address
 // matches the regex pattern.
 type AuthorMatches struct {
@@ -217,6 +239,56 @@ func (dmf *DiffModifiesFile) Match(lc *LazyCommit) (CommitFilterResult, MatchedC
 	return CommitFilterResult{MatchedFileDiffs: matchedFileDiffs}, MatchedCommit{Diff: fileDiffHighlights}, nil
 }
 
+// TrailerMatches is a predicate that matches if the commit message has a
+// trailer with the given key whose value matches the regex pattern.
+type TrailerMatches struct {
+	Key []byte
+	*casetransform.Regexp
+}
+
+func (t *TrailerMatches) Match(lc *LazyCommit) (CommitFilterResult, MatchedCommit, error) {
+	for _, trailer := range lc.Trailers() {
+		if bytes.EqualFold(trailer.Key, t.Key) && t.Regexp.Match(trailer.Value, &lc.LowerBuf) {
+			return filterResult(true), MatchedCommit{}, nil
+		}
+	}
+	return filterResult(false), MatchedCommit{}, nil
+}
+
+// signatureStatusCodes maps each class of signature verification results to
+// the codes of the %G? placeholder of git log that belong to it.
+var signatureStatusCodes = map[protocol.SignatureStatus]string{
+	protocol.SignatureSigned:     "GBUXYRE",
+	protocol.SignatureUnsigned:   "N",
+	protocol.SignatureValid:      "GU",
+	protocol.SignatureInvalid:    "BXYR",
+	protocol.SignatureUnverified: "E",
+}
+
+// SignatureMatches is a predicate that matches if the %G? code of the
+// commit's signature is one of Codes.
+type SignatureMatches struct {
+	Codes string
+}
+
+func (s *SignatureMatches) Match(lc *LazyCommit) (CommitFilterResult, MatchedCommit, error) {
+	if len(lc.SignatureStatus) != 1 {
+		return filterResult(false), MatchedCommit{}, errors.Errorf("unexpected signature status %q", lc.SignatureStatus)
+	}
+	return filterResult(strings.IndexByte(s.Codes, lc.SignatureStatus[0]) >= 0), MatchedCommit{}, nil
+}
+
+// ParentCount is a predicate that matches if the number of parents of the
+// commit is within the given bounds.
+type ParentCount struct {
+	protocol.ParentCount
+}
+
+func (p *ParentCount) Match(lc *LazyCommit) (CommitFilterResult, MatchedCommit, error) {
+	n := lc.ParentCount()
+	return filterResult(n >= p.Min && (p.Max < 0 || n <= p.Max)), MatchedCommit{}, nil
+}
+
 type Constant struct {
 	Value bool
 }
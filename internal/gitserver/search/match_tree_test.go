@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
 )
 
@@ -89,3 +90,40 @@ func Test_matchesToRanges(t *testing.T) {
 		})
 	}
 }
+
+func TestSignatureMatches(t *testing.T) {
+	cases := []struct {
+		code string
+		want []protocol.SignatureStatus
+	}{
+		{code: "G", want: []protocol.SignatureStatus{protocol.SignatureSigned, protocol.SignatureValid}},
+		{code: "B", want: []protocol.SignatureStatus{protocol.SignatureSigned, protocol.SignatureInvalid}},
+		{code: "E", want: []protocol.SignatureStatus{protocol.SignatureSigned, protocol.SignatureUnverified}},
+		{code: "N", want: []protocol.SignatureStatus{protocol.SignatureUnsigned}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.code, func(t *testing.T) {
+			commit := &LazyCommit{RawCommit: &RawCommit{SignatureStatus: []byte(tc.code)}}
+
+			var got []protocol.SignatureStatus
+			for _, status := range []protocol.SignatureStatus{
+				protocol.SignatureSigned,
+				protocol.SignatureUnsigned,
+				protocol.SignatureValid,
+				protocol.SignatureInvalid,
+				protocol.SignatureUnverified,
+			} {
+				tree, err := ToMatchTree(&protocol.SignatureMatches{Status: status})
+				require.NoError(t, err)
+
+				cfr, _, err := tree.Match(commit)
+				require.NoError(t, err)
+				if cfr.Satisfies() {
+					got = append(got, status)
+				}
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -20,17 +20,18 @@ import (
 
 // Git formatting directives as described in man git-log (see PRETTY FORMATS)
 const (
-	hash           = "%H"
-	refNames       = "%D"
-	sourceRefs     = "%S"
-	authorName     = "%aN"
-	authorEmail    = "%aE"
-	authorDate     = "%at"
-	committerName  = "%cN"
-	committerEmail = "%cE"
-	committerDate  = "%ct"
-	rawBody        = "%B"
-	parentHashes   = "%P"
+	hash            = "%H"
+	refNames        = "%D"
+	sourceRefs      = "%S"
+	authorName      = "%aN"
+	authorEmail     = "%aE"
+	authorDate      = "%at"
+	committerName   = "%cN"
+	committerEmail  = "%cE"
+	committerDate   = "%ct"
+	rawBody         = "%B"
+	parentHashes    = "%P"
+	signatureStatus = "%G?"
 )
 
 var (
@@ -46,6 +47,7 @@ var (
 		committerDate,
 		rawBody,
 		parentHashes,
+		signatureStatus,
 	}
 
 	// commitSeparator is a special ascii code we use to separate each commit, the
@@ -55,19 +57,36 @@ var (
 	// depending on the number of files modified in the commit.
 	commitSeparator = []byte("\x1E")
 
-	// Note that we begin each commit with a special string constant. This allows us
-	// to easily separate each commit since the number of parts in each commit varies
-	// depending on the number of files modified.
-	logArgs = []string{
+	sep = []byte{0x0}
+)
+
+// logArgs returns the arguments to git log that print the commits to search.
+// Merge commits are only included if includeMerges is true, and the signature
+// status field is only populated if includeSignatures is true, since verifying
+// a signature runs gpg or ssh-keygen for every signed commit.
+func logArgs(includeMerges, includeSignatures bool) []string {
+	fields := make([]string, 0, len(commitFields))
+	for _, field := range commitFields {
+		if field == signatureStatus && !includeSignatures {
+			field = ""
+		}
+		fields = append(fields, field)
+	}
+
+	args := []string{
 		"log",
 		"--decorate=full",
 		"-z",
-		"--no-merges",
-		"--format=format:" + "%x1E" + strings.Join(commitFields, "%x00") + "%x00",
+	}
+	if !includeMerges {
+		args = append(args, "--no-merges")
 	}
 
-	sep = []byte{0x0}
-)
+	// Note that we begin each commit with a special string constant. This allows us
+	// to easily separate each commit since the number of parts in each commit varies
+	// depending on the number of files modified.
+	return append(args, "--format=format:"+"%x1E"+strings.Join(fields, "%x00")+"%x00")
+}
 
 type job struct {
 	batch      []*RawCommit
@@ -133,8 +152,18 @@ func (cs *CommitSearcher) Search(ctx context.Context, onMatch func(*protocol.Com
 }
 
 func (cs *CommitSearcher) feedBatches(ctx context.Context, jobs chan job, resultChans chan chan *protocol.CommitMatch) (err error) {
+	var includeMerges, includeSignatures bool
+	visitMatchTree(cs.Query, func(mt MatchTree) {
+		switch mt.(type) {
+		case *ParentCount:
+			includeMerges = true
+		case *SignatureMatches:
+			includeSignatures = true
+		}
+	})
+
 	revArgs := revsToGitArgs(cs.Revisions)
-	args := append(logArgs, revArgs...)
+	args := append(logArgs(includeMerges, includeSignatures), revArgs...)
 	if cs.IncludeModifiedFiles {
 		args = append(args, "--name-only")
 	}
@@ -265,18 +294,19 @@ func revsToGitArgs(revs []protocol.RevisionSpecifier) []string {
 
 // RawCommit is a shallow parse of the output of git log
 type RawCommit struct {
-	Hash           []byte
-	RefNames       []byte
-	SourceRefs     []byte
-	AuthorName     []byte
-	AuthorEmail    []byte
-	AuthorDate     []byte
-	CommitterName  []byte
-	CommitterEmail []byte
-	CommitterDate  []byte
-	Message        []byte
-	ParentHashes   []byte
-	ModifiedFiles  [][]byte
+	Hash            []byte
+	RefNames        []byte
+	SourceRefs      []byte
+	AuthorName      []byte
+	AuthorEmail     []byte
+	AuthorDate      []byte
+	CommitterName   []byte
+	CommitterEmail  []byte
+	CommitterDate   []byte
+	Message         []byte
+	ParentHashes    []byte
+	SignatureStatus []byte
+	ModifiedFiles   [][]byte
 }
 
 type CommitScanner struct {
@@ -341,18 +371,19 @@ func (c *CommitScanner) Scan() bool {
 	}
 
 	c.next = &RawCommit{
-		Hash:           parts[0],
-		RefNames:       parts[1],
-		SourceRefs:     parts[2],
-		AuthorName:     parts[3],
-		AuthorEmail:    parts[4],
-		AuthorDate:     parts[5],
-		CommitterName:  parts[6],
-		CommitterEmail: parts[7],
-		CommitterDate:  parts[8],
-		Message:        bytes.TrimSpace(parts[9]),
-		ParentHashes:   parts[10],
-		ModifiedFiles:  parts[11:],
+		Hash:            parts[0],
+		RefNames:        parts[1],
+		SourceRefs:      parts[2],
+		AuthorName:      parts[3],
+		AuthorEmail:     parts[4],
+		AuthorDate:      parts[5],
+		CommitterName:   parts[6],
+		CommitterEmail:  parts[7],
+		CommitterDate:   parts[8],
+		Message:         bytes.TrimSpace(parts[9]),
+		ParentHashes:    parts[10],
+		SignatureStatus: parts[11],
+		ModifiedFiles:   parts[12:],
 	}
 
 	return true
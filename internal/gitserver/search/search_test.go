@@ -220,75 +220,79 @@ func TestCommitScanner(t *testing.T) {
 	}{
 		{
 			input: []byte(
-				"\x1E2061ba96d63cba38f20a76f039cf29ef68736b8a\x00\x00HEAD\x00Camden Cheek\x00camden@sourcegraph.com\x001632251505\x00Camden Cheek\x00camden@sourcegraph.com\x001632251505\x00fix import\n\x005230097b75dcbb2c214618dd171da4053aff18a6\x00\x00" +
-					"\x1E5230097b75dcbb2c214618dd171da4053aff18a6\x00\x00HEAD\x00Camden Cheek\x00camden@sourcegraph.com\x001632248499\x00Camden Cheek\x00camden@sourcegraph.com\x001632248499\x00only set matches if they exist\n\x00\x00",
+				"\x1E2061ba96d63cba38f20a76f039cf29ef68736b8a\x00\x00HEAD\x00Camden Cheek\x00camden@sourcegraph.com\x001632251505\x00Camden Cheek\x00camden@sourcegraph.com\x001632251505\x00fix import\n\x005230097b75dcbb2c214618dd171da4053aff18a6\x00N\x00\x00" +
+					"\x1E5230097b75dcbb2c214618dd171da4053aff18a6\x00\x00HEAD\x00Camden Cheek\x00camden@sourcegraph.com\x001632248499\x00Camden Cheek\x00camden@sourcegraph.com\x001632248499\x00only set matches if they exist\n\x00\x00G\x00",
 			),
 			expected: []*RawCommit{
 				{
-					Hash:           []byte("2061ba96d63cba38f20a76f039cf29ef68736b8a"),
-					RefNames:       []byte(""),
-					SourceRefs:     []byte("HEAD"),
-					AuthorName:     []byte("Camden Cheek"),
-					AuthorEmail:    []byte("camden@sourcegraph.com"),
-					AuthorDate:     []byte("1632251505"),
-					CommitterName:  []byte("Camden Cheek"),
-					CommitterEmail: []byte("camden@sourcegraph.com"),
-					CommitterDate:  []byte("1632251505"),
-					Message:        []byte("fix import"),
-					ParentHashes:   []byte("5230097b75dcbb2c214618dd171da4053aff18a6"),
-					ModifiedFiles:  [][]byte{{}, {}},
+					Hash:            []byte("2061ba96d63cba38f20a76f039cf29ef68736b8a"),
+					RefNames:        []byte(""),
+					SourceRefs:      []byte("HEAD"),
+					AuthorName:      []byte("Camden Cheek"),
+					AuthorEmail:     []byte("camden@sourcegraph.com"),
+					AuthorDate:      []byte("1632251505"),
+					CommitterName:   []byte("Camden Cheek"),
+					CommitterEmail:  []byte("camden@sourcegraph.com"),
+					CommitterDate:   []byte("1632251505"),
+					Message:         []byte("fix import"),
+					ParentHashes:    []byte("5230097b75dcbb2c214618dd171da4053aff18a6"),
+					SignatureStatus: []byte("N"),
+					ModifiedFiles:   [][]byte{{}, {}},
 				},
 				{
-					Hash:           []byte("5230097b75dcbb2c214618dd171da4053aff18a6"),
-					RefNames:       []byte(""),
-					SourceRefs:     []byte("HEAD"),
-					AuthorName:     []byte("Camden Cheek"),
-					AuthorEmail:    []byte("camden@sourcegraph.com"),
-					AuthorDate:     []byte("1632248499"),
-					CommitterName:  []byte("Camden Cheek"),
-					CommitterEmail: []byte("camden@sourcegraph.com"),
-					CommitterDate:  []byte("1632248499"),
-					Message:        []byte("only set matches if they exist"),
-					ParentHashes:   []byte(""),
-					ModifiedFiles:  [][]byte{{}},
+					Hash:            []byte("5230097b75dcbb2c214618dd171da4053aff18a6"),
+					RefNames:        []byte(""),
+					SourceRefs:      []byte("HEAD"),
+					AuthorName:      []byte("Camden Cheek"),
+					AuthorEmail:     []byte("camden@sourcegraph.com"),
+					AuthorDate:      []byte("1632248499"),
+					CommitterName:   []byte("Camden Cheek"),
+					CommitterEmail:  []byte("camden@sourcegraph.com"),
+					CommitterDate:   []byte("1632248499"),
+					Message:         []byte("only set matches if they exist"),
+					ParentHashes:    []byte(""),
+					SignatureStatus: []byte("G"),
+					ModifiedFiles:   [][]byte{{}},
 				},
 			},
 		},
 		{
 			input: []byte(
-				"\x1E2061ba96d63cba38f20a76f039cf29ef68736b8a\x00\x00HEAD\x00Camden Cheek\x00camden@sourcegraph.com\x001632251505\x00Camden Cheek\x00camden@sourcegraph.com\x001632251505\x00fix import\n\x005230097b75dcbb2c214618dd171da4053aff18a6\x00\x00file1" +
-					"\x1E5230097b75dcbb2c214618dd171da4053aff18a6\x00\x00HEAD\x00Camden Cheek\x00camden@sourcegraph.com\x001632248499\x00Camden Cheek\x00camden@sourcegraph.com\x001632248499\x00only set matches if they exist\n\x00\x00file1\x00file2",
+				"\x1E2061ba96d63cba38f20a76f039cf29ef68736b8a\x00\x00HEAD\x00Camden Cheek\x00camden@sourcegraph.com\x001632251505\x00Camden Cheek\x00camden@sourcegraph.com\x001632251505\x00fix import\n\x005230097b75dcbb2c214618dd171da4053aff18a6\x00N\x00\x00file1" +
+					"\x1E5230097b75dcbb2c214618dd171da4053aff18a6\x00\x00HEAD\x00Camden Cheek\x00camden@sourcegraph.com\x001632248499\x00Camden Cheek\x00camden@sourcegraph.com\x001632248499\x00only set matches if they exist\n\x00\x00G\x00file1\x00file2",
 			),
 			expected: []*RawCommit{
 				{
-					Hash:           []byte("2061ba96d63cba38f20a76f039cf29ef68736b8a"),
-					RefNames:       []byte(""),
-					SourceRefs:     []byte("HEAD"),
-					AuthorName:     []byte("Camden Cheek"),
-					AuthorEmail:    []byte("camden@sourcegraph.com"),
-					AuthorDate:     []byte("1632251505"),
-					CommitterName:  []byte("Camden Cheek"),
-					CommitterEmail: []byte("camden@sourcegraph.com"),
-					CommitterDate:  []byte("1632251505"),
-					Message:        []byte("fix import"),
-					ParentHashes:   []byte("5230097b75dcbb2c214618dd171da4053aff18a6"),
+					Hash:            []byte("2061ba96d63cba38f20a76f039cf29ef68736b8a"),
+					RefNames:        []byte(""),
+					SourceRefs:      []byte("HEAD"),
+					AuthorName:      []byte("Camden Cheek"),
+					AuthorEmail:     []byte("camden@sourcegraph.com"),
+					AuthorDate:      []byte("1632251505"),
+					CommitterName:   []byte("Camden Cheek"),
+					CommitterEmail:  []byte("camden@sourcegraph.com"),
+					CommitterDate:   []byte("1632251505"),
+					Message:         []byte("fix import"),
+					ParentHashes:    []byte("5230097b75dcbb2c214618dd171da4053aff18a6"),
+					SignatureStatus: []byte("N"),
 					ModifiedFiles: [][]byte{
 						{},
 						[]byte("file1"),
 					},
 				},
 				{
-					Hash:           []byte("5230097b75dcbb2c214618dd171da4053aff18a6"),
-					RefNames:       []byte(""),
-					SourceRefs:     []byte("HEAD"),
-					AuthorName:     []byte("Camden Cheek"),
-					AuthorEmail:    []byte("camden@sourcegraph.com"),
-					AuthorDate:     []byte("1632248499"),
-					CommitterName:  []byte("Camden Cheek"),
-					CommitterEmail: []byte("camden@sourcegraph.com"),
-					CommitterDate:  []byte("1632248499"),
-					Message:        []byte("only set matches if they exist"),
-					ParentHashes:   []byte(""),
+					Hash:            []byte("5230097b75dcbb2c214618dd171da4053aff18a6"),
+					RefNames:        []byte(""),
+					SourceRefs:      []byte("HEAD"),
+					AuthorName:      []byte("Camden Cheek"),
+					AuthorEmail:     []byte("camden@sourcegraph.com"),
+					AuthorDate:      []byte("1632248499"),
+					CommitterName:   []byte("Camden Cheek"),
+					CommitterEmail:  []byte("camden@sourcegraph.com"),
+					CommitterDate:   []byte("1632248499"),
+					Message:         []byte("only set matches if they exist"),
+					ParentHashes:    []byte(""),
+					SignatureStatus: []byte("G"),
 					ModifiedFiles: [][]byte{
 						[]byte("file1"),
 						[]byte("file2"),
@@ -625,3 +629,67 @@ func Test_revsToGitArgs(t *testing.T) {
 		require.Equal(t, tc.expected, got)
 	}
 }
+
+func TestSearchCommitMetadata(t *testing.T) {
+	const env = "GIT_COMMITTER_NAME=camden " +
+		"GIT_COMMITTER_EMAIL=camden@ccheek.com " +
+		"GIT_AUTHOR_NAME=camden " +
+		"GIT_AUTHOR_EMAIL=camden@ccheek.com "
+
+	cmds := []string{
+		"echo lorem > file1",
+		"git add -A",
+		env + "git commit -m 'add file1' -m 'Signed-off-by: Alice <alice@example.com>'",
+		"git checkout -b feature",
+		"echo ipsum > file2",
+		"git add -A",
+		env + "git commit -m 'add file2' -m 'Co-authored-by: Bob <bob@example.com>'",
+		"git checkout -",
+		env + "git merge --no-ff -m 'merge feature' feature",
+	}
+	dir := initGitRepository(t, cmds...)
+
+	run := func(t *testing.T, query protocol.Node) []string {
+		tree, err := ToMatchTree(query)
+		require.NoError(t, err)
+		searcher := &CommitSearcher{
+			RepoDir: dir,
+			Query:   tree,
+		}
+		var messages []string
+		err = searcher.Search(context.Background(), func(match *protocol.CommitMatch) {
+			messages = append(messages, strings.SplitN(match.Message.Content, "\n", 2)[0])
+		})
+		require.NoError(t, err)
+		return messages
+	}
+
+	t.Run("trailer", func(t *testing.T) {
+		got := run(t, &protocol.TrailerMatches{Key: "signed-off-by", Expr: "alice"})
+		require.Equal(t, []string{"add file1"}, got)
+
+		got = run(t, &protocol.TrailerMatches{Key: "Co-authored-by", Expr: "BOB", IgnoreCase: true})
+		require.Equal(t, []string{"add file2"}, got)
+	})
+
+	t.Run("missing trailer", func(t *testing.T) {
+		got := run(t, protocol.NewNot(&protocol.TrailerMatches{Key: "Signed-off-by"}))
+		require.Equal(t, []string{"add file2"}, got)
+	})
+
+	t.Run("merge commits", func(t *testing.T) {
+		got := run(t, &protocol.ParentCount{Min: 2, Max: -1})
+		require.Equal(t, []string{"merge feature"}, got)
+
+		got = run(t, &protocol.ParentCount{Min: 0, Max: 0})
+		require.Equal(t, []string{"add file1"}, got)
+	})
+
+	t.Run("unsigned commits", func(t *testing.T) {
+		got := run(t, &protocol.SignatureMatches{Status: protocol.SignatureUnsigned})
+		require.ElementsMatch(t, []string{"add file2", "add file1"}, got)
+
+		got = run(t, &protocol.SignatureMatches{Status: protocol.SignatureSigned})
+		require.Empty(t, got)
+	})
+}
@@ -0,0 +1,194 @@
+package repos
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/azuredevops"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// An AzureDevOpsSource yields repositories from a single Azure DevOps connection configured
+// in Sourcegraph via the external services configuration.
+type AzureDevOpsSource struct {
+	svc       *types.ExternalService
+	config    *schema.AzureDevOpsConnection
+	exclude   excludeFunc
+	cli       *azuredevops.Client
+	serviceID string
+}
+
+// NewAzureDevOpsSource returns a new AzureDevOpsSource from the given external service.
+func NewAzureDevOpsSource(svc *types.ExternalService, cf *httpcli.Factory) (*AzureDevOpsSource, error) {
+	var c schema.AzureDevOpsConnection
+	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
+		return nil, errors.Wrapf(err, "external service id=%d config error", svc.ID)
+	}
+	return newAzureDevOpsSource(svc, &c, cf)
+}
+
+func newAzureDevOpsSource(svc *types.ExternalService, c *schema.AzureDevOpsConnection, cf *httpcli.Factory) (*AzureDevOpsSource, error) {
+	if cf == nil {
+		cf = httpcli.ExternalClientFactory
+	}
+
+	httpCli, err := cf.Doer()
+	if err != nil {
+		return nil, err
+	}
+
+	var eb excludeBuilder
+	for _, r := range c.Exclude {
+		eb.Exact(r.Name)
+		eb.Exact(r.Id)
+		eb.Pattern(r.Pattern)
+	}
+	exclude, err := eb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := azuredevops.NewClient(svc.URN(), c, httpCli)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AzureDevOpsSource{
+		svc:       svc,
+		config:    c,
+		exclude:   exclude,
+		cli:       cli,
+		serviceID: extsvc.NormalizeBaseURL(cli.URL).String(),
+	}, nil
+}
+
+// ListRepos returns all Azure DevOps repositories of the organizations and projects
+// configured with this AzureDevOpsSource's config.
+func (s *AzureDevOpsSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	seen := make(map[string]bool)
+
+	for _, org := range s.config.Orgs {
+		s.listRepos(ctx, org, seen, results)
+	}
+
+	for _, project := range s.config.Projects {
+		s.listRepos(ctx, project, seen, results)
+	}
+}
+
+// listRepos sends all repositories of the given organization ("org") or project
+// ("org/project") over the results channel, skipping repositories that have
+// already been seen.
+func (s *AzureDevOpsSource) listRepos(ctx context.Context, projectOrOrg string, seen map[string]bool, results chan SourceResult) {
+	repos, err := s.cli.ListRepositoriesByProjectOrOrg(ctx, azuredevops.ListRepositoriesByProjectOrOrgArgs{
+		ProjectOrOrgName: projectOrOrg,
+	})
+	if err != nil {
+		results <- SourceResult{Source: s, Err: errors.Wrapf(err, "azuredevops.repositories: item=%q", projectOrOrg)}
+		return
+	}
+
+	// The organization is not part of the repository payload, so we take it from
+	// the item we've listed the repositories of.
+	org := strings.SplitN(projectOrOrg, "/", 2)[0]
+
+	for i := range repos {
+		repo := &repos[i]
+
+		// Disabled repositories cannot be cloned
+		if repo.IsDisabled {
+			continue
+		}
+
+		if seen[repo.ID] || s.excludes(org, repo) {
+			continue
+		}
+		seen[repo.ID] = true
+
+		r, err := s.makeRepo(org, repo)
+		if err != nil {
+			results <- SourceResult{Source: s, Err: err}
+			continue
+		}
+		results <- SourceResult{Source: s, Repo: r}
+	}
+}
+
+// ExternalServices returns a singleton slice containing the external service.
+func (s *AzureDevOpsSource) ExternalServices() types.ExternalServices {
+	return types.ExternalServices{s.svc}
+}
+
+func (s *AzureDevOpsSource) makeRepo(org string, r *azuredevops.Repository) (*types.Repo, error) {
+	host, err := url.Parse(s.serviceID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed Azure DevOps config, invalid URL: %q", s.config.Url)
+	}
+
+	urn := s.svc.URN()
+	nameWithOwner := azureDevOpsNameWithOwner(org, r)
+
+	return &types.Repo{
+		Name: reposource.AzureDevOpsRepoName(
+			s.config.RepositoryPathPattern,
+			host.Hostname(),
+			nameWithOwner,
+		),
+		URI: string(reposource.AzureDevOpsRepoName(
+			"",
+			host.Hostname(),
+			nameWithOwner,
+		)),
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          r.ID,
+			ServiceType: extsvc.TypeAzureDevOps,
+			ServiceID:   s.serviceID,
+		},
+		Fork:    r.IsFork,
+		Private: r.IsPrivate(),
+		Sources: map[string]*types.SourceInfo{
+			urn: {
+				ID:       urn,
+				CloneURL: s.remoteURL(r),
+			},
+		},
+		Metadata: r,
+	}, nil
+}
+
+// remoteURL returns the repository's Git remote URL
+//
+// note: this does not contain credentials, if you need to get an authenticated
+// clone url use repos.CloneURL
+func (s *AzureDevOpsSource) remoteURL(repo *azuredevops.Repository) string {
+	if s.config.GitURLType == "ssh" {
+		return repo.SSHURL
+	}
+
+	// Azure DevOps includes the organization as the user in HTTPS clone URLs
+	// (https://org@dev.azure.com/...), which we don't want to store.
+	u, err := url.Parse(repo.CloneURL)
+	if err != nil {
+		return repo.CloneURL
+	}
+	u.User = nil
+	return u.String()
+}
+
+func (s *AzureDevOpsSource) excludes(org string, r *azuredevops.Repository) bool {
+	return s.exclude(azureDevOpsNameWithOwner(org, r)) || s.exclude(r.ID)
+}
+
+// azureDevOpsNameWithOwner returns the "org/project/repository" name of the given
+// repository.
+func azureDevOpsNameWithOwner(org string, r *azuredevops.Repository) string {
+	return org + "/" + r.Project.Name + "/" + r.Name
+}
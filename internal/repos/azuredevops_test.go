@@ -0,0 +1,107 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestAzureDevOpsSource_ListRepos(t *testing.T) {
+	repository := func(id, project, name string, disabled bool) string {
+		return fmt.Sprintf(`{
+			"id": %q,
+			"name": %q,
+			"remoteUrl": "https://sgtest@dev.azure.com/sgtest/%[2]s/_git/%[3]s",
+			"sshUrl": "git@ssh.dev.azure.com:v3/sgtest/%[2]s/%[3]s",
+			"isDisabled": %[4]t,
+			"project": {"name": %[2]q, "visibility": "private"}
+		}`, id, project, name, disabled)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sgtest/_apis/git/repositories":
+			fmt.Fprintf(w, `{"count": 3, "value": [%s, %s, %s]}`,
+				repository("1", "alpha", "a", false),
+				repository("2", "alpha", "excluded", false),
+				repository("3", "beta", "disabled", true),
+			)
+		case "/sgtest/alpha/_apis/git/repositories":
+			fmt.Fprintf(w, `{"count": 2, "value": [%s, %s]}`,
+				repository("1", "alpha", "a", false),
+				repository("4", "alpha", "b", false),
+			)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	conf := &schema.AzureDevOpsConnection{
+		Url:      srv.URL,
+		Username: "user",
+		Token:    "token",
+		Orgs:     []string{"sgtest"},
+		Projects: []string{"sgtest/alpha"},
+		Exclude: []*schema.ExcludedAzureDevOpsRepo{
+			{Name: "sgtest/alpha/excluded"},
+		},
+	}
+
+	svc := &types.ExternalService{
+		Kind:   extsvc.KindAzureDevOps,
+		Config: marshalJSON(t, conf),
+	}
+
+	src, err := NewAzureDevOpsSource(svc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repos, err := listAll(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names, cloneURLs []string
+	for _, r := range repos {
+		names = append(names, string(r.Name))
+		for _, info := range r.Sources {
+			cloneURLs = append(cloneURLs, info.CloneURL)
+		}
+	}
+	sort.Strings(names)
+	sort.Strings(cloneURLs)
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := u.Hostname()
+
+	wantNames := []string{
+		host + "/sgtest/alpha/a",
+		host + "/sgtest/alpha/b",
+	}
+	if diff := cmp.Diff(wantNames, names); diff != "" {
+		t.Errorf("unexpected repo names (-want +got):\n%s", diff)
+	}
+
+	wantCloneURLs := []string{
+		"https://dev.azure.com/sgtest/alpha/_git/a",
+		"https://dev.azure.com/sgtest/alpha/_git/b",
+	}
+	if diff := cmp.Diff(wantCloneURLs, cloneURLs); diff != "" {
+		t.Errorf("unexpected clone URLs (-want +got):\n%s", diff)
+	}
+}
@@ -81,14 +81,9 @@ var (
 		Help: "Incremented each time the scheduler updates a repository due to user traffic.",
 	})
 
-	schedKnownRepos = promauto.NewGauge(prometheus.GaugeOpts{
-		Name: "src_repoupdater_sched_known_repos",
-		Help: "The number of repositories that are managed by the scheduler.",
-	})
-
-	schedUpdateQueueLength = promauto.NewGauge(prometheus.GaugeOpts{
-		Name: "src_repoupdater_sched_update_queue_length",
-		Help: "The number of repositories that are currently queued for update",
+	schedWebhookFetch = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "src_repoupdater_sched_webhook_fetch",
+		Help: "Incremented each time the scheduler updates a repository due to a code host push webhook.",
 	})
 )
 
@@ -323,4 +318,36 @@ and exists
 		}
 		return count
 	})
+
+	// The schedule and the update queue of the UpdateScheduler are shared by all
+	// repo-updater instances, so they are counted in the database.
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "src_repoupdater_sched_known_repos",
+		Help: "The number of repositories that are managed by the scheduler.",
+	}, func() float64 {
+		count, err := scanCount(`
+-- source: internal/repos/metrics.go:src_repoupdater_sched_known_repos
+SELECT COUNT(*) FROM repo_update_schedule
+`)
+		if err != nil {
+			log15.Error("Failed to count scheduled repos", "err", err)
+			return 0
+		}
+		return count
+	})
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "src_repoupdater_sched_update_queue_length",
+		Help: "The number of repositories that are currently queued for update",
+	}, func() float64 {
+		count, err := scanCount(`
+-- source: internal/repos/metrics.go:src_repoupdater_sched_update_queue_length
+SELECT COUNT(*) FROM repo_update_queue
+`)
+		if err != nil {
+			log15.Error("Failed to count queued repos", "err", err)
+			return 0
+		}
+		return count
+	})
 }
@@ -1,10 +1,8 @@
 package repos
 
 import (
-	"container/heap"
 	"context"
 	"math/rand"
-	"strings"
 	"sync"
 	"time"
 
@@ -17,9 +15,11 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
 	gitserverprotocol "github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/hostname"
 	"github.com/sourcegraph/sourcegraph/internal/mutablelimiter"
 	"github.com/sourcegraph/sourcegraph/internal/repoupdater/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
 )
 
 // schedulerConfig tracks the active scheduler configuration.
@@ -84,6 +84,28 @@ const (
 
 	// maxDelay is the maximum amount of time between scheduled updates for a single repository.
 	maxDelay = 8 * time.Hour
+
+	// webhookDelay is the minimum amount of time between scheduled updates for a repository
+	// whose code host sends push webhooks. Such repositories are updated as soon as a webhook
+	// is received, so polling only catches up on missed webhooks.
+	webhookDelay = maxDelay
+
+	// webhookExpiry is how long after the last push webhook a repository is still considered
+	// to receive webhooks.
+	webhookExpiry = 7 * 24 * time.Hour
+
+	// pollInterval is how often the scheduler looks for due repos in the schedule and for
+	// repos in the update queue. Both are shared with other repo-updater instances, which may
+	// have changed them in the meantime.
+	pollInterval = time.Second
+
+	// scheduleBatchSize is the maximum number of due repos that are enqueued at once.
+	scheduleBatchSize = 500
+
+	// leaseGracePeriod is added to the timeout of git commands to determine how long a
+	// repo-updater instance holds the lease of a repo that it updates. Should the instance go
+	// away during the update, other instances update the repo once the lease expires.
+	leaseGracePeriod = 5 * time.Minute
 )
 
 // UpdateScheduler schedules repo update (or clone) requests to gitserver.
@@ -100,15 +122,37 @@ const (
 // backoff by doubling the current interval. This ensures that problematic repos
 // don't stay in the front of the schedule clogging up the queue.
 //
+// Repos whose code host has sent a push webhook within the last week are updated
+// when the webhook is received, and are polled no more often than every 8 hours.
+//
 // When it is time for a repo to update, the scheduler inserts the repo into a queue.
 //
 // A worker continuously dequeues repos and sends updates to gitserver, but its concurrency
 // is limited by the gitMaxConcurrentClones site configuration.
+//
+// The schedule and the queue are stored in the database, so that they survive restarts
+// and so that multiple repo-updater instances can share the work: each due repo is
+// enqueued by a single instance, and each queued repo is leased to the single instance
+// that updates it.
 type UpdateScheduler struct {
-	db          database.DB
-	updateQueue *updateQueue
-	schedule    *schedule
-	logger      log.Logger
+	db     database.DB
+	store  *scheduleStore
+	logger log.Logger
+
+	// owner identifies this repo-updater instance in the leases of the update queue.
+	owner string
+
+	// The scheduler performs a non-blocking send on this channel when it enqueues
+	// repos so that the update loop can wake up if it is idle. Repos enqueued by
+	// other repo-updater instances are picked up by polling.
+	notifyEnqueue chan struct{}
+
+	mu sync.Mutex // protects randGenerator
+
+	// random source used to add jitter to repo update intervals.
+	randGenerator interface {
+		Int63n(n int64) int64
+	}
 }
 
 // A configuredRepo represents the configuration data for a given repo from
@@ -126,54 +170,50 @@ const notifyChanBuffer = 1
 
 // NewUpdateScheduler returns a new scheduler.
 func NewUpdateScheduler(logger log.Logger, db database.DB) *UpdateScheduler {
-	updateSchedLogger := logger.Scoped("UpdateScheduler", "repo update scheduler")
-
 	return &UpdateScheduler{
-		db: db,
-		updateQueue: &updateQueue{
-			index:         make(map[api.RepoID]*repoUpdate),
-			notifyEnqueue: make(chan struct{}, notifyChanBuffer),
-		},
-		schedule: &schedule{
-			index:         make(map[api.RepoID]*scheduledRepoUpdate),
-			wakeup:        make(chan struct{}, notifyChanBuffer),
-			randGenerator: rand.New(rand.NewSource(time.Now().UnixNano())),
-			logger:        updateSchedLogger.Scoped("Schedule", ""),
-		},
-		logger: updateSchedLogger,
+		db:            db,
+		store:         newScheduleStore(db),
+		logger:        logger.Scoped("UpdateScheduler", "repo update scheduler"),
+		owner:         hostname.Get(),
+		notifyEnqueue: make(chan struct{}, notifyChanBuffer),
+		randGenerator: rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
-// runScheduleLoop starts the loop that schedules updates by enqueuing them into the updateQueue.
+// runScheduleLoop starts the loop that schedules updates by enqueuing due repos into the
+// update queue.
 func (s *UpdateScheduler) runScheduleLoop(ctx context.Context) {
 	for {
 		select {
-		case <-s.schedule.wakeup:
+		case <-time.After(pollInterval):
 		case <-ctx.Done():
-			s.schedule.reset()
 			return
 		}
 
-		s.runSchedule()
+		if err := s.runSchedule(ctx); err != nil && ctx.Err() == nil {
+			schedError.WithLabelValues("runSchedule").Inc()
+			s.logger.Error("error enqueuing due repos", log.Error(err))
+		}
 		schedLoops.Inc()
 	}
 }
 
-func (s *UpdateScheduler) runSchedule() {
-	s.schedule.mu.Lock()
-	defer s.schedule.mu.Unlock()
-	defer s.schedule.rescheduleTimer()
-
-	for len(s.schedule.heap) != 0 {
-		repoUpdate := s.schedule.heap[0]
-		if !repoUpdate.Due.Before(timeNow().Add(time.Millisecond)) {
-			break
+func (s *UpdateScheduler) runSchedule(ctx context.Context) error {
+	for {
+		n, err := s.store.enqueueDue(ctx, timeNow(), scheduleBatchSize)
+		if err != nil {
+			return err
 		}
+		if n == 0 {
+			return nil
+		}
+
+		schedAutoFetch.Add(float64(n))
+		notify(s.notifyEnqueue)
 
-		schedAutoFetch.Inc()
-		s.updateQueue.enqueue(repoUpdate.Repo, priorityLow)
-		repoUpdate.Due = timeNow().Add(repoUpdate.Interval)
-		heap.Fix(s.schedule, 0)
+		if n < scheduleBatchSize {
+			return nil
+		}
 	}
 }
 
@@ -183,9 +223,9 @@ func (s *UpdateScheduler) runUpdateLoop(ctx context.Context) {
 
 	for {
 		select {
-		case <-s.updateQueue.notifyEnqueue:
+		case <-s.notifyEnqueue:
+		case <-time.After(pollInterval):
 		case <-ctx.Done():
-			s.updateQueue.reset()
 			return
 		}
 
@@ -196,7 +236,15 @@ func (s *UpdateScheduler) runUpdateLoop(ctx context.Context) {
 				return
 			}
 
-			repo, ok := s.updateQueue.acquireNext()
+			repo, ok, err := s.store.acquireNext(ctx, s.owner, timeNow(), timeNow().Add(conf.GitLongCommandTimeout()+leaseGracePeriod))
+			if err != nil {
+				if ctx.Err() == nil {
+					schedError.WithLabelValues("acquireNext").Inc()
+					s.logger.Error("error acquiring repo for update", log.Error(err))
+				}
+				cancel()
+				break
+			}
 			if !ok {
 				cancel()
 				break
@@ -206,7 +254,14 @@ func (s *UpdateScheduler) runUpdateLoop(ctx context.Context) {
 
 			go func(ctx context.Context, repo configuredRepo, cancel context.CancelFunc) {
 				defer cancel()
-				defer s.updateQueue.remove(repo, true)
+				defer func() {
+					// Release the repo even if the scheduler is stopping, so that other
+					// instances don't have to wait for the lease to expire.
+					if err := s.store.release(context.Background(), repo.ID, s.owner); err != nil {
+						schedError.WithLabelValues("release").Inc()
+						subLogger.Error("error releasing repo", log.Error(err), log.String("uri", string(repo.Name)))
+					}
+				}()
 
 				// This is a blocking call since the repo will be cloned synchronously by gitserver
 				// if it doesn't exist or update it if it does. The timeout of this request depends
@@ -221,28 +276,48 @@ func (s *UpdateScheduler) runUpdateLoop(ctx context.Context) {
 					subLogger.Error("error updating repo", log.String("err", resp.Error), log.String("uri", string(repo.Name)))
 				}
 
-				if interval := getCustomInterval(subLogger, conf.Get(), string(repo.Name)); interval > 0 {
-					s.schedule.updateInterval(repo, interval)
-					return
-				}
-
-				if err != nil || (resp != nil && resp.Error != "") {
-					// On error we will double the current interval so that we back off and don't
-					// get stuck with problematic repos with low intervals.
-					if currentInterval, ok := s.schedule.getCurrentInterval(repo); ok {
-						s.schedule.updateInterval(repo, currentInterval*2)
-					}
-				} else if resp != nil && resp.LastFetched != nil && resp.LastChanged != nil {
-					// This is the heuristic that is described in the UpdateScheduler documentation.
-					// Update that documentation if you update this logic.
-					interval := resp.LastFetched.Sub(*resp.LastChanged) / 2
-					s.schedule.updateInterval(repo, interval)
+				if err := s.reschedule(ctx, repo, resp, err); err != nil {
+					schedError.WithLabelValues("reschedule").Inc()
+					subLogger.Error("error rescheduling repo", log.Error(err), log.String("uri", string(repo.Name)))
 				}
 			}(ctx, repo, cancel)
 		}
 	}
 }
 
+// reschedule updates the update interval of a repo in the schedule after an update
+// finished with the given response and error.
+func (s *UpdateScheduler) reschedule(ctx context.Context, repo configuredRepo, resp *gitserverprotocol.RepoUpdateResponse, updateErr error) error {
+	if interval := getCustomInterval(s.logger, conf.Get(), string(repo.Name)); interval > 0 {
+		return s.updateInterval(ctx, repo, interval)
+	}
+
+	update, ok, err := s.store.get(ctx, repo.ID)
+	if err != nil || !ok {
+		return err
+	}
+
+	if updateErr != nil || (resp != nil && resp.Error != "") {
+		// On error we will double the current interval so that we back off and don't
+		// get stuck with problematic repos with low intervals.
+		return s.updateInterval(ctx, repo, update.Interval*2)
+	}
+
+	if resp != nil && resp.LastFetched != nil && resp.LastChanged != nil {
+		// This is the heuristic that is described in the UpdateScheduler documentation.
+		// Update that documentation if you update this logic.
+		interval := resp.LastFetched.Sub(*resp.LastChanged) / 2
+		if interval < webhookDelay && update.receivesWebhooks() {
+			// New commits trigger an update through a webhook, so we only
+			// need to poll to catch up on missed webhooks.
+			interval = webhookDelay
+		}
+		return s.updateInterval(ctx, repo, interval)
+	}
+
+	return nil
+}
+
 func getCustomInterval(logger log.Logger, c *conf.Unified, repoName string) time.Duration {
 	if c == nil {
 		return 0
@@ -287,35 +362,43 @@ var configuredLimiter = func() *mutablelimiter.Limiter {
 // possible. We treat repos differently depending on which part of the
 // diff they are:
 //
-//
-//   Deleted    - remove from scheduler and queue.
-//   Added      - new repo, enqueue for asap clone.
-//   Modified   - likely new url or name. May also be a sign of new
-//                commits. Enqueue for asap clone (or fetch).
-//   Unmodified - we likely already have this cloned. Just rely on
-//                the scheduler and do not enqueue.
-func (s *UpdateScheduler) UpdateFromDiff(diff Diff) {
+//	Deleted    - remove from scheduler and queue.
+//	Added      - new repo, enqueue for asap clone.
+//	Modified   - likely new url or name. May also be a sign of new
+//	             commits. Enqueue for asap clone (or fetch).
+//	Unmodified - we likely already have this cloned. Just rely on
+//	             the scheduler and do not enqueue.
+func (s *UpdateScheduler) UpdateFromDiff(ctx context.Context, diff Diff) error {
+	var removed, upserted, enqueued []api.RepoID
+
 	for _, r := range diff.Deleted {
-		s.remove(r)
+		removed = append(removed, r.ID)
 	}
 
 	for _, r := range diff.Added {
-		s.upsert(r, true)
+		upserted = append(upserted, r.ID)
+		enqueued = append(enqueued, r.ID)
 	}
 	for _, r := range diff.Modified {
-		s.upsert(r, true)
+		upserted = append(upserted, r.ID)
+		enqueued = append(enqueued, r.ID)
 	}
 
-	known := len(diff.Added) + len(diff.Modified)
 	for _, r := range diff.Unmodified {
 		if r.IsDeleted() {
-			s.remove(r)
+			removed = append(removed, r.ID)
 			continue
 		}
+		upserted = append(upserted, r.ID)
+	}
 
-		known++
-		s.upsert(r, false)
+	if err := s.store.remove(ctx, removed, timeNow()); err != nil {
+		return errors.Wrap(err, "removing deleted repos")
+	}
+	if err := s.store.insert(ctx, upserted, timeNow().Add(minDelay)); err != nil {
+		return errors.Wrap(err, "scheduling repos")
 	}
+	return s.enqueue(ctx, enqueued, priorityLow)
 }
 
 // PrioritiseUncloned will treat any repos listed in ids as uncloned, which in
@@ -323,77 +406,87 @@ func (s *UpdateScheduler) UpdateFromDiff(diff Diff) {
 //
 // This method should be called periodically with the list of all repositories
 // managed by the scheduler that are not cloned on gitserver.
-func (s *UpdateScheduler) PrioritiseUncloned(repos []types.MinimalRepo) {
-	s.schedule.prioritiseUncloned(repos)
+func (s *UpdateScheduler) PrioritiseUncloned(ctx context.Context, repos []types.MinimalRepo) error {
+	// All non-cloned repos will be due for cloning as if they are newly added
+	// repos.
+	return s.store.prioritise(ctx, minimalRepoIDs(repos), timeNow().Add(minDelay))
 }
 
 // EnsureScheduled ensures that all repos in repos exist in the scheduler.
-func (s *UpdateScheduler) EnsureScheduled(repos []types.MinimalRepo) {
-	s.schedule.insertNew(repos)
+func (s *UpdateScheduler) EnsureScheduled(ctx context.Context, repos []types.MinimalRepo) error {
+	return s.store.insert(ctx, minimalRepoIDs(repos), timeNow().Add(minDelay))
 }
 
-// ListRepoIDs lists the ids of all repos managed by the scheduler
-func (s *UpdateScheduler) ListRepoIDs() []api.RepoID {
-	s.schedule.mu.Lock()
-	defer s.schedule.mu.Unlock()
-
-	ids := make([]api.RepoID, len(s.schedule.heap))
-	for i := range s.schedule.heap {
-		ids[i] = s.schedule.heap[i].Repo.ID
+func minimalRepoIDs(repos []types.MinimalRepo) []api.RepoID {
+	ids := make([]api.RepoID, 0, len(repos))
+	for _, r := range repos {
+		ids = append(ids, r.ID)
 	}
 	return ids
 }
 
-// upsert adds r to the scheduler for periodic updates. If r.ID is already in
-// the scheduler, then the fields are updated (upsert).
-//
-// If enqueue is true then r is also enqueued to the update queue for a git
-// fetch/clone soon.
-func (s *UpdateScheduler) upsert(r *types.Repo, enqueue bool) {
-	repo := configuredRepoFromRepo(r)
-	logger := s.logger.With(log.String("repo", string(r.Name)))
+// ListRepoIDs lists the ids of all repos managed by the scheduler
+func (s *UpdateScheduler) ListRepoIDs(ctx context.Context) ([]api.RepoID, error) {
+	return s.store.listRepoIDs(ctx)
+}
 
-	updated := s.schedule.upsert(repo)
-	logger.Debug("scheduler.schedule.upserted", log.Bool("updated", updated))
+// UpdateOnce causes a single update of the given repository.
+// It neither adds nor removes the repo from the schedule.
+func (s *UpdateScheduler) UpdateOnce(ctx context.Context, id api.RepoID, name api.RepoName) error {
+	schedManualFetch.Inc()
+	return s.enqueue(ctx, []api.RepoID{id}, priorityHigh)
+}
 
-	if !enqueue {
-		return
+// UpdateFromWebhook causes a single update of the given repository in response
+// to a push webhook from its code host. The repo is then considered to receive
+// webhooks, which backs off its scheduled updates.
+func (s *UpdateScheduler) UpdateFromWebhook(ctx context.Context, id api.RepoID, name api.RepoName) error {
+	if err := s.store.recordWebhook(ctx, id, timeNow()); err != nil {
+		return errors.Wrap(err, "recording webhook")
 	}
-	updated = s.updateQueue.enqueue(repo, priorityLow)
-	logger.Debug("scheduler.updateQueue.enqueued", log.Bool("updated", updated))
+	schedWebhookFetch.Inc()
+	return s.enqueue(ctx, []api.RepoID{id}, priorityHigh)
 }
 
-func (s *UpdateScheduler) remove(r *types.Repo) {
-	repo := configuredRepoFromRepo(r)
-	logger := s.logger.With(log.String("repo", string(r.Name)))
-
-	if s.schedule.remove(repo) {
-		logger.Debug("scheduler.schedule.removed")
+// enqueue adds the repos to the update queue with the given priority, and wakes
+// up the update loop.
+func (s *UpdateScheduler) enqueue(ctx context.Context, ids []api.RepoID, p priority) error {
+	if len(ids) == 0 {
+		return nil
 	}
-
-	if s.updateQueue.remove(repo, false) {
-		logger.Debug("scheduler.updateQueue.removed")
+	if err := s.store.enqueue(ctx, ids, p, timeNow()); err != nil {
+		return errors.Wrap(err, "enqueuing repos")
 	}
+	notify(s.notifyEnqueue)
+	return nil
 }
 
-func configuredRepoFromRepo(r *types.Repo) configuredRepo {
-	repo := configuredRepo{
-		ID:   r.ID,
-		Name: r.Name,
+// updateInterval updates the update interval of a repo in the schedule.
+// It does nothing if the repo is not in the schedule.
+func (s *UpdateScheduler) updateInterval(ctx context.Context, repo configuredRepo, interval time.Duration) error {
+	switch {
+	case interval > maxDelay:
+		interval = maxDelay
+	case interval < minDelay:
+		interval = minDelay
 	}
 
-	return repo
-}
+	// Add a jitter of 5% on either side of the interval to avoid
+	// repos getting updated at the same time.
+	delta := int64(interval) / 20
+	s.mu.Lock()
+	interval = interval + time.Duration(s.randGenerator.Int63n(2*delta)-delta)
+	s.mu.Unlock()
 
-// UpdateOnce causes a single update of the given repository.
-// It neither adds nor removes the repo from the schedule.
-func (s *UpdateScheduler) UpdateOnce(id api.RepoID, name api.RepoName) {
-	repo := configuredRepo{
-		ID:   id,
-		Name: name,
+	due := timeNow().Add(interval)
+	if err := s.store.updateInterval(ctx, repo.ID, interval, due); err != nil {
+		return err
 	}
-	schedManualFetch.Inc()
-	s.updateQueue.enqueue(repo, priorityHigh)
+
+	s.logger.Debug("updated repo",
+		log.Object("repo", log.String("name", string(repo.Name)), log.Duration("due", due.Sub(timeNow()))),
+	)
+	return nil
 }
 
 // DebugDump returns the state of the update scheduler for debugging.
@@ -407,45 +500,17 @@ func (s *UpdateScheduler) DebugDump(ctx context.Context, db database.DB) any {
 		Name: "repos",
 	}
 
-	s.schedule.mu.Lock()
-	schedule := schedule{
-		heap: make([]*scheduledRepoUpdate, len(s.schedule.heap)),
-	}
-	for i, update := range s.schedule.heap {
-		// Copy the scheduledRepoUpdate as a value so that
-		// popping off the heap here won't update the index value of the real heap, and
-		// we don't do a racy read on the repo pointer which may change concurrently in the real heap.
-		updateCopy := *update
-		schedule.heap[i] = &updateCopy
-	}
-	s.schedule.mu.Unlock()
-
-	for len(schedule.heap) > 0 {
-		update := heap.Pop(&schedule).(*scheduledRepoUpdate)
-		data.Schedule = append(data.Schedule, update)
-	}
-
-	s.updateQueue.mu.Lock()
-	updateQueue := updateQueue{
-		heap: make([]*repoUpdate, len(s.updateQueue.heap)),
-	}
-	for i, update := range s.updateQueue.heap {
-		// Copy the repoUpdate as a value so that
-		// popping off the heap here won't update the index value of the real heap, and
-		// we don't do a racy read on the repo pointer which may change concurrently in the real heap.
-		updateCopy := *update
-		updateQueue.heap[i] = &updateCopy
+	var err error
+	data.Schedule, err = s.store.list(ctx)
+	if err != nil {
+		s.logger.Warn("getting schedule for debug page", log.Error(err))
 	}
-	s.updateQueue.mu.Unlock()
 
-	for len(updateQueue.heap) > 0 {
-		// Copy the scheduledRepoUpdate as a value so that the repo pointer
-		// won't change concurrently after we release the lock.
-		update := heap.Pop(&updateQueue).(*repoUpdate)
-		data.UpdateQueue = append(data.UpdateQueue, update)
+	data.UpdateQueue, err = s.store.listQueue(ctx, timeNow())
+	if err != nil {
+		s.logger.Warn("getting update queue for debug page", log.Error(err))
 	}
 
-	var err error
 	data.SyncJobs, err = db.ExternalServices().GetSyncJobs(ctx)
 	if err != nil {
 		s.logger.Warn("getting external service sync jobs for debug page", log.Error(err))
@@ -455,49 +520,31 @@ func (s *UpdateScheduler) DebugDump(ctx context.Context, db database.DB) any {
 }
 
 // ScheduleInfo returns the current schedule info for a repo.
-func (s *UpdateScheduler) ScheduleInfo(id api.RepoID) *protocol.RepoUpdateSchedulerInfoResult {
-	var result protocol.RepoUpdateSchedulerInfoResult
+func (s *UpdateScheduler) ScheduleInfo(ctx context.Context, id api.RepoID) (*protocol.RepoUpdateSchedulerInfoResult, error) {
+	schedule, queue, err := s.store.scheduleInfo(ctx, id, timeNow())
+	if err != nil {
+		return nil, err
+	}
 
-	s.schedule.mu.Lock()
-	if update := s.schedule.index[id]; update != nil {
+	var result protocol.RepoUpdateSchedulerInfoResult
+	if schedule != nil {
 		result.Schedule = &protocol.RepoScheduleState{
-			Index:           update.Index,
-			Total:           len(s.schedule.index),
-			IntervalSeconds: int(update.Interval / time.Second),
-			Due:             update.Due,
+			Index:           schedule.Index,
+			Total:           schedule.Total,
+			IntervalSeconds: int(schedule.Interval / time.Second),
+			Due:             schedule.Due,
 		}
 	}
-	s.schedule.mu.Unlock()
-
-	s.updateQueue.mu.Lock()
-	if update := s.updateQueue.index[id]; update != nil {
+	if queue != nil {
 		result.Queue = &protocol.RepoQueueState{
-			Index:    update.Index,
-			Total:    len(s.updateQueue.index),
-			Updating: update.Updating,
-			Priority: int(update.Priority),
+			Index:    queue.Index,
+			Total:    queue.Total,
+			Updating: queue.Updating,
+			Priority: int(queue.Priority),
 		}
 	}
-	s.updateQueue.mu.Unlock()
-
-	return &result
-}
-
-// updateQueue is a priority queue of repos to update.
-// A repo can't have more than one location in the queue.
-// Implements heap.Interface and sort.Interface.
-type updateQueue struct {
-	mu sync.Mutex
 
-	heap  []*repoUpdate
-	index map[api.RepoID]*repoUpdate
-
-	seq uint64
-
-	// The queue performs a non-blocking send on this channel
-	// when a new value is enqueued so that the update loop
-	// can wake up if it is idle.
-	notifyEnqueue chan struct{}
+	return &result, nil
 }
 
 type priority int
@@ -511,418 +558,23 @@ const (
 type repoUpdate struct {
 	Repo     configuredRepo
 	Priority priority
-	Seq      uint64 // the sequence number of the update
-	Updating bool   // whether the repo has been acquired for update
-	Index    int    `json:"-"` // the index in the heap
-}
-
-func (q *updateQueue) reset() {
-	q.mu.Lock()
-	defer q.mu.Unlock()
-
-	q.heap = q.heap[:0]
-	q.index = map[api.RepoID]*repoUpdate{}
-	q.seq = 0
-	q.notifyEnqueue = make(chan struct{}, notifyChanBuffer)
-
-	schedUpdateQueueLength.Set(0)
-}
-
-// enqueue adds the repo to the queue with the given priority.
-//
-// If the repo is already in the queue and it isn't yet updating,
-// the repo is updated.
-//
-// If the given priority is higher than the one in the queue,
-// the repo's position in the queue is updated accordingly.
-func (q *updateQueue) enqueue(repo configuredRepo, p priority) (updated bool) {
-	if repo.ID == 0 {
-		panic("repo.id is zero")
-	}
-
-	q.mu.Lock()
-	defer q.mu.Unlock()
-
-	update := q.index[repo.ID]
-	if update == nil {
-		heap.Push(q, &repoUpdate{
-			Repo:     repo,
-			Priority: p,
-		})
-		notify(q.notifyEnqueue)
-		return false
-	}
-
-	if update.Updating {
-		return false
-	}
-
-	update.Repo = repo
-	if p <= update.Priority {
-		// Repo is already in the queue with at least as good priority.
-		return true
-	}
-
-	// Repo is in the queue at a lower priority.
-	update.Priority = p      // bump the priority
-	update.Seq = q.nextSeq() // put it after all existing updates with this priority
-	heap.Fix(q, update.Index)
-	notify(q.notifyEnqueue)
-
-	return true
-}
-
-// nextSeq increments and returns the next sequence number.
-// The caller must hold the lock on q.mu.
-func (q *updateQueue) nextSeq() uint64 {
-	q.seq++
-	return q.seq
-}
-
-// remove removes the repo from the queue if the repo.Updating matches the updating argument.
-func (q *updateQueue) remove(repo configuredRepo, updating bool) (removed bool) {
-	if repo.ID == 0 {
-		panic("repo.id is zero")
-	}
-
-	q.mu.Lock()
-	defer q.mu.Unlock()
-
-	update := q.index[repo.ID]
-	if update != nil && update.Updating == updating {
-		heap.Remove(q, update.Index)
-		return true
-	}
-
-	return false
-}
-
-// acquireNext acquires the next repo for update.
-// The acquired repo must be removed from the queue
-// when the update finishes (independent of success or failure).
-func (q *updateQueue) acquireNext() (configuredRepo, bool) {
-	q.mu.Lock()
-	defer q.mu.Unlock()
-	if len(q.heap) == 0 {
-		return configuredRepo{}, false
-	}
-	update := q.heap[0]
-	if update.Updating {
-		// Everything in the queue is already updating.
-		return configuredRepo{}, false
-	}
-	update.Updating = true
-	heap.Fix(q, update.Index)
-	return update.Repo, true
-}
-
-// The following methods implement heap.Interface based on the priority queue example:
-// https://golang.org/pkg/container/heap/#example__priorityQueue
-// These methods are not safe for concurrent use. Therefore, it is the caller's
-// responsibility to ensure they're being guarded by a mutex during any heap operation,
-// i.e. heap.Fix, heap.Remove, heap.Push, heap.Pop.
-
-func (q *updateQueue) Len() int {
-	n := len(q.heap)
-	schedUpdateQueueLength.Set(float64(n))
-	return n
-}
-
-func (q *updateQueue) Less(i, j int) bool {
-	qi := q.heap[i]
-	qj := q.heap[j]
-	if qi.Updating != qj.Updating {
-		// Repos that are already updating are sorted last.
-		return qj.Updating
-	}
-	if qi.Priority != qj.Priority {
-		// We want Pop to give us the highest, not lowest, priority so we use greater than here.
-		return qi.Priority > qj.Priority
-	}
-	// Queue semantics for items with the same priority.
-	return qi.Seq < qj.Seq
-}
-
-func (q *updateQueue) Swap(i, j int) {
-	q.heap[i], q.heap[j] = q.heap[j], q.heap[i]
-	q.heap[i].Index = i
-	q.heap[j].Index = j
-}
-
-func (q *updateQueue) Push(x any) {
-	n := len(q.heap)
-	item := x.(*repoUpdate)
-	item.Index = n
-	item.Seq = q.nextSeq()
-	q.heap = append(q.heap, item)
-	q.index[item.Repo.ID] = item
-}
-
-func (q *updateQueue) Pop() any {
-	n := len(q.heap)
-	item := q.heap[n-1]
-	item.Index = -1 // for safety
-	q.heap = q.heap[0 : n-1]
-	delete(q.index, item.Repo.ID)
-	return item
-}
-
-// schedule is the schedule of when repos get enqueued into the updateQueue.
-type schedule struct {
-	mu sync.Mutex
-
-	heap  []*scheduledRepoUpdate // min heap of scheduledRepoUpdates based on their due time.
-	index map[api.RepoID]*scheduledRepoUpdate
-
-	// timer sends a value on the wakeup channel when it is time
-	timer  *time.Timer
-	wakeup chan struct{}
-	logger log.Logger
-
-	// random source used to add jitter to repo update intervals.
-	randGenerator interface {
-		Int63n(n int64) int64
-	}
+	QueuedAt time.Time // the time at which the repo was queued with its priority
+	Updating bool      // whether the repo has been acquired for update
+	LeasedBy string    // the repo-updater instance that acquired the repo
 }
 
 // scheduledRepoUpdate is the update schedule for a single repo.
 type scheduledRepoUpdate struct {
-	Repo     configuredRepo // the repo to update
-	Interval time.Duration  // how regularly the repo is updated
-	Due      time.Time      // the next time that the repo will be enqueued for a update
-	Index    int            `json:"-"` // the index in the heap
-}
-
-// upsert inserts or updates a repo in the schedule.
-func (s *schedule) upsert(repo configuredRepo) (updated bool) {
-	if repo.ID == 0 {
-		panic("repo.id is zero")
-	}
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if update := s.index[repo.ID]; update != nil {
-		update.Repo = repo
-		return true
-	}
-
-	heap.Push(s, &scheduledRepoUpdate{
-		Repo:     repo,
-		Interval: minDelay,
-		Due:      timeNow().Add(minDelay),
-	})
-
-	s.rescheduleTimer()
-
-	return false
+	Repo        configuredRepo // the repo to update
+	Interval    time.Duration  // how regularly the repo is updated
+	Due         time.Time      // the next time that the repo will be enqueued for a update
+	LastWebhook time.Time      // the last time that a push webhook was received for the repo
 }
 
-func (s *schedule) prioritiseUncloned(uncloned []types.MinimalRepo) {
-	// All non-cloned repos will be due for cloning as if they are newly added
-	// repos.
-	notClonedDue := timeNow().Add(minDelay)
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	// Iterate over all repos in the scheduler. If it isn't in cloned bump it
-	// up the queue. Note: we iterate over index because we will be mutating
-	// heap.
-	rescheduleTimer := false
-	for _, repo := range uncloned {
-		if repoUpdate := s.index[repo.ID]; repoUpdate == nil {
-			heap.Push(s, &scheduledRepoUpdate{
-				Repo:     configuredRepo{ID: repo.ID, Name: repo.Name},
-				Interval: minDelay,
-				Due:      notClonedDue,
-			})
-			rescheduleTimer = true
-		} else if repoUpdate.Due.After(notClonedDue) {
-			repoUpdate.Due = notClonedDue
-			heap.Fix(s, repoUpdate.Index)
-			rescheduleTimer = true
-		}
-	}
-
-	// We updated the queue, inform the scheduler loop.
-	if rescheduleTimer {
-		s.rescheduleTimer()
-	}
-}
-
-// insertNew will insert repos only if they are not known to the scheduler
-func (s *schedule) insertNew(repos []types.MinimalRepo) {
-	required := make(map[string]struct{}, len(repos))
-	for _, n := range repos {
-		required[strings.ToLower(string(n.Name))] = struct{}{}
-	}
-
-	configuredRepos := make([]configuredRepo, len(repos))
-	for i := range repos {
-		configuredRepos[i] = configuredRepo{
-			ID:   repos[i].ID,
-			Name: repos[i].Name,
-		}
-	}
-
-	due := timeNow().Add(minDelay)
-	rescheduleTimer := false
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	for _, repo := range configuredRepos {
-		if update := s.index[repo.ID]; update != nil {
-			continue
-		}
-		heap.Push(s, &scheduledRepoUpdate{
-			Repo:     repo,
-			Interval: minDelay,
-			Due:      due,
-		})
-		rescheduleTimer = true
-	}
-
-	if rescheduleTimer {
-		s.rescheduleTimer()
-	}
-}
-
-// updateInterval updates the update interval of a repo in the schedule.
-// It does nothing if the repo is not in the schedule.
-func (s *schedule) updateInterval(repo configuredRepo, interval time.Duration) {
-	if repo.ID == 0 {
-		panic("repo.id is zero")
-	}
-
-	s.mu.Lock()
-	if update := s.index[repo.ID]; update != nil {
-		switch {
-		case interval > maxDelay:
-			update.Interval = maxDelay
-		case interval < minDelay:
-			update.Interval = minDelay
-		default:
-			update.Interval = interval
-		}
-
-		// Add a jitter of 5% on either side of the interval to avoid
-		// repos getting updated at the same time.
-		delta := int64(update.Interval) / 20
-		update.Interval = update.Interval + time.Duration(s.randGenerator.Int63n(2*delta)-delta)
-
-		update.Due = timeNow().Add(update.Interval)
-		s.logger.Debug("updated repo",
-			log.Object("repo", log.String("name", string(repo.Name)), log.Duration("due", update.Due.Sub(timeNow()))),
-		)
-		heap.Fix(s, update.Index)
-		s.rescheduleTimer()
-	}
-	s.mu.Unlock()
-}
-
-// getCurrentInterval gets the current interval for the supplied repo and a bool
-// indicating whether it was found.
-func (s *schedule) getCurrentInterval(repo configuredRepo) (time.Duration, bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	update, ok := s.index[repo.ID]
-	if !ok || update == nil {
-		return 0, false
-	}
-	return update.Interval, true
-}
-
-// remove removes a repo from the schedule.
-func (s *schedule) remove(repo configuredRepo) (removed bool) {
-	if repo.ID == 0 {
-		panic("repo.id is zero")
-	}
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	update := s.index[repo.ID]
-	if update == nil {
-		return false
-	}
-
-	reschedule := update.Index == 0
-	if heap.Remove(s, update.Index); reschedule {
-		s.rescheduleTimer()
-	}
-
-	return true
-}
-
-// rescheduleTimer schedules the scheduler to wakeup
-// at the time that the next repo is due for an update.
-// The caller must hold the lock on s.mu.
-func (s *schedule) rescheduleTimer() {
-	if s.timer != nil {
-		s.timer.Stop()
-		s.timer = nil
-	}
-	if len(s.heap) > 0 {
-		delay := s.heap[0].Due.Sub(timeNow())
-		s.timer = timeAfterFunc(delay, func() {
-			notify(s.wakeup)
-		})
-	}
-}
-
-func (s *schedule) reset() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	s.heap = s.heap[:0]
-	s.index = map[api.RepoID]*scheduledRepoUpdate{}
-	s.wakeup = make(chan struct{}, notifyChanBuffer)
-	if s.timer != nil {
-		s.timer.Stop()
-		s.timer = nil
-	}
-
-	s.logger.Debug("schedKnownRepos reset")
-	schedKnownRepos.Set(0)
-}
-
-// The following methods implement heap.Interface based on the priority queue example:
-// https://golang.org/pkg/container/heap/#example__priorityQueue
-// These methods are not safe for concurrent use. Therefore, it is the caller's
-// responsibility to ensure they're being guarded by a mutex during any heap operation,
-// i.e. heap.Fix, heap.Remove, heap.Push, heap.Pop.
-
-func (s *schedule) Len() int { return len(s.heap) }
-func (s *schedule) Less(i, j int) bool {
-	return s.heap[i].Due.Before(s.heap[j].Due)
-}
-
-func (s *schedule) Swap(i, j int) {
-	s.heap[i], s.heap[j] = s.heap[j], s.heap[i]
-	s.heap[i].Index = i
-	s.heap[j].Index = j
-}
-
-func (s *schedule) Push(x any) {
-	n := len(s.heap)
-	item := x.(*scheduledRepoUpdate)
-	item.Index = n
-	s.heap = append(s.heap, item)
-	s.index[item.Repo.ID] = item
-	schedKnownRepos.Inc()
-}
-
-func (s *schedule) Pop() any {
-	n := len(s.heap)
-	item := s.heap[n-1]
-	item.Index = -1 // for safety
-	s.heap = s.heap[0 : n-1]
-	delete(s.index, item.Repo.ID)
-	schedKnownRepos.Dec()
-	return item
+// receivesWebhooks returns whether a push webhook was received for the repo
+// within webhookExpiry.
+func (u *scheduledRepoUpdate) receivesWebhooks() bool {
+	return !u.LastWebhook.IsZero() && timeNow().Sub(u.LastWebhook) < webhookExpiry
 }
 
 // notify performs a non-blocking send on the channel.
@@ -935,7 +587,4 @@ var notify = func(ch chan struct{}) {
 }
 
 // Mockable time functions for testing.
-var (
-	timeNow       = time.Now
-	timeAfterFunc = time.AfterFunc
-)
+var timeNow = time.Now
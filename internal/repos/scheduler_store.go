@@ -0,0 +1,371 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+)
+
+// scheduleStore persists the schedule and the update queue of the
+// UpdateScheduler in the repo_update_schedule and repo_update_queue tables, so
+// that they survive restarts and are shared by all repo-updater instances.
+//
+// Times are passed in by the caller rather than taken from the database clock
+// so that they are consistent with the times the scheduler computes.
+type scheduleStore struct {
+	*basestore.Store
+}
+
+func newScheduleStore(db database.DB) *scheduleStore {
+	return &scheduleStore{Store: basestore.NewWithHandle(db.Handle())}
+}
+
+// insert adds the given repos to the schedule, due at the given time. Repos
+// that are already in the schedule are left unchanged.
+func (s *scheduleStore) insert(ctx context.Context, ids []api.RepoID, due time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.Exec(ctx, sqlf.Sprintf(insertScheduleQuery, int(minDelay/time.Second), due, pq.Array(ids)))
+}
+
+const insertScheduleQuery = `
+-- source: internal/repos/scheduler_store.go:insert
+INSERT INTO repo_update_schedule (repo_id, interval_seconds, due_at)
+SELECT id, %s::integer, %s::timestamptz FROM repo WHERE id = ANY(%s)
+ON CONFLICT (repo_id) DO NOTHING
+`
+
+// prioritise adds the given repos to the schedule like insert, and moves the
+// repos that are already in the schedule up to the given time if they are due
+// later.
+func (s *scheduleStore) prioritise(ctx context.Context, ids []api.RepoID, due time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.Exec(ctx, sqlf.Sprintf(prioritiseScheduleQuery, int(minDelay/time.Second), due, pq.Array(ids)))
+}
+
+const prioritiseScheduleQuery = `
+-- source: internal/repos/scheduler_store.go:prioritise
+INSERT INTO repo_update_schedule (repo_id, interval_seconds, due_at)
+SELECT id, %s::integer, %s::timestamptz FROM repo WHERE id = ANY(%s)
+ON CONFLICT (repo_id) DO UPDATE
+SET due_at = EXCLUDED.due_at
+WHERE repo_update_schedule.due_at > EXCLUDED.due_at
+`
+
+// remove removes the given repos from the schedule, as well as from the update
+// queue unless they are currently being updated.
+func (s *scheduleStore) remove(ctx context.Context, ids []api.RepoID, now time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.Exec(ctx, sqlf.Sprintf(removeScheduleQuery, pq.Array(ids), pq.Array(ids), now))
+}
+
+const removeScheduleQuery = `
+-- source: internal/repos/scheduler_store.go:remove
+WITH unscheduled AS (
+	DELETE FROM repo_update_schedule WHERE repo_id = ANY(%s)
+)
+DELETE FROM repo_update_queue
+WHERE repo_id = ANY(%s)
+AND (lease_expires_at IS NULL OR lease_expires_at <= %s)
+`
+
+// listRepoIDs returns the IDs of all repos in the schedule.
+func (s *scheduleStore) listRepoIDs(ctx context.Context) ([]api.RepoID, error) {
+	return basestore.NewSliceScanner(basestore.ScanAny[api.RepoID])(s.Query(ctx, sqlf.Sprintf(listScheduledRepoIDsQuery)))
+}
+
+const listScheduledRepoIDsQuery = `
+-- source: internal/repos/scheduler_store.go:listRepoIDs
+SELECT repo_id FROM repo_update_schedule
+`
+
+// get returns the schedule of the given repo, and whether the repo is in the
+// schedule.
+func (s *scheduleStore) get(ctx context.Context, id api.RepoID) (*scheduledRepoUpdate, bool, error) {
+	q := sqlf.Sprintf(scheduledRepoUpdatesQuery, sqlf.Sprintf("s.repo_id = %s", id))
+	return basestore.NewFirstScanner(scanScheduledRepoUpdate)(s.Query(ctx, q))
+}
+
+// list returns the schedule of all repos, ordered by when they are due.
+func (s *scheduleStore) list(ctx context.Context) ([]*scheduledRepoUpdate, error) {
+	q := sqlf.Sprintf(scheduledRepoUpdatesQuery, sqlf.Sprintf("TRUE"))
+	return basestore.NewSliceScanner(scanScheduledRepoUpdate)(s.Query(ctx, q))
+}
+
+const scheduledRepoUpdatesQuery = `
+-- source: internal/repos/scheduler_store.go:list
+SELECT s.repo_id, r.name, s.interval_seconds, s.due_at, s.last_webhook_at
+FROM repo_update_schedule s
+JOIN repo r ON r.id = s.repo_id
+WHERE %s
+ORDER BY s.due_at, s.repo_id
+`
+
+func scanScheduledRepoUpdate(sc dbutil.Scanner) (*scheduledRepoUpdate, error) {
+	var (
+		update          scheduledRepoUpdate
+		intervalSeconds int
+		lastWebhook     sql.NullTime
+	)
+	if err := sc.Scan(&update.Repo.ID, &update.Repo.Name, &intervalSeconds, &update.Due, &lastWebhook); err != nil {
+		return nil, err
+	}
+	update.Interval = time.Duration(intervalSeconds) * time.Second
+	update.LastWebhook = lastWebhook.Time
+	return &update, nil
+}
+
+// updateInterval sets the update interval of a repo in the schedule along with
+// the next time it is due. It does nothing if the repo is not in the schedule.
+func (s *scheduleStore) updateInterval(ctx context.Context, id api.RepoID, interval time.Duration, due time.Time) error {
+	return s.Exec(ctx, sqlf.Sprintf(updateIntervalQuery, int(interval/time.Second), due, id))
+}
+
+const updateIntervalQuery = `
+-- source: internal/repos/scheduler_store.go:updateInterval
+UPDATE repo_update_schedule SET interval_seconds = %s, due_at = %s WHERE repo_id = %s
+`
+
+// recordWebhook records that a push webhook was received for a repo at the
+// given time. It does nothing if the repo is not in the schedule.
+func (s *scheduleStore) recordWebhook(ctx context.Context, id api.RepoID, at time.Time) error {
+	return s.Exec(ctx, sqlf.Sprintf(recordWebhookQuery, at, id))
+}
+
+const recordWebhookQuery = `
+-- source: internal/repos/scheduler_store.go:recordWebhook
+UPDATE repo_update_schedule SET last_webhook_at = %s WHERE repo_id = %s
+`
+
+// enqueueDue enqueues up to limit repos that are due at the given time with
+// low priority, and schedules their next update. Repos that are already queued
+// keep their place in the queue. Repos locked by a concurrent call (i.e. by
+// another repo-updater instance) are skipped. It returns the number of due
+// repos.
+func (s *scheduleStore) enqueueDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	res, err := s.ExecResult(ctx, sqlf.Sprintf(enqueueDueQuery, now, limit, priorityLow, now, now))
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+const enqueueDueQuery = `
+-- source: internal/repos/scheduler_store.go:enqueueDue
+WITH due AS (
+	SELECT repo_id, interval_seconds
+	FROM repo_update_schedule
+	WHERE due_at <= %s
+	ORDER BY due_at
+	LIMIT %s
+	FOR UPDATE SKIP LOCKED
+),
+enqueued AS (
+	INSERT INTO repo_update_queue (repo_id, priority, queued_at)
+	SELECT repo_id, %s::integer, %s::timestamptz FROM due
+	ON CONFLICT (repo_id) DO NOTHING
+)
+UPDATE repo_update_schedule s
+SET due_at = %s::timestamptz + due.interval_seconds * interval '1 second'
+FROM due
+WHERE s.repo_id = due.repo_id
+`
+
+// enqueue adds the given repos to the update queue with the given priority.
+//
+// Repos that are already queued with a lower priority, and are not currently
+// being updated, are moved to the back of the queue of the given priority.
+// Other queued repos are left unchanged.
+func (s *scheduleStore) enqueue(ctx context.Context, ids []api.RepoID, p priority, now time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.Exec(ctx, sqlf.Sprintf(enqueueQuery, p, now, pq.Array(ids)))
+}
+
+const enqueueQuery = `
+-- source: internal/repos/scheduler_store.go:enqueue
+INSERT INTO repo_update_queue (repo_id, priority, queued_at)
+SELECT id, %s::integer, %s::timestamptz FROM repo WHERE id = ANY(%s)
+ON CONFLICT (repo_id) DO UPDATE
+SET priority = EXCLUDED.priority, queued_at = EXCLUDED.queued_at
+WHERE repo_update_queue.priority < EXCLUDED.priority
+AND (repo_update_queue.lease_expires_at IS NULL OR repo_update_queue.lease_expires_at <= EXCLUDED.queued_at)
+`
+
+// acquireNext leases the next repo in the update queue to owner until the
+// given expiry. Repos that are leased to another owner are skipped until their
+// lease expires. The acquired repo must be released when the update finishes
+// (independent of success or failure).
+func (s *scheduleStore) acquireNext(ctx context.Context, owner string, now, expiry time.Time) (configuredRepo, bool, error) {
+	return basestore.NewFirstScanner(scanConfiguredRepo)(s.Query(ctx, sqlf.Sprintf(acquireNextQuery, now, owner, expiry)))
+}
+
+const acquireNextQuery = `
+-- source: internal/repos/scheduler_store.go:acquireNext
+WITH candidate AS (
+	SELECT repo_id
+	FROM repo_update_queue
+	WHERE lease_expires_at IS NULL OR lease_expires_at <= %s
+	ORDER BY priority DESC, queued_at, repo_id
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED
+),
+leased AS (
+	UPDATE repo_update_queue q
+	SET leased_by = %s, lease_expires_at = %s
+	FROM candidate
+	WHERE q.repo_id = candidate.repo_id
+	RETURNING q.repo_id
+)
+SELECT r.id, r.name
+FROM leased
+JOIN repo r ON r.id = leased.repo_id
+`
+
+func scanConfiguredRepo(sc dbutil.Scanner) (repo configuredRepo, err error) {
+	err = sc.Scan(&repo.ID, &repo.Name)
+	return repo, err
+}
+
+// release removes a repo leased to owner from the update queue.
+func (s *scheduleStore) release(ctx context.Context, id api.RepoID, owner string) error {
+	return s.Exec(ctx, sqlf.Sprintf(releaseQuery, id, owner))
+}
+
+const releaseQuery = `
+-- source: internal/repos/scheduler_store.go:release
+DELETE FROM repo_update_queue WHERE repo_id = %s AND leased_by = %s
+`
+
+// listQueue returns all repos in the update queue, in the order in which they
+// are updated. Repos that are currently being updated are sorted last.
+func (s *scheduleStore) listQueue(ctx context.Context, now time.Time) ([]*repoUpdate, error) {
+	return basestore.NewSliceScanner(scanRepoUpdate)(s.Query(ctx, sqlf.Sprintf(listQueueQuery, now)))
+}
+
+const listQueueQuery = `
+-- source: internal/repos/scheduler_store.go:listQueue
+WITH queue AS (
+	SELECT
+		q.repo_id,
+		r.name,
+		q.priority,
+		q.queued_at,
+		COALESCE(q.lease_expires_at > %s, FALSE) AS updating,
+		COALESCE(q.leased_by, '') AS leased_by
+	FROM repo_update_queue q
+	JOIN repo r ON r.id = q.repo_id
+)
+SELECT repo_id, name, priority, queued_at, updating, leased_by
+FROM queue
+ORDER BY updating, priority DESC, queued_at, repo_id
+`
+
+func scanRepoUpdate(sc dbutil.Scanner) (*repoUpdate, error) {
+	var update repoUpdate
+	err := sc.Scan(
+		&update.Repo.ID,
+		&update.Repo.Name,
+		&update.Priority,
+		&update.QueuedAt,
+		&update.Updating,
+		&update.LeasedBy,
+	)
+	return &update, err
+}
+
+// scheduleInfo returns the position of a repo in the schedule and the update
+// queue, if it is in either of them.
+func (s *scheduleStore) scheduleInfo(ctx context.Context, id api.RepoID, now time.Time) (schedule *scheduleState, queue *queueState, err error) {
+	schedule, _, err = basestore.NewFirstScanner(scanScheduleState)(s.Query(ctx, sqlf.Sprintf(scheduleStateQuery, id)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	queue, _, err = basestore.NewFirstScanner(scanQueueState)(s.Query(ctx, sqlf.Sprintf(queueStateQuery, now, id)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return schedule, queue, nil
+}
+
+// scheduleState is the position of a repo in the schedule.
+type scheduleState struct {
+	Index    int
+	Total    int
+	Interval time.Duration
+	Due      time.Time
+}
+
+const scheduleStateQuery = `
+-- source: internal/repos/scheduler_store.go:scheduleInfo
+SELECT
+	(SELECT COUNT(*) FROM repo_update_schedule o WHERE (o.due_at, o.repo_id) < (s.due_at, s.repo_id)),
+	(SELECT COUNT(*) FROM repo_update_schedule),
+	s.interval_seconds,
+	s.due_at
+FROM repo_update_schedule s
+WHERE s.repo_id = %s
+`
+
+func scanScheduleState(sc dbutil.Scanner) (*scheduleState, error) {
+	var (
+		state           scheduleState
+		intervalSeconds int
+	)
+	if err := sc.Scan(&state.Index, &state.Total, &intervalSeconds, &state.Due); err != nil {
+		return nil, err
+	}
+	state.Interval = time.Duration(intervalSeconds) * time.Second
+	return &state, nil
+}
+
+// queueState is the position of a repo in the update queue.
+type queueState struct {
+	Index    int
+	Total    int
+	Updating bool
+	Priority priority
+}
+
+const queueStateQuery = `
+-- source: internal/repos/scheduler_store.go:scheduleInfo
+WITH queue AS (
+	SELECT repo_id, priority, queued_at, COALESCE(lease_expires_at > %s, FALSE) AS updating
+	FROM repo_update_queue
+),
+ranked AS (
+	SELECT
+		repo_id,
+		priority,
+		updating,
+		ROW_NUMBER() OVER (ORDER BY updating, priority DESC, queued_at, repo_id) - 1 AS index,
+		COUNT(*) OVER () AS total
+	FROM queue
+)
+SELECT index, total, updating, priority
+FROM ranked
+WHERE repo_id = %s
+`
+
+func scanQueueState(sc dbutil.Scanner) (*queueState, error) {
+	var state queueState
+	err := sc.Scan(&state.Index, &state.Total, &state.Updating, &state.Priority)
+	return &state, err
+}
@@ -1,32 +1,33 @@
 package repos
 
 import (
-	"container/heap"
 	"context"
-	"reflect"
+	"sort"
 	"testing"
 	"time"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/google/go-cmp/cmp"
+	"github.com/keegancsmith/sqlf"
 
 	"github.com/sourcegraph/log/logtest"
 
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
 	gitserverprotocol "github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/mutablelimiter"
+	"github.com/sourcegraph/sourcegraph/internal/repoupdater/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
 
-var defaultTime = time.Date(2000, 1, 1, 1, 1, 1, 1, time.UTC)
+var defaultTime = time.Date(2000, 1, 1, 1, 1, 1, 0, time.UTC)
 
 func init() {
 	timeNow = nil
 	notify = nil
-	timeAfterFunc = nil
 }
 
 func mockTime(t time.Time) {
@@ -36,8 +37,7 @@ func mockTime(t time.Time) {
 }
 
 type recording struct {
-	notifications       []chan struct{}
-	timeAfterFuncDelays []time.Duration
+	notifications []chan struct{}
 }
 
 func startRecording() (*recording, func()) {
@@ -49,1282 +49,606 @@ func startRecording() (*recording, func()) {
 		r.notifications = append(r.notifications, ch)
 	}
 
-	timeAfterFunc = func(delay time.Duration, f func()) *time.Timer {
-		r.timeAfterFuncDelays = append(r.timeAfterFuncDelays, delay)
-		f()
-		return nil
-	}
-
 	return &r, func() {
 		timeNow = nil
 		notify = nil
-		timeAfterFunc = nil
 	}
 }
 
-func TestUpdateQueue_enqueue(t *testing.T) {
-	a := configuredRepo{ID: 1, Name: "a"}
-	a2 := configuredRepo{ID: 1, Name: "a2"}
-	b := configuredRepo{ID: 2, Name: "b"}
-	c := configuredRepo{ID: 3, Name: "c"}
-	d := configuredRepo{ID: 4, Name: "d"}
-	e := configuredRepo{ID: 5, Name: "e"}
-	db := database.NewMockDB()
-
-	type enqueueCall struct {
-		repo     configuredRepo
-		priority priority
-	}
-	tests := []struct {
-		name                  string
-		calls                 []*enqueueCall
-		acquire               int // acquire n updates before assertions
-		expectedUpdates       []*repoUpdate
-		expectedNotifications int
-	}{
-		{
-			name: "enqueue low priority",
-			calls: []*enqueueCall{
-				{repo: a, priority: priorityLow},
-			},
-			expectedUpdates: []*repoUpdate{
-				{
-					Repo:     a,
-					Priority: priorityLow,
-					Seq:      1,
-				},
-			},
-			expectedNotifications: 1,
-		},
-		{
-			name: "enqueue high priority",
-			calls: []*enqueueCall{
-				{repo: a, priority: priorityHigh},
-			},
-			expectedUpdates: []*repoUpdate{
-				{
-					Repo:     a,
-					Priority: priorityHigh,
-					Seq:      1,
-				},
-			},
-			expectedNotifications: 1,
-		},
-		{
-			name: "enqueue low b then high a",
-			calls: []*enqueueCall{
-				{repo: b, priority: priorityLow},
-				{repo: a, priority: priorityHigh},
-			},
-			expectedUpdates: []*repoUpdate{
-				{
-					Repo:     a,
-					Priority: priorityHigh,
-					Seq:      2,
-				},
-				{
-					Repo:     b,
-					Priority: priorityLow,
-					Seq:      1,
-				},
-			},
-			expectedNotifications: 2,
-		},
-		{
-			name: "enqueue high a then low b",
-			calls: []*enqueueCall{
-				{repo: a, priority: priorityHigh},
-				{repo: b, priority: priorityLow},
-			},
-			expectedUpdates: []*repoUpdate{
-				{
-					Repo:     a,
-					Priority: priorityHigh,
-					Seq:      1,
-				},
-				{
-					Repo:     b,
-					Priority: priorityLow,
-					Seq:      2,
-				},
-			},
-			expectedNotifications: 2,
-		},
-		{
-			name: "enqueue low a then low a",
-			calls: []*enqueueCall{
-				{repo: a, priority: priorityLow},
-				{repo: a, priority: priorityLow},
-			},
-			expectedUpdates: []*repoUpdate{
-				{
-					Repo:     a,
-					Priority: priorityLow,
-					Seq:      1,
-				},
-			},
-			expectedNotifications: 1,
-		},
-		{
-			name: "enqueue high a then low a",
-			calls: []*enqueueCall{
-				{repo: a, priority: priorityHigh},
-				{repo: a, priority: priorityLow},
-			},
-			expectedUpdates: []*repoUpdate{
-				{
-					Repo:     a,
-					Priority: priorityHigh,
-					Seq:      1,
-				},
-			},
-			expectedNotifications: 1,
-		},
-		{
-			name: "enqueue low a then high a",
-			calls: []*enqueueCall{
-				{repo: a, priority: priorityLow},
-				{repo: a, priority: priorityHigh},
-			},
-			expectedUpdates: []*repoUpdate{
-				{
-					Repo:     a,
-					Priority: priorityHigh,
-					Seq:      2,
-				},
-			},
-			expectedNotifications: 2,
-		},
-		{
-			name: "repo is updated if not already updating",
-			calls: []*enqueueCall{
-				{repo: a, priority: priorityHigh},
-				{repo: a2, priority: priorityLow},
-			},
-			expectedUpdates: []*repoUpdate{
-				{
-					Repo:     a2,
-					Priority: priorityHigh,
-					Seq:      1, // Priority remains high
-				},
-			},
-			expectedNotifications: 1,
-		},
-		{
-			name: "repo is NOT updated if already updating",
-			calls: []*enqueueCall{
-				{repo: a, priority: priorityHigh},
-				{repo: a2, priority: priorityLow},
-			},
-			acquire: 1,
-			expectedUpdates: []*repoUpdate{
-				{
-					Repo:     a,
-					Priority: priorityHigh,
-					Updating: true,
-					Seq:      1,
-				},
-			},
-			expectedNotifications: 1,
-		},
-		{
-			name: "heap is fixed when priority is bumped",
-			calls: []*enqueueCall{
-				{repo: c, priority: priorityLow},
-				{repo: d, priority: priorityLow},
-				{repo: a, priority: priorityLow},
-				{repo: e, priority: priorityLow},
-				{repo: b, priority: priorityLow},
-
-				{repo: a, priority: priorityHigh},
-				{repo: b, priority: priorityHigh},
-				{repo: c, priority: priorityHigh},
-				{repo: d, priority: priorityHigh},
-				{repo: e, priority: priorityHigh},
-			},
-			expectedUpdates: []*repoUpdate{
-				{
-					Repo:     a,
-					Priority: priorityHigh,
-					Seq:      6,
-				},
-				{
-					Repo:     b,
-					Priority: priorityHigh,
-					Seq:      7,
-				},
-				{
-					Repo:     c,
-					Priority: priorityHigh,
-					Seq:      8,
-				},
-				{
-					Repo:     d,
-					Priority: priorityHigh,
-					Seq:      9,
-				},
-				{
-					Repo:     e,
-					Priority: priorityHigh,
-					Seq:      10,
-				},
-			},
-			expectedNotifications: 10,
-		},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			r, stop := startRecording()
-			defer stop()
-
-			s := NewUpdateScheduler(logtest.Scoped(t), db)
-
-			for _, call := range test.calls {
-				s.updateQueue.enqueue(call.repo, call.priority)
-				if test.acquire > 0 {
-					s.updateQueue.acquireNext()
-					test.acquire--
-				}
-			}
-
-			verifyQueue(t, s, test.expectedUpdates)
+type mockRandomGenerator struct{}
 
-			// Verify notifications.
-			expectedRecording := &recording{}
-			for i := 0; i < test.expectedNotifications; i++ {
-				expectedRecording.notifications = append(expectedRecording.notifications, s.updateQueue.notifyEnqueue)
-			}
-			if !reflect.DeepEqual(expectedRecording, r) {
-				t.Log(cmp.Diff(expectedRecording, r))
-				t.Fatalf("\nexpected\n%s\ngot\n%s", spew.Sdump(expectedRecording), spew.Sdump(r))
-			}
-		})
-	}
+func (m *mockRandomGenerator) Int63n(n int64) int64 {
+	return n / 2
 }
 
-func TestUpdateQueue_remove(t *testing.T) {
-	a := configuredRepo{ID: 1, Name: "a"}
-	b := configuredRepo{ID: 2, Name: "b"}
-	c := configuredRepo{ID: 3, Name: "c"}
-
-	type removeCall struct {
-		repo     configuredRepo
-		updating bool
+func newTestDB(t *testing.T) database.DB {
+	if testing.Short() {
+		t.Skip()
 	}
 
-	tests := []struct {
-		name         string
-		initialQueue []*repoUpdate
-		removeCalls  []*removeCall
-		finalQueue   []*repoUpdate
-	}{
-		{
-			name: "remove only",
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
-			},
-			removeCalls: []*removeCall{
-				{repo: a},
-			},
-		},
-		{
-			name: "remove front",
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
-				{Repo: b, Seq: 2},
-			},
-			removeCalls: []*removeCall{
-				{repo: a},
-			},
-			finalQueue: []*repoUpdate{
-				{Repo: b, Seq: 2},
-			},
-		},
-		{
-			name: "remove back",
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
-				{Repo: b, Seq: 2},
-			},
-			removeCalls: []*removeCall{
-				{repo: b},
-			},
-			finalQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
-			},
-		},
-		{
-			name: "remove middle",
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
-				{Repo: b, Seq: 2},
-				{Repo: c, Seq: 3},
-			},
-			removeCalls: []*removeCall{
-				{repo: c},
-			},
-			finalQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
-				{Repo: b, Seq: 2},
-			},
-		},
-		{
-			name: "remove not present",
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
-			},
-			removeCalls: []*removeCall{
-				{repo: b},
-			},
-			finalQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
-			},
-		},
-		{
-			name: "remove from empty queue",
-			removeCalls: []*removeCall{
-				{repo: a},
-			},
-		},
-		{
-			name: "remove all",
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
-				{Repo: b, Seq: 2},
-				{Repo: c, Seq: 3},
-			},
-			removeCalls: []*removeCall{
-				{repo: a},
-				{repo: b},
-				{repo: c},
-			},
-		},
-		{
-			name: "remove all reverse",
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
-				{Repo: b, Seq: 2},
-				{Repo: c, Seq: 3},
-			},
-			removeCalls: []*removeCall{
-				{repo: c},
-				{repo: b},
-				{repo: a},
-			},
-		},
-		{
-			name: "don't remove updating",
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1, Updating: true},
-			},
-			removeCalls: []*removeCall{
-				{repo: a, updating: false},
-			},
-			finalQueue: []*repoUpdate{
-				{Repo: a, Seq: 1, Updating: true},
-			},
-		},
-		{
-			name: "don't remove not updating",
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1, Updating: false},
-			},
-			removeCalls: []*removeCall{
-				{repo: a, updating: true},
-			},
-			finalQueue: []*repoUpdate{
-				{Repo: a, Seq: 1, Updating: false},
-			},
-		},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			r, stop := startRecording()
-			defer stop()
-
-			s := NewUpdateScheduler(logtest.Scoped(t), database.NewMockDB())
-			setupInitialQueue(s, test.initialQueue)
-
-			// Perform the removals.
-			for _, call := range test.removeCalls {
-				s.updateQueue.remove(call.repo, call.updating)
-			}
-
-			verifyQueue(t, s, test.finalQueue)
+	logger := logtest.Scoped(t)
+	return database.NewDB(logger, dbtest.NewDB(logger, t))
+}
 
-			// Verify no notifications.
-			expectedRecording := &recording{}
-			if !reflect.DeepEqual(expectedRecording, r) {
-				t.Fatalf("\nexpected\n%s\ngot\n%s", spew.Sdump(expectedRecording), spew.Sdump(r))
-			}
-		})
-	}
+// newTestScheduler returns a scheduler of the repo-updater instance with the
+// given hostname.
+func newTestScheduler(t *testing.T, db database.DB, owner string) *UpdateScheduler {
+	s := NewUpdateScheduler(logtest.Scoped(t), db)
+	s.owner = owner
+	s.randGenerator = &mockRandomGenerator{}
+	return s
 }
 
-func TestUpdateQueue_acquireNext(t *testing.T) {
-	a := configuredRepo{ID: 1, Name: "a"}
-	b := configuredRepo{ID: 2, Name: "b"}
+func createTestRepos(t *testing.T, db database.DB, names ...api.RepoName) []configuredRepo {
+	t.Helper()
 
-	tests := []struct {
-		name           string
-		initialQueue   []*repoUpdate
-		acquireResults []*configuredRepo
-		finalQueue     []*repoUpdate
-	}{
-		{
-			name:           "acquire from empty queue returns nil",
-			acquireResults: []*configuredRepo{nil},
-		},
-		{
-			name: "acquire sets updating to true",
-			initialQueue: []*repoUpdate{
-				{Repo: a, Updating: false, Seq: 1},
-			},
-			acquireResults: []*configuredRepo{&a},
-			finalQueue: []*repoUpdate{
-				{Repo: a, Updating: true, Seq: 1},
-			},
-		},
-		{
-			name: "acquire sends update to back of queue",
-			initialQueue: []*repoUpdate{
-				{Repo: a, Updating: false, Seq: 1},
-				{Repo: b, Updating: false, Seq: 2},
-			},
-			acquireResults: []*configuredRepo{&a},
-			finalQueue: []*repoUpdate{
-				{Repo: b, Updating: false, Seq: 2},
-				{Repo: a, Updating: true, Seq: 1},
-			},
-		},
-		{
-			name: "acquire does not return repos that are already updating",
-			initialQueue: []*repoUpdate{
-				{Repo: a, Updating: true, Seq: 1},
-			},
-			acquireResults: []*configuredRepo{nil},
-			finalQueue: []*repoUpdate{
-				{Repo: a, Updating: true, Seq: 1},
-			},
-		},
+	repos := make([]configuredRepo, 0, len(names))
+	for _, name := range names {
+		r := &types.Repo{Name: name}
+		if err := db.Repos().Create(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+		repos = append(repos, configuredRepo{ID: r.ID, Name: r.Name})
 	}
+	return repos
+}
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			r, stop := startRecording()
-			defer stop()
-
-			s := NewUpdateScheduler(logtest.Scoped(t), database.NewMockDB())
-			setupInitialQueue(s, test.initialQueue)
-
-			// Test aquireNext.
-			for i, expected := range test.acquireResults {
-				actual, ok := s.updateQueue.acquireNext()
-				got := &actual
-				if !ok {
-					got = nil
-				}
-				if !reflect.DeepEqual(expected, got) {
-					t.Fatalf("\nacquireNext expected %d\n%s\ngot\n%s", i, spew.Sdump(expected), spew.Sdump(got))
-				}
-			}
-
-			verifyQueue(t, s, test.finalQueue)
+func setupInitialSchedule(t *testing.T, s *UpdateScheduler, initialSchedule []*scheduledRepoUpdate) {
+	t.Helper()
 
-			// Verify no notifications.
-			expectedRecording := &recording{}
-			if !reflect.DeepEqual(expectedRecording, r) {
-				t.Fatalf("\nexpected\n%s\ngot\n%s", spew.Sdump(expectedRecording), spew.Sdump(r))
-			}
-		})
-	}
-}
+	for _, update := range initialSchedule {
+		var lastWebhook *time.Time
+		if !update.LastWebhook.IsZero() {
+			lastWebhook = &update.LastWebhook
+		}
 
-func setupInitialQueue(s *UpdateScheduler, initialQueue []*repoUpdate) {
-	for _, update := range initialQueue {
-		heap.Push(s.updateQueue, update)
+		q := sqlf.Sprintf(
+			`INSERT INTO repo_update_schedule (repo_id, interval_seconds, due_at, last_webhook_at) VALUES (%s, %s, %s, %s)`,
+			update.Repo.ID, int(update.Interval/time.Second), update.Due, lastWebhook,
+		)
+		if err := s.store.Exec(context.Background(), q); err != nil {
+			t.Fatal(err)
+		}
 	}
 }
 
-func verifyQueue(t *testing.T, s *UpdateScheduler, expected []*repoUpdate) {
+func setupInitialQueue(t *testing.T, s *UpdateScheduler, initialQueue []*repoUpdate) {
 	t.Helper()
 
-	var actualQueue []*repoUpdate
-	for len(s.updateQueue.heap) > 0 {
-		update := heap.Pop(s.updateQueue).(*repoUpdate)
-		update.Index = 0 // this will always be -1, but easier to set it to 0 to avoid boilerplate in test cases
-		actualQueue = append(actualQueue, update)
-	}
+	for _, update := range initialQueue {
+		var leaseExpiresAt *time.Time
+		if update.Updating {
+			expiry := timeNow().Add(time.Hour)
+			leaseExpiresAt = &expiry
+		}
 
-	if !reflect.DeepEqual(expected, actualQueue) {
-		t.Fatalf("\nexpected final queue\n%s\ngot\n%s", spew.Sdump(expected), spew.Sdump(actualQueue))
+		q := sqlf.Sprintf(
+			`INSERT INTO repo_update_queue (repo_id, priority, queued_at, leased_by, lease_expires_at) VALUES (%s, %s, %s, NULLIF(%s, ''), %s)`,
+			update.Repo.ID, update.Priority, update.QueuedAt, update.LeasedBy, leaseExpiresAt,
+		)
+		if err := s.store.Exec(context.Background(), q); err != nil {
+			t.Fatal(err)
+		}
 	}
 }
 
-func Test_updateScheduler_UpdateFromDiff(t *testing.T) {
-	a := configuredRepo{ID: 1, Name: "a"}
-	b := configuredRepo{ID: 2, Name: "b"}
+func verifySchedule(t *testing.T, s *UpdateScheduler, expected []*scheduledRepoUpdate) {
+	t.Helper()
 
-	tests := []struct {
-		name            string
-		initialSchedule []*scheduledRepoUpdate
-		initialQueue    []*repoUpdate
-		diff            Diff
-		finalSchedule   []*scheduledRepoUpdate
-		finalQueue      []*repoUpdate
-	}{
-		{
-			name: "diff with deleted repos",
-			initialSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: minDelay, Due: defaultTime.Add(minDelay)},
-			},
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1, Updating: false},
-			},
-			diff: Diff{
-				Deleted: []*types.Repo{
-					{ID: a.ID, Name: a.Name},
-				},
-			},
-		},
-		{
-			name: "diff with add and modified repos",
-			diff: Diff{
-				Added: []*types.Repo{
-					{
-						ID:   a.ID,
-						Name: a.Name,
-					},
-				},
-				Modified: []*types.Repo{
-					{
-						ID:   b.ID,
-						Name: b.Name,
-					},
-				},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: minDelay, Due: defaultTime.Add(minDelay)},
-				{Repo: b, Interval: minDelay, Due: defaultTime.Add(minDelay)},
-			},
-			finalQueue: []*repoUpdate{
-				{Repo: a, Seq: 1, Updating: false},
-				{Repo: b, Seq: 2, Updating: false},
-			},
-		},
-		{
-			name: "diff with unmodified but partially deleted repos",
-			initialSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: minDelay, Due: defaultTime.Add(minDelay)},
-			},
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1, Updating: false},
-			},
-			diff: Diff{
-				Unmodified: []*types.Repo{
-					{
-						ID:        a.ID,
-						Name:      a.Name,
-						DeletedAt: defaultTime,
-					},
-					{
-						ID:   b.ID,
-						Name: b.Name,
-					},
-				},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{Repo: b, Interval: minDelay, Due: defaultTime.Add(minDelay)},
-			},
-		},
+	actual, err := s.store.list(context.Background())
+	if err != nil {
+		t.Fatal(err)
 	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			// The recording is not important for testing this method, but we want to mock and clean up timers.
-			_, stop := startRecording()
-			defer stop()
-
-			s := NewUpdateScheduler(logtest.Scoped(t), database.NewMockDB())
-			setupInitialSchedule(s, test.initialSchedule)
-			setupInitialQueue(s, test.initialQueue)
-
-			s.UpdateFromDiff(test.diff)
-
-			verifySchedule(t, s, test.finalSchedule)
-			verifyQueue(t, s, test.finalQueue)
-		})
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected schedule (-want +got):\n%s", diff)
 	}
 }
 
-func TestSchedule_upsert(t *testing.T) {
-	a := configuredRepo{ID: 1, Name: "a"}
-	a2 := configuredRepo{ID: 1, Name: "a2"}
-	b := configuredRepo{ID: 2, Name: "b"}
-
-	type upsertCall struct {
-		time time.Time
-		repo configuredRepo
-	}
+func verifyQueue(t *testing.T, s *UpdateScheduler, expected []*repoUpdate) {
+	t.Helper()
 
-	tests := []struct {
-		name                string
-		initialSchedule     []*scheduledRepoUpdate
-		upsertCalls         []*upsertCall
-		finalSchedule       []*scheduledRepoUpdate
-		timeAfterFuncDelays []time.Duration
-		wakeupNotifications int
-	}{
-		{
-			name: "upsert empty schedule",
-			upsertCalls: []*upsertCall{
-				{repo: a, time: defaultTime},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{
-					Interval: minDelay,
-					Due:      defaultTime.Add(minDelay),
-					Repo:     a,
-				},
-			},
-			timeAfterFuncDelays: []time.Duration{minDelay},
-			wakeupNotifications: 1,
-		},
-		{
-			name: "upsert duplicate is no-op",
-			initialSchedule: []*scheduledRepoUpdate{
-				{
-					Interval: minDelay,
-					Due:      defaultTime,
-					Repo:     a,
-				},
-			},
-			upsertCalls: []*upsertCall{
-				{repo: a, time: defaultTime.Add(time.Minute)},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{
-					Interval: minDelay,
-					Due:      defaultTime,
-					Repo:     a,
-				},
-			},
-		},
-		{
-			name: "existing update repo is updated",
-			initialSchedule: []*scheduledRepoUpdate{
-				{
-					Interval: minDelay,
-					Due:      defaultTime,
-					Repo:     a,
-				},
-			},
-			upsertCalls: []*upsertCall{
-				{repo: a2, time: defaultTime.Add(time.Minute)},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{
-					Interval: minDelay,
-					Due:      defaultTime,
-					Repo:     a2,
-				},
-			},
-		},
-		{
-			name: "upsert later",
-			initialSchedule: []*scheduledRepoUpdate{
-				{
-					Interval: minDelay,
-					Due:      defaultTime.Add(30 * time.Second),
-					Repo:     a,
-				},
-			},
-			upsertCalls: []*upsertCall{
-				{repo: b, time: defaultTime.Add(time.Second)},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{
-					Interval: minDelay,
-					Due:      defaultTime.Add(30 * time.Second),
-					Repo:     a,
-				},
-				{
-					Interval: minDelay,
-					Due:      defaultTime.Add(time.Second + minDelay),
-					Repo:     b,
-				},
-			},
-			timeAfterFuncDelays: []time.Duration{29 * time.Second},
-			wakeupNotifications: 1,
-		},
-		{
-			name: "upsert before",
-			initialSchedule: []*scheduledRepoUpdate{
-				{
-					Interval: minDelay,
-					Due:      defaultTime.Add(time.Minute),
-					Repo:     a,
-				},
-			},
-			upsertCalls: []*upsertCall{
-				{repo: b, time: defaultTime.Add(time.Second)},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{
-					Interval: minDelay,
-					Due:      defaultTime.Add(time.Second + minDelay),
-					Repo:     b,
-				},
-				{
-					Interval: minDelay,
-					Due:      defaultTime.Add(time.Minute),
-					Repo:     a,
-				},
-			},
-			timeAfterFuncDelays: []time.Duration{minDelay},
-			wakeupNotifications: 1,
-		},
+	actual, err := s.store.listQueue(context.Background(), timeNow())
+	if err != nil {
+		t.Fatal(err)
 	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			r, stop := startRecording()
-			defer stop()
-
-			s := NewUpdateScheduler(logtest.Scoped(t), database.NewMockDB())
-			setupInitialSchedule(s, test.initialSchedule)
-
-			for _, call := range test.upsertCalls {
-				mockTime(call.time)
-				s.schedule.upsert(call.repo)
-			}
-
-			verifySchedule(t, s, test.finalSchedule)
-			verifyScheduleRecording(t, s, test.timeAfterFuncDelays, test.wakeupNotifications, r)
-		})
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected queue (-want +got):\n%s", diff)
 	}
 }
 
-func TestUpdateQueue_PrioritiseUncloned(t *testing.T) {
-	cloned1 := configuredRepo{ID: 1, Name: "cloned1"}
-	cloned2 := configuredRepo{ID: 2, Name: "CLONED2"}
-	notcloned := configuredRepo{ID: 3, Name: "notcloned"}
-
-	_, stop := startRecording()
-	defer stop()
-
-	s := NewUpdateScheduler(logtest.Scoped(t), database.NewMockDB())
+func verifyNotifications(t *testing.T, expected []chan struct{}, r *recording) {
+	t.Helper()
 
-	assertFront := func(name api.RepoName) {
-		t.Helper()
-		front := s.schedule.heap[0].Repo.Name
-		if front != name {
-			t.Fatalf("front of schedule is %q, want %q", front, name)
-		}
+	if len(expected) != len(r.notifications) {
+		t.Fatalf("expected %d notifications, got %d", len(expected), len(r.notifications))
 	}
-
-	// add everything to the scheduler for the distant future.
-	mockTime(defaultTime.Add(time.Hour))
-	for _, repo := range []configuredRepo{cloned1, cloned2, notcloned} {
-		s.schedule.upsert(repo)
+	for i := range expected {
+		if expected[i] != r.notifications[i] {
+			t.Fatalf("unexpected channel for notification %d", i)
+		}
 	}
-
-	assertFront(cloned1.Name)
-
-	// Reset the time to now and do prioritiseUncloned. We then verify that notcloned
-	// is now at the front of the queue.
-	mockTime(defaultTime)
-	s.schedule.prioritiseUncloned([]types.MinimalRepo{
-		{
-			ID:   3,
-			Name: "notcloned",
-		},
-	})
-
-	assertFront(notcloned.Name)
 }
 
-func TestScheduleInsertNew(t *testing.T) {
-	repo1 := types.MinimalRepo{ID: 1, Name: "repo1"}
-	repo2 := types.MinimalRepo{ID: 2, Name: "repo2"}
-
-	_, stop := startRecording()
+func TestUpdateScheduler_UpdateOnce(t *testing.T) {
+	r, stop := startRecording()
 	defer stop()
 
-	s := NewUpdateScheduler(logtest.Scoped(t), database.NewMockDB())
+	ctx := context.Background()
+	db := newTestDB(t)
+	s := newTestScheduler(t, db, "repo-updater-0")
+	repos := createTestRepos(t, db, "a", "b", "c")
+	a, b, c := repos[0], repos[1], repos[2]
 
-	assertFront := func(name api.RepoName) {
+	enqueue := func(at time.Time, repo configuredRepo, p priority) {
 		t.Helper()
-		front := s.schedule.heap[0].Repo.Name
-		if front != name {
-			t.Fatalf("front of schedule is %q, want %q", front, name)
+		mockTime(at)
+		if err := s.enqueue(ctx, []api.RepoID{repo.ID}, p); err != nil {
+			t.Fatal(err)
 		}
 	}
 
-	// add everything to the scheduler for the distant future.
-	mockTime(defaultTime.Add(time.Hour))
-	s.schedule.insertNew([]types.MinimalRepo{repo1})
-	assertFront(repo1.Name)
-
-	// Add including old
-	mockTime(defaultTime)
-	s.schedule.insertNew([]types.MinimalRepo{repo1, repo2})
-	assertFront(repo2.Name)
-}
-
-type mockRandomGenerator struct{}
+	enqueue(defaultTime, a, priorityLow)
+	enqueue(defaultTime.Add(1*time.Second), b, priorityLow)
 
-func (m *mockRandomGenerator) Int63n(n int64) int64 {
-	return n / 2
-}
+	mockTime(defaultTime.Add(2 * time.Second))
+	if err := s.UpdateOnce(ctx, c.ID, c.Name); err != nil {
+		t.Fatal(err)
+	}
 
-func TestSchedule_updateInterval(t *testing.T) {
-	a := configuredRepo{ID: 1, Name: "a"}
-	b := configuredRepo{ID: 2, Name: "b"}
-	c := configuredRepo{ID: 3, Name: "c"}
-	d := configuredRepo{ID: 4, Name: "d"}
-	e := configuredRepo{ID: 5, Name: "e"}
+	// Enqueuing a repo with a priority that is not higher keeps its place in the queue.
+	enqueue(defaultTime.Add(3*time.Second), a, priorityLow)
 
-	type updateCall struct {
-		time     time.Time
-		repo     configuredRepo
-		interval time.Duration
+	// Enqueuing a repo with a higher priority moves it after the queued repos of that priority.
+	mockTime(defaultTime.Add(4 * time.Second))
+	if err := s.UpdateOnce(ctx, b.ID, b.Name); err != nil {
+		t.Fatal(err)
 	}
 
-	tests := []struct {
-		name                string
-		initialSchedule     []*scheduledRepoUpdate
-		updateCalls         []*updateCall
-		finalSchedule       []*scheduledRepoUpdate
-		timeAfterFuncDelays []time.Duration
-		wakeupNotifications int
-	}{
-		{
-			name: "update has no effect if repo isn't in schedule",
-			updateCalls: []*updateCall{
-				{repo: a, time: defaultTime},
-			},
-		},
-		{
-			name: "update earlier",
-			initialSchedule: []*scheduledRepoUpdate{
-				{
-					Repo:     a,
-					Interval: minDelay,
-					Due:      defaultTime.Add(time.Hour),
-				},
-			},
-			updateCalls: []*updateCall{
-				{
-					repo:     a,
-					time:     defaultTime.Add(time.Second),
-					interval: 123 * time.Second,
-				},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{
-					Repo:     a,
-					Interval: 123 * time.Second,
-					Due:      defaultTime.Add(124 * time.Second),
-				},
-			},
-			timeAfterFuncDelays: []time.Duration{123 * time.Second},
-			wakeupNotifications: 1,
-		},
-		{
-			name: "minimum interval",
-			initialSchedule: []*scheduledRepoUpdate{
-				{
-					Repo:     a,
-					Interval: maxDelay,
-					Due:      defaultTime.Add(maxDelay),
-				},
-			},
-			updateCalls: []*updateCall{
-				{
-					repo:     a,
-					time:     defaultTime,
-					interval: time.Second,
-				},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{
-					Repo:     a,
-					Interval: minDelay,
-					Due:      defaultTime.Add(minDelay),
-				},
-			},
-			timeAfterFuncDelays: []time.Duration{minDelay},
-			wakeupNotifications: 1,
-		},
-		{
-			name: "maximum interval",
-			initialSchedule: []*scheduledRepoUpdate{
-				{
-					Repo:     a,
-					Interval: minDelay,
-					Due:      defaultTime.Add(minDelay),
-				},
-			},
-			updateCalls: []*updateCall{
-				{
-					repo:     a,
-					time:     defaultTime,
-					interval: 365 * 25 * time.Hour,
-				},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{
-					Repo:     a,
-					Interval: maxDelay,
-					Due:      defaultTime.Add(maxDelay),
-				},
-			},
-			timeAfterFuncDelays: []time.Duration{maxDelay},
-			wakeupNotifications: 1,
-		},
-		{
-			name: "update later",
-			initialSchedule: []*scheduledRepoUpdate{
-				{
-					Repo:     a,
-					Interval: minDelay,
-					Due:      defaultTime.Add(time.Hour),
-				},
-			},
-			updateCalls: []*updateCall{
-				{
-					repo:     a,
-					time:     defaultTime.Add(time.Second),
-					interval: 123 * time.Minute,
-				},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{
-					Repo:     a,
-					Interval: 123 * time.Minute,
-					Due:      defaultTime.Add(time.Second + 123*time.Minute),
-				},
-			},
-			timeAfterFuncDelays: []time.Duration{123 * time.Minute},
-			wakeupNotifications: 1,
-		},
-		{
-			name: "heap reorders correctly",
-			initialSchedule: []*scheduledRepoUpdate{
-				{Repo: c, Interval: minDelay, Due: defaultTime.Add(1 * time.Minute)},
-				{Repo: d, Interval: minDelay, Due: defaultTime.Add(2 * time.Minute)},
-				{Repo: a, Interval: minDelay, Due: defaultTime.Add(3 * time.Minute)},
-				{Repo: e, Interval: minDelay, Due: defaultTime.Add(4 * time.Minute)},
-				{Repo: b, Interval: minDelay, Due: defaultTime.Add(5 * time.Minute)},
-			},
-			updateCalls: []*updateCall{
-				{repo: a, time: defaultTime, interval: 1 * time.Minute},
-				{repo: b, time: defaultTime, interval: 2 * time.Minute},
-				{repo: c, time: defaultTime, interval: 3 * time.Minute},
-				{repo: d, time: defaultTime, interval: 4 * time.Minute},
-				{repo: e, time: defaultTime, interval: 5 * time.Minute},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: 1 * time.Minute, Due: defaultTime.Add(1 * time.Minute)},
-				{Repo: b, Interval: 2 * time.Minute, Due: defaultTime.Add(2 * time.Minute)},
-				{Repo: c, Interval: 3 * time.Minute, Due: defaultTime.Add(3 * time.Minute)},
-				{Repo: d, Interval: 4 * time.Minute, Due: defaultTime.Add(4 * time.Minute)},
-				{Repo: e, Interval: 5 * time.Minute, Due: defaultTime.Add(5 * time.Minute)},
-			},
-			timeAfterFuncDelays: []time.Duration{time.Minute, time.Minute, time.Minute, time.Minute, time.Minute},
-			wakeupNotifications: 5,
-		},
-	}
+	verifyQueue(t, s, []*repoUpdate{
+		{Repo: c, Priority: priorityHigh, QueuedAt: defaultTime.Add(2 * time.Second)},
+		{Repo: b, Priority: priorityHigh, QueuedAt: defaultTime.Add(4 * time.Second)},
+		{Repo: a, Priority: priorityLow, QueuedAt: defaultTime},
+	})
+	verifyNotifications(t, []chan struct{}{
+		s.notifyEnqueue, s.notifyEnqueue, s.notifyEnqueue, s.notifyEnqueue, s.notifyEnqueue,
+	}, r)
+}
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			r, stop := startRecording()
-			defer stop()
+func TestUpdateScheduler_UpdateOnceWhileUpdating(t *testing.T) {
+	_, stop := startRecording()
+	defer stop()
 
-			s := NewUpdateScheduler(logtest.Scoped(t), database.NewMockDB())
-			setupInitialSchedule(s, test.initialSchedule)
-			s.schedule.randGenerator = &mockRandomGenerator{}
+	ctx := context.Background()
+	db := newTestDB(t)
+	s := newTestScheduler(t, db, "repo-updater-0")
+	a := createTestRepos(t, db, "a")[0]
 
-			for _, call := range test.updateCalls {
-				mockTime(call.time)
-				s.schedule.updateInterval(call.repo, call.interval)
-			}
+	setupInitialQueue(t, s, []*repoUpdate{
+		{Repo: a, Priority: priorityLow, QueuedAt: defaultTime, Updating: true, LeasedBy: "repo-updater-1"},
+	})
 
-			verifySchedule(t, s, test.finalSchedule)
-			verifyScheduleRecording(t, s, test.timeAfterFuncDelays, test.wakeupNotifications, r)
-		})
+	// Repos that are being updated are not updated again.
+	if err := s.UpdateOnce(ctx, a.ID, a.Name); err != nil {
+		t.Fatal(err)
 	}
+
+	verifyQueue(t, s, []*repoUpdate{
+		{Repo: a, Priority: priorityLow, QueuedAt: defaultTime, Updating: true, LeasedBy: "repo-updater-1"},
+	})
 }
 
-func TestSchedule_remove(t *testing.T) {
-	a := configuredRepo{ID: 1, Name: "a"}
-	b := configuredRepo{ID: 2, Name: "b"}
-	c := configuredRepo{ID: 3, Name: "c"}
+func TestScheduleStore_acquireNext(t *testing.T) {
+	_, stop := startRecording()
+	defer stop()
+
+	ctx := context.Background()
+	db := newTestDB(t)
+	s0 := newTestScheduler(t, db, "repo-updater-0")
+	s1 := newTestScheduler(t, db, "repo-updater-1")
+	repos := createTestRepos(t, db, "a", "b")
+	a, b := repos[0], repos[1]
+
+	setupInitialQueue(t, s0, []*repoUpdate{
+		{Repo: a, Priority: priorityLow, QueuedAt: defaultTime},
+		{Repo: b, Priority: priorityHigh, QueuedAt: defaultTime.Add(time.Second)},
+	})
+
+	expiry := defaultTime.Add(time.Hour)
+	acquireNext := func(s *UpdateScheduler) (configuredRepo, bool) {
+		t.Helper()
+		repo, ok, err := s.store.acquireNext(ctx, s.owner, timeNow(), expiry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo, ok
+	}
+
+	// Each instance acquires a different repo, in queue order.
+	if repo, ok := acquireNext(s0); !ok || repo != b {
+		t.Fatalf("expected repo-updater-0 to acquire %v, got %v", b, repo)
+	}
+	if repo, ok := acquireNext(s1); !ok || repo != a {
+		t.Fatalf("expected repo-updater-1 to acquire %v, got %v", a, repo)
+	}
+	if repo, ok := acquireNext(s0); ok {
+		t.Fatalf("expected no repo to acquire, got %v", repo)
+	}
+
+	verifyQueue(t, s0, []*repoUpdate{
+		{Repo: b, Priority: priorityHigh, QueuedAt: defaultTime.Add(time.Second), Updating: true, LeasedBy: "repo-updater-0"},
+		{Repo: a, Priority: priorityLow, QueuedAt: defaultTime, Updating: true, LeasedBy: "repo-updater-1"},
+	})
+
+	// Repos can only be released by the instance that acquired them.
+	if err := s1.store.release(ctx, b.ID, s1.owner); err != nil {
+		t.Fatal(err)
+	}
+	if err := s0.store.release(ctx, b.ID, s0.owner); err != nil {
+		t.Fatal(err)
+	}
 
-	type removeCall struct {
-		time time.Time
-		repo configuredRepo
+	// Repos are acquired by another instance once their lease expires.
+	mockTime(expiry)
+	expiry = expiry.Add(time.Hour)
+	if repo, ok := acquireNext(s0); !ok || repo != a {
+		t.Fatalf("expected repo-updater-0 to acquire %v, got %v", a, repo)
 	}
+	if err := s1.store.release(ctx, a.ID, s1.owner); err != nil {
+		t.Fatal(err)
+	}
+
+	verifyQueue(t, s0, []*repoUpdate{
+		{Repo: a, Priority: priorityLow, QueuedAt: defaultTime, Updating: true, LeasedBy: "repo-updater-0"},
+	})
+}
 
+func Test_updateScheduler_UpdateFromDiff(t *testing.T) {
 	tests := []struct {
-		name                string
-		initialSchedule     []*scheduledRepoUpdate
-		removeCalls         []*removeCall
-		finalSchedule       []*scheduledRepoUpdate
-		timeAfterFuncDelays []time.Duration
-		wakeupNotifications int
+		name            string
+		initialSchedule func(a, b configuredRepo) []*scheduledRepoUpdate
+		initialQueue    func(a, b configuredRepo) []*repoUpdate
+		diff            func(a, b configuredRepo) Diff
+		finalSchedule   func(a, b configuredRepo) []*scheduledRepoUpdate
+		finalQueue      func(a, b configuredRepo) []*repoUpdate
 	}{
 		{
-			name: "remove on empty schedule",
-			removeCalls: []*removeCall{
-				{repo: a, time: defaultTime},
-			},
-		},
-		{
-			name: "remove has no effect if repo isn't in schedule",
-			initialSchedule: []*scheduledRepoUpdate{
-				{Repo: a},
+			name: "diff with deleted repos",
+			initialSchedule: func(a, b configuredRepo) []*scheduledRepoUpdate {
+				return []*scheduledRepoUpdate{
+					{Repo: a, Interval: minDelay, Due: defaultTime.Add(minDelay)},
+				}
 			},
-			removeCalls: []*removeCall{
-				{repo: b},
+			initialQueue: func(a, b configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: a, QueuedAt: defaultTime},
+				}
 			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{Repo: a},
+			diff: func(a, b configuredRepo) Diff {
+				return Diff{
+					Deleted: []*types.Repo{
+						{ID: a.ID, Name: a.Name},
+					},
+				}
 			},
 		},
 		{
-			name: "remove last scheduled doesn't reschedule timer",
-			initialSchedule: []*scheduledRepoUpdate{
-				{Repo: a},
+			name: "diff with deleted repos that are updating",
+			initialSchedule: func(a, b configuredRepo) []*scheduledRepoUpdate {
+				return []*scheduledRepoUpdate{
+					{Repo: a, Interval: minDelay, Due: defaultTime.Add(minDelay)},
+				}
+			},
+			initialQueue: func(a, b configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: a, QueuedAt: defaultTime, Updating: true, LeasedBy: "repo-updater-0"},
+				}
+			},
+			diff: func(a, b configuredRepo) Diff {
+				return Diff{
+					Deleted: []*types.Repo{
+						{ID: a.ID, Name: a.Name},
+					},
+				}
 			},
-			removeCalls: []*removeCall{
-				{repo: a},
+			finalQueue: func(a, b configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: a, QueuedAt: defaultTime, Updating: true, LeasedBy: "repo-updater-0"},
+				}
 			},
 		},
 		{
-			name: "remove next reschedules timer",
-			initialSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: minDelay, Due: defaultTime},
-				{Repo: b, Interval: minDelay, Due: defaultTime.Add(minDelay)},
-				{Repo: c, Interval: maxDelay, Due: defaultTime.Add(maxDelay)},
+			name: "diff with add and modified repos",
+			diff: func(a, b configuredRepo) Diff {
+				return Diff{
+					Added: []*types.Repo{
+						{ID: a.ID, Name: a.Name},
+					},
+					Modified: []*types.Repo{
+						{ID: b.ID, Name: b.Name},
+					},
+				}
 			},
-			removeCalls: []*removeCall{
-				{repo: a, time: defaultTime},
+			finalSchedule: func(a, b configuredRepo) []*scheduledRepoUpdate {
+				return []*scheduledRepoUpdate{
+					{Repo: a, Interval: minDelay, Due: defaultTime.Add(minDelay)},
+					{Repo: b, Interval: minDelay, Due: defaultTime.Add(minDelay)},
+				}
 			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{Repo: b, Interval: minDelay, Due: defaultTime.Add(minDelay)},
-				{Repo: c, Interval: maxDelay, Due: defaultTime.Add(maxDelay)},
+			finalQueue: func(a, b configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: a, QueuedAt: defaultTime},
+					{Repo: b, QueuedAt: defaultTime},
+				}
 			},
-			timeAfterFuncDelays: []time.Duration{minDelay},
-			wakeupNotifications: 1,
 		},
 		{
-			name: "remove not-next doesn't reschedule timer",
-			initialSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: minDelay, Due: defaultTime},
-				{Repo: b, Interval: minDelay, Due: defaultTime.Add(minDelay)},
-				{Repo: c, Interval: maxDelay, Due: defaultTime.Add(maxDelay)},
+			name: "diff with unmodified but partially deleted repos",
+			initialSchedule: func(a, b configuredRepo) []*scheduledRepoUpdate {
+				return []*scheduledRepoUpdate{
+					{Repo: a, Interval: minDelay, Due: defaultTime.Add(minDelay)},
+				}
+			},
+			initialQueue: func(a, b configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: a, QueuedAt: defaultTime},
+				}
 			},
-			removeCalls: []*removeCall{
-				{repo: b, time: defaultTime},
-				{repo: c, time: defaultTime},
+			diff: func(a, b configuredRepo) Diff {
+				return Diff{
+					Unmodified: []*types.Repo{
+						{ID: a.ID, Name: a.Name, DeletedAt: defaultTime},
+						{ID: b.ID, Name: b.Name},
+					},
+				}
 			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: minDelay, Due: defaultTime},
+			finalSchedule: func(a, b configuredRepo) []*scheduledRepoUpdate {
+				return []*scheduledRepoUpdate{
+					{Repo: b, Interval: minDelay, Due: defaultTime.Add(minDelay)},
+				}
 			},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			r, stop := startRecording()
+			_, stop := startRecording()
 			defer stop()
 
-			s := NewUpdateScheduler(logtest.Scoped(t), database.NewMockDB())
-			setupInitialSchedule(s, test.initialSchedule)
+			db := newTestDB(t)
+			s := newTestScheduler(t, db, "repo-updater-0")
+			repos := createTestRepos(t, db, "a", "b")
+			a, b := repos[0], repos[1]
+
+			if test.initialSchedule != nil {
+				setupInitialSchedule(t, s, test.initialSchedule(a, b))
+			}
+			if test.initialQueue != nil {
+				setupInitialQueue(t, s, test.initialQueue(a, b))
+			}
 
-			for _, call := range test.removeCalls {
-				mockTime(call.time)
-				s.schedule.remove(call.repo)
+			if err := s.UpdateFromDiff(context.Background(), test.diff(a, b)); err != nil {
+				t.Fatal(err)
 			}
 
-			verifySchedule(t, s, test.finalSchedule)
-			verifyScheduleRecording(t, s, test.timeAfterFuncDelays, test.wakeupNotifications, r)
+			var (
+				finalSchedule []*scheduledRepoUpdate
+				finalQueue    []*repoUpdate
+			)
+			if test.finalSchedule != nil {
+				finalSchedule = test.finalSchedule(a, b)
+			}
+			if test.finalQueue != nil {
+				finalQueue = test.finalQueue(a, b)
+			}
+			verifySchedule(t, s, finalSchedule)
+			verifyQueue(t, s, finalQueue)
 		})
 	}
 }
 
-func setupInitialSchedule(s *UpdateScheduler, initialSchedule []*scheduledRepoUpdate) {
-	for _, update := range initialSchedule {
-		heap.Push(s.schedule, update)
+func TestUpdateScheduler_PrioritiseUncloned(t *testing.T) {
+	_, stop := startRecording()
+	defer stop()
+
+	db := newTestDB(t)
+	s := newTestScheduler(t, db, "repo-updater-0")
+	repos := createTestRepos(t, db, "a", "b", "c")
+	a, b, c := repos[0], repos[1], repos[2]
+
+	setupInitialSchedule(t, s, []*scheduledRepoUpdate{
+		{Repo: a, Interval: time.Hour, Due: defaultTime.Add(time.Hour)},
+		{Repo: b, Interval: time.Hour, Due: defaultTime.Add(10 * time.Second)},
+	})
+
+	err := s.PrioritiseUncloned(context.Background(), []types.MinimalRepo{
+		{ID: a.ID, Name: a.Name},
+		{ID: b.ID, Name: b.Name},
+		{ID: c.ID, Name: c.Name},
+	})
+	if err != nil {
+		t.Fatal(err)
 	}
+
+	verifySchedule(t, s, []*scheduledRepoUpdate{
+		{Repo: b, Interval: time.Hour, Due: defaultTime.Add(10 * time.Second)},
+		{Repo: a, Interval: time.Hour, Due: defaultTime.Add(minDelay)},
+		{Repo: c, Interval: minDelay, Due: defaultTime.Add(minDelay)},
+	})
 }
 
-func verifySchedule(t *testing.T, s *UpdateScheduler, expected []*scheduledRepoUpdate) {
-	t.Helper()
+func TestUpdateScheduler_EnsureScheduled(t *testing.T) {
+	_, stop := startRecording()
+	defer stop()
+
+	ctx := context.Background()
+	db := newTestDB(t)
+	s := newTestScheduler(t, db, "repo-updater-0")
+	repos := createTestRepos(t, db, "a", "b")
+	a, b := repos[0], repos[1]
+
+	setupInitialSchedule(t, s, []*scheduledRepoUpdate{
+		{Repo: a, Interval: time.Hour, Due: defaultTime.Add(time.Hour)},
+	})
 
-	var actualSchedule []*scheduledRepoUpdate
-	for len(s.schedule.heap) > 0 {
-		update := heap.Pop(s.schedule).(*scheduledRepoUpdate)
-		update.Index = 0 // this will always be -1, but easier to set it to 0 to avoid boilerplate in test cases
-		actualSchedule = append(actualSchedule, update)
+	err := s.EnsureScheduled(ctx, []types.MinimalRepo{
+		{ID: a.ID, Name: a.Name},
+		{ID: b.ID, Name: b.Name},
+	})
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	if !reflect.DeepEqual(expected, actualSchedule) {
-		t.Fatalf("\nexpected final schedule\n%s\ngot\n%s", spew.Sdump(expected), spew.Sdump(actualSchedule))
+	verifySchedule(t, s, []*scheduledRepoUpdate{
+		{Repo: b, Interval: minDelay, Due: defaultTime.Add(minDelay)},
+		{Repo: a, Interval: time.Hour, Due: defaultTime.Add(time.Hour)},
+	})
+
+	ids, err := s.ListRepoIDs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if diff := cmp.Diff([]api.RepoID{a.ID, b.ID}, ids); diff != "" {
+		t.Fatalf("unexpected repo IDs (-want +got):\n%s", diff)
 	}
 }
 
-func verifyScheduleRecording(t *testing.T, s *UpdateScheduler, timeAfterFuncDelays []time.Duration, wakeupNotifications int, r *recording) {
-	t.Helper()
+func TestUpdateScheduler_UpdateFromWebhook(t *testing.T) {
+	r, stop := startRecording()
+	defer stop()
+
+	ctx := context.Background()
+	db := newTestDB(t)
+	s := newTestScheduler(t, db, "repo-updater-0")
+	repos := createTestRepos(t, db, "a", "b")
+	a, b := repos[0], repos[1]
 
-	if !reflect.DeepEqual(timeAfterFuncDelays, r.timeAfterFuncDelays) {
-		t.Fatalf("\nexpected timeAfterFuncDelays\n%s\ngot\n%s", spew.Sdump(timeAfterFuncDelays), spew.Sdump(r.timeAfterFuncDelays))
+	setupInitialSchedule(t, s, []*scheduledRepoUpdate{
+		{Repo: a, Interval: time.Hour, Due: defaultTime.Add(time.Hour)},
+	})
+
+	mockTime(defaultTime.Add(time.Minute))
+	if err := s.UpdateFromWebhook(ctx, a.ID, a.Name); err != nil {
+		t.Fatal(err)
+	}
+	// Repos that are not in the schedule are still updated.
+	if err := s.UpdateFromWebhook(ctx, b.ID, b.Name); err != nil {
+		t.Fatal(err)
 	}
 
-	if l := len(r.notifications); l != wakeupNotifications {
-		t.Fatalf("expected %d notifications; got %d", wakeupNotifications, l)
+	update, ok, err := s.store.get(ctx, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || !update.receivesWebhooks() {
+		t.Error("expected repo a to receive webhooks")
 	}
 
-	for _, n := range r.notifications {
-		if n != s.schedule.wakeup {
-			t.Fatalf("received notification on wrong channel")
+	verifyQueue(t, s, []*repoUpdate{
+		{Repo: a, Priority: priorityHigh, QueuedAt: defaultTime.Add(time.Minute)},
+		{Repo: b, Priority: priorityHigh, QueuedAt: defaultTime.Add(time.Minute)},
+	})
+	verifySchedule(t, s, []*scheduledRepoUpdate{
+		{Repo: a, Interval: time.Hour, Due: defaultTime.Add(time.Hour), LastWebhook: defaultTime.Add(time.Minute)},
+	})
+	verifyNotifications(t, []chan struct{}{s.notifyEnqueue, s.notifyEnqueue}, r)
+}
+
+func TestScheduledRepoUpdate_receivesWebhooks(t *testing.T) {
+	_, stop := startRecording()
+	defer stop()
+
+	for _, tc := range []struct {
+		lastWebhook time.Time
+		want        bool
+	}{
+		{lastWebhook: time.Time{}, want: false},
+		{lastWebhook: defaultTime.Add(-time.Hour), want: true},
+		{lastWebhook: defaultTime.Add(-webhookExpiry), want: false},
+	} {
+		update := &scheduledRepoUpdate{LastWebhook: tc.lastWebhook}
+		if have := update.receivesWebhooks(); have != tc.want {
+			t.Errorf("receivesWebhooks with last webhook at %s: want %t, have %t", tc.lastWebhook, tc.want, have)
 		}
 	}
 }
 
-func TestUpdateScheduler_runSchedule(t *testing.T) {
-	a := configuredRepo{ID: 1, Name: "a"}
-	b := configuredRepo{ID: 2, Name: "b"}
-	c := configuredRepo{ID: 3, Name: "c"}
-	d := configuredRepo{ID: 4, Name: "d"}
-	e := configuredRepo{ID: 5, Name: "e"}
+func TestUpdateScheduler_updateInterval(t *testing.T) {
+	_, stop := startRecording()
+	defer stop()
 
-	tests := []struct {
-		name                  string
-		initialSchedule       []*scheduledRepoUpdate
-		finalSchedule         []*scheduledRepoUpdate
-		finalQueue            []*repoUpdate
-		timeAfterFuncDelays   []time.Duration
-		expectedNotifications func(s *UpdateScheduler) []chan struct{}
+	ctx := context.Background()
+	db := newTestDB(t)
+	s := newTestScheduler(t, db, "repo-updater-0")
+	repos := createTestRepos(t, db, "a", "b", "c", "d")
+	a, b, c, d := repos[0], repos[1], repos[2], repos[3]
+
+	setupInitialSchedule(t, s, []*scheduledRepoUpdate{
+		{Repo: a, Interval: time.Hour, Due: defaultTime.Add(time.Hour)},
+		{Repo: b, Interval: time.Hour, Due: defaultTime.Add(time.Hour)},
+		{Repo: c, Interval: time.Hour, Due: defaultTime.Add(time.Hour)},
+	})
+
+	for _, call := range []struct {
+		repo     configuredRepo
+		interval time.Duration
 	}{
-		{
-			name: "empty schedule",
-		},
-		{
-			name: "no updates due",
-			initialSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: 11 * time.Second, Due: defaultTime.Add(time.Minute)},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: 11 * time.Second, Due: defaultTime.Add(time.Minute)},
-			},
-			timeAfterFuncDelays: []time.Duration{time.Minute},
-			expectedNotifications: func(s *UpdateScheduler) []chan struct{} {
-				return []chan struct{}{s.schedule.wakeup}
-			},
-		},
-		{
-			name: "one update due, rescheduled to front",
-			initialSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: 11 * time.Second, Due: defaultTime.Add(1 * time.Microsecond)},
-				{Repo: b, Interval: 22 * time.Second, Due: defaultTime.Add(time.Minute)},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: 11 * time.Second, Due: defaultTime.Add(11 * time.Second)},
-				{Repo: b, Interval: 22 * time.Second, Due: defaultTime.Add(time.Minute)},
-			},
-			finalQueue: []*repoUpdate{
-				{Repo: a, Priority: priorityLow, Seq: 1},
-			},
-			timeAfterFuncDelays: []time.Duration{11 * time.Second},
-			expectedNotifications: func(s *UpdateScheduler) []chan struct{} {
-				return []chan struct{}{s.updateQueue.notifyEnqueue, s.schedule.wakeup}
-			},
-		},
-		{
-			name: "one update due, rescheduled to back",
-			initialSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: 11 * time.Minute, Due: defaultTime},
-				{Repo: b, Interval: 22 * time.Second, Due: defaultTime.Add(time.Minute)},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{Repo: b, Interval: 22 * time.Second, Due: defaultTime.Add(time.Minute)},
-				{Repo: a, Interval: 11 * time.Minute, Due: defaultTime.Add(11 * time.Minute)},
-			},
-			finalQueue: []*repoUpdate{
-				{Repo: a, Priority: priorityLow, Seq: 1},
-			},
-			timeAfterFuncDelays: []time.Duration{time.Minute},
-			expectedNotifications: func(s *UpdateScheduler) []chan struct{} {
-				return []chan struct{}{s.updateQueue.notifyEnqueue, s.schedule.wakeup}
-			},
-		},
-		{
-			name: "all updates due",
-			initialSchedule: []*scheduledRepoUpdate{
-				{Repo: c, Interval: 3 * time.Minute, Due: defaultTime.Add(-5 * time.Minute)},
-				{Repo: d, Interval: 4 * time.Minute, Due: defaultTime.Add(-4 * time.Minute)},
-				{Repo: a, Interval: 1 * time.Minute, Due: defaultTime.Add(-3 * time.Minute)},
-				{Repo: e, Interval: 5 * time.Minute, Due: defaultTime.Add(-2 * time.Minute)},
-				{Repo: b, Interval: 2 * time.Minute, Due: defaultTime.Add(-1 * time.Minute)},
-			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: 1 * time.Minute, Due: defaultTime.Add(1 * time.Minute)},
-				{Repo: b, Interval: 2 * time.Minute, Due: defaultTime.Add(2 * time.Minute)},
-				{Repo: c, Interval: 3 * time.Minute, Due: defaultTime.Add(3 * time.Minute)},
-				{Repo: d, Interval: 4 * time.Minute, Due: defaultTime.Add(4 * time.Minute)},
-				{Repo: e, Interval: 5 * time.Minute, Due: defaultTime.Add(5 * time.Minute)},
-			},
-			finalQueue: []*repoUpdate{
-				{Repo: c, Priority: priorityLow, Seq: 1},
-				{Repo: d, Priority: priorityLow, Seq: 2},
-				{Repo: a, Priority: priorityLow, Seq: 3},
-				{Repo: e, Priority: priorityLow, Seq: 4},
-				{Repo: b, Priority: priorityLow, Seq: 5},
-			},
-			timeAfterFuncDelays: []time.Duration{1 * time.Minute},
-			expectedNotifications: func(s *UpdateScheduler) []chan struct{} {
-				return []chan struct{}{
-					s.updateQueue.notifyEnqueue,
-					s.updateQueue.notifyEnqueue,
-					s.updateQueue.notifyEnqueue,
-					s.updateQueue.notifyEnqueue,
-					s.updateQueue.notifyEnqueue,
-					s.schedule.wakeup,
-				}
-			},
-		},
+		{repo: a, interval: 123 * time.Second},
+		{repo: b, interval: time.Second},
+		{repo: c, interval: 48 * time.Hour},
+		// Repos that are not in the schedule are ignored.
+		{repo: d, interval: time.Minute},
+	} {
+		if err := s.updateInterval(ctx, call.repo, call.interval); err != nil {
+			t.Fatal(err)
+		}
 	}
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			r, stop := startRecording()
-			defer stop()
+	verifySchedule(t, s, []*scheduledRepoUpdate{
+		{Repo: b, Interval: minDelay, Due: defaultTime.Add(minDelay)},
+		{Repo: a, Interval: 123 * time.Second, Due: defaultTime.Add(123 * time.Second)},
+		{Repo: c, Interval: maxDelay, Due: defaultTime.Add(maxDelay)},
+	})
+}
 
-			s := NewUpdateScheduler(logtest.Scoped(t), database.NewMockDB())
+func TestUpdateScheduler_runSchedule(t *testing.T) {
+	r, stop := startRecording()
+	defer stop()
 
-			setupInitialSchedule(s, test.initialSchedule)
+	db := newTestDB(t)
+	s := newTestScheduler(t, db, "repo-updater-0")
+	repos := createTestRepos(t, db, "a", "b", "c")
+	a, b, c := repos[0], repos[1], repos[2]
 
-			s.runSchedule()
+	setupInitialSchedule(t, s, []*scheduledRepoUpdate{
+		{Repo: a, Interval: time.Hour, Due: defaultTime.Add(-time.Minute)},
+		{Repo: b, Interval: 2 * time.Minute, Due: defaultTime},
+		{Repo: c, Interval: time.Minute, Due: defaultTime.Add(time.Minute)},
+	})
+	setupInitialQueue(t, s, []*repoUpdate{
+		{Repo: b, Priority: priorityHigh, QueuedAt: defaultTime.Add(-time.Second)},
+	})
 
-			verifySchedule(t, s, test.finalSchedule)
-			verifyQueue(t, s, test.finalQueue)
-			verifyRecording(t, s, test.timeAfterFuncDelays, test.expectedNotifications, r)
-		})
+	if err := s.runSchedule(context.Background()); err != nil {
+		t.Fatal(err)
 	}
+
+	// Due repos are scheduled again, and are enqueued unless they are already queued.
+	verifySchedule(t, s, []*scheduledRepoUpdate{
+		{Repo: c, Interval: time.Minute, Due: defaultTime.Add(time.Minute)},
+		{Repo: b, Interval: 2 * time.Minute, Due: defaultTime.Add(2 * time.Minute)},
+		{Repo: a, Interval: time.Hour, Due: defaultTime.Add(time.Hour)},
+	})
+	verifyQueue(t, s, []*repoUpdate{
+		{Repo: b, Priority: priorityHigh, QueuedAt: defaultTime.Add(-time.Second)},
+		{Repo: a, Priority: priorityLow, QueuedAt: defaultTime},
+	})
+	verifyNotifications(t, []chan struct{}{s.notifyEnqueue}, r)
 }
 
 func TestUpdateScheduler_runUpdateLoop(t *testing.T) {
-	a := configuredRepo{ID: 1, Name: "a"}
-	b := configuredRepo{ID: 2, Name: "b"}
-	c := configuredRepo{ID: 3, Name: "c"}
-
 	type mockRequestRepoUpdate struct {
-		repo configuredRepo
+		repo string
 		resp *gitserverprotocol.RepoUpdateResponse
 		err  error
 	}
@@ -1332,79 +656,181 @@ func TestUpdateScheduler_runUpdateLoop(t *testing.T) {
 	tests := []struct {
 		name                   string
 		gitMaxConcurrentClones int
-		initialSchedule        []*scheduledRepoUpdate
-		initialQueue           []*repoUpdate
+		initialSchedule        func(repos map[string]configuredRepo) []*scheduledRepoUpdate
+		initialQueue           func(repos map[string]configuredRepo) []*repoUpdate
 		mockRequestRepoUpdates []*mockRequestRepoUpdate
-		finalSchedule          []*scheduledRepoUpdate
-		finalQueue             []*repoUpdate
-		timeAfterFuncDelays    []time.Duration
-		expectedNotifications  func(s *UpdateScheduler) []chan struct{}
+		finalSchedule          func(repos map[string]configuredRepo) []*scheduledRepoUpdate
+		finalQueue             func(repos map[string]configuredRepo) []*repoUpdate
 	}{
 		{
 			name: "empty queue",
 		},
 		{
 			name: "non-empty queue at clone limit",
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
+			initialQueue: func(repos map[string]configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: repos["a"], QueuedAt: defaultTime},
+				}
 			},
-			finalQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
+			finalQueue: func(repos map[string]configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: repos["a"], QueuedAt: defaultTime},
+				}
 			},
 		},
 		{
 			name:                   "queue drains",
 			gitMaxConcurrentClones: 1,
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
-				{Repo: b, Seq: 2},
-				{Repo: c, Seq: 3},
+			initialQueue: func(repos map[string]configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: repos["a"], QueuedAt: defaultTime},
+					{Repo: repos["b"], QueuedAt: defaultTime.Add(time.Second)},
+					{Repo: repos["c"], QueuedAt: defaultTime.Add(2 * time.Second)},
+				}
+			},
+			mockRequestRepoUpdates: []*mockRequestRepoUpdate{
+				{repo: "a"},
+				{repo: "b"},
+				{repo: "c"},
+			},
+		},
+		{
+			name:                   "repos updated by other instances are skipped",
+			gitMaxConcurrentClones: 1,
+			initialQueue: func(repos map[string]configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: repos["a"], QueuedAt: defaultTime, Updating: true, LeasedBy: "repo-updater-1"},
+					{Repo: repos["b"], QueuedAt: defaultTime.Add(time.Second)},
+				}
 			},
 			mockRequestRepoUpdates: []*mockRequestRepoUpdate{
-				{repo: a},
-				{repo: b},
-				{repo: c},
+				{repo: "b"},
+			},
+			finalQueue: func(repos map[string]configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: repos["a"], QueuedAt: defaultTime, Updating: true, LeasedBy: "repo-updater-1"},
+				}
 			},
 		},
 		{
 			name:                   "schedule updated",
 			gitMaxConcurrentClones: 1,
-			initialSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: time.Hour, Due: defaultTime.Add(time.Hour)},
+			initialSchedule: func(repos map[string]configuredRepo) []*scheduledRepoUpdate {
+				return []*scheduledRepoUpdate{
+					{Repo: repos["a"], Interval: time.Hour, Due: defaultTime.Add(time.Hour)},
+				}
 			},
-			initialQueue: []*repoUpdate{
-				{Repo: a, Seq: 1},
-				{Repo: b, Seq: 1},
+			initialQueue: func(repos map[string]configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: repos["a"], QueuedAt: defaultTime},
+					{Repo: repos["b"], QueuedAt: defaultTime},
+				}
 			},
 			mockRequestRepoUpdates: []*mockRequestRepoUpdate{
 				{
-					repo: a,
+					repo: "a",
+					resp: &gitserverprotocol.RepoUpdateResponse{
+						LastFetched: timePtr(defaultTime.Add(2 * time.Minute)),
+						LastChanged: timePtr(defaultTime),
+					},
+				},
+				{
+					repo: "b",
 					resp: &gitserverprotocol.RepoUpdateResponse{
 						LastFetched: timePtr(defaultTime.Add(2 * time.Minute)),
 						LastChanged: timePtr(defaultTime),
 					},
 				},
+			},
+			finalSchedule: func(repos map[string]configuredRepo) []*scheduledRepoUpdate {
+				return []*scheduledRepoUpdate{
+					{Repo: repos["a"], Interval: time.Minute, Due: defaultTime.Add(time.Minute)},
+				}
+			},
+		},
+		{
+			name:                   "schedule backs off on error",
+			gitMaxConcurrentClones: 1,
+			initialSchedule: func(repos map[string]configuredRepo) []*scheduledRepoUpdate {
+				return []*scheduledRepoUpdate{
+					{Repo: repos["a"], Interval: time.Hour, Due: defaultTime.Add(time.Hour)},
+				}
+			},
+			initialQueue: func(repos map[string]configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: repos["a"], QueuedAt: defaultTime},
+				}
+			},
+			mockRequestRepoUpdates: []*mockRequestRepoUpdate{
+				{repo: "a", err: errors.New("boom")},
+			},
+			finalSchedule: func(repos map[string]configuredRepo) []*scheduledRepoUpdate {
+				return []*scheduledRepoUpdate{
+					{Repo: repos["a"], Interval: 2 * time.Hour, Due: defaultTime.Add(2 * time.Hour)},
+				}
+			},
+		},
+		{
+			name:                   "schedule backs off for repo receiving webhooks",
+			gitMaxConcurrentClones: 1,
+			initialSchedule: func(repos map[string]configuredRepo) []*scheduledRepoUpdate {
+				return []*scheduledRepoUpdate{
+					{Repo: repos["a"], Interval: time.Hour, Due: defaultTime.Add(time.Hour), LastWebhook: defaultTime.Add(-time.Hour)},
+				}
+			},
+			initialQueue: func(repos map[string]configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: repos["a"], QueuedAt: defaultTime},
+				}
+			},
+			mockRequestRepoUpdates: []*mockRequestRepoUpdate{
 				{
-					repo: b,
+					repo: "a",
 					resp: &gitserverprotocol.RepoUpdateResponse{
 						LastFetched: timePtr(defaultTime.Add(2 * time.Minute)),
 						LastChanged: timePtr(defaultTime),
 					},
 				},
 			},
-			finalSchedule: []*scheduledRepoUpdate{
-				{Repo: a, Interval: time.Minute, Due: defaultTime.Add(time.Minute)},
+			finalSchedule: func(repos map[string]configuredRepo) []*scheduledRepoUpdate {
+				return []*scheduledRepoUpdate{
+					{Repo: repos["a"], Interval: webhookDelay, Due: defaultTime.Add(webhookDelay), LastWebhook: defaultTime.Add(-time.Hour)},
+				}
+			},
+		},
+		{
+			name:                   "schedule does not back off for repo with expired webhooks",
+			gitMaxConcurrentClones: 1,
+			initialSchedule: func(repos map[string]configuredRepo) []*scheduledRepoUpdate {
+				return []*scheduledRepoUpdate{
+					{Repo: repos["a"], Interval: time.Hour, Due: defaultTime.Add(time.Hour), LastWebhook: defaultTime.Add(-webhookExpiry)},
+				}
+			},
+			initialQueue: func(repos map[string]configuredRepo) []*repoUpdate {
+				return []*repoUpdate{
+					{Repo: repos["a"], QueuedAt: defaultTime},
+				}
+			},
+			mockRequestRepoUpdates: []*mockRequestRepoUpdate{
+				{
+					repo: "a",
+					resp: &gitserverprotocol.RepoUpdateResponse{
+						LastFetched: timePtr(defaultTime.Add(2 * time.Minute)),
+						LastChanged: timePtr(defaultTime),
+					},
+				},
 			},
-			timeAfterFuncDelays: []time.Duration{time.Minute},
-			expectedNotifications: func(s *UpdateScheduler) []chan struct{} {
-				return []chan struct{}{s.schedule.wakeup}
+			finalSchedule: func(repos map[string]configuredRepo) []*scheduledRepoUpdate {
+				return []*scheduledRepoUpdate{
+					{Repo: repos["a"], Interval: time.Minute, Due: defaultTime.Add(time.Minute), LastWebhook: defaultTime.Add(-webhookExpiry)},
+				}
 			},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			r, stop := startRecording()
+			_, stop := startRecording()
 			defer stop()
 
 			configuredLimiter = func() *mutablelimiter.Limiter {
@@ -1414,6 +840,13 @@ func TestUpdateScheduler_runUpdateLoop(t *testing.T) {
 				configuredLimiter = nil
 			}()
 
+			db := newTestDB(t)
+			s := newTestScheduler(t, db, "repo-updater-0")
+			repos := map[string]configuredRepo{}
+			for _, repo := range createTestRepos(t, db, "a", "b", "c") {
+				repos[string(repo.Name)] = repo
+			}
+
 			expectedRequestCount := len(test.mockRequestRepoUpdates)
 			mockRequestRepoUpdates := make(chan *mockRequestRepoUpdate, expectedRequestCount)
 			for _, m := range test.mockRequestRepoUpdates {
@@ -1425,8 +858,8 @@ func TestUpdateScheduler_runUpdateLoop(t *testing.T) {
 			requestRepoUpdate = func(ctx context.Context, db database.DB, repo configuredRepo, since time.Duration) (*gitserverprotocol.RepoUpdateResponse, error) {
 				select {
 				case mock := <-mockRequestRepoUpdates:
-					if !reflect.DeepEqual(mock.repo, repo) {
-						t.Errorf("\nexpected requestRepoUpdate\n%s\ngot\n%s", spew.Sdump(mock.repo), spew.Sdump(repo))
+					if want := repos[mock.repo]; want != repo {
+						t.Errorf("expected requestRepoUpdate for %v, got %v", want, repo)
 					}
 					contexts <- ctx // Intercept all contexts so we can wait for spawned goroutines to finish.
 					return mock.resp, mock.err
@@ -1436,14 +869,15 @@ func TestUpdateScheduler_runUpdateLoop(t *testing.T) {
 			}
 			defer func() { requestRepoUpdate = nil }()
 
-			s := NewUpdateScheduler(logtest.Scoped(t), database.NewMockDB())
-			s.schedule.randGenerator = &mockRandomGenerator{}
+			if test.initialSchedule != nil {
+				setupInitialSchedule(t, s, test.initialSchedule(repos))
+			}
+			if test.initialQueue != nil {
+				setupInitialQueue(t, s, test.initialQueue(repos))
+			}
 
 			// unbuffer the channel
-			s.updateQueue.notifyEnqueue = make(chan struct{})
-
-			setupInitialSchedule(s, test.initialSchedule)
-			setupInitialQueue(s, test.initialQueue)
+			s.notifyEnqueue = make(chan struct{})
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
@@ -1455,9 +889,10 @@ func TestUpdateScheduler_runUpdateLoop(t *testing.T) {
 			}()
 
 			// Let the goroutine do a single loop.
-			s.updateQueue.notifyEnqueue <- struct{}{}
+			s.notifyEnqueue <- struct{}{}
 
-			// Wait for all goroutines that have a mock request to finish.
+			// Wait for all goroutines that have a mock request to finish. The
+			// repo is released and rescheduled before the context is canceled.
 			// There may be additional goroutines which don't have a mock request
 			// and will block until the context is canceled.
 			for i := 0; i < expectedRequestCount; i++ {
@@ -1465,9 +900,18 @@ func TestUpdateScheduler_runUpdateLoop(t *testing.T) {
 				<-ctx.Done()
 			}
 
-			verifySchedule(t, s, test.finalSchedule)
-			verifyQueue(t, s, test.finalQueue)
-			verifyRecording(t, s, test.timeAfterFuncDelays, test.expectedNotifications, r)
+			var (
+				finalSchedule []*scheduledRepoUpdate
+				finalQueue    []*repoUpdate
+			)
+			if test.finalSchedule != nil {
+				finalSchedule = test.finalSchedule(repos)
+			}
+			if test.finalQueue != nil {
+				finalQueue = test.finalQueue(repos)
+			}
+			verifySchedule(t, s, finalSchedule)
+			verifyQueue(t, s, finalQueue)
 
 			// Cancel the context.
 			cancel()
@@ -1478,66 +922,58 @@ func TestUpdateScheduler_runUpdateLoop(t *testing.T) {
 	}
 }
 
-func verifyRecording(t *testing.T, s *UpdateScheduler, timeAfterFuncDelays []time.Duration, expectedNotifications func(s *UpdateScheduler) []chan struct{}, r *recording) {
-	if !reflect.DeepEqual(timeAfterFuncDelays, r.timeAfterFuncDelays) {
-		t.Fatalf("\nexpected timeAfterFuncDelays\n%s\ngot\n%s", spew.Sdump(timeAfterFuncDelays), spew.Sdump(r.timeAfterFuncDelays))
-	}
-
-	if expectedNotifications == nil {
-		expectedNotifications = func(s *UpdateScheduler) []chan struct{} {
-			return nil
-		}
-	}
-
-	if expected := expectedNotifications(s); !reflect.DeepEqual(expected, r.notifications) {
-		t.Fatalf("\nexpected notifications\n%s\ngot\n%s", spew.Sdump(expected), spew.Sdump(r.notifications))
-	}
-}
-
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
 
-func Test_updateQueue_Less(t *testing.T) {
-	q := &updateQueue{}
-	tests := []struct {
-		name   string
-		heap   []*repoUpdate
-		expVal bool
-	}{
-		{
-			name: "updating",
-			heap: []*repoUpdate{
-				{Updating: false},
-				{Updating: true},
-			},
-			expVal: true,
-		},
-		{
-			name: "priority",
-			heap: []*repoUpdate{
-				{Priority: priorityHigh},
-				{Priority: priorityLow},
-			},
-			expVal: true,
+func TestUpdateScheduler_ScheduleInfo(t *testing.T) {
+	_, stop := startRecording()
+	defer stop()
+
+	ctx := context.Background()
+	db := newTestDB(t)
+	s := newTestScheduler(t, db, "repo-updater-0")
+	repos := createTestRepos(t, db, "a", "b", "c")
+	a, b, c := repos[0], repos[1], repos[2]
+
+	setupInitialSchedule(t, s, []*scheduledRepoUpdate{
+		{Repo: a, Interval: time.Minute, Due: defaultTime.Add(time.Minute)},
+		{Repo: b, Interval: time.Hour, Due: defaultTime.Add(time.Hour)},
+	})
+	setupInitialQueue(t, s, []*repoUpdate{
+		{Repo: a, Priority: priorityHigh, QueuedAt: defaultTime, Updating: true, LeasedBy: "repo-updater-1"},
+		{Repo: b, Priority: priorityHigh, QueuedAt: defaultTime},
+	})
+
+	info, err := s.ScheduleInfo(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &protocol.RepoUpdateSchedulerInfoResult{
+		Schedule: &protocol.RepoScheduleState{
+			Index:           1,
+			Total:           2,
+			IntervalSeconds: 3600,
+			Due:             defaultTime.Add(time.Hour),
 		},
-		{
-			name: "seq",
-			heap: []*repoUpdate{
-				{Seq: 1},
-				{Seq: 2},
-			},
-			expVal: true,
+		Queue: &protocol.RepoQueueState{
+			Index:    0,
+			Total:    2,
+			Updating: false,
+			Priority: int(priorityHigh),
 		},
 	}
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			q.heap = test.heap
-			got := q.Less(0, 1)
-			if test.expVal != got {
-				t.Fatalf("want %v but got: %v", test.expVal, got)
-			}
-		})
+	if diff := cmp.Diff(want, info); diff != "" {
+		t.Fatalf("unexpected schedule info (-want +got):\n%s", diff)
+	}
+
+	// Repos that are neither scheduled nor queued have no schedule info.
+	info, err = s.ScheduleInfo(ctx, c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&protocol.RepoUpdateSchedulerInfoResult{}, info); diff != "" {
+		t.Fatalf("unexpected schedule info (-want +got):\n%s", diff)
 	}
 }
 
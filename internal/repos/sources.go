@@ -53,6 +53,8 @@ func NewSource(ctx context.Context, db database.DB, svc *types.ExternalService,
 		return NewBitbucketServerSource(svc, cf)
 	case extsvc.KindBitbucketCloud:
 		return NewBitbucketCloudSource(svc, cf)
+	case extsvc.KindAzureDevOps:
+		return NewAzureDevOpsSource(svc, cf)
 	case extsvc.KindGitolite:
 		return NewGitoliteSource(db, svc, cf)
 	case extsvc.KindPhabricator:
@@ -72,6 +74,8 @@ func NewSource(ctx context.Context, db database.DB, svc *types.ExternalService,
 		return NewNpmPackagesSource(svc, cf)
 	case extsvc.KindPythonPackages:
 		return NewPythonPackagesSource(svc, cf)
+	case extsvc.KindRubyPackages:
+		return NewRubyPackagesSource(svc, cf)
 	case extsvc.KindRustPackages:
 		return NewRustPackagesSource(svc, cf)
 	case extsvc.KindOther:
@@ -139,10 +139,30 @@ func (c *Client) EnqueueRepoUpdate(ctx context.Context, repo api.RepoName) (*pro
 		return MockEnqueueRepoUpdate(ctx, repo)
 	}
 
-	req := &protocol.RepoUpdateRequest{
+	return c.enqueueRepoUpdate(ctx, &protocol.RepoUpdateRequest{
 		Repo: repo,
+	})
+}
+
+// MockEnqueueWebhookRepoUpdate mocks (*Client).EnqueueWebhookRepoUpdate for tests.
+var MockEnqueueWebhookRepoUpdate func(ctx context.Context, repo api.RepoName) (*protocol.RepoUpdateResponse, error)
+
+// EnqueueWebhookRepoUpdate requests that the named repository be updated in
+// the near future because its code host sent a push webhook for it. Besides
+// the update, this tells repo-updater that the repository receives webhooks,
+// so that it is polled less frequently. It does not wait for the update.
+func (c *Client) EnqueueWebhookRepoUpdate(ctx context.Context, repo api.RepoName) (*protocol.RepoUpdateResponse, error) {
+	if MockEnqueueWebhookRepoUpdate != nil {
+		return MockEnqueueWebhookRepoUpdate(ctx, repo)
 	}
 
+	return c.enqueueRepoUpdate(ctx, &protocol.RepoUpdateRequest{
+		Repo:    repo,
+		Webhook: true,
+	})
+}
+
+func (c *Client) enqueueRepoUpdate(ctx context.Context, req *protocol.RepoUpdateRequest) (*protocol.RepoUpdateResponse, error) {
 	resp, err := c.httpPost(ctx, "enqueue-repo-update", req)
 	if err != nil {
 		return nil, err
@@ -156,7 +176,7 @@ func (c *Client) EnqueueRepoUpdate(ctx context.Context, repo api.RepoName) (*pro
 
 	var res protocol.RepoUpdateResponse
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, &repoNotFoundError{string(repo), string(bs)}
+		return nil, &repoNotFoundError{string(req.Repo), string(bs)}
 	} else if resp.StatusCode < 200 || resp.StatusCode >= 400 {
 		return nil, errors.New(string(bs))
 	} else if err = json.Unmarshal(bs, &res); err != nil {
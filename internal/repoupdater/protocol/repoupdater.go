@@ -230,10 +230,14 @@ type RepoLinks struct {
 // RepoUpdateRequest is a request to update the contents of a given repo, or clone it if it doesn't exist.
 type RepoUpdateRequest struct {
 	Repo api.RepoName `json:"repo"`
+
+	// Webhook is true if the update was requested in response to a push
+	// webhook from the code host of the repository.
+	Webhook bool `json:"webhook,omitempty"`
 }
 
 func (a *RepoUpdateRequest) String() string {
-	return fmt.Sprintf("RepoUpdateRequest{%s}", a.Repo)
+	return fmt.Sprintf("RepoUpdateRequest{%s, Webhook: %t}", a.Repo, a.Webhook)
 }
 
 // RepoUpdateResponse is a response type to a RepoUpdateRequest.
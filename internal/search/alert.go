@@ -81,6 +81,20 @@ func AlertForCappedAndExpression() *Alert {
 	}
 }
 
+// AlertForPartialBlame reports that the authors of the matched lines of some
+// files are missing from the results because those files could not be blamed.
+func AlertForPartialBlame(failedFiles int) *Alert {
+	files := "files"
+	if failedFiles == 1 {
+		files = "file"
+	}
+	return &Alert{
+		PrometheusType: "partial_blame",
+		Title:          "Some authors could not be determined",
+		Description:    fmt.Sprintf("The matched lines of %d %s could not be blamed, so their authors are not included in the results.", failedFiles, files),
+	}
+}
+
 // AlertForQuery converts errors in the query to search alerts.
 func AlertForQuery(queryString string, err error) *Alert {
 	if errors.HasType(err, &query.UnsupportedError{}) || errors.HasType(err, &query.ExpectedOperand{}) {
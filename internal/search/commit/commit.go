@@ -309,6 +309,15 @@ func queryParameterToPredicate(parameter query.Parameter, caseSensitive, diff bo
 		newPred = &gitprotocol.CommitAfter{Time: t}
 	case query.FieldMessage:
 		newPred = &gitprotocol.MessageMatches{Expr: parameter.Value, IgnoreCase: !caseSensitive}
+	case query.FieldTrailer:
+		key, expr, _ := query.ParseTrailer(parameter.Value) // field already validated
+		newPred = &gitprotocol.TrailerMatches{Key: key, Expr: expr, IgnoreCase: !caseSensitive}
+	case query.FieldSignature:
+		status, _ := query.ParseSignatureStatus(parameter.Value) // field already validated
+		newPred = &gitprotocol.SignatureMatches{Status: gitprotocol.SignatureStatus(status)}
+	case query.FieldParents:
+		min, max, _ := query.ParseParentCount(parameter.Value) // field already validated
+		newPred = &gitprotocol.ParentCount{Min: min, Max: max}
 	case query.FieldContent:
 		if diff {
 			newPred = &gitprotocol.DiffMatches{Expr: parameter.Value, IgnoreCase: !caseSensitive}
@@ -316,6 +325,12 @@ func queryParameterToPredicate(parameter query.Parameter, caseSensitive, diff bo
 			newPred = &gitprotocol.MessageMatches{Expr: parameter.Value, IgnoreCase: !caseSensitive}
 		}
 	case query.FieldFile:
+		if parameter.Annotation.Labels.IsSet(query.IsPredicate) {
+			// Predicates such as file:has.owner() are not evaluated by
+			// commit search. Commit matches are dropped by the file owners
+			// job instead.
+			break
+		}
 		newPred = &gitprotocol.DiffModifiesFile{Expr: parameter.Value, IgnoreCase: !caseSensitive}
 	case query.FieldLang:
 		newPred = &gitprotocol.DiffModifiesFile{Expr: query.LangToFileRegexp(parameter.Value), IgnoreCase: true}
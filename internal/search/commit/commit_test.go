@@ -63,6 +63,30 @@ func TestQueryToGitQuery(t *testing.T) {
 			&protocol.MessageMatches{Expr: "message2", IgnoreCase: true},
 			&protocol.DiffModifiesFile{Expr: "file", IgnoreCase: true},
 		),
+	}, {
+		name: "trailer with value",
+		input: query.Basic{
+			Parameters: []query.Parameter{{Field: query.FieldTrailer, Value: "Co-authored-by:alice"}},
+		},
+		output: &protocol.TrailerMatches{Key: "Co-authored-by", Expr: "alice", IgnoreCase: true},
+	}, {
+		name: "negated trailer",
+		input: query.Basic{
+			Parameters: []query.Parameter{{Field: query.FieldTrailer, Value: "Signed-off-by", Negated: true}},
+		},
+		output: protocol.NewNot(&protocol.TrailerMatches{Key: "Signed-off-by", IgnoreCase: true}),
+	}, {
+		name: "signature",
+		input: query.Basic{
+			Parameters: []query.Parameter{{Field: query.FieldSignature, Value: "unsigned"}},
+		},
+		output: &protocol.SignatureMatches{Status: protocol.SignatureUnsigned},
+	}, {
+		name: "parents",
+		input: query.Basic{
+			Parameters: []query.Parameter{{Field: query.FieldParents, Value: ">1"}},
+		},
+		output: &protocol.ParentCount{Min: 2, Max: -1},
 	}}
 
 	for _, tc := range cases {
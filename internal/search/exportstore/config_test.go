@@ -0,0 +1,72 @@
+package exportstore
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigDisabled(t *testing.T) {
+	config := Config{}
+	config.SetMockGetter(mapGetter(nil))
+	config.Load()
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+
+	if config.Enabled() {
+		t.Errorf("expected exports to be disabled without a backend")
+	}
+}
+
+func TestConfigFilesystem(t *testing.T) {
+	env := map[string]string{
+		"SEARCH_EXPORT_UPLOAD_BACKEND":         "Filesystem",
+		"SEARCH_EXPORT_UPLOAD_TTL":             "8h",
+		"SEARCH_EXPORT_UPLOAD_FILESYSTEM_ROOT": "/mnt/exports",
+	}
+
+	config := Config{}
+	config.SetMockGetter(mapGetter(env))
+	config.Load()
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+
+	if !config.Enabled() {
+		t.Errorf("expected exports to be enabled")
+	}
+	if config.Backend != "filesystem" {
+		t.Errorf("unexpected value for Backend. want=%s have=%s", "filesystem", config.Backend)
+	}
+	if config.Bucket != "search-exports" {
+		t.Errorf("unexpected value for Bucket. want=%s have=%s", "search-exports", config.Bucket)
+	}
+	if config.TTL != 8*time.Hour {
+		t.Errorf("unexpected value for TTL. want=%v have=%v", 8*time.Hour, config.TTL)
+	}
+	if config.FilesystemRoot != "/mnt/exports" {
+		t.Errorf("unexpected value for Filesystem.Root. want=%s have=%s", "/mnt/exports", config.FilesystemRoot)
+	}
+}
+
+func TestConfigInvalidBackend(t *testing.T) {
+	config := Config{}
+	config.SetMockGetter(mapGetter(map[string]string{"SEARCH_EXPORT_UPLOAD_BACKEND": "ftp"}))
+	config.Load()
+
+	if err := config.Validate(); err == nil {
+		t.Fatalf("expected validation error")
+	}
+}
+
+func mapGetter(env map[string]string) func(name, defaultValue, description string) string {
+	return func(name, defaultValue, description string) string {
+		if v, ok := env[name]; ok {
+			return v
+		}
+
+		return defaultValue
+	}
+}
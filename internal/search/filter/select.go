@@ -39,9 +39,12 @@ var validSelectors = object{
 			"removed": nil,
 		},
 	},
-	Content: nil,
+	Content: {
+		"author": nil,
+	},
 	File: {
 		"directory": nil,
+		"owners":    nil,
 		"path":      nil,
 	},
 	Repository: nil,
@@ -0,0 +1,189 @@
+package jobutil
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/job"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// NewContentAuthorsJob creates a job that blames the matched lines of the file
+// matches streamed by its child job and replaces them with the authors of
+// those lines. Authors are aggregated across all matches and are sent in a
+// single event, ordered by the number of matched lines they last modified,
+// once the child job completes.
+func NewContentAuthorsJob(child job.Job) job.Job {
+	return &contentAuthorsJob{child: child}
+}
+
+// blameConcurrency is the number of files that are blamed concurrently.
+const blameConcurrency = 8
+
+type contentAuthorsJob struct {
+	child job.Job
+}
+
+func (j *contentAuthorsJob) Run(ctx context.Context, clients job.RuntimeClients, stream streaming.Sender) (alert *search.Alert, err error) {
+	_, ctx, stream, finish := job.StartSpan(ctx, stream, j)
+	defer func() { finish(alert, err) }()
+
+	var (
+		mu      sync.Mutex
+		failed  int
+		authors = make(map[string]*result.AuthorMatch)
+	)
+
+	g := goroutine.NewBounded(blameConcurrency)
+
+	filteredStream := streaming.StreamFunc(func(event streaming.SearchEvent) {
+		for _, m := range event.Results {
+			fm, ok := m.(*result.FileMatch)
+			if !ok {
+				// Only matched lines have authors.
+				continue
+			}
+
+			g.Go(func() error {
+				hunks, lines, err := blameMatchedLines(ctx, clients.Gitserver, fm)
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if err != nil {
+					// A file that can't be blamed doesn't fail the search, its
+					// authors are missing from the results instead. Blames that
+					// fail because the search is canceled aren't reported.
+					if ctx.Err() == nil {
+						failed++
+					}
+					return nil
+				}
+
+				for _, line := range lines {
+					hunk := hunkForLine(hunks, line)
+					if hunk == nil {
+						continue
+					}
+					key := authorKey(hunk.Author.Name, hunk.Author.Email)
+					author, ok := authors[key]
+					if !ok {
+						author = &result.AuthorMatch{Name: hunk.Author.Name, Email: hunk.Author.Email}
+						authors[key] = author
+					}
+					author.Count++
+				}
+				return nil
+			})
+		}
+
+		// Forward progress and stats, but not the file matches.
+		event.Results = nil
+		stream.Send(event)
+	})
+
+	alert, err = j.child.Run(ctx, clients, filteredStream)
+	g.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(authors) > 0 {
+		stream.Send(streaming.SearchEvent{Results: sortedAuthorMatches(authors)})
+	}
+
+	if failed > 0 {
+		alert = search.MaxPriorityAlert(alert, search.AlertForPartialBlame(failed))
+	}
+
+	return alert, err
+}
+
+func (j *contentAuthorsJob) Name() string {
+	return "ContentAuthorsJob"
+}
+
+func (j *contentAuthorsJob) Tags() []log.Field {
+	return []log.Field{}
+}
+
+// blameMatchedLines blames the range of lines spanning all matched lines of
+// fm. It returns the blame hunks along with the 1-indexed matched lines.
+func blameMatchedLines(ctx context.Context, client gitserver.Client, fm *result.FileMatch) ([]*gitserver.Hunk, []int, error) {
+	var lines []int
+	for _, lm := range fm.ChunkMatches.AsLineMatches() {
+		// Skip context lines of multiline chunks that do not contain a match.
+		if len(lm.OffsetAndLengths) == 0 {
+			continue
+		}
+		lines = append(lines, int(lm.LineNumber)+1)
+	}
+	if len(lines) == 0 {
+		return nil, nil, nil
+	}
+	sort.Ints(lines)
+
+	hunks, err := client.BlameFile(ctx, fm.Repo.Name, fm.Path, &gitserver.BlameOptions{
+		NewestCommit: fm.CommitID,
+		StartLine:    lines[0],
+		EndLine:      lines[len(lines)-1],
+	}, authz.DefaultSubRepoPermsChecker)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "blaming %s in %s@%s", fm.Path, fm.Repo.Name, fm.CommitID)
+	}
+
+	return hunks, lines, nil
+}
+
+// hunkForLine returns the hunk containing the given 1-indexed line, or nil if
+// there is none.
+func hunkForLine(hunks []*gitserver.Hunk, line int) *gitserver.Hunk {
+	for _, hunk := range hunks {
+		if hunk.StartLine <= line && line < hunk.EndLine {
+			return hunk
+		}
+	}
+	return nil
+}
+
+// authorKey identifies an author by email, falling back to their name for
+// commits without an author email.
+func authorKey(name, email string) string {
+	if email != "" {
+		return email
+	}
+	return name
+}
+
+// sortedAuthorMatches returns the given authors ordered by descending count,
+// then by name.
+func sortedAuthorMatches(authors map[string]*result.AuthorMatch) result.Matches {
+	sorted := make([]*result.AuthorMatch, 0, len(authors))
+	for _, author := range authors {
+		sorted = append(sorted, author)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Email < sorted[j].Email
+	})
+
+	matches := make(result.Matches, 0, len(sorted))
+	for _, author := range sorted {
+		matches = append(matches, author)
+	}
+	return matches
+}
@@ -0,0 +1,107 @@
+package jobutil
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/gitdomain"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/job"
+	"github.com/sourcegraph/sourcegraph/internal/search/job/mockjob"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+func TestContentAuthorsJob(t *testing.T) {
+	repo := types.MinimalRepo{ID: 1, Name: "github.com/sourcegraph/sourcegraph"}
+
+	// fileMatch returns a match of the given 0-indexed lines of path.
+	fileMatch := func(path string, lines ...int) *result.FileMatch {
+		fm := &result.FileMatch{File: result.File{Repo: repo, CommitID: "deadbeef", Path: path}}
+		for _, line := range lines {
+			fm.ChunkMatches = append(fm.ChunkMatches, result.ChunkMatch{
+				Content:      "oldAPI()",
+				ContentStart: result.Location{Line: line},
+				Ranges: result.Ranges{{
+					Start: result.Location{Line: line, Column: 0},
+					End:   result.Location{Line: line, Column: 6},
+				}},
+			})
+		}
+		return fm
+	}
+
+	alice := gitdomain.Signature{Name: "Alice", Email: "alice@example.com"}
+	bob := gitdomain.Signature{Name: "Bob", Email: "bob@example.com"}
+
+	gsClient := gitserver.NewMockClient()
+	gsClient.BlameFileFunc.SetDefaultHook(func(_ context.Context, _ api.RepoName, path string, opt *gitserver.BlameOptions, _ authz.SubRepoPermissionChecker) ([]*gitserver.Hunk, error) {
+		require.Equal(t, api.CommitID("deadbeef"), opt.NewestCommit)
+
+		switch path {
+		case "a.go":
+			require.Equal(t, 2, opt.StartLine)
+			require.Equal(t, 10, opt.EndLine)
+			return []*gitserver.Hunk{
+				{StartLine: 2, EndLine: 5, Author: alice},
+				{StartLine: 5, EndLine: 11, Author: bob},
+			}, nil
+		case "b.go":
+			return []*gitserver.Hunk{
+				{StartLine: 1, EndLine: 2, Author: bob},
+			}, nil
+		case "c.go":
+			return nil, errors.New("blame failed")
+		}
+		return nil, nil
+	})
+
+	child := mockjob.NewMockJob()
+	child.RunFunc.SetDefaultHook(func(_ context.Context, _ job.RuntimeClients, s streaming.Sender) (*search.Alert, error) {
+		s.Send(streaming.SearchEvent{
+			Results: []result.Match{
+				fileMatch("a.go", 1, 3, 9),
+				&result.RepoMatch{Name: repo.Name, ID: repo.ID},
+			},
+			Stats: streaming.Stats{IsLimitHit: true},
+		})
+		s.Send(streaming.SearchEvent{
+			Results: []result.Match{fileMatch("b.go", 0), fileMatch("c.go", 4)},
+		})
+		return nil, nil
+	})
+
+	var (
+		mu       sync.Mutex
+		sent     []result.Match
+		limitHit bool
+	)
+	stream := streaming.StreamFunc(func(e streaming.SearchEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, e.Results...)
+		limitHit = limitHit || e.Stats.IsLimitHit
+	})
+
+	j := NewContentAuthorsJob(child)
+	alert, err := j.Run(context.Background(), job.RuntimeClients{Gitserver: gsClient}, stream)
+	require.NoError(t, err)
+
+	// The authors of c.go are missing, which is reported rather than failing
+	// the search.
+	require.Equal(t, search.AlertForPartialBlame(1), alert)
+
+	require.Equal(t, []result.Match{
+		&result.AuthorMatch{Name: "Alice", Email: "alice@example.com", Count: 2},
+		&result.AuthorMatch{Name: "Bob", Email: "bob@example.com", Count: 2},
+	}, sent)
+	require.True(t, limitHit)
+}
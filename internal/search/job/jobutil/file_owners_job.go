@@ -0,0 +1,212 @@
+package jobutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/codeowners"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/job"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// NewFileOwnersJob creates a job that resolves the owners of the file matches
+// streamed by its child job from the CODEOWNERS file of their repository at
+// the searched revision.
+//
+// File matches that are not owned by all of the given owners are dropped, as
+// are all other matches since only files have owners. If selectOwners is true,
+// the remaining file matches are replaced by the owners of the files, each of
+// which is only sent once per repository.
+func NewFileOwnersJob(child job.Job, owners []string, selectOwners bool) job.Job {
+	return &fileOwnersJob{child: child, owners: owners, selectOwners: selectOwners}
+}
+
+type fileOwnersJob struct {
+	child        job.Job
+	owners       []string
+	selectOwners bool
+}
+
+func (j *fileOwnersJob) Run(ctx context.Context, clients job.RuntimeClients, stream streaming.Sender) (alert *search.Alert, err error) {
+	_, ctx, stream, finish := job.StartSpan(ctx, stream, j)
+	defer func() { finish(alert, err) }()
+
+	var (
+		mu       sync.Mutex
+		errs     error
+		seen     = make(map[repoOwner]struct{})
+		reported = make(map[api.RepoName]struct{})
+	)
+
+	rules := newCodeownersCache(clients.DB)
+
+	filteredStream := streaming.StreamFunc(func(event streaming.SearchEvent) {
+		// A file match may be replaced by several owner matches, so the results
+		// can't be filtered in place.
+		filtered := make([]result.Match, 0, len(event.Results))
+		for _, m := range event.Results {
+			fm, ok := m.(*result.FileMatch)
+			if !ok {
+				// Only files have owners.
+				continue
+			}
+
+			rs, err := rules.get(ctx, fm.Repo.Name, fm.CommitID)
+			if err != nil {
+				// The error is shared by all file matches of the repository,
+				// so it is only reported once.
+				mu.Lock()
+				if _, ok := reported[fm.Repo.Name]; !ok {
+					reported[fm.Repo.Name] = struct{}{}
+					errs = errors.Append(errs, err)
+				}
+				mu.Unlock()
+				continue
+			}
+
+			if !ownedByAll(rs, fm.Path, j.owners) {
+				continue
+			}
+
+			if !j.selectOwners {
+				filtered = append(filtered, m)
+				continue
+			}
+
+			for _, owner := range rs.Owners(fm.Path) {
+				key := repoOwner{repo: fm.Repo.Name, owner: codeowners.NormalizeOwner(owner)}
+				mu.Lock()
+				_, ok := seen[key]
+				seen[key] = struct{}{}
+				mu.Unlock()
+				if ok {
+					continue
+				}
+				filtered = append(filtered, &result.OwnerMatch{Handle: owner, Repo: fm.Repo})
+			}
+		}
+		event.Results = filtered
+		stream.Send(event)
+	})
+
+	alert, err = j.child.Run(ctx, clients, filteredStream)
+	if err != nil {
+		errs = errors.Append(errs, err)
+	}
+	return alert, errs
+}
+
+func (j *fileOwnersJob) Name() string {
+	return "FileOwnersJob"
+}
+
+func (j *fileOwnersJob) Tags() []log.Field {
+	return []log.Field{
+		trace.Printf("owners", "%q", j.owners),
+		log.Bool("selectOwners", j.selectOwners),
+	}
+}
+
+// ownedByAll returns whether the file at path is owned by all of the given
+// owners.
+func ownedByAll(rs *codeowners.Ruleset, path string, owners []string) bool {
+	for _, owner := range owners {
+		if !rs.IsOwnedBy(path, owner) {
+			return false
+		}
+	}
+	return true
+}
+
+type repoOwner struct {
+	repo  api.RepoName
+	owner string
+}
+
+type repoCommit struct {
+	repo   api.RepoName
+	commit api.CommitID
+}
+
+// codeownersCache loads and caches the CODEOWNERS ruleset of a repository at a
+// commit, since all file matches of a repository usually share the same one.
+type codeownersCache struct {
+	client *gitserver.ClientImplementor
+
+	mu    sync.Mutex
+	rules map[repoCommit]*codeownersEntry
+}
+
+// codeownersEntry is the ruleset of a repository at a commit. It is loaded
+// once, by the first caller that asks for it.
+type codeownersEntry struct {
+	once sync.Once
+	rs   *codeowners.Ruleset
+	err  error
+}
+
+func newCodeownersCache(db database.DB) *codeownersCache {
+	return &codeownersCache{
+		client: gitserver.NewClient(db),
+		rules:  make(map[repoCommit]*codeownersEntry),
+	}
+}
+
+// get returns the ruleset of the first CODEOWNERS file found in the repository
+// at the given commit, or nil if there is none. Concurrent calls for other
+// repositories or commits do not wait for each other.
+func (c *codeownersCache) get(ctx context.Context, repo api.RepoName, commit api.CommitID) (*codeowners.Ruleset, error) {
+	key := repoCommit{repo: repo, commit: commit}
+
+	c.mu.Lock()
+	entry, ok := c.rules[key]
+	if !ok {
+		entry = &codeownersEntry{}
+		c.rules[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.rs, entry.err = c.load(ctx, repo, commit)
+	})
+	return entry.rs, entry.err
+}
+
+func (c *codeownersCache) load(ctx context.Context, repo api.RepoName, commit api.CommitID) (*codeowners.Ruleset, error) {
+	for _, path := range codeowners.Paths {
+		content, err := c.client.ReadFile(ctx, repo, commit, path, authz.DefaultSubRepoPermsChecker)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s in %s@%s", path, repo, commit)
+		}
+
+		rs, err := codeowners.Parse(bytes.NewReader(content))
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s in %s@%s", path, repo, commit)
+		}
+		return rs, nil
+	}
+	return nil, nil
+}
+
+// fileOwnersValue returns a summary of the parameters of a fileOwnersJob for
+// printing job trees.
+func fileOwnersValue(j *fileOwnersJob) string {
+	return fmt.Sprintf("owners=%s select=%t", strings.Join(j.owners, ","), j.selectOwners)
+}
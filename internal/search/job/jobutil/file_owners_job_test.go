@@ -0,0 +1,148 @@
+package jobutil
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/job"
+	"github.com/sourcegraph/sourcegraph/internal/search/job/mockjob"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+func TestFileOwnersJob(t *testing.T) {
+	gitserver.Mocks.ReadFile = func(_ api.CommitID, name string) ([]byte, error) {
+		if name != ".github/CODEOWNERS" {
+			return nil, os.ErrNotExist
+		}
+		return []byte("*.go @go-team\n/docs/ @docs-team @alice\n"), nil
+	}
+	t.Cleanup(gitserver.ResetMocks)
+
+	repo := types.MinimalRepo{ID: 1, Name: "github.com/sourcegraph/sourcegraph"}
+	fileMatch := func(path string) *result.FileMatch {
+		return &result.FileMatch{File: result.File{Repo: repo, CommitID: "deadbeef", Path: path}}
+	}
+
+	runMatches := func(owners []string, selectOwners bool, matches []result.Match) ([]result.Match, error) {
+		child := mockjob.NewMockJob()
+		child.RunFunc.SetDefaultHook(func(_ context.Context, _ job.RuntimeClients, s streaming.Sender) (*search.Alert, error) {
+			s.Send(streaming.SearchEvent{Results: matches})
+			return nil, nil
+		})
+
+		var sent []result.Match
+		stream := streaming.StreamFunc(func(e streaming.SearchEvent) {
+			sent = append(sent, e.Results...)
+		})
+
+		j := NewFileOwnersJob(child, owners, selectOwners)
+		_, err := j.Run(context.Background(), job.RuntimeClients{DB: database.NewMockDB()}, stream)
+		return sent, err
+	}
+
+	run := func(t *testing.T, owners []string, selectOwners bool) []result.Match {
+		sent, err := runMatches(owners, selectOwners, []result.Match{
+			fileMatch("main.go"),
+			fileMatch("docs/index.md"),
+			fileMatch("docs/tool.go"),
+			&result.RepoMatch{Name: repo.Name, ID: repo.ID},
+		})
+		require.NoError(t, err)
+		return sent
+	}
+
+	t.Run("has.owner", func(t *testing.T) {
+		sent := run(t, []string{"docs-team"}, false)
+		require.Equal(t, []result.Match{
+			fileMatch("docs/index.md"),
+			fileMatch("docs/tool.go"),
+		}, sent)
+	})
+
+	t.Run("multiple has.owner", func(t *testing.T) {
+		sent := run(t, []string{"@docs-team", "@Alice"}, false)
+		require.Equal(t, []result.Match{
+			fileMatch("docs/index.md"),
+			fileMatch("docs/tool.go"),
+		}, sent)
+	})
+
+	t.Run("select owners", func(t *testing.T) {
+		sent := run(t, nil, true)
+		require.Equal(t, []result.Match{
+			&result.OwnerMatch{Handle: "@go-team", Repo: repo},
+			&result.OwnerMatch{Handle: "@docs-team", Repo: repo},
+			&result.OwnerMatch{Handle: "@alice", Repo: repo},
+		}, sent)
+	})
+
+	t.Run("select owners of a file with several owners followed by other files", func(t *testing.T) {
+		gitserver.Mocks.ReadFile = func(_ api.CommitID, name string) ([]byte, error) {
+			if name != "CODEOWNERS" {
+				return nil, os.ErrNotExist
+			}
+			return []byte("*.go @go-team\n/docs/ @docs-team @alice\n*.md @writers\n"), nil
+		}
+
+		sent, err := runMatches(nil, true, []result.Match{
+			fileMatch("docs/index.go"),
+			fileMatch("main.go"),
+			fileMatch("README.md"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []result.Match{
+			&result.OwnerMatch{Handle: "@docs-team", Repo: repo},
+			&result.OwnerMatch{Handle: "@alice", Repo: repo},
+			&result.OwnerMatch{Handle: "@go-team", Repo: repo},
+			&result.OwnerMatch{Handle: "@writers", Repo: repo},
+		}, sent)
+	})
+
+	t.Run("CODEOWNERS error is reported once per repository", func(t *testing.T) {
+		gitserver.Mocks.ReadFile = func(_ api.CommitID, _ string) ([]byte, error) {
+			return nil, errors.New("gitserver unavailable")
+		}
+
+		sent, err := runMatches(nil, true, []result.Match{
+			fileMatch("main.go"),
+			fileMatch("docs/index.md"),
+		})
+		require.Empty(t, sent)
+		var multiErr errors.MultiError
+		require.True(t, errors.As(err, &multiErr))
+		require.Len(t, multiErr.Errors(), 1)
+	})
+
+	t.Run("select owners with different spellings", func(t *testing.T) {
+		gitserver.Mocks.ReadFile = func(_ api.CommitID, name string) ([]byte, error) {
+			if name != "CODEOWNERS" {
+				return nil, os.ErrNotExist
+			}
+			return []byte("*.go @Alice\n/docs/ alice\n"), nil
+		}
+
+		sent := run(t, nil, true)
+		require.Equal(t, []result.Match{
+			&result.OwnerMatch{Handle: "@Alice", Repo: repo},
+		}, sent)
+	})
+
+	t.Run("no CODEOWNERS", func(t *testing.T) {
+		gitserver.Mocks.ReadFile = func(_ api.CommitID, _ string) ([]byte, error) {
+			return nil, os.ErrNotExist
+		}
+
+		sent := run(t, []string{"@go-team"}, false)
+		require.Empty(t, sent)
+	})
+}
@@ -171,11 +171,27 @@ func NewBasicJob(inputs *run.SearchInputs, b query.Basic) (job.Job, error) {
 		}
 	}
 
+	{ // Apply file:has.owner() predicates and select:file.owners
+		owners := b.FileHasOwner()
+		sp, _ := filter.SelectPathFromString(b.FindValue(query.FieldSelect)) // Invariant: select already validated
+		selectOwners := len(sp) > 1 && sp.Root() == filter.File && sp[1] == "owners"
+		if len(owners) > 0 || selectOwners {
+			basicJob = NewFileOwnersJob(basicJob, owners, selectOwners)
+		}
+	}
+
 	{ // Apply limit
 		maxResults := b.ToParseTree().MaxResults(inputs.DefaultLimit())
 		basicJob = NewLimitJob(maxResults, basicJob)
 	}
 
+	{ // Apply select:content.author after the limit, so that only matched lines within the limit are blamed
+		sp, _ := filter.SelectPathFromString(b.FindValue(query.FieldSelect)) // Invariant: select already validated
+		if len(sp) > 1 && sp.Root() == filter.Content && sp[1] == "author" {
+			basicJob = NewContentAuthorsJob(basicJob)
+		}
+	}
+
 	{ // Apply timeout
 		timeout := timeoutDuration(b)
 		basicJob = NewTimeoutJob(timeout, basicJob)
@@ -566,6 +582,7 @@ func toTextPatternInfo(b query.Basic, resultTypes result.Types, p search.Protoco
 		Languages:                    langInclude,
 		PathPatternsAreCaseSensitive: b.IsCaseSensitive(),
 		CombyRule:                    b.FindValue(query.FieldCombyRule),
+		StructuralEngine:             b.FindValue(query.FieldStructuralEngine),
 		Index:                        b.Index(),
 		Select:                       selector,
 	}
@@ -627,19 +644,20 @@ func toRepoOptions(b query.Basic, userSettings *schema.Settings) search.RepoOpti
 	searchContextSpec := b.FindValue(query.FieldContext)
 
 	return search.RepoOptions{
-		RepoFilters:       repoFilters,
-		MinusRepoFilters:  minusRepoFilters,
-		Dependencies:      b.Dependencies(),
-		Dependents:        b.Dependents(),
-		SearchContextSpec: searchContextSpec,
-		ForkSet:           b.Fork() != nil,
-		OnlyForks:         fork == query.Only,
-		NoForks:           fork == query.No,
-		ArchivedSet:       b.Archived() != nil,
-		OnlyArchived:      archived == query.Only,
-		NoArchived:        archived == query.No,
-		Visibility:        visibility,
-		CommitAfter:       commitAfter,
+		RepoFilters:        repoFilters,
+		MinusRepoFilters:   minusRepoFilters,
+		Dependencies:       b.Dependencies(),
+		Dependents:         b.Dependents(),
+		HasVulnerabilities: b.Vulnerabilities(),
+		SearchContextSpec:  searchContextSpec,
+		ForkSet:            b.Fork() != nil,
+		OnlyForks:          fork == query.Only,
+		NoForks:            fork == query.No,
+		ArchivedSet:        b.Archived() != nil,
+		OnlyArchived:       archived == query.Only,
+		NoArchived:         archived == query.No,
+		Visibility:         visibility,
+		CommitAfter:        commitAfter,
 	}
 }
 
@@ -798,6 +816,7 @@ func toFeatures(flagSet *featureflag.FlagSet) search.Features {
 	return search.Features{
 		ContentBasedLangFilters: flagSet.GetBoolOr("search-content-based-lang-detection", false),
 		HybridSearch:            flagSet.GetBoolOr("search-hybrid", false),
+		StructuralTreeSitter:    flagSet.GetBoolOr("search-structural-tree-sitter", false),
 	}
 }
 
@@ -48,6 +48,8 @@ type Mapper struct {
 
 	// Filter Jobs
 	MapSubRepoPermsFilterJob func(child job.Job) job.Job
+	MapFileOwnersJob         func(owners []string, selectOwners bool, child job.Job) ([]string, bool, job.Job)
+	MapContentAuthorsJob     func(child job.Job) job.Job
 }
 
 func (m *Mapper) Map(j job.Job) job.Job {
@@ -219,6 +221,21 @@ func (m *Mapper) Map(j job.Job) job.Job {
 		}
 		return NewFilterJob(child)
 
+	case *fileOwnersJob:
+		child := m.Map(j.child)
+		owners, selectOwners := j.owners, j.selectOwners
+		if m.MapFileOwnersJob != nil {
+			owners, selectOwners, child = m.MapFileOwnersJob(owners, selectOwners, child)
+		}
+		return NewFileOwnersJob(child, owners, selectOwners)
+
+	case *contentAuthorsJob:
+		child := m.Map(j.child)
+		if m.MapContentAuthorsJob != nil {
+			child = m.MapContentAuthorsJob(child)
+		}
+		return NewContentAuthorsJob(child)
+
 	case *NoopJob:
 		return j
 
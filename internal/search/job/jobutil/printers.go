@@ -126,6 +126,22 @@ func SexpFormat(j job.Job, sep, indent string) string {
 			writeSexp(j.child)
 			b.WriteString(")")
 			depth--
+		case *fileOwnersJob:
+			b.WriteString("(FILEOWNERS")
+			depth++
+			writeSep(b, sep, indent, depth)
+			b.WriteString(fileOwnersValue(j))
+			writeSep(b, sep, indent, depth)
+			writeSexp(j.child)
+			b.WriteString(")")
+			depth--
+		case *contentAuthorsJob:
+			b.WriteString("(CONTENTAUTHORS")
+			depth++
+			writeSep(b, sep, indent, depth)
+			writeSexp(j.child)
+			b.WriteString(")")
+			depth--
 		case *selectJob:
 			b.WriteString("(SELECT")
 			depth++
@@ -287,6 +303,22 @@ func PrettyMermaid(j job.Job) string {
 			writeEdge(b, depth, srcId, id)
 			writeMermaid(j.child)
 			depth--
+		case *fileOwnersJob:
+			srcId := id
+			depth++
+			writeNode(b, depth, RoundedStyle, &id, "FILEOWNERS")
+			writeEdge(b, depth, srcId, id)
+			writeNode(b, depth, DefaultStyle, &id, fileOwnersValue(j))
+			writeEdge(b, depth, srcId, id)
+			writeMermaid(j.child)
+			depth--
+		case *contentAuthorsJob:
+			srcId := id
+			depth++
+			writeNode(b, depth, RoundedStyle, &id, "CONTENTAUTHORS")
+			writeEdge(b, depth, srcId, id)
+			writeMermaid(j.child)
+			depth--
 		case *selectJob:
 			srcId := id
 			depth++
@@ -417,6 +449,20 @@ func toJSON(j job.Job, verbose bool) any {
 				Filter: emitJSON(j.child),
 				Value:  "SubRepoPermissions",
 			}
+		case *fileOwnersJob:
+			return struct {
+				FileOwners any    `json:"FILEOWNERS"`
+				Value      string `json:"value"`
+			}{
+				FileOwners: emitJSON(j.child),
+				Value:      fileOwnersValue(j),
+			}
+		case *contentAuthorsJob:
+			return struct {
+				ContentAuthors any `json:"CONTENTAUTHORS"`
+			}{
+				ContentAuthors: emitJSON(j.child),
+			}
 		case *selectJob:
 			return struct {
 				Select any    `json:"SELECT"`
@@ -35,6 +35,8 @@ var allJobs = []job.Job{
 	&TimeoutJob{},
 	&LimitJob{},
 	&subRepoPermsFilterJob{},
+	&fileOwnersJob{},
+	&contentAuthorsJob{},
 	&selectJob{},
 	&alertJob{},
 }
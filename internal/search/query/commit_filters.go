@@ -0,0 +1,75 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/grafana/regexp"
+
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+var trailerKeyPattern = lazyregexp.New(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// ParseTrailer parses the value of a trailer: field, which is either a
+// trailer key such as "Signed-off-by", or a key and a regular expression
+// matched against the trailer value, separated by a colon, such as
+// "Co-authored-by:alice".
+func ParseTrailer(value string) (key, expr string, err error) {
+	key = value
+	if i := strings.Index(value, ":"); i >= 0 {
+		key, expr = value[:i], value[i+1:]
+	}
+	if !trailerKeyPattern.MatchString(key) {
+		return "", "", errors.Errorf("invalid trailer key %q. Trailer keys may only contain letters, digits and dashes (example: trailer:Signed-off-by)", key)
+	}
+	if _, err := regexp.Compile(expr); err != nil {
+		return "", "", err
+	}
+	return key, expr, nil
+}
+
+// ParseSignatureStatus validates the value of a signature: field.
+func ParseSignatureStatus(value string) (string, error) {
+	switch status := strings.ToLower(value); status {
+	case "signed", "unsigned", "valid", "invalid", "unverified":
+		return status, nil
+	}
+	return "", errors.Errorf("invalid value %q for field %q. Valid values are: signed, unsigned, valid, invalid, unverified", value, FieldSignature)
+}
+
+// ParseParentCount parses the value of a parents: field, which is a number
+// optionally preceded by one of the comparison operators <, <=, > and >=. It
+// returns the inclusive range of parent counts that it accepts, where a max of
+// -1 means that there is no upper bound.
+func ParseParentCount(value string) (min, max int, err error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(value, prefix) {
+			op = prefix
+			break
+		}
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(value[len(op):]))
+	if err != nil || n < 0 {
+		return 0, 0, errors.Errorf(`invalid value %q for field %q (examples: "parents:2", "parents:>1", "parents:<=1")`, value, FieldParents)
+	}
+
+	switch op {
+	case ">":
+		return n + 1, -1, nil
+	case ">=":
+		return n, -1, nil
+	case "<":
+		if n == 0 {
+			return 0, 0, errors.Errorf("invalid value %q for field %q: no commit has fewer than 0 parents", value, FieldParents)
+		}
+		return 0, n - 1, nil
+	case "<=":
+		return 0, n, nil
+	default:
+		return n, n, nil
+	}
+}
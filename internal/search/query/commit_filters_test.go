@@ -0,0 +1,75 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestParseTrailer(t *testing.T) {
+	cases := []struct {
+		value    string
+		key      string
+		expr     string
+		hasError bool
+	}{
+		{value: "Signed-off-by", key: "Signed-off-by"},
+		{value: "Co-authored-by:alice", key: "Co-authored-by", expr: "alice"},
+		{value: "Fixes:https://example.com/1", key: "Fixes", expr: "https://example.com/1"},
+		{value: "", hasError: true},
+		{value: "Signed off by", hasError: true},
+		{value: "Reviewed-by:(", hasError: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.value, func(t *testing.T) {
+			key, expr, err := ParseTrailer(tc.value)
+			if tc.hasError {
+				if err == nil {
+					t.Fatalf("expected an error, got key %q and expr %q", key, expr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key != tc.key || expr != tc.expr {
+				t.Fatalf("got key %q and expr %q, want key %q and expr %q", key, expr, tc.key, tc.expr)
+			}
+		})
+	}
+}
+
+func TestParseParentCount(t *testing.T) {
+	cases := []struct {
+		value    string
+		min, max int
+		hasError bool
+	}{
+		{value: "1", min: 1, max: 1},
+		{value: ">1", min: 2, max: -1},
+		{value: ">=2", min: 2, max: -1},
+		{value: "<2", min: 0, max: 1},
+		{value: "<=1", min: 0, max: 1},
+		{value: "<0", hasError: true},
+		{value: "-1", hasError: true},
+		{value: "=>1", hasError: true},
+		{value: "merge", hasError: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.value, func(t *testing.T) {
+			min, max, err := ParseParentCount(tc.value)
+			if tc.hasError {
+				if err == nil {
+					t.Fatalf("expected an error, got min %d and max %d", min, max)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if min != tc.min || max != tc.max {
+				t.Fatalf("got min %d and max %d, want min %d and max %d", min, max, tc.min, tc.max)
+			}
+		})
+	}
+}
@@ -26,13 +26,17 @@ const (
 	FieldAuthor    = "author"
 	FieldCommitter = "committer"
 	FieldMessage   = "message"
+	FieldTrailer   = "trailer"
+	FieldSignature = "signature"
+	FieldParents   = "parents"
 
 	// Temporary experimental fields:
-	FieldIndex     = "index"
-	FieldCount     = "count" // Searches that specify `count:` will fetch at least that number of results, or the full result set
-	FieldTimeout   = "timeout"
-	FieldCombyRule = "rule"
-	FieldSelect    = "select"
+	FieldIndex            = "index"
+	FieldCount            = "count" // Searches that specify `count:` will fetch at least that number of results, or the full result set
+	FieldTimeout          = "timeout"
+	FieldCombyRule        = "rule"
+	FieldStructuralEngine = "engine"
+	FieldSelect           = "select"
 )
 
 var allFields = map[string]struct{}{
@@ -64,10 +68,14 @@ var allFields = map[string]struct{}{
 	FieldMessage:            empty,
 	"m":                     empty,
 	"msg":                   empty,
+	FieldTrailer:            empty,
+	FieldSignature:          empty,
+	FieldParents:            empty,
 	FieldIndex:              empty,
 	FieldCount:              empty,
 	FieldTimeout:            empty,
 	FieldCombyRule:          empty,
+	FieldStructuralEngine:   empty,
 	FieldRev:                empty,
 	"revision":              empty,
 	FieldSelect:             empty,
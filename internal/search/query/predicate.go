@@ -40,10 +40,12 @@ var DefaultPredicateRegistry = PredicateRegistry{
 		"deps":                  func() Predicate { return &RepoDependenciesPredicate{} },
 		"dependents":            func() Predicate { return &RepoDependentsPredicate{} },
 		"revdeps":               func() Predicate { return &RepoDependentsPredicate{} },
+		"has.vulnerability":     func() Predicate { return &RepoHasVulnerabilityPredicate{} },
 	},
 	FieldFile: {
 		"contains.content": func() Predicate { return &FileContainsContentPredicate{} },
 		"contains":         func() Predicate { return &FileContainsContentPredicate{} },
+		"has.owner":        func() Predicate { return &FileHasOwnerPredicate{} },
 	},
 }
 
@@ -327,6 +329,27 @@ func (f *RepoDependentsPredicate) Plan(parent Basic) (Plan, error) {
 	return nil, nil
 }
 
+// RepoHasVulnerabilityPredicate represents the `repo:has.vulnerability(id)` predicate,
+// which filters to repos with a lockfile dependency affected by the security advisory
+// with the given identifier or alias. An empty identifier matches any advisory.
+type RepoHasVulnerabilityPredicate struct {
+	ID string
+}
+
+func (f *RepoHasVulnerabilityPredicate) ParseParams(params string) error {
+	if strings.ContainsAny(params, " \t\n") {
+		return errors.Errorf("repo:has.vulnerability argument %q should not contain whitespace", params)
+	}
+	f.ID = params
+	return nil
+}
+
+func (f *RepoHasVulnerabilityPredicate) Field() string { return FieldRepo }
+func (f *RepoHasVulnerabilityPredicate) Name() string  { return "has.vulnerability" }
+func (f *RepoHasVulnerabilityPredicate) Plan(parent Basic) (Plan, error) {
+	return nil, nil
+}
+
 /* repo:contains.content(pattern) */
 
 type FileContainsContentPredicate struct {
@@ -364,6 +387,33 @@ func (f *FileContainsContentPredicate) Plan(parent Basic) (Plan, error) {
 	return BuildPlan(nodes), nil
 }
 
+// FileHasOwnerPredicate represents the `file:has.owner(owner)` predicate,
+// which filters to files that are owned by the given owner according to the
+// CODEOWNERS file of the repository at the searched revision.
+type FileHasOwnerPredicate struct {
+	Owner string
+}
+
+func (f *FileHasOwnerPredicate) ParseParams(params string) error {
+	if params == "" {
+		return errors.Errorf("file:has.owner argument should not be empty")
+	}
+	if strings.ContainsAny(params, " \t\n") {
+		return errors.Errorf("file:has.owner argument %q should not contain whitespace", params)
+	}
+	f.Owner = params
+	return nil
+}
+
+func (f FileHasOwnerPredicate) Field() string { return FieldFile }
+func (f FileHasOwnerPredicate) Name() string  { return "has.owner" }
+
+// Plan returns nil, since the predicate is evaluated against the results of
+// the query rather than being substituted.
+func (f *FileHasOwnerPredicate) Plan(parent Basic) (Plan, error) {
+	return nil, nil
+}
+
 // nonPredicateRepos returns the repo nodes in a query that aren't predicates,
 // respecting parameters that determine repo results.
 func nonPredicateRepos(q Basic) []Node {
@@ -169,3 +169,92 @@ func TestRepoDependentsPredicate(t *testing.T) {
 		}
 	})
 }
+
+func TestRepoHasVulnerabilityPredicate(t *testing.T) {
+	t.Run("ParseParams", func(t *testing.T) {
+		type test struct {
+			name     string
+			params   string
+			expected *RepoHasVulnerabilityPredicate
+		}
+
+		valid := []test{
+			{`empty`, ``, &RepoHasVulnerabilityPredicate{}},
+			{`cve`, `CVE-2021-44228`, &RepoHasVulnerabilityPredicate{ID: "CVE-2021-44228"}},
+			{`ghsa`, `GHSA-jfh8-c2jp-5v3q`, &RepoHasVulnerabilityPredicate{ID: "GHSA-jfh8-c2jp-5v3q"}},
+		}
+
+		for _, tc := range valid {
+			t.Run(tc.name, func(t *testing.T) {
+				p := &RepoHasVulnerabilityPredicate{}
+				err := p.ParseParams(tc.params)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if !reflect.DeepEqual(tc.expected, p) {
+					t.Fatalf("expected %#v, got %#v", tc.expected, p)
+				}
+			})
+		}
+
+		invalid := []test{
+			{`whitespace`, `CVE-2021-44228 CVE-2021-45046`, nil},
+		}
+
+		for _, tc := range invalid {
+			t.Run(tc.name, func(t *testing.T) {
+				p := &RepoHasVulnerabilityPredicate{}
+				err := p.ParseParams(tc.params)
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+			})
+		}
+	})
+}
+
+func TestFileHasOwnerPredicate(t *testing.T) {
+	t.Run("ParseParams", func(t *testing.T) {
+		type test struct {
+			name     string
+			params   string
+			expected *FileHasOwnerPredicate
+		}
+
+		valid := []test{
+			{`team`, `@sourcegraph/search`, &FileHasOwnerPredicate{Owner: "@sourcegraph/search"}},
+			{`user without @`, `alice`, &FileHasOwnerPredicate{Owner: "alice"}},
+			{`email`, `alice@example.com`, &FileHasOwnerPredicate{Owner: "alice@example.com"}},
+		}
+
+		for _, tc := range valid {
+			t.Run(tc.name, func(t *testing.T) {
+				p := &FileHasOwnerPredicate{}
+				err := p.ParseParams(tc.params)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if !reflect.DeepEqual(tc.expected, p) {
+					t.Fatalf("expected %#v, got %#v", tc.expected, p)
+				}
+			})
+		}
+
+		invalid := []test{
+			{`empty`, ``, nil},
+			{`whitespace`, `@alice @bob`, nil},
+		}
+
+		for _, tc := range invalid {
+			t.Run(tc.name, func(t *testing.T) {
+				p := &FileHasOwnerPredicate{}
+				err := p.ParseParams(tc.params)
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+			})
+		}
+	})
+}
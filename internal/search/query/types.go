@@ -167,6 +167,15 @@ func (q Q) Dependents() (dependents []string) {
 	return dependents
 }
 
+func (q Q) Vulnerabilities() (vulnerabilities []string) {
+	VisitPredicate(q, func(field, name, value string) {
+		if field == FieldRepo && name == "has.vulnerability" {
+			vulnerabilities = append(vulnerabilities, value)
+		}
+	})
+	return vulnerabilities
+}
+
 func (q Q) MaxResults(defaultLimit int) int {
 	if q == nil {
 		return 0
@@ -318,9 +327,12 @@ func (b Basic) IsEmptyPattern() bool {
 type Parameters []Parameter
 
 // IncludeExcludeValues partitions multiple values of a field into positive
-// (include) and negated (exclude) values.
+// (include) and negated (exclude) values. Predicates are skipped.
 func (p Parameters) IncludeExcludeValues(field string) (include, exclude []string) {
-	VisitField(toNodes(p), field, func(v string, negated bool, _ Annotation) {
+	VisitField(toNodes(p), field, func(v string, negated bool, ann Annotation) {
+		if ann.Labels.IsSet(IsPredicate) {
+			return
+		}
 		if negated {
 			exclude = append(exclude, v)
 		} else {
@@ -365,6 +377,16 @@ func (p Parameters) RepoContainsFile() (include, exclude []string) {
 	return include, exclude
 }
 
+// FileHasOwner returns the owners of all `file:has.owner()` predicates.
+func (p Parameters) FileHasOwner() (owners []string) {
+	VisitPredicate(toNodes(p), func(field, name, value string) {
+		if field == FieldFile && name == "has.owner" {
+			owners = append(owners, value)
+		}
+	})
+	return owners
+}
+
 // Exists returns whether a parameter exists in the query (whether negated or not).
 func (p Parameters) Exists(field string) bool {
 	found := false
@@ -392,6 +414,15 @@ func (p Parameters) Dependents() (dependents []string) {
 	return dependents
 }
 
+func (p Parameters) Vulnerabilities() (vulnerabilities []string) {
+	VisitPredicate(toNodes(p), func(field, name, value string) {
+		if field == FieldRepo && name == "has.vulnerability" {
+			vulnerabilities = append(vulnerabilities, value)
+		}
+	})
+	return vulnerabilities
+}
+
 func (p Parameters) MaxResults(defaultLimit int) int {
 	if count := p.Count(); count != nil {
 		return *count
@@ -221,6 +221,30 @@ func validateField(field, value string, negated bool, seen map[string]struct{})
 		return err
 	}
 
+	isValidTrailer := func() error {
+		_, _, err := ParseTrailer(value)
+		return err
+	}
+
+	isValidSignatureStatus := func() error {
+		_, err := ParseSignatureStatus(value)
+		return err
+	}
+
+	isValidStructuralEngine := func() error {
+		for _, engine := range StructuralEngines {
+			if value == engine {
+				return nil
+			}
+		}
+		return errors.Errorf("invalid value %q for field %q. Valid values are: %s", value, field, strings.Join(StructuralEngines, ", "))
+	}
+
+	isValidParentCount := func() error {
+		_, _, err := ParseParentCount(value)
+		return err
+	}
+
 	satisfies := func(fns ...func() error) error {
 		for _, fn := range fns {
 			if err := fn(); err != nil {
@@ -272,6 +296,15 @@ func validateField(field, value string, negated bool, seen map[string]struct{})
 		FieldCommitter,
 		FieldMessage:
 		return satisfies(isValidRegexp)
+	case
+		FieldTrailer:
+		return satisfies(isValidTrailer)
+	case
+		FieldSignature:
+		return satisfies(isValidSignatureStatus)
+	case
+		FieldParents:
+		return satisfies(isValidParentCount)
 	case
 		FieldIndex,
 		FieldFork,
@@ -283,6 +316,9 @@ func validateField(field, value string, negated bool, seen map[string]struct{})
 	case
 		FieldCombyRule:
 		return satisfies(isSingular, isNotNegated)
+	case
+		FieldStructuralEngine:
+		return satisfies(isSingular, isNotNegated, isValidStructuralEngine)
 	case
 		FieldTimeout:
 		return satisfies(isSingular, isNotNegated, isDuration)
@@ -339,7 +375,8 @@ func validateCommitParameters(nodes []Node) error {
 	var seenCommitParam string
 	var typeCommitExists bool
 	VisitParameter(nodes, func(field, value string, _ bool, _ Annotation) {
-		if field == FieldAuthor || field == FieldBefore || field == FieldAfter || field == FieldMessage {
+		switch field {
+		case FieldAuthor, FieldBefore, FieldAfter, FieldMessage, FieldTrailer, FieldSignature, FieldParents:
 			seenCommitParam = field
 		}
 		if field == FieldType && (value == "commit" || value == "diff") {
@@ -376,6 +413,27 @@ func validateTypeStructural(nodes []Node) error {
 	return nil
 }
 
+// StructuralEngines are the values of the engine: field, which selects the
+// engine that runs a structural search.
+var StructuralEngines = []string{"comby", "tree-sitter"}
+
+func validateStructuralEngine(nodes []Node) error {
+	seenStructural := false
+	seenEngine := false
+	VisitPattern(nodes, func(_ string, _ bool, annotation Annotation) {
+		if annotation.Labels.IsSet(Structural) {
+			seenStructural = true
+		}
+	})
+	VisitField(nodes, FieldStructuralEngine, func(_ string, _ bool, _ Annotation) {
+		seenEngine = true
+	})
+	if seenEngine && !seenStructural {
+		return errors.New("the field `engine:` is only supported for structural search patterns (patterntype:structural)")
+	}
+	return nil
+}
+
 func validateRefGlobs(nodes []Node) error {
 	if !ContainsRefGlobs(nodes) {
 		return nil
@@ -496,6 +554,7 @@ func validate(nodes []Node) error {
 		validateRepoHasFile,
 		validateCommitParameters,
 		validateTypeStructural,
+		validateStructuralEngine,
 		validateRefGlobs,
 	)
 }
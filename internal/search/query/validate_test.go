@@ -83,6 +83,22 @@ func TestValidation(t *testing.T) {
 			input: "repo:foo author:rob@saucegraph.com",
 			want:  `your query contains the field 'author', which requires type:commit or type:diff in the query`,
 		},
+		{
+			input: "repo:foo -trailer:Signed-off-by",
+			want:  `your query contains the field 'trailer', which requires type:commit or type:diff in the query`,
+		},
+		{
+			input: "type:commit trailer:Signed_off_by",
+			want:  `invalid trailer key "Signed_off_by". Trailer keys may only contain letters, digits and dashes (example: trailer:Signed-off-by)`,
+		},
+		{
+			input: "type:commit signature:maybe",
+			want:  `invalid value "maybe" for field "signature". Valid values are: signed, unsigned, valid, invalid, unverified`,
+		},
+		{
+			input: "type:commit parents:many",
+			want:  `invalid value "many" for field "parents" (examples: "parents:2", "parents:>1", "parents:<=1")`,
+		},
 		{
 			input: "repohasfile:README type:symbol yolo",
 			want:  "repohasfile is not compatible for type:symbol. Subscribe to https://github.com/sourcegraph/sourcegraph/issues/4610 for updates",
@@ -109,6 +125,15 @@ func TestValidation(t *testing.T) {
 			want:       "this structural search query specifies `type:` and is not supported. Structural search syntax only applies to searching file contents and is not currently supported for diff searches",
 			searchType: SearchTypeStructural,
 		},
+		{
+			input:      "engine:semgrep foo(:[args])",
+			want:       `invalid value "semgrep" for field "engine". Valid values are: comby, tree-sitter`,
+			searchType: SearchTypeStructural,
+		},
+		{
+			input: "engine:tree-sitter foo",
+			want:  "the field `engine:` is only supported for structural search patterns (patterntype:structural)",
+		},
 	}
 	for _, c := range cases {
 		t.Run("validate and/or query", func(t *testing.T) {
@@ -159,7 +159,32 @@ func (r *Resolver) Resolve(ctx context.Context, op search.RepoOptions) (Resolved
 		}
 	}
 
-	if (len(op.Dependencies) > 0 || len(op.Dependents) > 0) && len(dependencyNames) == 0 {
+	if len(op.HasVulnerabilities) > 0 {
+		vulnerableNames, err := r.vulnerableRepos(ctx, &op)
+		if err != nil {
+			return Resolved{}, err
+		}
+
+		if len(op.Dependencies) > 0 || len(op.Dependents) > 0 {
+			// Restrict the dependencies and dependents to the vulnerable repositories
+			vulnerableNamesSet := make(map[string]struct{}, len(vulnerableNames))
+			for _, name := range vulnerableNames {
+				vulnerableNamesSet[name] = struct{}{}
+			}
+
+			filtered := dependencyNames[:0]
+			for _, name := range dependencyNames {
+				if _, ok := vulnerableNamesSet[name]; ok {
+					filtered = append(filtered, name)
+				}
+			}
+			dependencyNames = filtered
+		} else {
+			dependencyNames = vulnerableNames
+		}
+	}
+
+	if (len(op.Dependencies) > 0 || len(op.Dependents) > 0 || len(op.HasVulnerabilities) > 0) && len(dependencyNames) == 0 {
 		return Resolved{}, ErrNoResolvedRepos
 	}
 
@@ -654,6 +679,29 @@ func (r *Resolver) dependents(ctx context.Context, op *search.RepoOptions) (_ []
 	return depNames, depRevs, nil
 }
 
+func (r *Resolver) vulnerableRepos(ctx context.Context, op *search.RepoOptions) (_ []string, err error) {
+	tr, ctx := trace.New(ctx, "searchrepos.vulnerableRepos", "")
+	defer func() {
+		tr.LazyPrintf("vulnerabilities: %v", op.HasVulnerabilities)
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	if !conf.DependeciesSearchEnabled() {
+		return nil, errors.Errorf("support for `repo:has.vulnerability()` is disabled in site config (`experimentalFeatures.dependenciesSearch`)")
+	}
+
+	// An empty argument matches any vulnerability and does not restrict the other arguments
+	vulnerabilityIDs := make([]string, 0, len(op.HasVulnerabilities))
+	for _, vulnerabilityID := range op.HasVulnerabilities {
+		if vulnerabilityID != "" {
+			vulnerabilityIDs = append(vulnerabilityIDs, vulnerabilityID)
+		}
+	}
+
+	return livedependencies.GetService(r.DB, livedependencies.NewSyncer()).VulnerableRepositoryNames(ctx, vulnerabilityIDs)
+}
+
 // ExactlyOneRepo returns whether exactly one repo: literal field is specified and
 // delineated by regex anchors ^ and $. This function helps determine whether we
 // should return results for a single repo regardless of whether it is a fork or
@@ -0,0 +1,54 @@
+package result
+
+import (
+	"github.com/sourcegraph/sourcegraph/internal/search/filter"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// AuthorMatch is an author of matched lines, as determined by git blame. It is
+// produced by select:content.author, which aggregates the matched lines of all
+// searched repositories by author.
+type AuthorMatch struct {
+	Name  string
+	Email string
+
+	// Count is the number of matched lines last modified by the author.
+	Count int
+}
+
+// RepoName returns an empty repository, as an author match aggregates lines
+// across repositories.
+func (a *AuthorMatch) RepoName() types.MinimalRepo {
+	return types.MinimalRepo{}
+}
+
+func (a *AuthorMatch) Limit(limit int) int {
+	// Always represents one result and limit > 0 so we just return limit - 1.
+	return limit - 1
+}
+
+func (a *AuthorMatch) ResultCount() int {
+	return 1
+}
+
+func (a *AuthorMatch) Select(path filter.SelectPath) Match {
+	if path.Root() == filter.Content && len(path) > 1 && path[1] == "author" {
+		return a
+	}
+	return nil
+}
+
+// Key returns a key that is the same for all matches of an author. Authors are
+// identified by email, or by name for commits without an author email.
+func (a *AuthorMatch) Key() Key {
+	author := a.Email
+	if author == "" {
+		author = a.Name
+	}
+	return Key{
+		TypeRank: rankAuthorMatch,
+		Author:   author,
+	}
+}
+
+func (a *AuthorMatch) searchResultMarker() {}
@@ -8,7 +8,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/types"
 )
 
-// Match is *FileMatch | *RepoMatch | *CommitMatch. We have a private method
+// Match is *FileMatch | *RepoMatch | *CommitMatch | *OwnerMatch | *AuthorMatch. We have a private method
 // to ensure only those types implement Match.
 type Match interface {
 	ResultCount() int
@@ -34,6 +34,8 @@ var (
 	_ Match = (*RepoMatch)(nil)
 	_ Match = (*CommitMatch)(nil)
 	_ Match = (*CommitDiffMatch)(nil)
+	_ Match = (*OwnerMatch)(nil)
+	_ Match = (*AuthorMatch)(nil)
 )
 
 // Match ranks are used for sorting the different match types.
@@ -44,6 +46,8 @@ const (
 	rankCommitMatch = 1
 	rankDiffMatch   = 2
 	rankRepoMatch   = 3
+	rankOwnerMatch  = 4
+	rankAuthorMatch = 5
 )
 
 // Key is a sorting or deduplicating key for a Match. It contains all the
@@ -74,6 +78,13 @@ type Key struct {
 	// Empty if there is no file associated with the match (e.g. RepoMatch or CommitMatch)
 	Path string
 
+	// Owner is the owner handle if this key is for an owner match.
+	Owner string
+
+	// Author is the author email, or name if they have no email, if this key
+	// is for an author match.
+	Author string
+
 	// TypeRank is the sorting rank of the type this key belongs to.
 	TypeRank int
 }
@@ -100,6 +111,14 @@ func (k Key) Less(other Key) bool {
 		return k.Path < other.Path
 	}
 
+	if k.Owner != other.Owner {
+		return k.Owner < other.Owner
+	}
+
+	if k.Author != other.Author {
+		return k.Author < other.Author
+	}
+
 	return k.TypeRank < other.TypeRank
 }
 
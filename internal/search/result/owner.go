@@ -0,0 +1,49 @@
+package result
+
+import (
+	"github.com/sourcegraph/sourcegraph/internal/codeowners"
+	"github.com/sourcegraph/sourcegraph/internal/search/filter"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// OwnerMatch is an owner of files in a repository, as declared in the
+// CODEOWNERS file of the repository. It is produced by select:file.owners.
+type OwnerMatch struct {
+	// Handle is the owner as written in the CODEOWNERS file, for example
+	// "@sourcegraph/search" or "alice@example.com".
+	Handle string
+
+	Repo types.MinimalRepo
+}
+
+func (o *OwnerMatch) RepoName() types.MinimalRepo {
+	return o.Repo
+}
+
+func (o *OwnerMatch) Limit(limit int) int {
+	// Always represents one result and limit > 0 so we just return limit - 1.
+	return limit - 1
+}
+
+func (o *OwnerMatch) ResultCount() int {
+	return 1
+}
+
+func (o *OwnerMatch) Select(path filter.SelectPath) Match {
+	if path.Root() == filter.File && len(path) > 1 && path[1] == "owners" {
+		return o
+	}
+	return nil
+}
+
+// Key returns a key that is the same for all matches of an owner in a
+// repository, such that an owner is only returned once per repository.
+func (o *OwnerMatch) Key() Key {
+	return Key{
+		Repo:     o.Repo.Name,
+		TypeRank: rankOwnerMatch,
+		Owner:    codeowners.NormalizeOwner(o.Handle),
+	}
+}
+
+func (o *OwnerMatch) searchResultMarker() {}
@@ -65,6 +65,7 @@ func Search(
 			IncludePatterns:              p.IncludePatterns,
 			Languages:                    p.Languages,
 			CombyRule:                    p.CombyRule,
+			StructuralEngine:             p.StructuralEngine,
 			PathPatternsAreRegExps:       true,
 			Select:                       p.Select.Root(),
 			Limit:                        int(p.FileMatchLimit),
@@ -81,6 +82,8 @@ func Search(
 		FetchTimeout:     fetchTimeout.String(),
 		IndexerEndpoints: indexerEndpoints,
 		FeatHybrid:       features.HybridSearch, // TODO(keegan) HACK because I didn't want to change the signatures to so many function calls.
+
+		FeatStructuralTreeSitter: features.StructuralTreeSitter,
 	}
 
 	body, err := json.Marshal(r)
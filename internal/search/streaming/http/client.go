@@ -127,6 +127,10 @@ func (r *eventMatchUnmarshaller) UnmarshalJSON(b []byte) error {
 		r.EventMatch = &EventSymbolMatch{}
 	case CommitMatchType:
 		r.EventMatch = &EventCommitMatch{}
+	case OwnerMatchType:
+		r.EventMatch = &EventOwnerMatch{}
+	case AuthorMatchType:
+		r.EventMatch = &EventAuthorMatch{}
 	default:
 		return errors.Errorf("unknown MatchType %v", typeU.Type)
 	}
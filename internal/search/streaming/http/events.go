@@ -157,6 +157,32 @@ type EventCommitMatch struct {
 
 func (e *EventCommitMatch) eventMatch() {}
 
+// EventOwnerMatch is an owner of files in a repository, as returned by
+// select:file.owners.
+type EventOwnerMatch struct {
+	// Type is always OwnerMatchType. Included here for marshalling.
+	Type MatchType `json:"type"`
+
+	Handle       string `json:"handle"`
+	RepositoryID int32  `json:"repositoryID"`
+	Repository   string `json:"repository"`
+}
+
+func (e *EventOwnerMatch) eventMatch() {}
+
+// EventAuthorMatch is an author of matched lines and the number of matched
+// lines they last modified, as returned by select:content.author.
+type EventAuthorMatch struct {
+	// Type is always AuthorMatchType. Included here for marshalling.
+	Type MatchType `json:"type"`
+
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Count int    `json:"count"`
+}
+
+func (e *EventAuthorMatch) eventMatch() {}
+
 // EventFilter is a suggestion for a search filter. Currently has a 1-1
 // correspondance with the SearchFilter graphql type.
 type EventFilter struct {
@@ -196,6 +222,8 @@ const (
 	SymbolMatchType
 	CommitMatchType
 	PathMatchType
+	OwnerMatchType
+	AuthorMatchType
 )
 
 func (t MatchType) MarshalJSON() ([]byte, error) {
@@ -210,6 +238,10 @@ func (t MatchType) MarshalJSON() ([]byte, error) {
 		return []byte(`"commit"`), nil
 	case PathMatchType:
 		return []byte(`"path"`), nil
+	case OwnerMatchType:
+		return []byte(`"owner"`), nil
+	case AuthorMatchType:
+		return []byte(`"author"`), nil
 	default:
 		return nil, errors.Errorf("unknown MatchType: %d", t)
 	}
@@ -226,6 +258,10 @@ func (t *MatchType) UnmarshalJSON(b []byte) error {
 		*t = CommitMatchType
 	} else if bytes.Equal(b, []byte(`"path"`)) {
 		*t = PathMatchType
+	} else if bytes.Equal(b, []byte(`"owner"`)) {
+		*t = OwnerMatchType
+	} else if bytes.Equal(b, []byte(`"author"`)) {
+		*t = AuthorMatchType
 	} else {
 		return errors.Errorf("unknown MatchType: %s", b)
 	}
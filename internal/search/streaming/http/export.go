@@ -0,0 +1,322 @@
+package http
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// ExportFormat is a file format that search results can be exported to.
+type ExportFormat string
+
+const (
+	ExportFormatCSV   ExportFormat = "csv"
+	ExportFormatJSONL ExportFormat = "jsonl"
+)
+
+// ParseExportFormat returns the export format with the given name.
+func ParseExportFormat(name string) (ExportFormat, error) {
+	switch format := ExportFormat(strings.ToLower(name)); format {
+	case ExportFormatCSV, ExportFormatJSONL:
+		return format, nil
+	default:
+		return "", errors.Errorf("unknown export format %q: must be %q or %q", name, ExportFormatCSV, ExportFormatJSONL)
+	}
+}
+
+// ContentType returns the MIME type of files in the export format.
+func (f ExportFormat) ContentType() string {
+	if f == ExportFormatJSONL {
+		return "application/x-ndjson"
+	}
+	return "text/csv"
+}
+
+// Extension returns the file extension of files in the export format.
+func (f ExportFormat) Extension() string {
+	return "." + string(f)
+}
+
+// ExportColumns are the columns that can be included in an export. Each exported row
+// describes a single matched line, symbol, path, repository, commit, owner, or author;
+// columns that do not apply to a match are left empty.
+var ExportColumns = []string{
+	"type",
+	"repository",
+	"branch",
+	"commit",
+	"path",
+	"line",
+	"content",
+	"symbol",
+	"symbolKind",
+	"symbolContainer",
+	"owner",
+	"author",
+	"email",
+	"count",
+	"date",
+	"message",
+	"url",
+}
+
+// DefaultExportColumns are the columns exported when none are requested.
+var DefaultExportColumns = []string{"type", "repository", "commit", "path", "line", "content", "symbol"}
+
+// ParseExportColumns parses a comma-separated list of export columns. An empty list selects
+// DefaultExportColumns.
+func ParseExportColumns(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultExportColumns, nil
+	}
+
+	known := make(map[string]struct{}, len(ExportColumns))
+	for _, column := range ExportColumns {
+		known[column] = struct{}{}
+	}
+
+	var columns []string
+	for _, column := range strings.Split(value, ",") {
+		column = strings.TrimSpace(column)
+		if _, ok := known[column]; !ok {
+			return nil, errors.Errorf("unknown export column %q: must be one of %s", column, strings.Join(ExportColumns, ", "))
+		}
+		columns = append(columns, column)
+	}
+
+	return columns, nil
+}
+
+// ExportWriter writes search matches as rows of a CSV or JSON Lines file.
+type ExportWriter struct {
+	format  ExportFormat
+	columns []string
+
+	w          *bufio.Writer
+	csv        *csv.Writer
+	wroteFirst bool
+
+	// Rows is the number of rows written so far.
+	Rows int
+}
+
+func NewExportWriter(w io.Writer, format ExportFormat, columns []string) *ExportWriter {
+	bw := bufio.NewWriter(w)
+
+	e := &ExportWriter{
+		format:  format,
+		columns: columns,
+		w:       bw,
+	}
+	if format == ExportFormatCSV {
+		e.csv = csv.NewWriter(bw)
+	}
+
+	return e
+}
+
+// Write writes the rows of the given match.
+func (e *ExportWriter) Write(match EventMatch) error {
+	if !e.wroteFirst {
+		e.wroteFirst = true
+
+		if e.csv != nil {
+			if err := e.csv.Write(e.columns); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, row := range exportRows(match) {
+		if err := e.writeRow(row); err != nil {
+			return err
+		}
+		e.Rows++
+	}
+
+	return nil
+}
+
+func (e *ExportWriter) writeRow(row exportRow) error {
+	if e.csv != nil {
+		record := make([]string, 0, len(e.columns))
+		for _, column := range e.columns {
+			record = append(record, row.string(column))
+		}
+		return e.csv.Write(record)
+	}
+
+	object := make(map[string]any, len(e.columns))
+	for _, column := range e.columns {
+		object[column] = row[column]
+	}
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	if _, err := e.w.Write(encoded); err != nil {
+		return err
+	}
+	return e.w.WriteByte('\n')
+}
+
+// Flush writes any buffered rows to the underlying writer. A CSV export without any matches
+// consists of the header row only.
+func (e *ExportWriter) Flush() error {
+	if !e.wroteFirst && e.csv != nil {
+		e.wroteFirst = true
+
+		if err := e.csv.Write(e.columns); err != nil {
+			return err
+		}
+	}
+
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+
+	return e.w.Flush()
+}
+
+// exportRow maps column names to values. Absent columns are exported as empty values in CSV
+// and as null in JSON Lines.
+type exportRow map[string]any
+
+func (r exportRow) string(column string) string {
+	switch v := r[column].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+func exportRows(match EventMatch) []exportRow {
+	switch v := match.(type) {
+	case *EventContentMatch:
+		base := exportRow{
+			"type":       "content",
+			"repository": v.Repository,
+			"branch":     firstBranch(v.Branches),
+			"commit":     v.Commit,
+			"path":       v.Path,
+		}
+
+		var rows []exportRow
+		for _, lm := range v.LineMatches {
+			rows = append(rows, base.with(exportRow{
+				"line":    int(lm.LineNumber) + 1,
+				"content": lm.Line,
+			}))
+		}
+		for _, cm := range v.ChunkMatches {
+			rows = append(rows, base.with(exportRow{
+				"line":    cm.ContentStart.Line + 1,
+				"content": cm.Content,
+			}))
+		}
+		if len(rows) == 0 {
+			rows = append(rows, base)
+		}
+		return rows
+
+	case *EventPathMatch:
+		return []exportRow{{
+			"type":       "path",
+			"repository": v.Repository,
+			"branch":     firstBranch(v.Branches),
+			"commit":     v.Commit,
+			"path":       v.Path,
+		}}
+
+	case *EventSymbolMatch:
+		base := exportRow{
+			"type":       "symbol",
+			"repository": v.Repository,
+			"branch":     firstBranch(v.Branches),
+			"commit":     v.Commit,
+			"path":       v.Path,
+		}
+
+		rows := make([]exportRow, 0, len(v.Symbols))
+		for _, symbol := range v.Symbols {
+			rows = append(rows, base.with(exportRow{
+				"symbol":          symbol.Name,
+				"symbolKind":      symbol.Kind,
+				"symbolContainer": symbol.ContainerName,
+				"url":             symbol.URL,
+			}))
+		}
+		return rows
+
+	case *EventRepoMatch:
+		return []exportRow{{
+			"type":       "repo",
+			"repository": v.Repository,
+			"branch":     firstBranch(v.Branches),
+		}}
+
+	case *EventCommitMatch:
+		return []exportRow{{
+			"type":       "commit",
+			"repository": v.Repository,
+			"commit":     v.OID,
+			"content":    v.Content,
+			"author":     v.AuthorName,
+			"date":       v.AuthorDate,
+			"message":    v.Message,
+			"url":        v.URL,
+		}}
+
+	case *EventOwnerMatch:
+		return []exportRow{{
+			"type":       "owner",
+			"repository": v.Repository,
+			"owner":      v.Handle,
+		}}
+
+	case *EventAuthorMatch:
+		return []exportRow{{
+			"type":   "author",
+			"author": v.Name,
+			"email":  v.Email,
+			"count":  v.Count,
+		}}
+	}
+
+	return nil
+}
+
+func (r exportRow) with(other exportRow) exportRow {
+	merged := make(exportRow, len(r)+len(other))
+	for k, v := range r {
+		merged[k] = v
+	}
+	for k, v := range other {
+		merged[k] = v
+	}
+	return merged
+}
+
+func firstBranch(branches []string) any {
+	if len(branches) == 0 {
+		return nil
+	}
+	return branches[0]
+}
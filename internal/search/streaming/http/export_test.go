@@ -0,0 +1,112 @@
+package http
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportWriter(t *testing.T) {
+	t.Parallel()
+
+	matches := []EventMatch{
+		&EventContentMatch{
+			Type:       ContentMatchType,
+			Path:       "main.go",
+			Repository: "github.com/sourcegraph/sourcegraph",
+			Commit:     "deadbeef",
+			LineMatches: []EventLineMatch{
+				{Line: "func main() {", LineNumber: 9},
+				{Line: `	fmt.Println("hello, world")`, LineNumber: 10},
+			},
+		},
+		&EventSymbolMatch{
+			Type:       SymbolMatchType,
+			Path:       "search.go",
+			Repository: "github.com/sourcegraph/sourcegraph",
+			Branches:   []string{"main"},
+			Commit:     "deadbeef",
+			Symbols: []Symbol{
+				{Name: "Search", ContainerName: "Client", Kind: "METHOD"},
+			},
+		},
+		&EventRepoMatch{
+			Type:       RepoMatchType,
+			Repository: "github.com/sourcegraph/zoekt",
+		},
+		&EventCommitMatch{
+			Type:       CommitMatchType,
+			Repository: "github.com/sourcegraph/zoekt",
+			OID:        "cafebabe",
+			Message:    "fix index",
+			AuthorName: "alice",
+			AuthorDate: time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC),
+		},
+		&EventAuthorMatch{
+			Type:  AuthorMatchType,
+			Name:  "alice",
+			Email: "alice@example.com",
+			Count: 3,
+		},
+	}
+
+	write := func(format ExportFormat, columns []string) (string, int) {
+		var buf bytes.Buffer
+		w := NewExportWriter(&buf, format, columns)
+		for _, match := range matches {
+			require.NoError(t, w.Write(match))
+		}
+		require.NoError(t, w.Flush())
+		return buf.String(), w.Rows
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		got, rows := write(ExportFormatCSV, DefaultExportColumns)
+		require.Equal(t, 6, rows)
+		require.Equal(t, ``+
+			"type,repository,commit,path,line,content,symbol\n"+
+			"content,github.com/sourcegraph/sourcegraph,deadbeef,main.go,10,func main() {,\n"+
+			"content,github.com/sourcegraph/sourcegraph,deadbeef,main.go,11,\"\tfmt.Println(\"\"hello, world\"\")\",\n"+
+			"symbol,github.com/sourcegraph/sourcegraph,deadbeef,search.go,,,Search\n"+
+			"repo,github.com/sourcegraph/zoekt,,,,,\n"+
+			"commit,github.com/sourcegraph/zoekt,cafebabe,,,,\n"+
+			"author,,,,,,\n",
+			got)
+	})
+
+	t.Run("JSONL", func(t *testing.T) {
+		got, _ := write(ExportFormatJSONL, []string{"type", "branch", "line", "date"})
+		require.Equal(t, ``+
+			`{"branch":null,"date":null,"line":10,"type":"content"}`+"\n"+
+			`{"branch":null,"date":null,"line":11,"type":"content"}`+"\n"+
+			`{"branch":"main","date":null,"line":null,"type":"symbol"}`+"\n"+
+			`{"branch":null,"date":null,"line":null,"type":"repo"}`+"\n"+
+			`{"branch":null,"date":"2022-06-01T12:00:00Z","line":null,"type":"commit"}`+"\n"+
+			`{"branch":null,"date":null,"line":null,"type":"author"}`+"\n",
+			got)
+	})
+
+	t.Run("Empty CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewExportWriter(&buf, ExportFormatCSV, []string{"repository", "path"})
+		require.NoError(t, w.Flush())
+		require.Equal(t, "repository,path\n", buf.String())
+	})
+}
+
+func TestParseExportColumns(t *testing.T) {
+	t.Parallel()
+
+	columns, err := ParseExportColumns("")
+	require.NoError(t, err)
+	require.Equal(t, DefaultExportColumns, columns)
+
+	columns, err = ParseExportColumns("repository, path,line")
+	require.NoError(t, err)
+	require.Equal(t, []string{"repository", "path", "line"}, columns)
+
+	_, err = ParseExportColumns("repository,stars")
+	require.Error(t, err)
+}
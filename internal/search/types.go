@@ -175,6 +175,10 @@ type TextPatternInfo struct {
 	PatternMatchesPath    bool
 
 	Languages []string
+
+	// StructuralEngine is the engine searcher runs structural searches
+	// with. See protocol.PatternInfo.
+	StructuralEngine string
 }
 
 func (p *TextPatternInfo) String() string {
@@ -188,6 +192,9 @@ func (p *TextPatternInfo) String() string {
 		} else {
 			args = append(args, "comby")
 		}
+		if p.StructuralEngine != "" {
+			args = append(args, fmt.Sprintf("engine:%s", p.StructuralEngine))
+		}
 	}
 	if p.IsWordMatch {
 		args = append(args, "word")
@@ -250,6 +257,11 @@ type Features struct {
 	// unindexed searches. Searcher (unindexed search) will the only search
 	// what has changed since the indexed commit.
 	HybridSearch bool
+
+	// StructuralTreeSitter when true will run structural searches with the
+	// in-process tree-sitter engine of searcher instead of comby, unless the
+	// search uses a rule or a hole syntax that only comby supports.
+	StructuralTreeSitter bool
 }
 
 type RepoOptions struct {
@@ -258,6 +270,10 @@ type RepoOptions struct {
 	Dependencies     []string
 	Dependents       []string
 
+	// HasVulnerabilities holds the arguments of `repo:has.vulnerability()` predicates. An
+	// empty argument matches repositories affected by any vulnerability.
+	HasVulnerabilities []string
+
 	CaseSensitiveRepoFilters bool
 	SearchContextSpec        string
 
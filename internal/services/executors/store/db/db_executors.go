@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
 
 	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
 	"github.com/sourcegraph/sourcegraph/internal/services/executors/store"
@@ -27,6 +28,7 @@ func scanExecutors(rows *sql.Rows, queryErr error) (_ []types.Executor, err erro
 			&executor.ID,
 			&executor.Hostname,
 			&executor.QueueName,
+			pq.Array(&executor.QueueNames),
 			&executor.OS,
 			&executor.Architecture,
 			&executor.DockerVersion,
@@ -105,6 +107,7 @@ SELECT
 	h.id,
 	h.hostname,
 	h.queue_name,
+	h.queue_names,
 	h.os,
 	h.architecture,
 	h.docker_version,
@@ -125,6 +128,7 @@ func makeExecutorSearchCondition(term string) *sqlf.Query {
 	searchableColumns := []string{
 		"h.hostname",
 		"h.queue_name",
+		"array_to_string(h.queue_names, ',')",
 		"h.os",
 		"h.architecture",
 		"h.docker_version",
@@ -152,6 +156,7 @@ SELECT
 	h.id,
 	h.hostname,
 	h.queue_name,
+	h.queue_names,
 	h.os,
 	h.architecture,
 	h.docker_version,
@@ -175,6 +180,7 @@ SELECT
 	h.id,
 	h.hostname,
 	h.queue_name,
+	h.queue_names,
 	h.os,
 	h.architecture,
 	h.docker_version,
@@ -199,6 +205,7 @@ func (s *ExecutorStore) upsertHeartbeat(ctx context.Context, executor types.Exec
 		// insert
 		executor.Hostname,
 		executor.QueueName,
+		pq.Array(executor.QueueNames),
 		executor.OS,
 		executor.Architecture,
 		executor.DockerVersion,
@@ -211,6 +218,7 @@ func (s *ExecutorStore) upsertHeartbeat(ctx context.Context, executor types.Exec
 
 		// update
 		executor.QueueName,
+		pq.Array(executor.QueueNames),
 		executor.OS,
 		executor.Architecture,
 		executor.DockerVersion,
@@ -227,6 +235,7 @@ const executorStoreUpsertHeartbeatQuery = `
 INSERT INTO executor_heartbeats (
 	hostname,
 	queue_name,
+	queue_names,
 	os,
 	architecture,
 	docker_version,
@@ -237,10 +246,11 @@ INSERT INTO executor_heartbeats (
 	first_seen_at,
 	last_seen_at
 )
-VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
 ON CONFLICT (hostname) DO UPDATE
 SET
 	queue_name = %s,
+	queue_names = %s,
 	os = %s,
 	architecture = %s,
 	docker_version = %s,
@@ -152,6 +152,7 @@ func TestExecutorsGetByID(t *testing.T) {
 		ID:              1,
 		Hostname:        "test-hostname",
 		QueueName:       "test-queue-name",
+		QueueNames:      []string{"test-queue-name"},
 		OS:              "test-os",
 		Architecture:    "test-architecture",
 		DockerVersion:   "test-docker-version",
@@ -169,6 +170,7 @@ func TestExecutorsGetByID(t *testing.T) {
 	}
 
 	expected.QueueName += "-changed"
+	expected.QueueNames = append(expected.QueueNames, "test-other-queue-name")
 	expected.OS += "-changed"
 	expected.Architecture += "-changed"
 	expected.DockerVersion += "-changed"
@@ -24,6 +24,13 @@ func (e *ExecutorResolver) ID() graphql.ID {
 }
 func (e *ExecutorResolver) Hostname() string  { return e.executor.Hostname }
 func (e *ExecutorResolver) QueueName() string { return e.executor.QueueName }
+func (e *ExecutorResolver) QueueNames() []string {
+	// Executors that poll a single queue may not report their queue names.
+	if len(e.executor.QueueNames) == 0 {
+		return []string{e.executor.QueueName}
+	}
+	return e.executor.QueueNames
+}
 func (e *ExecutorResolver) Active() bool {
 	// TODO: Read the value of the executor worker heartbeat interval in here.
 	heartbeatInterval := 5 * time.Second
@@ -47,6 +47,8 @@ func (e *ExternalService) RedactedConfig() (string, error) {
 		es.redactString(c.Token, "token")
 	case *schema.BitbucketCloudConnection:
 		es.redactString(c.AppPassword, "appPassword")
+	case *schema.AzureDevOpsConnection:
+		es.redactString(c.Token, "token")
 	case *schema.AWSCodeCommitConnection:
 		es.redactString(c.SecretAccessKey, "secretAccessKey")
 		es.redactString(c.GitCredentials.Password, "gitCredentials", "password")
@@ -70,6 +72,8 @@ func (e *ExternalService) RedactedConfig() (string, error) {
 				return "", err
 			}
 		}
+	case *schema.RubyPackagesConnection:
+		err = es.redactURL(c.Repository, "repository")
 	case *schema.RustPackagesConnection:
 		// Nothing to redact
 	case *schema.JVMPackagesConnection:
@@ -136,6 +140,9 @@ func (e *ExternalService) UnredactConfig(old *ExternalService) error {
 	case *schema.BitbucketCloudConnection:
 		o := oldCfg.(*schema.BitbucketCloudConnection)
 		es.unredactString(c.AppPassword, o.AppPassword, "appPassword")
+	case *schema.AzureDevOpsConnection:
+		o := oldCfg.(*schema.AzureDevOpsConnection)
+		es.unredactString(c.Token, o.Token, "token")
 	case *schema.AWSCodeCommitConnection:
 		o := oldCfg.(*schema.AWSCodeCommitConnection)
 		es.unredactString(c.SecretAccessKey, o.SecretAccessKey, "secretAccessKey")
@@ -158,6 +165,11 @@ func (e *ExternalService) UnredactConfig(old *ExternalService) error {
 		if err != nil {
 			return err
 		}
+	case *schema.RubyPackagesConnection:
+		err = es.unredactURL(c.Repository, oldCfg.(*schema.RubyPackagesConnection).Repository, "repository")
+		if err != nil {
+			return err
+		}
 	case *schema.RustPackagesConnection:
 		// Nothing to unredact
 	case *schema.JVMPackagesConnection:
@@ -14,6 +14,7 @@ type Config struct {
 	TTL          time.Duration
 	S3           S3Config
 	GCS          GCSConfig
+	Filesystem   FilesystemConfig
 }
 
 func normalizeConfig(t Config) Config {
@@ -21,8 +22,8 @@ func normalizeConfig(t Config) Config {
 	// Normalize the backend name.
 	o.Backend = strings.ToLower(o.Backend)
 
-	if o.Backend == "minio" {
-		// No manual provisioning on minIO.
+	if o.Backend == "minio" || o.Backend == "filesystem" {
+		// No manual provisioning on minIO or the local filesystem.
 		o.ManageBucket = true
 	}
 	return o
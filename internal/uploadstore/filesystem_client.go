@@ -0,0 +1,262 @@
+package uploadstore
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+type filesystemStore struct {
+	dir          string
+	ttl          time.Duration
+	manageBucket bool
+	operations   *Operations
+}
+
+var _ Store = &filesystemStore{}
+
+type FilesystemConfig struct {
+	// Root is the directory under which a directory for each bucket is created.
+	Root string
+}
+
+// filesystemExpirationInterval is the interval between scans of the target directory
+// for objects that have exceeded the configured TTL.
+const filesystemExpirationInterval = time.Hour
+
+// newFilesystemFromConfig creates a new store backed by a directory on the local disk.
+func newFilesystemFromConfig(ctx context.Context, config Config, operations *Operations) (Store, error) {
+	if config.Filesystem.Root == "" {
+		return nil, errors.New("no root directory configured for filesystem upload store")
+	}
+
+	return newFilesystemWithDir(filepath.Join(config.Filesystem.Root, config.Bucket), config.TTL, config.ManageBucket, operations), nil
+}
+
+func newFilesystemWithDir(dir string, ttl time.Duration, manageBucket bool, operations *Operations) *filesystemStore {
+	return &filesystemStore{
+		dir:          dir,
+		ttl:          ttl,
+		manageBucket: manageBucket,
+		operations:   operations,
+	}
+}
+
+func (s *filesystemStore) Init(ctx context.Context) error {
+	if !s.manageBucket {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.dir, os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create directory")
+	}
+
+	if s.ttl > 0 {
+		// There is no lifecycle configuration for a plain directory, so we emulate
+		// the bucket lifecycle rules of the other backends with a background task.
+		go goroutine.NewPeriodicGoroutine(
+			context.Background(),
+			filesystemExpirationInterval,
+			goroutine.NewHandlerWithErrorMessage("expire filesystem upload store objects", s.expireObjects),
+		).Start()
+	}
+
+	return nil
+}
+
+func (s *filesystemStore) Get(ctx context.Context, key string) (_ io.ReadCloser, err error) {
+	ctx, _, endObservation := s.operations.Get.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("key", key),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get object")
+	}
+
+	return f, nil
+}
+
+func (s *filesystemStore) Upload(ctx context.Context, key string, r io.Reader) (_ int64, err error) {
+	ctx, _, endObservation := s.operations.Upload.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("key", key),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	n, err := s.writeAtomically(key, func(w io.Writer) (int64, error) {
+		return io.Copy(w, r)
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to upload object")
+	}
+
+	return n, nil
+}
+
+func (s *filesystemStore) Compose(ctx context.Context, destination string, sources ...string) (_ int64, err error) {
+	ctx, _, endObservation := s.operations.Compose.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("destination", destination),
+		log.String("sources", strings.Join(sources, ", ")),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	defer func() {
+		if err == nil {
+			// Delete sources on success
+			if err := s.deleteSources(sources); err != nil {
+				log15.Error("Failed to delete source objects", "error", err)
+			}
+		}
+	}()
+
+	n, err := s.writeAtomically(destination, func(w io.Writer) (int64, error) {
+		var total int64
+		for _, source := range sources {
+			n, err := s.copyObject(w, source)
+			if err != nil {
+				return 0, err
+			}
+
+			total += n
+		}
+
+		return total, nil
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to compose objects")
+	}
+
+	return n, nil
+}
+
+func (s *filesystemStore) Delete(ctx context.Context, key string) (err error) {
+	ctx, _, endObservation := s.operations.Delete.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("key", key),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	return errors.Wrap(s.remove(key), "failed to delete object")
+}
+
+// expireObjects removes all objects from the target directory that were last modified
+// before the configured TTL.
+func (s *filesystemStore) expireObjects(ctx context.Context) error {
+	threshold := time.Now().Add(-s.ttl)
+
+	return filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				// Deleted concurrently
+				return nil
+			}
+
+			return err
+		}
+
+		if info.ModTime().Before(threshold) {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return errors.Wrap(err, "failed to delete expired object")
+			}
+		}
+
+		return nil
+	})
+}
+
+// writeAtomically invokes the given function with a temporary file in the target directory
+// and moves the file to the path of the given key once the function returns successfully.
+// Readers of the key will never observe a partially written object.
+func (s *filesystemStore) writeAtomically(key string, fn func(w io.Writer) (int64, error)) (_ int64, err error) {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	n, err := fn(tmp)
+	if err != nil {
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// copyObject writes the content of the object at the given key into the given writer.
+func (s *filesystemStore) copyObject(w io.Writer, key string) (int64, error) {
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return io.Copy(w, f)
+}
+
+func (s *filesystemStore) deleteSources(sources []string) error {
+	return goroutine.RunWorkersOverStrings(sources, func(index int, source string) error {
+		if err := s.remove(source); err != nil {
+			return errors.Wrap(err, "failed to delete source object")
+		}
+
+		return nil
+	})
+}
+
+// remove deletes the file backing the given key. Deleting a key that does not exist is not
+// an error, matching the behavior of the blob store backends.
+func (s *filesystemStore) remove(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// path returns the path of the file backing the given key. Keys are cleaned as if they
+// were rooted so that they cannot refer to a file outside of the target directory.
+func (s *filesystemStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.Clean("/"+key))
+}
@@ -0,0 +1,208 @@
+package uploadstore
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+func TestFilesystemInit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "test-bucket")
+
+	client := testFilesystemClient(dir, true)
+	if err := client.Init(context.Background()); err != nil {
+		t.Fatalf("unexpected error initializing client: %s", err)
+	}
+
+	if info, err := os.Stat(dir); err != nil {
+		t.Fatalf("unexpected error statting directory: %s", err)
+	} else if !info.IsDir() {
+		t.Errorf("expected %s to be a directory", dir)
+	}
+}
+
+func TestFilesystemInitUnmanaged(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "test-bucket")
+
+	client := testFilesystemClient(dir, false)
+	if err := client.Init(context.Background()); err != nil {
+		t.Fatalf("unexpected error initializing client: %s", err)
+	}
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("unexpected directory. want=%v have=%v", os.ErrNotExist, err)
+	}
+}
+
+func TestFilesystemUploadAndGet(t *testing.T) {
+	client := testFilesystemClient(t.TempDir(), true)
+
+	size, err := client.Upload(context.Background(), "test-key", bytes.NewReader([]byte("TEST PAYLOAD")))
+	if err != nil {
+		t.Fatalf("unexpected error uploading object: %s", err)
+	}
+	if size != 12 {
+		t.Errorf("unexpected size. want=%d have=%d", 12, size)
+	}
+
+	rc, err := client.Get(context.Background(), "test-key")
+	if err != nil {
+		t.Fatalf("unexpected error getting object: %s", err)
+	}
+	defer rc.Close()
+
+	contents, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("unexpected error reading object: %s", err)
+	}
+	if string(contents) != "TEST PAYLOAD" {
+		t.Errorf("unexpected contents. want=%s have=%s", "TEST PAYLOAD", contents)
+	}
+}
+
+func TestFilesystemGetMissing(t *testing.T) {
+	client := testFilesystemClient(t.TempDir(), true)
+
+	if _, err := client.Get(context.Background(), "test-key"); err == nil {
+		t.Fatalf("expected error getting missing object")
+	}
+}
+
+func TestFilesystemKeyTraversal(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "test-bucket")
+
+	client := testFilesystemClient(dir, true)
+	if _, err := client.Upload(context.Background(), "../outside", bytes.NewReader([]byte("TEST PAYLOAD"))); err != nil {
+		t.Fatalf("unexpected error uploading object: %s", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "outside")); !os.IsNotExist(err) {
+		t.Errorf("expected object to be written within the target directory")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "outside")); err != nil {
+		t.Errorf("unexpected error statting object: %s", err)
+	}
+}
+
+func TestFilesystemCompose(t *testing.T) {
+	client := testFilesystemClient(t.TempDir(), true)
+
+	for key, payload := range map[string]string{
+		"test-src1": "FOO",
+		"test-src2": "BAR",
+		"test-src3": "BAZ",
+	} {
+		if _, err := client.Upload(context.Background(), key, bytes.NewReader([]byte(payload))); err != nil {
+			t.Fatalf("unexpected error uploading object: %s", err)
+		}
+	}
+
+	size, err := client.Compose(context.Background(), "test-key", "test-src1", "test-src2", "test-src3")
+	if err != nil {
+		t.Fatalf("unexpected error composing objects: %s", err)
+	}
+	if size != 9 {
+		t.Errorf("unexpected size. want=%d have=%d", 9, size)
+	}
+
+	rc, err := client.Get(context.Background(), "test-key")
+	if err != nil {
+		t.Fatalf("unexpected error getting object: %s", err)
+	}
+	defer rc.Close()
+
+	contents, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("unexpected error reading object: %s", err)
+	}
+	if string(contents) != "FOOBARBAZ" {
+		t.Errorf("unexpected contents. want=%s have=%s", "FOOBARBAZ", contents)
+	}
+
+	for _, key := range []string{"test-src1", "test-src2", "test-src3"} {
+		if _, err := client.Get(context.Background(), key); err == nil {
+			t.Errorf("expected source object %s to be deleted", key)
+		}
+	}
+}
+
+func TestFilesystemComposeMissingSource(t *testing.T) {
+	client := testFilesystemClient(t.TempDir(), true)
+
+	if _, err := client.Upload(context.Background(), "test-src1", bytes.NewReader([]byte("FOO"))); err != nil {
+		t.Fatalf("unexpected error uploading object: %s", err)
+	}
+
+	if _, err := client.Compose(context.Background(), "test-key", "test-src1", "test-src2"); err == nil {
+		t.Fatalf("expected error composing objects")
+	}
+
+	if _, err := client.Get(context.Background(), "test-key"); err == nil {
+		t.Errorf("expected destination object to not exist")
+	}
+	if _, err := client.Get(context.Background(), "test-src1"); err != nil {
+		t.Errorf("expected source object to be retained on failure: %s", err)
+	}
+}
+
+func TestFilesystemDelete(t *testing.T) {
+	client := testFilesystemClient(t.TempDir(), true)
+
+	if _, err := client.Upload(context.Background(), "test-key", bytes.NewReader([]byte("TEST PAYLOAD"))); err != nil {
+		t.Fatalf("unexpected error uploading object: %s", err)
+	}
+
+	if err := client.Delete(context.Background(), "test-key"); err != nil {
+		t.Fatalf("unexpected error deleting object: %s", err)
+	}
+	if _, err := client.Get(context.Background(), "test-key"); err == nil {
+		t.Errorf("expected object to be deleted")
+	}
+
+	// Deleting a missing object is not an error
+	if err := client.Delete(context.Background(), "test-key"); err != nil {
+		t.Fatalf("unexpected error deleting object: %s", err)
+	}
+}
+
+func TestFilesystemExpireObjects(t *testing.T) {
+	dir := t.TempDir()
+	client := rawFilesystemClient(dir, true)
+
+	for _, key := range []string{"test-old", "test-new"} {
+		if _, err := client.Upload(context.Background(), key, bytes.NewReader([]byte("TEST PAYLOAD"))); err != nil {
+			t.Fatalf("unexpected error uploading object: %s", err)
+		}
+	}
+
+	old := time.Now().Add(-time.Hour * 24 * 4)
+	if err := os.Chtimes(filepath.Join(dir, "test-old"), old, old); err != nil {
+		t.Fatalf("unexpected error changing modification time: %s", err)
+	}
+
+	if err := client.expireObjects(context.Background()); err != nil {
+		t.Fatalf("unexpected error expiring objects: %s", err)
+	}
+
+	if _, err := client.Get(context.Background(), "test-old"); err == nil {
+		t.Errorf("expected expired object to be deleted")
+	}
+	if _, err := client.Get(context.Background(), "test-new"); err != nil {
+		t.Errorf("unexpected error getting unexpired object: %s", err)
+	}
+}
+
+func testFilesystemClient(dir string, manageBucket bool) Store {
+	return newLazyStore(rawFilesystemClient(dir, manageBucket))
+}
+
+func rawFilesystemClient(dir string, manageBucket bool) *filesystemStore {
+	return newFilesystemWithDir(dir, time.Hour*24*3, manageBucket, NewOperations(&observation.TestContext, "test", "brittlestore"))
+}
@@ -175,6 +175,11 @@ func (s *gcsStore) update(ctx context.Context, bucket gcsBucketHandle) error {
 }
 
 func (s *gcsStore) lifecycle() storage.Lifecycle {
+	if s.ttl <= 0 {
+		// Objects do not expire.
+		return storage.Lifecycle{}
+	}
+
 	return storage.Lifecycle{
 		Rules: []storage.LifecycleRule{
 			{
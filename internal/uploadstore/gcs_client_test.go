@@ -257,6 +257,11 @@ func TestGCSLifecycle(t *testing.T) {
 	} else if value := lifecycle.Rules[0].Condition.AgeInDays; value != 3 {
 		t.Errorf("unexpected expiration days. want=%d have=%d", 3, value)
 	}
+
+	client = newGCSWithClient(nil, "test-bucket", 0, true, GCSConfig{ProjectID: "pid"}, NewOperations(&observation.TestContext, "test", "brittlestore"))
+	if lifecycle := client.lifecycle(); len(lifecycle.Rules) != 0 {
+		t.Errorf("unexpected lifecycle rules without ttl")
+	}
 }
 
 func testGCSClient(client gcsAPI, manageBucket bool) Store {
@@ -284,6 +284,11 @@ func (s *s3Store) create(ctx context.Context) error {
 }
 
 func (s *s3Store) update(ctx context.Context) error {
+	if s.bucketLifecycleConfiguration == nil {
+		// Objects do not expire.
+		return nil
+	}
+
 	configureRequest := &s3.PutBucketLifecycleConfigurationInput{
 		Bucket:                 aws.String(s.bucket),
 		LifecycleConfiguration: s.bucketLifecycleConfiguration,
@@ -360,7 +365,13 @@ func isConnectionResetError(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "read: connection reset by peer")
 }
 
+// s3BucketLifecycleConfiguration returns the lifecycle configuration that expires objects
+// after the given TTL, or nil if the TTL is zero and objects should never expire.
 func s3BucketLifecycleConfiguration(backend string, ttl time.Duration) *s3types.BucketLifecycleConfiguration {
+	if ttl <= 0 {
+		return nil
+	}
+
 	days := int32(ttl / (time.Hour * 24))
 
 	rules := []s3types.LifecycleRule{
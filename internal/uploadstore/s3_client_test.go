@@ -403,6 +403,10 @@ func TestS3BucketLifecycleConfiguration(t *testing.T) {
 			t.Errorf("unexpected ttl for multipart upload expiration. want=%d have=%d", 3, multipartExpiration)
 		}
 	}
+
+	if lifecycle := s3BucketLifecycleConfiguration("s3", 0); lifecycle != nil {
+		t.Errorf("unexpected lifecycle rules without ttl")
+	}
 }
 
 func testS3Client(client s3API, uploader s3Uploader) Store {
@@ -410,5 +414,5 @@ func testS3Client(client s3API, uploader s3Uploader) Store {
 }
 
 func rawS3Client(client s3API, uploader s3Uploader) *s3Store {
-	return newS3WithClients(client, uploader, "test-bucket", true, nil, NewOperations(&observation.TestContext, "test", "brittleStore"))
+	return newS3WithClients(client, uploader, "test-bucket", true, s3BucketLifecycleConfiguration("s3", time.Hour*24*3), NewOperations(&observation.TestContext, "test", "brittleStore"))
 }
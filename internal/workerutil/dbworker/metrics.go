@@ -45,4 +45,40 @@ func InitPrometheusMetric(observationContext *observation.Context, workerStore s
 
 		return float64(age) / float64(time.Second)
 	}))
+
+	observationContext.Registerer.MustRegister(&queuedByKeyCollector{
+		workerStore: workerStore,
+		desc: prometheus.NewDesc(
+			fmt.Sprintf("src_%s_queued_by_key", teamAndResource),
+			fmt.Sprintf("Number of %s records in the queued state for the fairness keys with the most queued records.", resource),
+			[]string{"key"},
+			constLabels,
+		),
+	})
+}
+
+// maxQueuedByKeyKeys bounds the cardinality of the queue depth by key metric.
+const maxQueuedByKeyKeys = 20
+
+// queuedByKeyCollector reports the queue depth of the keys with the most queued records
+// of a store configured with a fairness key. It reports nothing for other stores.
+type queuedByKeyCollector struct {
+	workerStore store.Store
+	desc        *prometheus.Desc
+}
+
+func (c *queuedByKeyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *queuedByKeyCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.workerStore.QueuedCountByKey(context.Background(), maxQueuedByKeyKeys)
+	if err != nil {
+		log15.Error("Failed to determine queue size by key", "error", err)
+		return
+	}
+
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), key)
+	}
 }
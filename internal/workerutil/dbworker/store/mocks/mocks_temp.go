@@ -49,6 +49,9 @@ type MockStore struct {
 	// QueuedCountFunc is an instance of a mock function object controlling
 	// the behavior of the method QueuedCount.
 	QueuedCountFunc *StoreQueuedCountFunc
+	// QueuedCountByKeyFunc is an instance of a mock function object
+	// controlling the behavior of the method QueuedCountByKey.
+	QueuedCountByKeyFunc *StoreQueuedCountByKeyFunc
 	// RequeueFunc is an instance of a mock function object controlling the
 	// behavior of the method Requeue.
 	RequeueFunc *StoreRequeueFunc
@@ -112,6 +115,11 @@ func NewMockStore() *MockStore {
 				return
 			},
 		},
+		QueuedCountByKeyFunc: &StoreQueuedCountByKeyFunc{
+			defaultHook: func(context.Context, int) (r0 map[string]int, r1 error) {
+				return
+			},
+		},
 		RequeueFunc: &StoreRequeueFunc{
 			defaultHook: func(context.Context, int, time.Time) (r0 error) {
 				return
@@ -184,6 +192,11 @@ func NewStrictMockStore() *MockStore {
 				panic("unexpected invocation of MockStore.QueuedCount")
 			},
 		},
+		QueuedCountByKeyFunc: &StoreQueuedCountByKeyFunc{
+			defaultHook: func(context.Context, int) (map[string]int, error) {
+				panic("unexpected invocation of MockStore.QueuedCountByKey")
+			},
+		},
 		RequeueFunc: &StoreRequeueFunc{
 			defaultHook: func(context.Context, int, time.Time) error {
 				panic("unexpected invocation of MockStore.Requeue")
@@ -238,6 +251,9 @@ func NewMockStoreFrom(i store.Store) *MockStore {
 		QueuedCountFunc: &StoreQueuedCountFunc{
 			defaultHook: i.QueuedCount,
 		},
+		QueuedCountByKeyFunc: &StoreQueuedCountByKeyFunc{
+			defaultHook: i.QueuedCountByKey,
+		},
 		RequeueFunc: &StoreRequeueFunc{
 			defaultHook: i.Requeue,
 		},
@@ -1239,6 +1255,114 @@ func (c StoreQueuedCountFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// StoreQueuedCountByKeyFunc describes the behavior when the
+// QueuedCountByKey method of the parent MockStore instance is invoked.
+type StoreQueuedCountByKeyFunc struct {
+	defaultHook func(context.Context, int) (map[string]int, error)
+	hooks       []func(context.Context, int) (map[string]int, error)
+	history     []StoreQueuedCountByKeyFuncCall
+	mutex       sync.Mutex
+}
+
+// QueuedCountByKey delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockStore) QueuedCountByKey(v0 context.Context, v1 int) (map[string]int, error) {
+	r0, r1 := m.QueuedCountByKeyFunc.nextHook()(v0, v1)
+	m.QueuedCountByKeyFunc.appendCall(StoreQueuedCountByKeyFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the QueuedCountByKey
+// method of the parent MockStore instance is invoked and the hook queue is
+// empty.
+func (f *StoreQueuedCountByKeyFunc) SetDefaultHook(hook func(context.Context, int) (map[string]int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// QueuedCountByKey method of the parent MockStore instance invokes the hook
+// at the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *StoreQueuedCountByKeyFunc) PushHook(hook func(context.Context, int) (map[string]int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *StoreQueuedCountByKeyFunc) SetDefaultReturn(r0 map[string]int, r1 error) {
+	f.SetDefaultHook(func(context.Context, int) (map[string]int, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *StoreQueuedCountByKeyFunc) PushReturn(r0 map[string]int, r1 error) {
+	f.PushHook(func(context.Context, int) (map[string]int, error) {
+		return r0, r1
+	})
+}
+
+func (f *StoreQueuedCountByKeyFunc) nextHook() func(context.Context, int) (map[string]int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *StoreQueuedCountByKeyFunc) appendCall(r0 StoreQueuedCountByKeyFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of StoreQueuedCountByKeyFuncCall objects
+// describing the invocations of this function.
+func (f *StoreQueuedCountByKeyFunc) History() []StoreQueuedCountByKeyFuncCall {
+	f.mutex.Lock()
+	history := make([]StoreQueuedCountByKeyFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// StoreQueuedCountByKeyFuncCall is an object that describes an invocation
+// of method QueuedCountByKey on an instance of MockStore.
+type StoreQueuedCountByKeyFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 map[string]int
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c StoreQueuedCountByKeyFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c StoreQueuedCountByKeyFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // StoreRequeueFunc describes the behavior when the Requeue method of the
 // parent MockStore instance is invoked.
 type StoreRequeueFunc struct {
@@ -16,6 +16,7 @@ type operations struct {
 	markFailed              *observation.Operation
 	maxDurationInQueue      *observation.Operation
 	queuedCount             *observation.Operation
+	queuedCountByKey        *observation.Operation
 	requeue                 *observation.Operation
 	resetStalled            *observation.Operation
 	updateExecutionLogEntry *observation.Operation
@@ -46,6 +47,7 @@ func newOperations(storeName string, observationContext *observation.Context) *o
 		markFailed:              op("MarkFailed"),
 		maxDurationInQueue:      op("MaxDurationInQueue"),
 		queuedCount:             op("QueuedCount"),
+		queuedCountByKey:        op("QueuedCountByKey"),
 		requeue:                 op("Requeue"),
 		resetStalled:            op("ResetStalled"),
 		updateExecutionLogEntry: op("UpdateExecutionLogEntry"),
@@ -81,6 +81,11 @@ type Store interface {
 	// QueuedCount returns the number of queued records matching the given conditions.
 	QueuedCount(ctx context.Context, includeProcessing bool, conditions []*sqlf.Query) (int, error)
 
+	// QueuedCountByKey returns the number of queued records for each value of `FairnessKeyExpression`,
+	// limited to the given number of keys with the most queued records. Returns nil if the store has
+	// no fairness key.
+	QueuedCountByKey(ctx context.Context, limit int) (map[string]int, error)
+
 	// MaxDurationInQueue returns the maximum age of queued records in this store. Returns 0 if there are no queued records.
 	MaxDurationInQueue(ctx context.Context) (time.Duration, error)
 
@@ -222,6 +227,31 @@ type Options struct {
 	// supplied.
 	OrderByExpression *sqlf.Query
 
+	// PriorityExpression is an optional SQL expression evaluating to the integer priority of a record.
+	// Records with a higher priority are dequeued before records with a lower priority, regardless of
+	// `FairnessKeyExpression` and `OrderByExpression`. This expression may use the alias provided in
+	// `ViewName`, if one was supplied.
+	PriorityExpression *sqlf.Query
+
+	// FairnessKeyExpression is an optional SQL expression, such as a repository, user, or namespace
+	// column, that partitions records into groups that share the workers of the queue. If supplied,
+	// records of equal priority are dequeued round-robin across keys: the next record is taken from
+	// the key with the fewest records currently processing or ahead of it in the queue, so a single
+	// key with many queued records cannot monopolize the queue. `OrderByExpression` then orders the
+	// records within a key and breaks ties between keys. This expression may use the alias provided
+	// in `ViewName`, if one was supplied.
+	//
+	// Ranking records by key considers every dequeueable record rather than only the first few in
+	// order, so large queues should index the state and key columns.
+	FairnessKeyExpression *sqlf.Query
+
+	// MaxConcurrencyPerKey, if positive, is the maximum number of records with the same value of
+	// `FairnessKeyExpression` that may be processing at the same time. Records of a key at the cap
+	// are skipped until a record of that key finishes. The cap is checked when a record is dequeued
+	// without locking the key, so concurrent dequeues may briefly exceed it. This option requires
+	// `FairnessKeyExpression`.
+	MaxConcurrencyPerKey int
+
 	// ColumnExpressions are the target columns provided to the query when selecting a job record. These
 	// expressions may use the alias provided in `ViewName`, if one was supplied.
 	ColumnExpressions []*sqlf.Query
@@ -280,6 +310,10 @@ func newStore(handle basestore.TransactableHandle, options Options, observationC
 		panic("no name supplied to github.com/sourcegraph/sourcegraph/internal/dbworker/store:newStore")
 	}
 
+	if options.MaxConcurrencyPerKey > 0 && options.FairnessKeyExpression == nil {
+		panic("MaxConcurrencyPerKey supplied without FairnessKeyExpression to github.com/sourcegraph/sourcegraph/internal/dbworker/store:newStore")
+	}
+
 	if options.ViewName == "" {
 		options.ViewName = options.TableName
 	}
@@ -390,6 +424,57 @@ SELECT COUNT(*) FROM %s WHERE (
 ) %s
 `
 
+// QueuedCountByKey returns the number of queued records for each value of `FairnessKeyExpression`,
+// limited to the given number of keys with the most queued records. Returns nil if the store has
+// no fairness key.
+func (s *store) QueuedCountByKey(ctx context.Context, limit int) (_ map[string]int, err error) {
+	ctx, _, endObservation := s.operations.queuedCountByKey.With(ctx, &err, observation.Args{})
+	defer endObservation(1, observation.Args{})
+
+	if s.options.FairnessKeyExpression == nil {
+		return nil, nil
+	}
+
+	return scanCountsByKey(s.Query(ctx, s.formatQuery(
+		queuedCountByKeyQuery,
+		s.options.FairnessKeyExpression,
+		quote(s.options.ViewName),
+		s.options.MaxNumRetries,
+		limit,
+	)))
+}
+
+const queuedCountByKeyQuery = `
+-- source: internal/workerutil/store.go:QueuedCountByKey
+SELECT COALESCE((%s)::text, '') AS key, COUNT(*) FROM %s WHERE (
+	{state} = 'queued' OR
+	({state} = 'errored' AND {num_failures} < %s)
+)
+GROUP BY 1
+ORDER BY 2 DESC, 1
+LIMIT %s
+`
+
+func scanCountsByKey(rows *sql.Rows, queryErr error) (_ map[string]int, err error) {
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var key string
+		var count int
+		if err := rows.Scan(&key, &count); err != nil {
+			return nil, err
+		}
+
+		counts[key] = count
+	}
+
+	return counts, nil
+}
+
 // MaxDurationInQueue returns the longest duration for which a job associated with this store instance has
 // been in the queued state (including errored records that can be retried in the future). This method returns
 // an duration of zero if there are no jobs ready for processing.
@@ -519,15 +604,7 @@ func (s *store) Dequeue(ctx context.Context, workerHostname string, conditions [
 
 	record, exists, err := s.options.Scan(s.Query(ctx, s.formatQuery(
 		dequeueQuery,
-		s.options.OrderByExpression,
-		quote(s.options.ViewName),
-		now,
-		retryAfter,
-		now,
-		retryAfter,
-		s.options.MaxNumRetries,
-		makeConditionSuffix(conditions),
-		s.options.OrderByExpression,
+		s.makePotentialCandidatesQuery(now, retryAfter, conditions),
 		quote(s.options.TableName),
 		quote(s.options.TableName),
 		quote(s.options.TableName),
@@ -548,27 +625,7 @@ func (s *store) Dequeue(ctx context.Context, workerHostname string, conditions [
 
 const dequeueQuery = `
 -- source: internal/workerutil/store.go:Dequeue
-WITH potential_candidates AS (
-	SELECT
-		{id} AS candidate_id,
-		ROW_NUMBER() OVER (ORDER BY %s) AS order
-	FROM %s
-	WHERE
-		(
-			(
-				{state} = 'queued' AND
-				({process_after} IS NULL OR {process_after} <= %s)
-			) OR (
-				%s > 0 AND
-				{state} = 'errored' AND
-				%s - {finished_at} > (%s * '1 second'::interval) AND
-				{num_failures} < %s
-			)
-		)
-		%s
-	ORDER BY %s
-	LIMIT 50
-),
+WITH %s,
 candidate AS (
 	SELECT
 		{id} FROM %s
@@ -596,6 +653,125 @@ WHERE
 	{id} IN (SELECT {id} FROM candidate)
 `
 
+// makePotentialCandidatesQuery constructs the common table expressions of the dequeue query that
+// define potential_candidates, the first dequeueable records matching the given conditions along
+// with the order in which they should be dequeued.
+func (s *store) makePotentialCandidatesQuery(now time.Time, retryAfter int, conditions []*sqlf.Query) *sqlf.Query {
+	dequeueable := s.formatQuery(
+		dequeueableConditionQuery,
+		now,
+		retryAfter,
+		now,
+		retryAfter,
+		s.options.MaxNumRetries,
+	)
+
+	if s.options.FairnessKeyExpression == nil {
+		orderBy := s.options.OrderByExpression
+		if s.options.PriorityExpression != nil {
+			orderBy = sqlf.Sprintf("(%s) DESC, %s", s.options.PriorityExpression, orderBy)
+		}
+
+		return s.formatQuery(
+			potentialCandidatesQuery,
+			orderBy,
+			quote(s.options.ViewName),
+			dequeueable,
+			makeConditionSuffix(conditions),
+			orderBy,
+		)
+	}
+
+	priority := s.options.PriorityExpression
+	if priority == nil {
+		priority = sqlf.Sprintf("0")
+	}
+
+	shareCondition := sqlf.Sprintf("TRUE")
+	if s.options.MaxConcurrencyPerKey > 0 {
+		shareCondition = sqlf.Sprintf("share <= %s", s.options.MaxConcurrencyPerKey)
+	}
+
+	return s.formatQuery(
+		fairPotentialCandidatesQuery,
+		s.options.FairnessKeyExpression,
+		quote(s.options.ViewName),
+		priority,
+		s.options.FairnessKeyExpression,
+		priority,
+		s.options.OrderByExpression,
+		s.options.OrderByExpression,
+		quote(s.options.ViewName),
+		s.options.FairnessKeyExpression,
+		dequeueable,
+		makeConditionSuffix(conditions),
+		shareCondition,
+	)
+}
+
+const dequeueableConditionQuery = `
+(
+	(
+		{state} = 'queued' AND
+		({process_after} IS NULL OR {process_after} <= %s)
+	) OR (
+		%s > 0 AND
+		{state} = 'errored' AND
+		%s - {finished_at} > (%s * '1 second'::interval) AND
+		{num_failures} < %s
+	)
+)
+`
+
+const potentialCandidatesQuery = `
+potential_candidates AS (
+	SELECT
+		{id} AS candidate_id,
+		ROW_NUMBER() OVER (ORDER BY %s) AS order
+	FROM %s
+	WHERE
+		%s
+		%s
+	ORDER BY %s
+	LIMIT 50
+)
+`
+
+// fairPotentialCandidatesQuery orders records by their share: the number of records of the same
+// key that are processing or ahead of the record in the queue. Taking records in order of share
+// interleaves the keys, starting with the keys that have the fewest records processing.
+const fairPotentialCandidatesQuery = `
+processing_by_key AS (
+	SELECT
+		%s AS fairness_key,
+		COUNT(*) AS num_processing
+	FROM %s
+	WHERE {state} = 'processing'
+	GROUP BY 1
+),
+ranked_candidates AS (
+	SELECT
+		{id} AS candidate_id,
+		(%s) AS priority,
+		COALESCE(pbk.num_processing, 0) + ROW_NUMBER() OVER (PARTITION BY %s ORDER BY (%s) DESC, %s) AS share,
+		ROW_NUMBER() OVER (ORDER BY %s) AS base_order
+	FROM %s
+	LEFT JOIN processing_by_key pbk ON pbk.fairness_key IS NOT DISTINCT FROM %s
+	WHERE
+		%s
+		%s
+),
+potential_candidates AS (
+	SELECT
+		candidate_id,
+		ROW_NUMBER() OVER (ORDER BY priority DESC, share, base_order) AS order
+	FROM ranked_candidates
+	WHERE %s
+	ORDER BY priority DESC, share, base_order
+	LIMIT 50
+)
+`
+
 // makeDequeueSelectExpressions constructs the ordered set of SQL expressions that are returned
 // from the dequeue query. This method returns a copy of the configured column expressions slice
 // where expressions referencing one of the column updated by dequeue are replaced by the updated
@@ -114,6 +114,50 @@ func TestStoreQueuedCountConditions(t *testing.T) {
 	}
 }
 
+func TestStoreQueuedCountByKey(t *testing.T) {
+	db := setupStoreTest(t)
+
+	if _, err := db.ExecContext(context.Background(), `
+		INSERT INTO workerutil_test (id, state, repository_id)
+		VALUES
+			(1, 'queued', 1),
+			(2, 'queued', 1),
+			(3, 'queued', 2),
+			(4, 'processing', 2),
+			(5, 'queued', 3),
+			(6, 'errored', 3),
+			(7, 'queued', 3),
+			(8, 'queued', NULL)
+	`); err != nil {
+		t.Fatalf("unexpected error inserting records: %s", err)
+	}
+
+	options := defaultTestStoreOptions(nil)
+	options.FairnessKeyExpression = sqlf.Sprintf("workerutil_test.repository_id")
+
+	counts, err := testStore(db, options).QueuedCountByKey(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("unexpected error getting queued count by key: %s", err)
+	}
+
+	expected := map[string]int{"3": 3, "1": 2, "": 1}
+	if diff := cmp.Diff(expected, counts); diff != "" {
+		t.Errorf("unexpected counts (-want +got):\n%s", diff)
+	}
+}
+
+func TestStoreQueuedCountByKeyNoFairnessKey(t *testing.T) {
+	db := setupStoreTest(t)
+
+	counts, err := testStore(db, defaultTestStoreOptions(nil)).QueuedCountByKey(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error getting queued count by key: %s", err)
+	}
+	if counts != nil {
+		t.Errorf("unexpected counts. want=%v have=%v", nil, counts)
+	}
+}
+
 func TestStoreMaxDurationInQueue(t *testing.T) {
 	db := setupStoreTest(t)
 
@@ -241,6 +285,116 @@ func TestStoreDequeueOrder(t *testing.T) {
 	assertDequeueRecordResult(t, 2, record, ok, err)
 }
 
+func TestStoreDequeuePriority(t *testing.T) {
+	db := setupStoreTest(t)
+
+	if _, err := db.ExecContext(context.Background(), `
+		INSERT INTO workerutil_test (id, state, created_at, priority)
+		VALUES
+			(1, 'queued', NOW() - '5 minute'::interval, 0),
+			(2, 'queued', NOW() - '4 minute'::interval, 0),
+			(3, 'queued', NOW() - '3 minute'::interval, 1),
+			(4, 'queued', NOW() - '2 minute'::interval, 2),
+			(5, 'queued', NOW() - '1 minute'::interval, 1)
+	`); err != nil {
+		t.Fatalf("unexpected error inserting records: %s", err)
+	}
+
+	options := defaultTestStoreOptions(nil)
+	options.PriorityExpression = sqlf.Sprintf("workerutil_test.priority")
+	store := testStore(db, options)
+
+	for _, expectedID := range []int{4, 3, 5, 1, 2} {
+		record, ok, err := store.Dequeue(context.Background(), "test", nil)
+		assertDequeueRecordResult(t, expectedID, record, ok, err)
+	}
+}
+
+func TestStoreDequeueFairness(t *testing.T) {
+	db := setupStoreTest(t)
+
+	if _, err := db.ExecContext(context.Background(), `
+		INSERT INTO workerutil_test (id, state, created_at, repository_id)
+		VALUES
+			(1, 'queued', NOW() - '5 minute'::interval, 1),
+			(2, 'queued', NOW() - '4 minute'::interval, 1),
+			(3, 'queued', NOW() - '3 minute'::interval, 1),
+			(4, 'queued', NOW() - '2 minute'::interval, 2),
+			(5, 'queued', NOW() - '1 minute'::interval, 2)
+	`); err != nil {
+		t.Fatalf("unexpected error inserting records: %s", err)
+	}
+
+	options := defaultTestStoreOptions(nil)
+	options.FairnessKeyExpression = sqlf.Sprintf("workerutil_test.repository_id")
+	store := testStore(db, options)
+
+	// Repository 1 queued first, but does not get to process all of its records
+	// before repository 2 gets a turn.
+	for _, expectedID := range []int{1, 4, 2, 5, 3} {
+		record, ok, err := store.Dequeue(context.Background(), "test", nil)
+		assertDequeueRecordResult(t, expectedID, record, ok, err)
+	}
+}
+
+func TestStoreDequeueFairnessPriority(t *testing.T) {
+	db := setupStoreTest(t)
+
+	if _, err := db.ExecContext(context.Background(), `
+		INSERT INTO workerutil_test (id, state, created_at, repository_id, priority)
+		VALUES
+			(1, 'queued', NOW() - '5 minute'::interval, 1, 0),
+			(2, 'queued', NOW() - '4 minute'::interval, 1, 1),
+			(3, 'queued', NOW() - '3 minute'::interval, 1, 1),
+			(4, 'queued', NOW() - '2 minute'::interval, 2, 0)
+	`); err != nil {
+		t.Fatalf("unexpected error inserting records: %s", err)
+	}
+
+	options := defaultTestStoreOptions(nil)
+	options.FairnessKeyExpression = sqlf.Sprintf("workerutil_test.repository_id")
+	options.PriorityExpression = sqlf.Sprintf("workerutil_test.priority")
+	store := testStore(db, options)
+
+	for _, expectedID := range []int{2, 3, 4, 1} {
+		record, ok, err := store.Dequeue(context.Background(), "test", nil)
+		assertDequeueRecordResult(t, expectedID, record, ok, err)
+	}
+}
+
+func TestStoreDequeueMaxConcurrencyPerKey(t *testing.T) {
+	db := setupStoreTest(t)
+
+	if _, err := db.ExecContext(context.Background(), `
+		INSERT INTO workerutil_test (id, state, created_at, repository_id)
+		VALUES
+			(1, 'processing', NOW() - '6 minute'::interval, 1),
+			(2, 'queued', NOW() - '5 minute'::interval, 1),
+			(3, 'queued', NOW() - '4 minute'::interval, 2),
+			(4, 'queued', NOW() - '3 minute'::interval, 2),
+			(5, 'queued', NOW() - '2 minute'::interval, 3)
+	`); err != nil {
+		t.Fatalf("unexpected error inserting records: %s", err)
+	}
+
+	options := defaultTestStoreOptions(nil)
+	options.FairnessKeyExpression = sqlf.Sprintf("workerutil_test.repository_id")
+	options.MaxConcurrencyPerKey = 1
+	store := testStore(db, options)
+
+	for _, expectedID := range []int{3, 5} {
+		record, ok, err := store.Dequeue(context.Background(), "test", nil)
+		assertDequeueRecordResult(t, expectedID, record, ok, err)
+	}
+
+	// Every repository has a record processing
+	if _, ok, err := store.Dequeue(context.Background(), "test", nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if ok {
+		t.Fatalf("did not expect a dequeueable record")
+	}
+}
+
 func TestStoreDequeueConditions(t *testing.T) {
 	db := setupStoreTest(t)
 
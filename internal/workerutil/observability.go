@@ -2,6 +2,8 @@ package workerutil
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -17,8 +19,9 @@ type WorkerMetrics struct {
 	// logger provided by operations where relevant.
 	logger log.Logger
 
-	operations *operations
-	numJobs    Gauge
+	operations        *operations
+	numJobs           *concurrencyGauges
+	recordLabelValues func(record Record) []string
 }
 
 type Gauge interface {
@@ -33,8 +36,10 @@ type operations struct {
 }
 
 type metricOptions struct {
-	labels          map[string]string
-	durationBuckets []float64
+	labels            map[string]string
+	recordLabels      []string
+	recordLabelValues func(record Record) []string
+	durationBuckets   []float64
 }
 
 type MetricOption func(o *metricOptions)
@@ -43,6 +48,16 @@ func WithLabels(labels map[string]string) MetricOption {
 	return func(o *metricOptions) { o.labels = labels }
 }
 
+// WithRecordLabels adds labels whose values depend on the record being processed, e.g. the
+// queue from which the record was dequeued. The given function must return a value for each
+// of the given label names.
+func WithRecordLabels(names []string, values func(record Record) []string) MetricOption {
+	return func(o *metricOptions) {
+		o.recordLabels = names
+		o.recordLabelValues = values
+	}
+}
+
 func WithDurationBuckets(buckets []float64) MetricOption {
 	return func(o *metricOptions) { o.durationBuckets = buckets }
 }
@@ -54,7 +69,7 @@ func WithDurationBuckets(buckets []float64) MetricOption {
 //   - {prefix}_error_total: number of handler operations resulting in an error
 //   - {prefix}_handlers: the number of active handler routines
 //
-// The given labels are emitted on each metric.
+// The given labels, as well as the record labels, are emitted on each metric.
 func NewMetrics(observationContext *observation.Context, prefix string, opts ...MetricOption) WorkerMetrics {
 	options := &metricOptions{
 		durationBuckets: prometheus.DefBuckets,
@@ -71,33 +86,43 @@ func NewMetrics(observationContext *observation.Context, prefix string, opts ...
 		values = append(values, value)
 	}
 
-	gauge := func(name, help string) prometheus.Gauge {
-		gaugeVec := prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Name: fmt.Sprintf("src_%s_%s", prefix, name),
-			Help: help,
-		}, keys)
+	gaugeVec := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: fmt.Sprintf("src_%s_handlers", prefix),
+		Help: "The number of active handlers.",
+	}, append(append([]string{}, keys...), options.recordLabels...))
+	observationContext.Registerer.MustRegister(gaugeVec)
 
-		observationContext.Registerer.MustRegister(gaugeVec)
-		return gaugeVec.WithLabelValues(values...)
+	numJobs := &concurrencyGauges{vec: gaugeVec, values: values, gauges: map[string]Gauge{}}
+	if len(options.recordLabels) == 0 {
+		// Emit the gauge before the first record is processed.
+		numJobs.get(nil)
 	}
 
-	numJobs := gauge(
-		"handlers",
-		"The number of active handlers.",
-	)
-
 	return WorkerMetrics{
-		logger:     observationContext.Logger,
-		operations: newOperations(observationContext, prefix, keys, values, options.durationBuckets),
-		numJobs:    newLenientConcurrencyGauge(numJobs, time.Second*5),
+		logger:            observationContext.Logger,
+		operations:        newOperations(observationContext, prefix, keys, values, options.recordLabels, options.durationBuckets),
+		numJobs:           numJobs,
+		recordLabelValues: options.recordLabelValues,
 	}
 }
 
-func newOperations(observationContext *observation.Context, prefix string, keys, values []string, durationBuckets []float64) *operations {
+// recordLabels returns the values of the record labels for the given record.
+func (m WorkerMetrics) recordLabels(record Record) []string {
+	if m.recordLabelValues == nil {
+		return nil
+	}
+	return m.recordLabelValues(record)
+}
+
+func newOperations(observationContext *observation.Context, prefix string, keys, values, recordKeys []string, durationBuckets []float64) *operations {
+	// The values of the record labels are given on each invocation, after the label values
+	// of the operation.
+	labels := append(append(append([]string{}, keys...), "op"), recordKeys...)
+
 	metrics := metrics.NewREDMetrics(
 		observationContext.Registerer,
 		prefix,
-		metrics.WithLabels(append(keys, "op")...),
+		metrics.WithLabels(labels...),
 		metrics.WithCountHelp("Total number of method invocations."),
 		metrics.WithDurationBuckets(durationBuckets),
 	)
@@ -117,6 +142,31 @@ func newOperations(observationContext *observation.Context, prefix string, keys,
 	}
 }
 
+// concurrencyGauges holds a lenient concurrency gauge for each combination of record label
+// values.
+type concurrencyGauges struct {
+	vec    *prometheus.GaugeVec
+	values []string
+
+	mu     sync.Mutex
+	gauges map[string]Gauge
+}
+
+// get returns the gauge for the given record label values.
+func (g *concurrencyGauges) get(recordLabelValues []string) Gauge {
+	key := strings.Join(recordLabelValues, "\x00")
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	gauge, ok := g.gauges[key]
+	if !ok {
+		gauge = newLenientConcurrencyGauge(g.vec.WithLabelValues(append(append([]string{}, g.values...), recordLabelValues...)...), time.Second*5)
+		g.gauges[key] = gauge
+	}
+	return gauge
+}
+
 // newLenientConcurrencyGauge creates a new gauge-like object that
 // emits the maximum value over the last five seconds into the given
 // gauge. Note that this gauge should be used to track concurrency
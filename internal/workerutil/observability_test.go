@@ -0,0 +1,57 @@
+package workerutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+func TestNewMetricsRecordLabels(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	observationContext := observation.TestContext
+	observationContext.Registerer = registry
+
+	metrics := NewMetrics(&observationContext, "test_worker",
+		WithLabels(map[string]string{"instance": "test"}),
+		WithRecordLabels([]string{"state"}, func(record Record) []string {
+			return []string{record.(TestRecord).State}
+		}),
+	)
+
+	for _, record := range []TestRecord{{ID: 1, State: "a"}, {ID: 2, State: "b"}, {ID: 3, State: "b"}} {
+		_, _, endObservation := metrics.operations.handle.With(context.Background(), nil, observation.Args{
+			MetricLabelValues: metrics.recordLabels(record),
+		})
+		endObservation(1, observation.Args{})
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "src_test_worker_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["instance"] != "test" || labels["op"] != "Handle" {
+				t.Errorf("unexpected labels %v", labels)
+			}
+			counts[labels["state"]] += metric.GetCounter().GetValue()
+		}
+	}
+
+	if diff := cmp.Diff(map[string]float64{"a": 1, "b": 2}, counts); diff != "" {
+		t.Errorf("unexpected counts by record label (-want +got):\n%s", diff)
+	}
+}
@@ -294,10 +294,13 @@ func (w *Worker) dequeueAndHandle() (dequeued bool, err error) {
 	}
 
 	// Set up observability
-	w.options.Metrics.numJobs.Inc()
+	recordLabels := w.options.Metrics.recordLabels(record)
+	numJobs := w.options.Metrics.numJobs.get(recordLabels)
+	numJobs.Inc()
 	processLog.Info("Dequeued record for processing", log.Int("id", record.RecordID()))
 	processArgs := observation.Args{
-		LogFields: []otlog.Field{otlog.Int("record.id", record.RecordID())},
+		MetricLabelValues: recordLabels,
+		LogFields:         []otlog.Field{otlog.Int("record.id", record.RecordID())},
 	}
 
 	if hook, ok := w.handler.(WithHooks); ok {
@@ -325,7 +328,7 @@ func (w *Worker) dequeueAndHandle() (dequeued bool, err error) {
 			// Remove the record from the set of running jobs, so it is not included
 			// in heartbeat updates anymore.
 			defer w.runningIDSet.Remove(record.RecordID())
-			w.options.Metrics.numJobs.Dec()
+			numJobs.Dec()
 			w.handlerSemaphore <- struct{}{}
 			w.wg.Done()
 			workerSpan.Finish()
@@ -342,7 +345,9 @@ func (w *Worker) dequeueAndHandle() (dequeued bool, err error) {
 // handle processes the given record. This method returns an error only if there is an issue updating
 // the record to a terminal state - no handler errors will bubble up.
 func (w *Worker) handle(ctx, workerContext context.Context, record Record) (err error) {
-	ctx, handleLog, endOperation := w.options.Metrics.operations.handle.With(ctx, &err, observation.Args{})
+	ctx, handleLog, endOperation := w.options.Metrics.operations.handle.With(ctx, &err, observation.Args{
+		MetricLabelValues: w.options.Metrics.recordLabels(record),
+	})
 	defer endOperation(1, observation.Args{})
 
 	// If a maximum runtime is configured, set a deadline on the handle context.
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/conversion/datastructures"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/reader"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/pathexistence"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
 	"github.com/sourcegraph/sourcegraph/lib/errors"
@@ -25,6 +26,26 @@ func Correlate(ctx context.Context, r io.Reader, root string, getChildren pathex
 		return nil, err
 	}
 
+	return groupCorrelatedState(ctx, state, root, getChildren)
+}
+
+// CorrelateElements returns a correlation state object from the given LSIF elements with the
+// data canonicalized and pruned for storage. This is used for indexes that are not encoded as
+// newline-delimited JSON (e.g. SCIP) and are translated into LSIF elements by the caller.
+//
+// If getChildren == nil, no pruning of irrelevant data is performed.
+func CorrelateElements(ctx context.Context, elements []reader.Element, root string, getChildren pathexistence.GetChildrenFunc) (*precise.GroupedBundleDataChans, error) {
+	state, err := correlateFromElements(elements, root)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupCorrelatedState(ctx, state, root, getChildren)
+}
+
+// groupCorrelatedState canonicalizes and prunes the given raw correlation state and converts it
+// into the format written to the codeintel database.
+func groupCorrelatedState(ctx context.Context, state *State, root string, getChildren pathexistence.GetChildrenFunc) (*precise.GroupedBundleDataChans, error) {
 	// Remove duplicate elements, collapse linked elements
 	canonicalize(state)
 
@@ -148,6 +169,31 @@ func correlateFromReader(ctx context.Context, r io.Reader, root string) (*State,
 	return wrappedState.State, nil
 }
 
+// correlateFromElements returns a correlation state object from the given LSIF elements. The
+// data in the correlation state is neither canonicalized nor pruned.
+func correlateFromElements(elements []reader.Element, root string) (*State, error) {
+	wrappedState := newWrappedState(root)
+
+	for i, element := range elements {
+		converted := Element{
+			ID:      element.ID,
+			Type:    element.Type,
+			Label:   element.Label,
+			Payload: translatePayload(element.Payload),
+		}
+
+		if err := correlateElement(wrappedState, converted); err != nil {
+			return nil, errors.Errorf("index malformed on element %d: %s", i+1, err)
+		}
+	}
+
+	if wrappedState.LSIFVersion == "" {
+		return nil, ErrMissingMetaData
+	}
+
+	return wrappedState.State, nil
+}
+
 type wrappedState struct {
 	*State
 	dumpRoot            string
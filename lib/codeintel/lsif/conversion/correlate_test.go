@@ -194,6 +194,36 @@ func TestCorrelate(t *testing.T) {
 	}
 }
 
+func TestCorrelateFromElements(t *testing.T) {
+	input, err := os.ReadFile("../testdata/dump1.lsif")
+	if err != nil {
+		t.Fatalf("unexpected error reading test file: %s", err)
+	}
+
+	var elements []reader.Element
+	for pair := range reader.Read(context.Background(), bytes.NewReader(input)) {
+		if pair.Err != nil {
+			t.Fatalf("unexpected error reading input: %s", pair.Err)
+		}
+
+		elements = append(elements, pair.Element)
+	}
+
+	state, err := correlateFromElements(elements, "root")
+	if err != nil {
+		t.Fatalf("unexpected error correlating elements: %s", err)
+	}
+
+	expectedState, err := correlateFromReader(context.Background(), bytes.NewReader(input), "root")
+	if err != nil {
+		t.Fatalf("unexpected error correlating input: %s", err)
+	}
+
+	if diff := cmp.Diff(expectedState, state, datastructures.Comparers...); diff != "" {
+		t.Errorf("unexpected state (-want +got):\n%s", diff)
+	}
+}
+
 func TestCorrelateMetaDataRoot(t *testing.T) {
 	input, err := os.ReadFile("../testdata/dump2.lsif")
 	if err != nil {
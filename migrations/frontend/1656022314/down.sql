@@ -0,0 +1,6 @@
+ALTER TABLE cm_webhooks
+  DROP COLUMN IF EXISTS method,
+  DROP COLUMN IF EXISTS headers,
+  DROP COLUMN IF EXISTS body_template,
+  DROP COLUMN IF EXISTS secret,
+  DROP COLUMN IF EXISTS secret_key_id;
@@ -0,0 +1,12 @@
+ALTER TABLE cm_webhooks
+  ADD COLUMN IF NOT EXISTS method text,
+  ADD COLUMN IF NOT EXISTS headers jsonb,
+  ADD COLUMN IF NOT EXISTS body_template text,
+  ADD COLUMN IF NOT EXISTS secret text,
+  ADD COLUMN IF NOT EXISTS secret_key_id text;
+
+COMMENT ON COLUMN cm_webhooks.method IS 'The HTTP method of the request sent to the webhook URL. Only used if body_template is set';
+COMMENT ON COLUMN cm_webhooks.headers IS 'A JSON array of {"name": ..., "value": ...} objects, whose values are templates rendered over the code monitor event. Only used if body_template is set';
+COMMENT ON COLUMN cm_webhooks.body_template IS 'The template of the request body rendered over the code monitor event. When null, the default JSON payload is sent';
+COMMENT ON COLUMN cm_webhooks.secret IS 'The secret used to compute HMAC signatures of the request body in header templates';
+COMMENT ON COLUMN cm_webhooks.secret_key_id IS 'The identifier of the key the secret is encrypted with. When empty, the secret is not encrypted';
@@ -0,0 +1 @@
+ALTER TABLE access_tokens DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,3 @@
+ALTER TABLE access_tokens ADD COLUMN IF NOT EXISTS expires_at timestamp with time zone;
+
+COMMENT ON COLUMN access_tokens.expires_at IS 'The time after which the access token is no longer valid. When null, the access token does not expire';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS vulnerability_matches;
+DROP TABLE IF EXISTS vulnerability_affected_packages;
+DROP TABLE IF EXISTS vulnerabilities;
@@ -0,0 +1,58 @@
+CREATE TABLE IF NOT EXISTS vulnerabilities (
+    id SERIAL PRIMARY KEY,
+    source_id text NOT NULL,
+    aliases text[] NOT NULL DEFAULT '{}',
+    summary text NOT NULL DEFAULT '',
+    details text NOT NULL DEFAULT '',
+    severity text NOT NULL DEFAULT '',
+    published_at timestamp with time zone,
+    modified_at timestamp with time zone,
+    withdrawn_at timestamp with time zone
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS vulnerabilities_source_id ON vulnerabilities USING btree (source_id);
+
+COMMENT ON TABLE vulnerabilities IS 'Security advisories imported from an OSV-format advisory database.';
+
+COMMENT ON COLUMN vulnerabilities.source_id IS 'The identifier of the advisory in the source database (e.g., GHSA-xxxx-xxxx-xxxx or GO-2022-0001).';
+
+COMMENT ON COLUMN vulnerabilities.aliases IS 'Identifiers of the same advisory in other databases (e.g., CVE identifiers).';
+
+COMMENT ON COLUMN vulnerabilities.severity IS 'The CVSS vector describing the severity of the advisory, if known.';
+
+COMMENT ON COLUMN vulnerabilities.withdrawn_at IS 'The time at which the advisory was withdrawn. Withdrawn advisories are not matched against dependencies.';
+
+CREATE TABLE IF NOT EXISTS vulnerability_affected_packages (
+    id SERIAL PRIMARY KEY,
+    vulnerability_id integer NOT NULL REFERENCES vulnerabilities(id) ON DELETE CASCADE,
+    package_scheme text NOT NULL,
+    package_name text NOT NULL,
+    versions text[] NOT NULL DEFAULT '{}',
+    ranges jsonb NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX IF NOT EXISTS vulnerability_affected_packages_vulnerability_id ON vulnerability_affected_packages USING btree (vulnerability_id);
+
+CREATE INDEX IF NOT EXISTS vulnerability_affected_packages_package_scheme_package_name ON vulnerability_affected_packages USING btree (package_scheme, package_name);
+
+COMMENT ON TABLE vulnerability_affected_packages IS 'A package affected by a vulnerability, along with the affected versions of the package.';
+
+COMMENT ON COLUMN vulnerability_affected_packages.package_scheme IS 'The scheme of the package, matching `codeintel_lockfile_references.package_scheme`.';
+
+COMMENT ON COLUMN vulnerability_affected_packages.package_name IS 'The name of the package, matching `codeintel_lockfile_references.package_name`.';
+
+COMMENT ON COLUMN vulnerability_affected_packages.versions IS 'Versions of the package that are explicitly listed as affected.';
+
+COMMENT ON COLUMN vulnerability_affected_packages.ranges IS 'Ranges of affected versions of the package, as a list of objects with optional `introduced`, `fixed`, and `lastAffected` keys.';
+
+CREATE TABLE IF NOT EXISTS vulnerability_matches (
+    id SERIAL PRIMARY KEY,
+    vulnerability_affected_package_id integer NOT NULL REFERENCES vulnerability_affected_packages(id) ON DELETE CASCADE,
+    codeintel_lockfile_reference_id integer NOT NULL REFERENCES codeintel_lockfile_references(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS vulnerability_matches_affected_package_id_lockfile_reference_id ON vulnerability_matches USING btree (vulnerability_affected_package_id, codeintel_lockfile_reference_id);
+
+CREATE INDEX IF NOT EXISTS vulnerability_matches_codeintel_lockfile_reference_id ON vulnerability_matches USING btree (codeintel_lockfile_reference_id);
+
+COMMENT ON TABLE vulnerability_matches IS 'Associates a vulnerable package version with the lockfile dependencies that resolve to it.';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS repo_update_queue;
+DROP TABLE IF EXISTS repo_update_schedule;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS repo_update_schedule (
+    repo_id integer PRIMARY KEY REFERENCES repo(id) ON DELETE CASCADE,
+    interval_seconds integer NOT NULL,
+    due_at timestamp with time zone NOT NULL,
+    last_webhook_at timestamp with time zone
+);
+
+CREATE INDEX IF NOT EXISTS repo_update_schedule_due_at ON repo_update_schedule USING btree (due_at);
+
+COMMENT ON TABLE repo_update_schedule IS 'The schedule of when repositories are enqueued into repo_update_queue by repo-updater.';
+
+COMMENT ON COLUMN repo_update_schedule.interval_seconds IS 'How regularly the repository is updated.';
+
+COMMENT ON COLUMN repo_update_schedule.due_at IS 'The next time at which the repository is enqueued for an update.';
+
+COMMENT ON COLUMN repo_update_schedule.last_webhook_at IS 'The last time at which a push webhook was received for the repository.';
+
+CREATE TABLE IF NOT EXISTS repo_update_queue (
+    repo_id integer PRIMARY KEY REFERENCES repo(id) ON DELETE CASCADE,
+    priority integer NOT NULL,
+    queued_at timestamp with time zone NOT NULL,
+    leased_by text,
+    lease_expires_at timestamp with time zone
+);
+
+CREATE INDEX IF NOT EXISTS repo_update_queue_priority_queued_at ON repo_update_queue USING btree (priority DESC, queued_at);
+
+COMMENT ON TABLE repo_update_queue IS 'A priority queue of repositories that repo-updater instances update on gitserver.';
+
+COMMENT ON COLUMN repo_update_queue.queued_at IS 'The time at which the repository was queued at its current priority. Repositories with the same priority are updated in queue order.';
+
+COMMENT ON COLUMN repo_update_queue.leased_by IS 'The hostname of the repo-updater instance updating the repository.';
+
+COMMENT ON COLUMN repo_update_queue.lease_expires_at IS 'The time at which the repository can be updated by another repo-updater instance, if it is still in the queue.';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS gitserver_repo_backups;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS gitserver_repo_backups (
+    id SERIAL PRIMARY KEY,
+    repo_id integer NOT NULL REFERENCES repo(id) ON DELETE CASCADE,
+    base_id integer REFERENCES gitserver_repo_backups(id) ON DELETE CASCADE,
+    key text NOT NULL,
+    head_ref text NOT NULL,
+    refs text[] NOT NULL,
+    size_bytes bigint NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS gitserver_repo_backups_repo_id ON gitserver_repo_backups USING btree (repo_id);
+
+CREATE INDEX IF NOT EXISTS gitserver_repo_backups_base_id ON gitserver_repo_backups USING btree (base_id);
+
+COMMENT ON TABLE gitserver_repo_backups IS 'Git bundles of repositories written to the upload store by gitserver. Repositories are restored from the latest full bundle and the incremental bundles based on it.';
+
+COMMENT ON COLUMN gitserver_repo_backups.base_id IS 'The full bundle that this incremental bundle is based on. Null for full bundles.';
+
+COMMENT ON COLUMN gitserver_repo_backups.key IS 'The key of the bundle in the upload store.';
+
+COMMENT ON COLUMN gitserver_repo_backups.head_ref IS 'The ref that HEAD pointed to when the bundle was written.';
+
+COMMENT ON COLUMN gitserver_repo_backups.refs IS 'The refs of the repository when the bundle was written, as "<object name> <ref name>" pairs. The next incremental bundle only contains objects that are not reachable from them.';
@@ -0,0 +1,3 @@
+ALTER TABLE executor_heartbeats DROP COLUMN IF EXISTS queue_names;
+
+COMMENT ON COLUMN executor_heartbeats.queue_name IS 'The queue name that the executor polls for work.';
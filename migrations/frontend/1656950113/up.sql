@@ -0,0 +1,5 @@
+ALTER TABLE executor_heartbeats ADD COLUMN IF NOT EXISTS queue_names text[];
+
+COMMENT ON COLUMN executor_heartbeats.queue_name IS 'The queue name that the executor polls for work. Empty for executors that poll several queues.';
+
+COMMENT ON COLUMN executor_heartbeats.queue_names IS 'The names of all queues that the executor polls for work.';
@@ -6,6 +6,10 @@ import _ "embed"
 //go:embed aws_codecommit.schema.json
 var AWSCodeCommitSchemaJSON string
 
+// AzureDevOpsSchemaJSON is the content of the file "azuredevops.schema.json".
+//go:embed azuredevops.schema.json
+var AzureDevOpsSchemaJSON string
+
 // BatchSpecSchemaJSON is the content of the file "batch_spec.schema.json".
 //go:embed batch_spec.schema.json
 var BatchSpecSchemaJSON string
@@ -54,6 +58,10 @@ var NpmPackagesSchemaJSON string
 //go:embed python-packages.schema.json
 var PythonPackagesSchemaJSON string
 
+// RubyPackagesSchemaJSON is the content of the file "ruby-packages.schema.json".
+//go:embed ruby-packages.schema.json
+var RubyPackagesSchemaJSON string
+
 // RustPackagesSchemaJSON is the content of the file "python-packages.schema.json".
 //go:embed rust-packages.schema.json
 var RustPackagesSchemaJSON string